@@ -221,7 +221,9 @@ func (w *Watcher) approveProposal(ctx context.Context, proposalID string) error
 			AvailableTasksCount: len(p.Tasks),
 			Status:              "active",
 		}
-		_ = w.store.UpsertContractWithTasks(ctx, contract, p.Tasks)
+		if err := w.store.UpsertContractWithTasks(ctx, contract, p.Tasks); err != nil {
+			log.Printf("agents/watcher: upsert contract %s failed: %v", contractID, err)
+		}
 	}
 	return nil
 }
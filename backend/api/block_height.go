@@ -0,0 +1,49 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// ParseBlockHeight validates a block height taken from a URL: decimal
+// digits only, non-negative and, when tip > 0, not above the chain tip.
+func ParseBlockHeight(raw string, tip int64) (int64, error) {
+	if raw == "" {
+		return 0, fmt.Errorf("height is required")
+	}
+	height, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("height %q is not a number", raw)
+	}
+	if height < 0 {
+		return 0, fmt.Errorf("height %d is negative", height)
+	}
+	if tip > 0 && height > tip {
+		return 0, fmt.Errorf("height %d is above the chain tip %d", height, tip)
+	}
+	return height, nil
+}
+
+// knownTip returns the chain tip used to bound requested heights, or 0 when
+// none is known (no monitor, or it has not reached a height source yet).
+func (api *DataAPI) knownTip() int64 {
+	if api.tipHeight != nil {
+		return api.tipHeight()
+	}
+	if api.blockMonitor != nil {
+		return api.blockMonitor.KnownTipHeight()
+	}
+	return 0
+}
+
+// RequireBlockHeight parses raw with ParseBlockHeight against the known tip.
+// On failure it writes the shared 400 response and returns false.
+func (api *DataAPI) RequireBlockHeight(w http.ResponseWriter, raw string) (int64, bool) {
+	height, err := ParseBlockHeight(raw, api.knownTip())
+	if err != nil {
+		http.Error(w, "Invalid block height: "+err.Error(), http.StatusBadRequest)
+		return 0, false
+	}
+	return height, true
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseBlockHeight(t *testing.T) {
+	tests := []struct {
+		raw     string
+		tip     int64
+		want    int64
+		wantErr string
+	}{
+		{raw: "0", tip: 100, want: 0},
+		{raw: "100", tip: 100, want: 100},
+		{raw: "840000", tip: 0, want: 840000}, // unknown tip: no upper bound
+		{raw: "", tip: 100, wantErr: "required"},
+		{raw: "-1", tip: 100, wantErr: "negative"},
+		{raw: "abc", tip: 100, wantErr: "not a number"},
+		{raw: "12a", tip: 100, wantErr: "not a number"},
+		{raw: "101", tip: 100, wantErr: "above the chain tip"},
+	}
+	for _, tt := range tests {
+		got, err := ParseBlockHeight(tt.raw, tt.tip)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseBlockHeight(%q, %d) error = %v, want %q", tt.raw, tt.tip, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseBlockHeight(%q, %d) = %d, %v; want %d", tt.raw, tt.tip, got, err, tt.want)
+		}
+	}
+}
+
+func TestBlockEndpointsRejectInvalidHeights(t *testing.T) {
+	t.Setenv("BLOCKS_DIR", t.TempDir())
+	api := &DataAPI{dataStorage: &mockDataStorage{}, tipHeight: func() int64 { return 1000 }}
+
+	endpoints := []struct {
+		name    string
+		handler http.HandlerFunc
+		url     func(height string) string
+	}{
+		{"block data", api.HandleGetBlockData, func(h string) string { return "/api/data/block/" + h }},
+		{"raw block", api.HandleGetRawBlock, func(h string) string { return "/api/block/" + h }},
+		{"block images", api.HandleGetBlockImages, func(h string) string { return "/api/data/block-images?height=" + h }},
+		{"block inscriptions", api.HandleGetBlockInscriptionsPaginated, func(h string) string { return "/api/data/block-inscriptions/" + h }},
+	}
+	heights := map[string]string{
+		"-5":   "negative",
+		"tip":  "not a number",
+		"1001": "above the chain tip",
+	}
+
+	for _, ep := range endpoints {
+		for height, reason := range heights {
+			w := httptest.NewRecorder()
+			ep.handler(w, httptest.NewRequest(http.MethodGet, ep.url(height), nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s height %s: status %d, want 400", ep.name, height, w.Code)
+				continue
+			}
+			if body := w.Body.String(); !strings.HasPrefix(body, "Invalid block height: ") || !strings.Contains(body, reason) {
+				t.Errorf("%s height %s: body %q, want %q", ep.name, height, body, reason)
+			}
+		}
+	}
+}
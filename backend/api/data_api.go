@@ -7,10 +7,12 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -20,7 +22,9 @@ import (
 	"time"
 
 	"stargate-backend/bitcoin"
+	"stargate-backend/core"
 	"stargate-backend/security"
+	"stargate-backend/stego"
 	"stargate-backend/storage"
 )
 
@@ -35,6 +39,8 @@ type DataAPI struct {
 	// even if the BlockDataCache.Inscriptions list for that height is currently empty.
 	heightIndex map[int64][]string
 	txMu        sync.RWMutex
+	// tipHeight overrides the chain tip used by RequireBlockHeight (tests).
+	tipHeight func() int64
 }
 
 // NewDataAPI creates a new data API instance
@@ -58,6 +64,17 @@ func (api *DataAPI) resolveBlocksDir() string {
 	return "blocks"
 }
 
+// blockDir returns the on-disk directory for a block height, resolved through
+// the block index (or a directory scan) and falling back to the legacy
+// <height>_00000000 layout.
+func (api *DataAPI) blockDir(height int64) string {
+	base := strings.TrimRight(api.resolveBlocksDir(), "/")
+	if dir, err := bitcoin.FindBlockDirectory(base, height); err == nil {
+		return dir
+	}
+	return fmt.Sprintf("%s/%d_00000000", base, height)
+}
+
 // loadBlockFromDisk reads a single block JSON file into a cache struct.
 func (api *DataAPI) loadBlockFromDisk(height int64) (*storage.BlockDataCache, error) {
 	baseDir := strings.TrimRight(api.resolveBlocksDir(), "/")
@@ -65,8 +82,8 @@ func (api *DataAPI) loadBlockFromDisk(height int64) (*storage.BlockDataCache, er
 
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		// Try directory-based layout: <height>_00000000/inscriptions.json
-		dirPath := fmt.Sprintf("%s/%d_00000000/inscriptions.json", baseDir, height)
+		// Try directory-based layout: <height>_<hash>/inscriptions.json
+		dirPath := filepath.Join(api.blockDir(height), "inscriptions.json")
 		if data2, err2 := os.ReadFile(dirPath); err2 == nil {
 			data = data2
 		} else {
@@ -205,10 +222,8 @@ func (api *DataAPI) HandleGetBlockData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	log.Printf("Extracted height string: %s", heightStr)
-	height, err := strconv.ParseInt(heightStr, 10, 64)
-	if err != nil {
-		log.Printf("ParseInt error: %v", err)
-		http.Error(w, "Invalid block height", http.StatusBadRequest)
+	height, ok := api.RequireBlockHeight(w, heightStr)
+	if !ok {
 		return
 	}
 
@@ -253,6 +268,46 @@ func (api *DataAPI) HandleGetBlockData(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(blockData)
 }
 
+// HandleGetRawBlock serves the block.json written by the block monitor for a
+// processed block (GET /api/block/{height}): header, transactions, extracted
+// images and parser metadata. Unprocessed heights return 404.
+func (api *DataAPI) HandleGetRawBlock(w http.ResponseWriter, r *http.Request) {
+	api.EnableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	heightStr := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/block/"), "/")
+	height, ok := api.RequireBlockHeight(w, heightStr)
+	if !ok {
+		return
+	}
+
+	dir, err := bitcoin.FindBlockDirectory(api.resolveBlocksDir(), height)
+	if err != nil {
+		http.Error(w, "Block not found", http.StatusNotFound)
+		return
+	}
+	blockJSON, err := os.ReadFile(filepath.Join(dir, "block.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			http.Error(w, "Block not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Failed to read block.json for block %d: %v", height, err)
+		http.Error(w, "Failed to read block data", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(blockJSON)
+}
+
 // HandleGetRecentBlocks handles getting recent blocks with steganography data
 func (api *DataAPI) HandleGetRecentBlocks(w http.ResponseWriter, r *http.Request) {
 	api.EnableCORS(w, r)
@@ -506,6 +561,176 @@ func (api *DataAPI) HandleGetBlockSummaries(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// smartContractFilter holds the query parameters accepted by HandleGetSmartContracts.
+type smartContractFilter struct {
+	contractType  string
+	minConfidence float64
+	fromHeight    int64
+	toHeight      int64
+	hasMessage    *bool
+	limit         int
+	offset        int
+}
+
+// parseSmartContractFilter reads the filter from the query string; malformed
+// values are reported rather than silently ignored.
+func parseSmartContractFilter(q url.Values) (smartContractFilter, error) {
+	f := smartContractFilter{
+		contractType: strings.ToLower(strings.TrimSpace(q.Get("contract_type"))),
+		limit:        50,
+	}
+	if raw := q.Get("min_confidence"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || v > 1 {
+			return f, fmt.Errorf("invalid min_confidence %q", raw)
+		}
+		f.minConfidence = v
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int64
+	}{{"from_height", &f.fromHeight}, {"to_height", &f.toHeight}} {
+		if raw := q.Get(p.name); raw != "" {
+			v, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil || v < 0 {
+				return f, fmt.Errorf("invalid %s %q", p.name, raw)
+			}
+			*p.dst = v
+		}
+	}
+	if f.toHeight > 0 && f.fromHeight > f.toHeight {
+		return f, fmt.Errorf("from_height %d is above to_height %d", f.fromHeight, f.toHeight)
+	}
+	if raw := q.Get("has_message"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return f, fmt.Errorf("invalid has_message %q", raw)
+		}
+		f.hasMessage = &v
+	}
+	if raw := q.Get("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return f, fmt.Errorf("invalid limit %q", raw)
+		}
+		if v > 500 {
+			v = 500
+		}
+		f.limit = v
+	}
+	if raw := q.Get("offset"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			return f, fmt.Errorf("invalid offset %q", raw)
+		}
+		f.offset = v
+	}
+	return f, nil
+}
+
+func (f smartContractFilter) matches(contract bitcoin.SmartContractData) bool {
+	if f.contractType != "" && smartContractType(contract) != f.contractType {
+		return false
+	}
+	if contract.Confidence < f.minConfidence {
+		return false
+	}
+	if f.fromHeight > 0 && contract.BlockHeight < f.fromHeight {
+		return false
+	}
+	if f.toHeight > 0 && contract.BlockHeight > f.toHeight {
+		return false
+	}
+	if f.hasMessage != nil && (smartContractMessage(contract) != "") != *f.hasMessage {
+		return false
+	}
+	return true
+}
+
+// smartContractType returns the metadata contract_type, defaulting to
+// "steganographic" for the detections the block monitor records.
+func smartContractType(contract bitcoin.SmartContractData) string {
+	if t := strings.ToLower(strings.TrimSpace(stringFromAny(contract.Metadata["contract_type"]))); t != "" {
+		return t
+	}
+	return "steganographic"
+}
+
+func smartContractMessage(contract bitcoin.SmartContractData) string {
+	for _, key := range []string{"extracted_message", "embedded_message", "message"} {
+		if msg := strings.TrimSpace(stringFromAny(contract.Metadata[key])); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// HandleGetSmartContracts lists the smart contracts recorded in processed
+// blocks, newest block first. A contract seen in several blocks is listed
+// once, keyed by visible_pixel_hash, at its latest height. Filters
+// (contract_type, min_confidence, from_height/to_height, has_message) are
+// applied server-side before limit/offset; filtered_total counts the matches
+// and total every contract.
+func (api *DataAPI) HandleGetSmartContracts(w http.ResponseWriter, r *http.Request) {
+	api.EnableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseSmartContractFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	total := 0
+	matched := []bitcoin.SmartContractData{}
+	seen := make(map[string]bool)
+	for _, h := range api.listAvailableBlockHeights() {
+		block, err := api.loadBlock(h)
+		if err != nil {
+			log.Printf("Failed to load block %d: %v", h, err)
+			continue
+		}
+		for _, contract := range block.SmartContracts {
+			// Blocks are walked newest first, so the first sighting of an
+			// image carries its latest height and confidence.
+			if hash := strings.TrimSpace(stringFromAny(contract.Metadata["visible_pixel_hash"])); hash != "" {
+				if seen[hash] {
+					continue
+				}
+				seen[hash] = true
+			}
+			total++
+			if filter.matches(contract) {
+				matched = append(matched, contract)
+			}
+		}
+	}
+
+	page := []bitcoin.SmartContractData{}
+	if filter.offset < len(matched) {
+		end := filter.offset + filter.limit
+		if end > len(matched) {
+			end = len(matched)
+		}
+		page = matched[filter.offset:end]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"contracts":      page,
+		"limit":          filter.limit,
+		"offset":         filter.offset,
+		"filtered_total": len(matched),
+		"total":          total,
+	})
+}
+
 // HandleGetSteganographyStats handles getting overall steganography statistics
 func (api *DataAPI) HandleGetSteganographyStats(w http.ResponseWriter, r *http.Request) {
 	api.EnableCORS(w, r)
@@ -529,6 +754,107 @@ func (api *DataAPI) HandleGetSteganographyStats(w http.ResponseWriter, r *http.R
 	json.NewEncoder(w).Encode(stats)
 }
 
+// HandleSearchMessages searches extracted stego messages across processed blocks
+// (GET /api/messages/search?q=...&limit=N), newest block first.
+func (api *DataAPI) HandleSearchMessages(w http.ResponseWriter, r *http.Request) {
+	api.EnableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "q parameter is required", http.StatusBadRequest)
+		return
+	}
+	limit := storage.DefaultMessageSearchLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	searcher, ok := api.dataStorage.(storage.MessageSearcher)
+	if !ok {
+		http.Error(w, "Message search not supported by storage backend", http.StatusNotImplemented)
+		return
+	}
+	matches, err := searcher.SearchMessages(query, limit)
+	if err != nil {
+		log.Printf("Failed to search messages for %q: %v", query, err)
+		http.Error(w, "Failed to search messages", http.StatusInternalServerError)
+		return
+	}
+	if matches == nil {
+		matches = []storage.MessageMatch{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"query":   query,
+		"results": matches,
+		"total":   len(matches),
+	})
+}
+
+// stegoDetection is a smart contract entry in the recent detections feed, with
+// its extracted message lifted out of the metadata.
+type stegoDetection struct {
+	bitcoin.SmartContractData
+	ExtractedMessage string `json:"extracted_message,omitempty"`
+}
+
+// HandleGetRecentStegoDetections returns the most recent stego detections
+// across processed blocks (GET /api/stego/recent?limit=N), ordered by block
+// height and first-seen time, newest first.
+func (api *DataAPI) HandleGetRecentStegoDetections(w http.ResponseWriter, r *http.Request) {
+	api.EnableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := storage.DefaultRecentDetectionsLimit
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	lister, ok := api.dataStorage.(storage.RecentDetectionLister)
+	if !ok {
+		http.Error(w, "Recent detections not supported by storage backend", http.StatusNotImplemented)
+		return
+	}
+	contracts, err := lister.GetRecentDetections(limit)
+	if err != nil {
+		log.Printf("Failed to list recent detections: %v", err)
+		http.Error(w, "Failed to list recent detections", http.StatusInternalServerError)
+		return
+	}
+	detections := make([]stegoDetection, 0, len(contracts))
+	for _, contract := range contracts {
+		detections = append(detections, stegoDetection{SmartContractData: contract, ExtractedMessage: smartContractMessage(contract)})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"detections": detections,
+		"total":      len(detections),
+	})
+}
+
 // HandleRealtimeUpdates handles real-time updates via Server-Sent Events
 func (api *DataAPI) HandleRealtimeUpdates(w http.ResponseWriter, r *http.Request) {
 	api.EnableCORS(w, r)
@@ -630,7 +956,11 @@ func (api *DataAPI) HandleScanBlockOnDemand(w http.ResponseWriter, r *http.Reque
 	// Process the block
 	log.Printf("On-demand scan requested for block %d-%d, force_scan=%v", startHeight, endHeight, forceScan)
 	for height := startHeight; height <= endHeight; height++ {
-		if err := api.blockMonitor.ProcessBlock(height); err != nil {
+		process := api.blockMonitor.ProcessBlock
+		if forceScan {
+			process = api.blockMonitor.ReprocessBlock
+		}
+		if err := process(height); err != nil {
 			http.Error(w, fmt.Sprintf("Failed to scan block %d: %v", height, err), http.StatusInternalServerError)
 			return
 		}
@@ -657,27 +987,128 @@ func (api *DataAPI) HandleScanBlockOnDemand(w http.ResponseWriter, r *http.Reque
 	})
 }
 
-// HandleGetBlockImages handles getting images for a specific block with enhanced metadata
-func (api *DataAPI) HandleGetBlockImages(w http.ResponseWriter, r *http.Request) {
+// HandleBackfill starts a background historical backfill (POST) or reports its progress (GET).
+func (api *DataAPI) HandleBackfill(w http.ResponseWriter, r *http.Request) {
 	api.EnableCORS(w, r)
 	if r.Method == "OPTIONS" {
 		return
 	}
 
-	if r.Method != "GET" {
+	switch r.Method {
+	case "GET":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(api.blockMonitor.BackfillStatus())
+	case "POST":
+		var request struct {
+			StartHeight int64 `json:"start_height"`
+			EndHeight   int64 `json:"end_height"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid JSON request", http.StatusBadRequest)
+			return
+		}
+		if request.EndHeight < request.StartHeight || request.StartHeight < 0 {
+			http.Error(w, "end_height must be >= start_height >= 0", http.StatusBadRequest)
+			return
+		}
+		if err := api.blockMonitor.StartBackfill(request.StartHeight, request.EndHeight); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		log.Printf("Backfill requested for blocks %d-%d", request.StartHeight, request.EndHeight)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":      true,
+			"start_height": request.StartHeight,
+			"end_height":   request.EndHeight,
+			"message":      "Backfill started",
+		})
+	default:
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleRescanImage re-runs the stego scanner on a previously extracted image
+// (POST /bitcoin/v1/rescan/image/{sha256}) and stores the new scan result.
+// The optional JSON body carries scan options:
+// {"extract_message": true, "confidence_threshold": 0.5, "include_metadata": true}.
+func (api *DataAPI) HandleRescanImage(w http.ResponseWriter, r *http.Request) {
+	api.EnableCORS(w, r)
+	if r.Method == "OPTIONS" {
 		return
 	}
 
-	heightStr := r.URL.Query().Get("height")
-	if heightStr == "" {
-		http.Error(w, "height parameter required", http.StatusBadRequest)
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	height, err := strconv.ParseInt(heightStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid height parameter", http.StatusBadRequest)
+	contentHash := strings.Trim(strings.TrimPrefix(r.URL.Path, "/bitcoin/v1/rescan/image/"), "/")
+	if decoded, err := hex.DecodeString(contentHash); err != nil || len(decoded) != sha256.Size {
+		http.Error(w, "Invalid image sha256", http.StatusBadRequest)
+		return
+	}
+
+	request := struct {
+		ExtractMessage      *bool    `json:"extract_message"`
+		ConfidenceThreshold *float64 `json:"confidence_threshold"`
+		IncludeMetadata     *bool    `json:"include_metadata"`
+	}{}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+			http.Error(w, "Invalid JSON request", http.StatusBadRequest)
+			return
+		}
+	}
+	options := core.ScanOptions{
+		ExtractMessage:      true,
+		ConfidenceThreshold: 0.5,
+		IncludeMetadata:     true,
+	}
+	if request.ExtractMessage != nil {
+		options.ExtractMessage = *request.ExtractMessage
+	}
+	if request.ConfidenceThreshold != nil {
+		options.ConfidenceThreshold = *request.ConfidenceThreshold
+	}
+	if request.IncludeMetadata != nil {
+		options.IncludeMetadata = *request.IncludeMetadata
+	}
+
+	result, err := api.blockMonitor.RescanImage(contentHash, options)
+	switch {
+	case errors.Is(err, bitcoin.ErrImageNotFound):
+		http.Error(w, "Image not found", http.StatusNotFound)
+		return
+	case errors.Is(err, bitcoin.ErrScannerUnavailable):
+		http.Error(w, "Scanner not available", http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		log.Printf("Failed to rescan image %s: %v", contentHash, err)
+		http.Error(w, "Failed to rescan image", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Rescanned image %s in block %d: is_stego=%v", contentHash, result.BlockHeight, result.ScanResult["is_stego"])
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// HandleGetBlockImages handles getting images for a specific block with enhanced metadata
+func (api *DataAPI) HandleGetBlockImages(w http.ResponseWriter, r *http.Request) {
+	api.EnableCORS(w, r)
+	if r.Method == "OPTIONS" {
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	height, ok := api.RequireBlockHeight(w, r.URL.Query().Get("height"))
+	if !ok {
 		return
 	}
 
@@ -783,11 +1214,9 @@ func (api *DataAPI) HandleGetBlockInscriptionsPaginated(w http.ResponseWriter, r
 		return
 	}
 	// Expected: /api/data/block-inscriptions/{height}
-	heightStr := parts[3]
-	height, err := strconv.ParseInt(heightStr, 10, 64)
-	if err != nil {
-		log.Printf("block-inscriptions: invalid height %q", heightStr)
-		http.Error(w, "invalid height", http.StatusBadRequest)
+	height, ok := api.RequireBlockHeight(w, parts[3])
+	if !ok {
+		log.Printf("block-inscriptions: invalid height %q", parts[3])
 		return
 	}
 
@@ -959,7 +1388,7 @@ func (api *DataAPI) HandleGetBlockInscriptionsPaginated(w http.ResponseWriter, r
 			// Detect placeholder content and attempt to read the actual file.
 			looksPlaceholder := inscriptionContent == "" || strings.HasPrefix(inscriptionContent, "Extracted from transaction")
 			if looksPlaceholder {
-				blockDir := fmt.Sprintf("%s/%d_00000000", strings.TrimRight(api.resolveBlocksDir(), "/"), height)
+				blockDir := api.blockDir(height)
 				safePath, err := security.SanitizePath(blockDir, ins.FilePath)
 				if err == nil {
 					if data, err := os.ReadFile(safePath); err == nil {
@@ -993,8 +1422,7 @@ func (api *DataAPI) sniffContentType(height int64, filePath string) string {
 	if strings.TrimSpace(filePath) == "" {
 		return ""
 	}
-	base := strings.TrimRight(api.resolveBlocksDir(), "/")
-	fsPath := filepath.Join(fmt.Sprintf("%s/%d_00000000", base, height), filePath)
+	fsPath := filepath.Join(api.blockDir(height), filePath)
 	file, err := os.Open(fsPath)
 	if err != nil {
 		return ""
@@ -1483,8 +1911,7 @@ func (api *DataAPI) serveBlockImage(w http.ResponseWriter, height int64, filePat
 		http.Error(w, "inscription not found", http.StatusNotFound)
 		return
 	}
-	base := strings.TrimRight(api.resolveBlocksDir(), "/")
-	baseDir := fmt.Sprintf("%s/%d_00000000", base, height)
+	baseDir := api.blockDir(height)
 	safePath, err := security.SanitizePath(baseDir, filePath)
 	if err != nil {
 		http.Error(w, "inscription not found", http.StatusNotFound)
@@ -1648,8 +2075,7 @@ func (api *DataAPI) loadInscriptionContent(height int64, ins bitcoin.Inscription
 	content := []byte(ins.Content)
 	mimeType := inferMime(ins.ContentType, content, ins.FileName)
 
-	base := strings.TrimRight(api.resolveBlocksDir(), "/")
-	blockDir := fmt.Sprintf("%s/%d_00000000", base, height)
+	blockDir := api.blockDir(height)
 	safePath, err := security.SanitizePath(blockDir, ins.FilePath)
 	if err == nil {
 		if data, err := os.ReadFile(safePath); err == nil {
@@ -1677,7 +2103,7 @@ func (api *DataAPI) loadInscriptionContent(height int64, ins bitcoin.Inscription
 
 	// If this is an image but the payload has leading garbage, trim to the first valid signature.
 	if strings.HasPrefix(mimeType, "image/") {
-		if trimmed := trimToImageSignature(content); len(trimmed) > 0 {
+		if trimmed := stego.TrimToImageSignature(content); len(trimmed) > 0 {
 			content = trimmed
 		}
 		// Re-evaluate MIME after trimming.
@@ -1917,38 +2343,6 @@ func looksBase64(s string) bool {
 	return true
 }
 
-// trimToImageSignature scans for known image headers and slices from the first match.
-func trimToImageSignature(b []byte) []byte {
-	if len(b) < 8 {
-		return b
-	}
-	sigs := [][]byte{
-		{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}, // PNG
-		{0xFF, 0xD8, 0xFF},       // JPEG
-		[]byte("RIFF"),           // WEBP (we also check WEBP marker)
-		{0x47, 0x49, 0x46, 0x38}, // GIF
-		[]byte("ftypavif"),       // AVIF (ISO BMFF)
-	}
-	for _, sig := range sigs {
-		if idx := bytes.Index(b, sig); idx >= 0 {
-			// For RIFF/WEBP, ensure WEBP marker exists.
-			if sig[0] == 'R' && len(b) >= idx+12 {
-				if !(b[idx+8] == 'W' && b[idx+9] == 'E' && b[idx+10] == 'B' && b[idx+11] == 'P') {
-					continue
-				}
-			}
-			if string(sig) == "ftypavif" {
-				// Ensure we return from the start of the ftyp box.
-				if idx >= 4 {
-					return b[idx-4:]
-				}
-			}
-			return b[idx:]
-		}
-	}
-	return b
-}
-
 type scriptOpLocal struct {
 	opcode byte
 	data   []byte
@@ -2168,9 +2562,9 @@ func appendIfNotPresent(list []string, v string) []string {
 // in the loaded BlockDataCache is empty.
 func (api *DataAPI) findFirstBlockImageThumbnail(height int64) string {
 	base := strings.TrimRight(api.resolveBlocksDir(), "/")
-	// Try the two common directory shapes used by the system.
+	// Try the indexed directory first, then any other directory for the height.
 	candidates := []string{
-		filepath.Join(base, fmt.Sprintf("%d_00000000", height), "images"),
+		filepath.Join(api.blockDir(height), "images"),
 	}
 	// Also support the hashed suffix form <height>_<hash>
 	if matches, err := filepath.Glob(filepath.Join(base, fmt.Sprintf("%d_*", height), "images")); err == nil {
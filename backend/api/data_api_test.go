@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"stargate-backend/bitcoin"
+	"stargate-backend/starlight"
 	"stargate-backend/storage"
 )
 
@@ -72,6 +76,137 @@ func TestHandleGetBlockInscriptionsPaginated_TextContentIncluded(t *testing.T) {
 	}
 }
 
+func TestHandleSearchMessages(t *testing.T) {
+	ds := storage.NewDataStorage(t.TempDir())
+	for _, b := range []struct {
+		height int64
+		msg    string
+	}{{10, "hello from block ten"}, {20, "HELLO again"}} {
+		resp := &bitcoin.BlockInscriptionsResponse{BlockHeight: b.height, BlockHash: "abc", Success: true}
+		scan := []map[string]interface{}{{"tx_id": fmt.Sprintf("tx%d", b.height), "extracted_message": b.msg}}
+		if err := ds.StoreBlockData(resp, scan); err != nil {
+			t.Fatal(err)
+		}
+	}
+	api := &DataAPI{dataStorage: ds}
+
+	w := httptest.NewRecorder()
+	api.HandleSearchMessages(w, httptest.NewRequest(http.MethodGet, "/api/messages/search?q=hello", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Results []storage.MessageMatch `json:"results"`
+		Total   int                    `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Total != 2 || body.Results[0].BlockHeight != 20 || body.Results[0].TxID != "tx20" {
+		t.Fatalf("unexpected results: %+v", body)
+	}
+
+	w = httptest.NewRecorder()
+	api.HandleSearchMessages(w, httptest.NewRequest(http.MethodGet, "/api/messages/search", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("missing q: expected 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	(&DataAPI{dataStorage: &mockDataStorage{}}).HandleSearchMessages(w, httptest.NewRequest(http.MethodGet, "/api/messages/search?q=x", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("unsupported storage: expected 501, got %d", w.Code)
+	}
+}
+
+func TestHandleRescanImageErrors(t *testing.T) {
+	t.Setenv("BLOCKS_DIR", t.TempDir())
+	monitor := bitcoin.NewBlockMonitor(bitcoin.NewBitcoinNodeClient("http://127.0.0.1:0"))
+	api := &DataAPI{blockMonitor: monitor}
+
+	w := httptest.NewRecorder()
+	api.HandleRescanImage(w, httptest.NewRequest(http.MethodPost, "/bitcoin/v1/rescan/image/abc", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("short hash: expected 400, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	api.HandleRescanImage(w, httptest.NewRequest(http.MethodPost, "/bitcoin/v1/rescan/image/"+strings.Repeat("zz", 32), nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("non-hex hash: expected 400, got %d", w.Code)
+	}
+
+	hash := strings.Repeat("ab", 32)
+	w = httptest.NewRecorder()
+	api.HandleRescanImage(w, httptest.NewRequest(http.MethodPost, "/bitcoin/v1/rescan/image/"+hash, nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("no scanner: expected 503, got %d", w.Code)
+	}
+
+	monitor.SetImageScanner(starlight.NewMockStarlightScanner())
+	w = httptest.NewRecorder()
+	api.HandleRescanImage(w, httptest.NewRequest(http.MethodPost, "/bitcoin/v1/rescan/image/"+hash, strings.NewReader(`{"confidence_threshold":0.2}`)))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unknown image: expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandleGetRawBlock(t *testing.T) {
+	blocksDir := t.TempDir()
+	t.Setenv("BLOCKS_DIR", blocksDir)
+	dir := filepath.Join(blocksDir, "840000_0000abcd")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	seeded := bitcoin.BlockData{
+		BlockHeader: bitcoin.BlockHeader{
+			Version:    0x20000000,
+			PrevBlock:  "00000000000000000002aaaa",
+			MerkleRoot: "merkle",
+			Timestamp:  1713571767,
+			Bits:       0x17034219,
+			Nonce:      42,
+			Hash:       "0000abcd",
+		},
+		Transactions: []bitcoin.TransactionData{{TxID: "tx1"}},
+	}
+	raw, err := json.Marshal(seeded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "block.json"), raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	api := &DataAPI{}
+	w := httptest.NewRecorder()
+	api.HandleGetRawBlock(w, httptest.NewRequest(http.MethodGet, "/api/block/840000", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var got bitcoin.BlockData
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.BlockHeader != seeded.BlockHeader {
+		t.Fatalf("header = %+v, want %+v", got.BlockHeader, seeded.BlockHeader)
+	}
+	if len(got.Transactions) != 1 || got.Transactions[0].TxID != "tx1" {
+		t.Fatalf("transactions = %+v", got.Transactions)
+	}
+
+	w = httptest.NewRecorder()
+	api.HandleGetRawBlock(w, httptest.NewRequest(http.MethodGet, "/api/block/840001", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unprocessed block: expected 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	api.HandleGetRawBlock(w, httptest.NewRequest(http.MethodGet, "/api/block/tip", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("bad height: expected 400, got %d", w.Code)
+	}
+}
+
 // --- mocks ---
 
 type mockDataStorage struct {
@@ -106,3 +241,169 @@ func (m *mockDataStorage) CreateRealtimeUpdate(string, int64, interface{}) *stor
 func (m *mockDataStorage) ReadTextContent(int64, string) (string, error) {
 	return "", fmt.Errorf("not found")
 }
+
+func TestHandleGetSmartContractsFilters(t *testing.T) {
+	t.Setenv("BLOCKS_DIR", t.TempDir())
+	ds := storage.NewDataStorage(t.TempDir())
+	blocks := map[int64][]bitcoin.SmartContractData{
+		100: {
+			{ContractID: "a", BlockHeight: 100, Confidence: 0.95, Metadata: map[string]any{"extracted_message": "hi"}},
+			{ContractID: "b", BlockHeight: 100, Confidence: 0.4, Metadata: map[string]any{}},
+		},
+		200: {
+			{ContractID: "c", BlockHeight: 200, Confidence: 0.8, Metadata: map[string]any{"contract_type": "Escrow", "embedded_message": "pay"}},
+			{ContractID: "d", BlockHeight: 200, Confidence: 0.75, Metadata: map[string]any{}},
+		},
+		300: {
+			{ContractID: "e", BlockHeight: 300, Confidence: 0.9, Metadata: map[string]any{"message": "later"}},
+		},
+	}
+	for height, contracts := range blocks {
+		resp := &bitcoin.BlockInscriptionsResponse{BlockHeight: height, BlockHash: "abc", SmartContracts: contracts, Success: true}
+		if err := ds.StoreBlockData(resp, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	api := &DataAPI{dataStorage: ds}
+
+	tests := []struct {
+		query    string
+		want     []string
+		filtered int
+	}{
+		{"", []string{"e", "c", "d", "a", "b"}, 5},
+		{"contract_type=escrow", []string{"c"}, 1},
+		{"contract_type=steganographic", []string{"e", "d", "a", "b"}, 4},
+		{"min_confidence=0.8", []string{"e", "c", "a"}, 3},
+		{"from_height=200", []string{"e", "c", "d"}, 3},
+		{"to_height=200", []string{"c", "d", "a", "b"}, 4},
+		{"from_height=150&to_height=250", []string{"c", "d"}, 2},
+		{"has_message=true", []string{"e", "c", "a"}, 3},
+		{"has_message=false", []string{"d", "b"}, 2},
+		{"has_message=true&min_confidence=0.85", []string{"e", "a"}, 2},
+		{"contract_type=steganographic&from_height=100&to_height=200&has_message=false&min_confidence=0.5", []string{"d"}, 1},
+		{"limit=2", []string{"e", "c"}, 5},
+		{"limit=2&offset=2", []string{"d", "a"}, 5},
+		{"min_confidence=0.7&offset=10", nil, 4},
+	}
+	for _, tc := range tests {
+		w := httptest.NewRecorder()
+		api.HandleGetSmartContracts(w, httptest.NewRequest(http.MethodGet, "/api/data/smart-contracts?"+tc.query, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: expected 200, got %d: %s", tc.query, w.Code, w.Body.String())
+		}
+		var body struct {
+			Contracts     []bitcoin.SmartContractData `json:"contracts"`
+			FilteredTotal int                         `json:"filtered_total"`
+			Total         int                         `json:"total"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, c := range body.Contracts {
+			got = append(got, c.ContractID)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") || body.FilteredTotal != tc.filtered || body.Total != 5 {
+			t.Fatalf("%q: got %v filtered=%d total=%d, want %v filtered=%d total=5", tc.query, got, body.FilteredTotal, body.Total, tc.want, tc.filtered)
+		}
+	}
+
+	for _, query := range []string{"min_confidence=high", "from_height=-1", "from_height=300&to_height=200", "has_message=maybe", "limit=0", "offset=-2"} {
+		w := httptest.NewRecorder()
+		api.HandleGetSmartContracts(w, httptest.NewRequest(http.MethodGet, "/api/data/smart-contracts?"+query, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%q: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestHandleGetSmartContractsDedupesByVisiblePixelHash(t *testing.T) {
+	t.Setenv("BLOCKS_DIR", t.TempDir())
+	ds := storage.NewDataStorage(t.TempDir())
+	blocks := map[int64][]bitcoin.SmartContractData{
+		500: {{ContractID: "stego_same", BlockHeight: 500, Confidence: 0.6, Metadata: map[string]any{"visible_pixel_hash": "same"}}},
+		501: {{ContractID: "stego_same", BlockHeight: 501, Confidence: 0.9, Metadata: map[string]any{"visible_pixel_hash": "same"}}},
+	}
+	for height, contracts := range blocks {
+		resp := &bitcoin.BlockInscriptionsResponse{BlockHeight: height, BlockHash: "abc", SmartContracts: contracts, Success: true}
+		if err := ds.StoreBlockData(resp, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	api := &DataAPI{dataStorage: ds}
+
+	w := httptest.NewRecorder()
+	api.HandleGetSmartContracts(w, httptest.NewRequest(http.MethodGet, "/api/data/smart-contracts", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Contracts []bitcoin.SmartContractData `json:"contracts"`
+		Total     int                         `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Contracts) != 1 || body.Total != 1 {
+		t.Fatalf("got %d contracts total=%d, want 1: %+v", len(body.Contracts), body.Total, body.Contracts)
+	}
+	if got := body.Contracts[0]; got.BlockHeight != 501 || got.Confidence != 0.9 {
+		t.Fatalf("got height=%d confidence=%v, want latest sighting 501/0.9", got.BlockHeight, got.Confidence)
+	}
+}
+
+func TestHandleGetRecentStegoDetections(t *testing.T) {
+	ds := storage.NewDataStorage(t.TempDir())
+	blocks := map[int64][]bitcoin.SmartContractData{
+		400: {{ContractID: "older", BlockHeight: 400, Confidence: 0.8, Metadata: map[string]any{"extracted_message": "first"}}},
+		401: {{ContractID: "newer", BlockHeight: 401, Confidence: 0.95, Metadata: map[string]any{"extracted_message": "second"}}},
+	}
+	for height, contracts := range blocks {
+		resp := &bitcoin.BlockInscriptionsResponse{BlockHeight: height, BlockHash: "abc", SmartContracts: contracts, Success: true}
+		if err := ds.StoreBlockData(resp, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	api := &DataAPI{dataStorage: ds}
+
+	w := httptest.NewRecorder()
+	api.HandleGetRecentStegoDetections(w, httptest.NewRequest(http.MethodGet, "/api/stego/recent?limit=5", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Detections []struct {
+			ContractID       string  `json:"contract_id"`
+			BlockHeight      int64   `json:"block_height"`
+			Confidence       float64 `json:"confidence"`
+			ExtractedMessage string  `json:"extracted_message"`
+		} `json:"detections"`
+		Total int `json:"total"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Total != 2 || len(body.Detections) != 2 {
+		t.Fatalf("unexpected body: %+v", body)
+	}
+	first, second := body.Detections[0], body.Detections[1]
+	if first.ContractID != "newer" || first.BlockHeight != 401 || first.Confidence != 0.95 || first.ExtractedMessage != "second" {
+		t.Fatalf("newest detection = %+v", first)
+	}
+	if second.ContractID != "older" || second.ExtractedMessage != "first" {
+		t.Fatalf("second detection = %+v", second)
+	}
+
+	w = httptest.NewRecorder()
+	api.HandleGetRecentStegoDetections(w, httptest.NewRequest(http.MethodGet, "/api/stego/recent?limit=abc", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("bad limit: expected 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	(&DataAPI{dataStorage: &mockDataStorage{}}).HandleGetRecentStegoDetections(w, httptest.NewRequest(http.MethodGet, "/api/stego/recent", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("unsupported storage: expected 501, got %d", w.Code)
+	}
+}
@@ -0,0 +1,189 @@
+package bitcoin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// backfillProgressFile lives in the blocks directory next to recent-blocks.json.
+const backfillProgressFile = "backfill-progress.json"
+
+// BackfillProgress tracks a historical backfill. It is persisted after every
+// height so an interrupted backfill resumes where it stopped.
+type BackfillProgress struct {
+	StartHeight int64     `json:"start_height"`
+	EndHeight   int64     `json:"end_height"`
+	NextHeight  int64     `json:"next_height"`
+	Processed   int64     `json:"processed"`
+	Skipped     int64     `json:"skipped"`
+	Failed      []int64   `json:"failed,omitempty"`
+	Running     bool      `json:"running"`
+	Completed   bool      `json:"completed"`
+	LastError   string    `json:"last_error,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BackfillRange processes the historical heights [start, end] with the same
+// per-block throttling as the forward monitor. Heights that already have a
+// complete block directory are skipped, and progress is persisted so a re-run of the
+// same range resumes from the first unfinished height. The forward monitor's
+// currentHeight is never touched, so both can run side by side.
+func (bm *BlockMonitor) BackfillRange(ctx context.Context, start, end int64) (BackfillProgress, error) {
+	if start < 0 || end < start {
+		return BackfillProgress{}, fmt.Errorf("invalid backfill range %d-%d", start, end)
+	}
+	if !bm.backfillMu.TryLock() {
+		return bm.BackfillStatus(), fmt.Errorf("backfill already running")
+	}
+	defer bm.backfillMu.Unlock()
+	return bm.backfillRange(ctx, start, end)
+}
+
+// backfillRange does the work of BackfillRange; the caller holds backfillMu.
+func (bm *BlockMonitor) backfillRange(ctx context.Context, start, end int64) (BackfillProgress, error) {
+	progress := BackfillProgress{StartHeight: start, EndHeight: end, NextHeight: start}
+	if saved, err := bm.loadBackfillProgress(); err == nil &&
+		saved.StartHeight == start && saved.EndHeight == end && !saved.Completed &&
+		saved.NextHeight > start && saved.NextHeight <= end {
+		progress = saved
+		log.Printf("Resuming backfill %d-%d at height %d", start, end, progress.NextHeight)
+	}
+	progress.Running = true
+	bm.setBackfillProgress(progress)
+
+	fetched := false
+	for height := progress.NextHeight; height <= end; height++ {
+		if err := ctx.Err(); err != nil {
+			progress.LastError = err.Error()
+			break
+		}
+
+		if _, ok := bm.completeBlockDirectory(height); ok {
+			progress.Skipped++
+		} else {
+			if fetched && bm.blockDelay > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(bm.blockDelay):
+				}
+				if err := ctx.Err(); err != nil {
+					progress.LastError = err.Error()
+					break
+				}
+			}
+			fetched = true
+			if err := bm.ProcessBlock(height); err != nil {
+				log.Printf("Backfill: failed to process block %d: %v", height, err)
+				progress.Failed = append(progress.Failed, height)
+				progress.LastError = err.Error()
+			} else {
+				progress.Processed++
+			}
+		}
+
+		progress.NextHeight = height + 1
+		bm.setBackfillProgress(progress)
+	}
+
+	progress.Running = false
+	progress.Completed = progress.NextHeight > end
+	bm.setBackfillProgress(progress)
+	log.Printf("Backfill %d-%d stopped at %d: processed=%d skipped=%d failed=%d",
+		start, end, progress.NextHeight, progress.Processed, progress.Skipped, len(progress.Failed))
+	return progress, ctx.Err()
+}
+
+// StartBackfill runs BackfillRange in the background. It returns an error
+// immediately if a backfill is already running; the backfill lock is taken
+// before returning, so concurrent callers cannot both start one. Stop cancels
+// the run and Shutdown waits for it.
+func (bm *BlockMonitor) StartBackfill(start, end int64) error {
+	if start < 0 || end < start {
+		return fmt.Errorf("invalid backfill range %d-%d", start, end)
+	}
+	if !bm.backfillMu.TryLock() {
+		return fmt.Errorf("backfill already running")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	bm.mu.Lock()
+	bm.backfillCancel = cancel
+	bm.mu.Unlock()
+	bm.goLoop(func() {
+		defer bm.backfillMu.Unlock()
+		defer func() {
+			bm.mu.Lock()
+			bm.backfillCancel = nil
+			bm.mu.Unlock()
+			cancel()
+		}()
+		if _, err := bm.backfillRange(ctx, start, end); err != nil {
+			log.Printf("Backfill %d-%d ended with error: %v", start, end, err)
+		}
+	})
+	return nil
+}
+
+// cancelBackfill stops a backfill started by StartBackfill; bm.mu must be held.
+func (bm *BlockMonitor) cancelBackfill() {
+	if bm.backfillCancel != nil {
+		bm.backfillCancel()
+	}
+}
+
+// BackfillStatus returns the progress of the current or most recent backfill.
+func (bm *BlockMonitor) BackfillStatus() BackfillProgress {
+	bm.mu.RLock()
+	current := bm.backfill
+	bm.mu.RUnlock()
+	if current != nil {
+		return *current
+	}
+	saved, err := bm.loadBackfillProgress()
+	if err != nil {
+		return BackfillProgress{}
+	}
+	saved.Running = false
+	return saved
+}
+
+func (bm *BlockMonitor) setBackfillProgress(progress BackfillProgress) {
+	progress.UpdatedAt = time.Now()
+	progress.Failed = append([]int64(nil), progress.Failed...)
+	bm.mu.Lock()
+	bm.backfill = &progress
+	bm.mu.Unlock()
+	if err := bm.saveBackfillProgress(progress); err != nil {
+		log.Printf("Backfill: failed to persist progress: %v", err)
+	}
+}
+
+func (bm *BlockMonitor) loadBackfillProgress() (BackfillProgress, error) {
+	var progress BackfillProgress
+	data, err := os.ReadFile(filepath.Join(bm.blocksDir, backfillProgressFile))
+	if err != nil {
+		return progress, err
+	}
+	err = json.Unmarshal(data, &progress)
+	return progress, err
+}
+
+func (bm *BlockMonitor) saveBackfillProgress(progress BackfillProgress) error {
+	if err := os.MkdirAll(bm.blocksDir, bm.dirMode); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(bm.blocksDir, backfillProgressFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, bm.fileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,205 @@
+package bitcoin
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// fakeRawSource serves synthetic single-coinbase blocks keyed by height.
+type fakeRawSource struct {
+	mu      sync.Mutex
+	blocks  map[int64]string
+	fetches map[int64]int
+}
+
+func newFakeRawSource() *fakeRawSource {
+	return &fakeRawSource{blocks: map[int64]string{}, fetches: map[int64]int{}}
+}
+
+// addChain appends consecutive blocks for heights [start, end], each linking to
+// the previous one, and returns their hashes by height.
+func (f *fakeRawSource) addChain(t *testing.T, start, end int64, prev chainhash.Hash, salt uint32) map[int64]string {
+	t.Helper()
+	hashes := map[int64]string{}
+	for h := start; h <= end; h++ {
+		raw, hash := buildTestBlock(t, prev, uint32(h)+salt)
+		f.mu.Lock()
+		f.blocks[h] = raw
+		f.mu.Unlock()
+		hashes[h] = hash.String()
+		prev = hash
+	}
+	return hashes
+}
+
+func (f *fakeRawSource) GetRawBlockHex(height int64) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetches[height]++
+	raw, ok := f.blocks[height]
+	if !ok {
+		return "", fmt.Errorf("no block at height %d", height)
+	}
+	return raw, nil
+}
+
+func (f *fakeRawSource) ParseBlock(hexData string) (*ParsedBlock, error) {
+	return (&RawBlockClient{}).ParseBlock(hexData)
+}
+
+func (f *fakeRawSource) fetchCount(height int64) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.fetches[height]
+}
+
+func buildTestBlock(t *testing.T, prev chainhash.Hash, nonce uint32) (string, chainhash.Hash) {
+	t.Helper()
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: 0xffffffff},
+		SignatureScript:  []byte{0x01, byte(nonce)},
+		Sequence:         0xffffffff,
+	})
+	coinbase.AddTxOut(&wire.TxOut{Value: 50_0000_0000, PkScript: []byte{0x51}})
+
+	block := wire.NewMsgBlock(&wire.BlockHeader{
+		Version:    1,
+		PrevBlock:  prev,
+		MerkleRoot: coinbase.TxHash(),
+		Timestamp:  time.Unix(1700000000+int64(nonce), 0),
+		Bits:       0x1d00ffff,
+		Nonce:      nonce,
+	})
+	if err := block.AddTransaction(coinbase); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := block.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return hex.EncodeToString(buf.Bytes()), block.BlockHash()
+}
+
+func newTestBlockMonitor(t *testing.T, source RawBlockSource) *BlockMonitor {
+	t.Helper()
+	bm := NewBlockMonitor(NewBitcoinNodeClient("http://127.0.0.1:0"))
+	bm.blocksDir = t.TempDir()
+	bm.blockDelay = 0
+	bm.ipfsClient = nil
+	bm.SetRawBlockSource(source)
+	return bm
+}
+
+func TestBackfillRangeCreatesDirectoriesAndResumes(t *testing.T) {
+	source := newFakeRawSource()
+	source.addChain(t, 100, 103, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	ctx := context.Background()
+
+	progress, err := bm.BackfillRange(ctx, 100, 102)
+	if err != nil {
+		t.Fatalf("backfill: %v", err)
+	}
+	if progress.Processed != 3 || progress.Skipped != 0 || !progress.Completed {
+		t.Fatalf("progress = %+v, want 3 processed and completed", progress)
+	}
+	for h := int64(100); h <= 102; h++ {
+		dir, err := FindBlockDirectory(bm.blocksDir, h)
+		if err != nil {
+			t.Fatalf("height %d: %v", h, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "inscriptions.json")); err != nil {
+			t.Fatalf("height %d summary missing: %v", h, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(bm.blocksDir, backfillProgressFile)); err != nil {
+		t.Fatalf("progress file not persisted: %v", err)
+	}
+
+	// A wider re-run skips the existing directories and only fetches the new height.
+	progress, err = bm.BackfillRange(ctx, 100, 103)
+	if err != nil {
+		t.Fatalf("second backfill: %v", err)
+	}
+	if progress.Processed != 1 || progress.Skipped != 3 {
+		t.Fatalf("progress = %+v, want 1 processed and 3 skipped", progress)
+	}
+	for h := int64(100); h <= 103; h++ {
+		if got := source.fetchCount(h); got != 1 {
+			t.Fatalf("height %d fetched %d times, want 1", h, got)
+		}
+	}
+	if bm.currentHeight != 0 {
+		t.Fatalf("backfill moved forward monitor height to %d", bm.currentHeight)
+	}
+}
+
+func TestBackfillRangeResumesFromPersistedProgress(t *testing.T) {
+	source := newFakeRawSource()
+	source.addChain(t, 10, 12, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+
+	if err := bm.saveBackfillProgress(BackfillProgress{StartHeight: 10, EndHeight: 12, NextHeight: 12}); err != nil {
+		t.Fatal(err)
+	}
+	progress, err := bm.BackfillRange(context.Background(), 10, 12)
+	if err != nil {
+		t.Fatalf("backfill: %v", err)
+	}
+	if progress.NextHeight != 13 || !progress.Completed {
+		t.Fatalf("progress = %+v, want completed at 13", progress)
+	}
+	if source.fetchCount(10) != 0 || source.fetchCount(11) != 0 || source.fetchCount(12) != 1 {
+		t.Fatalf("resume fetched wrong heights: %v", source.fetches)
+	}
+}
+
+// gatedRawSource blocks every fetch until release is closed.
+type gatedRawSource struct {
+	*fakeRawSource
+	release chan struct{}
+}
+
+func (s *gatedRawSource) GetRawBlockHex(height int64) (string, error) {
+	<-s.release
+	return s.fakeRawSource.GetRawBlockHex(height)
+}
+
+func TestStartBackfillAdmitsOneConcurrentCaller(t *testing.T) {
+	source := &gatedRawSource{fakeRawSource: newFakeRawSource(), release: make(chan struct{})}
+	source.addChain(t, 20, 20, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+
+	// The second call comes before the first backfill goroutine has had a
+	// chance to run, so only the lock taken inside StartBackfill can stop it.
+	if err := bm.StartBackfill(20, 20); err != nil {
+		t.Fatalf("first start: %v", err)
+	}
+	if err := bm.StartBackfill(20, 20); err == nil {
+		t.Fatal("second start succeeded while a backfill was running")
+	}
+
+	close(source.release)
+	deadline := time.Now().Add(5 * time.Second)
+	for !bm.backfillMu.TryLock() {
+		if time.Now().After(deadline) {
+			t.Fatal("backfill did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	bm.backfillMu.Unlock()
+	if got := source.fetchCount(20); got != 1 {
+		t.Fatalf("height 20 fetched %d times, want 1", got)
+	}
+}
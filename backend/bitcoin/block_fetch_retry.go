@@ -0,0 +1,53 @@
+package bitcoin
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// fetchRawBlockHex downloads height through the raw block source, retrying
+// rate-limited downloads up to maxRetries times with exponential backoff
+// starting at retryDelay. Not-found and other errors are returned at once.
+func (bm *BlockMonitor) fetchRawBlockHex(height int64) (string, error) {
+	delay := bm.retryDelay
+	for attempt := 0; ; attempt++ {
+		hexData, err := bm.rawClient.GetRawBlockHex(height)
+		switch {
+		case err == nil:
+			return hexData, nil
+		case errors.Is(err, ErrRawBlockNotFound):
+			bm.recordRawBlockNotFound()
+			return "", err
+		case !errors.Is(err, ErrRawBlockRateLimited):
+			return "", err
+		case attempt >= bm.maxRetries:
+			bm.recordRawBlockRetriesExhausted()
+			return "", err
+		}
+
+		bm.recordRawBlockRetry()
+		log.Printf("Raw block %d rate limited (attempt %d/%d), retrying in %v", height, attempt+1, bm.maxRetries+1, delay)
+		if !bm.sleepUnlessStopped(delay) {
+			return "", err
+		}
+		delay *= 2
+	}
+}
+
+// sleepUnlessStopped waits for d and reports false if the monitor was
+// stopped first.
+func (bm *BlockMonitor) sleepUnlessStopped(d time.Duration) bool {
+	bm.mu.RLock()
+	stop := bm.stopChan
+	bm.mu.RUnlock()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		return false
+	}
+}
@@ -0,0 +1,97 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// newRawBlockServer serves block 100 as an Esplora endpoint whose
+// block-height lookup answers status for the first failures requests.
+func newRawBlockServer(t *testing.T, failures int32, status int) (*httptest.Server, string, *int32) {
+	t.Helper()
+	rawHex, hash := buildTestBlock(t, chainhash.Hash{}, 100)
+	raw, _ := hex.DecodeString(rawHex)
+	var lookups int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/block-height/100", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&lookups, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(hash.String()))
+	})
+	mux.HandleFunc("/block/"+hash.String()+"/raw", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(raw)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, rawHex, &lookups
+}
+
+func newRetryTestMonitor(t *testing.T, srv *httptest.Server) *BlockMonitor {
+	t.Helper()
+	bm := newTestBlockMonitor(t, NewRawBlockClientWithHTTP(srv.URL, srv.Client()))
+	bm.maxRetries = 3
+	bm.retryDelay = time.Millisecond
+	return bm
+}
+
+func TestFetchRawBlockHexRetriesRateLimits(t *testing.T) {
+	srv, rawHex, lookups := newRawBlockServer(t, 2, http.StatusTooManyRequests)
+	bm := newRetryTestMonitor(t, srv)
+
+	got, err := bm.fetchRawBlockHex(100)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if got != rawHex {
+		t.Fatal("downloaded block does not match the served block")
+	}
+	if n := atomic.LoadInt32(lookups); n != 3 {
+		t.Fatalf("block-height requests = %d, want 3", n)
+	}
+	stats := bm.GetStatistics()
+	if stats["raw_block_retries"] != int64(2) || stats["raw_block_retries_exhausted"] != int64(0) {
+		t.Fatalf("retry stats = %v / %v, want 2 / 0", stats["raw_block_retries"], stats["raw_block_retries_exhausted"])
+	}
+}
+
+func TestFetchRawBlockHexGivesUpAfterMaxRetries(t *testing.T) {
+	srv, _, lookups := newRawBlockServer(t, 100, http.StatusTooManyRequests)
+	bm := newRetryTestMonitor(t, srv)
+
+	_, err := bm.fetchRawBlockHex(100)
+	if !errors.Is(err, ErrRawBlockRateLimited) {
+		t.Fatalf("err = %v, want ErrRawBlockRateLimited", err)
+	}
+	if n := atomic.LoadInt32(lookups); n != 4 {
+		t.Fatalf("block-height requests = %d, want 4 (1 + maxRetries)", n)
+	}
+	if got := bm.GetStatistics()["raw_block_retries_exhausted"]; got != int64(1) {
+		t.Fatalf("raw_block_retries_exhausted = %v, want 1", got)
+	}
+}
+
+func TestFetchRawBlockHexDoesNotRetryNotFound(t *testing.T) {
+	srv, _, lookups := newRawBlockServer(t, 100, http.StatusNotFound)
+	bm := newRetryTestMonitor(t, srv)
+
+	_, err := bm.fetchRawBlockHex(100)
+	if !errors.Is(err, ErrRawBlockNotFound) {
+		t.Fatalf("err = %v, want ErrRawBlockNotFound", err)
+	}
+	if n := atomic.LoadInt32(lookups); n != 1 {
+		t.Fatalf("block-height requests = %d, want 1", n)
+	}
+	stats := bm.GetStatistics()
+	if stats["raw_block_not_found"] != int64(1) || stats["raw_block_retries"] != int64(0) {
+		t.Fatalf("stats = %v / %v, want not_found 1, retries 0", stats["raw_block_not_found"], stats["raw_block_retries"])
+	}
+}
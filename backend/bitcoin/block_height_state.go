@@ -0,0 +1,87 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// monitorHeightFile lives in the blocks directory unless
+// STARGATE_MONITOR_STATE_FILE points elsewhere.
+const monitorHeightFile = "monitor-height.json"
+
+// monitorHeightState is the persisted forward-monitor position.
+type monitorHeightState struct {
+	Height    int64     `json:"height"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// monitorHeightPath returns the file the forward-monitor height is kept in.
+func (bm *BlockMonitor) monitorHeightPath() string {
+	if p := strings.TrimSpace(os.Getenv("STARGATE_MONITOR_STATE_FILE")); p != "" {
+		return p
+	}
+	return filepath.Join(bm.blocksDir, monitorHeightFile)
+}
+
+// loadMonitorHeight reads the persisted height. A missing file returns 0 and
+// no error; an unreadable or invalid one returns an error.
+func (bm *BlockMonitor) loadMonitorHeight() (int64, error) {
+	data, err := os.ReadFile(bm.monitorHeightPath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var state monitorHeightState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return 0, err
+	}
+	if state.Height <= 0 {
+		return 0, fmt.Errorf("invalid height %d", state.Height)
+	}
+	return state.Height, nil
+}
+
+// saveMonitorHeight persists height so a restart resumes from height+1.
+func (bm *BlockMonitor) saveMonitorHeight(height int64) error {
+	bm.heightFileMu.Lock()
+	defer bm.heightFileMu.Unlock()
+
+	path := bm.monitorHeightPath()
+	if err := os.MkdirAll(filepath.Dir(path), bm.dirMode); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(monitorHeightState{Height: height, UpdatedAt: time.Now().UTC()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, bm.fileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// restoreMonitorHeight resumes the forward monitor from the persisted height.
+// Without a usable file the monitor keeps height 0 and seeds from the tip as
+// on a first run. Callers must hold bm.mu.
+func (bm *BlockMonitor) restoreMonitorHeight() {
+	if bm.currentHeight > 0 {
+		return
+	}
+	height, err := bm.loadMonitorHeight()
+	if err != nil {
+		log.Printf("Ignoring monitor height file %s: %v; seeding from the chain tip", bm.monitorHeightPath(), err)
+		return
+	}
+	if height > 0 {
+		log.Printf("Resuming block monitor after persisted height %d", height)
+		bm.currentHeight = height
+	}
+}
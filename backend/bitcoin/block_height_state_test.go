@@ -0,0 +1,58 @@
+package bitcoin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMonitorHeightPersistsAcrossRestarts(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.advanceCurrentHeight(850000)
+	bm.advanceCurrentHeight(850001)
+
+	restarted := newTestBlockMonitor(t, newFakeRawSource())
+	restarted.blocksDir = bm.blocksDir
+	restarted.restoreMonitorHeight()
+	if got := restarted.monitorHeight(); got != 850001 {
+		t.Fatalf("restored height = %d, want 850001", got)
+	}
+}
+
+func TestMonitorHeightFallsBackWhenFileMissingOrCorrupt(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.restoreMonitorHeight()
+	if got := bm.monitorHeight(); got != 0 {
+		t.Fatalf("height without a file = %d, want 0", got)
+	}
+
+	for _, content := range []string{"{not json", `{"height": -4}`} {
+		if err := os.WriteFile(filepath.Join(bm.blocksDir, monitorHeightFile), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		bm.restoreMonitorHeight()
+		if got := bm.monitorHeight(); got != 0 {
+			t.Fatalf("height from %q = %d, want 0", content, got)
+		}
+	}
+}
+
+func TestMonitorHeightFileFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "height.json")
+	t.Setenv("STARGATE_MONITOR_STATE_FILE", path)
+
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.advanceCurrentHeight(42)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("state file not written to STARGATE_MONITOR_STATE_FILE: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(bm.blocksDir, monitorHeightFile)); !os.IsNotExist(err) {
+		t.Fatalf("default state file written despite override: %v", err)
+	}
+
+	restarted := newTestBlockMonitor(t, newFakeRawSource())
+	restarted.restoreMonitorHeight()
+	if got := restarted.monitorHeight(); got != 42 {
+		t.Fatalf("restored height = %d, want 42", got)
+	}
+}
@@ -0,0 +1,68 @@
+package bitcoin
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"stargate-backend/stego"
+)
+
+// extensionContentTypes maps stored block image extensions to MIME types.
+var extensionContentTypes = map[string]string{
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+	"avif": "image/avif",
+	"bmp":  "image/bmp",
+	"svg":  "image/svg+xml",
+	"html": "text/html",
+	"htm":  "text/html",
+	"txt":  "text/plain",
+	"json": "application/json",
+}
+
+// ServableImage returns the MIME type of a stored block image and the bytes
+// to serve, cleaned the way sanitizeExtractedImage cleans images at
+// extraction. The content decides the type: a raster signature or SVG/HTML
+// markup after any opcode prefix wins over the extension, which is only
+// used when the bytes are inconclusive.
+func ServableImage(fileName string, data []byte) (string, []byte) {
+	contentType := sniffBlockImageType(data)
+	if contentType == "" {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(fileName), "."))
+		contentType = extensionContentTypes[ext]
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	cleaned := sanitizeExtractedImage(ExtractedImageData{FileName: fileName, ContentType: contentType, Data: data})
+	return contentType, cleaned.Data
+}
+
+// sniffBlockImageType detects a raster image or SVG/HTML document inside
+// data, skipping prefix bytes. It returns "" when neither is found.
+func sniffBlockImageType(data []byte) string {
+	trimmed := stego.TrimToImageSignature(data)
+	if len(trimmed) >= 12 && string(trimmed[4:12]) == "ftypavif" {
+		return "image/avif"
+	}
+	if ct := http.DetectContentType(trimmed); strings.HasPrefix(ct, "image/") {
+		return ct
+	}
+	idx := bytes.IndexByte(data, '<')
+	if idx < 0 {
+		return ""
+	}
+	head := strings.ToLower(string(data[idx:min(len(data), idx+512)]))
+	switch {
+	case strings.HasPrefix(head, "<svg"), strings.HasPrefix(head, "<?xml") && strings.Contains(head, "<svg"):
+		return "image/svg+xml"
+	case strings.HasPrefix(head, "<!doctype html"), strings.HasPrefix(head, "<html"):
+		return "text/html"
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package bitcoin
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func TestServableImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	pngBytes := buf.Bytes()
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`)
+	html := []byte("<!DOCTYPE html><html><body>hi</body></html>")
+
+	tests := []struct {
+		name     string
+		fileName string
+		data     []byte
+		wantType string
+		wantBody []byte
+	}{
+		{"prefixed png without extension", "abc123", append([]byte("ord\x01\x09image/png\x00"), pngBytes...), "image/png", pngBytes},
+		{"prefixed svg", "abc123.svg", append([]byte{0x01, 0x4c}, svg...), "image/svg+xml", svg},
+		{"html named png", "abc123.png", append([]byte{0x00, 0x63}, html...), "text/html", html},
+		{"text by extension", "note.txt", []byte("plain words"), "text/plain", []byte("plain words")},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotType, gotBody := ServableImage(tc.fileName, tc.data)
+			if gotType != tc.wantType {
+				t.Fatalf("content type = %q, want %q", gotType, tc.wantType)
+			}
+			if !bytes.Equal(gotBody, tc.wantBody) {
+				t.Fatalf("body = %q, want %q", gotBody, tc.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package bitcoin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"stargate-backend/security"
+)
+
+// blockIndexFile lives in the blocks directory next to recent-blocks.json.
+const blockIndexFile = "index.json"
+
+// blockIndexMu serialises read-modify-write cycles on index.json.
+var blockIndexMu sync.Mutex
+
+// BlockIndex maps block heights and full block hashes to their directory
+// names under the blocks directory, so lookups do not depend on how the
+// directories are named. A height maps to the directory processed last.
+// ByImage maps the sha256 of each stored image file to where it lives.
+type BlockIndex struct {
+	ByHeight  map[int64]string         `json:"by_height"`
+	ByHash    map[string]string        `json:"by_hash"`
+	ByImage   map[string]ImageLocation `json:"by_image"`
+	UpdatedAt int64                    `json:"updated_at"`
+}
+
+// ImageLocation is the block directory and file name of a stored image.
+type ImageLocation struct {
+	Height   int64  `json:"height"`
+	Dir      string `json:"dir"`
+	FileName string `json:"file_name"`
+}
+
+func newBlockIndex() *BlockIndex {
+	return &BlockIndex{ByHeight: map[int64]string{}, ByHash: map[string]string{}, ByImage: map[string]ImageLocation{}}
+}
+
+// LoadBlockIndex reads blocksDir/index.json.
+func LoadBlockIndex(blocksDir string) (*BlockIndex, error) {
+	data, err := os.ReadFile(filepath.Join(blocksDir, blockIndexFile))
+	if err != nil {
+		return nil, err
+	}
+	idx := newBlockIndex()
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("failed to parse block index: %w", err)
+	}
+	if idx.ByHeight == nil {
+		idx.ByHeight = map[int64]string{}
+	}
+	if idx.ByHash == nil {
+		idx.ByHash = map[string]string{}
+	}
+	if idx.ByImage == nil {
+		idx.ByImage = map[string]ImageLocation{}
+	}
+	return idx, nil
+}
+
+// RebuildBlockIndex scans blocksDir and rewrites index.json from the block
+// directories found there. When several directories exist for a height the
+// most recently written one wins.
+func RebuildBlockIndex(blocksDir string, mode os.FileMode) (*BlockIndex, error) {
+	blockIndexMu.Lock()
+	defer blockIndexMu.Unlock()
+
+	idx, err := scanBlockIndex(blocksDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeBlockIndex(blocksDir, idx, mode); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// scanBlockIndex builds an index from the block directories under blocksDir.
+func scanBlockIndex(blocksDir string) (*BlockIndex, error) {
+	entries, err := os.ReadDir(blocksDir)
+	if err != nil {
+		return nil, err
+	}
+	idx := newBlockIndex()
+	newest := make(map[int64]time.Time)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		height, ok := heightFromBlockDirName(entry.Name())
+		if !ok {
+			continue
+		}
+		if hash := blockDirHash(filepath.Join(blocksDir, entry.Name())); hash != "" {
+			idx.ByHash[hash] = entry.Name()
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if seen, ok := newest[height]; !ok || info.ModTime().After(seen) {
+			newest[height] = info.ModTime()
+			idx.ByHeight[height] = entry.Name()
+		}
+	}
+	// Index images only from the directory that won each height, so a
+	// stale copy of a block never shadows the live one.
+	for height, name := range idx.ByHeight {
+		for sum, fileName := range blockDirImageHashes(filepath.Join(blocksDir, name)) {
+			idx.ByImage[sum] = ImageLocation{Height: height, Dir: name, FileName: fileName}
+		}
+	}
+	return idx, nil
+}
+
+// blockDirImageHashes hashes the image files listed in dir's
+// inscriptions.json, keyed by sha256 hex.
+func blockDirImageHashes(dir string) map[string]string {
+	data, err := os.ReadFile(filepath.Join(dir, "inscriptions.json"))
+	if err != nil {
+		return nil
+	}
+	var summary struct {
+		Images []struct {
+			FileName string `json:"file_name"`
+		} `json:"images"`
+	}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil
+	}
+	out := make(map[string]string, len(summary.Images))
+	for _, img := range summary.Images {
+		path, err := security.ContainedFilePath(filepath.Join(dir, "images"), img.FileName)
+		if err != nil {
+			continue
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		sum := sha256.Sum256(raw)
+		out[hex.EncodeToString(sum[:])] = img.FileName
+	}
+	return out
+}
+
+// blockDirHash returns the full block hash recorded in dir's block.json,
+// falling back to inscriptions.json.
+func blockDirHash(dir string) string {
+	if hash, err := readBlockHeaderHash(filepath.Join(dir, "block.json")); err == nil && hash != "" {
+		return strings.ToLower(hash)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "inscriptions.json"))
+	if err != nil {
+		return ""
+	}
+	var summary struct {
+		BlockHash string `json:"block_hash"`
+	}
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(summary.BlockHash))
+}
+
+func writeBlockIndex(blocksDir string, idx *BlockIndex, mode os.FileMode) error {
+	idx.UpdatedAt = time.Now().Unix()
+	data, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal block index: %w", err)
+	}
+	path := filepath.Join(blocksDir, blockIndexFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, mode); err != nil {
+		return fmt.Errorf("failed to write block index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace block index: %w", err)
+	}
+	return nil
+}
+
+// updateBlockIndex applies fn to the current index (rebuilt from the
+// directory layout when missing or unreadable) and writes it back.
+func (bm *BlockMonitor) updateBlockIndex(fn func(idx *BlockIndex)) error {
+	blockIndexMu.Lock()
+	defer blockIndexMu.Unlock()
+
+	idx, err := LoadBlockIndex(bm.blocksDir)
+	if err != nil {
+		if idx, err = scanBlockIndex(bm.blocksDir); err != nil {
+			return err
+		}
+	}
+	fn(idx)
+	return writeBlockIndex(bm.blocksDir, idx, bm.fileMode)
+}
+
+// indexBlockDir records dirName as the directory for height and hash, and
+// as the home of images, which must carry the bytes saveImages wrote.
+func (bm *BlockMonitor) indexBlockDir(height int64, hash, dirName string, images ...ExtractedImageData) error {
+	return bm.updateBlockIndex(func(idx *BlockIndex) {
+		idx.ByHeight[height] = dirName
+		if hash = strings.ToLower(strings.TrimSpace(hash)); hash != "" {
+			idx.ByHash[hash] = dirName
+		}
+		for _, img := range images {
+			sum := sha256.Sum256(img.Data)
+			idx.ByImage[hex.EncodeToString(sum[:])] = ImageLocation{Height: height, Dir: dirName, FileName: img.FileName}
+		}
+	})
+}
+
+// unindexBlockDirs drops every index entry pointing at one of dirNames.
+func (bm *BlockMonitor) unindexBlockDirs(dirNames ...string) error {
+	drop := make(map[string]bool, len(dirNames))
+	for _, name := range dirNames {
+		drop[name] = true
+	}
+	return bm.updateBlockIndex(func(idx *BlockIndex) {
+		for height, name := range idx.ByHeight {
+			if drop[name] {
+				delete(idx.ByHeight, height)
+			}
+		}
+		for hash, name := range idx.ByHash {
+			if drop[name] {
+				delete(idx.ByHash, hash)
+			}
+		}
+		for sum, loc := range idx.ByImage {
+			if drop[loc.Dir] {
+				delete(idx.ByImage, sum)
+			}
+		}
+	})
+}
+
+// ensureBlockIndex rebuilds index.json at start-up when it is missing or
+// was written before images were indexed.
+func (bm *BlockMonitor) ensureBlockIndex() {
+	data, err := os.ReadFile(filepath.Join(bm.blocksDir, blockIndexFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err == nil {
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			return
+		}
+		if _, ok := fields["by_image"]; ok {
+			return
+		}
+	}
+	idx, err := RebuildBlockIndex(bm.blocksDir, bm.fileMode)
+	if err != nil {
+		log.Printf("Failed to rebuild block index: %v", err)
+		return
+	}
+	log.Printf("Rebuilt block index: %d heights, %d hashes, %d images", len(idx.ByHeight), len(idx.ByHash), len(idx.ByImage))
+}
+
+// indexedBlockDir returns the indexed directory for key when it still exists.
+func indexedBlockDir(blocksDir string, lookup func(idx *BlockIndex) string) (string, bool) {
+	idx, err := LoadBlockIndex(blocksDir)
+	if err != nil {
+		return "", false
+	}
+	name := lookup(idx)
+	if name == "" {
+		return "", false
+	}
+	dir := filepath.Join(blocksDir, name)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
+
+// FindBlockDirectoryByHash locates the directory of the block with the given
+// full hash, using the index and falling back to a directory scan.
+func FindBlockDirectoryByHash(blocksDir, hash string) (string, error) {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	if hash == "" {
+		return "", fmt.Errorf("empty block hash")
+	}
+	if dir, ok := indexedBlockDir(blocksDir, func(idx *BlockIndex) string { return idx.ByHash[hash] }); ok {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(blocksDir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, ok := heightFromBlockDirName(entry.Name()); !ok {
+			continue
+		}
+		dir := filepath.Join(blocksDir, entry.Name())
+		if blockDirHash(dir) == hash {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("block directory not found for hash %s", hash)
+}
@@ -0,0 +1,120 @@
+package bitcoin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+func TestBlockIndexResolvesHeightAndHash(t *testing.T) {
+	source := newFakeRawSource()
+	hashes := source.addChain(t, 1200, 1201, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	for h := int64(1200); h <= 1201; h++ {
+		if err := bm.ProcessBlock(h); err != nil {
+			t.Fatalf("ProcessBlock(%d): %v", h, err)
+		}
+	}
+
+	idx, err := LoadBlockIndex(bm.blocksDir)
+	if err != nil {
+		t.Fatalf("index.json not written: %v", err)
+	}
+	if len(idx.ByHeight) != 2 || len(idx.ByHash) != 2 {
+		t.Fatalf("index = %+v, want 2 heights and 2 hashes", idx)
+	}
+
+	// Rename the directory away from the <height>_<hash8> pattern so only the
+	// index can resolve it.
+	oldDir, err := bm.findBlockDirectory(1201)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const renamed = "custom-block-dir"
+	if err := os.Rename(oldDir, filepath.Join(bm.blocksDir, renamed)); err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.unindexBlockDirs(filepath.Base(oldDir)); err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.indexBlockDir(1201, hashes[1201], renamed); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(bm.blocksDir, renamed)
+
+	if dir, err := FindBlockDirectory(bm.blocksDir, 1201); err != nil || dir != want {
+		t.Fatalf("FindBlockDirectory(1201) = %q, %v; want %q", dir, err, want)
+	}
+	if dir, err := FindBlockDirectoryByHash(bm.blocksDir, hashes[1201]); err != nil || dir != want {
+		t.Fatalf("FindBlockDirectoryByHash = %q, %v; want %q", dir, err, want)
+	}
+	dir, err := FindBlockDirectoryByHash(bm.blocksDir, hashes[1200])
+	if err != nil {
+		t.Fatalf("FindBlockDirectoryByHash(1200): %v", err)
+	}
+	if got, _ := FindBlockDirectory(bm.blocksDir, 1200); got != dir {
+		t.Fatalf("height and hash lookups disagree: %q vs %q", got, dir)
+	}
+}
+
+func TestEnsureBlockIndexRebuildsMissingIndex(t *testing.T) {
+	source := newFakeRawSource()
+	hashes := source.addChain(t, 1300, 1300, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	if err := bm.ProcessBlock(1300); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	dir, err := bm.findBlockDirectory(1300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(bm.blocksDir, blockIndexFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	bm.ensureBlockIndex()
+
+	idx, err := LoadBlockIndex(bm.blocksDir)
+	if err != nil {
+		t.Fatalf("index not rebuilt: %v", err)
+	}
+	if got := idx.ByHeight[1300]; got != filepath.Base(dir) {
+		t.Fatalf("by_height[1300] = %q, want %q", got, filepath.Base(dir))
+	}
+	if got := idx.ByHash[hashes[1300]]; got != filepath.Base(dir) {
+		t.Fatalf("by_hash = %q, want %q", got, filepath.Base(dir))
+	}
+}
+
+func TestEnsureBlockIndexRebuildsIndexWithoutImages(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 2}
+	source.addChain(t, 1310, 1310, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.notificationSinks = nil
+	if err := bm.ProcessBlock(1310); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	idx, err := LoadBlockIndex(bm.blocksDir)
+	if err != nil || len(idx.ByImage) != 2 {
+		t.Fatalf("by_image after ProcessBlock = %+v, %v; want 2 images", idx, err)
+	}
+
+	// An index written before images were indexed has no by_image key.
+	legacy := `{"by_height":{"1310":"` + idx.ByHeight[1310] + `"},"by_hash":{}}`
+	if err := os.WriteFile(filepath.Join(bm.blocksDir, blockIndexFile), []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bm.ensureBlockIndex()
+
+	rebuilt, err := LoadBlockIndex(bm.blocksDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for sum, loc := range idx.ByImage {
+		if rebuilt.ByImage[sum] != loc {
+			t.Fatalf("by_image[%s] = %+v, want %+v", sum, rebuilt.ByImage[sum], loc)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package bitcoin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"stargate-backend/security"
+)
+
+// blockSummaryFile mirrors the inscriptions.json layout written by
+// saveBlockSummaryWithScanResults, reusing the monitor's own structs.
+type blockSummaryFile struct {
+	BlockHeight       *int64              `json:"block_height"`
+	BlockHash         string              `json:"block_hash"`
+	Timestamp         int64               `json:"timestamp"`
+	TotalTransactions int                 `json:"total_transactions"`
+	Inscriptions      []InscriptionData   `json:"inscriptions"`
+	Images            []blockSummaryImage `json:"images"`
+	SmartContracts    []SmartContractData `json:"smart_contracts"`
+	ProcessingTime    int64               `json:"processing_time_ms"`
+	Success           *bool               `json:"success"`
+	SteganographyScan map[string]any      `json:"steganography_scan,omitempty"`
+}
+
+type blockSummaryImage struct {
+	ExtractedImageData
+	ScanResult map[string]any `json:"scan_result"`
+}
+
+// BlockDirImage describes one extracted image referenced by a block directory.
+type BlockDirImage struct {
+	FileName   string  `json:"file_name"`
+	Format     string  `json:"format"`
+	SizeBytes  int64   `json:"size_bytes"`
+	SHA256     string  `json:"sha256,omitempty"`
+	OnDisk     bool    `json:"on_disk"`
+	IsStego    bool    `json:"is_stego"`
+	Confidence float64 `json:"confidence"`
+	ScanError  string  `json:"scan_error,omitempty"`
+}
+
+// BlockDirReport summarises and validates a stored block directory.
+type BlockDirReport struct {
+	Dir               string          `json:"dir"`
+	Height            int64           `json:"height"`
+	Hash              string          `json:"hash"`
+	Timestamp         int64           `json:"timestamp"`
+	TotalTransactions int             `json:"total_transactions"`
+	InscriptionCount  int             `json:"inscription_count"`
+	SmartContracts    int             `json:"smart_contracts"`
+	StegoCount        int             `json:"stego_count"`
+	ScanErrors        int             `json:"scan_errors"`
+	Images            []BlockDirImage `json:"images"`
+	Problems          []string        `json:"problems,omitempty"`
+}
+
+// Valid reports whether the directory passed every schema check.
+func (r *BlockDirReport) Valid() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *BlockDirReport) problemf(format string, args ...any) {
+	r.Problems = append(r.Problems, fmt.Sprintf(format, args...))
+}
+
+// FindBlockDirectory locates the directory for a height under blocksDir,
+// consulting index.json first and falling back to the <height>_<hash> names.
+func FindBlockDirectory(blocksDir string, height int64) (string, error) {
+	if dir, ok := indexedBlockDir(blocksDir, func(idx *BlockIndex) string { return idx.ByHeight[height] }); ok {
+		return dir, nil
+	}
+	entries, err := os.ReadDir(blocksDir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			// Extract height from directory name (format: height_hash)
+			parts := strings.Split(entry.Name(), "_")
+			if len(parts) >= 1 {
+				if dirHeight, err := strconv.ParseInt(parts[0], 10, 64); err == nil && dirHeight == height {
+					return filepath.Join(blocksDir, entry.Name()), nil
+				}
+			}
+		}
+	}
+
+	return "", fmt.Errorf("block directory not found for height %d", height)
+}
+
+// InspectBlockDirectory validates inscriptions.json (and block.json when present)
+// in dir against the schema the block monitor writes, hashes the extracted images
+// and summarises the stego scan results. Schema problems are reported on the
+// returned report; an error is only returned when the summary cannot be read.
+func InspectBlockDirectory(dir string) (*BlockDirReport, error) {
+	report := &BlockDirReport{Dir: dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, "inscriptions.json"))
+	if err != nil {
+		return nil, fmt.Errorf("read inscriptions.json: %w", err)
+	}
+	var summary blockSummaryFile
+	if err := json.Unmarshal(data, &summary); err != nil {
+		report.problemf("inscriptions.json: %v", err)
+		return report, nil
+	}
+
+	if summary.BlockHeight == nil {
+		report.problemf("block_height missing")
+	} else {
+		report.Height = *summary.BlockHeight
+	}
+	report.Hash = summary.BlockHash
+	report.Timestamp = summary.Timestamp
+	report.TotalTransactions = summary.TotalTransactions
+	report.InscriptionCount = len(summary.Inscriptions)
+	report.SmartContracts = len(summary.SmartContracts)
+
+	if decoded, err := hex.DecodeString(summary.BlockHash); err != nil || len(decoded) != 32 {
+		report.problemf("block_hash %q is not a 32-byte hex hash", summary.BlockHash)
+	}
+	if summary.Success == nil {
+		report.problemf("success flag missing")
+	}
+	if summary.TotalTransactions <= 0 {
+		report.problemf("total_transactions must be positive, got %d", summary.TotalTransactions)
+	}
+
+	// Directory name must agree with the summary (<height>_<hash prefix>).
+	name := filepath.Base(dir)
+	if prefix, suffix, ok := strings.Cut(name, "_"); ok {
+		if summary.BlockHeight != nil && prefix != strconv.FormatInt(*summary.BlockHeight, 10) {
+			report.problemf("directory height %s does not match block_height %d", prefix, *summary.BlockHeight)
+		}
+		if summary.BlockHash != "" && !strings.HasPrefix(summary.BlockHash, suffix) {
+			report.problemf("directory hash prefix %s does not match block_hash %s", suffix, summary.BlockHash)
+		}
+	} else {
+		report.problemf("directory name %q is not <height>_<hash>", name)
+	}
+
+	if headerHash, err := readBlockHeaderHash(filepath.Join(dir, "block.json")); err == nil {
+		if headerHash != summary.BlockHash {
+			report.problemf("block.json hash %s does not match block_hash %s", headerHash, summary.BlockHash)
+		}
+	} else if !os.IsNotExist(err) {
+		report.problemf("block.json: %v", err)
+	}
+
+	imagesDir := filepath.Join(dir, "images")
+	for i, img := range summary.Images {
+		entry := BlockDirImage{
+			FileName:  img.FileName,
+			Format:    img.Format,
+			SizeBytes: int64(img.SizeBytes),
+		}
+		if img.FileName == "" {
+			report.problemf("images[%d]: file_name missing", i)
+		} else if path, err := security.ContainedFilePath(imagesDir, img.FileName); err != nil {
+			report.problemf("images[%d]: %v", i, err)
+		} else if raw, err := os.ReadFile(path); err == nil {
+			sum := sha256.Sum256(raw)
+			entry.SHA256 = hex.EncodeToString(sum[:])
+			entry.SizeBytes = int64(len(raw))
+			entry.OnDisk = true
+		}
+		if img.ScanResult == nil {
+			report.problemf("images[%d]: scan_result missing", i)
+		} else {
+			entry.IsStego, _ = img.ScanResult["is_stego"].(bool)
+			entry.Confidence = confidenceFromAny(img.ScanResult["confidence"])
+			entry.ScanError = stringFromAny(img.ScanResult["scan_error"])
+		}
+		if entry.IsStego {
+			report.StegoCount++
+		}
+		if entry.ScanError != "" {
+			report.ScanErrors++
+		}
+		report.Images = append(report.Images, entry)
+	}
+
+	if summary.SteganographyScan != nil {
+		if declared, ok := intFromAny(summary.SteganographyScan["stego_count"]); ok && declared != report.StegoCount {
+			report.problemf("steganography_scan.stego_count %d does not match %d stego images", declared, report.StegoCount)
+		}
+	}
+
+	return report, nil
+}
+
+// completeBlockDirectory returns the directory for height when it holds a
+// summary that passes InspectBlockDirectory, i.e. the block needs no refetch.
+func (bm *BlockMonitor) completeBlockDirectory(height int64) (string, bool) {
+	dir, err := FindBlockDirectory(bm.blocksDir, height)
+	if err != nil {
+		return "", false
+	}
+	report, err := InspectBlockDirectory(dir)
+	if err != nil || !report.Valid() {
+		return dir, false
+	}
+	return dir, true
+}
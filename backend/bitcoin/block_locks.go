@@ -0,0 +1,68 @@
+package bitcoin
+
+import "sync"
+
+// heightLocks hands out one mutex per block height so that the monitor loop,
+// backfills, reorg handling and admin-triggered ProcessBlock calls never work
+// on the same block directory at once, while different heights still proceed
+// in parallel. The zero value is ready to use.
+type heightLocks struct {
+	mu    sync.Mutex
+	locks map[int64]*heightLock
+}
+
+type heightLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock blocks until height is free and returns the matching unlock function.
+func (h *heightLocks) lock(height int64) func() {
+	h.mu.Lock()
+	if h.locks == nil {
+		h.locks = make(map[int64]*heightLock)
+	}
+	l, ok := h.locks[height]
+	if !ok {
+		l = &heightLock{}
+		h.locks[height] = l
+	}
+	l.refs++
+	h.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		h.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(h.locks, height)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// tryLock takes the lock for height only if no one holds or is waiting for
+// it, returning ok=false instead of blocking.
+func (h *heightLocks) tryLock(height int64) (unlock func(), ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, busy := h.locks[height]; busy {
+		return nil, false
+	}
+	if h.locks == nil {
+		h.locks = make(map[int64]*heightLock)
+	}
+	l := &heightLock{refs: 1}
+	l.mu.Lock()
+	h.locks[height] = l
+	return func() {
+		l.mu.Unlock()
+		h.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(h.locks, height)
+		}
+		h.mu.Unlock()
+	}, true
+}
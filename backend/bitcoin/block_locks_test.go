@@ -0,0 +1,85 @@
+package bitcoin
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// Run with -race: concurrent ProcessBlock calls must neither duplicate work on a
+// height nor race on the statistics counters.
+func TestProcessBlockConcurrentSameAndDifferentHeights(t *testing.T) {
+	source := newFakeRawSource()
+	source.addChain(t, 1000, 1003, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+
+	calls := []int64{1000, 1000, 1000, 1000, 1001, 1001, 1002, 1002, 1003, 1003}
+	var wg sync.WaitGroup
+	for _, height := range calls {
+		wg.Add(1)
+		go func(height int64) {
+			defer wg.Done()
+			if err := bm.ProcessBlock(height); err != nil {
+				t.Errorf("ProcessBlock(%d): %v", height, err)
+			}
+		}(height)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			bm.GetStatistics()
+		}
+	}()
+	wg.Wait()
+
+	for height := int64(1000); height <= 1003; height++ {
+		if got := source.fetchCount(height); got != 1 {
+			t.Fatalf("block %d fetched %d times, want 1", height, got)
+		}
+	}
+	stats := bm.GetStatistics()
+	if stats["total_transactions"] != int64(4) || stats["blocks_skipped"] != int64(6) {
+		t.Fatalf("stats = %+v, want 4 transactions and 6 skips", stats)
+	}
+}
+
+func TestHeightLocksReleaseEntries(t *testing.T) {
+	var locks heightLocks
+	unlock := locks.lock(7)
+	done := make(chan struct{})
+	go func() {
+		locks.lock(7)()
+		close(done)
+	}()
+	unlock()
+	<-done
+	if len(locks.locks) != 0 {
+		t.Fatalf("%d height locks left behind", len(locks.locks))
+	}
+}
+
+func TestHeightLocksTryLock(t *testing.T) {
+	var locks heightLocks
+	unlock := locks.lock(7)
+	if _, ok := locks.tryLock(7); ok {
+		t.Fatal("tryLock took a held height")
+	}
+	unlock()
+
+	unlockTry, ok := locks.tryLock(7)
+	if !ok {
+		t.Fatal("tryLock failed on a free height")
+	}
+	done := make(chan struct{})
+	go func() {
+		locks.lock(7)()
+		close(done)
+	}()
+	unlockTry()
+	<-done
+	if len(locks.locks) != 0 {
+		t.Fatalf("%d height locks left behind", len(locks.locks))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -28,12 +29,14 @@ import (
 	"stargate-backend/storage/ipfs"
 	"stargate-backend/security"
 	"stargate-backend/services"
+	"stargate-backend/starlight"
+	"stargate-backend/stego"
 )
 
 // BlockMonitor handles comprehensive Bitcoin block monitoring and data extraction
 type BlockMonitor struct {
 	bitcoinClient   *BitcoinNodeClient
-	rawClient       *RawBlockClient
+	rawClient       RawBlockSource
 	bitcoinAPI      *BitcoinAPI
 	currentHeight   int64
 	lastChecked     time.Time
@@ -45,26 +48,55 @@ type BlockMonitor struct {
 	sweepStore      SweepTaskStore
 	sweepMempool    *MempoolClient
 	stegoReconciler StegoReconciler
+	imageScanner    ImageScanner
 	unpinPath       func(context.Context, string) error
 	ipfsClient      *ipfs.Client
 	reconcileMu     sync.Mutex
+	processLocks    heightLocks // serialises processBlock per height
+	backfillMu      sync.Mutex
+	backfillCancel  context.CancelFunc // cancels the StartBackfill run; called by Stop
+	heightFileMu    sync.Mutex         // serialises writes of the persisted monitor height
+	loops           sync.WaitGroup     // goroutines started by Start and StartBackfill
+	backfill        *BackfillProgress
 
 	// Configuration
-	checkInterval time.Duration
-	blocksDir     string
-	maxRetries    int
-	retryDelay    time.Duration
+	checkInterval  time.Duration
+	blockDelay     time.Duration // pause between consecutive block fetches
+	scanTimeout    time.Duration // per-image scanner budget; 0 disables
+	crossCheckHash bool          // compare fetched hashes with the node client's block-height endpoint
+	blocksDir      string
+	dirMode        os.FileMode    // permissions for directories created under blocksDir
+	fileMode       os.FileMode    // permissions for files written under blocksDir
+	embedMaxBytes  int            // >0 embeds image content up to this size as base64 in inscriptions.json
+	scanProbs      bool           // keep the scanner's per-class probabilities in scan results
+	scanReadyWait  time.Duration  // how long monitoring waits for the scanner before the first scan; 0 skips the wait
+	scanReadyDefer bool           // keep waiting past scanReadyWait instead of monitoring without the scanner
+	retention      BlockRetention // pruning policy for old block directories; zero keeps everything
+	maxRetries     int
+	retryDelay     time.Duration
+	heightSources  []HeightSource // chain tip sources, tried in order
 
 	// Callbacks
-	onBlockProcessed []func(height int64)
-
-	// Statistics
-	blocksProcessed int64
-	totalTransactions   int64
-	totalImages         int64
-	totalStegoContracts int64
-	totalInscriptions   int64
-	lastProcessTime     time.Duration
+	onBlockProcessed  []func(height int64)
+	onMonitorEvent    []func(MonitorEvent)
+	notificationSinks []NotificationSink
+
+	// stegoContracts de-duplicates detections across blocks by visible_pixel_hash.
+	stegoContracts map[string]SmartContractData
+
+	// Statistics, guarded by mu (see block_stats.go)
+	blocksProcessed          int64
+	blocksSkipped            int64
+	reorgsHandled            int64
+	lastTipHeight            int64 // last tip accepted from a height source
+	rawBlockRetries          int64 // rate-limited downloads retried
+	rawBlockRetriesExhausted int64 // downloads still rate limited after maxRetries
+	rawBlockNotFound         int64 // heights the raw block sources answered 404 for
+	totalTransactions        int64
+	totalImages              int64
+	totalStegoContracts      int64
+	totalInscriptions        int64
+	lastProcessTime          time.Duration
 }
 
 // reconcileSweepInterval / reconcileSweepBlocks control the periodic safety-net
@@ -88,19 +120,22 @@ type BlockData struct {
 
 // TransactionData represents transaction information
 type TransactionData struct {
-	TxID        string     `json:"tx_id"`
-	Height      int        `json:"height"`
-	Time        int64      `json:"time"`
-	Status      string     `json:"status"`
-	VOut        []VOut     `json:"vout"`
-	VIn         []Vin      `json:"vin"`
-	WitnessSize int        `json:"witness_size"`
-	Inputs      []TxInput  `json:"inputs"`
-	Outputs     []TxOutput `json:"outputs"`
-	HasImages   bool       `json:"has_images"`
-	ImageCount  int        `json:"image_count"`
-	TextContent []string   `json:"text_content"`
-	HexData     []string   `json:"hex_data"`
+	TxID             string           `json:"tx_id"`
+	Height           int              `json:"height"`
+	Time             int64            `json:"time"`
+	Status           string           `json:"status"`
+	VOut             []VOut           `json:"vout"`
+	VIn              []Vin            `json:"vin"`
+	WitnessSize      int              `json:"witness_size"`
+	InputCount       int              `json:"input_count"`
+	OutputCount      int              `json:"output_count"`
+	Inputs           []TxInputDetail  `json:"inputs"`
+	Outputs          []TxOutputDetail `json:"outputs"`
+	DetailsTruncated bool             `json:"details_truncated,omitempty"`
+	HasImages        bool             `json:"has_images"`
+	ImageCount       int              `json:"image_count"`
+	TextContent      []string         `json:"text_content"`
+	HexData          []string         `json:"hex_data"`
 }
 
 // WitnessData represents extracted witness data
@@ -113,6 +148,7 @@ type WitnessData struct {
 	ImageCount  int      `json:"image_count"`
 	TextContent []string `json:"text_content"`
 	HexData     []string `json:"hex_data"`
+	Truncated   bool     `json:"truncated,omitempty"`
 }
 
 // InscriptionData represents inscription information
@@ -128,11 +164,39 @@ type InscriptionData struct {
 
 // SmartContractData represents smart contract information
 type SmartContractData struct {
-	ContractID  string         `json:"contract_id"`
-	BlockHeight int64          `json:"block_height"`
-	ImagePath   string         `json:"image_path"`
-	Confidence  float64        `json:"confidence"`
-	Metadata    map[string]any `json:"metadata"`
+	ContractID           string         `json:"contract_id"`
+	BlockHeight          int64          `json:"block_height"`
+	ImagePath            string         `json:"image_path"`
+	Confidence           float64        `json:"confidence"`
+	Metadata             map[string]any `json:"metadata"`
+	FirstSeenAt          int64          `json:"first_seen_at,omitempty"` // unix seconds
+	Source               string         `json:"source,omitempty"`
+	ReconciliationStatus string         `json:"reconciliation_status,omitempty"` // see Reconciliation* constants
+}
+
+// Creation paths recorded in SmartContractData.Source.
+const (
+	ContractSourceBlockScan = "block_scan"
+	ContractSourceIngestion = "ingestion"
+)
+
+// Reconciliation outcomes recorded in SmartContractData.ReconciliationStatus.
+const (
+	// ReconciliationUnmatched: stego was detected but no ingestion record matched.
+	ReconciliationUnmatched = "unmatched"
+	// ReconciliationHashMatched: an ingestion record matched the visible pixel
+	// hash, but the payout script or image could not be linked.
+	ReconciliationHashMatched = "hash_matched"
+	// ReconciliationFullyMatched: the contract is linked to its ingestion record
+	// and funding transaction.
+	ReconciliationFullyMatched = "fully_matched"
+)
+
+// RawBlockSource downloads and parses raw blocks by height.
+// RawBlockClient is the production implementation.
+type RawBlockSource interface {
+	GetRawBlockHex(height int64) (string, error)
+	ParseBlock(hexData string) (*ParsedBlock, error)
 }
 
 // StegoReconciler runs a stego reconcile given a CID + expected hash.
@@ -187,44 +251,80 @@ type BlockInscriptionsResponse struct {
 // NewBlockMonitor creates a new block monitor
 func NewBlockMonitor(client *BitcoinNodeClient) *BlockMonitor {
 	return &BlockMonitor{
-		bitcoinClient: client,
-		rawClient:     NewRawBlockClient(client.GetNetwork()),
-		checkInterval: 5 * time.Minute, // Check every 5 minutes
-		blocksDir:     blocksDirFromEnv(),
-		maxRetries:    3,
-		retryDelay:    10 * time.Second,
-		lastChecked:   time.Now(),
-		ipfsClient:    ipfs.NewClientFromEnv(),
+		bitcoinClient:     client,
+		rawClient:         NewRawBlockClient(client.GetNetwork()),
+		checkInterval:     5 * time.Minute, // Check every 5 minutes
+		blockDelay:        5 * time.Second,
+		scanTimeout:       scanImageTimeoutFromEnv(),
+		crossCheckHash:    crossCheckHashEnabled(),
+		heightSources:     heightSourcesFromEnv(client),
+		blocksDir:         blocksDirFromEnv(),
+		dirMode:           blocksDirModeFromEnv(),
+		fileMode:          blocksFileModeFromEnv(),
+		embedMaxBytes:     summaryEmbedLimitFromEnv(),
+		scanProbs:         scanProbabilitiesFromEnv(),
+		scanReadyWait:     scannerReadyTimeoutFromEnv(),
+		scanReadyDefer:    scannerReadyDeferFromEnv(),
+		retention:         blockRetentionFromEnv(),
+		maxRetries:        3,
+		retryDelay:        10 * time.Second,
+		lastChecked:       time.Now(),
+		ipfsClient:        ipfs.NewClientFromEnv(),
+		notificationSinks: notificationSinksFromEnv(),
 	}
 }
 
 // NewBlockMonitorWithStorage creates a new block monitor with data storage
 func NewBlockMonitorWithStorage(client *BitcoinNodeClient, dataStorage DataStorageInterface) *BlockMonitor {
 	return &BlockMonitor{
-		bitcoinClient: client,
-		rawClient:     NewRawBlockClient(client.GetNetwork()),
-		dataStorage:   dataStorage,
-		checkInterval: 5 * time.Minute, // Check every 5 minutes
-		blocksDir:     blocksDirFromEnv(),
-		maxRetries:    3,
-		retryDelay:    10 * time.Second,
-		lastChecked:   time.Now(),
-		ipfsClient:    ipfs.NewClientFromEnv(),
+		bitcoinClient:     client,
+		rawClient:         NewRawBlockClient(client.GetNetwork()),
+		dataStorage:       dataStorage,
+		checkInterval:     5 * time.Minute, // Check every 5 minutes
+		blockDelay:        5 * time.Second,
+		scanTimeout:       scanImageTimeoutFromEnv(),
+		crossCheckHash:    crossCheckHashEnabled(),
+		heightSources:     heightSourcesFromEnv(client),
+		blocksDir:         blocksDirFromEnv(),
+		dirMode:           blocksDirModeFromEnv(),
+		fileMode:          blocksFileModeFromEnv(),
+		embedMaxBytes:     summaryEmbedLimitFromEnv(),
+		scanProbs:         scanProbabilitiesFromEnv(),
+		scanReadyWait:     scannerReadyTimeoutFromEnv(),
+		scanReadyDefer:    scannerReadyDeferFromEnv(),
+		retention:         blockRetentionFromEnv(),
+		maxRetries:        3,
+		retryDelay:        10 * time.Second,
+		lastChecked:       time.Now(),
+		ipfsClient:        ipfs.NewClientFromEnv(),
+		notificationSinks: notificationSinksFromEnv(),
 	}
 }
 
 // NewBlockMonitorWithAPI creates a new block monitor with Bitcoin API
 func NewBlockMonitorWithAPI(client *BitcoinNodeClient, bitcoinAPI *BitcoinAPI) *BlockMonitor {
 	return &BlockMonitor{
-		bitcoinClient: client,
-		rawClient:     NewRawBlockClient(client.GetNetwork()),
-		bitcoinAPI:    bitcoinAPI,
-		checkInterval: 5 * time.Minute, // Check every 5 minutes
-		blocksDir:     blocksDirFromEnv(),
-		maxRetries:    3,
-		retryDelay:    10 * time.Second,
-		lastChecked:   time.Now(),
-		ipfsClient:    ipfs.NewClientFromEnv(),
+		bitcoinClient:     client,
+		rawClient:         NewRawBlockClient(client.GetNetwork()),
+		bitcoinAPI:        bitcoinAPI,
+		checkInterval:     5 * time.Minute, // Check every 5 minutes
+		blockDelay:        5 * time.Second,
+		scanTimeout:       scanImageTimeoutFromEnv(),
+		crossCheckHash:    crossCheckHashEnabled(),
+		heightSources:     heightSourcesFromEnv(client),
+		blocksDir:         blocksDirFromEnv(),
+		dirMode:           blocksDirModeFromEnv(),
+		fileMode:          blocksFileModeFromEnv(),
+		embedMaxBytes:     summaryEmbedLimitFromEnv(),
+		scanProbs:         scanProbabilitiesFromEnv(),
+		scanReadyWait:     scannerReadyTimeoutFromEnv(),
+		scanReadyDefer:    scannerReadyDeferFromEnv(),
+		retention:         blockRetentionFromEnv(),
+		maxRetries:        3,
+		retryDelay:        10 * time.Second,
+		lastChecked:       time.Now(),
+		ipfsClient:        ipfs.NewClientFromEnv(),
+		notificationSinks: notificationSinksFromEnv(),
 	}
 }
 
@@ -232,16 +332,28 @@ func NewBlockMonitorWithAPI(client *BitcoinNodeClient, bitcoinAPI *BitcoinAPI) *
 func NewBlockMonitorWithStorageAndAPI(client *BitcoinNodeClient, dataStorage DataStorageInterface, bitcoinAPI *BitcoinAPI) *BlockMonitor {
 	log.Printf("Creating block monitor with bitcoinAPI set: %v", bitcoinAPI != nil)
 	return &BlockMonitor{
-		bitcoinClient: client,
-		rawClient:     NewRawBlockClient(client.GetNetwork()),
-		dataStorage:   dataStorage,
-		bitcoinAPI:    bitcoinAPI,
-		checkInterval: 5 * time.Minute, // Check every 5 minutes
-		blocksDir:     blocksDirFromEnv(),
-		maxRetries:    3,
-		retryDelay:    10 * time.Second,
-		lastChecked:   time.Now(),
-		ipfsClient:    ipfs.NewClientFromEnv(),
+		bitcoinClient:     client,
+		rawClient:         NewRawBlockClient(client.GetNetwork()),
+		dataStorage:       dataStorage,
+		bitcoinAPI:        bitcoinAPI,
+		checkInterval:     5 * time.Minute, // Check every 5 minutes
+		blockDelay:        5 * time.Second,
+		scanTimeout:       scanImageTimeoutFromEnv(),
+		crossCheckHash:    crossCheckHashEnabled(),
+		heightSources:     heightSourcesFromEnv(client),
+		blocksDir:         blocksDirFromEnv(),
+		dirMode:           blocksDirModeFromEnv(),
+		fileMode:          blocksFileModeFromEnv(),
+		embedMaxBytes:     summaryEmbedLimitFromEnv(),
+		scanProbs:         scanProbabilitiesFromEnv(),
+		scanReadyWait:     scannerReadyTimeoutFromEnv(),
+		scanReadyDefer:    scannerReadyDeferFromEnv(),
+		retention:         blockRetentionFromEnv(),
+		maxRetries:        3,
+		retryDelay:        10 * time.Second,
+		lastChecked:       time.Now(),
+		ipfsClient:        ipfs.NewClientFromEnv(),
+		notificationSinks: notificationSinksFromEnv(),
 	}
 }
 
@@ -259,6 +371,11 @@ func (bm *BlockMonitor) SetIPFSUnpin(unpin func(context.Context, string) error)
 	bm.unpinPath = unpin
 }
 
+// SetRawBlockSource replaces the upstream raw block source (primarily for tests).
+func (bm *BlockMonitor) SetRawBlockSource(source RawBlockSource) {
+	bm.rawClient = source
+}
+
 // OnBlockProcessed registers a callback invoked after a block is successfully processed.
 func (bm *BlockMonitor) OnBlockProcessed(fn func(height int64)) {
 	bm.onBlockProcessed = append(bm.onBlockProcessed, fn)
@@ -284,14 +401,19 @@ func (bm *BlockMonitor) Start() error {
 	bm.stopChan = make(chan bool)
 
 	// Create blocks directory
-	if err := os.MkdirAll(bm.blocksDir, 0755); err != nil {
+	if err := os.MkdirAll(bm.blocksDir, bm.dirMode); err != nil {
 		return fmt.Errorf("failed to create blocks directory: %w", err)
 	}
+	bm.ensureBlockIndex()
+	bm.restoreMonitorHeight()
 
 	log.Printf("Starting block monitor with %s interval, bitcoinAPI set: %v", bm.checkInterval, bm.bitcoinAPI != nil)
 
-	go bm.monitorLoop()
-	go bm.reconcileSweepLoop()
+	bm.goLoop(bm.monitorLoop)
+	bm.goLoop(bm.reconcileSweepLoop)
+	if bm.retention.Enabled() {
+		bm.goLoop(bm.pruneLoop)
+	}
 
 	return nil
 }
@@ -301,6 +423,7 @@ func (bm *BlockMonitor) Stop() error {
 	bm.mu.Lock()
 	defer bm.mu.Unlock()
 
+	bm.cancelBackfill()
 	if !bm.isRunning {
 		return fmt.Errorf("block monitor is not running")
 	}
@@ -321,24 +444,35 @@ func (bm *BlockMonitor) IsRunning() bool {
 
 // GetStatistics returns current monitoring statistics
 func (bm *BlockMonitor) GetStatistics() map[string]any {
+	bm.ensureStegoContracts()
 	bm.mu.RLock()
 	defer bm.mu.RUnlock()
 
 	return map[string]any{
-		"blocks_processed":      bm.blocksProcessed,
-		"total_transactions":    bm.totalTransactions,
-		"total_images":          bm.totalImages,
-		"total_stego_contracts": bm.totalStegoContracts,
-		"total_inscriptions":    bm.totalInscriptions,
-		"current_height":        bm.currentHeight,
-		"last_process_time":     bm.lastProcessTime.Milliseconds(),
-		"is_running":            bm.isRunning,
-		"check_interval":        bm.checkInterval.Milliseconds(),
+		"blocks_processed":            bm.blocksProcessed,
+		"blocks_skipped":              bm.blocksSkipped,
+		"reorgs_handled":              bm.reorgsHandled,
+		"raw_block_retries":           bm.rawBlockRetries,
+		"raw_block_retries_exhausted": bm.rawBlockRetriesExhausted,
+		"raw_block_not_found":         bm.rawBlockNotFound,
+		"total_transactions":          bm.totalTransactions,
+		"total_images":                bm.totalImages,
+		"total_stego_contracts":       bm.totalStegoContracts,
+		"unique_stego_contracts":      len(bm.stegoContracts),
+		"total_inscriptions":          bm.totalInscriptions,
+		"current_height":              bm.currentHeight,
+		"last_process_time":           bm.lastProcessTime.Milliseconds(),
+		"is_running":                  bm.isRunning,
+		"check_interval":              bm.checkInterval.Milliseconds(),
 	}
 }
 
 // monitorLoop runs the main monitoring loop
 func (bm *BlockMonitor) monitorLoop() {
+	if !bm.awaitScannerReady() {
+		return
+	}
+
 	ticker := time.NewTicker(bm.checkInterval)
 	defer ticker.Stop()
 
@@ -369,7 +503,7 @@ func (bm *BlockMonitor) updateRecentBlocksSummary() error {
 	}
 
 	// Ensure the directory exists so we don't fail with a missing relative path when running in a container.
-	if err := os.MkdirAll(blocksDir, 0755); err != nil {
+	if err := os.MkdirAll(blocksDir, bm.dirMode); err != nil {
 		return fmt.Errorf("failed to ensure blocks directory: %w", err)
 	}
 
@@ -430,7 +564,7 @@ func (bm *BlockMonitor) updateRecentBlocksSummary() error {
 	}
 
 	summaryPath := filepath.Join(blocksDir, "recent-blocks.json")
-	if err := os.WriteFile(summaryPath, summaryJSON, 0644); err != nil {
+	if err := os.WriteFile(summaryPath, summaryJSON, bm.fileMode); err != nil {
 		return fmt.Errorf("failed to write recent blocks summary: %w", err)
 	}
 
@@ -440,23 +574,24 @@ func (bm *BlockMonitor) updateRecentBlocksSummary() error {
 
 // checkForNewBlocks checks for and processes new blocks more efficiently
 func (bm *BlockMonitor) checkForNewBlocks() error {
-	// Get current blockchain height from blockchain.info
-	currentHeight, err := bm.getCurrentHeightFromBlockchainInfo()
+	// Get current blockchain height from the first healthy height source
+	currentHeight, err := bm.getCurrentHeight()
 	if err != nil {
 		return fmt.Errorf("failed to get current height: %w", err)
 	}
 
-	log.Printf("Current blockchain height: %d, monitor height: %d", currentHeight, bm.currentHeight)
+	monitorHeight := bm.monitorHeight()
+	log.Printf("Current blockchain height: %d, monitor height: %d", currentHeight, monitorHeight)
 	if err := bm.reconcileCanonicalTip(currentHeight, 6); err != nil {
 		log.Printf("Failed to reconcile canonical tip: %v", err)
 	}
 
 	var startHeight int64
-	var maxBlocksPerCycle int64 = 2            // Very conservative: only 2 blocks per cycle
-	var delayBetweenRequests = 5 * time.Second // 5 second delay between requests
+	var maxBlocksPerCycle int64 = 2 // Very conservative: only 2 blocks per cycle
+	var delayBetweenRequests = bm.blockDelay
 
 	// If this is first run, process some recent blocks
-	if bm.currentHeight == 0 {
+	if monitorHeight == 0 {
 		// Process last 3 blocks as initial seed (reduced from 5)
 		startHeight = currentHeight - 2
 		if startHeight < 1 {
@@ -465,37 +600,53 @@ func (bm *BlockMonitor) checkForNewBlocks() error {
 		log.Printf("First run - processing blocks from %d to %d with %v delay between requests", startHeight, currentHeight, delayBetweenRequests)
 
 		for height := startHeight; height <= currentHeight; height++ {
-			if err := bm.ProcessBlock(height); err != nil {
+			if bm.stopRequested() {
+				return nil
+			}
+			if err := bm.processNextBlock(height); err != nil {
 				log.Printf("Error processing block %d: %v", height, err)
+				if errors.Is(err, ErrRawBlockRateLimited) {
+					// Retry this height next cycle rather than leave a gap.
+					break
+				}
 				continue
 			}
-			bm.currentHeight = height
-			bm.blocksProcessed++
+			bm.advanceCurrentHeight(height)
 
 			// Add delay between requests to avoid rate limiting
 			if height < currentHeight {
 				log.Printf("Waiting %v before processing next block...", delayBetweenRequests)
-				time.Sleep(delayBetweenRequests)
+				if !bm.sleepUnlessStopped(delayBetweenRequests) {
+					return nil
+				}
 			}
 		}
 	} else {
 		// Process new blocks in batches with throttling
-		startHeight = bm.currentHeight + 1
+		startHeight = monitorHeight + 1
 
 		log.Printf("Processing new blocks from %d to %d (max %d per cycle) with %v delay between requests", startHeight, currentHeight, maxBlocksPerCycle, delayBetweenRequests)
 
 		for height := startHeight; height <= currentHeight && height < startHeight+maxBlocksPerCycle; height++ {
-			if err := bm.ProcessBlock(height); err != nil {
+			if bm.stopRequested() {
+				return nil
+			}
+			if err := bm.processNextBlock(height); err != nil {
 				log.Printf("Error processing block %d: %v", height, err)
+				if errors.Is(err, ErrRawBlockRateLimited) {
+					// Retry this height next cycle rather than leave a gap.
+					break
+				}
 				continue
 			}
-			bm.currentHeight = height
-			bm.blocksProcessed++
+			bm.advanceCurrentHeight(height)
 
 			// Add delay between requests to avoid rate limiting
 			if height < currentHeight && height < startHeight+maxBlocksPerCycle-1 {
 				log.Printf("Waiting %v before processing next block...", delayBetweenRequests)
-				time.Sleep(delayBetweenRequests)
+				if !bm.sleepUnlessStopped(delayBetweenRequests) {
+					return nil
+				}
 			}
 		}
 	}
@@ -579,6 +730,7 @@ func (bm *BlockMonitor) pruneBlockDirsForHeight(height int64, canonicalHash stri
 	}
 	var removed bool
 	var hasCanonical bool
+	var moved []string
 	heightPrefix := fmt.Sprintf("%d_", height)
 	reorgDir := filepath.Join(blocksDir, "reorgs")
 	for _, entry := range entries {
@@ -595,27 +747,36 @@ func (bm *BlockMonitor) pruneBlockDirsForHeight(height int64, canonicalHash stri
 			continue
 		}
 		log.Printf("Reorg cleanup: moving stale block dir %s to reorgs (hash=%s canonical=%s)", entry.Name(), hash, canonicalHash)
-		if err := os.MkdirAll(reorgDir, 0755); err != nil {
+		if err := os.MkdirAll(reorgDir, bm.dirMode); err != nil {
 			return removed, err
 		}
 		dest := filepath.Join(reorgDir, entry.Name())
 		if err := os.Rename(dirPath, dest); err != nil {
-			if err := copyDir(dirPath, dest); err != nil {
+			if err := copyDir(dirPath, dest, bm.dirMode); err != nil {
 				return removed, err
 			}
 			if err := os.RemoveAll(dirPath); err != nil {
 				return removed, err
 			}
 		}
+		if err := writeOrphanMarker(dest, dirPath, height, hash, canonicalHash, bm.fileMode); err != nil {
+			log.Printf("Reorg cleanup: failed to mark %s as orphaned: %v", dest, err)
+		}
+		moved = append(moved, entry.Name())
 		removed = true
 	}
+	if len(moved) > 0 && blocksDir == bm.blocksDir {
+		if err := bm.unindexBlockDirs(moved...); err != nil {
+			log.Printf("Reorg cleanup: failed to update block index: %v", err)
+		}
+	}
 	if removed && !hasCanonical {
 		return true, nil
 	}
 	return false, nil
 }
 
-func copyDir(src, dest string) error {
+func copyDir(src, dest string, dirMode os.FileMode) error {
 	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -626,7 +787,7 @@ func copyDir(src, dest string) error {
 		}
 		target := filepath.Join(dest, rel)
 		if d.IsDir() {
-			return os.MkdirAll(target, 0755)
+			return os.MkdirAll(target, dirMode)
 		}
 		info, err := d.Info()
 		if err != nil {
@@ -655,19 +816,46 @@ func readBlockHeaderHash(path string) (string, error) {
 	return strings.TrimSpace(payload.BlockHeader.Hash), nil
 }
 
-// getCurrentHeightFromBlockchainInfo gets current height from the configured Bitcoin network
-func (bm *BlockMonitor) getCurrentHeightFromBlockchainInfo() (int64, error) {
-	return bm.bitcoinClient.GetCurrentHeight()
+// ProcessBlock downloads and processes a single block using raw block parser (exported for external use).
+// Heights whose directory is already complete are skipped; use ReprocessBlock to force.
+func (bm *BlockMonitor) ProcessBlock(height int64) error {
+	return bm.processBlock(height, false)
+}
+
+// ReprocessBlock processes a height even if a complete block directory already exists.
+func (bm *BlockMonitor) ReprocessBlock(height int64) error {
+	return bm.processBlock(height, true)
 }
 
-// ProcessBlock downloads and processes a single block using raw block parser (exported for external use)
-func (bm *BlockMonitor) ProcessBlock(height int64) error {
+func (bm *BlockMonitor) processBlock(height int64, force bool) (err error) {
+	unlock := bm.processLocks.lock(height)
+	defer unlock()
+
+	if !force {
+		if dir, ok := bm.completeBlockDirectory(height); ok {
+			log.Printf("Skipping block %d: %s is already complete", height, dir)
+			bm.recordBlockSkipped()
+			return nil
+		}
+	}
+
 	startTime := time.Now()
+	bm.emitMonitorEvent(MonitorEvent{Type: MonitorEventBlockStarted, Height: height})
+	defer func() {
+		if err != nil {
+			bm.emitMonitorEvent(MonitorEvent{
+				Type:       MonitorEventBlockFailed,
+				Height:     height,
+				DurationMs: time.Since(startTime).Milliseconds(),
+				Error:      err.Error(),
+			})
+		}
+	}()
 
 	log.Printf("Processing block %d, bitcoinAPI set: %v", height, bm.bitcoinAPI != nil)
 
 	// Get raw block hex from blockchain.info
-	hexData, err := bm.rawClient.GetRawBlockHex(height)
+	hexData, err := bm.fetchRawBlockHex(height)
 	if err != nil {
 		return fmt.Errorf("failed to get raw block hex: %w", err)
 	}
@@ -681,11 +869,22 @@ func (bm *BlockMonitor) ProcessBlock(height int64) error {
 	// Set the height in parsed block (this was missing!)
 	parsedBlock.Height = height
 
+	// Refuse to persist a block that does not chain onto what we already stored.
+	if err := bm.verifyBlockLink(height, parsedBlock); err != nil {
+		log.Printf("Rejecting block %d: %v", height, err)
+		return err
+	}
+
 	log.Printf("Parsed block %d: %d transactions, %d images found", height, len(parsedBlock.Transactions), len(parsedBlock.Images))
+	containImageFileNames(parsedBlock.Images)
 
 	// Create block directory
-	blockDir := filepath.Join(bm.blocksDir, fmt.Sprintf("%d_%s", height, parsedBlock.Hash[:8]))
-	if err := os.MkdirAll(blockDir, 0755); err != nil {
+	dirName, err := blockDirName(height, parsedBlock.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to name block directory: %w", err)
+	}
+	blockDir := filepath.Join(bm.blocksDir, dirName)
+	if err := os.MkdirAll(blockDir, bm.dirMode); err != nil {
 		return fmt.Errorf("failed to create block directory: %w", err)
 	}
 
@@ -701,7 +900,7 @@ func (bm *BlockMonitor) ProcessBlock(height int64) error {
 
 	// Scan each image individually using the native Go AlphaScanner via ScanImage.
 	// This replaces the former ScanBlock call which required the Python proxy.
-	var scanResults []map[string]any
+	var scanResults []ScanResultEntry
 	if len(parsedBlock.Images) > 0 {
 		log.Printf("Scanning %d images from block %d using per-image scanner", len(parsedBlock.Images), height)
 		var err error
@@ -722,7 +921,12 @@ func (bm *BlockMonitor) ProcessBlock(height int64) error {
 	inscriptions := bm.createInscriptionsFromImages(parsedBlock.Images)
 
 	// Create smart contracts and reconcile with ingested uploads when possible
-	smartContracts := bm.createSmartContractsFromScanResults(scanResults)
+	smartContracts := bm.createSmartContractsFromScanResults(scanResults, parsedBlock.Images, height)
+	if previous, err := readBlockDirContracts(blockDir); err == nil {
+		carryFirstSeen(smartContracts, previous)
+	}
+	bm.recordStegoContracts(smartContracts)
+	bm.notifyStegoDetections(height, smartContracts)
 	smartContracts = bm.reconcileIngestionContracts(blockDir, parsedBlock, scanResults, smartContracts, height)
 	smartContracts = bm.reconcileOracleIngestions(blockDir, parsedBlock, smartContracts, height)
 
@@ -730,9 +934,15 @@ func (bm *BlockMonitor) ProcessBlock(height int64) error {
 	if err := bm.saveBlockSummaryWithScanResults(blockDir, parsedBlock, inscriptions, scanResults, height, smartContracts); err != nil {
 		log.Printf("Failed to save block summary: %v", err)
 	}
+	stored := make([]ExtractedImageData, len(parsedBlock.Images))
+	for i, img := range parsedBlock.Images {
+		stored[i] = sanitizeExtractedImage(img)
+	}
+	if err := bm.indexBlockDir(height, parsedBlock.Hash, dirName, stored...); err != nil {
+		log.Printf("Failed to update block index: %v", err)
+	}
 
 	processingTime := time.Since(startTime)
-	bm.lastProcessTime = processingTime
 
 	// Create block response for storage
 	blockResponse := &BlockInscriptionsResponse{
@@ -749,7 +959,7 @@ func (bm *BlockMonitor) ProcessBlock(height int64) error {
 
 	// Store in data storage if available
 	if bm.dataStorage != nil {
-		if err := bm.dataStorage.StoreBlockData(blockResponse, scanResults); err != nil {
+		if err := bm.dataStorage.StoreBlockData(blockResponse, scanResultMaps(scanResults)); err != nil {
 			log.Printf("Failed to store block data in storage: %v", err)
 		} else {
 			log.Printf("Successfully stored block %d data in storage", height)
@@ -757,14 +967,20 @@ func (bm *BlockMonitor) ProcessBlock(height int64) error {
 	}
 
 	// Update statistics
-	bm.totalTransactions += int64(len(parsedBlock.Transactions))
-	bm.totalImages += int64(len(parsedBlock.Images))
-	bm.totalInscriptions += int64(len(inscriptions))
-	bm.totalStegoContracts += int64(bm.countStegoImages(scanResults))
+	bm.recordBlockStats(processingTime, len(parsedBlock.Transactions), len(parsedBlock.Images), len(inscriptions), bm.countStegoImages(scanResults))
 
 	log.Printf("Successfully processed block %d in %v: %d txs, %d images, %d inscriptions, %d stego detected, %d smart contracts",
 		height, processingTime, len(parsedBlock.Transactions), len(parsedBlock.Images), len(inscriptions), bm.countStegoImages(scanResults), len(smartContracts))
 
+	bm.notifyBlockProcessed(height, parsedBlock.Hash, smartContracts)
+	bm.emitMonitorEvent(MonitorEvent{
+		Type:        MonitorEventBlockCompleted,
+		Height:      height,
+		TxCount:     len(parsedBlock.Transactions),
+		ImagesFound: len(parsedBlock.Images),
+		DurationMs:  processingTime.Milliseconds(),
+	})
+
 	for _, fn := range bm.onBlockProcessed {
 		fn(height)
 	}
@@ -780,7 +996,7 @@ func (bm *BlockMonitor) ReconcileRecentBlocks(ctx context.Context, count int) er
 	bm.reconcileMu.Lock()
 	defer bm.reconcileMu.Unlock()
 
-	height, err := bm.getCurrentHeightFromBlockchainInfo()
+	height, err := bm.getCurrentHeight()
 	if err != nil {
 		return fmt.Errorf("get current height: %w", err)
 	}
@@ -792,7 +1008,7 @@ func (bm *BlockMonitor) ReconcileRecentBlocks(ctx context.Context, count int) er
 		if h < 0 {
 			break
 		}
-		if err := bm.ProcessBlock(h); err != nil {
+		if err := bm.ReprocessBlock(h); err != nil {
 			log.Printf("reconcile recent blocks: failed to process block %d: %v", h, err)
 		}
 	}
@@ -865,7 +1081,7 @@ func (bm *BlockMonitor) parseTxOutputsFromJSON(txid string, txJSON map[string]an
 func (bm *BlockMonitor) saveBlockData(blockDir string, parsedBlock *ParsedBlock, hexData string) error {
 	// Save raw hex data
 	hexFile := filepath.Join(blockDir, "block.hex")
-	if err := os.WriteFile(hexFile, []byte(hexData), 0644); err != nil {
+	if err := os.WriteFile(hexFile, []byte(hexData), bm.fileMode); err != nil {
 		return fmt.Errorf("failed to write hex file: %w", err)
 	}
 
@@ -881,6 +1097,7 @@ func (bm *BlockMonitor) saveBlockData(blockDir string, parsedBlock *ParsedBlock,
 			Hash:       parsedBlock.Header.Hash,
 		},
 		Transactions:    bm.convertTransactions(parsedBlock.Transactions),
+		WitnessData:     extractWitnessData(parsedBlock.Transactions),
 		ExtractedImages: parsedBlock.Images,
 		Metadata: BlockMetadata{
 			SourceFile:     fmt.Sprintf("block_%s.hex", parsedBlock.Header.Hash),
@@ -903,7 +1120,7 @@ func (bm *BlockMonitor) saveBlockData(blockDir string, parsedBlock *ParsedBlock,
 	}
 
 	blockFile := filepath.Join(blockDir, "block.json")
-	if err := os.WriteFile(blockFile, blockJSON, 0644); err != nil {
+	if err := os.WriteFile(blockFile, blockJSON, bm.fileMode); err != nil {
 		return fmt.Errorf("failed to write block JSON: %w", err)
 	}
 
@@ -917,15 +1134,19 @@ func (bm *BlockMonitor) saveImages(blockDir string, images []ExtractedImageData)
 	}
 
 	imagesDir := filepath.Join(blockDir, "images")
-	if err := os.MkdirAll(imagesDir, 0755); err != nil {
+	if err := os.MkdirAll(imagesDir, bm.dirMode); err != nil {
 		return fmt.Errorf("failed to create images directory: %w", err)
 	}
 
 	for _, image := range images {
 		cleaned := sanitizeExtractedImage(image)
-		imageFile := security.SafeFilePath(imagesDir, cleaned.FileName)
+		imageFile, err := security.ContainedFilePath(imagesDir, cleaned.FileName)
+		if err != nil {
+			log.Printf("Refusing to save image %q: %v", cleaned.FileName, err)
+			continue
+		}
 		// Save the actual image data
-		if err := os.WriteFile(imageFile, cleaned.Data, 0644); err != nil {
+		if err := os.WriteFile(imageFile, cleaned.Data, bm.fileMode); err != nil {
 			log.Printf("Failed to save image %s: %v", cleaned.FileName, err)
 		} else {
 			log.Printf("Successfully saved image %s (%d bytes)", cleaned.FileName, len(cleaned.Data))
@@ -998,7 +1219,7 @@ func (bm *BlockMonitor) saveBlockSummary(blockDir string, parsedBlock *ParsedBlo
 	}
 
 	summaryFile := filepath.Join(blockDir, "inscriptions.json")
-	if err := os.WriteFile(summaryFile, summaryJSON, 0644); err != nil {
+	if err := os.WriteFile(summaryFile, summaryJSON, bm.fileMode); err != nil {
 		return fmt.Errorf("failed to write summary file: %w", err)
 	}
 
@@ -1074,7 +1295,7 @@ func sanitizeExtractedImage(img ExtractedImageData) ExtractedImageData {
 			if idx := bytes.IndexByte(data, '<'); idx >= 0 {
 				data = data[idx:]
 			}
-		} else if trimmed := trimToImageSignatureLocal(data); len(trimmed) > 0 {
+		} else if trimmed := stego.TrimToImageSignature(data); len(trimmed) > 0 {
 			data = trimmed
 		}
 	} else {
@@ -1105,7 +1326,7 @@ func sanitizeInscriptionsForDisk(inscriptions []InscriptionData) []InscriptionDa
 				data = data[idx:]
 			}
 		} else if strings.HasPrefix(mime, "image/") {
-			if trimmed := trimToImageSignatureLocal(data); len(trimmed) > 0 {
+			if trimmed := stego.TrimToImageSignature(data); len(trimmed) > 0 {
 				data = trimmed
 			}
 		} else {
@@ -1172,6 +1393,7 @@ func stripNonPrintablePrefixLocal(b []byte) []byte {
 // convertTransactions converts parsed transactions to transaction data format
 func (bm *BlockMonitor) convertTransactions(transactions []Transaction) []TransactionData {
 	var txData []TransactionData
+	params := bm.networkParams()
 
 	for _, tx := range transactions {
 		witnessCount := 0
@@ -1186,6 +1408,7 @@ func (bm *BlockMonitor) convertTransactions(transactions []Transaction) []Transa
 			HasImages:  witnessCount > 0,
 			ImageCount: witnessCount,
 		}
+		fillTransactionDetails(&data, tx, params)
 		txData = append(txData, data)
 	}
 
@@ -1193,7 +1416,7 @@ func (bm *BlockMonitor) convertTransactions(transactions []Transaction) []Transa
 }
 
 // scanBlockViaAPI calls the /scan/block API endpoint to scan a block
-func (bm *BlockMonitor) scanBlockViaAPI(height int64) ([]map[string]any, error) {
+func (bm *BlockMonitor) scanBlockViaAPI(height int64) ([]ScanResultEntry, error) {
 	// Create the request for the /scan/block API
 	request := core.BlockScanRequest{
 		BlockHeight: int(height),
@@ -1235,35 +1458,23 @@ func (bm *BlockMonitor) scanBlockViaAPI(height int64) ([]map[string]any, error)
 	}
 
 	// Convert the API response to the expected format for block monitor
-	var results []map[string]any
+	var results []ScanResultEntry
 	for i, inscription := range scanResponse.Inscriptions {
-		result := map[string]any{
-			"tx_id":             inscription.TxID,
-			"image_index":       i,
-			"file_name":         inscription.FileName,
-			"size_bytes":        inscription.SizeBytes,
-			"format":            "unknown",
-			"scanned_at":        time.Now().Unix(),
-			"is_stego":          false,
-			"confidence":        0.0,
-			"stego_type":        "",
-			"extracted_message": "",
-			"scan_error":        "",
-			"stego_details":     nil,
+		result := ScanResultEntry{
+			TxID:       inscription.TxID,
+			ImageIndex: i,
+			FileName:   inscription.FileName,
+			SizeBytes:  inscription.SizeBytes,
+			Format:     "unknown",
+			ScannedAt:  time.Now().Unix(),
 		}
 
 		if inscription.ScanResult != nil {
-			result["is_stego"] = inscription.ScanResult.IsStego
-			result["confidence"] = inscription.ScanResult.Confidence
-			if inscription.ScanResult.StegoType != "" {
-				result["stego_type"] = inscription.ScanResult.StegoType
-			}
-			if inscription.ScanResult.ExtractedMessage != "" {
-				result["extracted_message"] = inscription.ScanResult.ExtractedMessage
-			}
-			if inscription.ScanResult.ExtractionError != "" {
-				result["scan_error"] = inscription.ScanResult.ExtractionError
-			}
+			result.IsStego = inscription.ScanResult.IsStego
+			result.Confidence = inscription.ScanResult.Confidence
+			result.StegoType = inscription.ScanResult.StegoType
+			result.ExtractedMessage = inscription.ScanResult.ExtractedMessage
+			result.ScanError = inscription.ScanResult.ExtractionError
 		}
 
 		results = append(results, result)
@@ -1273,10 +1484,10 @@ func (bm *BlockMonitor) scanBlockViaAPI(height int64) ([]map[string]any, error)
 }
 
 // countStegoImagesFromAPIResponse counts stego detections from API response
-func (bm *BlockMonitor) countStegoImagesFromAPIResponse(scanResults []map[string]any) int {
+func (bm *BlockMonitor) countStegoImagesFromAPIResponse(scanResults []ScanResultEntry) int {
 	count := 0
 	for _, result := range scanResults {
-		if isStego, ok := result["is_stego"].(bool); ok && isStego {
+		if result.IsStego {
 			count++
 		}
 	}
@@ -1284,93 +1495,89 @@ func (bm *BlockMonitor) countStegoImagesFromAPIResponse(scanResults []map[string
 }
 
 // countStegoImages counts how many images have steganography detected
-func (bm *BlockMonitor) countStegoImages(scanResults []map[string]any) int {
+func (bm *BlockMonitor) countStegoImages(scanResults []ScanResultEntry) int {
 	return bm.countStegoImagesFromAPIResponse(scanResults)
 }
 
 // scanImagesDirectly scans images using the BitcoinAPI directly
-func (bm *BlockMonitor) scanImagesDirectly(images []ExtractedImageData) ([]map[string]any, error) {
+func (bm *BlockMonitor) scanImagesDirectly(images []ExtractedImageData) ([]ScanResultEntry, error) {
 	log.Printf("scanImagesDirectly called with %d images", len(images))
-	var results []map[string]any
+	var results []ScanResultEntry
 
 	for i, image := range images {
-		// Create scan result for this image
-		result := map[string]any{
-			"tx_id":             image.TxID,
-			"image_index":       i,
-			"file_name":         image.FileName,
-			"size_bytes":        image.SizeBytes,
-			"format":            image.Format,
-			"scanned_at":        time.Now().Unix(),
-			"is_stego":          false,
-			"confidence":        0.0,
-			"stego_type":        "",
-			"extracted_message": "",
-			"scan_error":        "",
-			"stego_details":     nil,
-		}
-
-		// Try to scan the image using the scanner manager
-		if bm.bitcoinAPI != nil && bm.bitcoinAPI.scannerManager != nil {
-			log.Printf("Scanning image %d: %s (%d bytes)", i, image.FileName, len(image.Data))
-			scanResult, err := bm.bitcoinAPI.scannerManager.ScanImage(image.Data, core.ScanOptions{
-				ExtractMessage:      true,
-				ConfidenceThreshold: 0.5,
-				IncludeMetadata:     true,
-			})
-			if err != nil {
-				log.Printf("Failed to scan image %s: %v", image.FileName, err)
-				result["scan_error"] = err.Error()
-			} else {
-				log.Printf("Scanned image %s: is_stego=%v, confidence=%.2f", image.FileName, scanResult.IsStego, scanResult.Confidence)
-				result["is_stego"] = scanResult.IsStego
-				result["confidence"] = scanResult.Confidence
-				if scanResult.StegoType != "" {
-					result["stego_type"] = scanResult.StegoType
-				}
-				if scanResult.ExtractedMessage != "" {
-					result["extracted_message"] = scanResult.ExtractedMessage
-				}
-				if scanResult.ExtractionError != "" {
-					result["scan_error"] = scanResult.ExtractionError
-				}
-			}
-		} else {
-			log.Printf("Scanner not available for image %s", image.FileName)
-			result["scan_error"] = "Scanner not available"
-		}
-
-		results = append(results, result)
+		results = append(results, bm.scanImageResult(i, image, defaultImageScanOptions))
 	}
 
 	log.Printf("scanImagesDirectly completed, scanned %d images", len(results))
 	return results, nil
 }
 
+// defaultImageScanOptions are used for images scanned while processing a block.
+var defaultImageScanOptions = core.ScanOptions{
+	ExtractMessage:      true,
+	ConfidenceThreshold: 0.5,
+	IncludeMetadata:     true,
+}
+
+// scanImageResult scans one image and returns its scan_result entry as stored in inscriptions.json.
+func (bm *BlockMonitor) scanImageResult(i int, image ExtractedImageData, options core.ScanOptions) ScanResultEntry {
+	// Create scan result for this image
+	result := ScanResultEntry{
+		TxID:       image.TxID,
+		ImageIndex: i,
+		FileName:   image.FileName,
+		SizeBytes:  image.SizeBytes,
+		Format:     image.Format,
+		ScannedAt:  time.Now().Unix(),
+	}
+
+	// Try to scan the image using the scanner manager
+	if scanner := bm.scanner(); scanner != nil {
+		log.Printf("Scanning image %d: %s (%d bytes)", i, image.FileName, len(image.Data))
+		scanResult, err := bm.scanImageWithTimeout(scanner, image.Data, options)
+		if errors.Is(err, starlight.ErrCircuitOpen) {
+			// The scanner is failing; skip it until the breaker's cooldown ends.
+			result.ScanError = "not_scanned"
+		} else if errors.Is(err, starlight.ErrUnsupportedFormat) {
+			log.Printf("Skipping image %s: unsupported format", image.FileName)
+			result.ScanError = "unsupported_format"
+		} else if err != nil {
+			log.Printf("Failed to scan image %s: %v", image.FileName, err)
+			result.ScanError = err.Error()
+		} else {
+			log.Printf("Scanned image %s: is_stego=%v, confidence=%.2f", image.FileName, scanResult.IsStego, scanResult.Confidence)
+			result.IsStego = scanResult.IsStego
+			result.Confidence = scanResult.Confidence
+			result.StegoType = scanResult.StegoType
+			result.ExtractedMessage = scanResult.ExtractedMessage
+			result.ScanError = scanResult.ExtractionError
+			if bm.scanProbs && len(scanResult.Probabilities) > 0 {
+				result.Probabilities = scanResult.Probabilities
+			}
+		}
+	} else {
+		log.Printf("Scanner not available for image %s", image.FileName)
+		result.ScanError = "Scanner not available"
+	}
+
+	return result
+}
+
 // createEmptyScanResults creates empty scan results for all images
-func (bm *BlockMonitor) createEmptyScanResults(count int) []map[string]any {
-	results := make([]map[string]any, count)
+func (bm *BlockMonitor) createEmptyScanResults(count int) []ScanResultEntry {
+	results := make([]ScanResultEntry, count)
 	for i := 0; i < count; i++ {
-		results[i] = map[string]any{
-			"tx_id":             "",
-			"image_index":       i,
-			"file_name":         "",
-			"size_bytes":        0,
-			"format":            "",
-			"scanned_at":        time.Now().Unix(),
-			"is_stego":          false,
-			"confidence":        0.0,
-			"stego_type":        "",
-			"extracted_message": "",
-			"scan_error":        "not_scanned",
-			"stego_details":     nil,
+		results[i] = ScanResultEntry{
+			ImageIndex: i,
+			ScannedAt:  time.Now().Unix(),
+			ScanError:  "not_scanned",
 		}
 	}
 	return results
 }
 
 // saveBlockSummaryWithScanResults saves block summary including steganography scan results
-func (bm *BlockMonitor) saveBlockSummaryWithScanResults(blockDir string, parsedBlock *ParsedBlock, inscriptions []InscriptionData, scanResults []map[string]any, blockHeight int64, smartContracts []SmartContractData) error {
+func (bm *BlockMonitor) saveBlockSummaryWithScanResults(blockDir string, parsedBlock *ParsedBlock, inscriptions []InscriptionData, scanResults []ScanResultEntry, blockHeight int64, smartContracts []SmartContractData) error {
 	// Count stego detections
 	stegoCount := bm.countStegoImages(scanResults)
 
@@ -1412,21 +1619,11 @@ func (bm *BlockMonitor) saveBlockSummaryWithScanResults(blockDir string, parsedB
 			"file_name":  image.FileName,
 			"file_path":  image.FilePath,
 		}
+		bm.embedImageContent(enhancedImage, image)
 
 		// Add scan result if available
 		if len(scanResults) > i {
-			scanResult := scanResults[i]
-			if scanResult != nil {
-				enhancedImage["scan_result"] = scanResult
-			} else {
-				// Default scan result for unscanned images
-				enhancedImage["scan_result"] = map[string]any{
-					"is_stego":          false,
-					"stego_probability": 0.0,
-					"confidence":        0.0,
-					"prediction":        "not_scanned",
-				}
-			}
+			enhancedImage["scan_result"] = scanResults[i].ToMap()
 		} else {
 			// Default scan result for unscanned images
 			enhancedImage["scan_result"] = map[string]any{
@@ -1475,47 +1672,173 @@ func (bm *BlockMonitor) saveBlockSummaryWithScanResults(blockDir string, parsedB
 	}
 
 	summaryFile := filepath.Join(blockDir, "inscriptions.json")
-	if err := os.WriteFile(summaryFile, summaryJSON, 0644); err != nil {
+	if err := os.WriteFile(summaryFile, summaryJSON, bm.fileMode); err != nil {
 		return fmt.Errorf("failed to write summary file: %w", err)
 	}
 
 	return nil
 }
 
-// createSmartContractsFromScanResults creates smart contract data from steganography scan results
-func (bm *BlockMonitor) createSmartContractsFromScanResults(scanResults []map[string]any) []SmartContractData {
+// createSmartContractsFromScanResults creates smart contract data from steganography scan results.
+// Detections are keyed by the image's visible_pixel_hash (or, without an image, by
+// height, tx_id and image_index), so the same image yields one entry with a stable
+// ContractID no matter how often it is scanned.
+func (bm *BlockMonitor) createSmartContractsFromScanResults(scanResults []ScanResultEntry, images []ExtractedImageData, blockHeight int64) []SmartContractData {
 	var contracts []SmartContractData
 
 	for _, result := range scanResults {
-		if isStego, ok := result["is_stego"].(bool); ok && isStego {
+		if result.IsStego {
 			contract := SmartContractData{
-				ContractID:  fmt.Sprintf("stego_%v_%d", result["image_index"], time.Now().Unix()),
-				BlockHeight: 0, // Will be set by caller
-				ImagePath:   fmt.Sprintf("%v", result["file_name"]),
-				Confidence:  0.0,
-				Metadata: map[string]any{
-					"tx_id":             result["tx_id"],
-					"image_index":       result["image_index"],
-					"stego_type":        result["stego_type"],
-					"extracted_message": result["extracted_message"],
-					"scan_confidence":   result["confidence"],
-					"scan_timestamp":    result["scanned_at"],
-					"format":            result["format"],
-					"size_bytes":        result["size_bytes"],
-				},
-			}
-
-			if conf, ok := result["confidence"].(float64); ok {
-				contract.Confidence = conf
+				BlockHeight:          blockHeight,
+				ImagePath:            result.FileName,
+				Confidence:           result.Confidence,
+				Metadata:             buildContractMetadata(result),
+				FirstSeenAt:          time.Now().Unix(),
+				Source:               ContractSourceBlockScan,
+				ReconciliationStatus: ReconciliationUnmatched,
+			}
+
+			var hash string
+			if image := bm.findImageForScanResult(images, result); image != nil {
+				if cleaned := sanitizeExtractedImage(*image); len(cleaned.Data) > 0 {
+					hash = stego.VisiblePixelHash(cleaned.Data)
+					contract.Metadata["visible_pixel_hash"] = hash
+				}
 			}
+			contract.ContractID = stegoContractID(hash, blockHeight, result.TxID, result.ImageIndex)
 
-			contracts = append(contracts, contract)
+			contracts = upsertContractByID(contracts, contract)
 		}
 	}
 
 	return contracts
 }
 
+// recordStegoContracts folds a block's detections into the monitor-wide registry
+// keyed by visible_pixel_hash: a repeat sighting updates block height and
+// confidence of the existing entry instead of adding another contract.
+func (bm *BlockMonitor) recordStegoContracts(contracts []SmartContractData) {
+	bm.ensureStegoContracts()
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	for _, contract := range contracts {
+		foldStegoContract(bm.stegoContracts, contract)
+	}
+}
+
+// foldStegoContract adds contract to registry under its visible_pixel_hash,
+// keeping the latest block height and confidence for repeat sightings.
+func foldStegoContract(registry map[string]SmartContractData, contract SmartContractData) {
+	hash := stringFromAny(contract.Metadata["visible_pixel_hash"])
+	if hash == "" {
+		return
+	}
+	existing, ok := registry[hash]
+	if !ok {
+		meta := make(map[string]any, len(contract.Metadata)+1)
+		for k, v := range contract.Metadata {
+			meta[k] = v
+		}
+		meta["first_seen_height"] = contract.BlockHeight
+		contract.Metadata = meta
+		registry[hash] = contract
+		return
+	}
+	if contract.BlockHeight >= existing.BlockHeight {
+		existing.BlockHeight = contract.BlockHeight
+		existing.ImagePath = contract.ImagePath
+		existing.Confidence = contract.Confidence
+	}
+	registry[hash] = existing
+}
+
+// ensureStegoContracts seeds the registry from the block summaries on disk
+// the first time it is needed, so de-duplication survives a restart.
+func (bm *BlockMonitor) ensureStegoContracts() {
+	bm.mu.RLock()
+	loaded := bm.stegoContracts != nil
+	bm.mu.RUnlock()
+	if loaded {
+		return
+	}
+	registry := loadStegoContracts(bm.blocksDir)
+	bm.mu.Lock()
+	if bm.stegoContracts == nil {
+		bm.stegoContracts = registry
+	}
+	bm.mu.Unlock()
+}
+
+// loadStegoContracts folds the smart_contracts of every indexed block's
+// inscriptions.json into a registry, oldest block first.
+func loadStegoContracts(blocksDir string) map[string]SmartContractData {
+	registry := make(map[string]SmartContractData)
+	idx, err := LoadBlockIndex(blocksDir)
+	if err != nil {
+		if idx, err = scanBlockIndex(blocksDir); err != nil {
+			return registry
+		}
+	}
+	heights := make([]int64, 0, len(idx.ByHeight))
+	for height := range idx.ByHeight {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	for _, height := range heights {
+		contracts, err := readBlockDirContracts(filepath.Join(blocksDir, idx.ByHeight[height]))
+		if err != nil {
+			continue
+		}
+		for _, contract := range contracts {
+			foldStegoContract(registry, contract)
+		}
+	}
+	return registry
+}
+
+// readBlockDirContracts decodes the smart_contracts of dir's inscriptions.json.
+func readBlockDirContracts(dir string) ([]SmartContractData, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "inscriptions.json"))
+	if err != nil {
+		return nil, err
+	}
+	var summary map[string]any
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, err
+	}
+	return summaryContracts(summary)
+}
+
+// carryFirstSeen keeps the FirstSeenAt a contract had in an earlier summary
+// of the same block, so reprocessing does not reset when it was first seen.
+func carryFirstSeen(contracts, previous []SmartContractData) {
+	if len(previous) == 0 {
+		return
+	}
+	firstSeen := make(map[string]int64, len(previous))
+	for _, prev := range previous {
+		firstSeen[prev.ContractID] = prev.FirstSeenAt
+	}
+	for i := range contracts {
+		if seen := firstSeen[contracts[i].ContractID]; seen > 0 {
+			mergeContractProvenance(&contracts[i], SmartContractData{FirstSeenAt: seen})
+		}
+	}
+}
+
+// StegoContracts returns the de-duplicated stego detections across all stored blocks.
+func (bm *BlockMonitor) StegoContracts() []SmartContractData {
+	bm.ensureStegoContracts()
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+	out := make([]SmartContractData, 0, len(bm.stegoContracts))
+	for _, contract := range bm.stegoContracts {
+		out = append(out, contract)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ContractID < out[j].ContractID })
+	return out
+}
+
 type scanPayload struct {
 	message          string
 	payoutAddress    string
@@ -1523,7 +1846,7 @@ type scanPayload struct {
 	payoutScriptHash string
 }
 
-func (bm *BlockMonitor) reconcileIngestionContracts(blockDir string, parsedBlock *ParsedBlock, scanResults []map[string]any, smartContracts []SmartContractData, blockHeight int64) []SmartContractData {
+func (bm *BlockMonitor) reconcileIngestionContracts(blockDir string, parsedBlock *ParsedBlock, scanResults []ScanResultEntry, smartContracts []SmartContractData, blockHeight int64) []SmartContractData {
 	if bm.ingestion == nil || len(scanResults) == 0 {
 		return smartContracts
 	}
@@ -1540,17 +1863,11 @@ func (bm *BlockMonitor) reconcileIngestionContracts(blockDir string, parsedBlock
 	}
 
 	for _, result := range scanResults {
-		isStego, _ := result["is_stego"].(bool)
-		if !isStego {
+		if !result.IsStego || result.TxID == "" {
 			continue
 		}
 
-		txID := stringFromAny(result["tx_id"])
-		if txID == "" {
-			continue
-		}
-
-		tx, ok := txByID[txID]
+		tx, ok := txByID[result.TxID]
 		if !ok {
 			continue
 		}
@@ -1578,12 +1895,14 @@ func (bm *BlockMonitor) reconcileIngestionContracts(blockDir string, parsedBlock
 
 		matchedScript, ok := bm.matchPayoutScript(tx, payload)
 		if !ok {
+			markContractReconciliation(smartContracts, result, ReconciliationHashMatched)
 			continue
 		}
 
 		destPath, err := bm.moveIngestionImage(blockDir, rec)
 		if err != nil {
 			log.Printf("Failed to move ingestion image for %s: %v", visibleHash, err)
+			markContractReconciliation(smartContracts, result, ReconciliationHashMatched)
 			bm.maybeReconcileStego(rec)
 			continue
 		}
@@ -1610,20 +1929,18 @@ func (bm *BlockMonitor) reconcileIngestionContracts(blockDir string, parsedBlock
 		contractMeta["image_file"] = imageFile
 		contractMeta["image_path"] = imagePath
 
-		if updated := updateContractEntry(smartContracts, result, SmartContractData{
-			ContractID:  visibleHash,
-			BlockHeight: blockHeight,
-			ImagePath:   imagePath,
-			Confidence:  confidenceFromAny(result["confidence"]),
-			Metadata:    contractMeta,
-		}); !updated {
-			smartContracts = append(smartContracts, SmartContractData{
-				ContractID:  visibleHash,
-				BlockHeight: blockHeight,
-				ImagePath:   imagePath,
-				Confidence:  confidenceFromAny(result["confidence"]),
-				Metadata:    contractMeta,
-			})
+		contract := SmartContractData{
+			ContractID:           visibleHash,
+			BlockHeight:          blockHeight,
+			ImagePath:            imagePath,
+			Confidence:           result.Confidence,
+			Metadata:             contractMeta,
+			FirstSeenAt:          ingestionFirstSeen(rec),
+			Source:               ContractSourceIngestion,
+			ReconciliationStatus: ReconciliationFullyMatched,
+		}
+		if updated := updateContractEntry(smartContracts, result, contract); !updated {
+			smartContracts = append(smartContracts, contract)
 		}
 	}
 
@@ -1718,11 +2035,14 @@ func (bm *BlockMonitor) reconcileOracleIngestions(blockDir string, parsedBlock *
 			mergeIngestionMetadata(contractMeta, match.Metadata)
 			applyStegoMetadata(contractMeta, match.Metadata)
 			smartContracts = upsertContractByID(smartContracts, SmartContractData{
-				ContractID:  match.ID,
-				BlockHeight: blockHeight,
-				ImagePath:   imagePath,
-				Confidence:  0,
-				Metadata:    contractMeta,
+				ContractID:           match.ID,
+				BlockHeight:          blockHeight,
+				ImagePath:            imagePath,
+				Confidence:           0,
+				Metadata:             contractMeta,
+				FirstSeenAt:          ingestionFirstSeen(match),
+				Source:               ContractSourceIngestion,
+				ReconciliationStatus: ReconciliationFullyMatched,
 			})
 			bm.ensureMatchedContract(match.ID, match, tx.TxID, blockHeight, imagePath)
 			bm.markIngestionConfirmed(match, tx.TxID, blockHeight, imageFile, imagePath)
@@ -1793,11 +2113,14 @@ func (bm *BlockMonitor) reconcileOracleIngestions(blockDir string, parsedBlock *
 				mergeIngestionMetadata(contractMeta, match.Metadata)
 				applyStegoMetadata(contractMeta, match.Metadata)
 				smartContracts = upsertContractByID(smartContracts, SmartContractData{
-					ContractID:  match.ID,
-					BlockHeight: blockHeight,
-					ImagePath:   imagePath,
-					Confidence:  0,
-					Metadata:    contractMeta,
+					ContractID:           match.ID,
+					BlockHeight:          blockHeight,
+					ImagePath:            imagePath,
+					Confidence:           0,
+					Metadata:             contractMeta,
+					FirstSeenAt:          ingestionFirstSeen(match),
+					Source:               ContractSourceIngestion,
+					ReconciliationStatus: ReconciliationFullyMatched,
 				})
 				bm.ensureMatchedContract(match.ID, match, tx.TxID, blockHeight, imagePath)
 				bm.markIngestionConfirmed(match, tx.TxID, blockHeight, imageFile, imagePath)
@@ -1875,11 +2198,14 @@ func (bm *BlockMonitor) reconcileOracleIngestions(blockDir string, parsedBlock *
 				mergeIngestionMetadata(contractMeta, match.Metadata)
 				applyStegoMetadata(contractMeta, match.Metadata)
 				smartContracts = upsertContractByID(smartContracts, SmartContractData{
-					ContractID:  match.ID,
-					BlockHeight: blockHeight,
-					ImagePath:   imagePath,
-					Confidence:  0,
-					Metadata:    contractMeta,
+					ContractID:           match.ID,
+					BlockHeight:          blockHeight,
+					ImagePath:            imagePath,
+					Confidence:           0,
+					Metadata:             contractMeta,
+					FirstSeenAt:          ingestionFirstSeen(match),
+					Source:               ContractSourceIngestion,
+					ReconciliationStatus: ReconciliationFullyMatched,
 				})
 				bm.ensureMatchedContract(match.ID, match, tx.TxID, blockHeight, imagePath)
 				bm.markIngestionConfirmed(match, tx.TxID, blockHeight, imageFile, imagePath)
@@ -1932,11 +2258,14 @@ func (bm *BlockMonitor) reconcileOracleIngestions(blockDir string, parsedBlock *
 			applyStegoMetadata(contractMeta, match.Metadata)
 
 			smartContracts = upsertContractByID(smartContracts, SmartContractData{
-				ContractID:  match.ID,
-				BlockHeight: blockHeight,
-				ImagePath:   imagePath,
-				Confidence:  0,
-				Metadata:    contractMeta,
+				ContractID:           match.ID,
+				BlockHeight:          blockHeight,
+				ImagePath:            imagePath,
+				Confidence:           0,
+				Metadata:             contractMeta,
+				FirstSeenAt:          ingestionFirstSeen(match),
+				Source:               ContractSourceIngestion,
+				ReconciliationStatus: ReconciliationFullyMatched,
 			})
 			bm.ensureMatchedContract(match.ID, match, tx.TxID, blockHeight, imagePath)
 			bm.markIngestionConfirmed(match, tx.TxID, blockHeight, imageFile, imagePath)
@@ -2167,19 +2496,17 @@ func outputAddresses(script []byte, params *chaincfg.Params) []string {
 	return out
 }
 
-func (bm *BlockMonitor) findImageForScanResult(images []ExtractedImageData, result map[string]any) *ExtractedImageData {
-	fileName := stringFromAny(result["file_name"])
-	if fileName != "" {
+func (bm *BlockMonitor) findImageForScanResult(images []ExtractedImageData, result ScanResultEntry) *ExtractedImageData {
+	if result.FileName != "" {
 		for i := range images {
-			if images[i].FileName == fileName {
+			if images[i].FileName == result.FileName {
 				return &images[i]
 			}
 		}
 	}
-	txID := stringFromAny(result["tx_id"])
-	if txID != "" {
+	if result.TxID != "" {
 		for i := range images {
-			if images[i].TxID == txID {
+			if images[i].TxID == result.TxID {
 				return &images[i]
 			}
 		}
@@ -2698,20 +3025,20 @@ func scriptAddressHashes(script []byte, params *chaincfg.Params) []string {
 	return out
 }
 
-func parseScanPayload(result map[string]any) scanPayload {
+func parseScanPayload(result ScanResultEntry) scanPayload {
 	payload := scanPayload{
-		message:          strings.TrimSpace(stringFromAny(result["extracted_message"])),
-		payoutAddress:    strings.TrimSpace(stringFromAny(result["payout_address"])),
-		payoutScript:     normalizeHex(stringFromAny(result["payout_script"])),
-		payoutScriptHash: normalizeHex(stringFromAny(result["payout_script_hash"])),
+		message:          strings.TrimSpace(result.ExtractedMessage),
+		payoutAddress:    strings.TrimSpace(stringFromAny(result.Extra["payout_address"])),
+		payoutScript:     normalizeHex(stringFromAny(result.Extra["payout_script"])),
+		payoutScriptHash: normalizeHex(stringFromAny(result.Extra["payout_script_hash"])),
 	}
 	if payload.payoutAddress == "" {
-		payload.payoutAddress = strings.TrimSpace(stringFromAny(result["address"]))
+		payload.payoutAddress = strings.TrimSpace(stringFromAny(result.Extra["address"]))
 	}
 
 	raw := payload.message
 	if raw == "" {
-		raw = strings.TrimSpace(stringFromAny(result["embedded_message"]))
+		raw = strings.TrimSpace(stringFromAny(result.Extra["embedded_message"]))
 	}
 	raw = strings.TrimSpace(raw)
 	if raw == "" || !strings.HasPrefix(raw, "{") {
@@ -2838,10 +3165,13 @@ func (bm *BlockMonitor) moveIngestionImageWithFilename(blockDir string, rec *ser
 	}
 
 	destDir := filepath.Join(blockDir, "images")
-	if err := os.MkdirAll(destDir, 0755); err != nil {
+	if err := os.MkdirAll(destDir, bm.dirMode); err != nil {
 		return "", fmt.Errorf("failed to create images dir: %w", err)
 	}
-	destPath := security.SafeFilePath(destDir, destFilename)
+	destPath, err := security.ContainedFilePath(destDir, security.SanitizeFilename(destFilename))
+	if err != nil {
+		return "", fmt.Errorf("invalid ingestion image name: %w", err)
+	}
 	if _, err := os.Stat(destPath); err == nil {
 		// Already copied — keep upload files for IPFS mirror.
 		return destPath, nil
@@ -2897,7 +3227,7 @@ func (bm *BlockMonitor) moveIngestionImageWithFilename(blockDir string, rec *ser
 		if err != nil {
 			return "", fmt.Errorf("decode ingestion image: %w", err)
 		}
-		if err := os.WriteFile(destPath, data, 0644); err != nil {
+		if err := os.WriteFile(destPath, data, bm.fileMode); err != nil {
 			return "", fmt.Errorf("write ingestion image: %w", err)
 		}
 		return destPath, nil
@@ -2959,7 +3289,7 @@ func (bm *BlockMonitor) writeStegoToBlock(rec *services.IngestionRecord, destPat
 	if err != nil || len(stegoBytes) == 0 {
 		return false
 	}
-	if err := os.WriteFile(destPath, stegoBytes, 0644); err != nil {
+	if err := os.WriteFile(destPath, stegoBytes, bm.fileMode); err != nil {
 		log.Printf("failed to write stego image to block dir: %v", err)
 		return false
 	}
@@ -3256,8 +3586,7 @@ func visiblePixelHash(imageBytes []byte, message string) string {
 	if len(imageBytes) == 0 || message == "" {
 		return ""
 	}
-	sum := sha256.Sum256(imageBytes)
-	return fmt.Sprintf("%x", sum[:])
+	return stego.VisiblePixelHash(imageBytes)
 }
 
 func normalizeHex(value string) string {
@@ -3330,38 +3659,51 @@ func confidenceFromAny(value any) float64 {
 	return 0.0
 }
 
-func buildContractMetadata(result map[string]any) map[string]any {
-	return map[string]any{
-		"tx_id":             result["tx_id"],
-		"image_index":       result["image_index"],
-		"stego_type":        result["stego_type"],
-		"extracted_message": result["extracted_message"],
-		"scan_confidence":   result["confidence"],
-		"scan_timestamp":    result["scanned_at"],
-		"format":            result["format"],
-		"size_bytes":        result["size_bytes"],
+func buildContractMetadata(result ScanResultEntry) map[string]any {
+	meta := map[string]any{
+		"tx_id":             result.TxID,
+		"image_index":       result.ImageIndex,
+		"stego_type":        result.StegoType,
+		"extracted_message": result.ExtractedMessage,
+		"scan_confidence":   result.Confidence,
+		"scan_timestamp":    result.ScannedAt,
+		"format":            result.Format,
+		"size_bytes":        result.SizeBytes,
+	}
+	if len(result.Probabilities) > 0 {
+		meta["scan_probabilities"] = result.Probabilities
 	}
+	return meta
 }
 
-func updateContractEntry(contracts []SmartContractData, result map[string]any, updated SmartContractData) bool {
-	txID := stringFromAny(result["tx_id"])
-	imageIndex, hasIndex := intFromAny(result["image_index"])
+// contractIndexForResult returns the index of the entry created from result
+// (matched by tx_id and image_index), or -1.
+func contractIndexForResult(contracts []SmartContractData, result ScanResultEntry) int {
 	for i := range contracts {
 		if contracts[i].Metadata == nil {
 			continue
 		}
 		metaTx := stringFromAny(contracts[i].Metadata["tx_id"])
-		if metaTx == "" || metaTx != txID {
+		if metaTx == "" || metaTx != result.TxID {
 			continue
 		}
-		if hasIndex {
-			if metaIndex, ok := intFromAny(contracts[i].Metadata["image_index"]); ok && metaIndex != imageIndex {
-				continue
-			}
-		}
-		if contracts[i].Metadata == nil {
-			contracts[i].Metadata = map[string]any{}
+		if metaIndex, ok := intFromAny(contracts[i].Metadata["image_index"]); ok && metaIndex != result.ImageIndex {
+			continue
 		}
+		return i
+	}
+	return -1
+}
+
+// markContractReconciliation records status on the entry created from result.
+func markContractReconciliation(contracts []SmartContractData, result ScanResultEntry, status string) {
+	if i := contractIndexForResult(contracts, result); i >= 0 {
+		mergeContractProvenance(&contracts[i], SmartContractData{ReconciliationStatus: status})
+	}
+}
+
+func updateContractEntry(contracts []SmartContractData, result ScanResultEntry, updated SmartContractData) bool {
+	if i := contractIndexForResult(contracts, result); i >= 0 {
 		if updated.Metadata != nil {
 			contracts[i].Metadata = updated.Metadata
 		}
@@ -3371,6 +3713,7 @@ func updateContractEntry(contracts []SmartContractData, result map[string]any, u
 		if updated.Confidence > 0 {
 			contracts[i].Confidence = updated.Confidence
 		}
+		mergeContractProvenance(&contracts[i], updated)
 		return true
 	}
 	return false
@@ -3387,12 +3730,49 @@ func upsertContractByID(contracts []SmartContractData, updated SmartContractData
 			if updated.Confidence > 0 {
 				contracts[i].Confidence = updated.Confidence
 			}
+			mergeContractProvenance(&contracts[i], updated)
 			return contracts
 		}
 	}
 	return append(contracts, updated)
 }
 
+// mergeContractProvenance keeps the earliest FirstSeenAt of an entry, takes
+// the Source of the path that updated it last and never downgrades its
+// ReconciliationStatus.
+func mergeContractProvenance(existing *SmartContractData, updated SmartContractData) {
+	if updated.FirstSeenAt > 0 && (existing.FirstSeenAt == 0 || updated.FirstSeenAt < existing.FirstSeenAt) {
+		existing.FirstSeenAt = updated.FirstSeenAt
+	}
+	if updated.Source != "" {
+		existing.Source = updated.Source
+	}
+	if reconciliationRank(updated.ReconciliationStatus) > reconciliationRank(existing.ReconciliationStatus) {
+		existing.ReconciliationStatus = updated.ReconciliationStatus
+	}
+}
+
+func reconciliationRank(status string) int {
+	switch status {
+	case ReconciliationUnmatched:
+		return 1
+	case ReconciliationHashMatched:
+		return 2
+	case ReconciliationFullyMatched:
+		return 3
+	}
+	return 0
+}
+
+// ingestionFirstSeen returns when an ingested upload was first seen, falling
+// back to now for records without a creation time.
+func ingestionFirstSeen(rec *services.IngestionRecord) int64 {
+	if rec == nil || rec.CreatedAt.IsZero() {
+		return time.Now().Unix()
+	}
+	return rec.CreatedAt.Unix()
+}
+
 // GetBlockInscriptions retrieves inscriptions for a specific block height
 func (bm *BlockMonitor) GetBlockInscriptions(height int64) (*BlockInscriptionsResponse, error) {
 	// First, try to find existing block data
@@ -3430,22 +3810,5 @@ func (bm *BlockMonitor) GetBlockInscriptions(height int64) (*BlockInscriptionsRe
 
 // findBlockDirectory finds the directory for a given block height
 func (bm *BlockMonitor) findBlockDirectory(height int64) (string, error) {
-	entries, err := os.ReadDir(bm.blocksDir)
-	if err != nil {
-		return "", err
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			// Extract height from directory name (format: height_hash)
-			parts := strings.Split(entry.Name(), "_")
-			if len(parts) >= 1 {
-				if dirHeight, err := strconv.ParseInt(parts[0], 10, 64); err == nil && dirHeight == height {
-					return filepath.Join(bm.blocksDir, entry.Name()), nil
-				}
-			}
-		}
-	}
-
-	return "", fmt.Errorf("block directory not found for height %d", height)
+	return FindBlockDirectory(bm.blocksDir, height)
 }
@@ -0,0 +1,56 @@
+package bitcoin
+
+import (
+	"context"
+	"log"
+)
+
+// goLoop runs fn in a goroutine that Shutdown waits for.
+func (bm *BlockMonitor) goLoop(fn func()) {
+	bm.loops.Add(1)
+	go func() {
+		defer bm.loops.Done()
+		fn()
+	}()
+}
+
+// stopRequested reports whether Stop has been called since the last Start.
+func (bm *BlockMonitor) stopRequested() bool {
+	bm.mu.RLock()
+	stop := bm.stopChan
+	bm.mu.RUnlock()
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown stops the monitor and waits for its loops to return, so no block
+// directory is being written when it returns nil. A block already being
+// processed is finished first. It returns ctx.Err() if ctx ends before the
+// loops do. Shutdown on a monitor that is not running only cancels a
+// background backfill and waits.
+func (bm *BlockMonitor) Shutdown(ctx context.Context) error {
+	if bm.IsRunning() {
+		if err := bm.Stop(); err != nil {
+			log.Printf("Block monitor stop: %v", err)
+		}
+	} else {
+		bm.mu.Lock()
+		bm.cancelBackfill()
+		bm.mu.Unlock()
+	}
+	done := make(chan struct{})
+	go func() {
+		bm.loops.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,82 @@
+package bitcoin
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+func TestShutdownStopsMonitorAndWaitsForLoops(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.scanReadyWait = 0
+	if err := bm.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bm.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if bm.IsRunning() {
+		t.Fatal("monitor still running after Shutdown")
+	}
+	if !bm.stopRequested() {
+		t.Fatal("stop not signalled to the loops")
+	}
+	// A second call has nothing to stop and returns at once.
+	if err := bm.Shutdown(ctx); err != nil {
+		t.Fatalf("second shutdown: %v", err)
+	}
+}
+
+// enteredRawSource signals entered on the first fetch, before the gate.
+type enteredRawSource struct {
+	*gatedRawSource
+	once    sync.Once
+	entered chan struct{}
+}
+
+func (s *enteredRawSource) GetRawBlockHex(height int64) (string, error) {
+	s.once.Do(func() { close(s.entered) })
+	return s.gatedRawSource.GetRawBlockHex(height)
+}
+
+func TestShutdownCancelsAndWaitsForStartBackfill(t *testing.T) {
+	gated := &gatedRawSource{fakeRawSource: newFakeRawSource(), release: make(chan struct{})}
+	gated.addChain(t, 30, 32, chainhash.Hash{}, 0)
+	source := &enteredRawSource{gatedRawSource: gated, entered: make(chan struct{})}
+	bm := newTestBlockMonitor(t, source)
+	if err := bm.StartBackfill(30, 32); err != nil {
+		t.Fatalf("start backfill: %v", err)
+	}
+	select {
+	case <-source.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("backfill never fetched a block")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- bm.Shutdown(ctx) }()
+	select {
+	case err := <-done:
+		t.Fatalf("Shutdown returned %v while the backfill was mid-block", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(source.release)
+	if err := <-done; err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if got := source.fetchCount(31) + source.fetchCount(32); got != 0 {
+		t.Fatalf("backfill kept fetching after Shutdown: %d fetches", got)
+	}
+	if status := bm.BackfillStatus(); status.Running || status.Completed {
+		t.Fatalf("status = %+v, want a cancelled backfill", status)
+	}
+}
@@ -3,11 +3,14 @@ package bitcoin
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
 	"stargate-backend/core/smart_contract"
 )
 
@@ -157,3 +160,88 @@ func TestConfirmContractTasks_NoSweepDeps(t *testing.T) {
 	// Should not panic or error.
 	bm.confirmContractTasks("c", "tx", 0)
 }
+
+func TestProcessBlockSkipsCompleteDirectory(t *testing.T) {
+	source := newFakeRawSource()
+	source.addChain(t, 500, 500, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+
+	if err := bm.ProcessBlock(500); err != nil {
+		t.Fatalf("first process: %v", err)
+	}
+	if err := bm.ProcessBlock(500); err != nil {
+		t.Fatalf("second process: %v", err)
+	}
+	if got := source.fetchCount(500); got != 1 {
+		t.Fatalf("block fetched %d times, want 1", got)
+	}
+	if skipped := bm.GetStatistics()["blocks_skipped"]; skipped != int64(1) {
+		t.Fatalf("blocks_skipped = %v, want 1", skipped)
+	}
+
+	if err := bm.ReprocessBlock(500); err != nil {
+		t.Fatalf("forced reprocess: %v", err)
+	}
+	if got := source.fetchCount(500); got != 2 {
+		t.Fatalf("forced reprocess fetched %d times total, want 2", got)
+	}
+}
+
+func TestProcessBlockRejectsBrokenPrevBlockLink(t *testing.T) {
+	source := newFakeRawSource()
+	source.addChain(t, 700, 700, chainhash.Hash{}, 0)
+	// Height 701 claims a parent that is not the stored block 700.
+	source.addChain(t, 701, 701, chainhash.Hash{0xde, 0xad}, 0)
+	bm := newTestBlockMonitor(t, source)
+
+	if err := bm.ProcessBlock(700); err != nil {
+		t.Fatalf("process parent: %v", err)
+	}
+	err := bm.ProcessBlock(701)
+	var linkErr *BlockLinkError
+	if !errors.As(err, &linkErr) || linkErr.Field != "prev_block" {
+		t.Fatalf("ProcessBlock(701) error = %v, want prev_block BlockLinkError", err)
+	}
+	if _, err := FindBlockDirectory(bm.blocksDir, 701); err == nil {
+		t.Fatal("rejected block was persisted")
+	}
+}
+
+func TestSameStegoImageInTwoBlocksYieldsSingleContract(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 1}
+	source.addChain(t, 960, 961, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.notificationSinks = nil
+	bm.SetImageScanner(stegoScanner{})
+	sink := &recordingSink{}
+	bm.AddNotificationSink(sink)
+
+	for h := int64(960); h <= 961; h++ {
+		if err := bm.ProcessBlock(h); err != nil {
+			t.Fatalf("ProcessBlock(%d): %v", h, err)
+		}
+	}
+
+	contracts := bm.StegoContracts()
+	if len(contracts) != 1 {
+		t.Fatalf("got %d contracts, want 1: %+v", len(contracts), contracts)
+	}
+	got := contracts[0]
+	if got.BlockHeight != 961 {
+		t.Fatalf("block height = %d, want 961", got.BlockHeight)
+	}
+	if got.Metadata["first_seen_height"] != int64(960) {
+		t.Fatalf("first_seen_height = %v, want 960", got.Metadata["first_seen_height"])
+	}
+	if len(sink.events) != 2 || sink.events[0].ContractID != sink.events[1].ContractID || sink.events[0].ContractID != got.ContractID {
+		t.Fatalf("per-block contract IDs not stable: %+v", sink.events)
+	}
+
+	// A restarted monitor rebuilds the registry from the stored blocks.
+	restarted := newTestBlockMonitor(t, source)
+	restarted.blocksDir = bm.blocksDir
+	reloaded := restarted.StegoContracts()
+	if len(reloaded) != 1 || reloaded[0].ContractID != got.ContractID || reloaded[0].BlockHeight != 961 {
+		t.Fatalf("registry not rebuilt after restart: %+v", reloaded)
+	}
+}
@@ -0,0 +1,66 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"stargate-backend/security"
+)
+
+const (
+	defaultBlocksDirMode  os.FileMode = 0755
+	defaultBlocksFileMode os.FileMode = 0644
+)
+
+// blocksDirModeFromEnv returns the permission bits for directories created under
+// the blocks directory. Controlled by STARGATE_BLOCKS_DIR_MODE (octal, e.g. 0750).
+func blocksDirModeFromEnv() os.FileMode {
+	return fileModeFromEnv("STARGATE_BLOCKS_DIR_MODE", defaultBlocksDirMode)
+}
+
+// blocksFileModeFromEnv returns the permission bits for files written under the
+// blocks directory. Controlled by STARGATE_BLOCKS_FILE_MODE (octal, e.g. 0640).
+func blocksFileModeFromEnv() os.FileMode {
+	return fileModeFromEnv("STARGATE_BLOCKS_FILE_MODE", defaultBlocksFileMode)
+}
+
+func fileModeFromEnv(key string, fallback os.FileMode) os.FileMode {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	mode, err := strconv.ParseUint(raw, 8, 32)
+	if err != nil || mode > 0777 {
+		log.Printf("Ignoring invalid %s=%q, using %#o", key, raw, fallback)
+		return fallback
+	}
+	return os.FileMode(mode)
+}
+
+// blockDirName builds the <height>_<hash[:8]> directory name, refusing hashes
+// that are not plain hex so upstream data cannot steer the path.
+func blockDirName(height int64, hash string) (string, error) {
+	if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) < 4 {
+		return "", fmt.Errorf("invalid block hash %q", hash)
+	}
+	return fmt.Sprintf("%d_%s", height, hash[:8]), nil
+}
+
+// containImageFileNames rewrites image file names that are not a single plain
+// path element to their sanitized base name, so every later write (images/,
+// inscriptions.json, block.json) refers to a file inside the block directory.
+func containImageFileNames(images []ExtractedImageData) {
+	for i := range images {
+		name := images[i].FileName
+		if _, err := security.ContainedFilePath(".", name); err == nil {
+			continue
+		}
+		safe := security.SanitizeFilename(strings.ReplaceAll(name, `\`, "/"))
+		log.Printf("Rewriting unsafe image file name %q to %q", name, safe)
+		images[i].FileName = safe
+	}
+}
@@ -0,0 +1,83 @@
+package bitcoin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// maliciousImageSource attaches an image with a traversal file name to every block.
+type maliciousImageSource struct {
+	*fakeRawSource
+	fileName string
+}
+
+func (m *maliciousImageSource) ParseBlock(hexData string) (*ParsedBlock, error) {
+	parsed, err := m.fakeRawSource.ParseBlock(hexData)
+	if err != nil {
+		return nil, err
+	}
+	parsed.Images = append(parsed.Images, ExtractedImageData{
+		TxID:     "deadbeef",
+		Format:   "png",
+		FileName: m.fileName,
+		Data:     []byte("not really a png"),
+	})
+	return parsed, nil
+}
+
+func TestProcessBlockKeepsMaliciousImageNameInsideImagesDir(t *testing.T) {
+	source := &maliciousImageSource{fakeRawSource: newFakeRawSource(), fileName: "../../escape.png"}
+	source.addChain(t, 900, 900, chainhash.Hash{}, 0)
+	root := t.TempDir()
+	bm := newTestBlockMonitor(t, source)
+	bm.blocksDir = filepath.Join(root, "blocks")
+	bm.dirMode = 0750
+	bm.fileMode = 0640
+
+	if err := bm.ProcessBlock(900); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	dir, err := FindBlockDirectory(bm.blocksDir, 900)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, escaped := range []string{
+		filepath.Join(root, "escape.png"),
+		filepath.Join(bm.blocksDir, "escape.png"),
+		filepath.Join(dir, "escape.png"),
+	} {
+		if _, err := os.Stat(escaped); err == nil {
+			t.Fatalf("image escaped to %s", escaped)
+		}
+	}
+
+	written := filepath.Join(dir, "images", "escape.png")
+	info, err := os.Stat(written)
+	if err != nil {
+		t.Fatalf("image not written inside images dir: %v", err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Fatalf("image mode = %v, want 0640", info.Mode().Perm())
+	}
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dirInfo.Mode().Perm()&^0750 != 0 {
+		t.Fatalf("block dir mode = %v, want at most 0750", dirInfo.Mode().Perm())
+	}
+}
+
+func TestBlockDirNameRejectsNonHexHash(t *testing.T) {
+	if _, err := blockDirName(1, "../../etc"); err == nil {
+		t.Fatal("expected error for non-hex hash")
+	}
+	name, err := blockDirName(7, "00000000abcdef")
+	if err != nil || name != "7_00000000" {
+		t.Fatalf("blockDirName = %q, %v", name, err)
+	}
+}
@@ -0,0 +1,128 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxReorgDepth bounds how far back the monitor walks looking for a fork point.
+const maxReorgDepth = 6
+
+// orphanMarkerFile is written into every block directory moved to blocks/reorgs.
+const orphanMarkerFile = "orphaned.json"
+
+// orphanMarker records why a block directory was retired.
+type orphanMarker struct {
+	Height       int64     `json:"height"`
+	Hash         string    `json:"hash"`
+	ReplacedBy   string    `json:"replaced_by"`
+	OrphanedAt   time.Time `json:"orphaned_at"`
+	OriginalPath string    `json:"original_path"`
+}
+
+// processNextBlock processes height while following the chain forward. When the
+// fetched block does not link to the stored parent, the monitor treats it as a
+// reorg: the stale heights are rewritten from the new chain and height is retried.
+func (bm *BlockMonitor) processNextBlock(height int64) error {
+	err := bm.ProcessBlock(height)
+	var linkErr *BlockLinkError
+	if !errors.As(err, &linkErr) || linkErr.Field != "prev_block" {
+		return err
+	}
+
+	log.Printf("Reorg detected at height %d: parent %s is not stored block %s", height, linkErr.Got, linkErr.Expected)
+	fork, err := bm.handleReorg(height)
+	if err != nil {
+		return fmt.Errorf("reorg at height %d: %w", height, err)
+	}
+	log.Printf("Reorg resolved: fork point %d, rewrote heights %d-%d", fork, fork+1, height-1)
+	return bm.ProcessBlock(height)
+}
+
+// staleBlock is a stored height whose block upstream no longer serves, with
+// the hash that replaced it.
+type staleBlock struct {
+	height     int64
+	replacedBy string
+}
+
+// handleReorg walks back from height-1 until the block served upstream matches the
+// stored one, moves each stale directory to blocks/reorgs (marked as orphaned) and
+// reprocesses those heights in ascending order. It returns the fork height.
+// Nothing on disk is touched until the fork point has been found.
+func (bm *BlockMonitor) handleReorg(height int64) (int64, error) {
+	bm.reconcileMu.Lock()
+	defer bm.reconcileMu.Unlock()
+
+	var stale []staleBlock
+	fork := int64(-1)
+	for h := height - 1; h >= 0 && height-h <= maxReorgDepth; h-- {
+		stored, ok := bm.storedBlockHash(h)
+		if !ok {
+			fork = h
+			break
+		}
+		fetched, err := bm.fetchBlockHash(h)
+		if err != nil {
+			return 0, err
+		}
+		if fetched == stored {
+			fork = h
+			break
+		}
+		stale = append(stale, staleBlock{height: h, replacedBy: fetched})
+	}
+	if fork < 0 {
+		return 0, fmt.Errorf("no fork point within %d blocks", maxReorgDepth)
+	}
+	if len(stale) == 0 {
+		return 0, fmt.Errorf("stored block %d still matches upstream", height-1)
+	}
+
+	for _, b := range stale {
+		if _, err := bm.pruneBlockDirsForHeight(b.height, b.replacedBy); err != nil {
+			return 0, fmt.Errorf("orphan block %d: %w", b.height, err)
+		}
+	}
+	for i := len(stale) - 1; i >= 0; i-- {
+		if err := bm.ReprocessBlock(stale[i].height); err != nil {
+			return 0, fmt.Errorf("reprocess block %d: %w", stale[i].height, err)
+		}
+	}
+
+	bm.recordReorgHandled()
+	return fork, nil
+}
+
+// fetchBlockHash returns the header hash of the block currently served for height.
+func (bm *BlockMonitor) fetchBlockHash(height int64) (string, error) {
+	hexData, err := bm.fetchRawBlockHex(height)
+	if err != nil {
+		return "", fmt.Errorf("failed to get raw block hex: %w", err)
+	}
+	parsed, err := bm.rawClient.ParseBlock(hexData)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse block: %w", err)
+	}
+	return parsed.Header.Hash, nil
+}
+
+// writeOrphanMarker records in dir that the block it holds was replaced by replacedBy.
+func writeOrphanMarker(dir, originalPath string, height int64, hash, replacedBy string, mode os.FileMode) error {
+	data, err := json.MarshalIndent(orphanMarker{
+		Height:       height,
+		Hash:         hash,
+		ReplacedBy:   replacedBy,
+		OrphanedAt:   time.Now().UTC(),
+		OriginalPath: originalPath,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, orphanMarkerFile), data, mode)
+}
@@ -0,0 +1,88 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+func TestProcessNextBlockHandlesOneBlockReorg(t *testing.T) {
+	source := newFakeRawSource()
+	original := source.addChain(t, 800, 801, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+
+	for h := int64(800); h <= 801; h++ {
+		if err := bm.processNextBlock(h); err != nil {
+			t.Fatalf("process %d: %v", h, err)
+		}
+	}
+
+	// Replace 801 with a competing block on the same parent and extend it.
+	parent, err := chainhash.NewHashFromStr(original[800])
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaced := source.addChain(t, 801, 802, *parent, 1000)
+	if replaced[801] == original[801] {
+		t.Fatal("test chains must differ at 801")
+	}
+
+	if err := bm.processNextBlock(802); err != nil {
+		t.Fatalf("process 802 across reorg: %v", err)
+	}
+
+	if got, _ := bm.storedBlockHash(801); got != replaced[801] {
+		t.Fatalf("height 801 hash = %s, want reprocessed %s", got, replaced[801])
+	}
+	if got, _ := bm.storedBlockHash(802); got != replaced[802] {
+		t.Fatalf("height 802 hash = %s, want %s", got, replaced[802])
+	}
+	if got, _ := bm.storedBlockHash(800); got != original[800] {
+		t.Fatalf("fork point 800 was rewritten: %s", got)
+	}
+
+	orphanDir := filepath.Join(bm.blocksDir, "reorgs", "801_"+original[801][:8])
+	data, err := os.ReadFile(filepath.Join(orphanDir, orphanMarkerFile))
+	if err != nil {
+		t.Fatalf("orphan marker: %v", err)
+	}
+	var marker orphanMarker
+	if err := json.Unmarshal(data, &marker); err != nil {
+		t.Fatal(err)
+	}
+	if marker.Hash != original[801] || marker.ReplacedBy != replaced[801] {
+		t.Fatalf("marker = %+v", marker)
+	}
+	if got := bm.GetStatistics()["reorgs_handled"]; got != int64(1) {
+		t.Fatalf("reorgs_handled = %v, want 1", got)
+	}
+}
+
+func TestHandleReorgWithoutForkPointKeepsStoredBlocks(t *testing.T) {
+	source := newFakeRawSource()
+	original := source.addChain(t, 800, 800+maxReorgDepth+1, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	for h := int64(800); h <= 800+maxReorgDepth+1; h++ {
+		if err := bm.processNextBlock(h); err != nil {
+			t.Fatalf("process %d: %v", h, err)
+		}
+	}
+
+	// Replace the whole stored range with a chain that shares none of it.
+	source.addChain(t, 800, 800+maxReorgDepth+2, chainhash.Hash{}, 1000)
+	if err := bm.processNextBlock(800 + maxReorgDepth + 2); err == nil {
+		t.Fatal("expected an error when no fork point is within reach")
+	}
+
+	for h, want := range original {
+		if got, _ := bm.storedBlockHash(h); got != want {
+			t.Fatalf("height %d hash = %q, want untouched %s", h, got, want)
+		}
+	}
+	if entries, _ := os.ReadDir(filepath.Join(bm.blocksDir, "reorgs")); len(entries) != 0 {
+		t.Fatalf("orphaned %d block dirs on the error path", len(entries))
+	}
+}
@@ -0,0 +1,167 @@
+package bitcoin
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultBlockPruneInterval = time.Hour
+
+// BlockRetention limits how many block directories are kept under blocksDir.
+// A directory is pruned once it falls outside any configured limit; the zero
+// value keeps everything.
+type BlockRetention struct {
+	KeepBlocks int           // keep the directories of the newest N heights; 0 disables
+	MaxAge     time.Duration // keep directories written within this window; 0 disables
+	Interval   time.Duration // how often the background pruner runs
+}
+
+// Enabled reports whether any retention limit is set.
+func (r BlockRetention) Enabled() bool {
+	return r.KeepBlocks > 0 || r.MaxAge > 0
+}
+
+// blockRetentionFromEnv reads the retention policy from
+// STARGATE_BLOCK_RETENTION_BLOCKS, STARGATE_BLOCK_RETENTION_DAYS and
+// STARGATE_BLOCK_PRUNE_INTERVAL (Go duration). Pruning is off unless one of
+// the limits is set.
+func blockRetentionFromEnv() BlockRetention {
+	r := BlockRetention{Interval: defaultBlockPruneInterval}
+	if raw := strings.TrimSpace(os.Getenv("STARGATE_BLOCK_RETENTION_BLOCKS")); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+			r.KeepBlocks = n
+		} else {
+			log.Printf("Ignoring invalid STARGATE_BLOCK_RETENTION_BLOCKS=%q", raw)
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("STARGATE_BLOCK_RETENTION_DAYS")); raw != "" {
+		if days, err := strconv.ParseFloat(raw, 64); err == nil && days >= 0 {
+			r.MaxAge = time.Duration(days * float64(24*time.Hour))
+		} else {
+			log.Printf("Ignoring invalid STARGATE_BLOCK_RETENTION_DAYS=%q", raw)
+		}
+	}
+	if raw := strings.TrimSpace(os.Getenv("STARGATE_BLOCK_PRUNE_INTERVAL")); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil && d > 0 {
+			r.Interval = d
+		} else {
+			log.Printf("Ignoring invalid STARGATE_BLOCK_PRUNE_INTERVAL=%q, using %v", raw, defaultBlockPruneInterval)
+		}
+	}
+	return r
+}
+
+// SetBlockRetention replaces the retention policy. It takes effect on the
+// next prune; the background pruner only runs if a limit was set at Start.
+func (bm *BlockMonitor) SetBlockRetention(r BlockRetention) {
+	if r.Interval <= 0 {
+		r.Interval = defaultBlockPruneInterval
+	}
+	bm.retention = r
+}
+
+// pruneLoop applies the retention policy at start-up and then every
+// retention.Interval until the monitor stops.
+func (bm *BlockMonitor) pruneLoop() {
+	ticker := time.NewTicker(bm.retention.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := bm.PruneBlockDirectories(); err != nil {
+			log.Printf("Block retention: prune failed: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-bm.stopChan:
+			return
+		}
+	}
+}
+
+// PruneBlockDirectories deletes block directories outside the retention
+// policy and refreshes recent-blocks.json, returning how many were removed.
+// Heights that are being processed are skipped and reconsidered next time.
+func (bm *BlockMonitor) PruneBlockDirectories() (int, error) {
+	if !bm.retention.Enabled() {
+		return 0, nil
+	}
+	entries, err := os.ReadDir(bm.blocksDir)
+	if err != nil {
+		return 0, err
+	}
+
+	type blockDir struct {
+		name    string
+		height  int64
+		modTime time.Time
+	}
+	var dirs []blockDir
+	seen := make(map[int64]bool)
+	var heights []int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		height, ok := heightFromBlockDirName(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		dirs = append(dirs, blockDir{entry.Name(), height, info.ModTime()})
+		if !seen[height] {
+			seen[height] = true
+			heights = append(heights, height)
+		}
+	}
+
+	keep := make(map[int64]bool)
+	if bm.retention.KeepBlocks > 0 {
+		sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+		for i, h := range heights {
+			if i >= bm.retention.KeepBlocks {
+				break
+			}
+			keep[h] = true
+		}
+	}
+	cutoff := time.Now().Add(-bm.retention.MaxAge)
+
+	var removed []string
+	for _, dir := range dirs {
+		tooMany := bm.retention.KeepBlocks > 0 && !keep[dir.height]
+		tooOld := bm.retention.MaxAge > 0 && dir.modTime.Before(cutoff)
+		if !tooMany && !tooOld {
+			continue
+		}
+		unlock, ok := bm.processLocks.tryLock(dir.height)
+		if !ok {
+			log.Printf("Block retention: %s is being processed, skipping", dir.name)
+			continue
+		}
+		err := os.RemoveAll(filepath.Join(bm.blocksDir, dir.name))
+		unlock()
+		if err != nil {
+			return len(removed), fmt.Errorf("failed to remove %s: %w", dir.name, err)
+		}
+		log.Printf("Block retention: removed %s", dir.name)
+		removed = append(removed, dir.name)
+	}
+
+	if len(removed) > 0 {
+		if err := bm.unindexBlockDirs(removed...); err != nil {
+			return len(removed), err
+		}
+		if err := bm.updateRecentBlocksSummary(); err != nil {
+			return len(removed), err
+		}
+	}
+	return len(removed), nil
+}
@@ -0,0 +1,128 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func seedBlockDir(t *testing.T, blocksDir string, height int64, modTime time.Time) string {
+	t.Helper()
+	dir := filepath.Join(blocksDir, fmt.Sprintf("%d_%08x", height, height))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	summary := fmt.Sprintf(`{"block_height": %d}`, height)
+	if err := os.WriteFile(filepath.Join(dir, "inscriptions.json"), []byte(summary), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(dir, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func remainingHeights(t *testing.T, blocksDir string) []int64 {
+	t.Helper()
+	entries, err := os.ReadDir(blocksDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var heights []int64
+	for _, e := range entries {
+		if h, ok := heightFromBlockDirName(e.Name()); ok && e.IsDir() {
+			heights = append(heights, h)
+		}
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
+
+func TestPruneBlockDirectoriesKeepsNewestBlocks(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	now := time.Now()
+	for h := int64(100); h <= 105; h++ {
+		seedBlockDir(t, bm.blocksDir, h, now)
+	}
+	if err := os.MkdirAll(filepath.Join(bm.blocksDir, "reorgs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	bm.SetBlockRetention(BlockRetention{KeepBlocks: 3})
+
+	// Height 100 is mid-processing and must survive this round.
+	unlock := bm.processLocks.lock(100)
+	removed, err := bm.PruneBlockDirectories()
+	unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 2 {
+		t.Fatalf("removed %d directories, want 2", removed)
+	}
+	if got := remainingHeights(t, bm.blocksDir); fmt.Sprint(got) != "[100 103 104 105]" {
+		t.Fatalf("remaining heights = %v, want [100 103 104 105]", got)
+	}
+	if _, err := os.Stat(filepath.Join(bm.blocksDir, "reorgs")); err != nil {
+		t.Fatalf("non-block directory was removed: %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(bm.blocksDir, "recent-blocks.json"))
+	if err != nil {
+		t.Fatalf("recent-blocks.json not refreshed: %v", err)
+	}
+	var recent struct {
+		Total int `json:"total"`
+	}
+	if err := json.Unmarshal(raw, &recent); err != nil {
+		t.Fatal(err)
+	}
+	if recent.Total != 4 {
+		t.Fatalf("recent-blocks.json lists %d blocks, want 4", recent.Total)
+	}
+
+	// Once processing finishes the leftover directory is pruned too.
+	if _, err := bm.PruneBlockDirectories(); err != nil {
+		t.Fatal(err)
+	}
+	if got := remainingHeights(t, bm.blocksDir); fmt.Sprint(got) != "[103 104 105]" {
+		t.Fatalf("remaining heights = %v, want [103 104 105]", got)
+	}
+}
+
+func TestPruneBlockDirectoriesByAge(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	now := time.Now()
+	seedBlockDir(t, bm.blocksDir, 200, now.Add(-10*24*time.Hour))
+	seedBlockDir(t, bm.blocksDir, 201, now.Add(-8*24*time.Hour))
+	seedBlockDir(t, bm.blocksDir, 202, now.Add(-time.Hour))
+
+	// Disabled by default.
+	if removed, err := bm.PruneBlockDirectories(); err != nil || removed != 0 {
+		t.Fatalf("default policy removed %d (err %v), want nothing", removed, err)
+	}
+
+	bm.SetBlockRetention(BlockRetention{MaxAge: 7 * 24 * time.Hour})
+	if _, err := bm.PruneBlockDirectories(); err != nil {
+		t.Fatal(err)
+	}
+	if got := remainingHeights(t, bm.blocksDir); fmt.Sprint(got) != "[202]" {
+		t.Fatalf("remaining heights = %v, want [202]", got)
+	}
+}
+
+func TestBlockRetentionFromEnv(t *testing.T) {
+	if blockRetentionFromEnv().Enabled() {
+		t.Fatal("retention enabled without configuration")
+	}
+	t.Setenv("STARGATE_BLOCK_RETENTION_BLOCKS", "1000")
+	t.Setenv("STARGATE_BLOCK_RETENTION_DAYS", "1.5")
+	t.Setenv("STARGATE_BLOCK_PRUNE_INTERVAL", "10m")
+	r := blockRetentionFromEnv()
+	if r.KeepBlocks != 1000 || r.MaxAge != 36*time.Hour || r.Interval != 10*time.Minute {
+		t.Fatalf("retention = %+v", r)
+	}
+}
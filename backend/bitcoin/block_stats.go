@@ -0,0 +1,77 @@
+package bitcoin
+
+import (
+	"log"
+	"time"
+)
+
+// The statistics counters are shared between the monitor loop, backfills,
+// reorg handling and API-triggered processing, and read by GetStatistics.
+// Every mutation goes through the helpers below so it happens under bm.mu.
+
+// recordBlockStats adds a processed block's totals to the statistics.
+func (bm *BlockMonitor) recordBlockStats(processingTime time.Duration, transactions, images, inscriptions, stego int) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.lastProcessTime = processingTime
+	bm.totalTransactions += int64(transactions)
+	bm.totalImages += int64(images)
+	bm.totalInscriptions += int64(inscriptions)
+	bm.totalStegoContracts += int64(stego)
+}
+
+// recordBlockSkipped counts a height skipped because its directory was complete.
+func (bm *BlockMonitor) recordBlockSkipped() {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.blocksSkipped++
+}
+
+// recordReorgHandled counts a resolved chain reorganisation.
+func (bm *BlockMonitor) recordReorgHandled() {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.reorgsHandled++
+}
+
+// recordRawBlockRetry counts a rate-limited raw block download that is retried.
+func (bm *BlockMonitor) recordRawBlockRetry() {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.rawBlockRetries++
+}
+
+// recordRawBlockRetriesExhausted counts a download still rate limited after
+// the last retry.
+func (bm *BlockMonitor) recordRawBlockRetriesExhausted() {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.rawBlockRetriesExhausted++
+}
+
+// recordRawBlockNotFound counts a height the raw block sources do not have.
+func (bm *BlockMonitor) recordRawBlockNotFound() {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.rawBlockNotFound++
+}
+
+// advanceCurrentHeight records that the forward monitor finished height and
+// persists it for the next start.
+func (bm *BlockMonitor) advanceCurrentHeight(height int64) {
+	bm.mu.Lock()
+	bm.currentHeight = height
+	bm.blocksProcessed++
+	bm.mu.Unlock()
+
+	if err := bm.saveMonitorHeight(height); err != nil {
+		log.Printf("Failed to persist monitor height %d: %v", height, err)
+	}
+}
+
+// monitorHeight returns the last height finished by the forward monitor.
+func (bm *BlockMonitor) monitorHeight() int64 {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+	return bm.currentHeight
+}
@@ -0,0 +1,69 @@
+package bitcoin
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// Run with -race: statistics must be readable while blocks are processed and
+// never go backwards.
+func TestGetStatisticsDuringConcurrentProcessing(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 1}
+	source.addChain(t, 1100, 1107, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.SetImageScanner(stegoScanner{})
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for r := 0; r < 2; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			var lastTx, lastImages int64
+			for {
+				stats := bm.GetStatistics()
+				tx := stats["total_transactions"].(int64)
+				images := stats["total_images"].(int64)
+				if tx < lastTx || images < lastImages {
+					t.Errorf("statistics went backwards: %+v", stats)
+					return
+				}
+				lastTx, lastImages = tx, images
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		}()
+	}
+
+	var workers sync.WaitGroup
+	for height := int64(1100); height <= 1107; height++ {
+		workers.Add(1)
+		go func(height int64) {
+			defer workers.Done()
+			if err := bm.ProcessBlock(height); err != nil {
+				t.Errorf("ProcessBlock(%d): %v", height, err)
+			}
+			bm.advanceCurrentHeight(height)
+		}(height)
+	}
+	workers.Wait()
+	close(done)
+	readers.Wait()
+
+	stats := bm.GetStatistics()
+	for key, want := range map[string]int64{
+		"total_transactions":    8,
+		"total_images":          8,
+		"total_stego_contracts": 8,
+		"blocks_processed":      8,
+	} {
+		if stats[key] != want {
+			t.Fatalf("%s = %v, want %d", key, stats[key], want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package bitcoin
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BlockLinkError reports a fetched block that does not fit the locally stored chain.
+type BlockLinkError struct {
+	Height   int64
+	Field    string // "prev_block" or "hash"
+	Expected string
+	Got      string
+}
+
+func (e *BlockLinkError) Error() string {
+	return fmt.Sprintf("block %d %s mismatch: expected %s, got %s", e.Height, e.Field, e.Expected, e.Got)
+}
+
+// crossCheckHashEnabled reports whether fetched block hashes should also be
+// compared against the node client's /block-height endpoint.
+// Controlled by STARGATE_BLOCK_HASH_CROSSCHECK.
+func crossCheckHashEnabled() bool {
+	v, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("STARGATE_BLOCK_HASH_CROSSCHECK")))
+	return err == nil && v
+}
+
+// verifyBlockLink checks that parsed is really the block at height before it is
+// persisted: its prev_block must equal the stored hash of height-1 (when that
+// block has been processed), and, if enabled, its hash must match the second
+// source's canonical hash for the height.
+func (bm *BlockMonitor) verifyBlockLink(height int64, parsed *ParsedBlock) error {
+	if prevHash, ok := bm.storedBlockHash(height - 1); ok && prevHash != parsed.Header.PrevBlock {
+		return &BlockLinkError{Height: height, Field: "prev_block", Expected: prevHash, Got: parsed.Header.PrevBlock}
+	}
+
+	if bm.crossCheckHash && bm.bitcoinClient != nil {
+		canonical, err := bm.getCanonicalBlockHash(height)
+		if err != nil {
+			log.Printf("Block %d: hash cross-check unavailable: %v", height, err)
+		} else if canonical != "" && canonical != parsed.Header.Hash {
+			return &BlockLinkError{Height: height, Field: "hash", Expected: canonical, Got: parsed.Header.Hash}
+		}
+	}
+	return nil
+}
+
+// storedBlockHash returns the header hash recorded in block.json for height.
+func (bm *BlockMonitor) storedBlockHash(height int64) (string, bool) {
+	if height < 0 {
+		return "", false
+	}
+	dir, err := FindBlockDirectory(bm.blocksDir, height)
+	if err != nil {
+		return "", false
+	}
+	hash, err := readBlockHeaderHash(filepath.Join(dir, "block.json"))
+	if err != nil || hash == "" {
+		return "", false
+	}
+	return hash, true
+}
@@ -0,0 +1,44 @@
+package bitcoin
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DustLimitSats is Bitcoin Core's standard dust threshold for a P2PKH output
+	// at the default 3 sat/vB dust relay fee. It is the largest threshold of the
+	// standard output types, so a commitment output at or above it relays on
+	// every network whatever address type it pays.
+	DustLimitSats int64 = 546
+	// DefaultCommitmentSats is the commitment output value used when a PSBT
+	// request leaves commitment_sats unset.
+	DefaultCommitmentSats int64 = 1000
+)
+
+// CommitmentSatsFromEnv returns the default commitment output value:
+// STARGATE_COMMITMENT_SATS when set to a value at or above the dust limit,
+// otherwise DefaultCommitmentSats.
+func CommitmentSatsFromEnv() int64 {
+	raw := strings.TrimSpace(os.Getenv("STARGATE_COMMITMENT_SATS"))
+	if raw == "" {
+		return DefaultCommitmentSats
+	}
+	sats, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || sats < DustLimitSats {
+		log.Printf("Ignoring invalid STARGATE_COMMITMENT_SATS=%q (minimum %d), using %d", raw, DustLimitSats, DefaultCommitmentSats)
+		return DefaultCommitmentSats
+	}
+	return sats
+}
+
+// ValidateCommitmentSats rejects commitment output values that would be dust.
+func ValidateCommitmentSats(sats int64) error {
+	if sats < DustLimitSats {
+		return fmt.Errorf("commitment_sats %d is below the dust limit of %d sats; omit it to use the default of %d", sats, DustLimitSats, CommitmentSatsFromEnv())
+	}
+	return nil
+}
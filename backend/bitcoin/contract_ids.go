@@ -0,0 +1,113 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// legacyContractIDPattern matches the stego_{image_index}_{unixtime} IDs
+// written before contract IDs were derived from the block contents.
+var legacyContractIDPattern = regexp.MustCompile(`^stego_\d+_\d{9,}$`)
+
+// stegoContractID returns the deterministic ID of a detection: stego_ followed
+// by the visible_pixel_hash when the image is known, otherwise by the block
+// height, tx_id and image_index, so reprocessing a block yields the same IDs.
+func stegoContractID(visibleHash string, blockHeight int64, txID string, imageIndex int) string {
+	if visibleHash != "" {
+		return "stego_" + visibleHash
+	}
+	if txID == "" {
+		txID = "unknown"
+	}
+	return fmt.Sprintf("stego_%d_%s_%d", blockHeight, txID, imageIndex)
+}
+
+// MigrateContractIDs rewrites legacy timestamp-based contract IDs in every
+// inscriptions.json under blocksDir to the deterministic scheme used by
+// stegoContractID, and returns how many IDs were changed. Running it again is
+// a no-op.
+func MigrateContractIDs(blocksDir string) (int, error) {
+	entries, err := os.ReadDir(blocksDir)
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		height, ok := heightFromBlockDirName(entry.Name())
+		if !ok {
+			continue
+		}
+		n, err := migrateSummaryContractIDs(filepath.Join(blocksDir, entry.Name(), "inscriptions.json"), height)
+		if err != nil {
+			return migrated, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		migrated += n
+	}
+	return migrated, nil
+}
+
+func migrateSummaryContractIDs(summaryPath string, height int64) (int, error) {
+	raw, err := os.ReadFile(summaryPath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var summary map[string]any
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		return 0, fmt.Errorf("decode summary: %w", err)
+	}
+	contracts, err := summaryContracts(summary)
+	if err != nil {
+		return 0, fmt.Errorf("decode smart_contracts: %w", err)
+	}
+
+	migrated := 0
+	var out []SmartContractData
+	for _, contract := range contracts {
+		if legacyContractIDPattern.MatchString(contract.ContractID) {
+			blockHeight := contract.BlockHeight
+			if blockHeight == 0 {
+				blockHeight = height
+			}
+			index, _ := intFromAny(contract.Metadata["image_index"])
+			contract.ContractID = stegoContractID(
+				strings.TrimSpace(stringFromAny(contract.Metadata["visible_pixel_hash"])),
+				blockHeight,
+				strings.TrimSpace(stringFromAny(contract.Metadata["tx_id"])),
+				index,
+			)
+			migrated++
+		}
+		out = upsertContractByID(out, contract)
+	}
+	if migrated == 0 {
+		return 0, nil
+	}
+
+	summary["smart_contracts"] = out
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("encode summary: %w", err)
+	}
+	info, err := os.Stat(summaryPath)
+	if err != nil {
+		return 0, err
+	}
+	tmp := summaryPath + ".tmp"
+	if err := os.WriteFile(tmp, data, info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, summaryPath); err != nil {
+		return 0, err
+	}
+	return migrated, nil
+}
@@ -0,0 +1,122 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+func storedContractIDs(t *testing.T, bm *BlockMonitor, height int64) []string {
+	t.Helper()
+	dir, err := FindBlockDirectory(bm.blocksDir, height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "inscriptions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary map[string]any
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		t.Fatal(err)
+	}
+	contracts, err := summaryContracts(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, c := range contracts {
+		ids = append(ids, c.ContractID)
+	}
+	return ids
+}
+
+func TestReprocessingYieldsIdenticalContractIDs(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 2}
+	source.addChain(t, 975, 975, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.SetImageScanner(stegoScanner{})
+
+	if err := bm.ProcessBlock(975); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	first := storedContractIDs(t, bm, 975)
+	if len(first) == 0 {
+		t.Fatal("no contracts recorded")
+	}
+	if err := bm.ReprocessBlock(975); err != nil {
+		t.Fatalf("ReprocessBlock: %v", err)
+	}
+	if second := storedContractIDs(t, bm, 975); strings.Join(second, ",") != strings.Join(first, ",") {
+		t.Fatalf("reprocessing changed contract IDs: %v -> %v", first, second)
+	}
+
+	// Without an image to hash, the ID falls back to height, tx_id and image_index.
+	results := []ScanResultEntry{{TxID: "tx9", ImageIndex: 3, IsStego: true, Confidence: 0.9}}
+	contracts := bm.createSmartContractsFromScanResults(results, nil, 975)
+	if len(contracts) != 1 || contracts[0].ContractID != "stego_975_tx9_3" {
+		t.Fatalf("fallback contracts = %+v", contracts)
+	}
+}
+
+func TestMigrateContractIDs(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "830001_0000abcd")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	hash := strings.Repeat("ab", 32)
+	summary := map[string]any{
+		"block_height": 830001,
+		"smart_contracts": []SmartContractData{
+			{ContractID: "stego_0_1700000000", BlockHeight: 830001, Metadata: map[string]any{"tx_id": "tx1", "image_index": 0, "visible_pixel_hash": hash}},
+			{ContractID: "stego_1_1700000001", BlockHeight: 830001, Metadata: map[string]any{"tx_id": "tx2", "image_index": 1}},
+			{ContractID: "stego_" + hash, BlockHeight: 830001, Confidence: 0.8, Metadata: map[string]any{"visible_pixel_hash": hash}},
+			{ContractID: "proposal-1", BlockHeight: 830001},
+		},
+	}
+	raw, err := json.Marshal(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaryPath := filepath.Join(dir, "inscriptions.json")
+	if err := os.WriteFile(summaryPath, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := MigrateContractIDs(root)
+	if err != nil || n != 2 {
+		t.Fatalf("MigrateContractIDs = %d, %v; want 2", n, err)
+	}
+	stored, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var migrated map[string]any
+	if err := json.Unmarshal(stored, &migrated); err != nil {
+		t.Fatal(err)
+	}
+	contracts, err := summaryContracts(migrated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, c := range contracts {
+		ids = append(ids, c.ContractID)
+	}
+	want := []string{"stego_" + hash, "stego_830001_tx2_1", "proposal-1"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Fatalf("migrated IDs = %v, want %v", ids, want)
+	}
+	if contracts[0].Confidence != 0.8 {
+		t.Fatalf("duplicate entries not merged: %+v", contracts[0])
+	}
+
+	if n, err := MigrateContractIDs(root); err != nil || n != 0 {
+		t.Fatalf("second run = %d, %v; want no-op", n, err)
+	}
+}
@@ -0,0 +1,128 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrContractNotFound is returned by LinkContractToIngestion when no processed
+// block records a contract with the requested ID.
+var ErrContractNotFound = errors.New("contract not found")
+
+// ErrIngestionNotFound is returned by LinkContractToIngestion when the
+// ingestion record does not exist.
+var ErrIngestionNotFound = errors.New("ingestion record not found")
+
+// ErrIngestionUnavailable is returned by LinkContractToIngestion when no
+// ingestion service is configured.
+var ErrIngestionUnavailable = errors.New("ingestion service not available")
+
+// ErrVisibleHashMismatch is returned by LinkContractToIngestion when the
+// contract and the ingestion record describe different images.
+var ErrVisibleHashMismatch = errors.New("visible_pixel_hash mismatch")
+
+// LinkContractToIngestion manually associates a detected contract with an
+// ingestion record when automatic reconciliation could not. The record's
+// visible_pixel_hash (its ID when the metadata has none) must match the
+// contract's; the ingestion metadata is then merged into the contract, which
+// is marked fully_matched and rewritten in the block's inscriptions.json.
+func (bm *BlockMonitor) LinkContractToIngestion(contractID, ingestionID string) (*SmartContractData, error) {
+	contractID = strings.TrimSpace(contractID)
+	ingestionID = strings.TrimSpace(ingestionID)
+	if bm.ingestion == nil {
+		return nil, ErrIngestionUnavailable
+	}
+	rec, err := bm.ingestion.Get(ingestionID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrIngestionNotFound, ingestionID)
+	}
+	recHash := strings.TrimSpace(stringFromAny(rec.Metadata["visible_pixel_hash"]))
+	if recHash == "" {
+		recHash = rec.ID
+	}
+
+	bm.reconcileMu.Lock()
+	defer bm.reconcileMu.Unlock()
+
+	entries, err := os.ReadDir(bm.blocksDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, ok := heightFromBlockDirName(entry.Name()); !ok {
+			continue
+		}
+		summaryPath := filepath.Join(bm.blocksDir, entry.Name(), "inscriptions.json")
+		raw, err := os.ReadFile(summaryPath)
+		if err != nil {
+			continue
+		}
+		var summary map[string]any
+		if err := json.Unmarshal(raw, &summary); err != nil {
+			continue
+		}
+		contracts, err := summaryContracts(summary)
+		if err != nil {
+			continue
+		}
+		for i := range contracts {
+			if contracts[i].ContractID != contractID {
+				continue
+			}
+			contract := &contracts[i]
+			contractHash := strings.TrimSpace(stringFromAny(contract.Metadata["visible_pixel_hash"]))
+			if contractHash == "" || !strings.EqualFold(contractHash, recHash) {
+				return nil, fmt.Errorf("%w: contract %q has %q, ingestion %s has %q", ErrVisibleHashMismatch, contractID, contractHash, rec.ID, recHash)
+			}
+
+			if contract.Metadata == nil {
+				contract.Metadata = map[string]any{}
+			}
+			contract.Metadata["ingestion_id"] = rec.ID
+			contract.Metadata["match_type"] = "manual"
+			mergeIngestionMetadata(contract.Metadata, rec.Metadata)
+			applyStegoMetadata(contract.Metadata, rec.Metadata)
+			mergeContractProvenance(contract, SmartContractData{
+				FirstSeenAt:          ingestionFirstSeen(rec),
+				Source:               ContractSourceIngestion,
+				ReconciliationStatus: ReconciliationFullyMatched,
+			})
+
+			summary["smart_contracts"] = contracts
+			out, err := json.MarshalIndent(summary, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal summary: %w", err)
+			}
+			tmp := summaryPath + ".tmp"
+			if err := os.WriteFile(tmp, out, bm.fileMode); err != nil {
+				return nil, fmt.Errorf("failed to write summary: %w", err)
+			}
+			if err := os.Rename(tmp, summaryPath); err != nil {
+				return nil, fmt.Errorf("failed to replace summary: %w", err)
+			}
+			linked := *contract
+			return &linked, nil
+		}
+	}
+	return nil, ErrContractNotFound
+}
+
+// summaryContracts decodes the smart_contracts list of an inscriptions.json summary.
+func summaryContracts(summary map[string]any) ([]SmartContractData, error) {
+	raw, err := json.Marshal(summary["smart_contracts"])
+	if err != nil {
+		return nil, err
+	}
+	var contracts []SmartContractData
+	if err := json.Unmarshal(raw, &contracts); err != nil {
+		return nil, err
+	}
+	return contracts, nil
+}
@@ -0,0 +1,94 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"stargate-backend/services"
+)
+
+func TestLinkContractToIngestion(t *testing.T) {
+	ingest, err := services.NewIngestionService(filepath.Join(t.TempDir(), "ingestion.db"))
+	if err != nil {
+		t.Fatalf("init ingestion service: %v", err)
+	}
+	hash := strings.Repeat("ab", 32)
+	other := strings.Repeat("cd", 32)
+	for _, id := range []string{hash, other} {
+		if err := ingest.Create(services.IngestionRecord{
+			ID:       id,
+			Filename: "upload.png",
+			Status:   "pending",
+			Metadata: map[string]any{"visible_pixel_hash": id, "budget_sats": 5000},
+		}); err != nil {
+			t.Fatalf("create ingestion: %v", err)
+		}
+	}
+
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetIngestionService(ingest)
+	dir := filepath.Join(bm.blocksDir, "990_0000abcd")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	summary := map[string]any{
+		"block_height": 990,
+		"smart_contracts": []SmartContractData{{
+			ContractID:           "stego_" + hash,
+			BlockHeight:          990,
+			Confidence:           0.9,
+			Metadata:             map[string]any{"visible_pixel_hash": hash, "tx_id": "tx1"},
+			Source:               ContractSourceBlockScan,
+			ReconciliationStatus: ReconciliationHashMatched,
+		}},
+	}
+	raw, err := json.Marshal(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaryPath := filepath.Join(dir, "inscriptions.json")
+	if err := os.WriteFile(summaryPath, raw, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bm.LinkContractToIngestion("stego_"+hash, other); !errors.Is(err, ErrVisibleHashMismatch) {
+		t.Fatalf("mismatching link error = %v, want ErrVisibleHashMismatch", err)
+	}
+	if after, _ := os.ReadFile(summaryPath); string(after) != string(raw) {
+		t.Fatal("mismatching link rewrote inscriptions.json")
+	}
+	if _, err := bm.LinkContractToIngestion("stego_missing", hash); !errors.Is(err, ErrContractNotFound) {
+		t.Fatalf("unknown contract error = %v, want ErrContractNotFound", err)
+	}
+
+	linked, err := bm.LinkContractToIngestion("stego_"+hash, hash)
+	if err != nil {
+		t.Fatalf("matching link: %v", err)
+	}
+	if linked.ReconciliationStatus != ReconciliationFullyMatched || linked.Source != ContractSourceIngestion {
+		t.Fatalf("linked contract = %+v", linked)
+	}
+	if linked.Metadata["ingestion_id"] != hash || linked.Metadata["match_type"] != "manual" || linked.Metadata["budget_sats"] == nil {
+		t.Fatalf("linked metadata = %v", linked.Metadata)
+	}
+
+	stored, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var persisted map[string]any
+	if err := json.Unmarshal(stored, &persisted); err != nil {
+		t.Fatal(err)
+	}
+	contracts, err := summaryContracts(persisted)
+	if err != nil || len(contracts) != 1 {
+		t.Fatalf("persisted contracts = %+v (%v)", contracts, err)
+	}
+	if contracts[0].ReconciliationStatus != ReconciliationFullyMatched || persisted["block_height"] != float64(990) {
+		t.Fatalf("persisted summary = %v", persisted)
+	}
+}
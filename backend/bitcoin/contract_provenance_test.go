@@ -0,0 +1,200 @@
+package bitcoin
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"stargate-backend/services"
+)
+
+func TestScanCreatedContractRecordsBlockScanSource(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 1}
+	source.addChain(t, 970, 970, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.notificationSinks = nil
+	bm.SetImageScanner(stegoScanner{})
+	sink := &recordingSink{}
+	bm.AddNotificationSink(sink)
+
+	before := time.Now().Unix()
+	if err := bm.ProcessBlock(970); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	after := time.Now().Unix()
+
+	if len(sink.events) != 1 {
+		t.Fatalf("got %d detections, want 1", len(sink.events))
+	}
+	got := sink.events[0]
+	if got.Source != ContractSourceBlockScan {
+		t.Fatalf("source = %q, want %q", got.Source, ContractSourceBlockScan)
+	}
+	if got.FirstSeenAt < before || got.FirstSeenAt > after {
+		t.Fatalf("first_seen_at = %d, want within [%d, %d]", got.FirstSeenAt, before, after)
+	}
+}
+
+func TestReprocessKeepsEarlierFirstSeen(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 1}
+	source.addChain(t, 972, 972, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.notificationSinks = nil
+	bm.SetImageScanner(stegoScanner{})
+	if err := bm.ProcessBlock(972); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+
+	// Backdate the stored detection, as if it was first seen long ago.
+	dir, err := FindBlockDirectory(bm.blocksDir, 972)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summaryPath := filepath.Join(dir, "inscriptions.json")
+	raw, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary map[string]any
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		t.Fatal(err)
+	}
+	const earlier = int64(1_600_000_000)
+	summary["smart_contracts"].([]any)[0].(map[string]any)["first_seen_at"] = earlier
+	if raw, err = json.Marshal(summary); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(summaryPath, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bm.ReprocessBlock(972); err != nil {
+		t.Fatalf("ReprocessBlock: %v", err)
+	}
+	contracts, err := readBlockDirContracts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contracts) != 1 || contracts[0].FirstSeenAt != earlier {
+		t.Fatalf("contracts after reprocess = %+v, want first_seen_at %d", contracts, earlier)
+	}
+}
+
+func TestIngestionReconciledContractKeepsIngestionFirstSeen(t *testing.T) {
+	ingest, err := services.NewIngestionService(filepath.Join(t.TempDir(), "ingestion.db"))
+	if err != nil {
+		t.Fatalf("init ingestion service: %v", err)
+	}
+	const fundingTxID = "aa11223344556677889900aabbccddeeff00112233445566778899aabbccddee"
+	if err := ingest.Create(services.IngestionRecord{
+		ID:       "proposal-1",
+		Filename: "contract.png",
+		Status:   "pending",
+		Metadata: map[string]any{"funding_txids": fundingTxID},
+	}); err != nil {
+		t.Fatalf("create ingestion: %v", err)
+	}
+	rec, err := ingest.Get("proposal-1")
+	if err != nil {
+		t.Fatalf("get ingestion: %v", err)
+	}
+	if rec.CreatedAt.IsZero() {
+		t.Fatal("ingestion record has no created_at")
+	}
+
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetIngestionService(ingest)
+
+	// A scan-created entry for the same contract, seen after the upload.
+	existing := []SmartContractData{{
+		ContractID:  "proposal-1",
+		BlockHeight: 980,
+		Metadata:    map[string]any{},
+		FirstSeenAt: rec.CreatedAt.Unix() + 3600,
+		Source:      ContractSourceBlockScan,
+	}}
+	parsed := &ParsedBlock{Height: 980, Transactions: []Transaction{{TxID: fundingTxID}}}
+	contracts := bm.reconcileOracleIngestions(t.TempDir(), parsed, existing, 980)
+
+	if len(contracts) != 1 {
+		t.Fatalf("got %d contracts, want 1: %+v", len(contracts), contracts)
+	}
+	got := contracts[0]
+	if got.Source != ContractSourceIngestion {
+		t.Fatalf("source = %q, want %q", got.Source, ContractSourceIngestion)
+	}
+	if got.FirstSeenAt != rec.CreatedAt.Unix() {
+		t.Fatalf("first_seen_at = %d, want ingestion created_at %d", got.FirstSeenAt, rec.CreatedAt.Unix())
+	}
+	if got.ReconciliationStatus != ReconciliationFullyMatched {
+		t.Fatalf("reconciliation_status = %q, want %q", got.ReconciliationStatus, ReconciliationFullyMatched)
+	}
+}
+
+func TestReconcileIngestionContractsStatus(t *testing.T) {
+	t.Setenv("UPLOADS_DIR", t.TempDir())
+	payout := []byte{0x00, 0x14, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12, 0x13, 0x14}
+	imageData := []byte("stego image bytes")
+	visibleHash := visiblePixelHash(imageData, "hello")
+
+	tests := []struct {
+		name         string
+		record       bool
+		payoutScript string
+		want         string
+	}{
+		{"no ingestion record", false, hex.EncodeToString(payout), ReconciliationUnmatched},
+		{"payout script mismatch", true, "0014ffff", ReconciliationHashMatched},
+		{"hash and payout match", true, hex.EncodeToString(payout), ReconciliationFullyMatched},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ingest, err := services.NewIngestionService(filepath.Join(t.TempDir(), "ingestion.db"))
+			if err != nil {
+				t.Fatalf("init ingestion service: %v", err)
+			}
+			if tc.record {
+				if err := ingest.Create(services.IngestionRecord{
+					ID:          visibleHash,
+					Filename:    "upload.png",
+					Status:      "pending",
+					ImageBase64: base64.StdEncoding.EncodeToString(imageData),
+				}); err != nil {
+					t.Fatalf("create ingestion: %v", err)
+				}
+			}
+			bm := newTestBlockMonitor(t, newFakeRawSource())
+			bm.SetIngestionService(ingest)
+
+			image := ExtractedImageData{TxID: "tx1", FileName: "tx1_img_0.bin", Data: imageData}
+			results := []ScanResultEntry{{
+				TxID:             "tx1",
+				FileName:         image.FileName,
+				IsStego:          true,
+				Confidence:       0.9,
+				ExtractedMessage: "hello",
+				Extra:            map[string]any{"payout_script": tc.payoutScript},
+			}}
+			parsed := &ParsedBlock{
+				Height:       990,
+				Transactions: []Transaction{{TxID: "tx1", Outputs: []TxOutput{{Value: 1000, ScriptPubKey: payout}}}},
+				Images:       []ExtractedImageData{image},
+			}
+			contracts := bm.createSmartContractsFromScanResults(results, parsed.Images, 990)
+			contracts = bm.reconcileIngestionContracts(t.TempDir(), parsed, results, contracts, 990)
+
+			if len(contracts) != 1 {
+				t.Fatalf("got %d contracts, want 1: %+v", len(contracts), contracts)
+			}
+			if got := contracts[0].ReconciliationStatus; got != tc.want {
+				t.Fatalf("reconciliation_status = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package bitcoin
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// durationFromEnv reads name as a Go duration such as "10s"; 0 is allowed.
+// Unset values return def; unparsable or negative values are logged and
+// replaced by def.
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return def
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		log.Printf("Ignoring invalid %s=%q, using %v", name, raw, def)
+		return def
+	}
+	return d
+}
@@ -0,0 +1,22 @@
+package bitcoin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationFromEnv(t *testing.T) {
+	const name, def = "STARGATE_TEST_DURATION", 5 * time.Second
+	for raw, want := range map[string]time.Duration{
+		"":      def,
+		"250ms": 250 * time.Millisecond,
+		"0":     0,
+		"-1s":   def,
+		"soon":  def,
+	} {
+		t.Setenv(name, raw)
+		if got := durationFromEnv(name, def); got != want {
+			t.Fatalf("durationFromEnv(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
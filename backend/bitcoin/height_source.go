@@ -0,0 +1,184 @@
+package bitcoin
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHeightSources only trusts the configured node; public explorers are
+// opt-in through STARGATE_HEIGHT_SOURCES.
+const defaultHeightSources = "node"
+
+// HeightSource reports the current chain tip height.
+type HeightSource interface {
+	Name() string
+	CurrentHeight() (int64, error)
+}
+
+// nodeHeightSource asks the configured BitcoinNodeClient.
+type nodeHeightSource struct {
+	client *BitcoinNodeClient
+}
+
+func (s nodeHeightSource) Name() string { return "node" }
+
+func (s nodeHeightSource) CurrentHeight() (int64, error) {
+	return s.client.GetCurrentHeight()
+}
+
+// httpHeightSource reads a plain-text height from an Esplora-style
+// /blocks/tip/height endpoint.
+type httpHeightSource struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+// NewHTTPHeightSource returns a HeightSource that GETs url and parses the body
+// as a decimal block height.
+func NewHTTPHeightSource(name, url string) HeightSource {
+	return &httpHeightSource{name: name, url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *httpHeightSource) Name() string { return s.name }
+
+func (s *httpHeightSource) CurrentHeight() (int64, error) {
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return 0, err
+	}
+	height, err := strconv.ParseInt(strings.TrimSpace(string(body)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid height %q", strings.TrimSpace(string(body)))
+	}
+	return height, nil
+}
+
+// esploraTipURL returns the tip-height endpoint of a public explorer for network,
+// or "" when that explorer does not serve the network.
+func esploraTipURL(explorer, network string) string {
+	bases := map[string]map[string]string{
+		"mempool": {
+			"mainnet":  "https://mempool.space/api",
+			"testnet":  "https://mempool.space/testnet/api",
+			"testnet4": "https://mempool.space/testnet4/api",
+			"signet":   "https://mempool.space/signet/api",
+		},
+		"blockstream": {
+			"mainnet": "https://blockstream.info/api",
+			"testnet": "https://blockstream.info/testnet/api",
+			"signet":  "https://blockstream.info/signet/api",
+		},
+	}
+	base := bases[explorer][network]
+	if base == "" {
+		return ""
+	}
+	return base + "/blocks/tip/height"
+}
+
+// heightSourcesFromEnv builds the ordered failover list from
+// STARGATE_HEIGHT_SOURCES (default "node"). Entries are
+// "node", "mempool", "blockstream" or a full URL returning a plain-text height.
+func heightSourcesFromEnv(client *BitcoinNodeClient) []HeightSource {
+	raw := strings.TrimSpace(os.Getenv("STARGATE_HEIGHT_SOURCES"))
+	if raw == "" {
+		raw = defaultHeightSources
+	}
+	network := client.GetNetwork()
+
+	var sources []HeightSource
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch {
+		case name == "":
+		case name == "node":
+			sources = append(sources, nodeHeightSource{client: client})
+		case strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://"):
+			sources = append(sources, NewHTTPHeightSource(name, name))
+		default:
+			url := esploraTipURL(name, network)
+			if url == "" {
+				log.Printf("Ignoring height source %q: not available for %s", name, network)
+				continue
+			}
+			sources = append(sources, NewHTTPHeightSource(name, url))
+		}
+	}
+	if len(sources) == 0 {
+		sources = append(sources, nodeHeightSource{client: client})
+	}
+	return sources
+}
+
+// SetHeightSources replaces the ordered list of chain tip sources.
+func (bm *BlockMonitor) SetHeightSources(sources ...HeightSource) {
+	bm.heightSources = sources
+}
+
+// KnownTipHeight returns the last chain tip accepted from a height source or
+// reached by processing, without a network call. 0 means no tip is known yet.
+func (bm *BlockMonitor) KnownTipHeight() int64 {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+	return max(bm.lastTipHeight, bm.currentHeight)
+}
+
+// getCurrentHeight asks each height source in order and returns the first
+// answer that does not regress below the last accepted tip. When every source
+// that answered regressed, the poll fails but the accepted tip falls back to
+// the highest of those answers, so one bad report cannot pin the tip forever.
+func (bm *BlockMonitor) getCurrentHeight() (int64, error) {
+	var failures []string
+	var highestRegressed int64
+	for _, source := range bm.heightSources {
+		height, err := source.CurrentHeight()
+		if err != nil {
+			log.Printf("Height source %s failed: %v", source.Name(), err)
+			failures = append(failures, fmt.Sprintf("%s: %v", source.Name(), err))
+			continue
+		}
+
+		bm.mu.Lock()
+		last := bm.lastTipHeight
+		if height >= last {
+			bm.lastTipHeight = height
+		}
+		bm.mu.Unlock()
+		if height < last {
+			log.Printf("Rejecting height source %s: tip %d regressed below %d", source.Name(), height, last)
+			failures = append(failures, fmt.Sprintf("%s: height %d below %d", source.Name(), height, last))
+			highestRegressed = max(highestRegressed, height)
+			continue
+		}
+
+		log.Printf("Chain tip %d from height source %s", height, source.Name())
+		return height, nil
+	}
+	if len(failures) == 0 {
+		return 0, fmt.Errorf("no height sources configured")
+	}
+	if highestRegressed > 0 {
+		bm.mu.Lock()
+		if highestRegressed < bm.lastTipHeight {
+			log.Printf("All height sources are below tip %d; falling back to %d on the next poll", bm.lastTipHeight, highestRegressed)
+			bm.lastTipHeight = highestRegressed
+		}
+		bm.mu.Unlock()
+	}
+	return 0, fmt.Errorf("all height sources failed: %s", strings.Join(failures, "; "))
+}
@@ -0,0 +1,107 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type stubHeightSource struct {
+	name   string
+	height int64
+	err    error
+	calls  int
+}
+
+func (s *stubHeightSource) Name() string { return s.name }
+
+func (s *stubHeightSource) CurrentHeight() (int64, error) {
+	s.calls++
+	return s.height, s.err
+}
+
+func TestGetCurrentHeightFailsOverToSecondary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "840123\n")
+	}))
+	defer srv.Close()
+
+	primary := &stubHeightSource{name: "node", err: errors.New("connection refused")}
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetHeightSources(primary, NewHTTPHeightSource("mempool", srv.URL))
+
+	height, err := bm.getCurrentHeight()
+	if err != nil {
+		t.Fatalf("getCurrentHeight: %v", err)
+	}
+	if height != 840123 || primary.calls != 1 {
+		t.Fatalf("height = %d after %d primary calls, want 840123 from secondary", height, primary.calls)
+	}
+}
+
+func TestGetCurrentHeightRejectsRegressingSource(t *testing.T) {
+	primary := &stubHeightSource{name: "node", height: 100}
+	secondary := &stubHeightSource{name: "blockstream", height: 101}
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetHeightSources(primary, secondary)
+
+	if h, err := bm.getCurrentHeight(); err != nil || h != 100 {
+		t.Fatalf("first tip = %d, %v", h, err)
+	}
+
+	primary.height = 95
+	if h, err := bm.getCurrentHeight(); err != nil || h != 101 {
+		t.Fatalf("after primary regressed: tip = %d, %v; want 101 from secondary", h, err)
+	}
+
+	secondary.height = 90
+	if _, err := bm.getCurrentHeight(); err == nil {
+		t.Fatal("expected an error when every source regresses")
+	}
+	// The bogus high tip is dropped: the next poll accepts the best answer.
+	if h, err := bm.getCurrentHeight(); err != nil || h != 95 {
+		t.Fatalf("poll after regression: tip = %d, %v; want 95", h, err)
+	}
+}
+
+func TestHeightSourcesDefaultToNode(t *testing.T) {
+	client := NewBitcoinNodeClient("http://127.0.0.1:0")
+	t.Setenv("STARGATE_HEIGHT_SOURCES", "")
+	sources := heightSourcesFromEnv(client)
+	if len(sources) != 1 || sources[0].Name() != "node" {
+		t.Fatalf("default sources = %d, want only the node", len(sources))
+	}
+}
+
+func TestHeightSourcesFromEnv(t *testing.T) {
+	client := NewBitcoinNodeClient("http://127.0.0.1:0")
+	t.Setenv("STARGATE_HEIGHT_SOURCES", "mempool, https://example.test/tip , bogus")
+	sources := heightSourcesFromEnv(client)
+	if len(sources) != 2 || sources[0].Name() != "mempool" || sources[1].Name() != "https://example.test/tip" {
+		names := make([]string, len(sources))
+		for i, s := range sources {
+			names[i] = s.Name()
+		}
+		t.Fatalf("sources = %v", names)
+	}
+}
+
+func TestKnownTipHeightTracksSourcesAndProcessing(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	if tip := bm.KnownTipHeight(); tip != 0 {
+		t.Fatalf("tip before any source = %d, want 0", tip)
+	}
+	bm.SetHeightSources(&stubHeightSource{name: "node", height: 500})
+	if _, err := bm.getCurrentHeight(); err != nil {
+		t.Fatal(err)
+	}
+	if tip := bm.KnownTipHeight(); tip != 500 {
+		t.Fatalf("tip = %d, want 500", tip)
+	}
+	bm.advanceCurrentHeight(501)
+	if tip := bm.KnownTipHeight(); tip != 501 {
+		t.Fatalf("tip after processing 501 = %d, want 501", tip)
+	}
+}
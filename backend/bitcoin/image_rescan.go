@@ -0,0 +1,187 @@
+package bitcoin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"stargate-backend/core"
+	"stargate-backend/security"
+)
+
+// ErrImageNotFound is returned by RescanImage when no stored image has the requested hash.
+var ErrImageNotFound = errors.New("image not found")
+
+// ErrScannerUnavailable is returned by RescanImage when no image scanner is configured.
+var ErrScannerUnavailable = errors.New("scanner not available")
+
+// ImageRescanResult describes a stored image that was scanned again.
+type ImageRescanResult struct {
+	SHA256         string         `json:"sha256"`
+	BlockHeight    int64          `json:"block_height"`
+	BlockDir       string         `json:"block_dir"`
+	FileName       string         `json:"file_name"`
+	TxID           string         `json:"tx_id"`
+	ScanResult     map[string]any `json:"scan_result"`
+	PreviousResult map[string]any `json:"previous_scan_result,omitempty"`
+}
+
+// RescanImage looks up the previously extracted image whose sha256 is
+// contentHash in the block index, scans it again with options and rewrites
+// its scan_result (and the block's steganography_scan summary) in
+// inscriptions.json.
+func (bm *BlockMonitor) RescanImage(contentHash string, options core.ScanOptions) (*ImageRescanResult, error) {
+	contentHash = strings.ToLower(strings.TrimSpace(contentHash))
+	if decoded, err := hex.DecodeString(contentHash); err != nil || len(decoded) != sha256.Size {
+		return nil, fmt.Errorf("invalid sha256 %q", contentHash)
+	}
+	if bm.scanner() == nil {
+		return nil, ErrScannerUnavailable
+	}
+
+	bm.reconcileMu.Lock()
+	defer bm.reconcileMu.Unlock()
+
+	loc, ok := bm.indexedImage(contentHash)
+	if !ok {
+		return nil, ErrImageNotFound
+	}
+	// Hold the height lock across the read-modify-write of inscriptions.json
+	// so a concurrent processBlock of the same block cannot interleave.
+	height := loc.Height
+	unlock := bm.processLocks.lock(height)
+	defer unlock()
+	// The block may have been reprocessed while we waited for the lock.
+	if loc, ok = bm.indexedImage(contentHash); !ok || loc.Height != height {
+		return nil, ErrImageNotFound
+	}
+	return bm.rescanInBlockDir(filepath.Join(bm.blocksDir, loc.Dir), loc.Height, contentHash, loc.FileName, options)
+}
+
+// indexedImage returns where the block index says the image with sha256
+// contentHash lives.
+func (bm *BlockMonitor) indexedImage(contentHash string) (ImageLocation, bool) {
+	idx, err := LoadBlockIndex(bm.blocksDir)
+	if err != nil {
+		return ImageLocation{}, false
+	}
+	loc, ok := idx.ByImage[contentHash]
+	return loc, ok
+}
+
+func (bm *BlockMonitor) rescanInBlockDir(dir string, height int64, contentHash, fileName string, options core.ScanOptions) (*ImageRescanResult, error) {
+	summaryPath := filepath.Join(dir, "inscriptions.json")
+	raw, err := os.ReadFile(summaryPath)
+	if err != nil {
+		return nil, ErrImageNotFound
+	}
+	var summary map[string]any
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		return nil, ErrImageNotFound
+	}
+	images, _ := summary["images"].([]any)
+
+	for i, item := range images {
+		img, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if stringFromAny(img["file_name"]) != fileName {
+			continue
+		}
+		path, err := security.ContainedFilePath(filepath.Join(dir, "images"), fileName)
+		if err != nil {
+			return nil, ErrImageNotFound
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, ErrImageNotFound
+		}
+		// The index can lag a rewritten file; never rescan the wrong bytes.
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != contentHash {
+			return nil, ErrImageNotFound
+		}
+
+		previous, _ := img["scan_result"].(map[string]any)
+		image := ExtractedImageData{
+			TxID:      stringFromAny(img["tx_id"]),
+			Format:    stringFromAny(img["format"]),
+			FileName:  fileName,
+			SizeBytes: len(data),
+			Data:      data,
+		}
+		index := i
+		if prev, err := ParseScanResultEntry(previous); err == nil && previous["image_index"] != nil {
+			index = prev.ImageIndex
+		}
+		rescanned := bm.scanImageResult(index, image, options)
+		rescanned.RescannedAt = time.Now().Unix()
+		scanResult := rescanned.ToMap()
+		img["scan_result"] = scanResult
+		updateStegoSummary(summary, images)
+
+		out, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal summary: %w", err)
+		}
+		tmp := summaryPath + ".tmp"
+		if err := os.WriteFile(tmp, out, bm.fileMode); err != nil {
+			return nil, fmt.Errorf("failed to write summary: %w", err)
+		}
+		if err := os.Rename(tmp, summaryPath); err != nil {
+			return nil, fmt.Errorf("failed to replace summary: %w", err)
+		}
+
+		return &ImageRescanResult{
+			SHA256:         contentHash,
+			BlockHeight:    height,
+			BlockDir:       filepath.Base(dir),
+			FileName:       fileName,
+			TxID:           image.TxID,
+			ScanResult:     scanResult,
+			PreviousResult: previous,
+		}, nil
+	}
+	return nil, ErrImageNotFound
+}
+
+// updateStegoSummary recomputes steganography_scan the way saveBlockSummaryWithScanResults
+// writes it: present only when at least one image is flagged as stego.
+func updateStegoSummary(summary map[string]any, images []any) {
+	stegoCount := 0
+	for _, item := range images {
+		img, _ := item.(map[string]any)
+		scan, _ := img["scan_result"].(map[string]any)
+		if isStego, _ := scan["is_stego"].(bool); isStego {
+			stegoCount++
+		}
+	}
+	if stegoCount == 0 {
+		delete(summary, "steganography_scan")
+		return
+	}
+	summary["steganography_scan"] = map[string]any{
+		"total_images":   len(images),
+		"stego_detected": true,
+		"stego_count":    stegoCount,
+		"scan_timestamp": time.Now().Unix(),
+	}
+}
+
+// heightFromBlockDirName parses the height out of a <height>_<hash> directory name.
+func heightFromBlockDirName(name string) (int64, bool) {
+	prefix, _, ok := strings.Cut(name, "_")
+	if !ok {
+		return 0, false
+	}
+	height, err := strconv.ParseInt(prefix, 10, 64)
+	return height, err == nil
+}
@@ -0,0 +1,130 @@
+package bitcoin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"stargate-backend/core"
+)
+
+type cleanScanner struct{}
+
+func (cleanScanner) ScanImage([]byte, core.ScanOptions) (*core.ScanResult, error) {
+	return &core.ScanResult{IsStego: false, Confidence: 0.1}, nil
+}
+
+func TestRescanImageUpdatesStoredScanResult(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 2}
+	source.addChain(t, 970, 970, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.notificationSinks = nil
+	bm.SetImageScanner(cleanScanner{})
+	if err := bm.ProcessBlock(970); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+
+	// A better model now flags the second image.
+	bm.SetImageScanner(stegoScanner{})
+	sum := sha256.Sum256([]byte{1})
+	hash := hex.EncodeToString(sum[:])
+	res, err := bm.RescanImage(hash, core.ScanOptions{ExtractMessage: true, ConfidenceThreshold: 0.3})
+	if err != nil {
+		t.Fatalf("RescanImage: %v", err)
+	}
+	if res.BlockHeight != 970 || res.FileName != "cafebabe_img_1.png" || res.ScanResult["is_stego"] != true {
+		t.Fatalf("unexpected rescan result %+v", res)
+	}
+	if res.PreviousResult["is_stego"] != false {
+		t.Fatalf("previous result = %+v", res.PreviousResult)
+	}
+
+	dir, err := FindBlockDirectory(bm.blocksDir, 970)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "inscriptions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored struct {
+		Images []struct {
+			ScanResult map[string]any `json:"scan_result"`
+		} `json:"images"`
+		SteganographyScan map[string]any `json:"steganography_scan"`
+	}
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.Images[1].ScanResult["is_stego"] != true || stored.Images[0].ScanResult["is_stego"] != false {
+		t.Fatalf("stored results not updated: %+v", stored.Images)
+	}
+	if stored.SteganographyScan["stego_count"] != float64(1) {
+		t.Fatalf("steganography_scan = %+v", stored.SteganographyScan)
+	}
+	if report, err := InspectBlockDirectory(dir); err != nil || !report.Valid() {
+		t.Fatalf("block dir invalid after rescan: %v %+v", err, report)
+	}
+
+	// Lookups go through the index: drop the entry and the image is gone,
+	// rebuild it and the image is found again.
+	if err := bm.unindexBlockDirs(filepath.Base(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bm.RescanImage(hash, core.ScanOptions{}); !errors.Is(err, ErrImageNotFound) {
+		t.Fatalf("unindexed image error = %v", err)
+	}
+	if _, err := RebuildBlockIndex(bm.blocksDir, bm.fileMode); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := bm.RescanImage(hash, core.ScanOptions{}); err != nil || res.FileName != "cafebabe_img_1.png" {
+		t.Fatalf("rescan after rebuild = %+v, %v", res, err)
+	}
+
+	missing := sha256.Sum256([]byte("nope"))
+	if _, err := bm.RescanImage(hex.EncodeToString(missing[:]), core.ScanOptions{}); !errors.Is(err, ErrImageNotFound) {
+		t.Fatalf("missing image error = %v", err)
+	}
+}
+
+func TestRescanImageWaitsForBlockProcessing(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 1}
+	source.addChain(t, 971, 971, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.notificationSinks = nil
+	bm.SetImageScanner(cleanScanner{})
+	if err := bm.ProcessBlock(971); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+
+	// Stand in for a processBlock of the same height holding its lock.
+	unlock := bm.processLocks.lock(971)
+	sum := sha256.Sum256([]byte{0})
+	done := make(chan error, 1)
+	go func() {
+		_, err := bm.RescanImage(hex.EncodeToString(sum[:]), core.ScanOptions{})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		unlock()
+		t.Fatalf("RescanImage returned %v while the block was being processed", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("RescanImage: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("RescanImage did not finish after the lock was released")
+	}
+}
@@ -0,0 +1,26 @@
+package bitcoin
+
+import "stargate-backend/core"
+
+// ImageScanner scans a single image for steganography.
+// starlight.ScannerManager is the production implementation.
+type ImageScanner interface {
+	ScanImage(imageData []byte, options core.ScanOptions) (*core.ScanResult, error)
+}
+
+// SetImageScanner overrides the scanner used for per-image stego detection
+// (primarily for tests); by default the BitcoinAPI scanner manager is used.
+func (bm *BlockMonitor) SetImageScanner(scanner ImageScanner) {
+	bm.imageScanner = scanner
+}
+
+// scanner returns the image scanner to use, or nil when none is available.
+func (bm *BlockMonitor) scanner() ImageScanner {
+	if bm.imageScanner != nil {
+		return bm.imageScanner
+	}
+	if bm.bitcoinAPI != nil && bm.bitcoinAPI.scannerManager != nil {
+		return bm.bitcoinAPI.scannerManager
+	}
+	return nil
+}
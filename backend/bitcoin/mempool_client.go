@@ -32,6 +32,12 @@ func NewMempoolClient() *MempoolClient {
 	}
 }
 
+// NewMempoolClientWithHTTP builds a client for baseURL that sends requests
+// through client, e.g. one with an in-process transport.
+func NewMempoolClientWithHTTP(baseURL string, client *http.Client) *MempoolClient {
+	return &MempoolClient{baseURL: strings.TrimRight(baseURL, "/"), http: client}
+}
+
 // AddressUTXO represents a mempool.space UTXO entry.
 type AddressUTXO struct {
 	TxID   string `json:"txid"`
@@ -94,6 +100,25 @@ func (c *MempoolClient) FetchTx(txid string) (*wire.MsgTx, error) {
 	return msg, nil
 }
 
+// TxSeen reports whether mempool.space knows txid, either in its mempool or in
+// a block. A 404 means the transaction was never broadcast (or was evicted).
+func (c *MempoolClient) TxSeen(txid string) (bool, error) {
+	url := fmt.Sprintf("%s/tx/%s/status", c.baseURL, txid)
+	resp, err := c.http.Get(url)
+	if err != nil {
+		return false, fmt.Errorf("fetch tx status: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return false, fmt.Errorf("fetch tx status: status %d: %s", resp.StatusCode, string(body))
+}
+
 // FetchTxOutput returns the referenced output for the given utxo.
 func (c *MempoolClient) FetchTxOutput(txid string, vout uint32) (*wire.MsgTx, *wire.TxOut, error) {
 	msg, err := c.FetchTx(txid)
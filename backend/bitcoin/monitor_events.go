@@ -0,0 +1,40 @@
+package bitcoin
+
+import "time"
+
+// Block progress event types passed to OnMonitorEvent listeners.
+const (
+	MonitorEventBlockStarted   = "block_started"
+	MonitorEventBlockCompleted = "block_completed"
+	MonitorEventBlockFailed    = "block_failed"
+)
+
+// MonitorEvent reports block processing progress. TxCount, ImagesFound and
+// DurationMs are set on completion; Error and DurationMs on failure.
+type MonitorEvent struct {
+	Type        string    `json:"type"`
+	Height      int64     `json:"height"`
+	TxCount     int       `json:"tx_count,omitempty"`
+	ImagesFound int       `json:"images_found,omitempty"`
+	DurationMs  int64     `json:"duration_ms,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// OnMonitorEvent registers a callback for block start, completion and
+// failure. Callbacks run on the processing goroutine and must not block.
+func (bm *BlockMonitor) OnMonitorEvent(fn func(MonitorEvent)) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.onMonitorEvent = append(bm.onMonitorEvent, fn)
+}
+
+func (bm *BlockMonitor) emitMonitorEvent(evt MonitorEvent) {
+	evt.Time = time.Now().UTC()
+	bm.mu.RLock()
+	listeners := bm.onMonitorEvent
+	bm.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(evt)
+	}
+}
@@ -0,0 +1,49 @@
+package bitcoin
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+func TestProcessBlockEmitsMonitorEvents(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 2}
+	source.addChain(t, 970, 970, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.notificationSinks = nil
+
+	var mu sync.Mutex
+	var events []MonitorEvent
+	bm.OnMonitorEvent(func(evt MonitorEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, evt)
+	})
+
+	if err := bm.ProcessBlock(970); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want started and completed", events)
+	}
+	if events[0].Type != MonitorEventBlockStarted || events[0].Height != 970 {
+		t.Fatalf("first event = %+v", events[0])
+	}
+	done := events[1]
+	if done.Type != MonitorEventBlockCompleted || done.Height != 970 || done.ImagesFound != 2 || done.TxCount == 0 || done.Time.IsZero() {
+		t.Fatalf("completed event = %+v", done)
+	}
+
+	// A skipped (already complete) block emits nothing; a failing one reports the error.
+	events = nil
+	if err := bm.ProcessBlock(970); err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.ProcessBlock(971); err == nil {
+		t.Fatal("expected unknown height to fail")
+	}
+	if len(events) != 2 || events[1].Type != MonitorEventBlockFailed || events[1].Height != 971 || events[1].Error == "" {
+		t.Fatalf("events = %+v, want started and failed for 971", events)
+	}
+}
@@ -0,0 +1,59 @@
+package bitcoin
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	blocksProcessedDesc = prometheus.NewDesc(
+		"stargate_block_monitor_blocks_processed_total",
+		"Blocks processed by the forward block monitor.", nil, nil)
+	imagesDesc = prometheus.NewDesc(
+		"stargate_block_monitor_images_total",
+		"Images extracted from processed blocks.", nil, nil)
+	stegoContractsDesc = prometheus.NewDesc(
+		"stargate_block_monitor_stego_contracts_total",
+		"Images flagged as steganographic in processed blocks.", nil, nil)
+	currentHeightDesc = prometheus.NewDesc(
+		"stargate_block_monitor_current_height",
+		"Last block height finished by the forward block monitor.", nil, nil)
+	lastProcessDesc = prometheus.NewDesc(
+		"stargate_block_monitor_last_process_seconds",
+		"Wall time spent processing the most recent block.", nil, nil)
+)
+
+// monitorCollector exposes BlockMonitor statistics to Prometheus. Values are
+// read at scrape time under the monitor lock, so they always match GetStatistics.
+type monitorCollector struct {
+	bm *BlockMonitor
+}
+
+func (c monitorCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- blocksProcessedDesc
+	ch <- imagesDesc
+	ch <- stegoContractsDesc
+	ch <- currentHeightDesc
+	ch <- lastProcessDesc
+}
+
+func (c monitorCollector) Collect(ch chan<- prometheus.Metric) {
+	c.bm.mu.RLock()
+	processed := c.bm.blocksProcessed
+	images := c.bm.totalImages
+	stego := c.bm.totalStegoContracts
+	height := c.bm.currentHeight
+	lastProcess := c.bm.lastProcessTime
+	c.bm.mu.RUnlock()
+
+	ch <- prometheus.MustNewConstMetric(blocksProcessedDesc, prometheus.CounterValue, float64(processed))
+	ch <- prometheus.MustNewConstMetric(imagesDesc, prometheus.CounterValue, float64(images))
+	ch <- prometheus.MustNewConstMetric(stegoContractsDesc, prometheus.CounterValue, float64(stego))
+	ch <- prometheus.MustNewConstMetric(currentHeightDesc, prometheus.GaugeValue, float64(height))
+	ch <- prometheus.MustNewConstMetric(lastProcessDesc, prometheus.GaugeValue, lastProcess.Seconds())
+}
+
+// RegisterMetrics registers the monitor's counters with reg, e.g.
+// prometheus.DefaultRegisterer to serve them from the shared /metrics endpoint.
+func (bm *BlockMonitor) RegisterMetrics(reg prometheus.Registerer) error {
+	return reg.Register(monitorCollector{bm: bm})
+}
@@ -0,0 +1,39 @@
+package bitcoin
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestRegisterMetricsReflectsMonitorState(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	reg := prometheus.NewRegistry()
+	if err := bm.RegisterMetrics(reg); err != nil {
+		t.Fatal(err)
+	}
+
+	bm.advanceCurrentHeight(850000)
+	bm.advanceCurrentHeight(850001)
+	bm.recordBlockStats(1500*time.Millisecond, 10, 4, 2, 3)
+
+	w := httptest.NewRecorder()
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	for _, want := range []string{
+		"stargate_block_monitor_blocks_processed_total 2",
+		"stargate_block_monitor_images_total 4",
+		"stargate_block_monitor_stego_contracts_total 3",
+		"stargate_block_monitor_current_height 850001",
+		"stargate_block_monitor_last_process_seconds 1.5",
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Fatalf("scrape missing %q:\n%s", want, body)
+		}
+	}
+}
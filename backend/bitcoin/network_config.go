@@ -3,6 +3,8 @@ package bitcoin
 import (
 	"log"
 	"os"
+
+	"github.com/btcsuite/btcd/chaincfg"
 )
 
 // NetworkConfig holds configuration for different Bitcoin networks
@@ -64,6 +66,21 @@ func GetCurrentNetwork() string {
 	return network
 }
 
+// NetworkParams maps a network name as used by BITCOIN_NETWORK to chain
+// params, defaulting to testnet4.
+func NetworkParams(network string) *chaincfg.Params {
+	switch network {
+	case "mainnet":
+		return &chaincfg.MainNetParams
+	case "signet":
+		return &chaincfg.SigNetParams
+	case "testnet":
+		return &chaincfg.TestNet3Params
+	default:
+		return &chaincfg.TestNet4Params
+	}
+}
+
 // NewBitcoinNodeClientForNetwork creates a client for the specified network
 func NewBitcoinNodeClientForNetwork(network string) *BitcoinNodeClient {
 	config := GetNetworkConfig(network)
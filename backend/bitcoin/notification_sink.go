@@ -0,0 +1,197 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// NotificationSink receives a callback for every stego detection made while
+// processing a block, then one per processed block that produced contracts.
+type NotificationSink interface {
+	OnStegoDetected(blockHeight int64, contract SmartContractData)
+	OnBlockProcessed(blockHeight int64, blockHash string, contracts []SmartContractData)
+}
+
+// LogNotificationSink writes detections to the standard logger.
+type LogNotificationSink struct{}
+
+func (LogNotificationSink) OnStegoDetected(blockHeight int64, contract SmartContractData) {
+	log.Printf("Stego detected in block %d: contract=%s image=%s confidence=%.2f",
+		blockHeight, contract.ContractID, contract.ImagePath, contract.Confidence)
+}
+
+func (LogNotificationSink) OnBlockProcessed(blockHeight int64, blockHash string, contracts []SmartContractData) {
+	log.Printf("Block %d (%s) produced %d stego contracts", blockHeight, blockHash, len(contracts))
+}
+
+const (
+	// webhookQueueSize bounds the events waiting for delivery per webhook.
+	webhookQueueSize  = 64
+	webhookTimeout    = 10 * time.Second
+	webhookRetryDelay = 2 * time.Second
+)
+
+// WebhookNotificationSink POSTs each detection and each processed block with
+// contracts as JSON to URL. Deliveries are queued and sent by a single
+// background worker, so a slow or unreachable receiver never holds up block
+// processing; a failed delivery is retried once, and events arriving while
+// the queue is full are dropped and logged.
+type WebhookNotificationSink struct {
+	URL        string
+	client     *http.Client
+	retryDelay time.Duration
+	queue      chan webhookEvent
+}
+
+// webhookEvent is a queued payload together with a label for logging.
+type webhookEvent struct {
+	label   string
+	payload any
+}
+
+// stegoDetectedEvent is the webhook payload.
+type stegoDetectedEvent struct {
+	Event       string            `json:"event"`
+	BlockHeight int64             `json:"block_height"`
+	Contract    SmartContractData `json:"contract"`
+	DetectedAt  time.Time         `json:"detected_at"`
+}
+
+// blockStegoEvent is the per-block webhook payload.
+type blockStegoEvent struct {
+	Event       string              `json:"event"`
+	BlockHeight int64               `json:"block_height"`
+	BlockHash   string              `json:"block_hash"`
+	Contracts   []SmartContractData `json:"contracts"`
+	ProcessedAt time.Time           `json:"processed_at"`
+}
+
+// NewWebhookNotificationSink creates a webhook sink with a short request
+// timeout and starts its delivery worker.
+func NewWebhookNotificationSink(url string) *WebhookNotificationSink {
+	s := &WebhookNotificationSink{
+		URL:        url,
+		client:     &http.Client{Timeout: webhookTimeout},
+		retryDelay: webhookRetryDelay,
+		queue:      make(chan webhookEvent, webhookQueueSize),
+	}
+	go s.run()
+	return s
+}
+
+func (s *WebhookNotificationSink) OnStegoDetected(blockHeight int64, contract SmartContractData) {
+	s.enqueue(fmt.Sprintf("detection %s in block %d", contract.ContractID, blockHeight), stegoDetectedEvent{
+		Event:       "stego_detected",
+		BlockHeight: blockHeight,
+		Contract:    contract,
+		DetectedAt:  time.Now().UTC(),
+	})
+}
+
+func (s *WebhookNotificationSink) OnBlockProcessed(blockHeight int64, blockHash string, contracts []SmartContractData) {
+	s.enqueue(fmt.Sprintf("block %d", blockHeight), blockStegoEvent{
+		Event:       "block_stego_detected",
+		BlockHeight: blockHeight,
+		BlockHash:   blockHash,
+		Contracts:   append([]SmartContractData(nil), contracts...),
+		ProcessedAt: time.Now().UTC(),
+	})
+}
+
+// enqueue hands payload to the delivery worker without blocking.
+func (s *WebhookNotificationSink) enqueue(label string, payload any) {
+	select {
+	case s.queue <- webhookEvent{label: label, payload: payload}:
+	default:
+		log.Printf("Stego webhook queue for %s full; dropping %s", s.URL, label)
+	}
+}
+
+// run delivers queued events one at a time, retrying each failure once.
+func (s *WebhookNotificationSink) run() {
+	for event := range s.queue {
+		err := postJSON(s.client, s.URL, event.payload)
+		if err == nil {
+			continue
+		}
+		log.Printf("Stego webhook to %s for %s failed, retrying in %v: %v", s.URL, event.label, s.retryDelay, err)
+		time.Sleep(s.retryDelay)
+		if err := postJSON(s.client, s.URL, event.payload); err != nil {
+			log.Printf("Stego webhook to %s for %s failed after retry: %v", s.URL, event.label, err)
+		}
+	}
+}
+
+// postJSON POSTs v as JSON and treats any non-2xx status as an error.
+func postJSON(client *http.Client, url string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// notificationSinksFromEnv builds sinks from STARGATE_STEGO_NOTIFY_LOG (bool) and
+// STARGATE_STEGO_WEBHOOK_URLS (comma-separated). The older single-URL
+// STEGO_WEBHOOK_URL is still accepted as an alias; a URL named in both gets
+// one sink.
+func notificationSinksFromEnv() []NotificationSink {
+	var sinks []NotificationSink
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("STARGATE_STEGO_NOTIFY_LOG"))) {
+	case "1", "true", "yes", "on":
+		sinks = append(sinks, LogNotificationSink{})
+	}
+	urls := strings.Split(os.Getenv("STARGATE_STEGO_WEBHOOK_URLS"), ",")
+	urls = append(urls, os.Getenv("STEGO_WEBHOOK_URL"))
+	seen := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		if url = strings.TrimSpace(url); url != "" && !seen[url] {
+			seen[url] = true
+			sinks = append(sinks, NewWebhookNotificationSink(url))
+		}
+	}
+	return sinks
+}
+
+// AddNotificationSink registers an additional sink for stego detections.
+func (bm *BlockMonitor) AddNotificationSink(sink NotificationSink) {
+	bm.notificationSinks = append(bm.notificationSinks, sink)
+}
+
+// notifyBlockProcessed tells every registered sink about a processed block
+// that produced contracts.
+func (bm *BlockMonitor) notifyBlockProcessed(blockHeight int64, blockHash string, contracts []SmartContractData) {
+	if len(contracts) == 0 {
+		return
+	}
+	for _, sink := range bm.notificationSinks {
+		sink.OnBlockProcessed(blockHeight, blockHash, contracts)
+	}
+}
+
+// notifyStegoDetections fans each detection out to every registered sink.
+func (bm *BlockMonitor) notifyStegoDetections(blockHeight int64, detections []SmartContractData) {
+	if len(bm.notificationSinks) == 0 {
+		return
+	}
+	for _, detection := range detections {
+		detection.BlockHeight = blockHeight
+		for _, sink := range bm.notificationSinks {
+			sink.OnStegoDetected(blockHeight, detection)
+		}
+	}
+}
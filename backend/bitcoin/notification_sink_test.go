@@ -0,0 +1,223 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"stargate-backend/core"
+)
+
+// imageRawSource attaches n small images to every parsed block.
+type imageRawSource struct {
+	*fakeRawSource
+	n int
+}
+
+func (s *imageRawSource) ParseBlock(hexData string) (*ParsedBlock, error) {
+	parsed, err := s.fakeRawSource.ParseBlock(hexData)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < s.n; i++ {
+		parsed.Images = append(parsed.Images, ExtractedImageData{
+			TxID:     "cafebabe",
+			Format:   "png",
+			FileName: fmt.Sprintf("cafebabe_img_%d.png", i),
+			Data:     []byte{byte(i)},
+		})
+	}
+	return parsed, nil
+}
+
+// stegoScanner flags every image as stego.
+type stegoScanner struct{}
+
+func (stegoScanner) ScanImage([]byte, core.ScanOptions) (*core.ScanResult, error) {
+	return &core.ScanResult{IsStego: true, Confidence: 0.9, StegoType: "lsb"}, nil
+}
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []SmartContractData
+}
+
+func (r *recordingSink) OnStegoDetected(blockHeight int64, contract SmartContractData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, contract)
+}
+
+func (r *recordingSink) OnBlockProcessed(int64, string, []SmartContractData) {}
+
+func TestProcessBlockNotifiesSinkOncePerDetection(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 2}
+	source.addChain(t, 950, 950, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.notificationSinks = nil
+	bm.SetImageScanner(stegoScanner{})
+	sink := &recordingSink{}
+	bm.AddNotificationSink(sink)
+
+	if err := bm.ProcessBlock(950); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	if len(sink.events) != 2 {
+		t.Fatalf("sink called %d times, want 2", len(sink.events))
+	}
+	for _, ev := range sink.events {
+		if ev.BlockHeight != 950 || ev.Confidence != 0.9 {
+			t.Fatalf("unexpected detection %+v", ev)
+		}
+	}
+}
+
+func TestWebhookNotificationSinkPostsEvent(t *testing.T) {
+	received := make(chan stegoDetectedEvent, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var got stegoDetectedEvent
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		received <- got
+	}))
+	defer srv.Close()
+
+	NewWebhookNotificationSink(srv.URL).OnStegoDetected(42, SmartContractData{ContractID: "stego_0"})
+	select {
+	case got := <-received:
+		if got.Event != "stego_detected" || got.BlockHeight != 42 || got.Contract.ContractID != "stego_0" {
+			t.Fatalf("payload = %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+}
+
+func TestWebhookNotificationSinkDoesNotBlockOnSlowReceiver(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	sink := NewWebhookNotificationSink(srv.URL)
+	start := time.Now()
+	// One delivery in flight, a full queue, then overflow that is dropped.
+	for i := 0; i < webhookQueueSize+10; i++ {
+		sink.OnStegoDetected(int64(i), SmartContractData{ContractID: fmt.Sprintf("stego_%d", i)})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("OnStegoDetected blocked for %v behind a stalled receiver", elapsed)
+	}
+	if n := len(sink.queue); n > webhookQueueSize {
+		t.Fatalf("queue holds %d detections, want at most %d", n, webhookQueueSize)
+	}
+}
+
+func TestWebhookSinkPostsBlockPayloadAndRetriesOnce(t *testing.T) {
+	var attempts atomic.Int32
+	received := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		if payload["event"] != "block_stego_detected" {
+			return
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received <- payload
+	}))
+	defer srv.Close()
+
+	t.Setenv("STARGATE_STEGO_WEBHOOK_URLS", srv.URL)
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 2}
+	source.addChain(t, 960, 960, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	if len(bm.notificationSinks) != 1 {
+		t.Fatalf("sinks = %d, want the one webhook", len(bm.notificationSinks))
+	}
+	bm.notificationSinks[0].(*WebhookNotificationSink).retryDelay = 10 * time.Millisecond
+	bm.SetImageScanner(stegoScanner{})
+
+	if err := bm.ProcessBlock(960); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+
+	var payload map[string]any
+	select {
+	case payload = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("block webhook not delivered; %d attempts", attempts.Load())
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Fatalf("attempts = %d, want 2 (one retry)", n)
+	}
+	if payload["block_height"] != float64(960) {
+		t.Fatalf("payload = %v", payload)
+	}
+	if hash, _ := payload["block_hash"].(string); hash == "" {
+		t.Fatalf("payload missing block_hash: %v", payload)
+	}
+	if _, ok := payload["processed_at"].(string); !ok {
+		t.Fatalf("payload missing processed_at: %v", payload)
+	}
+	contracts, _ := payload["contracts"].([]any)
+	if len(contracts) != 2 {
+		t.Fatalf("contracts = %v, want 2", payload["contracts"])
+	}
+	first, _ := contracts[0].(map[string]any)
+	if first["contract_id"] == "" || first["block_height"] != float64(960) || first["confidence"] != 0.9 {
+		t.Fatalf("contract = %v", first)
+	}
+}
+
+type blockRecordingSink struct {
+	recordingSink
+	blocks []int64
+}
+
+func (r *blockRecordingSink) OnBlockProcessed(blockHeight int64, _ string, _ []SmartContractData) {
+	r.blocks = append(r.blocks, blockHeight)
+}
+
+func TestNotifyBlockProcessedSkipsBlocksWithoutContracts(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.notificationSinks = nil
+	sink := &blockRecordingSink{}
+	bm.AddNotificationSink(sink)
+
+	bm.notifyBlockProcessed(1, "hash", nil)
+	bm.notifyBlockProcessed(2, "hash", []SmartContractData{{ContractID: "stego_0"}})
+	if len(sink.blocks) != 1 || sink.blocks[0] != 2 {
+		t.Fatalf("blocks notified = %v, want [2]", sink.blocks)
+	}
+}
+
+func TestNotificationSinksFromEnvAcceptsLegacyWebhookURL(t *testing.T) {
+	t.Setenv("STARGATE_STEGO_NOTIFY_LOG", "")
+	t.Setenv("STARGATE_STEGO_WEBHOOK_URLS", "")
+	t.Setenv("STEGO_WEBHOOK_URL", "http://legacy.example/hook")
+	sinks := notificationSinksFromEnv()
+	if len(sinks) != 1 || sinks[0].(*WebhookNotificationSink).URL != "http://legacy.example/hook" {
+		t.Fatalf("sinks = %+v, want the legacy webhook", sinks)
+	}
+
+	t.Setenv("STARGATE_STEGO_WEBHOOK_URLS", "http://a.example/hook, http://legacy.example/hook")
+	if sinks = notificationSinksFromEnv(); len(sinks) != 2 {
+		t.Fatalf("sinks = %d, want 2 with the duplicate URL merged", len(sinks))
+	}
+}
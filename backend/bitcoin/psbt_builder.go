@@ -197,8 +197,8 @@ func BuildFundingPSBT(client *MempoolClient, params *chaincfg.Params, req PSBTRe
 			return nil, err
 		}
 		commitmentSats = req.CommitmentSats
-		if commitmentSats < 546 {
-			commitmentSats = 546
+		if commitmentSats < DustLimitSats {
+			commitmentSats = DustLimitSats
 		}
 	} else if len(req.PixelHash) > 0 && req.CommitmentSats > 0 {
 		// Legacy path: P2WSH hashlock (backward compat for old callers)
@@ -207,8 +207,8 @@ func BuildFundingPSBT(client *MempoolClient, params *chaincfg.Params, req PSBTRe
 			return nil, err
 		}
 		commitmentSats = req.CommitmentSats
-		if commitmentSats < 546 {
-			commitmentSats = 546
+		if commitmentSats < DustLimitSats {
+			commitmentSats = DustLimitSats
 		}
 	}
 
@@ -457,10 +457,10 @@ func BuildRaiseFundPSBT(client *MempoolClient, params *chaincfg.Params, payers [
 			return nil, err
 		}
 		if commitmentSats <= 0 {
-			commitmentSats = 1000
+			commitmentSats = DefaultCommitmentSats
 		}
-		if commitmentSats < 546 {
-			commitmentSats = 546
+		if commitmentSats < DustLimitSats {
+			commitmentSats = DustLimitSats
 		}
 	}
 	_ = donation // will be used when BuildRaiseFundPSBT is updated to accept DonationAddress
@@ -5,14 +5,26 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"strings"
 	"time"
+
+	"stargate-backend/stego"
 )
 
+// ErrRawBlockRateLimited reports that a raw block source (or the local
+// request budget) refused the download; retrying later may succeed.
+var ErrRawBlockRateLimited = errors.New("raw block source rate limited")
+
+// ErrRawBlockNotFound reports that the sources do not have a block at the
+// requested height; retrying immediately will not help.
+var ErrRawBlockNotFound = errors.New("raw block not found")
+
 // RawBlockClient handles efficient raw block downloading and parsing
 type RawBlockClient struct {
 	httpClient    *http.Client
@@ -20,6 +32,7 @@ type RawBlockClient struct {
 	connected     bool
 	totalRequests int64
 	network       string
+	apiBase       string // Esplora base overriding the per-network defaults; disables the blockchain.info fallback
 }
 
 // NewRawBlockClient creates a new raw block client
@@ -37,24 +50,46 @@ func NewRawBlockClient(network string) *RawBlockClient {
 	}
 }
 
-// GetRawBlockHex downloads raw block data as hex from multiple sources
+// NewRawBlockClientWithHTTP creates a raw block client reading from a single
+// Esplora-compatible baseURL through client. The caller owns request pacing,
+// so no local rate limit is applied.
+func NewRawBlockClientWithHTTP(baseURL string, client *http.Client) *RawBlockClient {
+	return &RawBlockClient{
+		httpClient:  client,
+		rateLimiter: NewRateLimiter(math.MaxInt32, time.Hour, 0),
+		network:     "mainnet",
+		apiBase:     strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// rawBlockAPI is one download URL; esplora sources return binary blocks.
+type rawBlockAPI struct {
+	url     string
+	esplora bool
+}
+
+// GetRawBlockHex downloads raw block data as hex from multiple sources.
+// When every source fails, the error wraps ErrRawBlockRateLimited if any
+// source answered 429, or ErrRawBlockNotFound if any answered 404.
 func (rbc *RawBlockClient) GetRawBlockHex(blockHeight int64) (string, error) {
 	rbc.totalRequests++
 
 	// Apply rate limiting
 	if !rbc.rateLimiter.AllowRequest() {
-		return "", fmt.Errorf("rate limit exceeded")
+		return "", fmt.Errorf("local request budget exhausted: %w", ErrRawBlockRateLimited)
 	}
 
 	// Try multiple APIs for raw block hex
-	apis := rbc.getRawBlockAPIs(blockHeight)
+	apis, lookupErr := rbc.getRawBlockAPIs(blockHeight)
+	rateLimited := errors.Is(lookupErr, ErrRawBlockRateLimited)
+	notFound := errors.Is(lookupErr, ErrRawBlockNotFound)
 
-	for _, apiURL := range apis {
-		log.Printf("Trying to download raw block %d from %s", blockHeight, apiURL)
+	for _, api := range apis {
+		log.Printf("Trying to download raw block %d from %s", blockHeight, api.url)
 
-		resp, err := rbc.httpClient.Get(apiURL)
+		resp, err := rbc.httpClient.Get(api.url)
 		if err != nil {
-			log.Printf("Failed to fetch from %s: %v", apiURL, err)
+			log.Printf("Failed to fetch from %s: %v", api.url, err)
 			continue
 		}
 		defer resp.Body.Close()
@@ -62,12 +97,12 @@ func (rbc *RawBlockClient) GetRawBlockHex(blockHeight int64) (string, error) {
 		if resp.StatusCode == 200 {
 			body, err := io.ReadAll(resp.Body)
 			if err != nil {
-				log.Printf("Failed to read response from %s: %v", apiURL, err)
+				log.Printf("Failed to read response from %s: %v", api.url, err)
 				continue
 			}
 
 			var hexData string
-			if strings.Contains(apiURL, "blockstream.info") || strings.Contains(apiURL, "mempool.space") {
+			if api.esplora {
 				// Blockstream returns binary data, convert to hex
 				hexData = hex.EncodeToString(body)
 			} else {
@@ -80,47 +115,63 @@ func (rbc *RawBlockClient) GetRawBlockHex(blockHeight int64) (string, error) {
 				return hexData, nil
 			}
 		} else {
-			log.Printf("API %s returned status %d", apiURL, resp.StatusCode)
+			log.Printf("API %s returned status %d", api.url, resp.StatusCode)
+			switch resp.StatusCode {
+			case http.StatusTooManyRequests:
+				rateLimited = true
+			case http.StatusNotFound:
+				notFound = true
+			}
 		}
 	}
 
+	switch {
+	case rateLimited:
+		return "", fmt.Errorf("failed to fetch raw block %d: %w", blockHeight, ErrRawBlockRateLimited)
+	case notFound:
+		return "", fmt.Errorf("failed to fetch raw block %d: %w", blockHeight, ErrRawBlockNotFound)
+	}
 	return "", fmt.Errorf("failed to fetch raw block from all APIs")
 }
 
-// getRawBlockAPIs returns a list of APIs to try for raw block data
-func (rbc *RawBlockClient) getRawBlockAPIs(blockHeight int64) []string {
-	var apis []string
+// getRawBlockAPIs returns a list of APIs to try for raw block data, and the
+// error of the Esplora hash lookup when that source had to be left out.
+func (rbc *RawBlockClient) getRawBlockAPIs(blockHeight int64) ([]rawBlockAPI, error) {
+	var apis []rawBlockAPI
 
 	// Primary: Blockstream/Mempool API (supports mainnet, testnet, testnet4, signet)
 	var blockstreamBase string
-	switch rbc.network {
-	case "testnet4":
+	switch {
+	case rbc.apiBase != "":
+		blockstreamBase = rbc.apiBase
+	case rbc.network == "testnet4":
 		blockstreamBase = "https://mempool.space/testnet4/api"
-	case "testnet":
+	case rbc.network == "testnet":
 		blockstreamBase = "https://blockstream.info/testnet/api"
-	case "signet":
+	case rbc.network == "signet":
 		blockstreamBase = "https://mempool.space/signet/api"
 	default:
 		blockstreamBase = "https://blockstream.info/api"
 	}
 
 	// For blockstream, we need to get hash first, then raw
-	if hash, err := rbc.getBlockHashFromBlockstream(blockstreamBase, blockHeight); err == nil {
-		apis = append(apis, fmt.Sprintf("%s/block/%s/raw", blockstreamBase, hash))
+	hash, lookupErr := rbc.getBlockHashFromBlockstream(blockstreamBase, blockHeight)
+	if lookupErr == nil {
+		apis = append(apis, rawBlockAPI{url: fmt.Sprintf("%s/block/%s/raw", blockstreamBase, hash), esplora: true})
 	}
 
 	// Fallback: blockchain.info (only for mainnet/testnet)
-	if rbc.network == "mainnet" || rbc.network == "testnet" {
+	if rbc.apiBase == "" && (rbc.network == "mainnet" || rbc.network == "testnet") {
 		var blockchainBase string
 		if rbc.network == "testnet" {
 			blockchainBase = "https://testnet.blockchain.info"
 		} else {
 			blockchainBase = "https://blockchain.info"
 		}
-		apis = append(apis, fmt.Sprintf("%s/rawblock/%d?format=hex", blockchainBase, blockHeight))
+		apis = append(apis, rawBlockAPI{url: fmt.Sprintf("%s/rawblock/%d?format=hex", blockchainBase, blockHeight)})
 	}
 
-	return apis
+	return apis, lookupErr
 }
 
 // getBlockHashFromBlockstream gets block hash from blockstream API
@@ -133,7 +184,13 @@ func (rbc *RawBlockClient) getBlockHashFromBlockstream(baseURL string, height in
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
+	switch resp.StatusCode {
+	case 200:
+	case http.StatusTooManyRequests:
+		return "", fmt.Errorf("block-height %d: %w", height, ErrRawBlockRateLimited)
+	case http.StatusNotFound:
+		return "", fmt.Errorf("block-height %d: %w", height, ErrRawBlockNotFound)
+	default:
 		return "", fmt.Errorf("status %d", resp.StatusCode)
 	}
 
@@ -804,36 +861,6 @@ func detectImage(data []byte) (string, []byte) {
 	return "", nil
 }
 
-// trimToImageSignatureLocal mirrors the API-level helper to avoid dependency cycles.
-func trimToImageSignatureLocal(b []byte) []byte {
-	if len(b) < 8 {
-		return b
-	}
-	sigs := [][]byte{
-		{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}, // PNG
-		{0xFF, 0xD8, 0xFF},       // JPEG
-		[]byte("RIFF"),           // WEBP (check WEBP marker)
-		{0x47, 0x49, 0x46, 0x38}, // GIF
-		[]byte("ftypavif"),       // AVIF
-	}
-	for _, sig := range sigs {
-		if idx := bytes.Index(b, sig); idx >= 0 {
-			if sig[0] == 'R' && len(b) >= idx+12 {
-				if !(b[idx+8] == 'W' && b[idx+9] == 'E' && b[idx+10] == 'B' && b[idx+11] == 'P') {
-					continue
-				}
-			}
-			if string(sig) == "ftypavif" {
-				if idx >= 4 {
-					return b[idx-4:]
-				}
-			}
-			return b[idx:]
-		}
-	}
-	return b
-}
-
 // dedupImage returns true if the image is new; false if we've already seen the same payload.
 func dedupImage(img *ExtractedImageData, seen map[string]bool) bool {
 	if len(img.Data) == 0 {
@@ -844,7 +871,7 @@ func dedupImage(img *ExtractedImageData, seen map[string]bool) bool {
 	// Skip SVG as it's text-based and doesn't have a binary signature we track.
 	isSVG := strings.Contains(strings.ToLower(img.ContentType), "svg") || img.Format == "svg"
 	if !isSVG && (strings.HasPrefix(img.ContentType, "image/") || strings.HasPrefix(img.Format, "png") || strings.HasPrefix(img.Format, "webp") || strings.HasPrefix(img.Format, "jpeg")) {
-		if trimmed := trimToImageSignatureLocal(img.Data); len(trimmed) > 0 {
+		if trimmed := stego.TrimToImageSignature(img.Data); len(trimmed) > 0 {
 			img.Data = trimmed
 			img.SizeBytes = len(trimmed)
 		}
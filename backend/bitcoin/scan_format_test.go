@@ -0,0 +1,46 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"stargate-backend/starlight"
+)
+
+func TestScanImagesDirectlyDecodesWebPAndGIF(t *testing.T) {
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{color.Black, color.White}), nil); err != nil {
+		t.Fatal(err)
+	}
+	webpData, err := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := starlight.NewAlphaScanner()
+	if err := scanner.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetImageScanner(scanner)
+
+	results, err := bm.scanImagesDirectly([]ExtractedImageData{
+		{TxID: "a", FileName: "a.webp", Format: "webp", Data: webpData},
+		{TxID: "b", FileName: "b.gif", Format: "gif", Data: gifData.Bytes()},
+		{TxID: "c", FileName: "c.svg", Format: "svg", Data: []byte("<svg/>")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"webp", "gif"} {
+		if results[i].ScanError != "" {
+			t.Fatalf("%s scan_error = %q, want a completed scan", name, results[i].ScanError)
+		}
+	}
+	if results[2].ScanError != "unsupported_format" {
+		t.Fatalf("svg scan_error = %q, want unsupported_format", results[2].ScanError)
+	}
+}
@@ -0,0 +1,112 @@
+package bitcoin
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"stargate-backend/core"
+)
+
+const (
+	defaultMaxConcurrentBlockScans = 2
+	defaultBlockScanQueueWait      = 10 * time.Second
+)
+
+// ScanLimiter bounds how many API-triggered block scans run at once, across
+// the REST scan endpoints and MCP scan_block_range. A request over the limit
+// waits up to the queue wait for a slot and is then rejected with 429. The
+// block monitor's own scans do not take a slot: they already run one block at
+// a time and must not be starved by API callers.
+type ScanLimiter struct {
+	slots chan struct{}
+	wait  time.Duration
+}
+
+// NewScanLimiter allows max concurrent scans (at least 1) and queues excess
+// requests for up to wait; wait 0 rejects them immediately.
+func NewScanLimiter(max int, wait time.Duration) *ScanLimiter {
+	if max < 1 {
+		max = 1
+	}
+	return &ScanLimiter{slots: make(chan struct{}, max), wait: wait}
+}
+
+// NewScanLimiterFromEnv reads STARGATE_MAX_CONCURRENT_BLOCK_SCANS (default
+// 2) and STARGATE_BLOCK_SCAN_QUEUE_WAIT (default 10s).
+func NewScanLimiterFromEnv() *ScanLimiter {
+	max := defaultMaxConcurrentBlockScans
+	if raw := strings.TrimSpace(os.Getenv("STARGATE_MAX_CONCURRENT_BLOCK_SCANS")); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			max = n
+		} else {
+			log.Printf("Ignoring invalid STARGATE_MAX_CONCURRENT_BLOCK_SCANS=%q, using %d", raw, max)
+		}
+	}
+	return NewScanLimiter(max, durationFromEnv("STARGATE_BLOCK_SCAN_QUEUE_WAIT", defaultBlockScanQueueWait))
+}
+
+// Acquire takes a slot, waiting up to the queue wait or until ctx is
+// cancelled. It reports whether a slot was taken; a taken slot must be given
+// back with Release.
+func (l *ScanLimiter) Acquire(ctx context.Context) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if l.wait <= 0 {
+		return false
+	}
+	timer := time.NewTimer(l.wait)
+	defer timer.Stop()
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Release gives back a slot taken by Acquire.
+func (l *ScanLimiter) Release() {
+	<-l.slots
+}
+
+// InFlight returns the number of scans currently holding a slot.
+func (l *ScanLimiter) InFlight() int {
+	return len(l.slots)
+}
+
+// Limit wraps a scan handler. Only POSTs start scans, so other methods
+// (CORS preflights, method errors) pass straight through.
+func (l *ScanLimiter) Limit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next(w, r)
+			return
+		}
+		if !l.Acquire(r.Context()) {
+			errorResp := core.NewErrorResponse(
+				"TOO_MANY_SCANS",
+				"Too many block scans in progress, retry later",
+				core.GenerateRequestID(),
+				map[string]any{"max_concurrent_scans": cap(l.slots)},
+			)
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(errorResp)
+			return
+		}
+		defer l.Release()
+		next(w, r)
+	}
+}
@@ -0,0 +1,117 @@
+package bitcoin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScanLimiterRejectsScansOverLimit(t *testing.T) {
+	const limit, callers = 2, 6
+	limiter := NewScanLimiter(limit, 0)
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{}, callers)
+	handler := limiter.Limit(func(w http.ResponseWriter, r *http.Request) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		started <- struct{}{}
+		<-release
+		running.Add(-1)
+	})
+
+	codes := make(chan int, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodPost, "/bitcoin/v1/scan/block", nil))
+			codes <- rec.Code
+		}()
+	}
+
+	// The callers that got a slot block in the handler; the rest return 429.
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+	rejected := 0
+	for rejected < callers-limit {
+		if code := <-codes; code != http.StatusTooManyRequests {
+			t.Fatalf("status %d while limit reached, want 429", code)
+		}
+		rejected++
+	}
+	if n := limiter.InFlight(); n != limit {
+		t.Fatalf("in flight = %d, want %d", n, limit)
+	}
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("admitted scan status %d, want 200", code)
+		}
+	}
+	if p := peak.Load(); p != limit {
+		t.Fatalf("peak concurrency = %d, want %d", p, limit)
+	}
+	if n := limiter.InFlight(); n != 0 {
+		t.Fatalf("slots leaked: %d in flight", n)
+	}
+}
+
+func TestScanLimiterQueuesWithinWait(t *testing.T) {
+	limiter := NewScanLimiter(1, 2*time.Second)
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	handler := limiter.Limit(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+	})
+
+	done := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodPost, "/api/data/scan", nil))
+			done <- rec.Code
+		}()
+	}
+	<-started
+	select {
+	case <-started:
+		t.Fatal("second scan started while the first held the only slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// Freeing the slot lets the queued scan run instead of being rejected.
+	release <- struct{}{}
+	<-started
+	close(release)
+	for i := 0; i < 2; i++ {
+		if code := <-done; code != http.StatusOK {
+			t.Fatalf("status %d, want 200 for queued scan", code)
+		}
+	}
+}
+
+func TestScanLimiterPassesNonPostThrough(t *testing.T) {
+	limiter := NewScanLimiter(1, 0)
+	limiter.slots <- struct{}{} // limit already reached
+	called := false
+	rec := httptest.NewRecorder()
+	limiter.Limit(func(w http.ResponseWriter, r *http.Request) { called = true })(rec, httptest.NewRequest(http.MethodOptions, "/bitcoin/v1/scan/block", nil))
+	if !called {
+		t.Fatal("OPTIONS preflight was limited")
+	}
+}
@@ -0,0 +1,26 @@
+package bitcoin
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// scanProbabilitiesFromEnv reports whether per-class scan probabilities are
+// stored with scan results and contract metadata. They are kept by default;
+// STARGATE_STORE_SCAN_PROBABILITIES=false drops them.
+func scanProbabilitiesFromEnv() bool {
+	raw := strings.TrimSpace(os.Getenv("STARGATE_STORE_SCAN_PROBABILITIES"))
+	if raw == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(raw)
+	return err != nil || enabled
+}
+
+// SetScanProbabilityStorage controls whether the scanner's per-class
+// probabilities are persisted in scan_result and as scan_probabilities in
+// smart contract metadata.
+func (bm *BlockMonitor) SetScanProbabilityStorage(enabled bool) {
+	bm.scanProbs = enabled
+}
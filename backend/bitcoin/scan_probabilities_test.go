@@ -0,0 +1,95 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"stargate-backend/core"
+)
+
+type probabilityScanner struct{}
+
+func (probabilityScanner) ScanImage([]byte, core.ScanOptions) (*core.ScanResult, error) {
+	return &core.ScanResult{
+		IsStego:       true,
+		Confidence:    0.9,
+		StegoType:     "lsb",
+		Probabilities: map[string]float64{"clean": 0.1, "lsb": 0.85, "alpha": 0.05},
+	}, nil
+}
+
+func summaryContractsAt(t *testing.T, bm *BlockMonitor, height int64) []SmartContractData {
+	t.Helper()
+	dir, err := bm.findBlockDirectory(height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "inscriptions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary map[string]any
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		t.Fatal(err)
+	}
+	contracts, err := summaryContracts(summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contracts
+}
+
+func TestScanProbabilitiesRoundTripThroughSummary(t *testing.T) {
+	want := map[string]float64{"clean": 0.1, "lsb": 0.85, "alpha": 0.05}
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 1}
+	source.addChain(t, 1000, 1001, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+	bm.SetImageScanner(probabilityScanner{})
+
+	if err := bm.ProcessBlock(1000); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	images := summaryImages(t, bm, 1000)
+	if len(images) != 1 {
+		t.Fatalf("got %d images, want 1", len(images))
+	}
+	raw, ok := images[0]["scan_result"].(map[string]any)
+	if !ok {
+		t.Fatalf("image has no scan_result: %v", images[0])
+	}
+	entry, err := ParseScanResultEntry(raw)
+	if err != nil {
+		t.Fatalf("parse scan_result: %v", err)
+	}
+	if !reflect.DeepEqual(entry.Probabilities, want) {
+		t.Fatalf("scan_result probabilities = %v, want %v", entry.Probabilities, want)
+	}
+	contracts := summaryContractsAt(t, bm, 1000)
+	if len(contracts) != 1 {
+		t.Fatalf("got %d contracts, want 1", len(contracts))
+	}
+	got, err := scanProbabilities("scan_probabilities", contracts[0].Metadata["scan_probabilities"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("contract scan_probabilities = %v, want %v", got, want)
+	}
+
+	bm.SetScanProbabilityStorage(false)
+	if err := bm.ProcessBlock(1001); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	images = summaryImages(t, bm, 1001)
+	if raw, _ := images[0]["scan_result"].(map[string]any); raw["probabilities"] != nil {
+		t.Fatalf("disabled storage kept probabilities: %v", raw)
+	}
+	if contracts := summaryContractsAt(t, bm, 1001); contracts[0].Metadata["scan_probabilities"] != nil {
+		t.Fatalf("disabled storage kept contract probabilities: %v", contracts[0].Metadata)
+	}
+}
@@ -0,0 +1,226 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ScanResultEntry is one image's steganography scan result. The block monitor
+// works with entries internally and converts them to the map form stored as
+// scan_result in inscriptions.json (and passed to DataStorage) only at the
+// JSON boundary via ToMap.
+type ScanResultEntry struct {
+	TxID             string
+	ImageIndex       int
+	FileName         string
+	SizeBytes        int
+	Format           string
+	ScannedAt        int64
+	IsStego          bool
+	Confidence       float64
+	StegoType        string
+	ExtractedMessage string
+	ScanError        string
+	StegoDetails     any
+	// Probabilities is the scanner's per-class distribution, omitted from the
+	// map when empty.
+	Probabilities map[string]float64
+	// RescannedAt is set by RescanImage and omitted from the map when zero.
+	RescannedAt int64
+	// Extra keeps keys that have no field above, so parsing and re-serialising a
+	// result never drops data.
+	Extra map[string]any
+}
+
+// ToMap returns the scan_result map persisted in inscriptions.json.
+func (e ScanResultEntry) ToMap() map[string]any {
+	out := make(map[string]any, 12+len(e.Extra))
+	for k, v := range e.Extra {
+		out[k] = v
+	}
+	out["tx_id"] = e.TxID
+	out["image_index"] = e.ImageIndex
+	out["file_name"] = e.FileName
+	out["size_bytes"] = e.SizeBytes
+	out["format"] = e.Format
+	out["scanned_at"] = e.ScannedAt
+	out["is_stego"] = e.IsStego
+	out["confidence"] = e.Confidence
+	out["stego_type"] = e.StegoType
+	out["extracted_message"] = e.ExtractedMessage
+	out["scan_error"] = e.ScanError
+	out["stego_details"] = e.StegoDetails
+	if len(e.Probabilities) > 0 {
+		out["probabilities"] = e.Probabilities
+	}
+	if e.RescannedAt != 0 {
+		out["rescanned_at"] = e.RescannedAt
+	}
+	return out
+}
+
+// MarshalJSON encodes the entry in its map form.
+func (e ScanResultEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.ToMap())
+}
+
+// UnmarshalJSON decodes a scan_result object with ParseScanResultEntry.
+func (e *ScanResultEntry) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw map[string]any
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	parsed, err := ParseScanResultEntry(raw)
+	if err != nil {
+		return err
+	}
+	*e = parsed
+	return nil
+}
+
+// ParseScanResultEntry converts a scan_result map into an entry. Known keys
+// must hold values of the expected type; anything else is kept in Extra.
+func ParseScanResultEntry(raw map[string]any) (ScanResultEntry, error) {
+	var e ScanResultEntry
+	var err error
+	for key, value := range raw {
+		switch key {
+		case "tx_id":
+			e.TxID, err = scanString(key, value)
+		case "image_index":
+			e.ImageIndex, err = scanInt(key, value)
+		case "file_name":
+			e.FileName, err = scanString(key, value)
+		case "size_bytes":
+			e.SizeBytes, err = scanInt(key, value)
+		case "format":
+			e.Format, err = scanString(key, value)
+		case "scanned_at":
+			e.ScannedAt, err = scanInt64(key, value)
+		case "is_stego":
+			e.IsStego, err = scanBool(key, value)
+		case "confidence":
+			e.Confidence, err = scanFloat(key, value)
+		case "stego_type":
+			e.StegoType, err = scanString(key, value)
+		case "extracted_message":
+			e.ExtractedMessage, err = scanString(key, value)
+		case "scan_error":
+			e.ScanError, err = scanString(key, value)
+		case "stego_details":
+			e.StegoDetails = value
+		case "probabilities":
+			e.Probabilities, err = scanProbabilities(key, value)
+		case "rescanned_at":
+			e.RescannedAt, err = scanInt64(key, value)
+		default:
+			if e.Extra == nil {
+				e.Extra = make(map[string]any)
+			}
+			e.Extra[key] = value
+		}
+		if err != nil {
+			return ScanResultEntry{}, err
+		}
+	}
+	return e, nil
+}
+
+// scanResultMaps converts entries to their map form for storage and JSON output.
+func scanResultMaps(entries []ScanResultEntry) []map[string]any {
+	out := make([]map[string]any, len(entries))
+	for i, entry := range entries {
+		out[i] = entry.ToMap()
+	}
+	return out
+}
+
+func scanString(key string, value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	}
+	return "", fmt.Errorf("scan result %s: expected string, got %T", key, value)
+}
+
+func scanBool(key string, value any) (bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	}
+	return false, fmt.Errorf("scan result %s: expected bool, got %T", key, value)
+}
+
+func scanFloat(key string, value any) (float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("scan result %s: expected number, got %T", key, value)
+}
+
+func scanInt64(key string, value any) (int64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v), nil
+		}
+	case json.Number:
+		if n, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("scan result %s: expected integer, got %T(%v)", key, value, value)
+}
+
+func scanProbabilities(key string, value any) (map[string]float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]float64:
+		return v, nil
+	case map[string]any:
+		out := make(map[string]float64, len(v))
+		for class, raw := range v {
+			p, err := scanFloat(key+"."+class, raw)
+			if err != nil {
+				return nil, err
+			}
+			out[class] = p
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("scan result %s: expected object, got %T", key, value)
+}
+
+func scanInt(key string, value any) (int, error) {
+	n, err := scanInt64(key, value)
+	return int(n), err
+}
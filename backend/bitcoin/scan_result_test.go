@@ -0,0 +1,113 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fullScanResultEntry sets every field of ScanResultEntry to a non-zero value,
+// so a field added without ToMap/ParseScanResultEntry support fails the round trip.
+func fullScanResultEntry(t *testing.T) ScanResultEntry {
+	t.Helper()
+	var e ScanResultEntry
+	v := reflect.ValueOf(&e).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		name := v.Type().Field(i).Name
+		switch f.Kind() {
+		case reflect.String:
+			f.SetString("value-" + name)
+		case reflect.Int, reflect.Int64:
+			f.SetInt(int64(100 + i))
+		case reflect.Float64:
+			f.SetFloat(0.75)
+		case reflect.Bool:
+			f.SetBool(true)
+		case reflect.Interface:
+			f.Set(reflect.ValueOf(map[string]any{"lsb": "red"}))
+		case reflect.Map:
+			if f.Type() == reflect.TypeOf(map[string]float64{}) {
+				f.Set(reflect.ValueOf(map[string]float64{"stego": 0.75, "clean": 0.25}))
+				continue
+			}
+			f.Set(reflect.ValueOf(map[string]any{"payout_address": "bc1qexample"}))
+		default:
+			t.Fatalf("field %s has unhandled kind %s", name, f.Kind())
+		}
+	}
+	return e
+}
+
+func TestScanResultEntryMapRoundTrip(t *testing.T) {
+	want := fullScanResultEntry(t)
+
+	got, err := ParseScanResultEntry(want.ToMap())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("map round trip:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestScanResultEntryJSONRoundTrip(t *testing.T) {
+	want := fullScanResultEntry(t)
+	want.StegoDetails = map[string]any{"lsb": "red"}
+
+	raw, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got ScanResultEntry
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("JSON round trip:\n got %+v\nwant %+v", got, want)
+	}
+
+	// The persisted form is unchanged: the same keys scan_result always had.
+	var asMap map[string]any
+	if err := json.Unmarshal(raw, &asMap); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"tx_id", "image_index", "file_name", "size_bytes", "format", "scanned_at", "is_stego", "confidence", "stego_type", "extracted_message", "scan_error", "stego_details"} {
+		if _, ok := asMap[key]; !ok {
+			t.Fatalf("serialised entry is missing %q: %s", key, raw)
+		}
+	}
+}
+
+func TestParseScanResultEntryKeepsUnknownKeys(t *testing.T) {
+	// "is_steg" is a typo; it must survive rather than vanish.
+	entry, err := ParseScanResultEntry(map[string]any{"tx_id": "abc", "is_steg": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.IsStego {
+		t.Fatal("typo key populated IsStego")
+	}
+	if entry.Extra["is_steg"] != true {
+		t.Fatalf("typo key dropped: %+v", entry)
+	}
+	if entry.ToMap()["is_steg"] != true {
+		t.Fatal("typo key lost when serialising")
+	}
+}
+
+func TestParseScanResultEntryRejectsWrongTypes(t *testing.T) {
+	cases := map[string]any{
+		"is_stego":    "true",
+		"confidence":  "0.9",
+		"image_index": 1.5,
+		"tx_id":       42,
+	}
+	for key, value := range cases {
+		_, err := ParseScanResultEntry(map[string]any{key: value})
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Fatalf("%s=%v: err = %v, want type error naming the key", key, value, err)
+		}
+	}
+}
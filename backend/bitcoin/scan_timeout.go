@@ -0,0 +1,49 @@
+package bitcoin
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"stargate-backend/core"
+)
+
+const defaultScanImageTimeout = 30 * time.Second
+
+// errScanTimeout is recorded as scan_error "timeout" for images that exceed the per-image budget.
+var errScanTimeout = errors.New("timeout")
+
+// scanImageTimeoutFromEnv returns the per-image scan budget.
+// Controlled by STARGATE_SCAN_IMAGE_TIMEOUT (Go duration, e.g. 10s); 0 disables it.
+func scanImageTimeoutFromEnv() time.Duration {
+	return durationFromEnv("STARGATE_SCAN_IMAGE_TIMEOUT", defaultScanImageTimeout)
+}
+
+// scanImageWithTimeout runs scanner.ScanImage bounded by bm.scanTimeout. The
+// scanner API is not context-aware, so a hung scan keeps its goroutine until it
+// returns; the block scan itself moves on to the next image.
+func (bm *BlockMonitor) scanImageWithTimeout(scanner ImageScanner, data []byte, options core.ScanOptions) (*core.ScanResult, error) {
+	if bm.scanTimeout <= 0 {
+		return scanner.ScanImage(data, options)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bm.scanTimeout)
+	defer cancel()
+
+	type scanOutcome struct {
+		result *core.ScanResult
+		err    error
+	}
+	done := make(chan scanOutcome, 1)
+	go func() {
+		result, err := scanner.ScanImage(data, options)
+		done <- scanOutcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		return nil, errScanTimeout
+	}
+}
@@ -0,0 +1,75 @@
+package bitcoin
+
+import (
+	"testing"
+	"time"
+
+	"stargate-backend/core"
+	"stargate-backend/starlight"
+)
+
+// hangingScanner blocks on images whose first byte is hangOn until release is closed.
+type hangingScanner struct {
+	hangOn  byte
+	release chan struct{}
+}
+
+func (s *hangingScanner) ScanImage(data []byte, _ core.ScanOptions) (*core.ScanResult, error) {
+	if len(data) > 0 && data[0] == s.hangOn {
+		<-s.release
+	}
+	return &core.ScanResult{IsStego: true, Confidence: 0.8}, nil
+}
+
+func TestScanImagesDirectlyTimesOutHungImage(t *testing.T) {
+	scanner := &hangingScanner{hangOn: 1, release: make(chan struct{})}
+	defer close(scanner.release)
+
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetImageScanner(scanner)
+	bm.scanTimeout = 50 * time.Millisecond
+
+	images := []ExtractedImageData{
+		{TxID: "a", FileName: "a.png", Data: []byte{0}},
+		{TxID: "b", FileName: "b.png", Data: []byte{1}},
+		{TxID: "c", FileName: "c.png", Data: []byte{2}},
+	}
+	start := time.Now()
+	results, err := bm.scanImagesDirectly(images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("scan took %v; hung image stalled the block", elapsed)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if results[1].ScanError != "timeout" || results[1].IsStego {
+		t.Fatalf("hung image result = %+v, want scan_error timeout", results[1])
+	}
+	for _, i := range []int{0, 2} {
+		if !results[i].IsStego || results[i].ScanError != "" {
+			t.Fatalf("image %d result = %+v, want completed scan", i, results[i])
+		}
+	}
+}
+
+type openBreakerScanner struct{}
+
+func (openBreakerScanner) ScanImage([]byte, core.ScanOptions) (*core.ScanResult, error) {
+	return &core.ScanResult{Prediction: "not_scanned"}, starlight.ErrCircuitOpen
+}
+
+func TestScanImagesDirectlyRecordsOpenBreakerAsNotScanned(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetImageScanner(openBreakerScanner{})
+
+	results, err := bm.scanImagesDirectly([]ExtractedImageData{{TxID: "a", FileName: "a.png", Data: []byte{0}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ScanError != "not_scanned" || results[0].IsStego {
+		t.Fatalf("results = %+v, want a single not_scanned entry", results)
+	}
+}
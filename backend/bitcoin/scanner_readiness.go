@@ -0,0 +1,120 @@
+package bitcoin
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultScannerReadyTimeout = 30 * time.Second
+	// minScannerReadyPoll keeps very short timeouts from polling in a busy
+	// loop (or handing time.NewTicker a zero interval).
+	minScannerReadyPoll = 10 * time.Millisecond
+)
+
+// scannerHealthReporter is implemented by the starlight ScannerManager.
+type scannerHealthReporter interface {
+	GetHealthStatus() map[string]interface{}
+}
+
+// scannerInitReporter is implemented by scanners that initialize lazily.
+type scannerInitReporter interface {
+	IsInitialized() bool
+}
+
+// scannerReadyTimeoutFromEnv returns how long monitoring waits for the scanner
+// before the first scan. Controlled by STARGATE_SCANNER_READY_TIMEOUT (Go
+// duration, e.g. 1m); 0 skips the wait.
+func scannerReadyTimeoutFromEnv() time.Duration {
+	return durationFromEnv("STARGATE_SCANNER_READY_TIMEOUT", defaultScannerReadyTimeout)
+}
+
+// scannerReadyDeferFromEnv reports whether monitoring should keep waiting for
+// the scanner instead of starting without it. Controlled by
+// STARGATE_SCANNER_READY_DEFER.
+func scannerReadyDeferFromEnv() bool {
+	enabled, _ := strconv.ParseBool(strings.TrimSpace(os.Getenv("STARGATE_SCANNER_READY_DEFER")))
+	return enabled
+}
+
+// SetScannerReadiness sets how long monitoring waits for the scanner before
+// the first scan and whether it keeps waiting once that time has passed.
+func (bm *BlockMonitor) SetScannerReadiness(timeout time.Duration, deferUntilReady bool) {
+	bm.scanReadyWait = timeout
+	bm.scanReadyDefer = deferUntilReady
+}
+
+// scannerReady reports whether the configured scanner can scan, with the
+// reason when it cannot. Scanners that expose no status are assumed ready.
+func (bm *BlockMonitor) scannerReady() (bool, string) {
+	scanner := bm.scanner()
+	if scanner == nil {
+		return false, "no scanner configured"
+	}
+	if reporter, ok := scanner.(scannerHealthReporter); ok {
+		status := reporter.GetHealthStatus()
+		if initialized, _ := status["initialized"].(bool); !initialized {
+			return false, "scanner not initialized"
+		}
+		if healthy, ok := status["scanner_healthy"].(bool); ok && !healthy {
+			return false, "scanner reports unhealthy"
+		}
+		return true, ""
+	}
+	if reporter, ok := scanner.(scannerInitReporter); ok && !reporter.IsInitialized() {
+		return false, "scanner not initialized"
+	}
+	return true, ""
+}
+
+// awaitScannerReady blocks until the scanner is ready or scanReadyWait has
+// passed, so the first blocks are not silently recorded without stego
+// detection. When the wait runs out it warns and returns true, unless
+// scanReadyDefer is set, in which case it keeps waiting. Without a configured
+// scanner there is nothing to wait for, so it returns at once unless
+// scanReadyDefer asks to hold monitoring back. It returns false if the
+// monitor is stopped while waiting.
+func (bm *BlockMonitor) awaitScannerReady() bool {
+	if bm.scanReadyWait <= 0 {
+		return true
+	}
+	if bm.scanner() == nil && !bm.scanReadyDefer {
+		log.Printf("No scanner configured; monitoring without stego detection")
+		return true
+	}
+	ready, reason := bm.scannerReady()
+	if ready {
+		return true
+	}
+	log.Printf("Waiting up to %v for scanner before monitoring: %s", bm.scanReadyWait, reason)
+
+	poll := min(max(bm.scanReadyWait/10, minScannerReadyPoll), time.Second)
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+	deadline := time.Now().Add(bm.scanReadyWait)
+	for {
+		select {
+		case <-bm.stopChan:
+			return false
+		case <-ticker.C:
+		}
+		if ready, reason = bm.scannerReady(); ready {
+			log.Printf("Scanner ready, starting block monitoring")
+			return true
+		}
+		if time.Now().Before(deadline) {
+			continue
+		}
+		warning := fmt.Sprintf("WARNING: scanner not ready after %v (%s)", bm.scanReadyWait, reason)
+		if !bm.scanReadyDefer {
+			log.Printf("%s; monitoring without stego detection until it is", warning)
+			return true
+		}
+		log.Printf("%s; deferring block monitoring", warning)
+		deadline = time.Now().Add(bm.scanReadyWait)
+	}
+}
@@ -0,0 +1,123 @@
+package bitcoin
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stargate-backend/core"
+)
+
+// lazyScanner reports IsInitialized false until ready is set.
+type lazyScanner struct {
+	ready atomic.Bool
+}
+
+func (s *lazyScanner) ScanImage([]byte, core.ScanOptions) (*core.ScanResult, error) {
+	return &core.ScanResult{}, nil
+}
+
+func (s *lazyScanner) IsInitialized() bool { return s.ready.Load() }
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(prev) })
+	return &buf
+}
+
+func TestAwaitScannerReadyWarnsAndProceedsAfterTimeout(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetImageScanner(&lazyScanner{})
+	bm.SetScannerReadiness(50*time.Millisecond, false)
+	bm.stopChan = make(chan bool)
+	logs := captureLog(t)
+
+	start := time.Now()
+	if !bm.awaitScannerReady() {
+		t.Fatal("awaitScannerReady returned false without a stop")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("returned after %v, want to wait for the 50ms timeout", elapsed)
+	}
+	if !strings.Contains(logs.String(), "WARNING: scanner not ready") {
+		t.Fatalf("no readiness warning logged: %s", logs)
+	}
+}
+
+func TestAwaitScannerReadyDefersUntilReady(t *testing.T) {
+	scanner := &lazyScanner{}
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetImageScanner(scanner)
+	bm.SetScannerReadiness(20*time.Millisecond, true)
+	bm.stopChan = make(chan bool)
+	logs := captureLog(t)
+
+	readyAt := time.Now().Add(150 * time.Millisecond)
+	time.AfterFunc(150*time.Millisecond, func() { scanner.ready.Store(true) })
+	if !bm.awaitScannerReady() {
+		t.Fatal("awaitScannerReady returned false without a stop")
+	}
+	if time.Now().Before(readyAt) {
+		t.Fatal("monitoring started before the scanner was ready")
+	}
+	if !strings.Contains(logs.String(), "deferring block monitoring") {
+		t.Fatalf("no deferral warning logged: %s", logs)
+	}
+}
+
+func TestAwaitScannerReadyStopsWhileDeferred(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetImageScanner(&lazyScanner{})
+	bm.SetScannerReadiness(20*time.Millisecond, true)
+	bm.stopChan = make(chan bool)
+	captureLog(t)
+
+	time.AfterFunc(60*time.Millisecond, func() { close(bm.stopChan) })
+	if bm.awaitScannerReady() {
+		t.Fatal("awaitScannerReady returned true after the monitor was stopped")
+	}
+}
+
+func TestAwaitScannerReadySkipsWaitForReadyScanner(t *testing.T) {
+	scanner := &lazyScanner{}
+	scanner.ready.Store(true)
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetImageScanner(scanner)
+	bm.SetScannerReadiness(time.Hour, true)
+
+	if !bm.awaitScannerReady() {
+		t.Fatal("ready scanner did not start monitoring")
+	}
+}
+
+func TestAwaitScannerReadyHandlesTinyTimeouts(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetImageScanner(&lazyScanner{})
+	bm.SetScannerReadiness(time.Nanosecond, false)
+	bm.stopChan = make(chan bool)
+	captureLog(t)
+
+	if !bm.awaitScannerReady() {
+		t.Fatal("awaitScannerReady returned false without a stop")
+	}
+}
+
+func TestAwaitScannerReadySkipsWaitWithoutScanner(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	bm.SetScannerReadiness(time.Hour, false)
+	bm.stopChan = make(chan bool)
+	logs := captureLog(t)
+
+	if !bm.awaitScannerReady() {
+		t.Fatal("awaitScannerReady returned false without a stop")
+	}
+	if !strings.Contains(logs.String(), "No scanner configured") {
+		t.Fatalf("no skip logged: %s", logs)
+	}
+}
@@ -0,0 +1,55 @@
+package bitcoin
+
+import (
+	"encoding/base64"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const defaultSummaryEmbedMaxBytes = 1 << 20
+
+// summaryEmbedLimitFromEnv returns the largest image whose content is embedded
+// as base64 in inscriptions.json, or 0 to keep the lean summary (the default).
+// Embedding is enabled by STARGATE_SUMMARY_EMBED_IMAGES=true; the per-image cap
+// is STARGATE_SUMMARY_EMBED_MAX_BYTES (default 1 MiB).
+func summaryEmbedLimitFromEnv() int {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("STARGATE_SUMMARY_EMBED_IMAGES")))
+	if err != nil || !enabled {
+		return 0
+	}
+	raw := strings.TrimSpace(os.Getenv("STARGATE_SUMMARY_EMBED_MAX_BYTES"))
+	if raw == "" {
+		return defaultSummaryEmbedMaxBytes
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		log.Printf("Ignoring invalid STARGATE_SUMMARY_EMBED_MAX_BYTES=%q, using %d", raw, defaultSummaryEmbedMaxBytes)
+		return defaultSummaryEmbedMaxBytes
+	}
+	return limit
+}
+
+// SetSummaryImageEmbedding embeds image content up to maxBytes per image as
+// base64 in inscriptions.json, making a block summary portable on its own.
+// A maxBytes of 0 restores the lean summary that relies on the images/ files.
+func (bm *BlockMonitor) SetSummaryImageEmbedding(maxBytes int) {
+	if maxBytes < 0 {
+		maxBytes = 0
+	}
+	bm.embedMaxBytes = maxBytes
+}
+
+// embedImageContent adds data_base64 to a summary image entry when embedding is
+// enabled; images over the cap are marked with embed_skipped instead.
+func (bm *BlockMonitor) embedImageContent(entry map[string]any, image ExtractedImageData) {
+	if bm.embedMaxBytes <= 0 || len(image.Data) == 0 {
+		return
+	}
+	if len(image.Data) > bm.embedMaxBytes {
+		entry["embed_skipped"] = "too_large"
+		return
+	}
+	entry["data_base64"] = base64.StdEncoding.EncodeToString(image.Data)
+}
@@ -0,0 +1,84 @@
+package bitcoin
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+func summaryImages(t *testing.T, bm *BlockMonitor, height int64) []map[string]any {
+	t.Helper()
+	dir, err := bm.findBlockDirectory(height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "inscriptions.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary struct {
+		Images []map[string]any `json:"images"`
+	}
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		t.Fatal(err)
+	}
+	return summary.Images
+}
+
+func TestSummaryEmbedsImagesOnlyWhenEnabled(t *testing.T) {
+	source := &imageRawSource{fakeRawSource: newFakeRawSource(), n: 2}
+	source.addChain(t, 960, 961, chainhash.Hash{}, 0)
+	bm := newTestBlockMonitor(t, source)
+
+	if err := bm.ProcessBlock(960); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	for _, img := range summaryImages(t, bm, 960) {
+		if _, ok := img["data_base64"]; ok {
+			t.Fatalf("default summary embeds content: %v", img)
+		}
+	}
+
+	bm.SetSummaryImageEmbedding(1024)
+	if err := bm.ProcessBlock(961); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+	images := summaryImages(t, bm, 961)
+	if len(images) != 2 {
+		t.Fatalf("got %d images, want 2", len(images))
+	}
+	for i, img := range images {
+		want := base64.StdEncoding.EncodeToString([]byte{byte(i)})
+		if img["data_base64"] != want {
+			t.Fatalf("image %d data_base64 = %v, want %q", i, img["data_base64"], want)
+		}
+	}
+}
+
+func TestEmbedImageContentRespectsCap(t *testing.T) {
+	bm := &BlockMonitor{embedMaxBytes: 4}
+	entry := map[string]any{}
+	bm.embedImageContent(entry, ExtractedImageData{Data: []byte("too big")})
+	if _, ok := entry["data_base64"]; ok || entry["embed_skipped"] != "too_large" {
+		t.Fatalf("oversized image entry = %v", entry)
+	}
+}
+
+func TestSummaryEmbedLimitFromEnv(t *testing.T) {
+	t.Setenv("STARGATE_SUMMARY_EMBED_IMAGES", "")
+	if got := summaryEmbedLimitFromEnv(); got != 0 {
+		t.Fatalf("disabled limit = %d", got)
+	}
+	t.Setenv("STARGATE_SUMMARY_EMBED_IMAGES", "true")
+	if got := summaryEmbedLimitFromEnv(); got != defaultSummaryEmbedMaxBytes {
+		t.Fatalf("default limit = %d", got)
+	}
+	t.Setenv("STARGATE_SUMMARY_EMBED_MAX_BYTES", "2048")
+	if got := summaryEmbedLimitFromEnv(); got != 2048 {
+		t.Fatalf("configured limit = %d", got)
+	}
+}
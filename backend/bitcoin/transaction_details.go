@@ -0,0 +1,115 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+)
+
+// maxTransactionDetailIO caps how many inputs and outputs of a single
+// transaction are expanded into block.json; InputCount/OutputCount still carry
+// the full totals and DetailsTruncated marks the cut.
+const maxTransactionDetailIO = 500
+
+const coinbasePrevTxID = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// TxInputDetail describes a transaction input in block.json.
+type TxInputDetail struct {
+	Index        int    `json:"index"`
+	PrevTxID     string `json:"prev_txid"`
+	PrevVOut     uint32 `json:"prev_vout"`
+	Coinbase     bool   `json:"coinbase,omitempty"`
+	ScriptSig    string `json:"scriptsig,omitempty"`
+	Sequence     uint32 `json:"sequence"`
+	WitnessItems int    `json:"witness_items"`
+	WitnessSize  int    `json:"witness_size"`
+}
+
+// TxOutputDetail describes a transaction output in block.json.
+type TxOutputDetail struct {
+	Index        int      `json:"index"`
+	Value        int64    `json:"value"`
+	ScriptPubKey string   `json:"scriptpubkey"`
+	ScriptType   string   `json:"scriptpubkey_type"`
+	Addresses    []string `json:"addresses,omitempty"`
+}
+
+// fillTransactionDetails expands the inputs, outputs and OP_RETURN payloads of
+// tx into data. Only the first maxTransactionDetailIO inputs and outputs are
+// expanded.
+func fillTransactionDetails(data *TransactionData, tx Transaction, params *chaincfg.Params) {
+	data.InputCount = len(tx.Inputs)
+	data.OutputCount = len(tx.Outputs)
+
+	for i, input := range tx.Inputs {
+		var stack [][]byte
+		if i < len(tx.InputWitnesses) {
+			stack = tx.InputWitnesses[i]
+		}
+		witnessSize := 0
+		for _, item := range stack {
+			witnessSize += len(item)
+		}
+		data.WitnessSize += witnessSize
+
+		if i >= maxTransactionDetailIO {
+			data.DetailsTruncated = true
+			continue
+		}
+		scriptSig := hex.EncodeToString(input.ScriptSig)
+		data.Inputs = append(data.Inputs, TxInputDetail{
+			Index:        i,
+			PrevTxID:     input.PreviousTxID,
+			PrevVOut:     input.PreviousIndex,
+			Coinbase:     input.PreviousTxID == coinbasePrevTxID && input.PreviousIndex == 0xffffffff,
+			ScriptSig:    scriptSig,
+			Sequence:     input.Sequence,
+			WitnessItems: len(stack),
+			WitnessSize:  witnessSize,
+		})
+		data.VIn = append(data.VIn, Vin{
+			TxID:      input.PreviousTxID,
+			VOut:      int(input.PreviousIndex),
+			ScriptSig: scriptSig,
+			Sequence:  int64(input.Sequence),
+		})
+	}
+
+	for i, output := range tx.Outputs {
+		for _, payload := range extractOpReturnPayloads(output.ScriptPubKey) {
+			if len(payload) == 0 {
+				continue
+			}
+			if isWitnessText(payload) {
+				data.TextContent = append(data.TextContent, string(payload))
+			} else {
+				data.HexData = append(data.HexData, hex.EncodeToString(payload))
+			}
+		}
+
+		if i >= maxTransactionDetailIO {
+			data.DetailsTruncated = true
+			continue
+		}
+		script := hex.EncodeToString(output.ScriptPubKey)
+		data.Outputs = append(data.Outputs, TxOutputDetail{
+			Index:        i,
+			Value:        output.Value,
+			ScriptPubKey: script,
+			ScriptType:   scriptTypeName(output.ScriptPubKey),
+			Addresses:    outputAddresses(output.ScriptPubKey, params),
+		})
+		data.VOut = append(data.VOut, VOut{
+			Value:        int(output.Value),
+			ScriptPubKey: script,
+		})
+	}
+}
+
+// scriptTypeName returns the txscript class of a scriptPubKey, e.g.
+// "witness_v0_keyhash" or "nulldata".
+func scriptTypeName(script []byte) string {
+	return strings.ToLower(txscript.GetScriptClass(script).String())
+}
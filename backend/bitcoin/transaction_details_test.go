@@ -0,0 +1,107 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestConvertTransactionsPopulatesInputsAndOutputs(t *testing.T) {
+	bm := newTestBlockMonitor(t, newFakeRawSource())
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(bytes.Repeat([]byte{0x11}, 20), bm.networkParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2wpkh, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opReturn, err := txscript.NullDataScript([]byte("stargate note"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: 0xffffffff}, SignatureScript: []byte{0x01, 0x09}, Sequence: 0xffffffff})
+	coinbase.AddTxOut(&wire.TxOut{Value: 50_0000_0000, PkScript: p2wpkh})
+
+	spend := wire.NewMsgTx(2)
+	spend.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: coinbase.TxHash(), Index: 0},
+		Sequence:         0xfffffffd,
+		Witness:          wire.TxWitness{bytes.Repeat([]byte{0x30}, 71), bytes.Repeat([]byte{0x02}, 33)},
+	})
+	spend.AddTxOut(&wire.TxOut{Value: 49_9999_0000, PkScript: p2wpkh})
+	spend.AddTxOut(&wire.TxOut{Value: 0, PkScript: opReturn})
+
+	block := wire.NewMsgBlock(&wire.BlockHeader{Version: 1, Timestamp: time.Unix(1700000000, 0), Bits: 0x1d00ffff})
+	for _, tx := range []*wire.MsgTx{coinbase, spend} {
+		if err := block.AddTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := block.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := (&RawBlockClient{}).ParseBlock(hex.EncodeToString(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txs := bm.convertTransactions(parsed.Transactions)
+	if len(txs) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(txs))
+	}
+
+	cb := txs[0]
+	if cb.InputCount != 1 || len(cb.Inputs) != 1 || !cb.Inputs[0].Coinbase {
+		t.Fatalf("coinbase inputs = %+v", cb.Inputs)
+	}
+	if len(cb.Outputs) != 1 || cb.Outputs[0].Value != 50_0000_0000 || cb.Outputs[0].ScriptType != "witness_v0_keyhash" {
+		t.Fatalf("coinbase outputs = %+v", cb.Outputs)
+	}
+	if len(cb.Outputs[0].Addresses) != 1 || cb.Outputs[0].Addresses[0] != addr.EncodeAddress() {
+		t.Fatalf("coinbase output addresses = %v", cb.Outputs[0].Addresses)
+	}
+
+	tx := txs[1]
+	in := tx.Inputs
+	if len(in) != 1 || in[0].Coinbase || in[0].PrevTxID != coinbase.TxHash().String() || in[0].Sequence != 0xfffffffd {
+		t.Fatalf("spend inputs = %+v", in)
+	}
+	if in[0].WitnessItems != 2 || in[0].WitnessSize != 104 || tx.WitnessSize != 104 {
+		t.Fatalf("witness accounting = %+v (tx %d)", in[0], tx.WitnessSize)
+	}
+	if len(tx.VIn) != 1 || len(tx.VOut) != 2 {
+		t.Fatalf("vin/vout = %d/%d", len(tx.VIn), len(tx.VOut))
+	}
+	if tx.OutputCount != 2 || tx.Outputs[1].ScriptType != "nulldata" || len(tx.Outputs[1].Addresses) != 0 {
+		t.Fatalf("spend outputs = %+v", tx.Outputs)
+	}
+	if len(tx.TextContent) != 1 || tx.TextContent[0] != "stargate note" {
+		t.Fatalf("text content = %q", tx.TextContent)
+	}
+	if tx.DetailsTruncated {
+		t.Fatal("small transaction marked truncated")
+	}
+}
+
+func TestFillTransactionDetailsCapsLargeTransactions(t *testing.T) {
+	tx := Transaction{TxID: "big"}
+	for i := 0; i < maxTransactionDetailIO+25; i++ {
+		tx.Outputs = append(tx.Outputs, TxOutput{Value: int64(i), ScriptPubKey: []byte{0x51}})
+	}
+
+	var data TransactionData
+	fillTransactionDetails(&data, tx, &chaincfg.TestNet4Params)
+	if data.OutputCount != maxTransactionDetailIO+25 || len(data.Outputs) != maxTransactionDetailIO || !data.DetailsTruncated {
+		t.Fatalf("count=%d expanded=%d truncated=%v", data.OutputCount, len(data.Outputs), data.DetailsTruncated)
+	}
+}
@@ -0,0 +1,42 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"testing"
+
+	"stargate-backend/stego"
+)
+
+// TestIngestionAndReconciliationHashesAgree checks that the hash an ingestion
+// record gets for an uploaded image is the one block reconciliation computes
+// for the same image found on chain behind an inscription envelope.
+func TestIngestionAndReconciliationHashesAgree(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i * 7)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	pngBytes := buf.Bytes()
+
+	for name, raw := range map[string][]byte{
+		"clean png":    pngBytes,
+		"envelope png": append([]byte("ord\x01\x09image/png\x00"), pngBytes...),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ingestHash, err := stego.VisiblePixelHashBase64(base64.StdEncoding.EncodeToString(raw))
+			if err != nil {
+				t.Fatalf("ingestion hash: %v", err)
+			}
+			cleaned := sanitizeExtractedImage(ExtractedImageData{Data: raw, ContentType: "image/png"})
+			if reconcileHash := visiblePixelHash(cleaned.Data, "wish"); reconcileHash != ingestHash {
+				t.Fatalf("reconciliation hash %s != ingestion hash %s", reconcileHash, ingestHash)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// minWitnessTextLen keeps short printable byte runs (e.g. a 1-byte version
+	// push) from being reported as text.
+	minWitnessTextLen = 4
+	// maxWitnessItemBytes caps how much of one stack item or decoded payload
+	// is kept in block.json.
+	maxWitnessItemBytes = 520
+	// maxWitnessInputBytes caps what is kept across all of one input's items
+	// and payloads, so a large inscription cannot bloat block.json.
+	maxWitnessInputBytes = 4096
+)
+
+// extractWitnessData builds the per-input witness breakdown stored in block.json:
+// every stack item as hex, its total size, and what the items decode to.
+// Ordinals envelopes and raw image bytes count as images, printable UTF-8 is
+// reported as text and non-text envelope payloads are kept as hex. Kept bytes
+// are capped per item and per input; entries that lost bytes are marked
+// truncated, while total_size always reports the full witness size.
+func extractWitnessData(transactions []Transaction) []WitnessData {
+	var out []WitnessData
+	for _, tx := range transactions {
+		for inIdx, stack := range tx.InputWitnesses {
+			if len(stack) == 0 {
+				continue
+			}
+			entry := WitnessData{
+				TxID:        tx.TxID,
+				InputIndex:  inIdx,
+				WitnessData: make([]string, 0, len(stack)),
+			}
+			budget := maxWitnessInputBytes
+			for _, item := range stack {
+				entry.WitnessData = append(entry.WitnessData, hex.EncodeToString(entry.keep(item, &budget)))
+				entry.TotalSize += len(item)
+				entry.classifyWitnessItem(item, &budget)
+			}
+			entry.HasImages = entry.ImageCount > 0
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// keep returns the prefix of data that fits both the per-item cap and the
+// input's remaining budget, marking the entry truncated when it cuts.
+func (w *WitnessData) keep(data []byte, budget *int) []byte {
+	n := min(len(data), maxWitnessItemBytes, *budget)
+	if n < len(data) {
+		w.Truncated = true
+	}
+	*budget -= n
+	return data[:n]
+}
+
+// classifyWitnessItem records what item decodes to. Opaque items are not
+// copied to hex_data since witness_data already holds their hex.
+func (w *WitnessData) classifyWitnessItem(item []byte, budget *int) {
+	if payloads := extractOrdinalPayloads(item); len(payloads) > 0 {
+		for _, payload := range payloads {
+			switch {
+			case strings.HasPrefix(payload.contentType, "image/"):
+				w.ImageCount++
+			case isWitnessText(payload.payload):
+				w.TextContent = append(w.TextContent, strings.ToValidUTF8(string(w.keep(payload.payload, budget)), ""))
+			default:
+				w.HexData = append(w.HexData, hex.EncodeToString(w.keep(payload.payload, budget)))
+			}
+		}
+		return
+	}
+	if imageType, _ := detectImage(item); imageType != "" {
+		w.ImageCount++
+		return
+	}
+	if isWitnessText(item) {
+		w.TextContent = append(w.TextContent, strings.ToValidUTF8(string(w.keep(item, budget)), ""))
+	}
+}
+
+// isWitnessText reports whether data is printable UTF-8 text.
+func isWitnessText(data []byte) bool {
+	if len(data) < minWitnessTextLen || !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+func TestProcessBlockWritesWitnessData(t *testing.T) {
+	signature := append([]byte{0x30, 0x44, 0x02, 0x20}, bytes.Repeat([]byte{0x01}, 66)...)
+	ordinal := buildOrdinalScript("text/plain;charset=utf-8", []byte("hello witness"))
+	png := append([]byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}, bytes.Repeat([]byte{0x00}, 8)...)
+
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: 0xffffffff}, SignatureScript: []byte{0x01, 0x07}})
+	coinbase.AddTxOut(&wire.TxOut{Value: 50_0000_0000, PkScript: []byte{0x51}})
+
+	spend := wire.NewMsgTx(2)
+	spend.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: coinbase.TxHash()},
+		Witness:          wire.TxWitness{signature, ordinal, png},
+	})
+	spend.AddTxOut(&wire.TxOut{Value: 1000, PkScript: []byte{0x51}})
+
+	block := wire.NewMsgBlock(&wire.BlockHeader{
+		Version:   1,
+		Timestamp: time.Unix(1700000000, 0),
+		Bits:      0x1d00ffff,
+	})
+	for _, tx := range []*wire.MsgTx{coinbase, spend} {
+		if err := block.AddTransaction(tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := block.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	source := newFakeRawSource()
+	source.blocks[700] = hex.EncodeToString(buf.Bytes())
+	bm := newTestBlockMonitor(t, source)
+	if err := bm.ProcessBlock(700); err != nil {
+		t.Fatalf("ProcessBlock: %v", err)
+	}
+
+	dir, err := bm.findBlockDirectory(700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "block.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored BlockData
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored.WitnessData) != 1 {
+		t.Fatalf("got %d witness entries, want 1: %+v", len(stored.WitnessData), stored.WitnessData)
+	}
+	w := stored.WitnessData[0]
+	if w.TxID != spend.TxHash().String() || w.InputIndex != 0 {
+		t.Fatalf("entry identifies %s input %d", w.TxID, w.InputIndex)
+	}
+	if len(w.WitnessData) != 3 || w.WitnessData[1] != hex.EncodeToString(ordinal) {
+		t.Fatalf("witness stack = %v", w.WitnessData)
+	}
+	if w.TotalSize != len(signature)+len(ordinal)+len(png) {
+		t.Fatalf("total size = %d", w.TotalSize)
+	}
+	if !w.HasImages || w.ImageCount != 1 {
+		t.Fatalf("images: has=%v count=%d", w.HasImages, w.ImageCount)
+	}
+	if len(w.TextContent) != 1 || w.TextContent[0] != "hello witness" {
+		t.Fatalf("text content = %q", w.TextContent)
+	}
+	// The signature's hex is already in witness_data; it is not stored twice.
+	if len(w.HexData) != 0 {
+		t.Fatalf("hex data = %v", w.HexData)
+	}
+	if w.Truncated {
+		t.Fatal("small witness marked truncated")
+	}
+}
+
+func TestExtractWitnessDataCapsStoredBytes(t *testing.T) {
+	big := bytes.Repeat([]byte{0xAB}, 3*maxWitnessItemBytes)
+	stack := [][]byte{big}
+	for i := 0; i < 2*maxWitnessInputBytes/maxWitnessItemBytes; i++ {
+		stack = append(stack, bytes.Repeat([]byte{0xCD}, maxWitnessItemBytes))
+	}
+	out := extractWitnessData([]Transaction{{TxID: "big", InputWitnesses: [][][]byte{stack}}})
+	if len(out) != 1 {
+		t.Fatalf("got %d entries", len(out))
+	}
+	w := out[0]
+	if !w.Truncated {
+		t.Fatal("oversized witness not marked truncated")
+	}
+	if len(w.WitnessData) != len(stack) {
+		t.Fatalf("witness_data has %d items, want %d", len(w.WitnessData), len(stack))
+	}
+	if got := len(w.WitnessData[0]); got != 2*maxWitnessItemBytes {
+		t.Fatalf("first item kept %d hex chars, want %d", got, 2*maxWitnessItemBytes)
+	}
+	kept := 0
+	for _, item := range w.WitnessData {
+		kept += len(item) / 2
+	}
+	if kept != maxWitnessInputBytes {
+		t.Fatalf("kept %d bytes across the input, want %d", kept, maxWitnessInputBytes)
+	}
+	want := len(big) + (len(stack)-1)*maxWitnessItemBytes
+	if w.TotalSize != want {
+		t.Fatalf("total size = %d, want the full %d", w.TotalSize, want)
+	}
+}
@@ -0,0 +1,139 @@
+// Command blocktool validates and pretty-prints a block directory written by
+// the block monitor (blocks/<height>_<hash>/).
+//
+// It checks inscriptions.json against the schema the monitor writes, cross
+// checks block.json and the directory name, lists the extracted images with
+// their on-disk sizes and SHA-256 hashes, and prints the stego scan summary.
+//
+// Usage:
+//
+//	go run ./backend/cmd/blocktool --dir data/blocks/830001_00000000
+//	go run ./backend/cmd/blocktool --blocks-dir data/blocks --height 830001
+//	go run ./backend/cmd/blocktool --height 830001 --json
+//	go run ./backend/cmd/blocktool --blocks-dir data/blocks --migrate-contract-ids
+//
+// --migrate-contract-ids rewrites legacy stego_{index}_{unixtime} contract IDs
+// in every block directory to the deterministic scheme and exits.
+//
+// Exit status is 1 when the directory fails validation.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"stargate-backend/bitcoin"
+)
+
+// errInvalid signals that the directory was inspected but failed validation.
+var errInvalid = errors.New("block directory failed validation")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if !errors.Is(err, errInvalid) {
+			fmt.Fprintf(os.Stderr, "blocktool: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("blocktool", flag.ContinueOnError)
+	fs.SetOutput(out)
+	dir := fs.String("dir", "", "Block directory to inspect (overrides --blocks-dir/--height)")
+	blocksDir := fs.String("blocks-dir", defaultBlocksDir(), "Root blocks directory (defaults to BLOCKS_DIR)")
+	height := fs.Int64("height", -1, "Block height to locate under --blocks-dir")
+	asJSON := fs.Bool("json", false, "Print the report as JSON")
+	migrate := fs.Bool("migrate-contract-ids", false, "Rewrite legacy timestamp-based contract IDs under --blocks-dir and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *migrate {
+		n, err := bitcoin.MigrateContractIDs(*blocksDir)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Migrated %d contract ID(s) under %s\n", n, *blocksDir)
+		return nil
+	}
+
+	target := *dir
+	if target == "" {
+		if *height < 0 {
+			return fmt.Errorf("either --dir or --height is required")
+		}
+		found, err := bitcoin.FindBlockDirectory(*blocksDir, *height)
+		if err != nil {
+			return err
+		}
+		target = found
+	}
+
+	report, err := bitcoin.InspectBlockDirectory(target)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printReport(out, report)
+	}
+
+	if !report.Valid() {
+		return errInvalid
+	}
+	return nil
+}
+
+func defaultBlocksDir() string {
+	if v := os.Getenv("BLOCKS_DIR"); v != "" {
+		return v
+	}
+	return "blocks"
+}
+
+func printReport(out io.Writer, r *bitcoin.BlockDirReport) {
+	fmt.Fprintf(out, "Block directory: %s\n", r.Dir)
+	fmt.Fprintf(out, "  height:        %d\n", r.Height)
+	fmt.Fprintf(out, "  hash:          %s\n", r.Hash)
+	fmt.Fprintf(out, "  timestamp:     %d\n", r.Timestamp)
+	fmt.Fprintf(out, "  transactions:  %d\n", r.TotalTransactions)
+	fmt.Fprintf(out, "  inscriptions:  %d\n", r.InscriptionCount)
+	fmt.Fprintf(out, "  contracts:     %d\n", r.SmartContracts)
+
+	fmt.Fprintf(out, "\nImages (%d):\n", len(r.Images))
+	if len(r.Images) > 0 {
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "  FILE\tFORMAT\tBYTES\tSHA256\tSTEGO\tCONFIDENCE")
+		for _, img := range r.Images {
+			hash := img.SHA256
+			if !img.OnDisk {
+				hash = "(missing on disk)"
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%d\t%s\t%v\t%.2f\n", img.FileName, img.Format, img.SizeBytes, hash, img.IsStego, img.Confidence)
+		}
+		tw.Flush()
+	}
+
+	fmt.Fprintf(out, "\nStego scan: %d scanned, %d stego detected, %d scan errors\n", len(r.Images), r.StegoCount, r.ScanErrors)
+
+	if r.Valid() {
+		fmt.Fprintln(out, "\nValidation: OK")
+		return
+	}
+	fmt.Fprintf(out, "\nValidation: %d problem(s)\n", len(r.Problems))
+	for _, p := range r.Problems {
+		fmt.Fprintf(out, "  - %s\n", p)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testBlockHash = "00000000000000000002a7c4c1e48d76c5a37902165a270156b7a8d72728a054"
+
+func seedBlockDir(t *testing.T, stegoCount int) string {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "830001_"+testBlockHash[:8])
+	if err := os.MkdirAll(filepath.Join(dir, "images"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	image := []byte("\x89PNG\r\n\x1a\nfake-image")
+	if err := os.WriteFile(filepath.Join(dir, "images", "tx1_0.png"), image, 0644); err != nil {
+		t.Fatal(err)
+	}
+	summary := map[string]any{
+		"block_height":       830001,
+		"block_hash":         testBlockHash,
+		"timestamp":          1700000000,
+		"total_transactions": 2,
+		"inscriptions":       []any{map[string]any{"tx_id": "tx1", "file_name": "tx1_0.png"}},
+		"images": []any{map[string]any{
+			"tx_id":       "tx1",
+			"format":      "png",
+			"size_bytes":  len(image),
+			"file_name":   "tx1_0.png",
+			"scan_result": map[string]any{"is_stego": true, "confidence": 0.91},
+		}},
+		"smart_contracts":    []any{},
+		"processing_time_ms": 12,
+		"success":            true,
+		"steganography_scan": map[string]any{"stego_count": stegoCount},
+	}
+	data, _ := json.Marshal(summary)
+	if err := os.WriteFile(filepath.Join(dir, "inscriptions.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	header, _ := json.Marshal(map[string]any{"block_header": map[string]any{"Hash": testBlockHash}})
+	if err := os.WriteFile(filepath.Join(dir, "block.json"), header, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestRunPrintsValidBlockDirectory(t *testing.T) {
+	root := seedBlockDir(t, 1)
+
+	var out bytes.Buffer
+	if err := run([]string{"--blocks-dir", root, "--height", "830001"}, &out); err != nil {
+		t.Fatalf("run: %v\n%s", err, out.String())
+	}
+	got := out.String()
+	for _, want := range []string{
+		"height:        830001",
+		"tx1_0.png",
+		"1 stego detected",
+		"Validation: OK",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunReportsSchemaProblems(t *testing.T) {
+	root := seedBlockDir(t, 3)
+
+	var out bytes.Buffer
+	err := run([]string{"--blocks-dir", root, "--height", "830001", "--json"}, &out)
+	if !errors.Is(err, errInvalid) {
+		t.Fatalf("run error = %v, want errInvalid", err)
+	}
+	var report struct {
+		Problems []string `json:"problems"`
+		Images   []struct {
+			SHA256 string `json:"sha256"`
+		} `json:"images"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("decode json report: %v", err)
+	}
+	if len(report.Problems) != 1 || !strings.Contains(report.Problems[0], "stego_count") {
+		t.Fatalf("problems = %v, want stego_count mismatch", report.Problems)
+	}
+	if len(report.Images) != 1 || len(report.Images[0].SHA256) != 64 {
+		t.Fatalf("images = %+v, want one hashed image", report.Images)
+	}
+}
@@ -0,0 +1,208 @@
+// Package coerce converts loosely typed values from decoded JSON, tool
+// arguments and metadata maps into concrete Go types.
+//
+// encoding/json decodes every number as float64 (or json.Number with
+// UseNumber), clients often send numbers as strings, and stores hand back
+// int64 or []byte. Each helper accepts all of these uniformly. The numeric
+// and bool helpers report ok=false for nil, empty or unparseable input so
+// callers can tell a missing value from a zero one.
+package coerce
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// String renders v as a string. nil becomes "", numbers are formatted without
+// exponents, and anything else falls back to fmt's %v.
+func String(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	case json.Number:
+		return t.String()
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	case fmt.Stringer:
+		return t.String()
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
+
+// Int64 converts v to an int64. Floats and decimal strings are truncated
+// toward zero; values outside the int64 range, NaN and infinities are rejected.
+func Int64(v interface{}) (int64, bool) {
+	switch t := v.(type) {
+	case int:
+		return int64(t), true
+	case int8:
+		return int64(t), true
+	case int16:
+		return int64(t), true
+	case int32:
+		return int64(t), true
+	case int64:
+		return t, true
+	case uint:
+		return uintToInt64(uint64(t))
+	case uint8:
+		return int64(t), true
+	case uint16:
+		return int64(t), true
+	case uint32:
+		return int64(t), true
+	case uint64:
+		return uintToInt64(t)
+	case float32:
+		return floatToInt64(float64(t))
+	case float64:
+		return floatToInt64(t)
+	case json.Number:
+		return parseInt64(string(t))
+	case string:
+		return parseInt64(t)
+	case []byte:
+		return parseInt64(string(t))
+	default:
+		return 0, false
+	}
+}
+
+// Float64 converts v to a float64.
+func Float64(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case float32:
+		return float64(t), true
+	case json.Number:
+		return parseFloat64(string(t))
+	case string:
+		return parseFloat64(t)
+	case []byte:
+		return parseFloat64(string(t))
+	}
+	if i, ok := Int64(v); ok {
+		return float64(i), true
+	}
+	if u, ok := v.(uint64); ok {
+		return float64(u), true
+	}
+	return 0, false
+}
+
+// Bool converts v to a bool. Strings accept strconv.ParseBool spellings plus
+// yes/no and on/off; numbers are true when non-zero.
+func Bool(v interface{}) (bool, bool) {
+	switch t := v.(type) {
+	case bool:
+		return t, true
+	case string:
+		return parseBool(t)
+	case []byte:
+		return parseBool(string(t))
+	}
+	if f, ok := Float64(v); ok {
+		return f != 0, true
+	}
+	return false, false
+}
+
+// Map returns v as a JSON object. A JSON-encoded object in a string or []byte
+// is decoded; a nil map is reported as missing.
+func Map(v interface{}) (map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return t, t != nil
+	case map[string]string:
+		if t == nil {
+			return nil, false
+		}
+		out := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			out[k] = val
+		}
+		return out, true
+	case string:
+		return decodeMap([]byte(t))
+	case []byte:
+		return decodeMap(t)
+	default:
+		return nil, false
+	}
+}
+
+func parseInt64(s string) (int64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, true
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return floatToInt64(f)
+}
+
+func parseFloat64(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+func parseBool(s string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "yes", "y", "on":
+		return true, true
+	case "no", "n", "off":
+		return false, true
+	}
+	if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+		return b, true
+	}
+	if f, ok := parseFloat64(s); ok {
+		return f != 0, true
+	}
+	return false, false
+}
+
+func floatToInt64(f float64) (int64, bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) || f >= math.MaxInt64 || f < math.MinInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func uintToInt64(u uint64) (int64, bool) {
+	if u > math.MaxInt64 {
+		return 0, false
+	}
+	return int64(u), true
+}
+
+func decodeMap(b []byte) (map[string]interface{}, bool) {
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil || out == nil {
+		return nil, false
+	}
+	return out, true
+}
@@ -0,0 +1,168 @@
+package coerce
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type stringer struct{}
+
+func (stringer) String() string { return "stringer" }
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{nil, ""},
+		{"abc", "abc"},
+		{[]byte("bytes"), "bytes"},
+		{json.Number("12.50"), "12.50"},
+		{float64(1000000), "1000000"},
+		{float64(1.5), "1.5"},
+		{float32(2.25), "2.25"},
+		{int64(-7), "-7"},
+		{42, "42"},
+		{true, "true"},
+		{stringer{}, "stringer"},
+	}
+	for _, tc := range tests {
+		if got := String(tc.in); got != tc.want {
+			t.Errorf("String(%#v) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestInt64(t *testing.T) {
+	tests := []struct {
+		in     interface{}
+		want   int64
+		wantOK bool
+	}{
+		{nil, 0, false},
+		{7, 7, true},
+		{int8(-8), -8, true},
+		{int16(16), 16, true},
+		{int32(32), 32, true},
+		{int64(1 << 40), 1 << 40, true},
+		{uint(9), 9, true},
+		{uint8(8), 8, true},
+		{uint16(16), 16, true},
+		{uint32(32), 32, true},
+		{uint64(64), 64, true},
+		{uint64(math.MaxUint64), 0, false},
+		{float32(3.9), 3, true},
+		{float64(1000), 1000, true},
+		{float64(-2.7), -2, true},
+		{math.NaN(), 0, false},
+		{math.Inf(1), 0, false},
+		{float64(1e20), 0, false},
+		{json.Number("123"), 123, true},
+		{json.Number("12.9"), 12, true},
+		{json.Number("x"), 0, false},
+		{" 456 ", 456, true},
+		{"7.5", 7, true},
+		{"", 0, false},
+		{"abc", 0, false},
+		{[]byte("99"), 99, true},
+		{true, 0, false},
+		{map[string]interface{}{}, 0, false},
+	}
+	for _, tc := range tests {
+		got, ok := Int64(tc.in)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("Int64(%#v) = %d, %v; want %d, %v", tc.in, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	tests := []struct {
+		in     interface{}
+		want   float64
+		wantOK bool
+	}{
+		{nil, 0, false},
+		{1.25, 1.25, true},
+		{float32(0.5), 0.5, true},
+		{3, 3, true},
+		{int64(-4), -4, true},
+		{uint64(math.MaxUint64), float64(math.MaxUint64), true},
+		{json.Number("2.5"), 2.5, true},
+		{json.Number("nope"), 0, false},
+		{" 0.001 ", 0.001, true},
+		{"NaN", 0, false},
+		{"", 0, false},
+		{[]byte("6"), 6, true},
+		{false, 0, false},
+		{[]int{1}, 0, false},
+	}
+	for _, tc := range tests {
+		got, ok := Float64(tc.in)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("Float64(%#v) = %v, %v; want %v, %v", tc.in, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestBool(t *testing.T) {
+	tests := []struct {
+		in     interface{}
+		want   bool
+		wantOK bool
+	}{
+		{nil, false, false},
+		{true, true, true},
+		{false, false, true},
+		{"true", true, true},
+		{"FALSE", false, true},
+		{"1", true, true},
+		{"0", false, true},
+		{" yes ", true, true},
+		{"off", false, true},
+		{"maybe", false, false},
+		{"", false, false},
+		{[]byte("t"), true, true},
+		{float64(0), false, true},
+		{float64(2), true, true},
+		{1, true, true},
+		{json.Number("0"), false, true},
+		{json.Number("1.5"), true, true},
+		{struct{}{}, false, false},
+	}
+	for _, tc := range tests {
+		got, ok := Bool(tc.in)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("Bool(%#v) = %v, %v; want %v, %v", tc.in, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	obj := map[string]interface{}{"a": float64(1)}
+	var nilMap map[string]interface{}
+	tests := []struct {
+		in     interface{}
+		want   map[string]interface{}
+		wantOK bool
+	}{
+		{nil, nil, false},
+		{obj, obj, true},
+		{nilMap, nil, false},
+		{map[string]string{"k": "v"}, map[string]interface{}{"k": "v"}, true},
+		{`{"a":1}`, obj, true},
+		{[]byte(`{"a":1}`), obj, true},
+		{`[1,2]`, nil, false},
+		{"null", nil, false},
+		{"not json", nil, false},
+		{42, nil, false},
+	}
+	for _, tc := range tests {
+		got, ok := Map(tc.in)
+		if ok != tc.wantOK || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Map(%#v) = %#v, %v; want %#v, %v", tc.in, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
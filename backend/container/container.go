@@ -1,6 +1,7 @@
 package container
 
 import (
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -29,6 +30,10 @@ type Container struct {
 	// Caches
 	ContractCache *smart_contract.ContractCache
 
+	// recordStore is the SQLite record store behind the inscription and
+	// contract services, or nil when they use the JSON files.
+	recordStore io.Closer
+
 	// Handlers
 	HealthHandler        *handlers.HealthHandler
 	DiscoveryHandler     *handlers.DiscoveryHandler
@@ -76,13 +81,15 @@ func NewContainer(apiKeyIssuer auth.APIKeyIssuer, apiKeyValidator auth.APIKeyVal
 	if err := os.MkdirAll(filepath.Dir(inscriptionsFile), 0755); err != nil {
 		log.Printf("failed to ensure data dir: %v", err)
 	}
-	inscriptionService := services.NewInscriptionService(inscriptionsFile)
-	blockService := services.NewBlockService()
 	contractsFile := os.Getenv("SMART_CONTRACTS_FILE")
 	if contractsFile == "" {
 		contractsFile = storage.DefaultPath("smart_contracts.json")
 	}
-	contractService := services.NewSmartContractService(contractsFile)
+	inscriptionStore, contractStore := initRecordStores(inscriptionsFile, contractsFile)
+	recordStore, _ := inscriptionStore.(io.Closer)
+	inscriptionService := services.NewInscriptionServiceWithStore(inscriptionStore)
+	blockService := services.NewBlockService()
+	contractService := services.NewSmartContractServiceWithStore(contractStore)
 	qrService := services.NewQRCodeService()
 	healthService := services.NewHealthService()
 	peerService := services.NewPeerService()
@@ -143,6 +150,8 @@ func NewContainer(apiKeyIssuer auth.APIKeyIssuer, apiKeyValidator auth.APIKeyVal
 		// Caches
 		ContractCache: contractCache,
 
+		recordStore: recordStore,
+
 		// Handlers
 		HealthHandler:      healthHandler,
 		DiscoveryHandler:   discoveryHandler,
@@ -165,6 +174,46 @@ func (c *Container) SetSmartContractHandler(store scmiddleware.Store) {
 	}
 }
 
+// Close releases the record store database, if one is open. Flush the
+// inscription and contract services first.
+func (c *Container) Close() error {
+	if c.recordStore == nil {
+		return nil
+	}
+	return c.recordStore.Close()
+}
+
+// initRecordStores picks the persistence for inscriptions and smart
+// contracts from STARGATE_RECORD_STORE: "file" (default) keeps the JSON
+// files, "sqlite" uses STARGATE_RECORDS_DB. A SQLite failure falls back to
+// the files so the server still starts.
+func initRecordStores(inscriptionsFile, contractsFile string) (services.InscriptionStore, services.ContractStore) {
+	switch kind := os.Getenv("STARGATE_RECORD_STORE"); kind {
+	case "", "file":
+	case "sqlite":
+		dbPath := os.Getenv("STARGATE_RECORDS_DB")
+		if dbPath == "" {
+			dataDir := os.Getenv("STARGATE_DATA_DIR")
+			if dataDir == "" {
+				dataDir = "data"
+			}
+			dbPath = filepath.Join(dataDir, "sqlite", "records.db")
+		}
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+			log.Printf("failed to ensure records db dir: %v", err)
+		}
+		store, err := services.NewSQLiteRecordStore(dbPath)
+		if err == nil {
+			log.Printf("Using SQLite record store at %s", dbPath)
+			return store, store
+		}
+		log.Printf("Failed to init SQLite record store, falling back to files: %v", err)
+	default:
+		log.Printf("Ignoring invalid STARGATE_RECORD_STORE=%q, using file", kind)
+	}
+	return services.NewFileInscriptionStore(inscriptionsFile), services.NewFileContractStore(contractsFile)
+}
+
 // initIngestionService retries connecting to Postgres a few times to avoid startup races.
 func initIngestionService(pgDSN string) *services.IngestionService {
 	const maxAttempts = 5
@@ -0,0 +1,91 @@
+package smart_contract
+
+import "time"
+
+// Commitment confirmation states reported by TaskCommitmentStatus.
+const (
+	CommitmentStatusNone        = "none"
+	CommitmentStatusProvisional = "provisional"
+	CommitmentStatusConfirmed   = "confirmed"
+)
+
+// TaskCommitment is the escrow/commitment view of a task: the commitment output
+// recorded when its funding PSBT was built, and whether the funding transaction
+// has confirmed on-chain (as tracked by the funding sync).
+type TaskCommitment struct {
+	TaskID                 string     `json:"task_id"`
+	ContractID             string     `json:"contract_id"`
+	HasCommitment          bool       `json:"has_commitment"`
+	CommitmentAddress      string     `json:"commitment_address,omitempty"`
+	CommitmentVout         uint32     `json:"commitment_vout"`
+	CommitmentSats         int64      `json:"commitment_sats"`
+	CommitmentRedeemScript string     `json:"commitment_redeem_script,omitempty"`
+	CommitmentRedeemHash   string     `json:"commitment_redeem_hash,omitempty"`
+	CommitmentPixelHash    string     `json:"commitment_pixel_hash,omitempty"`
+	CommitmentSource       string     `json:"commitment_source,omitempty"`
+	FundingTxID            string     `json:"funding_tx_id,omitempty"`
+	ConfirmationStatus     string     `json:"confirmation_status"`
+	Confirmed              bool       `json:"confirmed"`
+	BlockHeight            int64      `json:"block_height,omitempty"`
+	SeenAt                 *time.Time `json:"seen_at,omitempty"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+}
+
+// TaskCommitmentStatus extracts the commitment fields from a task's Merkle proof.
+// Tasks without a proof report ConfirmationStatus "none".
+func TaskCommitmentStatus(task Task) TaskCommitment {
+	out := TaskCommitment{
+		TaskID:             task.TaskID,
+		ContractID:         task.ContractID,
+		ConfirmationStatus: CommitmentStatusNone,
+	}
+	proof := task.MerkleProof
+	if proof == nil {
+		return out
+	}
+	out.CommitmentAddress = proof.CommitmentAddress
+	out.CommitmentVout = proof.CommitmentVout
+	out.CommitmentSats = proof.CommitmentSats
+	out.CommitmentRedeemScript = proof.CommitmentRedeemScript
+	out.CommitmentRedeemHash = proof.CommitmentRedeemHash
+	out.CommitmentPixelHash = proof.CommitmentPixelHash
+	out.CommitmentSource = proof.CommitmentSource
+	out.FundingTxID = proof.TxID
+	out.HasCommitment = proof.CommitmentAddress != "" || proof.CommitmentRedeemScript != "" || proof.CommitmentSats > 0
+	if proof.ConfirmationStatus != "" {
+		out.ConfirmationStatus = proof.ConfirmationStatus
+	} else if proof.TxID != "" {
+		out.ConfirmationStatus = CommitmentStatusProvisional
+	}
+	out.Confirmed = out.ConfirmationStatus == CommitmentStatusConfirmed
+	out.BlockHeight = proof.BlockHeight
+	if !proof.SeenAt.IsZero() {
+		seen := proof.SeenAt
+		out.SeenAt = &seen
+	}
+	out.ConfirmedAt = proof.ConfirmedAt
+	return out
+}
+
+// ClearCommitment strips the funding and commitment fields from a proof so a
+// fresh funding PSBT can be built for the task. The visible and product pixel
+// hashes, contractor wallet and funding address are kept, as is any recommit
+// and sweep history.
+func ClearCommitment(proof *MerkleProof) {
+	proof.TxID = ""
+	proof.PayerWallet = ""
+	proof.BlockHeight = 0
+	proof.BlockHeaderMerkleRoot = ""
+	proof.ProofPath = nil
+	proof.FundedAmountSats = 0
+	proof.CommitmentRedeemScript = ""
+	proof.CommitmentPixelHash = ""
+	proof.CommitmentRedeemHash = ""
+	proof.CommitmentAddress = ""
+	proof.CommitmentVout = 0
+	proof.CommitmentSats = 0
+	proof.CommitmentSource = ""
+	proof.ConfirmationStatus = ""
+	proof.SeenAt = time.Time{}
+	proof.ConfirmedAt = nil
+}
@@ -0,0 +1,34 @@
+package smart_contract
+
+import "strings"
+
+// ValidFundingStatus reports whether s is a ContractFilter.FundingStatus value.
+func ValidFundingStatus(s string) bool {
+	switch s {
+	case FundingStatusFunded, FundingStatusPartial, FundingStatusUnfunded:
+		return true
+	}
+	return false
+}
+
+// FundingStatusFor classifies a contract's funding from its task proofs. Only
+// confirmed proofs carrying a funding tx_id count; placeholder proofs created
+// with a proposal hold the intended amount but no transaction, and a
+// provisional proof's tx_id may come from a PSBT that was never broadcast.
+// Contracts without a budget are funded once any amount is.
+func FundingStatusFor(totalBudgetSats int64, proofs []MerkleProof) string {
+	var funded int64
+	for _, p := range proofs {
+		if strings.TrimSpace(p.TxID) != "" && p.ConfirmationStatus == CommitmentStatusConfirmed {
+			funded += p.FundedAmountSats
+		}
+	}
+	switch {
+	case funded <= 0:
+		return FundingStatusUnfunded
+	case funded >= totalBudgetSats:
+		return FundingStatusFunded
+	default:
+		return FundingStatusPartial
+	}
+}
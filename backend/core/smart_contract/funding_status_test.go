@@ -0,0 +1,24 @@
+package smart_contract
+
+import "testing"
+
+func TestFundingStatusFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget int64
+		proofs []MerkleProof
+		want   string
+	}{
+		{"no proofs", 1000, nil, FundingStatusUnfunded},
+		{"placeholder proof", 1000, []MerkleProof{{FundedAmountSats: 1000}}, FundingStatusUnfunded},
+		{"provisional proof", 1000, []MerkleProof{{TxID: "a", FundedAmountSats: 1000, ConfirmationStatus: CommitmentStatusProvisional}}, FundingStatusUnfunded},
+		{"partial", 1000, []MerkleProof{{TxID: "a", FundedAmountSats: 400, ConfirmationStatus: CommitmentStatusConfirmed}, {FundedAmountSats: 600}}, FundingStatusPartial},
+		{"summed", 1000, []MerkleProof{{TxID: "a", FundedAmountSats: 400, ConfirmationStatus: CommitmentStatusConfirmed}, {TxID: "b", FundedAmountSats: 600, ConfirmationStatus: CommitmentStatusConfirmed}}, FundingStatusFunded},
+		{"no budget", 0, []MerkleProof{{TxID: "a", FundedAmountSats: 1, ConfirmationStatus: CommitmentStatusConfirmed}}, FundingStatusFunded},
+	}
+	for _, tc := range tests {
+		if got := FundingStatusFor(tc.budget, tc.proofs); got != tc.want {
+			t.Errorf("%s: FundingStatusFor = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package smart_contract
+
+import "sort"
+
+// SortSubmissions orders submissions oldest first by CreatedAt, breaking
+// ties by SubmissionID so pages are stable across calls.
+func SortSubmissions(subs []Submission) {
+	sort.SliceStable(subs, func(i, j int) bool {
+		if !subs[i].CreatedAt.Equal(subs[j].CreatedAt) {
+			return subs[i].CreatedAt.Before(subs[j].CreatedAt)
+		}
+		return subs[i].SubmissionID < subs[j].SubmissionID
+	})
+}
+
+// PageSubmissions returns the window [offset, offset+limit) of subs and
+// whether more follow it. limit <= 0 returns everything from offset; a
+// negative offset is treated as 0.
+func PageSubmissions(subs []Submission, limit, offset int) ([]Submission, bool) {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(subs) {
+		return []Submission{}, false
+	}
+	end := len(subs)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return subs[offset:end], end < len(subs)
+}
@@ -0,0 +1,37 @@
+package smart_contract
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPageSubmissions(t *testing.T) {
+	subs := make([]Submission, 5)
+	for i := range subs {
+		subs[i].SubmissionID = string(rune('a' + i))
+		subs[i].CreatedAt = time.Unix(int64(100-i), 0)
+	}
+	SortSubmissions(subs)
+	if subs[0].SubmissionID != "e" || subs[4].SubmissionID != "a" {
+		t.Fatalf("sorted = %v", subs)
+	}
+
+	tests := []struct {
+		limit, offset int
+		wantLen       int
+		wantMore      bool
+	}{
+		{0, 0, 5, false},
+		{2, 0, 2, true},
+		{2, 3, 2, false},
+		{10, 4, 1, false},
+		{2, 9, 0, false},
+		{2, -1, 2, true},
+	}
+	for _, tt := range tests {
+		page, more := PageSubmissions(subs, tt.limit, tt.offset)
+		if len(page) != tt.wantLen || more != tt.wantMore {
+			t.Errorf("PageSubmissions(limit=%d, offset=%d) = %d items, more=%v; want %d, %v", tt.limit, tt.offset, len(page), more, tt.wantLen, tt.wantMore)
+		}
+	}
+}
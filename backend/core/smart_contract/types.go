@@ -12,6 +12,8 @@ const (
 	ContractStatusFunded    = "funded"
 	ContractStatusConfirmed = "confirmed"
 	ContractStatusExpired   = "expired"
+	ContractStatusCompleted = "completed"
+	ContractStatusClosed    = "closed"
 
 	// Task statuses
 	TaskStatusAvailable = "available"
@@ -49,6 +51,11 @@ const (
 	StatusActive    = "active"
 	StatusCompleted = "completed"
 	StatusAll       = "all"
+
+	// Funding statuses for ContractFilter.FundingStatus, see FundingStatusFor.
+	FundingStatusFunded   = "funded"
+	FundingStatusPartial  = "partial"
+	FundingStatusUnfunded = "unfunded"
 )
 
 // Contract captures a goal contract summary.
@@ -65,6 +72,7 @@ type Contract struct {
 	ConfirmedBlockHeight *int                    `json:"confirmed_block_height,omitempty"`
 	ConfirmedAt          *time.Time              `json:"confirmed_at,omitempty"`
 	CreatedAt            time.Time               `json:"created_at"`
+	LastActivityAt       *time.Time              `json:"last_activity_at,omitempty"` // latest claim, submission, review or funding on any task
 	ReworkRequests       []ContractReworkRequest `json:"rework_requests,omitempty"`
 }
 
@@ -98,6 +106,7 @@ type Task struct {
 	EstimatedHours   int               `json:"estimated_hours,omitempty"`
 	Requirements     map[string]string `json:"requirements,omitempty"`
 	MerkleProof      *MerkleProof      `json:"merkle_proof,omitempty"`
+	LastActivityAt   *time.Time        `json:"last_activity_at,omitempty"` // latest claim, submission, review or funding
 }
 
 // MerkleProof represents the payment proof for a funded task.
@@ -110,6 +119,7 @@ type MerkleProof struct {
 	ContractorWallet       string      `json:"contractor_wallet,omitempty"`
 	FundedAmountSats       int64       `json:"funded_amount_sats"`
 	FundingAddress         string      `json:"funding_address,omitempty"`
+	PayerWallet            string      `json:"payer_wallet,omitempty"` // wallet whose funding PSBT set the commitment
 	CommitmentRedeemScript string      `json:"commitment_redeem_script,omitempty"`
 	CommitmentPixelHash    string      `json:"commitment_pixel_hash,omitempty"`
 	CommitmentRedeemHash   string      `json:"commitment_redeem_hash,omitempty"`
@@ -168,30 +178,35 @@ type Submission struct {
 
 // ContractFilter captures list filters for contracts.
 type ContractFilter struct {
-	Status             string
-	Skills             []string
-	Creator            string
-	AiIdentifier       string
-	Limit              int
-	Offset             int
-	CursorHeight       *int       // For cursor-based pagination using confirmed_block_height
-	CursorDate         *time.Time // For cursor-based pagination using confirmed_at
-	CursorType         string     // 'before' or 'after'
-	OrderByConfirmedAt bool       // Order by confirmed_at instead of block height
+	Status              string
+	Skills              []string
+	Creator             string
+	AiIdentifier        string
+	Limit               int
+	Offset              int
+	CursorHeight        *int       // For cursor-based pagination using confirmed_block_height
+	CursorDate          *time.Time // For cursor-based pagination using confirmed_at
+	CursorType          string     // 'before' or 'after'
+	OrderByConfirmedAt  bool       // Order by confirmed_at instead of block height
+	FundingStatus       string     // funded | partial | unfunded; empty matches all
+	InactiveSince       *time.Time // Only include contracts with no activity (or creation) since this time
+	OrderByLastActivity bool       // Order by last activity, least recent first
 }
 
 // TaskFilter captures simple query params for listing tasks.
 type TaskFilter struct {
-	Skills            []string
-	MaxDifficulty     string
-	MinBudgetSats     int64
-	Limit             int
-	Offset            int
-	Status            string
-	ContractID        string
-	ClaimedBy         string
-	UpdatedSince      *time.Time // Only include tasks updated since this time
-	LastActivitySince *time.Time // Only include tasks with activity since this time
+	Skills              []string
+	MaxDifficulty       string
+	MinBudgetSats       int64
+	Limit               int
+	Offset              int
+	Status              string
+	ContractID          string
+	ClaimedBy           string
+	UpdatedSince        *time.Time // Only include tasks updated since this time
+	LastActivitySince   *time.Time // Only include tasks with activity since this time
+	InactiveSince       *time.Time // Only include tasks with no LastActivityAt since this time
+	OrderByLastActivity bool       // Order by LastActivityAt, least recent (or never) first
 }
 
 // Proposal represents a human/markdown wish that must be approved before tasks are published.
@@ -26,6 +26,9 @@ type ScanResult struct {
 	MethodID         *int    `json:"method_id,omitempty"`
 	ExtractedMessage string  `json:"extracted_message,omitempty"`
 	ExtractionError  string  `json:"extraction_error,omitempty"`
+	// Probabilities is the per-class distribution (class -> probability) when
+	// the scanner reports one.
+	Probabilities map[string]float64 `json:"probabilities,omitempty"`
 }
 
 // ImageScanResult represents the result of scanning a single image
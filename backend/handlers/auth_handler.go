@@ -18,6 +18,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
+	"stargate-backend/bitcoin"
 	auth "stargate-backend/storage/auth"
 )
 
@@ -66,24 +67,15 @@ func (h *APIKeyHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	wallet := strings.TrimSpace(body.Wallet)
-	if wallet != "" {
-		if getter, ok := h.validator.(interface {
-			Get(string) (auth.APIKey, bool)
-		}); ok {
-			if rec, ok := getter.Get(apiKey); ok {
-				if strings.TrimSpace(rec.Wallet) != "" && rec.Wallet != wallet {
-					h.sendError(w, http.StatusForbidden, "wallet already bound; rebind requires verification")
-					return
-				}
-			}
-		}
-		if updater, ok := h.validator.(auth.APIKeyWalletUpdater); ok {
-			if _, err := updater.UpdateWallet(apiKey, wallet); err != nil {
-				h.sendError(w, http.StatusInternalServerError, "failed to bind wallet to api key")
-				return
-			}
-		}
+	// Login never binds a wallet: binding needs proof of ownership via
+	// POST /api/auth/wallet. It only reports the wallet already bound.
+	wallet := ""
+	if rec, ok := h.validator.Get(apiKey); ok {
+		wallet = strings.TrimSpace(rec.Wallet)
+	}
+	if requested := strings.TrimSpace(body.Wallet); requested != "" && wallet != "" && requested != wallet {
+		h.sendError(w, http.StatusForbidden, "wallet already bound; rebind requires verification")
+		return
 	}
 
 	// Set httpOnly cookie for security
@@ -220,6 +212,110 @@ func (h *APIKeyHandler) HandleVerify(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// HandleWallet reads (GET) or binds (POST) the wallet for the caller's API key.
+// Binding is explicit here rather than a side effect of login or claim.
+// POST request: {"wallet_address":"...","signature":"..."}
+// The address must belong to the configured BITCOIN_NETWORK, and the
+// signature (legacy signmessage or BIP-322) must sign a challenge issued for
+// that wallet via POST /api/auth/challenge, proving the caller controls it.
+// Re-posting the wallet already bound is a no-op and needs no signature.
+// Response: { "wallet":"...","network":"...","bound":true }
+func (h *APIKeyHandler) HandleWallet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		h.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	apiKey := requestAPIKey(r)
+	if apiKey == "" || h.validator == nil || !h.validator.Validate(apiKey) {
+		h.sendError(w, http.StatusForbidden, "invalid api key")
+		return
+	}
+	rec, _ := h.validator.Get(apiKey)
+	network := bitcoin.GetCurrentNetwork()
+
+	if r.Method == http.MethodGet {
+		h.sendSuccess(w, walletBinding(rec.Wallet, network))
+		return
+	}
+
+	var body struct {
+		Wallet    string `json:"wallet_address"`
+		Signature string `json:"signature,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.sendError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	wallet := strings.TrimSpace(body.Wallet)
+	if wallet == "" {
+		h.sendError(w, http.StatusBadRequest, "wallet_address required")
+		return
+	}
+	params := bitcoin.NetworkParams(network)
+	if decoded, err := btcutil.DecodeAddress(wallet, params); err != nil || !decoded.IsForNet(params) {
+		h.sendError(w, http.StatusBadRequest, fmt.Sprintf("wallet_address is not a valid %s address", network))
+		return
+	}
+
+	if strings.TrimSpace(rec.Wallet) == wallet {
+		h.sendSuccess(w, walletBinding(wallet, network))
+		return
+	}
+
+	// Prove control of the address before payouts can be routed to it.
+	if h.challenges == nil {
+		h.sendError(w, http.StatusServiceUnavailable, "challenge store unavailable")
+		return
+	}
+	if strings.TrimSpace(body.Signature) == "" {
+		h.sendError(w, http.StatusForbidden, "signature required: sign the nonce from POST /api/auth/challenge with this wallet")
+		return
+	}
+	verifier := func(ch auth.Challenge, sig string) bool {
+		ok, err := VerifyBTCSignature(ch.Wallet, sig, strings.TrimSpace(ch.Nonce))
+		return err == nil && ok
+	}
+	if !h.challenges.Verify(wallet, body.Signature, verifier) {
+		h.sendError(w, http.StatusForbidden, "invalid signature")
+		return
+	}
+
+	updater, ok := h.validator.(auth.APIKeyWalletUpdater)
+	if !ok {
+		h.sendError(w, http.StatusNotImplemented, "api key store cannot bind wallets")
+		return
+	}
+	if _, err := updater.UpdateWallet(apiKey, wallet); err != nil {
+		h.sendError(w, http.StatusInternalServerError, "failed to bind wallet to api key")
+		return
+	}
+	h.sendSuccess(w, walletBinding(wallet, network))
+}
+
+func walletBinding(wallet, network string) map[string]interface{} {
+	wallet = strings.TrimSpace(wallet)
+	return map[string]interface{}{
+		"wallet":  wallet,
+		"network": network,
+		"bound":   wallet != "",
+	}
+}
+
+// requestAPIKey returns the caller's API key from the X-API-Key header,
+// a bearer token, or the X-API-Key cookie, in that order.
+func requestAPIKey(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key
+	}
+	if authHeader := r.Header.Get("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+	}
+	if cookie, err := r.Cookie("X-API-Key"); err == nil {
+		return strings.TrimSpace(cookie.Value)
+	}
+	return ""
+}
+
 // VerifyBTCSignature supports legacy signmessage (compact) and BIP-322 simple witness signatures.
 // It tries both the provided message and a hex-decoded variant to be lenient with wallets that
 // interpret hex-looking nonces differently.
@@ -3,9 +3,9 @@ package handlers
 import (
 	"bytes"
 	"context"
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -24,10 +24,12 @@ import (
 	_ "golang.org/x/image/bmp"
 	_ "golang.org/x/image/webp"
 
+	"stargate-backend/logging"
 	"stargate-backend/stego"
 	sc "stargate-backend/core/smart_contract"
 	"stargate-backend/storage/ipfs"
 	scmiddleware "stargate-backend/middleware/smart_contract"
+	"stargate-backend/middleware"
 	"stargate-backend/models"
 	"stargate-backend/security"
 	"stargate-backend/services"
@@ -56,11 +58,20 @@ func (h *BaseHandler) sendJSON(w http.ResponseWriter, statusCode int, data inter
 
 // sendError sends an error response
 func (h *BaseHandler) sendError(w http.ResponseWriter, statusCode int, message string) {
-	log.Printf("ERROR: Status %d - %s", statusCode, message)
+	logging.Errorf("Status %d - %s", statusCode, message)
 	errorResp := models.NewErrorResponse(message, statusCode)
 	h.sendJSON(w, statusCode, errorResp)
 }
 
+// sendErrorCode sends an error response carrying a machine-readable code in
+// place of the stringified status.
+func (h *BaseHandler) sendErrorCode(w http.ResponseWriter, statusCode int, code, message string) {
+	logging.Errorf("Status %d - %s: %s", statusCode, code, message)
+	errorResp := models.NewErrorResponse(message, statusCode)
+	errorResp.Error.Error.Code = code
+	h.sendJSON(w, statusCode, errorResp)
+}
+
 // sendSuccess sends a success response
 func (h *BaseHandler) sendSuccess(w http.ResponseWriter, data interface{}) {
 	successResp := models.NewSuccessResponse(data)
@@ -226,18 +237,66 @@ func placeholderPNG() []byte {
 	return data
 }
 
+// maxInscriptionsPageSize caps the limit accepted by HandleGetInscriptions.
+const maxInscriptionsPageSize = 100
+
+// inscriptionsPage holds the pagination parameters of GET /api/inscriptions.
+type inscriptionsPage struct {
+	limit  int // 0 returns every item
+	offset int
+	asc    bool
+}
+
+// parseInscriptionsPage reads limit, offset and order. limit is clamped to
+// 1..maxInscriptionsPageSize; order is "desc" (newest first, the default) or "asc".
+func parseInscriptionsPage(r *http.Request) (inscriptionsPage, error) {
+	var page inscriptionsPage
+	q := r.URL.Query()
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return page, fmt.Errorf("limit must be an integer")
+		}
+		page.limit = min(max(n, 1), maxInscriptionsPageSize)
+	}
+	if raw := q.Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return page, fmt.Errorf("offset must be a non-negative integer")
+		}
+		page.offset = n
+	}
+	switch strings.ToLower(strings.TrimSpace(q.Get("order"))) {
+	case "", "desc":
+	case "asc":
+		page.asc = true
+	default:
+		return page, fmt.Errorf("order must be asc or desc")
+	}
+	return page, nil
+}
+
 // HandleGetInscriptions handles getting all inscriptions
 // @Summary Get all pending inscriptions (smart contracts)
-// @Description Get all pending inscriptions (smart contracts)
+// @Description Get all pending inscriptions (smart contracts), optionally paginated with limit (max 100), offset and order (asc|desc by timestamp)
 // @Tags Inscriptions
 // @Produce  json
+// @Param limit query int false "Page size, 1-100; omit for all"
+// @Param offset query int false "Items to skip"
+// @Param order query string false "asc or desc (default)"
 // @Success 200 {object} models.PendingTransactionsResponse
+// @Failure 400 {object} models.ErrorResponse
 // @Router /api/inscriptions [get]
 func (h *InscriptionHandler) HandleGetInscriptions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		h.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
+	page, err := parseInscriptionsPage(r)
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	var inscriptions []models.InscriptionRequest
 	dedupe := make(map[string]int) // id -> index in inscriptions
@@ -330,6 +389,9 @@ func (h *InscriptionHandler) HandleGetInscriptions(w http.ResponseWriter, r *htt
 	}
 
 	sort.SliceStable(inscriptions, func(i, j int) bool {
+		if page.asc {
+			return inscriptions[i].Timestamp < inscriptions[j].Timestamp
+		}
 		return inscriptions[i].Timestamp > inscriptions[j].Timestamp
 	})
 
@@ -337,6 +399,17 @@ func (h *InscriptionHandler) HandleGetInscriptions(w http.ResponseWriter, r *htt
 		Transactions: inscriptions,
 		Total:        len(inscriptions),
 	}
+	if page.limit > 0 || page.offset > 0 {
+		start := min(page.offset, len(inscriptions))
+		end := len(inscriptions)
+		if page.limit > 0 {
+			end = min(start+page.limit, end)
+		}
+		response.Transactions = inscriptions[start:end]
+		if end < len(inscriptions) {
+			response.NextOffset = &end
+		}
+	}
 	h.sendSuccess(w, response)
 }
 
@@ -354,7 +427,7 @@ func (h *InscriptionHandler) fromIngestion(rec services.IngestionRecord) models.
 				if err := os.WriteFile(targetPath, data, 0644); err != nil {
 					fmt.Printf("Failed to write ingestion image to %s: %v\n", targetPath, err)
 				} else {
-					log.Printf("DEBUG: lazy-stored ingestion image to %s", targetPath)
+					logging.Debugf("lazy-stored ingestion image to %s", targetPath)
 				}
 			}
 		}
@@ -602,14 +675,6 @@ func stripWishTimestamp(message string) string {
 	return strings.TrimSpace(message[:idx])
 }
 
-func computeVisiblePixelHash(imageBytes []byte, text string) string {
-	// Include text (message) in hash if provided, for uniqueness of wish/inscription
-	// (previously ignored the text param, now uses both for Cat 6.6)
-	input := append(imageBytes, []byte(text)...)
-	sum := sha256.Sum256(input)
-	return fmt.Sprintf("%x", sum[:])
-}
-
 func wishContractID(visibleHash string) string {
 	visibleHash = strings.TrimSpace(visibleHash)
 	if visibleHash == "" {
@@ -764,25 +829,21 @@ func parsePriceSats(raw string) int64 {
 
 // HandleCreateInscription handles creating a new inscription
 func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *http.Request) {
-	log.Printf("DEBUG: CreateInscription handler called with method: %s, apiKeyIssuer: %v", r.Method, h.apiKeyIssuer != nil)
+	logging.Debugf("CreateInscription handler called with method: %s, apiKeyIssuer: %v", r.Method, h.apiKeyIssuer != nil)
 	if r.Method != http.MethodPost {
 		h.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	// Only support JSON requests
-	contentType := r.Header.Get("Content-Type")
-	if !strings.HasPrefix(contentType, "application/json") && !strings.HasPrefix(contentType, "application/json;") {
-		h.sendError(w, http.StatusBadRequest, "Only JSON content type is supported")
+	payload, err := readInscribeRequest(w, r)
+	if errors.Is(err, errInscribeBodyTooLarge) {
+		h.sendErrorCode(w, http.StatusRequestEntityTooLarge, errInscribeBodyTooLarge.Code, errInscribeBodyTooLarge.Message)
 		return
 	}
-
-	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.sendError(w, http.StatusBadRequest, "Failed to read request body")
+		h.sendError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	defer r.Body.Close()
 
 	var (
 		text        string
@@ -793,25 +854,8 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 		fundingMode string
 		filename    string
 		imgBytes    []byte
-		imageErr    error
 	)
 
-	var payload struct {
-		Message      string `json:"message"`
-		Text         string `json:"text"`
-		Method       string `json:"method"`
-		Price        string `json:"price"`
-		PriceUnit    string `json:"price_unit"`
-		Address      string `json:"address"`
-		FundingMode  string `json:"funding_mode"`
-		ImageBase64  string `json:"image_base64"`
-		Filename     string `json:"filename"`
-		SkipProposal bool   `json:"skip_proposal"`
-	}
-	if err := json.Unmarshal(bodyBytes, &payload); err != nil {
-		h.sendError(w, http.StatusBadRequest, "Invalid JSON")
-		return
-	}
 	text = payload.Message
 	if text == "" {
 		text = payload.Text
@@ -838,22 +882,22 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 
 	address = payload.Address
 	fundingMode = payload.FundingMode
-	filename = payload.Filename
-
-	if filename != "" && !security.ValidateExtension(filename, security.AllowedImageExtensions) {
-		h.sendError(w, http.StatusBadRequest, "Invalid file type. Allowed types: png, jpg, jpeg, gif, webp, avif, bmp, svg")
-		return
-	}
 
-	if payload.ImageBase64 != "" {
-		imgBytes, imageErr = base64.StdEncoding.DecodeString(payload.ImageBase64)
-		if imageErr != nil {
-			h.sendError(w, http.StatusBadRequest, "Invalid base64 image")
+	// The client's filename is never trusted: uploads are decoded to confirm
+	// they are PNG/JPEG and renamed after their content hash.
+	imgBytes = payload.image
+	if len(imgBytes) > 0 {
+		ext, err := validateInscribeImage(imgBytes)
+		if err != nil {
+			var uploadErr *imageUploadError
+			if errors.As(err, &uploadErr) {
+				h.sendErrorCode(w, http.StatusBadRequest, uploadErr.Code, uploadErr.Message)
+			} else {
+				h.sendError(w, http.StatusBadRequest, err.Error())
+			}
 			return
 		}
-		if filename == "" {
-			filename = "image.png"
-		}
+		filename = safeImageFilename(imgBytes, ext)
 	}
 
 	// Ensure we have image bytes & filename for downstream hashing/storage
@@ -885,7 +929,7 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 
 	if h.proxyBase != "" {
 		// Proxy to starlight /inscribe
-		log.Printf("DEBUG: Proxy path selected, proxyBase=%s", h.proxyBase)
+		logging.Debugf("Proxy path selected, proxyBase=%s", h.proxyBase)
 		var buf bytes.Buffer
 		writer := multipart.NewWriter(&buf)
 
@@ -903,6 +947,7 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 		proxyURL := fmt.Sprintf("%s/inscribe", strings.TrimRight(h.proxyBase, "/"))
 		proxyReq, _ := http.NewRequest(http.MethodPost, proxyURL, &buf)
 		proxyReq.Header.Set("Content-Type", writer.FormDataContentType())
+		middleware.SetRequestIDHeader(r.Context(), proxyReq)
 		if apiKey := os.Getenv("STARGATE_API_KEY"); apiKey != "" {
 			proxyReq.Header.Set("Authorization", "Bearer "+apiKey)
 		}
@@ -967,7 +1012,6 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 			return
 		}
 
-		ingestionID = starlightResp.ImageSHA256
 		starlightRequestID = starlightResp.RequestID
 		stegoImgBytes, err = base64.StdEncoding.DecodeString(starlightResp.ImageBase64)
 		if err != nil {
@@ -975,9 +1019,15 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 			return
 		}
 		stegoImageBase64 = starlightResp.ImageBase64
+		// Key the wish by the hash of the bytes we store, not the proxy's claim,
+		// so it matches what reconciliation and PSBT commitment recompute.
+		ingestionID = stego.VisiblePixelHash(stegoImgBytes)
+		if !strings.EqualFold(ingestionID, starlightResp.ImageSHA256) {
+			logging.Warnf("starlight image_sha256 %s does not match stored image hash %s", starlightResp.ImageSHA256, ingestionID)
+		}
 	} else {
 		// Native steganography (no proxy configured)
-		log.Printf("DEBUG: Native stego path selected")
+		logging.Debugf("Native stego path selected")
 		inscribeResult, err := stego.Inscribe(imgBytes, embeddedMessage, method)
 		if err != nil {
 			h.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to embed steganography: %v", err))
@@ -1000,7 +1050,7 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 	if creatorKey != "" && h.apiKeyValidator != nil {
 		if apiKeyRec, ok := h.apiKeyValidator.Get(creatorKey); ok {
 			creatorWallet = strings.TrimSpace(apiKeyRec.Wallet)
-			log.Printf("DEBUG: Found creator wallet: %s", creatorWallet)
+			logging.Debugf("Found creator wallet: %s", creatorWallet)
 		}
 	}
 
@@ -1014,6 +1064,11 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 		"funding_mode":     fundingMode,
 		"creator_wallet":   creatorWallet,
 	}
+	// Stored so proposals and PSBT commitment use this exact value instead of
+	// re-deriving it from the image. contract_id follows the same convention as
+	// IPFS-synced wishes, so create_proposal can work from ingestion_id alone.
+	meta["visible_pixel_hash"] = ingestionID
+	meta["contract_id"] = ingestionID
 	if starlightRequestID != "" {
 		meta["starlight_request_id"] = starlightRequestID
 	} else {
@@ -1025,7 +1080,7 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 
 	// Write stego image to uploads directory
 	uploadsDir := os.Getenv("UPLOADS_DIR")
-	log.Printf("DEBUG: uploadsDir resolved to: %s", uploadsDir)
+	logging.Debugf("uploadsDir resolved to: %s", uploadsDir)
 	if err := os.MkdirAll(uploadsDir, 0755); err != nil {
 		h.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to create uploads directory %s: %v", uploadsDir, err))
 		return
@@ -1033,15 +1088,15 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 	// Use hash-only filename for stealth
 	imageFilename := ingestionID
 	imagePath := security.SafeFilePath(uploadsDir, imageFilename)
-	log.Printf("DEBUG: Attempting to write stego image to %s (size: %d bytes)", imagePath, len(stegoImgBytes))
+	logging.Debugf("Attempting to write stego image to %s (size: %d bytes)", imagePath, len(stegoImgBytes))
 	if err := os.WriteFile(imagePath, stegoImgBytes, 0644); err != nil {
 		h.sendError(w, http.StatusInternalServerError, fmt.Sprintf("Failed to write image to %s: %v", imagePath, err))
 		return
 	}
-	log.Printf("DEBUG: Successfully stored stego image to %s", imagePath)
+	logging.Debugf("Successfully stored stego image to %s", imagePath)
 
 	if h.ingestionService != nil {
-		log.Printf("DEBUG: Creating ingestion record for %s", ingestionID)
+		logging.Debugf("Creating ingestion record for %s", ingestionID)
 		ingRec := services.IngestionRecord{
 			ID:            ingestionID,
 			Filename:      imageFilename,
@@ -1052,15 +1107,15 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 			Status:        "pending",
 		}
 		if err := h.ingestionService.Create(ingRec); err != nil {
-			log.Printf("ERROR: Failed to create ingestion record for %s: %v", ingestionID, err)
+			logging.Errorf("Failed to create ingestion record for %s: %v", ingestionID, err)
 		}
 		// Publish announcement
-		log.Printf("DEBUG: Publishing pending ingest announcement for %s", ingestionID)
+		logging.Debugf("Publishing pending ingest announcement for %s", ingestionID)
 		publishPendingIngestAnnouncement(ingestionID, ingestionID, imageFilename, "alpha", embeddedMessage, price, priceUnit, address, fundingMode, stegoImgBytes)
 	}
 
 	if h.store != nil {
-		log.Printf("DEBUG: Mirroring into store for %s", ingestionID)
+		logging.Debugf("Mirroring into store for %s", ingestionID)
 		proposalTitle := strings.TrimSpace(text)
 		if strings.HasPrefix(proposalTitle, "#") {
 			proposalTitle = strings.TrimSpace(strings.TrimLeft(proposalTitle, "#"))
@@ -1118,25 +1173,48 @@ func (h *InscriptionHandler) HandleCreateInscription(w http.ResponseWriter, r *h
 	return
 }
 
-// HandleDeleteInscription handles deleting an inscription and its associated wish
-func (h *InscriptionHandler) HandleDeleteInscription(w http.ResponseWriter, r *http.Request) {
+// HandleDeletePendingTransaction cancels a pending inscription before it is mined.
+func (h *InscriptionHandler) HandleDeletePendingTransaction(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		h.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	// Extract ID from URL path (e.g., /api/inscriptions/{id})
-	id := strings.TrimPrefix(r.URL.Path, "/api/inscriptions/")
+	id := strings.TrimSpace(strings.TrimPrefix(r.URL.Path, "/api/pending-transactions/"))
 	if id == "" {
 		h.sendError(w, http.StatusBadRequest, "Missing ID")
 		return
 	}
+	if h.inscriptionService == nil {
+		h.sendError(w, http.StatusServiceUnavailable, "Inscription service unavailable")
+		return
+	}
 
-	// Normalize ID (strip wish- prefix)
-	visibleHash := strings.TrimPrefix(id, "wish-")
+	requesterWallet := h.requesterWallet(r)
+	removed, err := h.inscriptionService.DeleteInscription(id, func(insc models.InscriptionRequest) bool {
+		return walletMayDelete(insc.Address, requesterWallet)
+	})
+	switch {
+	case errors.Is(err, services.ErrInscriptionNotFound):
+		h.sendError(w, http.StatusNotFound, "Inscription not found")
+		return
+	case errors.Is(err, services.ErrInscriptionNotOwned):
+		h.sendError(w, http.StatusForbidden, "Only the wallet that created this inscription or an authorized auditor can delete it")
+		return
+	case errors.Is(err, services.ErrInscriptionNotPending):
+		h.sendError(w, http.StatusForbidden, fmt.Sprintf("Cannot delete inscription %s: %v", id, err))
+		return
+	case err != nil:
+		h.sendError(w, http.StatusInternalServerError, "Failed to delete inscription")
+		return
+	}
+
+	h.sendSuccess(w, removed)
+}
 
-	// Get requester wallet from API key (provided by wrapWithAuth)
-	var requesterWallet string
+// requesterWallet returns the wallet bound to the request's API key (provided
+// by wrapWithAuth), or "" when there is none.
+func (h *InscriptionHandler) requesterWallet(r *http.Request) string {
 	apiKey := strings.TrimSpace(r.Header.Get("X-API-Key"))
 	if apiKey == "" {
 		auth := r.Header.Get("Authorization")
@@ -1146,9 +1224,48 @@ func (h *InscriptionHandler) HandleDeleteInscription(w http.ResponseWriter, r *h
 	}
 	if apiKey != "" && h.apiKeyValidator != nil {
 		if apiKeyRec, ok := h.apiKeyValidator.Get(apiKey); ok {
-			requesterWallet = strings.TrimSpace(apiKeyRec.Wallet)
+			return strings.TrimSpace(apiKeyRec.Wallet)
 		}
 	}
+	return ""
+}
+
+// walletMayDelete reports whether requester may delete a record owned by
+// owner: records without an owner are open, otherwise only the owner or the
+// global auditor (the donation address) may.
+func walletMayDelete(owner, requester string) bool {
+	owner = strings.TrimSpace(owner)
+	if owner == "" {
+		return true
+	}
+	if requester == "" {
+		return false
+	}
+	if strings.EqualFold(owner, requester) {
+		return true
+	}
+	donationAddr := strings.TrimSpace(os.Getenv("STARLIGHT_DONATION_ADDRESS"))
+	return donationAddr != "" && strings.EqualFold(requester, donationAddr)
+}
+
+// HandleDeleteInscription handles deleting an inscription and its associated wish
+func (h *InscriptionHandler) HandleDeleteInscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		h.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Extract ID from URL path (e.g., /api/inscriptions/{id})
+	id := strings.TrimPrefix(r.URL.Path, "/api/inscriptions/")
+	if id == "" {
+		h.sendError(w, http.StatusBadRequest, "Missing ID")
+		return
+	}
+
+	// Normalize ID (strip wish- prefix)
+	visibleHash := strings.TrimPrefix(id, "wish-")
+
+	requesterWallet := h.requesterWallet(r)
 
 	// 1. Check ownership via ingestion record
 	if h.ingestionService != nil {
@@ -1159,15 +1276,10 @@ func (h *InscriptionHandler) HandleDeleteInscription(w http.ResponseWriter, r *h
 		}
 
 		// Verify ownership
-		if creatorWallet, ok := rec.Metadata["creator_wallet"].(string); ok && creatorWallet != "" {
-			if requesterWallet == "" || !strings.EqualFold(strings.TrimSpace(creatorWallet), requesterWallet) {
-				// Special case: check global auditor status (donation address)
-				donationAddr := strings.TrimSpace(os.Getenv("STARLIGHT_DONATION_ADDRESS"))
-				if donationAddr == "" || !strings.EqualFold(requesterWallet, donationAddr) {
-					h.sendError(w, http.StatusForbidden, "Only the wish creator or an authorized auditor can delete this wish")
-					return
-				}
-			}
+		creatorWallet, _ := rec.Metadata["creator_wallet"].(string)
+		if !walletMayDelete(creatorWallet, requesterWallet) {
+			h.sendError(w, http.StatusForbidden, "Only the wish creator or an authorized auditor can delete this wish")
+			return
 		}
 
 		// Check status - deletion not allowed for confirmed/finalized items
@@ -1226,7 +1338,7 @@ func (h *BlockHandler) HandleGetBlocks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	blocks, err := h.blockService.GetBlocks()
+	blocks, err := h.blockService.GetBlocks(r.Context())
 	if err != nil {
 		h.sendError(w, http.StatusInternalServerError, "Failed to fetch blocks")
 		return
@@ -1594,16 +1706,16 @@ func (h *SearchHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" || strings.ToLower(query) == "block" || strings.ToLower(query) == "blocks" {
 		// Return recent blocks
-		h.sendSuccess(w, h.recentBlocksResponse(query))
+		h.sendSuccess(w, h.recentBlocksResponse(r.Context(), query))
 		return
 	}
 
 	// Search inscriptions and blocks
-	h.sendSuccess(w, h.searchData(query))
+	h.sendSuccess(w, h.searchData(r.Context(), query))
 }
 
-func (h *SearchHandler) recentBlocksResponse(query string) models.SearchResult {
-	result := h.searchData(query)
+func (h *SearchHandler) recentBlocksResponse(ctx context.Context, query string) models.SearchResult {
+	result := h.searchData(ctx, query)
 	if len(result.Blocks) > 5 {
 		result.Blocks = result.Blocks[:5]
 	}
@@ -1622,7 +1734,7 @@ func (h *SearchHandler) recentBlocksResponse(query string) models.SearchResult {
 	return result
 }
 
-func (h *SearchHandler) searchData(query string) models.SearchResult {
+func (h *SearchHandler) searchData(ctx context.Context, query string) models.SearchResult {
 	q := strings.ToLower(strings.TrimSpace(query))
 	var blocks []models.SearchResultItem
 	var inscriptions []models.SearchResultItem
@@ -1881,7 +1993,7 @@ func (h *SearchHandler) searchData(query string) models.SearchResult {
 
 	// Fallback to service search if nothing found or explicit query
 	if len(blocks) == 0 {
-		if svcBlocks, err := h.blockService.SearchBlocks(query); err == nil {
+		if svcBlocks, err := h.blockService.SearchBlocks(ctx, query); err == nil {
 			for _, b := range svcBlocks {
 				if m, ok := b.(map[string]interface{}); ok {
 					height, _ := m["height"].(int64)
@@ -1924,7 +2036,7 @@ func (h *SearchHandler) searchData(query string) models.SearchResult {
 		}
 	}
 
-	proposalList, err := h.store.ListProposals(context.Background(), sc.ProposalFilter{})
+	proposalList, err := h.store.ListProposals(ctx, sc.ProposalFilter{})
 	if err == nil {
 		for _, p := range proposalList {
 			if matchesQuery(p.ID, p.Title, p.DescriptionMD, p.VisiblePixelHash, p.Status) {
@@ -2026,8 +2138,12 @@ func (h *ProxyHandler) HandleProxy(w http.ResponseWriter, r *http.Request) {
 	}
 	defer resp.Body.Close()
 
-	// Copy response headers
+	// Copy response headers; ours already carries the request ID, so the
+	// upstream's echo must not add a second value.
 	for name, values := range resp.Header {
+		if http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(middleware.RequestIDHeader) && w.Header().Get(name) != "" {
+			continue
+		}
 		for _, value := range values {
 			w.Header().Add(name, value)
 		}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxInscribeImageBytes caps cover images accepted by POST /api/inscribe
+// when STARGATE_MAX_IMAGE_BYTES is unset.
+const DefaultMaxInscribeImageBytes = 10 << 20
+
+// DefaultMaxInscribeImagePixels caps the declared width*height of cover
+// images when STARGATE_MAX_IMAGE_PIXELS is unset.
+const DefaultMaxInscribeImagePixels = 40_000_000
+
+// Error codes returned when an uploaded cover image is rejected.
+const (
+	ErrCodeImageTooLarge = "IMAGE_TOO_LARGE"
+	ErrCodeInvalidImage  = "INVALID_IMAGE"
+)
+
+// maxInscribeImageBytes returns STARGATE_MAX_IMAGE_BYTES, or the default when
+// it is unset or not a positive integer.
+func maxInscribeImageBytes() int64 {
+	if v := strings.TrimSpace(os.Getenv("STARGATE_MAX_IMAGE_BYTES")); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return DefaultMaxInscribeImageBytes
+}
+
+// maxInscribeImagePixels returns STARGATE_MAX_IMAGE_PIXELS, or the default
+// when it is unset or not a positive integer.
+func maxInscribeImagePixels() int64 {
+	if v := strings.TrimSpace(os.Getenv("STARGATE_MAX_IMAGE_PIXELS")); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return DefaultMaxInscribeImagePixels
+}
+
+// imageUploadError is a cover image validation failure; Code is sent to the
+// client alongside the 400.
+type imageUploadError struct {
+	Code    string
+	Message string
+}
+
+func (e *imageUploadError) Error() string { return e.Message }
+
+// validateInscribeImage checks that data is a PNG or JPEG within the size
+// limits by decoding it, and returns the extension for the detected format.
+// The header is read first so an image declaring more pixels than allowed is
+// rejected before the full decode allocates its buffer.
+func validateInscribeImage(data []byte) (string, error) {
+	if limit := maxInscribeImageBytes(); int64(len(data)) > limit {
+		return "", &imageUploadError{
+			Code:    ErrCodeImageTooLarge,
+			Message: fmt.Sprintf("Image exceeds maximum size of %d bytes", limit),
+		}
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return "", &imageUploadError{Code: ErrCodeInvalidImage, Message: "Image must be a valid PNG or JPEG"}
+	}
+	if limit := maxInscribeImagePixels(); int64(cfg.Width)*int64(cfg.Height) > limit {
+		return "", &imageUploadError{
+			Code:    ErrCodeImageTooLarge,
+			Message: fmt.Sprintf("Image exceeds maximum of %d pixels", limit),
+		}
+	}
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		return "", &imageUploadError{Code: ErrCodeInvalidImage, Message: "Image must be a valid PNG or JPEG"}
+	}
+	switch format {
+	case "png":
+		return ".png", nil
+	case "jpeg":
+		return ".jpg", nil
+	}
+	return "", &imageUploadError{Code: ErrCodeInvalidImage, Message: "Image must be a valid PNG or JPEG"}
+}
+
+// safeImageFilename names an upload by its content hash so the client-supplied
+// filename never reaches the filesystem or downstream services.
+func safeImageFilename(data []byte, ext string) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]) + ext
+}
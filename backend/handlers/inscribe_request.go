@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"stargate-backend/coerce"
+)
+
+// inscribeRequest is the body accepted by POST /api/inscribe. It arrives
+// either as JSON ({message, image_base64, ...}) or as a multipart form with
+// the same field names plus an "image" file part.
+type inscribeRequest struct {
+	Message      string `json:"message"`
+	Text         string `json:"text"`
+	Method       string `json:"method"`
+	Price        string `json:"price"`
+	PriceUnit    string `json:"price_unit"`
+	Address      string `json:"address"`
+	FundingMode  string `json:"funding_mode"`
+	ImageBase64  string `json:"image_base64"`
+	Filename     string `json:"filename"`
+	SkipProposal bool   `json:"skip_proposal"`
+
+	// image holds the decoded cover image, from either image_base64 or the
+	// multipart "image" part.
+	image []byte
+}
+
+// inscribeJSONHeadroom is the room a JSON inscribe body gets on top of the
+// base64-encoded image for its other fields.
+const inscribeJSONHeadroom = 1 << 20
+
+// errInscribeBodyTooLarge is returned for JSON bodies over maxInscribeJSONBytes;
+// it is sent to the client as a 413.
+var errInscribeBodyTooLarge = &imageUploadError{Code: ErrCodeImageTooLarge, Message: "Request body too large"}
+
+// maxInscribeJSONBytes bounds a JSON inscribe body: the largest accepted
+// image once base64-encoded, plus inscribeJSONHeadroom.
+func maxInscribeJSONBytes() int64 {
+	return int64(base64.StdEncoding.EncodedLen(int(maxInscribeImageBytes()))) + inscribeJSONHeadroom
+}
+
+// readInscribeRequest decodes an inscribe request body. The returned error
+// message is safe to send to the client as a 400, except
+// errInscribeBodyTooLarge which is a 413.
+func readInscribeRequest(w http.ResponseWriter, r *http.Request) (inscribeRequest, error) {
+	var req inscribeRequest
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "application/json"):
+		r.Body = http.MaxBytesReader(w, r.Body, maxInscribeJSONBytes())
+		defer r.Body.Close()
+		bodyBytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return req, errInscribeBodyTooLarge
+			}
+			return req, errors.New("Failed to read request body")
+		}
+		if err := json.Unmarshal(bodyBytes, &req); err != nil {
+			return req, errors.New("Invalid JSON")
+		}
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return req, errors.New("Invalid multipart form")
+		}
+		req.Message = r.FormValue("message")
+		req.Text = r.FormValue("text")
+		req.Method = r.FormValue("method")
+		req.Price = r.FormValue("price")
+		req.PriceUnit = r.FormValue("price_unit")
+		req.Address = r.FormValue("address")
+		req.FundingMode = r.FormValue("funding_mode")
+		req.ImageBase64 = r.FormValue("image_base64")
+		req.Filename = r.FormValue("filename")
+		req.SkipProposal, _ = coerce.Bool(r.FormValue("skip_proposal"))
+
+		file, header, err := r.FormFile("image")
+		if err == nil {
+			defer file.Close()
+			// Read one byte past the limit so oversized uploads are reported
+			// as such by validateInscribeImage without buffering all of them.
+			if req.image, err = io.ReadAll(io.LimitReader(file, maxInscribeImageBytes()+1)); err != nil {
+				return req, errors.New("Failed to read image upload")
+			}
+			if req.Filename == "" {
+				req.Filename = header.Filename
+			}
+		} else if !errors.Is(err, http.ErrMissingFile) {
+			return req, errors.New("Invalid image upload")
+		}
+	default:
+		return req, errors.New("Content-Type must be application/json or multipart/form-data")
+	}
+
+	if len(req.image) == 0 && req.ImageBase64 != "" {
+		img, err := base64.StdEncoding.DecodeString(req.ImageBase64)
+		if err != nil {
+			return req, errors.New("Invalid base64 image")
+		}
+		req.image = img
+	}
+	return req, nil
+}
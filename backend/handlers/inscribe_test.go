@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	scmiddleware "stargate-backend/middleware/smart_contract"
+	"stargate-backend/services"
+	"stargate-backend/stego"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+// TestMain points the data directory at a temp dir for the whole package:
+// background sync goroutines started by the smart contract server can outlive
+// a test's t.Setenv and would otherwise create data/ under this directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "handlers-data-")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("STARGATE_DATA_DIR", dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func testCoverPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 4), G: uint8(y * 4), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode cover: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func newTestInscriptionHandler(t *testing.T) (*InscriptionHandler, *scstore.MemoryStore, *services.IngestionService, string) {
+	t.Helper()
+	uploads := t.TempDir()
+	t.Setenv("UPLOADS_DIR", uploads)
+	t.Setenv("STARGATE_PROXY_BASE", "")
+	t.Setenv("IPFS_INGEST_SYNC_ENABLED", "false")
+	ingest, err := services.NewIngestionService(filepath.Join(t.TempDir(), "ingestion.db"))
+	if err != nil {
+		t.Fatalf("init ingestion service: %v", err)
+	}
+	store := scstore.NewMemoryStore(time.Hour)
+	h := NewInscriptionHandler(nil, ingest, nil, nil)
+	h.SetStore(store)
+	return h, store, ingest, uploads
+}
+
+// assertPendingInscription checks the response names a visible pixel hash
+// equal to the SHA-256 of the stored stego image, and that the wish was
+// recorded as a pending ingestion and proposal keyed by that hash.
+func assertPendingInscription(t *testing.T, rec *httptest.ResponseRecorder, store *scstore.MemoryStore, ingest *services.IngestionService, uploads string) {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Success bool              `json:"success"`
+		Data    map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	hash := resp.Data["visible_pixel_hash"]
+	if !resp.Success || len(hash) != 64 {
+		t.Fatalf("response = %s", rec.Body.String())
+	}
+
+	stored, err := os.ReadFile(filepath.Join(uploads, hash))
+	if err != nil {
+		t.Fatalf("stego image not written: %v", err)
+	}
+	sum := sha256.Sum256(stored)
+	if got := hex.EncodeToString(sum[:]); got != hash {
+		t.Fatalf("visible_pixel_hash = %s, want sha256 of stored image %s", hash, got)
+	}
+
+	ingRec, err := ingest.Get(hash)
+	if err != nil {
+		t.Fatalf("ingestion record missing: %v", err)
+	}
+	if ingRec.Status != "pending" {
+		t.Fatalf("ingestion status = %q, want pending", ingRec.Status)
+	}
+	// Reconciliation and PSBT commitment read the stored hash, falling back to
+	// hashing the stored image; both must agree with the response.
+	if got, _ := ingRec.Metadata["visible_pixel_hash"].(string); got != hash {
+		t.Fatalf("ingestion visible_pixel_hash = %q, want %q", got, hash)
+	}
+	recImage, err := base64.StdEncoding.DecodeString(ingRec.ImageBase64)
+	if err != nil {
+		t.Fatalf("decode ingestion image: %v", err)
+	}
+	if got := stego.VisiblePixelHash(recImage); got != hash {
+		t.Fatalf("ingestion image hashes to %s, want %s", got, hash)
+	}
+	proposal, err := store.GetProposal(context.Background(), hash)
+	if err != nil {
+		t.Fatalf("proposal missing: %v", err)
+	}
+	if proposal.Status != "pending" || proposal.VisiblePixelHash != hash {
+		t.Fatalf("proposal = %+v", proposal)
+	}
+}
+
+func TestHandleCreateInscriptionJSON(t *testing.T) {
+	h, store, ingest, uploads := newTestInscriptionHandler(t)
+	body, _ := json.Marshal(map[string]string{
+		"message":      "Build a JSON wish",
+		"image_base64": base64.StdEncoding.EncodeToString(testCoverPNG(t)),
+		"price":        "1500",
+		"price_unit":   "sats",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/inscribe", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.HandleCreateInscription(rec, req)
+	assertPendingInscription(t, rec, store, ingest, uploads)
+}
+
+func TestHandleCreateInscriptionMultipart(t *testing.T) {
+	h, store, ingest, uploads := newTestInscriptionHandler(t)
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("text", "Build a multipart wish")
+	writer.WriteField("price", "0.0001")
+	part, _ := writer.CreateFormFile("image", "cover.png")
+	part.Write(testCoverPNG(t))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/inscribe", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	h.HandleCreateInscription(rec, req)
+	assertPendingInscription(t, rec, store, ingest, uploads)
+}
+
+func TestHandleCreateInscriptionRejectsBadBodies(t *testing.T) {
+	h, _, _, _ := newTestInscriptionHandler(t)
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"plain text", "text/plain", "hello"},
+		{"invalid json", "application/json", "{"},
+		{"missing message", "application/json", `{"image_base64":""}`},
+		{"bad base64", "application/json", `{"message":"m","image_base64":"***"}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/inscribe", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			rec := httptest.NewRecorder()
+			h.HandleCreateInscription(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestInscribeIngestionFeedsProposalCreation(t *testing.T) {
+	h, store, ingest, _ := newTestInscriptionHandler(t)
+	body, _ := json.Marshal(map[string]interface{}{
+		"message":       "# Ingestion wish\n\n* Build the thing",
+		"image_base64":  base64.StdEncoding.EncodeToString(testCoverPNG(t)),
+		"skip_proposal": true,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/inscribe", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.HandleCreateInscription(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("inscribe status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	hash := resp.Data["visible_pixel_hash"]
+
+	ingRec, err := ingest.Get(hash)
+	if err != nil {
+		t.Fatalf("ingestion record not retrievable by visible_pixel_hash: %v", err)
+	}
+	if ingRec.ImageBase64 == "" {
+		t.Fatal("ingestion record has no image")
+	}
+	if msg, _ := ingRec.Metadata["embedded_message"].(string); !strings.HasPrefix(msg, "# Ingestion wish") {
+		t.Fatalf("embedded_message = %q", msg)
+	}
+
+	// create_proposal with only the ingestion id must resolve the record.
+	mux := http.NewServeMux()
+	scmiddleware.NewServer(store, nil, ingest).RegisterRoutes(mux)
+	req = httptest.NewRequest(http.MethodPost, "/api/smart_contract/proposals", strings.NewReader(`{"ingestion_id":"`+hash+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create proposal from ingestion status = %d: %s", rec.Code, rec.Body.String())
+	}
+	proposal, err := store.GetProposal(context.Background(), "proposal-"+hash)
+	if err != nil {
+		t.Fatalf("proposal not stored: %v", err)
+	}
+	if proposal.VisiblePixelHash != hash {
+		t.Fatalf("proposal visible_pixel_hash = %q, want %q", proposal.VisiblePixelHash, hash)
+	}
+}
+
+func TestHandleCreateInscriptionIgnoresTraversalFilename(t *testing.T) {
+	h, _, _, uploads := newTestInscriptionHandler(t)
+	cover := testCoverPNG(t)
+	var proxiedName string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, header, err := r.FormFile("image")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		proxiedName = header.Filename
+		json.NewEncoder(w).Encode(map[string]string{
+			"request_id":   "req-1",
+			"image_sha256": stego.VisiblePixelHash(cover),
+			"image_base64": base64.StdEncoding.EncodeToString(cover),
+		})
+	}))
+	defer proxy.Close()
+	h.proxyBase = proxy.URL
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("message", "Traversal wish")
+	part, _ := writer.CreatePart(map[string][]string{
+		"Content-Disposition": {`form-data; name="image"; filename="../../etc/foo"`},
+		"Content-Type":        {"image/png"},
+	})
+	part.Write(cover)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/inscribe", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	h.HandleCreateInscription(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	sum := sha256.Sum256(cover)
+	if want := hex.EncodeToString(sum[:]) + ".png"; proxiedName != want {
+		t.Fatalf("proxied filename = %q, want %q", proxiedName, want)
+	}
+	if _, err := os.Stat(filepath.Join(uploads, "../../etc/foo")); !os.IsNotExist(err) {
+		t.Fatalf("upload escaped the uploads directory: %v", err)
+	}
+}
+
+func TestHandleCreateInscriptionRejectsInvalidImages(t *testing.T) {
+	h, _, _, _ := newTestInscriptionHandler(t)
+	t.Setenv("STARGATE_MAX_IMAGE_BYTES", "2048")
+	large := make([]byte, 4096)
+	copy(large, testCoverPNG(t))
+	// A tiny PNG whose header claims 100000x100000 pixels: decoding it in full
+	// would allocate tens of gigabytes.
+	bomb := testCoverPNG(t)
+	binary.BigEndian.PutUint32(bomb[16:20], 100000)
+	binary.BigEndian.PutUint32(bomb[20:24], 100000)
+	binary.BigEndian.PutUint32(bomb[29:33], crc32.ChecksumIEEE(bomb[12:29]))
+
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		code     string
+	}{
+		{"text posing as png", "cover.png", []byte("just some text, not an image\n"), ErrCodeInvalidImage},
+		{"over size limit", "cover.png", large, ErrCodeImageTooLarge},
+		{"declared dimensions over pixel limit", "cover.png", bomb, ErrCodeImageTooLarge},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer := multipart.NewWriter(&buf)
+			writer.WriteField("message", "Bad image wish")
+			part, _ := writer.CreateFormFile("image", tc.filename)
+			part.Write(tc.data)
+			writer.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/inscribe", &buf)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			rec := httptest.NewRecorder()
+			h.HandleCreateInscription(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+			var resp struct {
+				Error struct {
+					Error struct {
+						Code string `json:"code"`
+					} `json:"error"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.Error.Error.Code != tc.code {
+				t.Fatalf("error code = %q, want %q: %s", resp.Error.Error.Code, tc.code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleCreateInscriptionRejectsOversizedJSONBody(t *testing.T) {
+	h, _, _, _ := newTestInscriptionHandler(t)
+	t.Setenv("STARGATE_MAX_IMAGE_BYTES", "2048")
+	body := `{"message":"Big wish","image_base64":"` + strings.Repeat("A", int(maxInscribeJSONBytes())) + `"}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/inscribe", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.HandleCreateInscription(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), ErrCodeImageTooLarge) {
+		t.Fatalf("body missing %s: %s", ErrCodeImageTooLarge, rec.Body.String())
+	}
+}
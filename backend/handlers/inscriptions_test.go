@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	"stargate-backend/models"
+)
+
+func getInscriptionsPage(t *testing.T, h *InscriptionHandler, query string) (int, models.PendingTransactionsResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.HandleGetInscriptions(rec, httptest.NewRequest(http.MethodGet, "/api/inscriptions"+query, nil))
+	var resp struct {
+		Data models.PendingTransactionsResponse `json:"data"`
+	}
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return rec.Code, resp.Data
+}
+
+func TestHandleGetInscriptionsPagination(t *testing.T) {
+	h, store, _, _ := newTestInscriptionHandler(t)
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		c := smart_contract.Contract{
+			ContractID: fmt.Sprintf("page-contract-%d", i),
+			Title:      "Page",
+			Status:     "active",
+			CreatedAt:  base.Add(time.Duration(i) * time.Minute),
+		}
+		if err := store.UpsertContractWithTasks(context.Background(), c, nil); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+
+	code, all := getInscriptionsPage(t, h, "")
+	if code != http.StatusOK || all.Total < 3 || len(all.Transactions) != all.Total || all.NextOffset != nil {
+		t.Fatalf("unpaginated: code %d, total %d, items %d, next %v", code, all.Total, len(all.Transactions), all.NextOffset)
+	}
+
+	code, first := getInscriptionsPage(t, h, "?limit=2")
+	if code != http.StatusOK || len(first.Transactions) != 2 || first.Total != all.Total {
+		t.Fatalf("first page: code %d, %d items of %d", code, len(first.Transactions), first.Total)
+	}
+	if first.NextOffset == nil || *first.NextOffset != 2 {
+		t.Fatalf("first page next_offset = %v, want 2", first.NextOffset)
+	}
+	for i, item := range first.Transactions {
+		if item.ID != all.Transactions[i].ID {
+			t.Fatalf("first page item %d = %s, want %s", i, item.ID, all.Transactions[i].ID)
+		}
+	}
+
+	code, last := getInscriptionsPage(t, h, fmt.Sprintf("?limit=1000&offset=%d", all.Total-1))
+	if code != http.StatusOK || len(last.Transactions) != 1 || last.NextOffset != nil {
+		t.Fatalf("last page: code %d, %d items, next %v", code, len(last.Transactions), last.NextOffset)
+	}
+
+	_, asc := getInscriptionsPage(t, h, "?order=asc")
+	for i := 1; i < len(asc.Transactions); i++ {
+		if asc.Transactions[i].Timestamp < asc.Transactions[i-1].Timestamp {
+			t.Fatalf("order=asc not ascending at %d", i)
+		}
+	}
+
+	for _, query := range []string{"?limit=ten", "?offset=-1", "?order=newest"} {
+		if code, _ := getInscriptionsPage(t, h, query); code != http.StatusBadRequest {
+			t.Fatalf("%s: code = %d, want 400", query, code)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"stargate-backend/models"
+	"stargate-backend/services"
+	"stargate-backend/storage/auth"
+)
+
+func TestHandleDeletePendingTransaction(t *testing.T) {
+	uploads := t.TempDir()
+	t.Setenv("UPLOADS_DIR", uploads)
+	inscriptionsFile := filepath.Join(t.TempDir(), "inscriptions.json")
+	svc := services.NewInscriptionService(inscriptionsFile)
+	h := NewInscriptionHandler(svc, nil, nil, nil)
+
+	created, err := svc.CreateInscription(models.InscribeRequest{Text: "cancel me", Price: "1000"}, strings.NewReader("png"), "wish.png")
+	if err != nil {
+		t.Fatalf("create inscription: %v", err)
+	}
+	if _, err := os.Stat(created.ImageData); err != nil {
+		t.Fatalf("uploaded image missing: %v", err)
+	}
+
+	del := func(id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.HandleDeletePendingTransaction(rec, httptest.NewRequest(http.MethodDelete, "/api/pending-transactions/"+id, nil))
+		return rec
+	}
+
+	rec := del(created.ID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete: status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data models.InscriptionRequest `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Data.ID != created.ID || resp.Data.Text != "cancel me" {
+		t.Fatalf("removed record = %+v, want %s", resp.Data, created.ID)
+	}
+	if _, err := os.Stat(created.ImageData); !os.IsNotExist(err) {
+		t.Fatalf("uploaded image still present: %v", err)
+	}
+	if remaining, _ := svc.GetAllInscriptions(); len(remaining) != 0 {
+		t.Fatalf("remaining inscriptions = %+v, want none", remaining)
+	}
+
+	if rec := del(created.ID); rec.Code != http.StatusNotFound {
+		t.Fatalf("second delete: status %d, want 404", rec.Code)
+	}
+
+	mined, _ := json.Marshal([]models.InscriptionRequest{{ID: "pending_mined", Status: "confirmed"}})
+	if err := os.WriteFile(inscriptionsFile, mined, 0644); err != nil {
+		t.Fatalf("write inscriptions: %v", err)
+	}
+	if rec := del("pending_mined"); rec.Code != http.StatusForbidden {
+		t.Fatalf("delete confirmed: status %d, want 403", rec.Code)
+	}
+}
+
+func TestHandleDeletePendingTransactionRequiresOwner(t *testing.T) {
+	t.Setenv("UPLOADS_DIR", t.TempDir())
+	t.Setenv("STARLIGHT_DONATION_ADDRESS", "")
+	svc := services.NewInscriptionService(filepath.Join(t.TempDir(), "inscriptions.json"))
+	keys := auth.NewAPIKeyStore()
+	owner, _ := keys.Issue("owner@example.com", "bc1qowner", "test")
+	other, _ := keys.Issue("other@example.com", "bc1qother", "test")
+	h := NewInscriptionHandler(svc, nil, nil, keys)
+
+	created, err := svc.CreateInscription(models.InscribeRequest{Text: "mine", Price: "1000", Address: "BC1QOWNER"}, strings.NewReader("png"), "wish.png")
+	if err != nil {
+		t.Fatalf("create inscription: %v", err)
+	}
+	del := func(apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/pending-transactions/"+created.ID, nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		rec := httptest.NewRecorder()
+		h.HandleDeletePendingTransaction(rec, req)
+		return rec
+	}
+
+	for name, key := range map[string]string{"other wallet": other.Key, "no key": ""} {
+		if rec := del(key); rec.Code != http.StatusForbidden {
+			t.Fatalf("%s: status %d, want 403: %s", name, rec.Code, rec.Body.String())
+		}
+	}
+	if remaining, _ := svc.GetAllInscriptions(); len(remaining) != 1 {
+		t.Fatalf("denied delete removed the inscription: %+v", remaining)
+	}
+
+	if rec := del(owner.Key); rec.Code != http.StatusOK {
+		t.Fatalf("owner: status %d: %s", rec.Code, rec.Body.String())
+	}
+}
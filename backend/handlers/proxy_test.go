@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stargate-backend/middleware"
+)
+
+func TestHandleProxyForwardsRequestID(t *testing.T) {
+	var upstreamID string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamID = r.Header.Get(middleware.RequestIDHeader)
+		w.Header().Set(middleware.RequestIDHeader, "upstream-own-id")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer upstream.Close()
+
+	h := middleware.RequestID(http.HandlerFunc(NewProxyHandler(upstream.URL).HandleProxy))
+
+	for _, inbound := range []string{"", "client-trace-7"} {
+		req := httptest.NewRequest(http.MethodGet, "/stego/info", nil)
+		if inbound != "" {
+			req.Header.Set(middleware.RequestIDHeader, inbound)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		got := rec.Header().Values(middleware.RequestIDHeader)
+		if upstreamID == "" || len(got) != 1 || got[0] != upstreamID {
+			t.Fatalf("inbound %q: response ids %v, upstream saw %q", inbound, got, upstreamID)
+		}
+		if inbound != "" && upstreamID != inbound {
+			t.Fatalf("upstream saw %q, want %q", upstreamID, inbound)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"stargate-backend/services"
+)
+
+// MonitorStatistics is the part of the block monitor the stats endpoint reads.
+type MonitorStatistics interface {
+	GetStatistics() map[string]any
+}
+
+// StatsHandler serves GET /api/stats, an operator summary of the block
+// monitor, the local inscription queue and smart contracts.
+type StatsHandler struct {
+	*BaseHandler
+	inscriptionService *services.InscriptionService
+	contractService    *services.SmartContractService
+	monitor            MonitorStatistics
+	startedAt          time.Time
+}
+
+// NewStatsHandler creates a stats handler; uptime is measured from this call.
+// monitor may be nil when block monitoring is disabled.
+func NewStatsHandler(inscriptionService *services.InscriptionService, contractService *services.SmartContractService, monitor MonitorStatistics) *StatsHandler {
+	return &StatsHandler{
+		BaseHandler:        NewBaseHandler(),
+		inscriptionService: inscriptionService,
+		contractService:    contractService,
+		monitor:            monitor,
+		startedAt:          time.Now(),
+	}
+}
+
+// HandleGetStats returns the monitor statistics (current_height,
+// last_process_time in milliseconds, block/image/inscription totals) merged
+// with pending_inscriptions, smart_contracts and the server uptime.
+func (h *StatsHandler) HandleGetStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		h.sendError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	stats := map[string]any{}
+	if h.monitor != nil {
+		for k, v := range h.monitor.GetStatistics() {
+			stats[k] = v
+		}
+	}
+	stats["monitor_enabled"] = h.monitor != nil
+
+	pending := 0
+	if h.inscriptionService != nil {
+		inscriptions, err := h.inscriptionService.GetAllInscriptions()
+		if err != nil {
+			h.sendError(w, http.StatusInternalServerError, "Failed to load inscriptions")
+			return
+		}
+		for _, ins := range inscriptions {
+			if strings.EqualFold(ins.Status, "pending") {
+				pending++
+			}
+		}
+	}
+	stats["pending_inscriptions"] = pending
+
+	contracts := 0
+	if h.contractService != nil {
+		all, err := h.contractService.GetAllContracts()
+		if err != nil {
+			h.sendError(w, http.StatusInternalServerError, "Failed to load smart contracts")
+			return
+		}
+		contracts = len(all)
+	}
+	stats["smart_contracts"] = contracts
+
+	stats["started_at"] = h.startedAt.UTC().Format(time.RFC3339)
+	stats["uptime_seconds"] = int64(time.Since(h.startedAt).Seconds())
+
+	h.sendSuccess(w, stats)
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stargate-backend/services"
+)
+
+type fakeMonitorStats map[string]any
+
+func (f fakeMonitorStats) GetStatistics() map[string]any { return f }
+
+func TestHandleGetStats(t *testing.T) {
+	dir := t.TempDir()
+	inscriptions := filepath.Join(dir, "inscriptions.json")
+	contracts := filepath.Join(dir, "contracts.json")
+	os.WriteFile(inscriptions, []byte(`[{"id":"a","status":"pending"},{"id":"b","status":"pending"},{"id":"c","status":"confirmed"}]`), 0644)
+	os.WriteFile(contracts, []byte(`[{"contract_id":"x"}]`), 0644)
+
+	h := NewStatsHandler(
+		services.NewInscriptionService(inscriptions),
+		services.NewSmartContractService(contracts),
+		fakeMonitorStats{"current_height": 812, "last_process_time": 1500, "blocks_processed": 3},
+	)
+	rec := httptest.NewRecorder()
+	h.HandleGetStats(rec, httptest.NewRequest(http.MethodGet, "/api/stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := map[string]float64{
+		"current_height":       812,
+		"last_process_time":    1500,
+		"blocks_processed":     3,
+		"pending_inscriptions": 2,
+		"smart_contracts":      1,
+	}
+	for k, v := range want {
+		if got, _ := resp.Data[k].(float64); got != v {
+			t.Errorf("%s = %v, want %v", k, resp.Data[k], v)
+		}
+	}
+	if _, ok := resp.Data["uptime_seconds"].(float64); !ok {
+		t.Errorf("uptime_seconds missing: %v", resp.Data)
+	}
+	if resp.Data["monitor_enabled"] != true {
+		t.Errorf("monitor_enabled = %v", resp.Data["monitor_enabled"])
+	}
+}
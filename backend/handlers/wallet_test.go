@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	auth "stargate-backend/storage/auth"
+)
+
+func newTestWallet(t *testing.T, params *chaincfg.Params) (*btcec.PrivateKey, string) {
+	t.Helper()
+	priv, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(priv.PubKey().SerializeCompressed()), params)
+	if err != nil {
+		t.Fatalf("build address: %v", err)
+	}
+	return priv, addr.EncodeAddress()
+}
+
+func TestHandleWalletBindAndRebind(t *testing.T) {
+	t.Setenv("BITCOIN_NETWORK", "testnet4")
+	store := auth.NewAPIKeyStore()
+	const apiKey = "wallet-test-key"
+	store.Seed(apiKey, "agent@example.com", "test")
+	challenges := auth.NewChallengeStore(time.Minute)
+	handler := NewAPIKeyHandler(store, store, challenges)
+
+	call := func(method string, body map[string]string) (int, map[string]interface{}) {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, "/api/auth/wallet", &buf)
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		handler.HandleWallet(rec, req)
+		var resp struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp.Data
+	}
+
+	if code, data := call(http.MethodGet, nil); code != http.StatusOK || data["bound"] != false {
+		t.Fatalf("initial GET = %d %v, want unbound", code, data)
+	}
+
+	sign := func(priv *btcec.PrivateKey, wallet string) string {
+		ch, err := challenges.Issue(wallet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.StdEncoding.EncodeToString(ecdsa.SignCompact(priv, hashBitcoinMessage(ch.Nonce), true))
+	}
+
+	// Bind needs a signature proving control of the wallet.
+	firstKey, first := newTestWallet(t, &chaincfg.TestNet4Params)
+	if code, _ := call(http.MethodPost, map[string]string{"wallet_address": first}); code != http.StatusForbidden {
+		t.Fatalf("unsigned bind = %d, want 403", code)
+	}
+	if code, data := call(http.MethodPost, map[string]string{"wallet_address": first, "signature": sign(firstKey, first)}); code != http.StatusOK || data["wallet"] != first {
+		t.Fatalf("bind = %d %v", code, data)
+	}
+	if code, data := call(http.MethodGet, nil); code != http.StatusOK || data["wallet"] != first || data["bound"] != true {
+		t.Fatalf("GET after bind = %d %v", code, data)
+	}
+	if code, _ := call(http.MethodPost, map[string]string{"wallet_address": first}); code != http.StatusOK {
+		t.Fatalf("re-posting the bound wallet = %d, want 200", code)
+	}
+
+	// Re-bind needs a signature from the new wallet.
+	priv, second := newTestWallet(t, &chaincfg.TestNet4Params)
+	if code, _ := call(http.MethodPost, map[string]string{"wallet_address": second}); code != http.StatusForbidden {
+		t.Fatalf("unsigned rebind = %d, want 403", code)
+	}
+	if code, data := call(http.MethodPost, map[string]string{"wallet_address": second, "signature": sign(priv, second)}); code != http.StatusOK || data["wallet"] != second {
+		t.Fatalf("signed rebind = %d %v", code, data)
+	}
+	if rec, _ := store.Get(apiKey); rec.Wallet != second {
+		t.Fatalf("stored wallet = %q, want %q", rec.Wallet, second)
+	}
+}
+
+func TestHandleWalletRejectsForgedSignature(t *testing.T) {
+	t.Setenv("BITCOIN_NETWORK", "testnet4")
+	store := auth.NewAPIKeyStore()
+	const apiKey = "wallet-test-key"
+	store.Seed(apiKey, "agent@example.com", "test")
+	challenges := auth.NewChallengeStore(time.Minute)
+	handler := NewAPIKeyHandler(store, store, challenges)
+
+	_, victim := newTestWallet(t, &chaincfg.TestNet4Params)
+	attacker, _ := newTestWallet(t, &chaincfg.TestNet4Params)
+	ch, err := challenges.Issue(victim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := base64.StdEncoding.EncodeToString(ecdsa.SignCompact(attacker, hashBitcoinMessage(ch.Nonce), true))
+
+	for name, sig := range map[string]string{
+		"wrong key": forged,
+		"garbage":   base64.StdEncoding.EncodeToString([]byte("not a signature")),
+	} {
+		body, _ := json.Marshal(map[string]string{"wallet_address": victim, "signature": sig})
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/wallet", bytes.NewReader(body))
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		handler.HandleWallet(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("%s: status = %d, want 403", name, rec.Code)
+		}
+	}
+	if rec, _ := store.Get(apiKey); rec.Wallet != "" {
+		t.Fatalf("forged signature bound wallet %q", rec.Wallet)
+	}
+
+	// Login no longer binds a wallet as a side effect.
+	body, _ := json.Marshal(map[string]string{"api_key": apiKey, "wallet_address": victim})
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/login", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.HandleLogin(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if stored, _ := store.Get(apiKey); stored.Wallet != "" {
+		t.Fatalf("login bound wallet %q", stored.Wallet)
+	}
+}
+
+func TestHandleWalletRejectsInvalidAddress(t *testing.T) {
+	t.Setenv("BITCOIN_NETWORK", "testnet4")
+	store := auth.NewAPIKeyStore()
+	const apiKey = "wallet-test-key"
+	store.Seed(apiKey, "agent@example.com", "test")
+	handler := NewAPIKeyHandler(store, store, auth.NewChallengeStore(time.Minute))
+
+	_, mainnet := newTestWallet(t, &chaincfg.MainNetParams)
+	for _, wallet := range []string{"not-a-wallet", mainnet, ""} {
+		body, _ := json.Marshal(map[string]string{"wallet_address": wallet})
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/wallet", bytes.NewReader(body))
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		handler.HandleWallet(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("wallet %q: status = %d, want 400", wallet, rec.Code)
+		}
+	}
+	if rec, _ := store.Get(apiKey); rec.Wallet != "" {
+		t.Fatalf("invalid address was bound: %q", rec.Wallet)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/wallet", nil)
+	rec := httptest.NewRecorder()
+	handler.HandleWallet(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("missing key status = %d, want 403", rec.Code)
+	}
+}
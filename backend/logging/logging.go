@@ -0,0 +1,104 @@
+// Package logging adds levels to the standard logger. Output still goes
+// through package log, so existing log.SetOutput/SetFlags settings apply.
+// The level comes from LOG_LEVEL (debug, info, warn, error; default info).
+package logging
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Level orders log severities; a message is written when its level is at or
+// above the configured one.
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// prefixes keep the "DEBUG: " / "WARNING: " / "ERROR: " markers the code
+// used before levels existed, so log scrapers see the same lines.
+var prefixes = map[Level]string{
+	LevelDebug: "DEBUG: ",
+	LevelInfo:  "",
+	LevelWarn:  "WARNING: ",
+	LevelError: "ERROR: ",
+}
+
+var current atomic.Int32
+
+func init() {
+	SetLevel(LevelFromEnv())
+}
+
+// ParseLevel parses a level name, case-insensitively. "warning" is accepted
+// for warn.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+// LevelFromEnv returns the level named by LOG_LEVEL, or info when it is unset
+// or invalid.
+func LevelFromEnv() Level {
+	raw := os.Getenv("LOG_LEVEL")
+	if strings.TrimSpace(raw) == "" {
+		return LevelInfo
+	}
+	level, err := ParseLevel(raw)
+	if err != nil {
+		log.Printf("Ignoring invalid LOG_LEVEL=%q, using info", raw)
+		return LevelInfo
+	}
+	return level
+}
+
+// SetLevel changes the minimum level written.
+func SetLevel(level Level) {
+	current.Store(int32(level))
+}
+
+// GetLevel returns the minimum level written.
+func GetLevel() Level {
+	return Level(current.Load())
+}
+
+// Enabled reports whether messages at level are written. Use it to skip
+// building expensive debug output.
+func Enabled(level Level) bool {
+	return level >= GetLevel()
+}
+
+func output(level Level, format string, args ...any) {
+	if !Enabled(level) {
+		return
+	}
+	log.Output(3, prefixes[level]+fmt.Sprintf(format, args...))
+}
+
+// Debugf logs diagnostic detail, hidden unless LOG_LEVEL=debug.
+func Debugf(format string, args ...any) { output(LevelDebug, format, args...) }
+
+// Infof logs normal operational messages.
+func Infof(format string, args ...any) { output(LevelInfo, format, args...) }
+
+// Warnf logs recoverable problems.
+func Warnf(format string, args ...any) { output(LevelWarn, format, args...) }
+
+// Errorf logs failures.
+func Errorf(format string, args ...any) { output(LevelError, format, args...) }
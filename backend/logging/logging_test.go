@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prevOut, prevFlags, prevLevel := log.Writer(), log.Flags(), GetLevel()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(prevOut)
+		log.SetFlags(prevFlags)
+		SetLevel(prevLevel)
+	})
+	return &buf
+}
+
+func TestDebugSuppressedAtInfo(t *testing.T) {
+	buf := captureLog(t)
+	SetLevel(LevelInfo)
+
+	Debugf("callToolDirect called with tool %s", "list_contracts")
+	Infof("server started")
+	Warnf("slow upstream")
+
+	out := buf.String()
+	if strings.Contains(out, "callToolDirect") {
+		t.Fatalf("debug line written at info level: %q", out)
+	}
+	if out != "server started\nWARNING: slow upstream\n" {
+		t.Fatalf("output = %q", out)
+	}
+}
+
+func TestDebugWrittenAtDebug(t *testing.T) {
+	buf := captureLog(t)
+	SetLevel(LevelDebug)
+
+	Debugf("uploadsDir resolved to: %s", "/data/uploads")
+	if got := buf.String(); got != "DEBUG: uploadsDir resolved to: /data/uploads\n" {
+		t.Fatalf("output = %q", got)
+	}
+}
+
+func TestErrorLevelHidesWarnings(t *testing.T) {
+	buf := captureLog(t)
+	SetLevel(LevelError)
+
+	Infof("info")
+	Warnf("warn")
+	Errorf("boom")
+	if got := buf.String(); got != "ERROR: boom\n" {
+		t.Fatalf("output = %q", got)
+	}
+}
+
+func TestLevelFromEnv(t *testing.T) {
+	captureLog(t)
+	cases := map[string]Level{
+		"":        LevelInfo,
+		"debug":   LevelDebug,
+		"WARNING": LevelWarn,
+		" error ": LevelError,
+		"verbose": LevelInfo,
+	}
+	for raw, want := range cases {
+		t.Setenv("LOG_LEVEL", raw)
+		if got := LevelFromEnv(); got != want {
+			t.Errorf("LOG_LEVEL=%q: level %d, want %d", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"stargate-backend/middleware"
+	"stargate-backend/security"
+)
+
+// AuditEvent is one JSON line in the audit log.
+type AuditEvent struct {
+	Time           time.Time `json:"time"`
+	Event          string    `json:"event"`
+	Method         string    `json:"method"`
+	Path           string    `json:"path"`
+	RemoteAddr     string    `json:"remote_addr,omitempty"`
+	KeyFingerprint string    `json:"key_fingerprint,omitempty"`
+	RequestID      string    `json:"request_id,omitempty"`
+}
+
+// AuditLogger writes audit events as JSON lines to its own sink, apart from
+// the application log.
+type AuditLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewAuditLogger returns an audit logger writing to w; a nil w discards.
+func NewAuditLogger(w io.Writer) *AuditLogger {
+	if w == nil {
+		w = io.Discard
+	}
+	return &AuditLogger{w: w}
+}
+
+// NewAuditLoggerFromEnv configures the sink from STARGATE_AUDIT_LOG: empty or
+// "stdout", "stderr", "off", or a file path opened for appending.
+func NewAuditLoggerFromEnv() (*AuditLogger, error) {
+	sink := strings.TrimSpace(os.Getenv("STARGATE_AUDIT_LOG"))
+	switch strings.ToLower(sink) {
+	case "", "stdout":
+		return NewAuditLogger(os.Stdout), nil
+	case "stderr":
+		return NewAuditLogger(os.Stderr), nil
+	case "off", "none":
+		return NewAuditLogger(io.Discard), nil
+	}
+	f, err := os.OpenFile(sink, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log %s: %w", sink, err)
+	}
+	return NewAuditLogger(f), nil
+}
+
+// Record writes an audit event for r. key is reduced to its fingerprint.
+func (a *AuditLogger) Record(r *http.Request, event, key string) {
+	if a == nil {
+		return
+	}
+	entry := AuditEvent{
+		Time:           time.Now().UTC(),
+		Event:          event,
+		Method:         r.Method,
+		Path:           r.URL.Path,
+		RemoteAddr:     r.RemoteAddr,
+		KeyFingerprint: security.APIKeyFingerprint(key),
+		RequestID:      middleware.RequestIDFromContext(r.Context()),
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(append(line, '\n')); err != nil {
+		log.Printf("audit log write failed: %v", err)
+	}
+}
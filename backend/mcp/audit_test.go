@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/services"
+	"stargate-backend/starlight"
+	"stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestAuthWrapWritesRedactedAuditEntries(t *testing.T) {
+	const apiKey = "sk-live-0123456789abcdef"
+
+	var appLog bytes.Buffer
+	prevOut, prevFlags := log.Writer(), log.Flags()
+	log.SetOutput(&appLog)
+	defer func() {
+		log.SetOutput(prevOut)
+		log.SetFlags(prevFlags)
+	}()
+
+	var auditLog bytes.Buffer
+	server := NewHTTPMCPServer(scstore.NewMemoryStore(time.Hour), walletValidator{}, nil, &services.IngestionService{}, &starlight.ScannerManager{}, nil, auth.NewChallengeStore(10*time.Minute))
+	server.SetAuditLogger(NewAuditLogger(&auditLog))
+
+	handler := server.authWrap(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("debug: handling %s", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp/tools", nil)
+	req.Header.Set("X-API-Key", apiKey)
+	handler(httptest.NewRecorder(), req)
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/mcp/tools", nil))
+
+	var events []AuditEvent
+	scanner := bufio.NewScanner(&auditLog)
+	for scanner.Scan() {
+		var ev AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			t.Fatalf("audit line %q is not JSON: %v", scanner.Text(), err)
+		}
+		events = append(events, ev)
+	}
+	var names []string
+	for _, ev := range events {
+		names = append(names, ev.Event)
+	}
+	if got := strings.Join(names, ","); got != "request,authenticated,request,api_key_missing" {
+		t.Fatalf("audit events %s", got)
+	}
+	if events[1].KeyFingerprint != "sk-l...cdef" || events[1].Path != "/mcp/tools" {
+		t.Fatalf("authenticated event %+v", events[1])
+	}
+
+	if strings.Contains(auditLog.String(), apiKey) || strings.Contains(appLog.String(), apiKey) {
+		t.Fatal("raw API key was logged")
+	}
+	if strings.Contains(appLog.String(), "AUDIT") {
+		t.Fatalf("audit entries leaked into the application log: %s", appLog.String())
+	}
+	if strings.Contains(auditLog.String(), "debug:") {
+		t.Fatal("debug output reached the audit log")
+	}
+}
+
+func TestAuthWrapNeverLogsFullAPIKey(t *testing.T) {
+	const apiKey = "sk-live-fedcba9876543210"
+
+	var captured bytes.Buffer
+	prevOut := log.Writer()
+	log.SetOutput(&captured)
+	defer log.SetOutput(prevOut)
+
+	validator := &multiKeyWalletValidator{wallets: map[string]string{apiKey: "tb1qexample"}}
+	server := NewHTTPMCPServer(scstore.NewMemoryStore(time.Hour), validator, nil, &services.IngestionService{}, &starlight.ScannerManager{}, nil, auth.NewChallengeStore(10*time.Minute))
+	server.SetAuditLogger(NewAuditLogger(&captured))
+	handler := server.authWrap(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	send := func(key string) int {
+		req := httptest.NewRequest(http.MethodPost, "/mcp/call", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	// Authenticated until the per-key limit, then rate limited.
+	rateLimited := false
+	for i := 0; i < 101; i++ {
+		if send(apiKey) == http.StatusTooManyRequests {
+			rateLimited = true
+		}
+	}
+	if !rateLimited {
+		t.Fatal("rate limit path not exercised")
+	}
+	const wrongKey = "sk-live-not-a-real-key-000"
+	if code := send(wrongKey); code != http.StatusForbidden {
+		t.Fatalf("invalid key status = %d, want 403", code)
+	}
+
+	out := captured.String()
+	for _, secret := range []string{apiKey, wrongKey} {
+		if strings.Contains(out, secret) {
+			t.Fatalf("full API key %q found in log output", secret)
+		}
+	}
+	for _, event := range []string{`"authenticated"`, `"rate_limited"`, `"api_key_invalid"`} {
+		if !strings.Contains(out, event) {
+			t.Fatalf("log output has no %s event", event)
+		}
+	}
+	if !strings.Contains(out, "sk-l...3210") {
+		t.Fatal("log output has no fingerprint for the valid key")
+	}
+}
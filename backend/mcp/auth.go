@@ -2,39 +2,50 @@ package mcp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
-	"log"
 	"net/http"
 	"strings"
 	"time"
 )
 
-// checkRateLimit checks if the API key has exceeded rate limit (100 requests per minute)
+// checkRateLimit checks if the API key has exceeded rate limit (100 requests
+// per minute). Tool calls also go through checkToolRateLimit.
 func (h *HTTPMCPServer) checkRateLimit(key string) bool {
 	h.rateLimiterMu.Lock()
 	defer h.rateLimiterMu.Unlock()
 
-	now := time.Now()
-	window := now.Add(-time.Minute)
-	times := h.rateLimiter[key]
-	valid := make([]time.Time, 0, len(times))
-	for _, t := range times {
-		if t.After(window) {
-			valid = append(valid, t)
-		}
-	}
-	h.rateLimiter[key] = valid
-	if len(valid) >= 100 {
+	now := h.now()
+	valid := h.pruneRateLimitLocked(key, now)
+	if len(valid) >= keyRateLimit {
 		return false
 	}
-	h.rateLimiter[key] = append(h.rateLimiter[key], now)
+	h.rateLimiter[key] = append(valid, now)
 	return true
 }
 
+// purgeRateLimitWindows drops keys with no request inside the rate-limit
+// window. checkRateLimit only prunes a key when that key is used again, so
+// keys that stop calling would otherwise be kept forever.
+func (h *HTTPMCPServer) purgeRateLimitWindows(ctx context.Context, now time.Time) (int, error) {
+	h.rateLimiterMu.Lock()
+	defer h.rateLimiterMu.Unlock()
+
+	window := now.Add(-rateLimitWindow)
+	purged := 0
+	for key, times := range h.rateLimiter {
+		if len(times) == 0 || !times[len(times)-1].After(window) {
+			delete(h.rateLimiter, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
 func (h *HTTPMCPServer) authWrap(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("AUDIT: %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		h.audit.Record(r, "request", "")
 		// Check API key if configured
 		if h.apiKeyStore != nil {
 			key := r.Header.Get("X-API-Key")
@@ -50,22 +61,22 @@ func (h *HTTPMCPServer) authWrap(next http.HandlerFunc) http.HandlerFunc {
 				return
 			}
 			if key == "" {
-				log.Printf("AUDIT: Missing API key for %s %s", r.Method, r.URL.Path)
+				h.audit.Record(r, "api_key_missing", "")
 				h.writeHTTPError(w, http.StatusUnauthorized, "API_KEY_REQUIRED", "API key required", "Send X-API-Key or Authorization: Bearer <key>.")
 				return
 			}
 			if !h.apiKeyStore.Validate(key) {
-				log.Printf("AUDIT: Invalid API key for %s %s", r.Method, r.URL.Path)
+				h.audit.Record(r, "api_key_invalid", key)
 				h.writeHTTPError(w, http.StatusForbidden, "API_KEY_INVALID", "Invalid API key", "Double-check the X-API-Key header value.")
 				return
 			}
 			// Check rate limit
 			if !h.checkRateLimit(key) {
-				log.Printf("AUDIT: Rate limit exceeded for key %s on %s %s", key, r.Method, r.URL.Path)
+				h.audit.Record(r, "rate_limited", key)
 				h.writeHTTPError(w, http.StatusTooManyRequests, "RATE_LIMITED", "Rate limit exceeded", "Retry after a short delay.")
 				return
 			}
-			log.Printf("AUDIT: Authenticated request for key %s on %s %s", key, r.Method, r.URL.Path)
+			h.audit.Record(r, "authenticated", key)
 		}
 		next(w, r)
 	}
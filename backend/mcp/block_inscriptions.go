@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"stargate-backend/bitcoin"
+	"stargate-backend/coerce"
+)
+
+// blockInscriptionSource is the part of bitcoin.BlockMonitor that
+// get_block_inscriptions reads from.
+type blockInscriptionSource interface {
+	GetBlockInscriptions(height int64) (*bitcoin.BlockInscriptionsResponse, error)
+}
+
+// SetBlockMonitor gives the MCP tools access to the parsed block data the
+// monitor has written under BLOCKS_DIR.
+func (h *HTTPMCPServer) SetBlockMonitor(bm *bitcoin.BlockMonitor) {
+	if bm == nil {
+		h.blockSource = nil
+		return
+	}
+	h.blockSource = bm
+}
+
+// handleGetBlockInscriptions returns the inscriptions the block monitor
+// parsed for one block height.
+func (h *HTTPMCPServer) handleGetBlockInscriptions(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	if h.blockSource == nil {
+		return nil, NewServiceUnavailableError("get_block_inscriptions", "block monitor")
+	}
+
+	height, ok := coerce.Int64(args["height"])
+	if !ok || height < 0 {
+		return nil, NewValidationError("get_block_inscriptions", "height is required and must be a non-negative integer")
+	}
+
+	resp, err := h.blockSource.GetBlockInscriptions(height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load block inscriptions: %w", err)
+	}
+	if !resp.Success {
+		if strings.Contains(resp.Error, "not found") {
+			return nil, NewNotFoundError("get_block_inscriptions", "block", fmt.Sprintf("%d", height))
+		}
+		return nil, NewInternalError("get_block_inscriptions", resp.Error)
+	}
+
+	inscriptions := resp.Inscriptions
+	if inscriptions == nil {
+		inscriptions = []bitcoin.InscriptionData{}
+	}
+	return map[string]interface{}{
+		"block_height":       height,
+		"block_hash":         resp.BlockHash,
+		"timestamp":          resp.Timestamp,
+		"total_transactions": resp.TotalTransactions,
+		"total":              len(inscriptions),
+		"inscriptions":       inscriptions,
+	}, nil
+}
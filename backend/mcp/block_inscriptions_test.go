@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stargate-backend/bitcoin"
+	"stargate-backend/services"
+	"stargate-backend/starlight"
+	auth "stargate-backend/storage/auth"
+)
+
+type fakeInscriptionSource map[int64]*bitcoin.BlockInscriptionsResponse
+
+func (f fakeInscriptionSource) GetBlockInscriptions(height int64) (*bitcoin.BlockInscriptionsResponse, error) {
+	if resp, ok := f[height]; ok {
+		return resp, nil
+	}
+	return &bitcoin.BlockInscriptionsResponse{BlockHeight: height, Error: "Block not found"}, nil
+}
+
+func TestHandleGetBlockInscriptions(t *testing.T) {
+	server := NewHTTPMCPServer(nil, nil, nil, &services.IngestionService{}, &starlight.ScannerManager{}, nil, auth.NewChallengeStore(10*time.Minute))
+
+	if _, err := server.callToolDirect(context.Background(), "get_block_inscriptions", map[string]interface{}{"height": 1}, "", nil); err == nil {
+		t.Fatalf("expected service unavailable without a block monitor")
+	}
+
+	server.blockSource = fakeInscriptionSource{
+		100: {
+			BlockHeight: 100,
+			BlockHash:   "abc",
+			Success:     true,
+			Inscriptions: []bitcoin.InscriptionData{
+				{TxID: "tx1", ContentType: "image/png", SizeBytes: 42, FilePath: "blocks/100_abc/images/tx1.png"},
+			},
+		},
+	}
+
+	result, err := server.callToolDirect(context.Background(), "get_block_inscriptions", map[string]interface{}{"height": "100"}, "", nil)
+	if err != nil {
+		t.Fatalf("get_block_inscriptions: %v", err)
+	}
+	resp := result.(map[string]interface{})
+	inscriptions := resp["inscriptions"].([]bitcoin.InscriptionData)
+	if resp["total"] != 1 || len(inscriptions) != 1 || inscriptions[0].ContentType != "image/png" || inscriptions[0].SizeBytes != 42 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	_, err = server.callToolDirect(context.Background(), "get_block_inscriptions", map[string]interface{}{"height": 101}, "", nil)
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrCodeNotFound {
+		t.Fatalf("expected not found for unknown block, got %v", err)
+	}
+
+	_, err = server.callToolDirect(context.Background(), "get_block_inscriptions", map[string]interface{}{}, "", nil)
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected validation error for missing height, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	auth "stargate-backend/storage/auth"
+)
+
+func TestHandleDocsContentNegotiation(t *testing.T) {
+	server := NewHTTPMCPServer(nil, nil, nil, nil, nil, nil, auth.NewChallengeStore(10*time.Minute))
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/mcp/docs", nil)
+		r.Host = "api.starlight.local"
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		server.handleDocs(w, r)
+		return w
+	}
+
+	w := get("application/json")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+	var docs struct {
+		Title     string           `json:"title"`
+		Workflow  []map[string]any `json:"workflow"`
+		Endpoints []docsEndpoint   `json:"endpoints"`
+		Examples  []docsExample    `json:"examples"`
+		Tools     []ToolMetadata   `json:"tools"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &docs); err != nil {
+		t.Fatalf("decode JSON docs: %v\n%s", err, w.Body.String())
+	}
+	if docs.Title == "" || len(docs.Workflow) == 0 || len(docs.Endpoints) == 0 || len(docs.Examples) == 0 || len(docs.Tools) == 0 {
+		t.Fatalf("JSON docs missing sections: %+v", docs)
+	}
+	if !strings.HasPrefix(docs.Examples[0].URL, "http://api.starlight.local/") {
+		t.Fatalf("example URL %q does not use the request host", docs.Examples[0].URL)
+	}
+
+	for _, accept := range []string{"", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "text/html, application/json;q=0.5"} {
+		if ct := get(accept).Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+			t.Fatalf("Accept %q: Content-Type = %q, want text/html", accept, ct)
+		}
+	}
+}
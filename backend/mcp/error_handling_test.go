@@ -283,3 +283,116 @@ func TestErrorResponseStructure(t *testing.T) {
 		}
 	})
 }
+
+func TestToolCallsWithoutStore(t *testing.T) {
+	server := NewHTTPMCPServer(nil, allowAllValidator{}, nil, nil, nil, nil, auth.NewChallengeStore(10*time.Minute))
+
+	call := func(t *testing.T, tool string, args map[string]interface{}) MCPResponse {
+		t.Helper()
+		body, _ := json.Marshal(MCPRequest{Tool: tool, Arguments: args})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/mcp/call", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-API-Key", "test-key")
+		server.handleToolCall(w, r)
+
+		var resp MCPResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to unmarshal response: %v (%s)", err, w.Body.String())
+		}
+		return resp
+	}
+
+	for _, tool := range []string{"list_contracts", "get_task", "claim_task", "build_psbt", "create_task"} {
+		t.Run(tool, func(t *testing.T) {
+			resp := call(t, tool, map[string]interface{}{"task_id": "TASK-1", "contract_id": "CONTRACT-1"})
+			if resp.Success {
+				t.Fatalf("expected failure without a store")
+			}
+			if resp.ErrorCode != ErrCodeServiceUnavailable {
+				t.Fatalf("expected %s, got %s: %s", ErrCodeServiceUnavailable, resp.ErrorCode, resp.Error)
+			}
+		})
+	}
+
+	t.Run("storeless tool still works", func(t *testing.T) {
+		resp := call(t, "list_events", nil)
+		if !resp.Success {
+			t.Fatalf("list_events failed without a store: %s", resp.Error)
+		}
+	})
+
+	t.Run("unknown tool is still reported as unknown", func(t *testing.T) {
+		resp := call(t, "no_such_tool", nil)
+		if resp.Success || !strings.Contains(resp.Error, "unknown tool") {
+			t.Fatalf("expected unknown tool error, got %+v", resp)
+		}
+	})
+}
+
+func TestErrorRetryClassification(t *testing.T) {
+	store := scstore.NewMemoryStore(72 * time.Hour)
+	server := NewHTTPMCPServer(store, allowAllValidator{}, nil, nil, nil, nil, auth.NewChallengeStore(10*time.Minute))
+
+	call := func(t *testing.T, tool, key string, args map[string]interface{}) MCPResponse {
+		t.Helper()
+		body, _ := json.Marshal(MCPRequest{Tool: tool, Arguments: args})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/mcp/call", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-API-Key", key)
+		server.handleToolCall(w, r)
+
+		var resp MCPResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to unmarshal response: %v (%s)", err, w.Body.String())
+		}
+		return resp
+	}
+
+	t.Run("validation errors are not retryable", func(t *testing.T) {
+		resp := call(t, "get_auth_challenge", "fresh-key", map[string]interface{}{})
+		if resp.ErrorCode != ErrCodeValidationFailed {
+			t.Fatalf("expected %s, got %s", ErrCodeValidationFailed, resp.ErrorCode)
+		}
+		if resp.Retryable == nil || *resp.Retryable || resp.RetryAfterSeconds != 0 {
+			t.Fatalf("validation error retryable = %v, retry_after = %d; want explicit false", resp.Retryable, resp.RetryAfterSeconds)
+		}
+	})
+
+	t.Run("rate limited errors are retryable", func(t *testing.T) {
+		server.rateLimiterMu.Lock()
+		for i := 0; i < 100; i++ {
+			server.rateLimiter["busy-key"] = append(server.rateLimiter["busy-key"], time.Now())
+		}
+		server.rateLimiterMu.Unlock()
+
+		resp := call(t, "claim_task", "busy-key", map[string]interface{}{"task_id": "TASK-1"})
+		if resp.ErrorCode != ErrCodeRateLimited {
+			t.Fatalf("expected %s, got %s", ErrCodeRateLimited, resp.ErrorCode)
+		}
+		if resp.Retryable == nil || !*resp.Retryable || resp.RetryAfterSeconds <= 0 {
+			t.Fatalf("rate limit retryable = %v, retry_after = %d; want true with a delay", resp.Retryable, resp.RetryAfterSeconds)
+		}
+	})
+
+	t.Run("rest rate limit sets Retry-After", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		server.writeHTTPError(w, http.StatusTooManyRequests, ErrCodeRateLimited, "Rate limit exceeded", "")
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+			t.Fatalf("status %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+		}
+	})
+
+	for code, want := range map[string]bool{
+		ErrCodeUpstreamUnavailable: true,
+		"CLAIM_TASK_RATE_LIMITED":  true,
+		"CLAIM_TASK_TASK_TAKEN":    false,
+		ErrCodeInternalError:       false,
+		ErrCodeValidationFailed:    false,
+	} {
+		if got, _ := RetryPolicy(code); got != want {
+			t.Errorf("RetryPolicy(%s) = %v, want %v", code, got, want)
+		}
+	}
+}
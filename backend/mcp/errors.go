@@ -73,9 +73,10 @@ const (
 	ErrCodeRateLimited   = "RATE_LIMITED"
 
 	// Infrastructure error codes
-	ErrCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
-	ErrCodeInternalError      = "INTERNAL_ERROR"
-	ErrCodeBadGateway         = "BAD_GATEWAY"
+	ErrCodeServiceUnavailable  = "SERVICE_UNAVAILABLE"
+	ErrCodeInternalError       = "INTERNAL_ERROR"
+	ErrCodeBadGateway          = "BAD_GATEWAY"
+	ErrCodeUpstreamUnavailable = "UPSTREAM_UNAVAILABLE"
 
 	// Tool-specific prefixes
 	ToolPrefixClaimTask       = "CLAIM_TASK"
@@ -85,6 +86,27 @@ const (
 	ToolPrefixCreateWish      = "CREATE_WISH"
 )
 
+// retryableCodes lists error codes a client may retry unchanged, with a
+// suggested delay in seconds (0 when there is no better hint than backoff).
+// Tool-prefixed codes such as CLAIM_TASK_RATE_LIMITED classify like the bare code.
+var retryableCodes = map[string]int{
+	ErrCodeRateLimited:         60, // checkRateLimit counts requests over a one-minute window
+	ErrCodeServiceUnavailable:  0,
+	ErrCodeUpstreamUnavailable: 0,
+	ErrCodeBadGateway:          0,
+}
+
+// RetryPolicy reports whether an error with the given code is safe to retry
+// and, if known, how many seconds to wait first.
+func RetryPolicy(code string) (retryable bool, retryAfterSeconds int) {
+	for base, after := range retryableCodes {
+		if code == base || strings.HasSuffix(code, "_"+base) {
+			return true, after
+		}
+	}
+	return false, 0
+}
+
 // Helper functions to create common error types
 
 // NewValidationError creates a validation error for missing/invalid fields
@@ -204,6 +226,17 @@ func NewUnauthorizedError(tool, message string) *ToolError {
 	}
 }
 
+// NewForbiddenError creates a forbidden error for an authenticated caller
+// acting on something it does not own
+func NewForbiddenError(tool, message string) *ToolError {
+	return &ToolError{
+		Code:       ErrCodeForbidden,
+		Message:    message,
+		Tool:       tool,
+		HttpStatus: 403,
+	}
+}
+
 // NewConflictError creates a conflict error
 func NewConflictError(tool, message string) *ToolError {
 	return &ToolError{
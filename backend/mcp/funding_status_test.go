@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	"stargate-backend/services"
+	"stargate-backend/starlight"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestListContractsFundingStatus(t *testing.T) {
+	ctx := context.Background()
+	store := scstore.NewMemoryStore(time.Hour)
+	server := NewHTTPMCPServer(store, allowAllValidator{}, nil, &services.IngestionService{}, &starlight.ScannerManager{}, nil, auth.NewChallengeStore(10*time.Minute))
+
+	for id, txid := range map[string]string{"mcp-funded": "tx-mcp-funded", "mcp-unfunded": ""} {
+		c := smart_contract.Contract{ContractID: id, Title: id, Status: "active", TotalBudgetSats: 2000}
+		task := smart_contract.Task{TaskID: id + "-task", Title: id, Status: "available", MerkleProof: &smart_contract.MerkleProof{TxID: txid, FundedAmountSats: 2000, ConfirmationStatus: smart_contract.CommitmentStatusConfirmed}}
+		if err := store.UpsertContractWithTasks(ctx, c, []smart_contract.Task{task}); err != nil {
+			t.Fatalf("upsert %s: %v", id, err)
+		}
+	}
+
+	for status, want := range map[string]string{"funded": "mcp-funded", "unfunded": "mcp-unfunded"} {
+		result, err := server.handleListContracts(ctx, map[string]interface{}{"funding_status": status})
+		if err != nil {
+			t.Fatalf("list %s: %v", status, err)
+		}
+		seen := map[string]bool{}
+		for _, c := range result.(map[string]interface{})["contracts"].([]smart_contract.Contract) {
+			seen[c.ContractID] = true
+		}
+		for _, id := range []string{"mcp-funded", "mcp-unfunded"} {
+			if seen[id] != (id == want) {
+				t.Fatalf("funding_status=%s listed %s = %v", status, id, seen[id])
+			}
+		}
+	}
+
+	_, err := server.handleListContracts(ctx, map[string]interface{}{"funding_status": "sometimes"})
+	var validation *ValidationError
+	if !errors.As(err, &validation) || validation.Fields["funding_status"] == nil {
+		t.Fatalf("invalid funding_status err = %v, want field validation error", err)
+	}
+}
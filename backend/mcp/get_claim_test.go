@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	"stargate-backend/services"
+	"stargate-backend/starlight"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestGetClaimTool(t *testing.T) {
+	ctx := context.Background()
+	store := scstore.NewMemoryStore(time.Hour)
+	server := NewHTTPMCPServer(store, allowAllValidator{}, nil, &services.IngestionService{}, &starlight.ScannerManager{}, nil, auth.NewChallengeStore(10*time.Minute))
+
+	task := smart_contract.Task{TaskID: "mcp-claim-task", ContractID: "mcp-claim-contract", Title: "Claim me", Status: "available"}
+	if err := store.UpsertContractWithTasks(ctx, smart_contract.Contract{ContractID: "mcp-claim-contract", Title: "Claims", Status: "active"}, []smart_contract.Task{task}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	claim, err := store.ClaimTask(task.TaskID, "tb1qclaimer", nil)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+
+	result, err := server.callToolDirect(ctx, "get_claim", map[string]interface{}{"claim_id": claim.ClaimID}, "", nil)
+	if err != nil {
+		t.Fatalf("get_claim: %v", err)
+	}
+	details := result.(scstore.ClaimDetails)
+	if details.ClaimID != claim.ClaimID || details.TaskID != task.TaskID || details.Wallet != "tb1qclaimer" {
+		t.Fatalf("unexpected details: %+v", details)
+	}
+
+	_, err = server.callToolDirect(ctx, "get_claim", map[string]interface{}{"claim_id": "nope"}, "", nil)
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrCodeNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	if server.toolRequiresAuth("get_claim") {
+		t.Fatalf("get_claim should be a discovery tool")
+	}
+}
@@ -170,6 +170,12 @@ func NewGuidanceManifest(baseURL string) *GuidanceManifest {
 				RequiredFields: []string{"message", "image_base64"},
 				Description:    "Create a wish/inscription that seeds a proposal and contract metadata. Requires image payload.",
 			},
+			"propose_from_ingestion": {
+				Method:         "POST",
+				Endpoint:       apiBase + "/ingestion/{ingestion_id}/propose",
+				RequiredFields: []string{},
+				Description:    "Create a proposal from a pending ingestion (e.g. the ingestion_id returned by inscribe). Optional title, description_md, budget_sats and tasks override values derived from the record.",
+			},
 		},
 		AgentAssets: []AgentAsset{
 			{Name: "skill", URL: mcpBase + "/SKILL.md", Type: "markdown"},
@@ -231,6 +237,20 @@ func NewGuidanceManifest(baseURL string) *GuidanceManifest {
 						Description: "Filter contracts by required skills",
 						Items:       &ParameterSchema{Type: "string"},
 					},
+					"funding_status": {
+						Type:        "string",
+						Description: "Filter contracts by funding confirmed in task proofs",
+						Enum:        []string{"funded", "partial", "unfunded"},
+					},
+					"inactive_since": {
+						Type:        "string",
+						Description: "Only contracts with no activity since this RFC3339 time",
+					},
+					"order_by": {
+						Type:        "string",
+						Description: "Sort order; last_activity lists the least recently active first",
+						Enum:        []string{"last_activity"},
+					},
 					"limit": {
 						Type:        "integer",
 						Description: "Maximum number of contracts to return (default: 50)",
@@ -344,6 +364,40 @@ func NewGuidanceManifest(baseURL string) *GuidanceManifest {
 					{Description: "Get task details", Arguments: map[string]interface{}{"task_id": "task-123"}},
 				},
 			},
+			{
+				Name:         "get_claim",
+				Category:     ToolCategoryDiscovery,
+				Description:  "Look up a claim by ID: its task, wallet, status, expiry and any submission made under it. Use it to recover a claim_id whose claim_task response was lost",
+				AuthRequired: false,
+				Keywords:     []string{"claim", "status", "expiry", "recover"},
+				Parameters: map[string]*ParameterSchema{
+					"claim_id": {
+						Type:        "string",
+						Description: "The ID of the claim to retrieve",
+						Required:    true,
+					},
+				},
+				Examples: []ToolExample{
+					{Description: "Check a claim's status and expiry", Arguments: map[string]interface{}{"claim_id": "claim-123"}},
+				},
+			},
+			{
+				Name:         "get_task_commitment",
+				Category:     ToolCategoryDiscovery,
+				Description:  "Get a task's escrow commitment (address, vout, sats, redeem script) and whether its funding transaction has confirmed on-chain",
+				AuthRequired: false,
+				Keywords:     []string{"task", "commitment", "escrow", "funding", "confirmation"},
+				Parameters: map[string]*ParameterSchema{
+					"task_id": {
+						Type:        "string",
+						Description: "The ID of the task whose commitment to retrieve",
+						Required:    true,
+					},
+				},
+				Examples: []ToolExample{
+					{Description: "Check task funding status", Arguments: map[string]interface{}{"task_id": "task-123"}},
+				},
+			},
 			{
 				Name:         "get_scanner_info",
 				Category:     ToolCategoryDiscovery,
@@ -366,6 +420,17 @@ func NewGuidanceManifest(baseURL string) *GuidanceManifest {
 					{Description: "Fetch AI guidance and SKILL.md summary + links", Arguments: map[string]interface{}{}},
 				},
 			},
+			{
+				Name:         "get_workflow",
+				Category:     ToolCategoryDiscovery,
+				Description:  "Returns the agent workflow (inscribe, propose, approve, claim, submit, review) as ordered steps with the tool, REST endpoint, required inputs, expected outputs and preconditions of each, for orchestrating agents.",
+				AuthRequired: false,
+				Keywords:     []string{"workflow", "steps", "orchestrate", "plan", "guidance", "start"},
+				Parameters:   map[string]*ParameterSchema{},
+				Examples: []ToolExample{
+					{Description: "Fetch the workflow steps", Arguments: map[string]interface{}{}},
+				},
+			},
 			{
 				Name:         "list_tasks",
 				Category:     ToolCategoryDiscovery,
@@ -387,6 +452,15 @@ func NewGuidanceManifest(baseURL string) *GuidanceManifest {
 						Description: "Filter by task status",
 						Enum:        []string{"available", "claimed", "completed"},
 					},
+					"inactive_since": {
+						Type:        "string",
+						Description: "Only tasks with no activity since this RFC3339 time",
+					},
+					"order_by": {
+						Type:        "string",
+						Description: "Sort order; last_activity lists the least recently active first",
+						Enum:        []string{"last_activity"},
+					},
 					"limit": {
 						Type:        "integer",
 						Description: "Maximum number of tasks to return (default: 50)",
@@ -645,6 +719,57 @@ func NewGuidanceManifest(baseURL string) *GuidanceManifest {
 					{Description: "Scan transaction and extract inscribed skill", Arguments: map[string]interface{}{"transaction_id": "abc123..."}},
 				},
 			},
+			{
+				Name:         "scan_block_range",
+				Category:     ToolCategoryDiscovery,
+				Description:  "Scan a range of blocks for steganographic inscriptions and return per-block results with range totals. The span is capped (default 50 blocks) and each block after the first counts against the caller's rate limit.",
+				AuthRequired: true,
+				Keywords:     []string{"bitcoin", "block", "range", "scan", "steganography"},
+				Parameters: map[string]*ParameterSchema{
+					"start_height": {
+						Type:        "integer",
+						Description: "First block height to scan",
+						Required:    true,
+					},
+					"end_height": {
+						Type:        "integer",
+						Description: "Last block height to scan (inclusive)",
+						Required:    true,
+					},
+					"extract_message": {
+						Type:        "boolean",
+						Description: "Extract hidden messages from detected images (default true)",
+					},
+					"confidence_threshold": {
+						Type:        "number",
+						Description: "Minimum stego probability between 0 and 1 (default 0.5)",
+					},
+					"include_metadata": {
+						Type:        "boolean",
+						Description: "Include scan metadata (default true)",
+					},
+				},
+				Examples: []ToolExample{
+					{Description: "Scan ten blocks", Arguments: map[string]interface{}{"start_height": 170000, "end_height": 170009}},
+				},
+			},
+			{
+				Name:         "get_block_inscriptions",
+				Category:     ToolCategoryDiscovery,
+				Description:  "Get the inscriptions the block monitor parsed for a block height, with content types, sizes, and file paths",
+				AuthRequired: false,
+				Keywords:     []string{"bitcoin", "block", "inscriptions", "ordinals"},
+				Parameters: map[string]*ParameterSchema{
+					"height": {
+						Type:        "integer",
+						Description: "Block height to read",
+						Required:    true,
+					},
+				},
+				Examples: []ToolExample{
+					{Description: "List inscriptions in a block", Arguments: map[string]interface{}{"height": 170000}},
+				},
+			},
 			{
 				Name:         "list_events",
 				Category:     ToolCategoryDiscovery,
@@ -894,7 +1019,7 @@ func NewGuidanceManifest(baseURL string) *GuidanceManifest {
 					},
 					"commitment_sats": {
 						Type:        "integer",
-						Description: "Optional sats to lock in commitment output (min 546 sats)",
+						Description: "Optional sats to lock in commitment output (min 546 sats, the dust limit; 1000 recommended)",
 					},
 					"change_address": {
 						Type:        "string",
@@ -156,12 +156,7 @@ func (h *HTTPMCPServer) handleDiscover(w http.ResponseWriter, r *http.Request) {
 			"header_name": "X-API-Key",
 			"required":    fmt.Sprintf("%t", h.apiKeyStore != nil),
 		},
-		"rate_limits": map[string]interface{}{
-			"enabled":       false,
-			"notes":         "rate limiting planned; not enforced by default",
-			"recommended":   "10 rps claim, 5 rps submit (see roadmap)",
-			"burst_example": 100,
-		},
+		"rate_limits": h.rateLimitInfo(),
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(resp)
@@ -437,14 +432,11 @@ func (h *HTTPMCPServer) handleToolCall(w http.ResponseWriter, r *http.Request) {
 			h.writeStructuredErrorJSONRPC(w, NewUnauthorizedError(req.Tool, "Invalid API key. Double-check the X-API-Key header value."))
 			return
 		}
-		if h.apiKeyStore != nil && !h.checkRateLimit(apiKey) {
-			h.writeStructuredErrorJSONRPC(w, &ToolError{
-				Code:    ErrCodeRateLimited,
-				Message: "Rate limit exceeded. Retry after a short delay.",
-				Tool:    req.Tool,
-				HttpStatus: 429,
-			})
-			return
+		if h.apiKeyStore != nil {
+			if denial := h.checkToolRateLimit(apiKey, req.Tool); denial != nil {
+				h.writeStructuredErrorJSONRPC(w, rateLimitedError(req.Tool, denial))
+				return
+			}
 		}
 	}
 
@@ -7,20 +7,25 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"stargate-backend/bitcoin"
+	"stargate-backend/coerce"
 	"stargate-backend/core"
 	"stargate-backend/core/smart_contract"
 	"stargate-backend/handlers"
+	"stargate-backend/logging"
+	"stargate-backend/middleware"
 	scmiddleware "stargate-backend/middleware/smart_contract"
 	"stargate-backend/services"
 	"stargate-backend/starlight"
@@ -185,12 +190,20 @@ type HTTPMCPServer struct {
 	proxyBase        string
 	rateLimiterMu    sync.Mutex
 	rateLimiter      map[string][]time.Time
+	clock            func() time.Time
+	stopSweep        chan struct{}
+	closeOnce        sync.Once
+	toolRateLimits   map[string]int
 	challengeStore   *auth.ChallengeStore
 	network          string
 	guidance         *GuidanceManifest
 	chatHub          *ChatHub
 	sessions         map[string]*MCPSession
 	sessionMu        sync.RWMutex
+	audit            *AuditLogger
+	maxBlockRange    int
+	scanLimiter      *bitcoin.ScanLimiter
+	blockSource      blockInscriptionSource
 }
 
 // NewHTTPMCPServer creates a new HTTP MCP server
@@ -207,7 +220,13 @@ func NewHTTPMCPServer(store scmiddleware.Store, apiKeyStore auth.APIKeyValidator
 	}
 	baseURL = strings.TrimSuffix(baseURL, "/")
 
-	return &HTTPMCPServer{
+	audit, err := NewAuditLoggerFromEnv()
+	if err != nil {
+		log.Printf("audit log: %v; writing audit events to stdout", err)
+		audit = NewAuditLogger(os.Stdout)
+	}
+
+	h := &HTTPMCPServer{
 		store:            store,
 		apiKeyStore:      apiKeyStore,
 		apiKeyIssuer:     apiKeyIssuer,
@@ -220,17 +239,33 @@ func NewHTTPMCPServer(store scmiddleware.Store, apiKeyStore auth.APIKeyValidator
 		baseURL:          baseURL,
 		proxyBase:        os.Getenv("STARGATE_PROXY_BASE"),
 		rateLimiter:      make(map[string][]time.Time),
+		toolRateLimits:   toolRateLimitsFromEnv(),
 		challengeStore:   challengeStore,
 		network:          network,
 		guidance:         NewGuidanceManifest(baseURL),
 		chatHub:          NewChatHub(),
 		sessions:         make(map[string]*MCPSession),
+		audit:            audit,
+		maxBlockRange:    maxBlockRangeFromEnv(),
+		stopSweep:        make(chan struct{}),
 	}
+	go h.sweepRateLimits(rateLimitSweepInterval, h.stopSweep)
+	return h
 }
 
-// SetServer sets the smart_contract server reference
+// SetAuditLogger replaces the audit sink chosen from STARGATE_AUDIT_LOG.
+func (h *HTTPMCPServer) SetAuditLogger(audit *AuditLogger) {
+	h.audit = audit
+}
+
+// SetServer sets the smart_contract server reference, registers the MCP
+// rate limiter with its admin GC and advertises its limits on discover.
 func (h *HTTPMCPServer) SetServer(server *scmiddleware.Server) {
 	h.server = server
+	if server != nil {
+		server.RegisterGCSweeper("rate_limit_windows", h.purgeRateLimitWindows)
+		server.SetRateLimitInfo(h.rateLimitInfo())
+	}
 }
 
 func (h *HTTPMCPServer) createSession() string {
@@ -320,6 +355,12 @@ func (h *HTTPMCPServer) getHTTPEndpointsMap(baseURL string) map[string]interface
 			"required_fields": []string{"message", "image_base64"},
 			"description":     "Create a wish/inscription that seeds a proposal and contract metadata. Requires image payload.",
 		},
+		"propose_from_ingestion": map[string]interface{}{
+			"method":          "POST",
+			"endpoint":        baseURL + "/api/ingestion/{ingestion_id}/propose",
+			"required_fields": []string{},
+			"description":     "Create a proposal from a pending ingestion (e.g. the ingestion_id returned by inscribe). Optional title, description_md, budget_sats and tasks override values derived from the record.",
+		},
 	}
 }
 
@@ -354,7 +395,6 @@ func (h *HTTPMCPServer) writeHTTPError(w http.ResponseWriter, status int, code s
 // writeHTTPStructuredError writes structured error responses
 func (h *HTTPMCPServer) writeHTTPStructuredError(w http.ResponseWriter, status int, err error) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
 
 	resp := MCPResponse{
 		Success: false,
@@ -431,10 +471,16 @@ func (h *HTTPMCPServer) writeHTTPStructuredError(w http.ResponseWriter, status i
 		resp.Hint = "Please try again. If the problem persists, contact support"
 	}
 
-	// Add timestamp and version for all errors
+	// Add timestamp, version and retry classification for all errors
 	resp.Timestamp = time.Now().Format(time.RFC3339)
+	resp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 	resp.Version = "1.0.0"
+	resp.setRetryPolicy()
+	if resp.RetryAfterSeconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(resp.RetryAfterSeconds))
+	}
 
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(resp)
 }
 
@@ -517,9 +563,14 @@ func (h *HTTPMCPServer) writeStructuredErrorJSONRPC(w http.ResponseWriter, err e
 		resp.Hint = "Please try again. If the problem persists, contact support"
 	}
 
-	// Add timestamp and version for all errors
+	// Add timestamp, version and retry classification for all errors
 	resp.Timestamp = time.Now().Format(time.RFC3339)
+	resp.RequestID = w.Header().Get(middleware.RequestIDHeader)
 	resp.Version = "1.0.0"
+	resp.setRetryPolicy()
+	if resp.RetryAfterSeconds > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(resp.RetryAfterSeconds))
+	}
 
 	json.NewEncoder(w).Encode(resp)
 }
@@ -560,10 +611,13 @@ func (h *HTTPMCPServer) toolRequiresAuth(toolName string) bool {
 		"approve_proposal":      true,
 		"reject_submission":     true,
 		"approve_submission":    true,
+		"scan_block_range":      true, // Auth required - each block is charged to the caller's rate limit
 		"get_auth_challenge":    false, // No auth required - discovery tool
 		"verify_auth_challenge": false, // No auth required - solves chicken-egg problem
 		"validate_address":      false, // No auth required - AI debugging tool
 		"get_task":              false, // No auth required - discovery tool
+		"get_task_commitment":   false, // No auth required - discovery tool
+		"get_claim":             false, // No auth required - discovery tool
 		"list_submissions":      false, // No auth required - discovery tool
 		"build_psbt":                    true,  // Auth required - payer address derived from API key
 		"create_contract_rework_request": true,
@@ -571,7 +625,33 @@ func (h *HTTPMCPServer) toolRequiresAuth(toolName string) bool {
 	return authenticatedTools[toolName]
 }
 
+// storelessTools are the tools that never touch the smart contract store, so
+// they keep working when the server was started without one.
+var storelessTools = map[string]bool{
+	"list_events":            true,
+	"events_stream":          true,
+	"create_wish":            true,
+	"scan_image":             true,
+	"scan_transaction":       true,
+	"scan_block_range":       true,
+	"get_block_inscriptions": true,
+	"get_scanner_info":       true,
+	"get_ai_guidance":        true,
+	"get_workflow":           true,
+	"get_auth_challenge":     true,
+	"verify_auth_challenge":  true,
+	"validate_address":       true,
+	"chat_send":              true,
+	"chat_stream":            true,
+	"chat_members":           true,
+}
+
 func (h *HTTPMCPServer) callToolDirect(ctx context.Context, toolName string, args map[string]interface{}, apiKey string, r *http.Request) (interface{}, error) {
+	if h.store == nil && !storelessTools[toolName] {
+		if _, known := h.getToolSchemas()[toolName]; known {
+			return nil, NewServiceUnavailableError(toolName, "smart contract store")
+		}
+	}
 	switch toolName {
 	case "list_contracts":
 		return h.handleListContracts(ctx, args)
@@ -591,6 +671,10 @@ func (h *HTTPMCPServer) callToolDirect(ctx context.Context, toolName string, arg
 		return h.handleCreateContractReworkRequest(ctx, args, apiKey)
 	case "get_task":
 		return h.handleGetTask(ctx, args)
+	case "get_task_commitment":
+		return h.handleGetTaskCommitment(ctx, args)
+	case "get_claim":
+		return h.handleGetClaim(ctx, args)
 	case "list_events":
 		return h.handleListEvents(ctx, args)
 	case "events_stream":
@@ -613,10 +697,16 @@ func (h *HTTPMCPServer) callToolDirect(ctx context.Context, toolName string, arg
 		return h.handleScanImage(ctx, args)
 	case "scan_transaction":
 		return h.handleScanTransaction(ctx, args)
+	case "scan_block_range":
+		return h.handleScanBlockRange(ctx, args, apiKey)
+	case "get_block_inscriptions":
+		return h.handleGetBlockInscriptions(ctx, args)
 	case "get_scanner_info":
 		return h.handleGetScannerInfo(ctx, args)
 	case "get_ai_guidance":
 		return h.handleGetAIGuidanceTool(ctx, args, r)
+	case "get_workflow":
+		return h.handleGetWorkflow(ctx, args, r)
 	case "get_auth_challenge":
 		return h.handleGetAuthChallenge(ctx, args)
 	case "verify_auth_challenge":
@@ -658,20 +748,28 @@ func (h *HTTPMCPServer) handleListContracts(ctx context.Context, args map[string
 			}
 		}
 	}
+	if fundingStatus, ok := args["funding_status"].(string); ok && strings.TrimSpace(fundingStatus) != "" {
+		filter.FundingStatus = strings.ToLower(strings.TrimSpace(fundingStatus))
+		if !smart_contract.ValidFundingStatus(filter.FundingStatus) {
+			validation := NewValidationError("list_contracts", "Invalid request parameters")
+			validation.AddFieldError("funding_status", fundingStatus, "funding_status must be funded, partial or unfunded", false)
+			return nil, validation
+		}
+	}
+	var err error
+	if filter.InactiveSince, filter.OrderByLastActivity, err = activityArgs("list_contracts", args); err != nil {
+		return nil, err
+	}
 
 	// Handle pagination parameters
-	if limit, ok := args["limit"].(int); ok && limit > 0 {
-		filter.Limit = limit
-	} else if limitFloat, ok := args["limit"].(float64); ok && limitFloat > 0 {
-		filter.Limit = int(limitFloat)
+	if limit, ok := coerce.Int64(args["limit"]); ok && limit > 0 {
+		filter.Limit = int(limit)
 	} else {
 		filter.Limit = 50 // Default limit
 	}
 
-	if offset, ok := args["offset"].(int); ok && offset >= 0 {
-		filter.Offset = offset
-	} else if offsetFloat, ok := args["offset"].(float64); ok && offsetFloat >= 0 {
-		filter.Offset = int(offsetFloat)
+	if offset, ok := coerce.Int64(args["offset"]); ok && offset >= 0 {
+		filter.Offset = int(offset)
 	} else {
 		filter.Offset = 0 // Default offset
 	}
@@ -681,15 +779,11 @@ func (h *HTTPMCPServer) handleListContracts(ctx context.Context, args map[string
 		return nil, err
 	}
 
-	// Check if there are more results by requesting one more item
+	// Check if there are more results past this page
 	hasMore := false
 	if len(contracts) == filter.Limit {
-		checkFilter := filter
-		checkFilter.Offset = filter.Offset + filter.Limit
-		checkFilter.Limit = 1
-		moreResults, err := h.store.ListContracts(checkFilter)
-		if err == nil && len(moreResults) > 0 {
-			hasMore = true
+		if total, err := h.store.CountContracts(filter); err == nil {
+			hasMore = filter.Offset+len(contracts) < total
 		}
 	}
 
@@ -702,6 +796,31 @@ func (h *HTTPMCPServer) handleListContracts(ctx context.Context, args map[string
 	}, nil
 }
 
+// activityArgs reads the staleness arguments shared by list_contracts and
+// list_tasks: inactive_since (RFC3339) and order_by=last_activity.
+func activityArgs(tool string, args map[string]interface{}) (*time.Time, bool, error) {
+	var inactiveSince *time.Time
+	if raw, ok := args["inactive_since"].(string); ok && strings.TrimSpace(raw) != "" {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(raw))
+		if err != nil {
+			validation := NewValidationError(tool, "Invalid request parameters")
+			validation.AddFieldError("inactive_since", raw, "inactive_since must be an RFC3339 timestamp", false)
+			return nil, false, validation
+		}
+		inactiveSince = &t
+	}
+	byActivity := false
+	if order, ok := args["order_by"].(string); ok && strings.TrimSpace(order) != "" {
+		if !strings.EqualFold(strings.TrimSpace(order), "last_activity") {
+			validation := NewValidationError(tool, "Invalid request parameters")
+			validation.AddFieldError("order_by", order, "order_by must be last_activity", false)
+			return nil, false, validation
+		}
+		byActivity = true
+	}
+	return inactiveSince, byActivity, nil
+}
+
 func (h *HTTPMCPServer) handleListProposals(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	filter := smart_contract.ProposalFilter{}
 	if status, ok := args["status"].(string); ok {
@@ -715,18 +834,14 @@ func (h *HTTPMCPServer) handleListProposals(ctx context.Context, args map[string
 	}
 
 	// Handle pagination parameters
-	if limit, ok := args["limit"].(int); ok && limit > 0 {
-		filter.MaxResults = limit
-	} else if limitFloat, ok := args["limit"].(float64); ok && limitFloat > 0 {
-		filter.MaxResults = int(limitFloat)
+	if limit, ok := coerce.Int64(args["limit"]); ok && limit > 0 {
+		filter.MaxResults = int(limit)
 	} else {
 		filter.MaxResults = 50 // Default limit
 	}
 
-	if offset, ok := args["offset"].(int); ok && offset >= 0 {
-		filter.Offset = offset
-	} else if offsetFloat, ok := args["offset"].(float64); ok && offsetFloat >= 0 {
-		filter.Offset = int(offsetFloat)
+	if offset, ok := coerce.Int64(args["offset"]); ok && offset >= 0 {
+		filter.Offset = int(offset)
 	} else {
 		filter.Offset = 0 // Default offset
 	}
@@ -736,15 +851,11 @@ func (h *HTTPMCPServer) handleListProposals(ctx context.Context, args map[string
 		return nil, err
 	}
 
-	// Check if there are more results by requesting one more item
+	// Check if there are more results past this page
 	hasMore := false
 	if len(proposals) == filter.MaxResults {
-		checkFilter := filter
-		checkFilter.Offset = filter.Offset + filter.MaxResults
-		checkFilter.MaxResults = 1
-		moreResults, err := h.store.ListProposals(ctx, checkFilter)
-		if err == nil && len(moreResults) > 0 {
-			hasMore = true
+		if total, err := h.store.CountProposals(ctx, filter); err == nil {
+			hasMore = filter.Offset+len(proposals) < total
 		}
 	}
 
@@ -787,7 +898,7 @@ func (h *HTTPMCPServer) handleClaimTask(ctx context.Context, args map[string]int
 		}
 	}
 	if wallet == "" {
-		return nil, NewUnauthorizedError("claim_task", "wallet address required - please bind wallet to API key using /api/auth/verify")
+		return nil, NewUnauthorizedError("claim_task", "wallet address required - bind a wallet to your API key with POST /api/auth/wallet (or /api/auth/verify)")
 	}
 
 	// Return validation errors if any
@@ -804,6 +915,9 @@ func (h *HTTPMCPServer) handleClaimTask(ctx context.Context, args map[string]int
 		if strings.Contains(err.Error(), "already claimed") {
 			return nil, NewClaimTaskError("ALREADY_CLAIMED", "Task has already been claimed", "task_id")
 		}
+		if err == scstore.ErrTaskClosed {
+			return nil, NewClaimTaskError("TASK_CLOSED", "Task is closed: its work is approved or its contract has ended", "task_id")
+		}
 		return nil, err
 	}
 
@@ -833,7 +947,7 @@ func (h *HTTPMCPServer) handleCreateProposal(ctx context.Context, args map[strin
 	// Validate budget_sats if provided
 	var budgetSats int64 = 0
 	if budget, ok := args["budget_sats"]; ok {
-		if b, ok := budget.(float64); ok {
+		if b, ok := coerce.Float64(budget); ok {
 			if b < 0 {
 				validation.AddFieldError("budget_sats", budget, "budget_sats must be a non-negative number", false)
 			} else {
@@ -873,7 +987,6 @@ func (h *HTTPMCPServer) handleCreateProposal(ctx context.Context, args map[strin
 	}
 
 	proposalID := fmt.Sprintf("proposal-%d", time.Now().UnixNano())
-	contractID := "wish-" + visiblePixelHash
 	proposal := smart_contract.Proposal{
 		ID:               proposalID,
 		Title:            title,
@@ -884,12 +997,12 @@ func (h *HTTPMCPServer) handleCreateProposal(ctx context.Context, args map[strin
 		CreatedAt:        time.Now(),
 		Metadata: map[string]interface{}{
 			"creator_wallet":     creatorWallet,
-			"contract_id":        contractID,
+			"contract_id":        visiblePixelHash,
 			"visible_pixel_hash": visiblePixelHash,
 		},
 	}
 
-	log.Printf("MCP CREATE PROPOSAL DEBUG: ID=%s, metadata=%+v", proposal.ID, proposal.Metadata)
+	logging.Debugf("MCP create proposal: ID=%s, metadata=%+v", proposal.ID, proposal.Metadata)
 	err = h.store.CreateProposal(ctx, proposal)
 	if err != nil {
 		errMsg := err.Error()
@@ -899,6 +1012,9 @@ func (h *HTTPMCPServer) handleCreateProposal(ctx context.Context, args map[strin
 		if strings.Contains(errMsg, "already approved/published") {
 			return nil, NewCreateProposalError("ALREADY_FINALIZED", "This wish already has an approved or published proposal and is no longer accepting new proposals", "visible_pixel_hash")
 		}
+		if errors.Is(err, scstore.ErrTooManyTasks) {
+			return nil, NewCreateProposalError("TOO_MANY_TASKS", fmt.Sprintf("Proposals may define at most %d tasks", scstore.MaxTasksPerProposal()), "tasks")
+		}
 		return nil, NewInternalError("create_proposal", fmt.Sprintf("Failed to create proposal: %v", err))
 	}
 
@@ -1103,7 +1219,7 @@ func (h *HTTPMCPServer) requireAuthorizedApprover(apiKey string, proposal smart_
 	}
 
 	if !hasWishCreatorInfo {
-		log.Printf("WARNING: allowing approval for proposal %s with NO wish creator info", proposal.ID)
+		logging.Warnf("allowing approval for proposal %s with NO wish creator info", proposal.ID)
 		return nil
 	}
 
@@ -1134,7 +1250,7 @@ func (h *HTTPMCPServer) handleScanImage(ctx context.Context, args map[string]int
 		return nil, fmt.Errorf("scan failed: %w", err)
 	}
 
-	return map[string]interface{}{
+	response := map[string]interface{}{
 		"is_stego":          scanResult.IsStego,
 		"stego_probability": scanResult.StegoProbability,
 		"confidence":        scanResult.Confidence,
@@ -1142,7 +1258,11 @@ func (h *HTTPMCPServer) handleScanImage(ctx context.Context, args map[string]int
 		"stego_type":        scanResult.StegoType,
 		"extracted_message": scanResult.ExtractedMessage,
 		"extraction_error":  scanResult.ExtractionError,
-	}, nil
+	}
+	if len(scanResult.Probabilities) > 0 {
+		response["probabilities"] = scanResult.Probabilities
+	}
+	return response, nil
 }
 
 func (h *HTTPMCPServer) handleScanTransaction(ctx context.Context, args map[string]interface{}) (interface{}, error) {
@@ -1322,9 +1442,12 @@ func (h *HTTPMCPServer) handleGetScannerInfo(ctx context.Context, args map[strin
 	if h.scannerManager == nil {
 		return nil, NewServiceUnavailableError("get_scanner_info", "scanner")
 	}
+	breaker := h.scannerManager.CircuitBreakerStatus()
 	return map[string]interface{}{
-		"available": true,
-		"version":   "1.0.0",
+		"available":       breaker.State != "open",
+		"version":         "1.0.0",
+		"scanner_type":    h.scannerManager.GetScannerType(),
+		"circuit_breaker": breaker,
 	}, nil
 }
 
@@ -1343,20 +1466,20 @@ func (h *HTTPMCPServer) handleListTasks(ctx context.Context, args map[string]int
 			}
 		}
 	}
+	var err error
+	if filter.InactiveSince, filter.OrderByLastActivity, err = activityArgs("list_tasks", args); err != nil {
+		return nil, err
+	}
 
 	// Handle pagination parameters
-	if limit, ok := args["limit"].(int); ok && limit > 0 {
-		filter.Limit = limit
-	} else if limitFloat, ok := args["limit"].(float64); ok && limitFloat > 0 {
-		filter.Limit = int(limitFloat)
+	if limit, ok := coerce.Int64(args["limit"]); ok && limit > 0 {
+		filter.Limit = int(limit)
 	} else {
 		filter.Limit = 50 // Default limit
 	}
 
-	if offset, ok := args["offset"].(int); ok && offset >= 0 {
-		filter.Offset = offset
-	} else if offsetFloat, ok := args["offset"].(float64); ok && offsetFloat >= 0 {
-		filter.Offset = int(offsetFloat)
+	if offset, ok := coerce.Int64(args["offset"]); ok && offset >= 0 {
+		filter.Offset = int(offset)
 	} else {
 		filter.Offset = 0 // Default offset
 	}
@@ -1366,15 +1489,11 @@ func (h *HTTPMCPServer) handleListTasks(ctx context.Context, args map[string]int
 		return nil, err
 	}
 
-	// Check if there are more results by requesting one more item
+	// Check if there are more results past this page
 	hasMore := false
 	if len(tasks) == filter.Limit {
-		checkFilter := filter
-		checkFilter.Offset = filter.Offset + filter.Limit
-		checkFilter.Limit = 1
-		moreResults, err := h.store.ListTasks(checkFilter)
-		if err == nil && len(moreResults) > 0 {
-			hasMore = true
+		if total, err := h.store.CountTasks(filter); err == nil {
+			hasMore = filter.Offset+len(tasks) < total
 		}
 	}
 
@@ -1441,15 +1560,11 @@ func (h *HTTPMCPServer) handleListSubmissions(ctx context.Context, args map[stri
 	// Handle pagination parameters
 	limit := 50
 	offset := 0
-	if lim, ok := args["limit"].(int); ok && lim > 0 {
-		limit = lim
-	} else if lim, ok := args["limit"].(float64); ok && lim > 0 {
+	if lim, ok := coerce.Int64(args["limit"]); ok && lim > 0 {
 		limit = int(lim)
 	}
 
-	if off, ok := args["offset"].(int); ok && off >= 0 {
-		offset = off
-	} else if off, ok := args["offset"].(float64); ok && off >= 0 {
+	if off, ok := coerce.Int64(args["offset"]); ok && off >= 0 {
 		offset = int(off)
 	}
 
@@ -1473,21 +1588,9 @@ func (h *HTTPMCPServer) handleListSubmissions(ctx context.Context, args map[stri
 		filtered = append(filtered, sub)
 	}
 
-	// Apply pagination
-	hasMore := false
-	var paged []smart_contract.Submission
-	if offset < len(filtered) {
-		end := offset + limit
-		if end > len(filtered) {
-			end = len(filtered)
-		}
-		paged = filtered[offset:end]
-		if offset+limit < len(filtered) {
-			hasMore = true
-		}
-	} else {
-		paged = []smart_contract.Submission{}
-	}
+	// Apply pagination over a stable, oldest-first order
+	smart_contract.SortSubmissions(filtered)
+	paged, hasMore := smart_contract.PageSubmissions(filtered, limit, offset)
 
 	return map[string]interface{}{
 		"submissions": paged,
@@ -1696,6 +1799,50 @@ func (h *HTTPMCPServer) handleGetTask(ctx context.Context, args map[string]inter
 	return task, nil
 }
 
+func (h *HTTPMCPServer) handleGetClaim(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	validation := NewValidationError("get_claim", "Invalid request parameters")
+
+	claimID, ok := args["claim_id"].(string)
+	if !ok || claimID == "" {
+		validation.AddFieldError("claim_id", args["claim_id"], "claim_id is required and must be a string", true)
+	}
+	if validation.HasErrors() {
+		return nil, validation
+	}
+
+	details, err := scstore.LookupClaim(ctx, h.store, claimID, time.Now())
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, NewNotFoundError("get_claim", "claim", claimID)
+		}
+		return nil, NewInternalError("get_claim", fmt.Sprintf("Failed to get claim: %v", err))
+	}
+
+	return details, nil
+}
+
+func (h *HTTPMCPServer) handleGetTaskCommitment(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	validation := NewValidationError("get_task_commitment", "Invalid request parameters")
+
+	taskID, ok := args["task_id"].(string)
+	if !ok || taskID == "" {
+		validation.AddFieldError("task_id", args["task_id"], "task_id is required and must be a string", true)
+	}
+	if validation.HasErrors() {
+		return nil, validation
+	}
+
+	task, err := h.store.GetTask(taskID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, NewNotFoundError("get_task_commitment", "task", taskID)
+		}
+		return nil, NewInternalError("get_task_commitment", fmt.Sprintf("Failed to get task: %v", err))
+	}
+
+	return smart_contract.TaskCommitmentStatus(task), nil
+}
+
 func (h *HTTPMCPServer) handleListEvents(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	return map[string]interface{}{
 		"endpoint": "/api/smart_contract/events",
@@ -1867,6 +2014,7 @@ func (h *HTTPMCPServer) handleCreateWish(ctx context.Context, args map[string]in
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+apiKey)
+	middleware.SetRequestIDHeader(ctx, req)
 
 	resp, err := h.httpClient.Do(req)
 	if err != nil {
@@ -1996,10 +2144,26 @@ func (h *HTTPMCPServer) handleSubmitWork(ctx context.Context, args map[string]in
 		return nil, validation
 	}
 
+	// Only the wallet that claimed the task may submit under its claim; check
+	// before any artifact is written to disk.
+	claim, claimErr := h.store.GetClaim(claimID)
+	if h.apiKeyStore != nil {
+		if claimErr != nil {
+			return nil, NewNotFoundError("submit_work", "claim", claimID)
+		}
+		var wallet string
+		if keyInfo, ok := h.apiKeyStore.Get(apiKey); ok {
+			wallet = keyInfo.Wallet
+		}
+		if !scstore.ClaimHeldBy(claim, wallet) {
+			return nil, NewForbiddenError("submit_work", "claim is held by a different wallet")
+		}
+	}
+
 	// Compute subDir (contract_id/visible_pixel_hash) for sandbox URL
 	// This is used for both file storage and the sandbox_url response
 	subDir := claimID
-	if claim, err := h.store.GetClaim(claimID); err == nil {
+	if claimErr == nil {
 		if task, err := h.store.GetTask(claim.TaskID); err == nil {
 			subDir = scstore.NormalizeContractID(task.ContractID)
 		}
@@ -2089,7 +2253,7 @@ func (h *HTTPMCPServer) handleSubmitWork(ctx context.Context, args map[string]in
 				}
 
 				filePath := filepath.Join(subDirPath, baseName)
-				fmt.Printf("DEBUG: Writing file to: %s\n", filePath)
+				logging.Debugf("Writing file to: %s", filePath)
 
 				// Write file
 				if err := os.WriteFile(filePath, fileData, 0644); err != nil {
@@ -2172,7 +2336,7 @@ func (h *HTTPMCPServer) handleGetOpenContracts(ctx context.Context, args map[str
 
 	// Handle limit parameter
 	limit := 50 // default
-	if lim, ok := args["limit"].(float64); ok {
+	if lim, ok := coerce.Int64(args["limit"]); ok {
 		limit = int(lim)
 		if limit <= 0 {
 			limit = 50
@@ -2235,7 +2399,7 @@ func (h *HTTPMCPServer) handleGetAuthChallenge(ctx context.Context, args map[str
 	}
 
 	// Check for AI-friendly mode flag
-	aiMode, _ := args["ai_mode"].(bool)
+	aiMode, _ := coerce.Bool(args["ai_mode"])
 
 	// Return validation errors if any
 	if validation.HasErrors() {
@@ -2284,7 +2448,7 @@ func (h *HTTPMCPServer) handleVerifyAuthChallenge(ctx context.Context, args map[
 	}
 
 	email, _ := args["email"].(string)         // Optional
-	detailedMode, _ := args["detailed"].(bool) // Optional - enable detailed error reporting
+	detailedMode, _ := coerce.Bool(args["detailed"]) // Optional - enable detailed error reporting
 
 	// Return validation errors if any
 	if validation.HasErrors() {
@@ -2417,7 +2581,7 @@ func (h *HTTPMCPServer) handleCreateTask(ctx context.Context, args map[string]in
 	// Validate budget_sats
 	var budgetSats int64 = 0
 	if budget, ok := args["budget_sats"]; ok {
-		if b, ok := budget.(float64); ok {
+		if b, ok := coerce.Float64(budget); ok {
 			if b <= 0 {
 				validation.AddFieldError("budget_sats", budget, "budget_sats must be a positive number", true)
 			} else {
@@ -2449,18 +2613,12 @@ func (h *HTTPMCPServer) handleCreateTask(ctx context.Context, args map[string]in
 	}
 
 	var estimatedHours int = 0
-	if estHours, ok := args["estimated_hours"].(float64); ok {
+	if estHours, ok := coerce.Float64(args["estimated_hours"]); ok {
 		if estHours < 0 {
 			validation.AddFieldError("estimated_hours", estHours, "estimated_hours must be a non-negative number", false)
 		} else {
 			estimatedHours = int(estHours)
 		}
-	} else if estHours, ok := args["estimated_hours"].(int); ok {
-		if estHours < 0 {
-			validation.AddFieldError("estimated_hours", estHours, "estimated_hours must be a non-negative integer", false)
-		} else {
-			estimatedHours = estHours
-		}
 	}
 
 	var requirements map[string]string
@@ -2478,10 +2636,6 @@ func (h *HTTPMCPServer) handleCreateTask(ctx context.Context, args map[string]in
 		return nil, validation
 	}
 
-	if h.store == nil {
-		return nil, NewServiceUnavailableError("create_task", "task store")
-	}
-
 	// Verify contract exists
 	_, err := h.store.GetContract(contractID)
 	if err != nil {
@@ -2628,13 +2782,17 @@ func (h *HTTPMCPServer) handleBuildPSBT(ctx context.Context, args map[string]int
 	}
 
 	feeRate := int64(10)
-	if fr, ok := args["fee_rate_sat_per_vb"].(float64); ok && fr > 0 {
-		feeRate = int64(fr)
+	if fr, ok := coerce.Int64(args["fee_rate_sat_per_vb"]); ok && fr > 0 {
+		feeRate = fr
 	}
 
 	commitmentSats := int64(0)
-	if cs, ok := args["commitment_sats"].(float64); ok && cs > 0 {
-		commitmentSats = int64(cs)
+	if cs, ok := coerce.Int64(args["commitment_sats"]); ok && cs != 0 {
+		commitmentSats = cs
+		if err := bitcoin.ValidateCommitmentSats(commitmentSats); err != nil {
+			validation.AddFieldError("commitment_sats", args["commitment_sats"], err.Error(), false)
+			return nil, validation
+		}
 	}
 
 	var pixelHashBytes []byte
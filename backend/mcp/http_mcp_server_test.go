@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"stargate-backend/core/smart_contract"
+	scmiddleware "stargate-backend/middleware/smart_contract"
 	"stargate-backend/services"
 	"stargate-backend/starlight"
 	"stargate-backend/storage/auth"
@@ -752,3 +753,31 @@ func TestSubmitWorkRequiresArtifactsForRemoteAgents(t *testing.T) {
 		}
 	})
 }
+
+func TestAdminGCPurgesRateLimitWindows(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	server := NewHTTPMCPServer(store, allowAllValidator{}, nil, &services.IngestionService{}, &starlight.ScannerManager{}, nil, auth.NewChallengeStore(10*time.Minute))
+	rest := scmiddleware.NewServer(store, nil, nil)
+	server.SetServer(rest)
+
+	server.checkRateLimit("active-key")
+	server.rateLimiterMu.Lock()
+	server.rateLimiter["idle-key"] = []time.Time{time.Now().Add(-2 * time.Minute)}
+	server.rateLimiterMu.Unlock()
+
+	purged, err := rest.RunGC(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("gc: %v", err)
+	}
+	if purged["rate_limit_windows"] != 1 {
+		t.Fatalf("rate_limit_windows purged = %d, want 1", purged["rate_limit_windows"])
+	}
+	server.rateLimiterMu.Lock()
+	defer server.rateLimiterMu.Unlock()
+	if _, ok := server.rateLimiter["idle-key"]; ok {
+		t.Fatal("idle key still tracked")
+	}
+	if _, ok := server.rateLimiter["active-key"]; !ok {
+		t.Fatal("active key was purged")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -150,14 +151,21 @@ func (h *HTTPMCPServer) handleJSONRPCToolsCall(w http.ResponseWriter, r *http.Re
 			})
 			return
 		}
-		if h.apiKeyStore != nil && !h.checkRateLimit(apiKey) {
-			h.writeJSONRPCError(w, req.ID, -32003, "Rate limit exceeded", map[string]interface{}{
-				"code":    "RATE_LIMITED",
-				"message": "Rate limit exceeded",
-				"tool":    name,
-				"hint":    "Retry after a short delay.",
-			})
-			return
+		if h.apiKeyStore != nil {
+			if denial := h.checkToolRateLimit(apiKey, name); denial != nil {
+				toolErr := rateLimitedError(name, denial)
+				w.Header().Set("Retry-After", strconv.Itoa(denial.retryAfterSeconds()))
+				h.writeJSONRPCError(w, req.ID, -32003, "Rate limit exceeded", map[string]interface{}{
+					"code":                "RATE_LIMITED",
+					"message":             toolErr.Message,
+					"tool":                name,
+					"limited_tool":        denial.Tool,
+					"limit_per_minute":    denial.Limit,
+					"retry_after_seconds": denial.retryAfterSeconds(),
+					"hint":                toolErr.Hint,
+				})
+				return
+			}
 		}
 	}
 
@@ -215,6 +223,15 @@ func (h *HTTPMCPServer) writeJSONRPCResponse(w http.ResponseWriter, resp jsonRPC
 
 func (h *HTTPMCPServer) writeJSONRPCError(w http.ResponseWriter, id interface{}, code int, message string, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
+	if m, ok := data.(map[string]interface{}); ok {
+		if errCode, ok := m["code"].(string); ok {
+			retryable, after := RetryPolicy(errCode)
+			m["retryable"] = retryable
+			if _, exact := m["retry_after_seconds"]; !exact && after > 0 {
+				m["retry_after_seconds"] = after
+			}
+		}
+	}
 	json.NewEncoder(w).Encode(jsonRPCResponse{
 		JSONRPC: "2.0",
 		ID:      id,
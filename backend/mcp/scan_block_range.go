@@ -0,0 +1,201 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"stargate-backend/bitcoin"
+	"stargate-backend/coerce"
+	"stargate-backend/core"
+	"stargate-backend/starlight"
+)
+
+// defaultMaxBlockRange caps how many blocks one scan_block_range call may
+// cover when STARGATE_MCP_MAX_BLOCK_RANGE is unset.
+const defaultMaxBlockRange = 50
+
+// blockScanner is the part of starlight.ScannerManager scan_block_range uses.
+type blockScanner interface {
+	ScanBlock(blockHeight int64, options core.ScanOptions) (*core.BlockScanResponse, error)
+}
+
+// maxBlockRangeFromEnv reads STARGATE_MCP_MAX_BLOCK_RANGE.
+func maxBlockRangeFromEnv() int {
+	raw := os.Getenv("STARGATE_MCP_MAX_BLOCK_RANGE")
+	if raw == "" {
+		return defaultMaxBlockRange
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		log.Printf("Ignoring invalid STARGATE_MCP_MAX_BLOCK_RANGE=%q, using %d", raw, defaultMaxBlockRange)
+		return defaultMaxBlockRange
+	}
+	return n
+}
+
+// blockRangeResult is one block's entry in a scan_block_range response.
+type blockRangeResult struct {
+	BlockHeight       int64   `json:"block_height"`
+	BlockHash         string  `json:"block_hash,omitempty"`
+	TotalInscriptions int     `json:"total_inscriptions"`
+	ImagesScanned     int     `json:"images_scanned"`
+	StegoDetected     int     `json:"stego_detected"`
+	ProcessingTimeMs  float64 `json:"processing_time_ms"`
+	Error             string  `json:"error,omitempty"`
+}
+
+// blockRangeSummary is the scan_block_range response.
+type blockRangeSummary struct {
+	StartHeight           int64              `json:"start_height"`
+	EndHeight             int64              `json:"end_height"`
+	BlocksScanned         int                `json:"blocks_scanned"`
+	BlocksFailed          int                `json:"blocks_failed"`
+	TotalInscriptions     int                `json:"total_inscriptions"`
+	TotalImagesScanned    int                `json:"total_images_scanned"`
+	TotalStegoDetected    int                `json:"total_stego_detected"`
+	TotalProcessingTimeMs float64            `json:"total_processing_time_ms"`
+	Blocks                []blockRangeResult `json:"blocks"`
+	Complete              bool               `json:"complete"`
+	StoppedReason         string             `json:"stopped_reason,omitempty"`
+	NextHeight            *int64             `json:"next_height,omitempty"`
+}
+
+// anonymousRateLimitKey is the rate-limit bucket shared by scan_block_range
+// calls that carry no API key, so unauthenticated range scans are limited too.
+const anonymousRateLimitKey = "anonymous"
+
+// SetScanLimiter shares the limiter that bounds the REST block scan endpoints,
+// so scan_block_range takes a slot for every block it scans.
+func (h *HTTPMCPServer) SetScanLimiter(l *bitcoin.ScanLimiter) {
+	h.scanLimiter = l
+}
+
+// scanBlockRange scans start..end inclusive. allow is consulted before every
+// block after the first; when it refuses, the scan stops and the summary
+// records where to resume. Each block holds a slot of slots (when non-nil)
+// while it is scanned, and the scan stops if none frees up in time. An open
+// circuit breaker also stops the scan, since every later block would fail
+// the same way.
+func scanBlockRange(ctx context.Context, scanner blockScanner, start, end int64, options core.ScanOptions, allow func() bool, slots *bitcoin.ScanLimiter) blockRangeSummary {
+	summary := blockRangeSummary{
+		StartHeight: start,
+		EndHeight:   end,
+		Blocks:      []blockRangeResult{},
+		Complete:    true,
+	}
+	stop := func(height int64, reason string) {
+		summary.Complete = false
+		summary.StoppedReason = reason
+		summary.NextHeight = &height
+	}
+
+	for height := start; height <= end; height++ {
+		if ctx.Err() != nil {
+			stop(height, "cancelled")
+			break
+		}
+		if height > start && allow != nil && !allow() {
+			stop(height, "rate_limited")
+			break
+		}
+
+		if slots != nil && !slots.Acquire(ctx) {
+			stop(height, "scanner_busy")
+			break
+		}
+		resp, err := scanner.ScanBlock(height, options)
+		if slots != nil {
+			slots.Release()
+		}
+		if errors.Is(err, starlight.ErrCircuitOpen) {
+			stop(height, "scanner_unavailable")
+			break
+		}
+		entry := blockRangeResult{BlockHeight: height}
+		if err != nil {
+			entry.Error = err.Error()
+			summary.BlocksFailed++
+			summary.Blocks = append(summary.Blocks, entry)
+			continue
+		}
+
+		entry.BlockHash = resp.BlockHash
+		entry.TotalInscriptions = resp.TotalInscriptions
+		entry.ImagesScanned = resp.ImagesScanned
+		entry.StegoDetected = resp.StegoDetected
+		entry.ProcessingTimeMs = resp.ProcessingTimeMs
+		summary.Blocks = append(summary.Blocks, entry)
+
+		summary.BlocksScanned++
+		summary.TotalInscriptions += resp.TotalInscriptions
+		summary.TotalImagesScanned += resp.ImagesScanned
+		summary.TotalStegoDetected += resp.StegoDetected
+		summary.TotalProcessingTimeMs += resp.ProcessingTimeMs
+	}
+	return summary
+}
+
+// parseScanOptions reads the optional ScanOptions arguments, defaulting to
+// the options scan_image uses.
+func parseScanOptions(args map[string]interface{}) core.ScanOptions {
+	options := core.ScanOptions{
+		ExtractMessage:      true,
+		ConfidenceThreshold: 0.5,
+		IncludeMetadata:     true,
+	}
+	if v, ok := coerce.Bool(args["extract_message"]); ok {
+		options.ExtractMessage = v
+	}
+	if v, ok := coerce.Float64(args["confidence_threshold"]); ok && v >= 0 && v <= 1 {
+		options.ConfidenceThreshold = v
+	}
+	if v, ok := coerce.Bool(args["include_metadata"]); ok {
+		options.IncludeMetadata = v
+	}
+	return options
+}
+
+// handleScanBlockRange scans a span of blocks and aggregates the per-block
+// results. The call itself is charged one rate-limit slot by the dispatcher;
+// every further block charges the caller's key again (or the shared anonymous
+// bucket when there is none) so a wide range cannot starve other agents.
+func (h *HTTPMCPServer) handleScanBlockRange(ctx context.Context, args map[string]interface{}, apiKey string) (interface{}, error) {
+	if h.scannerManager == nil {
+		return nil, NewServiceUnavailableError("scan_block_range", "scanner")
+	}
+
+	start, ok := coerce.Int64(args["start_height"])
+	if !ok || start < 0 {
+		return nil, NewValidationError("scan_block_range", "start_height is required and must be a non-negative integer")
+	}
+	end, ok := coerce.Int64(args["end_height"])
+	if !ok || end < 0 {
+		return nil, NewValidationError("scan_block_range", "end_height is required and must be a non-negative integer")
+	}
+	if end < start {
+		return nil, NewValidationError("scan_block_range", "end_height must not be below start_height")
+	}
+	maxSpan := h.maxBlockRange
+	if maxSpan <= 0 {
+		maxSpan = defaultMaxBlockRange
+	}
+	if end-start+1 > int64(maxSpan) {
+		return nil, NewValidationError("scan_block_range", fmt.Sprintf("range covers %d blocks; at most %d blocks per call", end-start+1, maxSpan))
+	}
+
+	return scanBlockRange(ctx, h.scannerManager, start, end, parseScanOptions(args), h.blockRangeAllow(apiKey), h.scanLimiter), nil
+}
+
+// blockRangeAllow returns the per-block rate-limit check for a range scan:
+// the caller's key, or the shared anonymous bucket without a key store or key.
+func (h *HTTPMCPServer) blockRangeAllow(apiKey string) func() bool {
+	key := apiKey
+	if h.apiKeyStore == nil || key == "" {
+		key = anonymousRateLimitKey
+	}
+	return func() bool { return h.checkRateLimit(key) }
+}
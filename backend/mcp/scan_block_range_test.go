@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stargate-backend/bitcoin"
+	"stargate-backend/core"
+	"stargate-backend/services"
+	"stargate-backend/starlight"
+	auth "stargate-backend/storage/auth"
+)
+
+type fakeBlockScanner struct {
+	scanned []int64
+	fail    map[int64]error
+}
+
+func (f *fakeBlockScanner) ScanBlock(height int64, _ core.ScanOptions) (*core.BlockScanResponse, error) {
+	f.scanned = append(f.scanned, height)
+	if err := f.fail[height]; err != nil {
+		return nil, err
+	}
+	return &core.BlockScanResponse{
+		BlockHeight:       height,
+		BlockHash:         "hash",
+		TotalInscriptions: 3,
+		ImagesScanned:     2,
+		StegoDetected:     1,
+		ProcessingTimeMs:  5,
+	}, nil
+}
+
+func TestScanBlockRangeAggregates(t *testing.T) {
+	scanner := &fakeBlockScanner{fail: map[int64]error{102: errors.New("block not found")}}
+	summary := scanBlockRange(context.Background(), scanner, 100, 103, core.ScanOptions{}, nil, nil)
+
+	if !summary.Complete || summary.NextHeight != nil {
+		t.Fatalf("expected complete scan, got %+v", summary)
+	}
+	if summary.BlocksScanned != 3 || summary.BlocksFailed != 1 || len(summary.Blocks) != 4 {
+		t.Fatalf("unexpected block counts: %+v", summary)
+	}
+	if summary.TotalInscriptions != 9 || summary.TotalStegoDetected != 3 || summary.TotalImagesScanned != 6 {
+		t.Fatalf("unexpected totals: %+v", summary)
+	}
+	if summary.Blocks[2].Error != "block not found" {
+		t.Fatalf("expected failed block to carry its error, got %+v", summary.Blocks[2])
+	}
+}
+
+func TestScanBlockRangeStopsWhenRateLimited(t *testing.T) {
+	scanner := &fakeBlockScanner{}
+	budget := 2
+	allow := func() bool {
+		if budget == 0 {
+			return false
+		}
+		budget--
+		return true
+	}
+
+	summary := scanBlockRange(context.Background(), scanner, 10, 19, core.ScanOptions{}, allow, nil)
+	if summary.Complete || summary.StoppedReason != "rate_limited" {
+		t.Fatalf("expected rate-limited stop, got %+v", summary)
+	}
+	if len(scanner.scanned) != 3 || summary.NextHeight == nil || *summary.NextHeight != 13 {
+		t.Fatalf("expected 3 blocks scanned and resume at 13, got %v next=%v", scanner.scanned, summary.NextHeight)
+	}
+}
+
+func TestScanBlockRangeSharesScanLimiter(t *testing.T) {
+	slots := bitcoin.NewScanLimiter(1, 0)
+	scanner := &fakeBlockScanner{}
+	summary := scanBlockRange(context.Background(), scanner, 1, 3, core.ScanOptions{}, nil, slots)
+	if !summary.Complete || slots.InFlight() != 0 {
+		t.Fatalf("expected a complete scan that releases its slot, got %+v (in flight %d)", summary, slots.InFlight())
+	}
+
+	// A REST scan holds the only slot, so the range scan stops before scanning.
+	if !slots.Acquire(context.Background()) {
+		t.Fatal("acquire")
+	}
+	defer slots.Release()
+	scanner = &fakeBlockScanner{}
+	summary = scanBlockRange(context.Background(), scanner, 1, 3, core.ScanOptions{}, nil, slots)
+	if summary.StoppedReason != "scanner_busy" || *summary.NextHeight != 1 || len(scanner.scanned) != 0 {
+		t.Fatalf("expected stop while the limiter is full, got %+v", summary)
+	}
+}
+
+func TestHandleScanBlockRangeRateLimitsWithoutKeyStore(t *testing.T) {
+	server := NewHTTPMCPServer(nil, nil, nil, &services.IngestionService{}, &starlight.ScannerManager{}, nil, auth.NewChallengeStore(10*time.Minute))
+	for i := 0; i < keyRateLimit; i++ {
+		server.checkRateLimit(anonymousRateLimitKey)
+	}
+	scanner := &fakeBlockScanner{}
+	summary := scanBlockRange(context.Background(), scanner, 1, 3, core.ScanOptions{}, server.blockRangeAllow("tb1q-any-key"), nil)
+	if summary.StoppedReason != "rate_limited" || len(scanner.scanned) != 1 {
+		t.Fatalf("expected the anonymous bucket to stop the scan, got %+v", summary)
+	}
+}
+
+func TestScanBlockRangeStopsOnOpenCircuit(t *testing.T) {
+	scanner := &fakeBlockScanner{fail: map[int64]error{5: starlight.ErrCircuitOpen}}
+	summary := scanBlockRange(context.Background(), scanner, 4, 8, core.ScanOptions{}, nil, nil)
+	if summary.StoppedReason != "scanner_unavailable" || *summary.NextHeight != 5 || len(scanner.scanned) != 2 {
+		t.Fatalf("expected stop at open circuit, got %+v", summary)
+	}
+}
+
+func TestHandleScanBlockRangeValidatesSpan(t *testing.T) {
+	t.Setenv("STARGATE_MCP_MAX_BLOCK_RANGE", "5")
+	server := NewHTTPMCPServer(nil, nil, nil, &services.IngestionService{}, &starlight.ScannerManager{}, nil, auth.NewChallengeStore(10*time.Minute))
+
+	cases := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"missing start", map[string]interface{}{"end_height": 10}},
+		{"negative end", map[string]interface{}{"start_height": 1, "end_height": -1}},
+		{"reversed", map[string]interface{}{"start_height": 10, "end_height": 9}},
+		{"over max span", map[string]interface{}{"start_height": 10, "end_height": 15}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := server.handleScanBlockRange(context.Background(), tc.args, "")
+			var vErr *ValidationError
+			if !errors.As(err, &vErr) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+		})
+	}
+
+	if !server.toolRequiresAuth("scan_block_range") {
+		t.Fatalf("scan_block_range must require auth so the rate limiter applies")
+	}
+}
@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	"stargate-backend/services"
+	"stargate-backend/starlight"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestSubmitWorkRequiresClaimOwner(t *testing.T) {
+	t.Setenv("UPLOADS_DIR", t.TempDir())
+	ctx := context.Background()
+	store := scstore.NewMemoryStore(time.Hour)
+	validator := &multiKeyWalletValidator{wallets: map[string]string{
+		"owner-key":    "tb1qowner",
+		"intruder-key": "tb1qintruder",
+	}}
+	server := NewHTTPMCPServer(store, validator, nil, &services.IngestionService{}, &starlight.ScannerManager{}, nil, auth.NewChallengeStore(10*time.Minute))
+
+	task := smart_contract.Task{TaskID: "mcp-owner-task", ContractID: "mcp-owner-contract", Title: "Own it", Status: "available"}
+	if err := store.UpsertContractWithTasks(ctx, smart_contract.Contract{ContractID: "mcp-owner-contract", Title: "Owners", Status: "active"}, []smart_contract.Task{task}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	claim, err := store.ClaimTask(task.TaskID, "tb1qowner", nil)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	args := map[string]interface{}{
+		"claim_id": claim.ClaimID,
+		"deliverables": map[string]interface{}{
+			"notes": "done",
+			"artifacts": []interface{}{map[string]interface{}{
+				"filename": "result.txt",
+				"content":  base64.StdEncoding.EncodeToString([]byte("result")),
+			}},
+		},
+	}
+
+	_, err = server.callToolDirect(ctx, "submit_work", args, "intruder-key", nil)
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != ErrCodeForbidden || toolErr.HttpStatus != 403 {
+		t.Fatalf("expected forbidden for a different wallet, got %v", err)
+	}
+
+	if _, err := server.callToolDirect(ctx, "submit_work", args, "owner-key", nil); err != nil {
+		t.Fatalf("owner submit: %v", err)
+	}
+}
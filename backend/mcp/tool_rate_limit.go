@@ -0,0 +1,201 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"stargate-backend/logging"
+)
+
+// rateLimitWindow is the span every MCP rate-limit bucket counts over.
+const rateLimitWindow = time.Minute
+
+// rateLimitSweepInterval is how often the background sweeper drops keys with
+// no request left inside the window.
+const rateLimitSweepInterval = time.Minute
+
+// keyRateLimit is the per-minute budget shared by all calls with one API key.
+const keyRateLimit = 100
+
+// defaultToolRateLimit is the per-minute budget for a tool without an entry
+// in the tool limits map; it covers the cheap read tools.
+const defaultToolRateLimit = 100
+
+// defaultToolRateLimits caps the expensive write tools per API key per minute.
+var defaultToolRateLimits = map[string]int{
+	"claim_task":  10,
+	"submit_work": 5,
+}
+
+// toolRateLimitsFromEnv returns defaultToolRateLimits overridden by
+// STARGATE_MCP_TOOL_RATE_LIMITS, a comma-separated list of tool=limit pairs
+// such as "claim_task=20,build_psbt=5".
+func toolRateLimitsFromEnv() map[string]int {
+	limits := make(map[string]int, len(defaultToolRateLimits))
+	for tool, limit := range defaultToolRateLimits {
+		limits[tool] = limit
+	}
+	raw := os.Getenv("STARGATE_MCP_TOOL_RATE_LIMITS")
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		tool, value, ok := strings.Cut(pair, "=")
+		tool = strings.TrimSpace(tool)
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !ok || tool == "" || err != nil || n <= 0 {
+			log.Printf("Ignoring invalid STARGATE_MCP_TOOL_RATE_LIMITS entry %q", pair)
+			continue
+		}
+		limits[tool] = n
+	}
+	return limits
+}
+
+// toolRateLimit returns the per-minute budget for tool.
+func (h *HTTPMCPServer) toolRateLimit(tool string) int {
+	if limit, ok := h.toolRateLimits[tool]; ok {
+		return limit
+	}
+	return defaultToolRateLimit
+}
+
+// toolRateLimitBucket keys a tool's bucket apart from the key-wide one.
+func toolRateLimitBucket(key, tool string) string {
+	return key + "|tool:" + tool
+}
+
+// rateLimitDenial describes a refused call: the tool whose bucket was full
+// (empty for the key-wide bucket), that bucket's limit, and how long until
+// its oldest request leaves the window.
+type rateLimitDenial struct {
+	Tool       string
+	Limit      int
+	RetryAfter time.Duration
+}
+
+// retryAfterSeconds rounds RetryAfter up to whole seconds, at least one.
+func (d *rateLimitDenial) retryAfterSeconds() int {
+	secs := int((d.RetryAfter + time.Second - 1) / time.Second)
+	if secs < 1 {
+		secs = 1
+	}
+	return secs
+}
+
+// checkToolRateLimit charges one call to tool against both the key-wide
+// bucket and the key's bucket for tool. The call is recorded only when both
+// have room, so a refusal costs nothing.
+func (h *HTTPMCPServer) checkToolRateLimit(key, tool string) *rateLimitDenial {
+	h.rateLimiterMu.Lock()
+	defer h.rateLimiterMu.Unlock()
+
+	now := h.now()
+	keyTimes := h.pruneRateLimitLocked(key, now)
+	if len(keyTimes) >= keyRateLimit {
+		return &rateLimitDenial{Limit: keyRateLimit, RetryAfter: keyTimes[0].Add(rateLimitWindow).Sub(now)}
+	}
+	bucket := toolRateLimitBucket(key, tool)
+	toolTimes := h.pruneRateLimitLocked(bucket, now)
+	if limit := h.toolRateLimit(tool); len(toolTimes) >= limit {
+		return &rateLimitDenial{Tool: tool, Limit: limit, RetryAfter: toolTimes[0].Add(rateLimitWindow).Sub(now)}
+	}
+	h.rateLimiter[key] = append(keyTimes, now)
+	h.rateLimiter[bucket] = append(toolTimes, now)
+	return nil
+}
+
+// pruneRateLimitLocked drops requests older than the window from bucket and
+// returns what is left. Callers must hold rateLimiterMu.
+func (h *HTTPMCPServer) pruneRateLimitLocked(bucket string, now time.Time) []time.Time {
+	window := now.Add(-rateLimitWindow)
+	times := h.rateLimiter[bucket]
+	valid := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		if t.After(window) {
+			valid = append(valid, t)
+		}
+	}
+	h.rateLimiter[bucket] = valid
+	return valid
+}
+
+// now is the limiter's clock; tests replace clock to move time forward.
+func (h *HTTPMCPServer) now() time.Time {
+	if h.clock != nil {
+		return h.clock()
+	}
+	return time.Now()
+}
+
+// sweepRateLimits purges idle rate-limit keys every interval until stop is
+// closed. Without it a key that stops calling keeps its bucket until the next
+// admin GC run.
+func (h *HTTPMCPServer) sweepRateLimits(interval time.Duration, stop <-chan struct{}) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			if purged, _ := h.purgeRateLimitWindows(context.Background(), h.now()); purged > 0 {
+				logging.Debugf("rate limiter: swept %d idle keys", purged)
+			}
+		}
+	}
+}
+
+// Close stops the background rate-limit sweeper. It is safe to call more
+// than once.
+func (h *HTTPMCPServer) Close() {
+	h.closeOnce.Do(func() {
+		if h.stopSweep != nil {
+			close(h.stopSweep)
+		}
+	})
+}
+
+// rateLimitedError is the tool error for a refused call. It names the tool
+// that was limited and carries the exact retry delay.
+func rateLimitedError(tool string, denial *rateLimitDenial) *ToolError {
+	scope := "API key"
+	if denial.Tool != "" {
+		scope = denial.Tool
+	}
+	return &ToolError{
+		Code:    ErrCodeRateLimited,
+		Message: fmt.Sprintf("Rate limit exceeded for %s (%d requests per minute).", scope, denial.Limit),
+		Tool:    tool,
+		Hint:    fmt.Sprintf("Retry after %d seconds.", denial.retryAfterSeconds()),
+		Details: map[string]interface{}{
+			"limited_tool":        denial.Tool,
+			"limit_per_minute":    denial.Limit,
+			"retry_after_seconds": denial.retryAfterSeconds(),
+		},
+		HttpStatus: 429,
+	}
+}
+
+// rateLimitInfo is the rate_limits block advertised by the discover
+// endpoints.
+func (h *HTTPMCPServer) rateLimitInfo() map[string]interface{} {
+	perTool := make(map[string]int, len(h.toolRateLimits))
+	for tool, limit := range h.toolRateLimits {
+		perTool[tool] = limit
+	}
+	return map[string]interface{}{
+		"enabled":                     h.apiKeyStore != nil,
+		"window_seconds":              int(rateLimitWindow / time.Second),
+		"per_key_per_minute":          keyRateLimit,
+		"default_per_tool_per_minute": defaultToolRateLimit,
+		"per_tool_per_minute":         perTool,
+		"notes":                       "Limits apply per API key to authenticated tool calls. A refused call fails with RATE_LIMITED, names the limited tool and sets Retry-After.",
+	}
+}
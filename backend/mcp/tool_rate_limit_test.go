@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestPerToolRateLimits(t *testing.T) {
+	store := scstore.NewMemoryStore(72 * time.Hour)
+	server := NewHTTPMCPServer(store, allowAllValidator{}, nil, nil, nil, nil, auth.NewChallengeStore(10*time.Minute))
+
+	call := func(t *testing.T, tool, key string) (*httptest.ResponseRecorder, MCPResponse) {
+		t.Helper()
+		body, _ := json.Marshal(MCPRequest{Tool: tool, Arguments: map[string]interface{}{"claim_id": "missing-claim", "task_id": "missing-task"}})
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/mcp/call", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-API-Key", key)
+		server.handleToolCall(w, r)
+
+		var resp MCPResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("failed to unmarshal response: %v (%s)", err, w.Body.String())
+		}
+		return w, resp
+	}
+
+	limit := defaultToolRateLimits["submit_work"]
+	for i := 0; i < limit; i++ {
+		if _, resp := call(t, "submit_work", "agent-a"); resp.ErrorCode == ErrCodeRateLimited {
+			t.Fatalf("call %d limited before the %d/min budget was spent", i+1, limit)
+		}
+	}
+
+	w, resp := call(t, "submit_work", "agent-a")
+	if resp.ErrorCode != ErrCodeRateLimited {
+		t.Fatalf("expected %s after %d calls, got %+v", ErrCodeRateLimited, limit, resp)
+	}
+	if resp.Details["limited_tool"] != "submit_work" || resp.Details["tool"] != "submit_work" {
+		t.Fatalf("error does not name the limited tool: %+v", resp.Details)
+	}
+	if !strings.Contains(resp.Error, "submit_work") {
+		t.Fatalf("error message does not name the tool: %q", resp.Error)
+	}
+	if w.Header().Get("Retry-After") == "" || resp.RetryAfterSeconds <= 0 || resp.RetryAfterSeconds > 60 {
+		t.Fatalf("Retry-After %q, retry_after_seconds %d", w.Header().Get("Retry-After"), resp.RetryAfterSeconds)
+	}
+
+	if _, resp := call(t, "claim_task", "agent-a"); resp.ErrorCode == ErrCodeRateLimited {
+		t.Fatalf("claim_task limited by the submit_work bucket: %+v", resp)
+	}
+	if _, resp := call(t, "submit_work", "agent-b"); resp.ErrorCode == ErrCodeRateLimited {
+		t.Fatalf("another key limited by agent-a's bucket: %+v", resp)
+	}
+}
+
+func TestCheckToolRateLimitRefusalIsFree(t *testing.T) {
+	server := NewHTTPMCPServer(nil, allowAllValidator{}, nil, nil, nil, nil, auth.NewChallengeStore(10*time.Minute))
+	server.toolRateLimits = map[string]int{"claim_task": 1}
+
+	if denial := server.checkToolRateLimit("k", "claim_task"); denial != nil {
+		t.Fatalf("first call refused: %+v", denial)
+	}
+	for i := 0; i < 3; i++ {
+		if denial := server.checkToolRateLimit("k", "claim_task"); denial == nil || denial.Tool != "claim_task" || denial.Limit != 1 {
+			t.Fatalf("expected claim_task denial, got %+v", denial)
+		}
+	}
+	server.rateLimiterMu.Lock()
+	spent := len(server.rateLimiter["k"])
+	server.rateLimiterMu.Unlock()
+	if spent != 1 {
+		t.Fatalf("refused calls were charged to the key-wide bucket: %d entries", spent)
+	}
+	if denial := server.checkToolRateLimit("k", "list_tasks"); denial != nil {
+		t.Fatalf("read tool refused: %+v", denial)
+	}
+}
+
+func TestRateLimitSweeperDropsIdleKeys(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	server := NewHTTPMCPServer(store, allowAllValidator{}, nil, nil, nil, nil, auth.NewChallengeStore(10*time.Minute))
+	// Stop the constructor's sweeper so the clock can be swapped safely.
+	server.Close()
+	server.Close()
+
+	var mu sync.Mutex
+	now := time.Now()
+	server.clock = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		now = now.Add(d)
+		mu.Unlock()
+	}
+	tracked := func() int {
+		server.rateLimiterMu.Lock()
+		defer server.rateLimiterMu.Unlock()
+		return len(server.rateLimiter)
+	}
+
+	const idle = 500
+	for i := 0; i < idle; i++ {
+		if denial := server.checkToolRateLimit(fmt.Sprintf("idle-%d", i), "list_contracts"); denial != nil {
+			t.Fatalf("unexpected denial: %+v", denial)
+		}
+	}
+	advance(45 * time.Second)
+	server.checkRateLimit("active-key")
+	if got := tracked(); got != 2*idle+1 {
+		t.Fatalf("tracked %d buckets, want %d", got, 2*idle+1)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go server.sweepRateLimits(5*time.Millisecond, stop)
+
+	advance(30 * time.Second)
+	deadline := time.Now().Add(2 * time.Second)
+	for tracked() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("sweeper left %d buckets, want 1", tracked())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	server.rateLimiterMu.Lock()
+	_, ok := server.rateLimiter["active-key"]
+	server.rateLimiterMu.Unlock()
+	if !ok {
+		t.Fatal("sweeper dropped a key still inside the window")
+	}
+}
+
+func TestToolRateLimitsFromEnv(t *testing.T) {
+	t.Setenv("STARGATE_MCP_TOOL_RATE_LIMITS", "claim_task=20, build_psbt=3,bad,submit_work=0")
+	limits := toolRateLimitsFromEnv()
+	if limits["claim_task"] != 20 || limits["build_psbt"] != 3 || limits["submit_work"] != defaultToolRateLimits["submit_work"] {
+		t.Fatalf("limits = %v", limits)
+	}
+}
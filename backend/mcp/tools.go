@@ -50,6 +50,20 @@ func (h *HTTPMCPServer) getToolSchemasLegacy() map[string]interface{} {
 					"items":       map[string]interface{}{"type": "string"},
 					"description": "Filter contracts by required skills",
 				},
+				"funding_status": map[string]interface{}{
+					"type":        "string",
+					"description": "Filter contracts by funding confirmed in task proofs",
+					"enum":        []string{smart_contract.FundingStatusFunded, smart_contract.FundingStatusPartial, smart_contract.FundingStatusUnfunded},
+				},
+				"inactive_since": map[string]interface{}{
+					"type":        "string",
+					"description": "Only contracts with no activity since this RFC3339 time",
+				},
+				"order_by": map[string]interface{}{
+					"type":        "string",
+					"description": "Sort order; last_activity lists the least recently active first",
+					"enum":        []string{"last_activity"},
+				},
 				"limit": map[string]interface{}{
 					"type":        "integer",
 					"description": "Maximum number of contracts to return (default: 50)",
@@ -172,9 +186,43 @@ func (h *HTTPMCPServer) getToolSchemasLegacy() map[string]interface{} {
 				},
 			},
 		},
+		"get_claim": map[string]interface{}{
+			"category":    ToolCategoryDiscovery,
+			"description": "Look up a claim by ID: its task, wallet, status, expiry and any submission made under it. Use it to recover a claim_id whose claim_task response was lost",
+			"parameters": map[string]interface{}{
+				"claim_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The ID of the claim to retrieve",
+					"required":    true,
+				},
+			},
+			"examples": []map[string]interface{}{
+				{
+					"description": "Check a claim's status and expiry",
+					"arguments":   map[string]interface{}{"claim_id": "claim-123"},
+				},
+			},
+		},
+		"get_task_commitment": map[string]interface{}{
+			"category":    ToolCategoryDiscovery,
+			"description": "Get a task's escrow commitment (address, vout, sats, redeem script) and whether its funding transaction has confirmed on-chain",
+			"parameters": map[string]interface{}{
+				"task_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The ID of the task whose commitment to retrieve",
+					"required":    true,
+				},
+			},
+			"examples": []map[string]interface{}{
+				{
+					"description": "Check task funding status",
+					"arguments":   map[string]interface{}{"task_id": "task-123"},
+				},
+			},
+		},
 		"get_scanner_info": map[string]interface{}{
 			"category":    ToolCategoryDiscovery,
-			"description": "Get information about the steganographic scanner status and version",
+			"description": "Get information about the steganographic scanner status and version, including its circuit breaker state (closed, open or half-open)",
 			"parameters":  map[string]interface{}{},
 			"examples": []map[string]interface{}{
 				{
@@ -201,6 +249,15 @@ func (h *HTTPMCPServer) getToolSchemasLegacy() map[string]interface{} {
 					"description": "Filter by task status",
 					"enum":        []string{smart_contract.TaskStatusAvailable, smart_contract.TaskStatusClaimed, smart_contract.TaskStatusCompleted},
 				},
+				"inactive_since": map[string]interface{}{
+					"type":        "string",
+					"description": "Only tasks with no activity since this RFC3339 time",
+				},
+				"order_by": map[string]interface{}{
+					"type":        "string",
+					"description": "Sort order; last_activity lists the least recently active first",
+					"enum":        []string{"last_activity"},
+				},
 				"limit": map[string]interface{}{
 					"type":        "integer",
 					"description": "Maximum number of tasks to return (default: 50)",
@@ -516,6 +573,62 @@ func (h *HTTPMCPServer) getToolSchemasLegacy() map[string]interface{} {
 				},
 			},
 		},
+		"scan_block_range": map[string]interface{}{
+			"category":    ToolCategoryDiscovery,
+			"description": "Scan a range of blocks for steganographic inscriptions and return per-block results with range totals. The span is capped (default 50 blocks) and each block after the first counts against the caller's rate limit.",
+			"parameters": map[string]interface{}{
+				"start_height": map[string]interface{}{
+					"type":        "integer",
+					"description": "First block height to scan",
+					"required":    true,
+				},
+				"end_height": map[string]interface{}{
+					"type":        "integer",
+					"description": "Last block height to scan (inclusive)",
+					"required":    true,
+				},
+				"extract_message": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Extract hidden messages from detected images (default true)",
+				},
+				"confidence_threshold": map[string]interface{}{
+					"type":        "number",
+					"description": "Minimum stego probability between 0 and 1 (default 0.5)",
+				},
+				"include_metadata": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Include scan metadata (default true)",
+				},
+			},
+			"examples": []map[string]interface{}{
+				{
+					"description": "Scan ten blocks",
+					"arguments": map[string]interface{}{
+						"start_height": 170000,
+						"end_height":   170009,
+					},
+				},
+			},
+		},
+		"get_block_inscriptions": map[string]interface{}{
+			"category":    ToolCategoryDiscovery,
+			"description": "Get the inscriptions the block monitor parsed for a block height, with content types, sizes, and file paths",
+			"parameters": map[string]interface{}{
+				"height": map[string]interface{}{
+					"type":        "integer",
+					"description": "Block height to read",
+					"required":    true,
+				},
+			},
+			"examples": []map[string]interface{}{
+				{
+					"description": "List inscriptions in a block",
+					"arguments": map[string]interface{}{
+						"height": 170000,
+					},
+				},
+			},
+		},
 		"list_events": map[string]interface{}{
 			"category":    ToolCategoryDiscovery,
 			"description": "List recent MCP events with optional filters",
@@ -830,7 +943,7 @@ func (h *HTTPMCPServer) getToolSchemasLegacy() map[string]interface{} {
 				},
 				"commitment_sats": map[string]interface{}{
 					"type":        "integer",
-					"description": "Optional sats to lock in commitment output (min 546 sats)",
+					"description": "Optional sats to lock in commitment output (min 546 sats, the dust limit; 1000 recommended)",
 				},
 				"change_address": map[string]interface{}{
 					"type":        "string",
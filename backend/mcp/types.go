@@ -21,6 +21,21 @@ type MCPResponse struct {
 	RequestID      string                 `json:"request_id,omitempty"`
 	Version        string                 `json:"version,omitempty"`
 	Details        map[string]interface{} `json:"details,omitempty"`
+	// Retryable is set on every error response; nil on success.
+	Retryable         *bool `json:"retryable,omitempty"`
+	RetryAfterSeconds int   `json:"retry_after_seconds,omitempty"`
+}
+
+// setRetryPolicy classifies the response's ErrorCode with RetryPolicy. A
+// retry_after_seconds detail, such as a rate limiter's exact delay, replaces
+// the code's default delay.
+func (r *MCPResponse) setRetryPolicy() {
+	retryable, after := RetryPolicy(r.ErrorCode)
+	if exact, ok := r.Details["retry_after_seconds"].(int); ok && retryable && exact > 0 {
+		after = exact
+	}
+	r.Retryable = &retryable
+	r.RetryAfterSeconds = after
 }
 
 type jsonRPCRequest struct {
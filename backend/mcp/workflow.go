@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+)
+
+// WorkflowStep is one machine-readable step of the agent workflow returned by
+// the get_workflow tool. Tool names and inputs must match the tool schemas;
+// TestAgentWorkflowReferencesRealTools keeps them in sync.
+type WorkflowStep struct {
+	Step            int      `json:"step"`
+	Name            string   `json:"name"`
+	Actor           string   `json:"actor"`
+	Tool            string   `json:"tool"`
+	Endpoint        string   `json:"endpoint"`
+	AuthRequired    bool     `json:"auth_required"`
+	RequiredInputs  []string `json:"required_inputs"`
+	ExpectedOutputs []string `json:"expected_outputs"`
+	Preconditions   []string `json:"preconditions"`
+}
+
+// agentWorkflow lists the wish-to-payout flow from inscription to review.
+// Actor "requester" owns the wish and approvals; "worker" does the tasks.
+func agentWorkflow() []WorkflowStep {
+	return []WorkflowStep{
+		{
+			Step:            1,
+			Name:            "inscribe",
+			Actor:           "requester",
+			Tool:            "create_wish",
+			Endpoint:        "POST /api/inscribe",
+			AuthRequired:    true,
+			RequiredInputs:  []string{"message"},
+			ExpectedOutputs: []string{"visible_pixel_hash", "ingestion_id"},
+			Preconditions:   []string{"API key bound to the requester wallet (get_auth_challenge, verify_auth_challenge)"},
+		},
+		{
+			Step:            2,
+			Name:            "propose",
+			Actor:           "worker",
+			Tool:            "create_proposal",
+			Endpoint:        "POST /api/smart_contract/proposals",
+			AuthRequired:    true,
+			RequiredInputs:  []string{"title", "visible_pixel_hash"},
+			ExpectedOutputs: []string{"proposal.id", "proposal.status=pending"},
+			Preconditions:   []string{"wish from step 1 is pending (get_open_contracts)"},
+		},
+		{
+			Step:            3,
+			Name:            "approve",
+			Actor:           "requester",
+			Tool:            "approve_proposal",
+			Endpoint:        "POST /api/smart_contract/proposals/{proposal_id}/approve",
+			AuthRequired:    true,
+			RequiredInputs:  []string{"proposal_id"},
+			ExpectedOutputs: []string{"proposal_id", "tasks available to claim"},
+			Preconditions:   []string{"proposal is pending", "caller created the wish", "proposal defines at least one task"},
+		},
+		{
+			Step:            4,
+			Name:            "claim",
+			Actor:           "worker",
+			Tool:            "claim_task",
+			Endpoint:        "POST /api/smart_contract/tasks/{task_id}/claim",
+			AuthRequired:    true,
+			RequiredInputs:  []string{"task_id"},
+			ExpectedOutputs: []string{"claim.claim_id", "claim.expires_at"},
+			Preconditions:   []string{"task status is available (list_tasks)"},
+		},
+		{
+			Step:            5,
+			Name:            "submit",
+			Actor:           "worker",
+			Tool:            "submit_work",
+			Endpoint:        "POST /api/smart_contract/claims/{claim_id}/submit",
+			AuthRequired:    true,
+			RequiredInputs:  []string{"claim_id", "deliverables"},
+			ExpectedOutputs: []string{"submission.submission_id", "submission.status=pending_review"},
+			Preconditions:   []string{"claim from step 4 is active and not expired"},
+		},
+		{
+			Step:            6,
+			Name:            "review",
+			Actor:           "requester",
+			Tool:            "approve_submission",
+			Endpoint:        "POST /api/smart_contract/submissions/{submission_id}/review",
+			AuthRequired:    true,
+			RequiredInputs:  []string{"submission_id"},
+			ExpectedOutputs: []string{"submission_id", "task status=approved"},
+			Preconditions:   []string{"submission is pending_review (list_submissions)", "use reject_submission instead to request rework"},
+		},
+	}
+}
+
+// handleGetWorkflow is the implementation for the get_workflow MCP tool.
+func (h *HTTPMCPServer) handleGetWorkflow(ctx context.Context, args map[string]interface{}, r *http.Request) (interface{}, error) {
+	base := h.externalBaseURL(r)
+	return map[string]interface{}{
+		"steps":        agentWorkflow(),
+		"tool_call":    base + "/mcp/call",
+		"api_base":     base,
+		"skill_md_url": base + "/mcp/SKILL.md",
+	}, nil
+}
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	auth "stargate-backend/storage/auth"
+)
+
+func TestAgentWorkflowReferencesRealTools(t *testing.T) {
+	server := NewHTTPMCPServer(nil, nil, nil, nil, nil, nil, auth.NewChallengeStore(10*time.Minute))
+	tools := map[string]ToolDefinition{}
+	for _, tool := range server.guidance.Tools {
+		tools[tool.Name] = tool
+	}
+
+	steps := agentWorkflow()
+	for i, step := range steps {
+		if step.Step != i+1 {
+			t.Errorf("step %q numbered %d, want %d", step.Name, step.Step, i+1)
+		}
+		tool, ok := tools[step.Tool]
+		if !ok {
+			t.Errorf("step %q references unknown tool %q", step.Name, step.Tool)
+			continue
+		}
+		if step.AuthRequired != tool.AuthRequired {
+			t.Errorf("step %q auth_required = %v, tool %s says %v", step.Name, step.AuthRequired, step.Tool, tool.AuthRequired)
+		}
+		var required []string
+		for name, param := range tool.Parameters {
+			if param.Required {
+				required = append(required, name)
+			}
+		}
+		got := append([]string(nil), step.RequiredInputs...)
+		sort.Strings(required)
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(required, ",") {
+			t.Errorf("step %q required_inputs = %v, tool %s requires %v", step.Name, step.RequiredInputs, step.Tool, required)
+		}
+		if !strings.HasPrefix(step.Endpoint, "POST /api/") {
+			t.Errorf("step %q endpoint %q is not a REST call", step.Name, step.Endpoint)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/mcp/call", nil)
+	r.Host = "api.starlight.local"
+	result, err := server.callToolDirect(context.Background(), "get_workflow", map[string]interface{}{}, "", r)
+	if err != nil {
+		t.Fatalf("get_workflow without a store: %v", err)
+	}
+	body, _ := result.(map[string]interface{})
+	if got, _ := body["steps"].([]WorkflowStep); len(got) != len(steps) {
+		t.Fatalf("get_workflow steps = %v, want %d steps", body["steps"], len(steps))
+	}
+	if body["tool_call"] != "http://api.starlight.local/mcp/call" {
+		t.Fatalf("tool_call = %v", body["tool_call"])
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
@@ -22,7 +23,8 @@ func CORS(next http.Handler) http.Handler {
 		}
 		w.Header().Set("Access-Control-Allow-Origin", origin)
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-API-Key, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization, X-API-Key, X-Requested-With, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader)
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		if r.Method == "OPTIONS" {
@@ -52,18 +54,31 @@ func Logging(next http.Handler) http.Handler {
 			"status":   wrapped.statusCode,
 			"duration": duration.String(),
 		}
+		if id := RequestIDFromContext(r.Context()); id != "" {
+			entry["request_id"] = id
+		}
 		if err := json.NewEncoder(log.Writer()).Encode(entry); err != nil {
 			log.Printf("%s %s %d %v", r.Method, r.URL.Path, wrapped.statusCode, duration)
 		}
 	})
 }
 
-// Recovery middleware
+// Recovery turns a handler panic into a generic 500 and logs the panic with
+// its request ID and stack. http.ErrAbortHandler is re-raised so net/http
+// can abort the response as intended.
 func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("Panic recovered: %v", err)
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				id := RequestIDFromContext(r.Context())
+				if id == "" {
+					// Outermost in the chain, the context predates RequestID.
+					id = w.Header().Get(RequestIDHeader)
+				}
+				log.Printf("Panic recovered (request_id=%s) %s %s: %v\n%s", id, r.Method, r.URL.Path, err, debug.Stack())
 
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusInternalServerError)
@@ -141,6 +156,7 @@ func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
 				strings.Contains(r.URL.Path, "/chat/stream") ||
 				strings.Contains(r.URL.Path, "/mcp/events") ||
 				strings.Contains(r.URL.Path, "/smart_contract/events") ||
+				r.URL.Path == "/api/monitor/events" ||
 				r.URL.Path == "/api/health" ||
 				r.URL.Path == "/bitcoin/v1/health" {
 				next.ServeHTTP(w, r)
@@ -157,7 +173,9 @@ func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
 			done := make(chan struct{})
 			go func() {
 				defer close(done)
-				next.ServeHTTP(tracked, r)
+				// A panic here is on our goroutine, out of reach of the
+				// outer Recovery and of net/http, and would end the process.
+				Recovery(next).ServeHTTP(tracked, r)
 			}()
 
 			select {
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev, prevFlags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	t.Cleanup(func() {
+		log.SetOutput(prev)
+		log.SetFlags(prevFlags)
+	})
+	return &buf
+}
+
+func panicking(w http.ResponseWriter, r *http.Request) {
+	var m map[string]interface{}
+	_ = m["height"].(float64) // unchecked assertion on malformed input
+}
+
+func TestRecoveryReturns500AndLogsStack(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.Handler
+	}{
+		// Recovery outermost: the id comes from the response header.
+		{"outer", Recovery(RequestID(http.HandlerFunc(panicking)))},
+		// Behind Timeout the handler runs on its own goroutine.
+		{"timeout goroutine", Recovery(RequestID(Timeout(time.Second)(http.HandlerFunc(panicking))))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLog(t)
+			req := httptest.NewRequest(http.MethodGet, "/api/blocks", nil)
+			req.Header.Set(RequestIDHeader, "trace-panic-1")
+			rec := httptest.NewRecorder()
+
+			tt.handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusInternalServerError {
+				t.Fatalf("status %d, want 500", rec.Code)
+			}
+			if body := rec.Body.String(); !strings.Contains(body, "internal_server_error") || strings.Contains(body, "interface conversion") {
+				t.Fatalf("body %q, want generic error without panic details", body)
+			}
+			out := logs.String()
+			for _, want := range []string{"request_id=trace-panic-1", "interface conversion", "goroutine ", "middleware.panicking"} {
+				if !strings.Contains(out, want) {
+					t.Errorf("log missing %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
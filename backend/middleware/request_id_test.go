@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestRequestIDGeneratedWhenAbsent(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+		if got := r.Header.Get(RequestIDHeader); got != seen {
+			t.Errorf("request header %q, context %q", got, seen)
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+
+	if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(seen) {
+		t.Fatalf("generated id %q, want 32 hex chars", seen)
+	}
+	if got := rec.Header().Get(RequestIDHeader); got != seen {
+		t.Fatalf("response id %q, want %q", got, seen)
+	}
+}
+
+func TestRequestIDPreservedWhenPresent(t *testing.T) {
+	var seen string
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	req.Header.Set(RequestIDHeader, "client-trace-42")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if seen != "client-trace-42" {
+		t.Fatalf("context id %q, want client-trace-42", seen)
+	}
+	if got := rec.Header().Get(RequestIDHeader); got != "client-trace-42" {
+		t.Fatalf("response id %q, want client-trace-42", got)
+	}
+}
+
+func TestRequestIDReplacesInvalidInbound(t *testing.T) {
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "bad id\twith spaces")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got == "bad id\twith spaces" || got == "" {
+		t.Fatalf("response id %q, want a generated id", got)
+	}
+}
@@ -0,0 +1,29 @@
+package smart_contract
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// orderByLastActivity is the order_by value that sorts least recently active first.
+const orderByLastActivity = "last_activity"
+
+// activityQuery reads the staleness parameters shared by the contract and
+// task listings: inactive_since (RFC3339 or Unix seconds) and
+// order_by=last_activity.
+func activityQuery(r *http.Request) (inactiveSince *time.Time, byActivity bool, err error) {
+	inactiveSince, err = parseExportTime(r.URL.Query().Get("inactive_since"))
+	if err != nil {
+		return nil, false, fmt.Errorf("inactive_since: %v", err)
+	}
+	switch order := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("order_by"))); order {
+	case "":
+	case orderByLastActivity:
+		byActivity = true
+	default:
+		return nil, false, fmt.Errorf("order_by must be %s", orderByLastActivity)
+	}
+	return inactiveSince, byActivity, nil
+}
@@ -0,0 +1,57 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleTasksInactiveSince(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	ctx := context.Background()
+	c := smart_contract.Contract{ContractID: "rest-activity", Title: "Activity", Status: "active"}
+	tasks := []smart_contract.Task{
+		{TaskID: "rest-activity-idle", Title: "Idle", Status: "available"},
+		{TaskID: "rest-activity-busy", Title: "Busy", Status: "available"},
+	}
+	if err := store.UpsertContractWithTasks(ctx, c, tasks); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	cutoff := time.Now().Add(-time.Second)
+	if _, err := store.ClaimTask("rest-activity-busy", "tb1qrestactivity", nil); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	mux := http.NewServeMux()
+	NewServer(store, nil, nil).RegisterRoutes(mux)
+
+	rr := httptest.NewRecorder()
+	url := "/api/smart_contract/tasks?contract_id=rest-activity&order_by=last_activity&inactive_since=" + strconv.FormatInt(cutoff.Unix(), 10)
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, url, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var body struct {
+		Tasks []smart_contract.Task `json:"tasks"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Tasks) != 1 || body.Tasks[0].TaskID != "rest-activity-idle" {
+		t.Fatalf("inactive tasks = %+v, want only rest-activity-idle", body.Tasks)
+	}
+
+	for _, query := range []string{"inactive_since=yesterday", "order_by=title"} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/smart_contract/tasks?"+query, nil))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", query, rr.Code)
+		}
+	}
+}
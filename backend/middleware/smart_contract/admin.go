@@ -0,0 +1,131 @@
+package smart_contract
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+// testAdminEnabled reports whether destructive test-environment admin actions
+// (such as reset-and-seed) are allowed. Controlled by STARGATE_ENABLE_TEST_ADMIN.
+func testAdminEnabled() bool {
+	v, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("STARGATE_ENABLE_TEST_ADMIN")))
+	return err == nil && v
+}
+
+// adminAPIKeysFromEnv reads the comma-separated STARGATE_ADMIN_API_KEYS
+// allow-list. An empty list disables the admin-only endpoints.
+func adminAPIKeysFromEnv() map[string]bool {
+	keys := map[string]bool{}
+	for _, key := range strings.Split(os.Getenv("STARGATE_ADMIN_API_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// adminWrap serves next only for requests whose X-API-Key is valid and on the
+// admin allow-list; a valid key alone is not enough.
+func (s *Server) adminWrap(next http.HandlerFunc) http.HandlerFunc {
+	return s.authWrap(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.adminKeys) == 0 {
+			Error(w, http.StatusForbidden, "admin actions are disabled")
+			return
+		}
+		if !s.adminKeys[r.Header.Get("X-API-Key")] {
+			Error(w, http.StatusForbidden, "admin api key required")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// handleAdminFixtures describes the deterministic fixtures the store seeds.
+func (s *Server) handleAdminFixtures(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	JSON(w, http.StatusOK, scstore.Manifest())
+}
+
+// handleAdminResetAndSeed wipes the store and reloads the deterministic fixtures.
+// Only available when STARGATE_ENABLE_TEST_ADMIN=true.
+func (s *Server) handleAdminResetAndSeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !testAdminEnabled() {
+		Error(w, http.StatusForbidden, "test admin actions are disabled")
+		return
+	}
+	reseeder, ok := s.store.(scstore.Reseeder)
+	if !ok {
+		Error(w, http.StatusNotImplemented, "store does not support reset-and-seed")
+		return
+	}
+	manifest, err := reseeder.ResetAndSeed(r.Context())
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"status":   "reseeded",
+		"manifest": manifest,
+	})
+}
+
+// handleAdminConsistency reports tasks and submissions whose referenced
+// contract, claim or task no longer exists.
+func (s *Server) handleAdminConsistency(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	checker, ok := s.store.(scstore.ConsistencyChecker)
+	if !ok {
+		Error(w, http.StatusNotImplemented, "store does not support consistency checks")
+		return
+	}
+	report, err := checker.CheckConsistency(r.Context())
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	JSON(w, http.StatusOK, report)
+}
+
+// handleAdminRepair reports inconsistent stored data and, when called with
+// dry_run=false, fixes it. Dry run is the default.
+// POST /api/admin/repair (admin keys only)
+func (s *Server) handleAdminRepair(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	dryRun := true
+	if raw := strings.TrimSpace(r.URL.Query().Get("dry_run")); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			Error(w, http.StatusBadRequest, "dry_run must be a boolean")
+			return
+		}
+		dryRun = v
+	}
+	store, ok := s.store.(scstore.RepairStore)
+	if !ok {
+		Error(w, http.StatusNotImplemented, "store does not support repair")
+		return
+	}
+	report, err := scstore.Repair(r.Context(), store, dryRun)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	JSON(w, http.StatusOK, report)
+}
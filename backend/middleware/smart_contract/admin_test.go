@@ -0,0 +1,168 @@
+package smart_contract
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleAdminConsistency(t *testing.T) {
+	const apiKey = "admin-key"
+	t.Setenv("STARGATE_ADMIN_API_KEYS", apiKey)
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{
+		apiKey:       {Key: apiKey},
+		"member-key": {Key: "member-key"},
+	}}
+	mux := http.NewServeMux()
+	NewServer(scstore.NewMemoryStore(time.Hour), keys, nil).RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/smart_contract/admin/consistency", nil)
+	req.Header.Set("X-API-Key", apiKey)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var report scstore.ConsistencyReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.OrphanTasks == nil || report.OrphanSubmissions == nil {
+		t.Fatalf("report lists should be empty arrays, got %s", rec.Body.String())
+	}
+	if len(report.OrphanTasks) != 0 || len(report.OrphanSubmissions) != 0 {
+		t.Fatalf("seeded store reported orphans: %+v", report)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/smart_contract/admin/consistency", nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("unauthenticated status = %d, want 403", rec.Code)
+	}
+}
+
+func TestAdminSmartContractRoutesRequireAdminKey(t *testing.T) {
+	const apiKey = "admin-key"
+	t.Setenv("STARGATE_ADMIN_API_KEYS", apiKey)
+	t.Setenv("STARGATE_ENABLE_TEST_ADMIN", "true")
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{
+		apiKey:       {Key: apiKey},
+		"member-key": {Key: "member-key"},
+	}}
+	mux := http.NewServeMux()
+	NewServer(scstore.NewMemoryStore(time.Hour), keys, nil).RegisterRoutes(mux)
+
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/api/smart_contract/admin/fixtures"},
+		{http.MethodPost, "/api/smart_contract/admin/reset-and-seed"},
+		{http.MethodGet, "/api/smart_contract/admin/consistency"},
+	}
+	for _, route := range routes {
+		for key, want := range map[string]int{"member-key": http.StatusForbidden, apiKey: http.StatusOK} {
+			req := httptest.NewRequest(route.method, route.path, nil)
+			req.Header.Set("X-API-Key", key)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != want {
+				t.Fatalf("%s %s as %s: status = %d, want %d: %s", route.method, route.path, key, rec.Code, want, rec.Body.String())
+			}
+		}
+	}
+}
+
+func TestHandleAdminRepairDefaultsToDryRun(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "mcp.db")
+	store, err := scstore.NewSQLiteStore(dbPath, time.Hour, false)
+	if err != nil {
+		t.Fatalf("sqlite store: %v", err)
+	}
+	t.Cleanup(store.Close)
+	fieldHash := strings.Repeat("a", 64)
+	if err := store.CreateProposal(context.Background(), smart_contract.Proposal{
+		ID:               "repair-proposal",
+		Title:            "Mismatch",
+		VisiblePixelHash: fieldHash,
+		Status:           "pending",
+	}); err != nil {
+		t.Fatalf("create proposal: %v", err)
+	}
+	// Creation and metadata updates reject drift, so write it straight into
+	// the row as legacy data would carry it.
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`UPDATE mcp_proposals SET metadata=json_set(metadata, '$.visible_pixel_hash', ?) WHERE id=?`, strings.Repeat("b", 64), "repair-proposal"); err != nil {
+		t.Fatalf("write mismatched metadata: %v", err)
+	}
+
+	const apiKey = "admin-key"
+	t.Setenv("STARGATE_ADMIN_API_KEYS", apiKey)
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{
+		apiKey:       {Key: apiKey},
+		"member-key": {Key: "member-key"},
+	}}
+	mux := http.NewServeMux()
+	NewServer(store, keys, nil).RegisterRoutes(mux)
+
+	repairAs := func(key, method, query string) (*httptest.ResponseRecorder, scstore.RepairReport) {
+		req := httptest.NewRequest(method, "/api/admin/repair"+query, nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		var report scstore.RepairReport
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatalf("decode report: %v", err)
+			}
+		}
+		return rec, report
+	}
+	repair := func(method, query string) (*httptest.ResponseRecorder, scstore.RepairReport) {
+		return repairAs(apiKey, method, query)
+	}
+
+	// A valid key that is not on the admin allow-list cannot repair.
+	if rec, _ := repairAs("member-key", http.MethodPost, "?dry_run=false"); rec.Code != http.StatusForbidden {
+		t.Fatalf("non-admin status = %d, want 403", rec.Code)
+	}
+
+	rec, report := repair(http.MethodPost, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if !report.DryRun || report.Fixed != 0 || len(report.Actions) != 1 {
+		t.Fatalf("default repair = %+v, want one unfixed dry-run action", report)
+	}
+
+	rec, report = repair(http.MethodPost, "?dry_run=false")
+	if rec.Code != http.StatusOK || report.DryRun || report.Fixed != 1 {
+		t.Fatalf("repair = %d %+v, want one fix", rec.Code, report)
+	}
+	p, err := store.GetProposal(context.Background(), "repair-proposal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Metadata["visible_pixel_hash"] != fieldHash {
+		t.Fatalf("metadata hash not repaired: %v", p.Metadata["visible_pixel_hash"])
+	}
+
+	if rec, _ := repair(http.MethodPost, "?dry_run=maybe"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("bad dry_run status = %d, want 400", rec.Code)
+	}
+	if rec, _ := repair(http.MethodGet, ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET status = %d, want 405", rec.Code)
+	}
+}
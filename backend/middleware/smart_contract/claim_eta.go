@@ -0,0 +1,34 @@
+package smart_contract
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultClaimETAHorizon is how far ahead a claim's estimated_completion may
+// be when STARGATE_CLAIM_ETA_MAX_HORIZON is unset.
+const defaultClaimETAHorizon = 30 * 24 * time.Hour
+
+// claimETAHorizonFromEnv reads STARGATE_CLAIM_ETA_MAX_HORIZON as a Go
+// duration such as "72h". Invalid values are logged and replaced by the
+// default.
+func claimETAHorizonFromEnv() time.Duration {
+	return durationFromEnv("STARGATE_CLAIM_ETA_MAX_HORIZON", defaultClaimETAHorizon)
+}
+
+// normalizeEstimatedCompletion checks that a claim's estimated_completion is
+// after now and no more than horizon ahead, and returns it in UTC. A nil
+// estimate is allowed and stays nil.
+func normalizeEstimatedCompletion(eta *time.Time, now time.Time, horizon time.Duration) (*time.Time, error) {
+	if eta == nil {
+		return nil, nil
+	}
+	if !eta.After(now) {
+		return nil, fmt.Errorf("estimated_completion must be in the future (got %s)", eta.UTC().Format(time.RFC3339))
+	}
+	if eta.After(now.Add(horizon)) {
+		return nil, fmt.Errorf("estimated_completion must be within %s of now (got %s)", horizon, eta.UTC().Format(time.RFC3339))
+	}
+	utc := eta.UTC()
+	return &utc, nil
+}
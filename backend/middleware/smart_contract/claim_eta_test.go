@@ -0,0 +1,83 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleClaimTaskValidatesEstimatedCompletion(t *testing.T) {
+	t.Setenv("STARGATE_CLAIM_ETA_MAX_HORIZON", "72h")
+	store := scstore.NewMemoryStore(time.Hour)
+	contract := smart_contract.Contract{ContractID: "contract-claim-eta", Title: "ETA", Status: "active"}
+	task := smart_contract.Task{TaskID: "claim-eta-task", ContractID: contract.ContractID, Title: "ETA", Status: "available"}
+	if err := store.UpsertContractWithTasks(context.Background(), contract, []smart_contract.Task{task}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{"eta-key": {Key: "eta-key", Wallet: "bc1qeta"}}}
+	server := NewServer(store, keys, nil)
+	claim := func(eta time.Time) *httptest.ResponseRecorder {
+		t.Helper()
+		body, _ := json.Marshal(map[string]interface{}{"estimated_completion": eta})
+		req := httptest.NewRequest(http.MethodPost, "/api/smart_contract/tasks/"+task.TaskID+"/claim", strings.NewReader(string(body)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "eta-key")
+		rec := httptest.NewRecorder()
+		server.handleClaimTask(rec, req, task.TaskID)
+		return rec
+	}
+
+	rec := claim(time.Now().Add(-time.Hour))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "must be in the future") {
+		t.Fatalf("past eta: status %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = claim(time.Now().Add(96 * time.Hour))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "within 72h0m0s") {
+		t.Fatalf("over-horizon eta: status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// A valid estimate in another zone is accepted.
+	if rec := claim(time.Now().Add(24 * time.Hour).In(time.FixedZone("UTC+9", 9*3600))); rec.Code != http.StatusOK {
+		t.Fatalf("valid eta: status %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestNormalizeEstimatedCompletion(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	if eta, err := normalizeEstimatedCompletion(nil, now, time.Hour); eta != nil || err != nil {
+		t.Fatalf("nil eta: %v, %v", eta, err)
+	}
+	local := now.Add(30 * time.Minute).In(time.FixedZone("UTC-5", -5*3600))
+	eta, err := normalizeEstimatedCompletion(&local, now, time.Hour)
+	if err != nil || eta.Location() != time.UTC || !eta.Equal(local) {
+		t.Fatalf("valid eta: %v, %v", eta, err)
+	}
+	if _, err := normalizeEstimatedCompletion(&now, now, time.Hour); err == nil {
+		t.Fatal("eta equal to now accepted")
+	}
+}
+
+func TestClaimETAHorizonFromEnv(t *testing.T) {
+	t.Setenv("STARGATE_CLAIM_ETA_MAX_HORIZON", "")
+	if got := claimETAHorizonFromEnv(); got != defaultClaimETAHorizon {
+		t.Fatalf("unset: %v", got)
+	}
+	t.Setenv("STARGATE_CLAIM_ETA_MAX_HORIZON", "48h")
+	if got := claimETAHorizonFromEnv(); got != 48*time.Hour {
+		t.Fatalf("48h: %v", got)
+	}
+	for _, raw := range []string{"soon", "-1h", "0s"} {
+		t.Setenv("STARGATE_CLAIM_ETA_MAX_HORIZON", raw)
+		if got := claimETAHorizonFromEnv(); got != defaultClaimETAHorizon {
+			t.Fatalf("%q: %v, want default", raw, got)
+		}
+	}
+}
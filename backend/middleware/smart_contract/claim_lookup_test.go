@@ -0,0 +1,72 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleClaimsGetByID(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	contract := smart_contract.Contract{ContractID: "contract-claim-lookup", Title: "Lookup", Status: "active"}
+	task := smart_contract.Task{TaskID: "claim-lookup-task", ContractID: contract.ContractID, Title: "Lookup", Status: "available"}
+	if err := store.UpsertContractWithTasks(context.Background(), contract, []smart_contract.Task{task}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	claim, err := store.ClaimTask(task.TaskID, "bc1qlookup", nil)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+
+	server := NewServer(store, nil, nil)
+	get := func(claimID string) (*httptest.ResponseRecorder, scstore.ClaimDetails) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		server.handleClaims(rec, httptest.NewRequest(http.MethodGet, "/api/smart_contract/claims/"+claimID, nil))
+		var details scstore.ClaimDetails
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &details); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+		}
+		return rec, details
+	}
+
+	rec, details := get(claim.ClaimID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	if details.TaskID != task.TaskID || details.Wallet != "bc1qlookup" || details.Status != "active" || details.Expired {
+		t.Fatalf("unexpected claim details: %+v", details)
+	}
+	if !details.ExpiresAt.Equal(claim.ExpiresAt) {
+		t.Fatalf("expires_at = %v, want %v", details.ExpiresAt, claim.ExpiresAt)
+	}
+	if details.Submission != nil {
+		t.Fatalf("expected no submission before submit, got %+v", details.Submission)
+	}
+
+	sub, err := store.SubmitWork(claim.ClaimID, map[string]interface{}{"notes": "done"}, nil)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if _, details = get(claim.ClaimID); details.Submission == nil || details.Submission.SubmissionID != sub.SubmissionID {
+		t.Fatalf("expected submission %s, got %+v", sub.SubmissionID, details.Submission)
+	}
+
+	if rec, _ := get("missing-claim"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown claim: status %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	server.handleClaims(rec, httptest.NewRequest(http.MethodDelete, "/api/smart_contract/claims/"+claim.ClaimID, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("DELETE: status %d, want 405", rec.Code)
+	}
+}
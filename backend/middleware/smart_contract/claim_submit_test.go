@@ -0,0 +1,61 @@
+package smart_contract
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleClaimsSubmitRequiresClaimOwner(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	contract := smart_contract.Contract{ContractID: "contract-claim-owner", Title: "Ownership", Status: "active"}
+	task := smart_contract.Task{TaskID: "claim-owner-task", ContractID: contract.ContractID, Title: "Ownership", Status: "available"}
+	if err := store.UpsertContractWithTasks(context.Background(), contract, []smart_contract.Task{task}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	claim, err := store.ClaimTask(task.TaskID, "bc1qowner", nil)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{
+		"owner-key":    {Key: "owner-key", Wallet: "BC1QOWNER"},
+		"intruder-key": {Key: "intruder-key", Wallet: "bc1qintruder"},
+		"unbound-key":  {Key: "unbound-key"},
+	}}
+	server := NewServer(store, keys, nil)
+	submit := func(claimID, apiKey string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/smart_contract/claims/"+claimID+"/submit",
+			strings.NewReader(`{"deliverables":{"notes":"done"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		server.handleClaims(rec, req)
+		return rec
+	}
+
+	for _, key := range []string{"intruder-key", "unbound-key"} {
+		if rec := submit(claim.ClaimID, key); rec.Code != http.StatusForbidden {
+			t.Fatalf("%s: status %d, want 403: %s", key, rec.Code, rec.Body.String())
+		}
+	}
+	if details, err := scstore.LookupClaim(context.Background(), store, claim.ClaimID, time.Now()); err != nil || details.Submission != nil {
+		t.Fatalf("denied submit left a submission: %+v, %v", details.Submission, err)
+	}
+
+	if rec := submit("missing-claim", "owner-key"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown claim: status %d, want 404", rec.Code)
+	}
+
+	if rec := submit(claim.ClaimID, "owner-key"); rec.Code != http.StatusOK {
+		t.Fatalf("owner: status %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+}
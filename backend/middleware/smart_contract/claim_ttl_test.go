@@ -0,0 +1,28 @@
+package smart_contract
+
+import (
+	"testing"
+
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestProposalContractTasksCarriesClaimTTL(t *testing.T) {
+	p := smart_contract.Proposal{
+		ID:       "proposal-ttl",
+		Title:    "TTL",
+		Metadata: map[string]interface{}{scstore.ClaimTTLMetadataKey: 48, "visible_pixel_hash": "abc"},
+	}
+	contract, _ := proposalContractTasks(p)
+	if got := contract.Metadata[scstore.ClaimTTLMetadataKey]; got != 48 {
+		t.Fatalf("contract claim_ttl_hours = %v, want 48", got)
+	}
+	if _, ok := contract.Metadata["visible_pixel_hash"]; ok {
+		t.Fatal("unrelated proposal metadata copied to contract")
+	}
+
+	delete(p.Metadata, scstore.ClaimTTLMetadataKey)
+	if contract, _ := proposalContractTasks(p); contract.Metadata != nil {
+		t.Fatalf("contract metadata without override = %v", contract.Metadata)
+	}
+}
@@ -0,0 +1,355 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stargate-backend/bitcoin"
+	"stargate-backend/core/smart_contract"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestTaskCommitmentAfterPSBTBuild(t *testing.T) {
+	store := scstore.NewMemoryStore(72 * 60 * 60)
+	payerWallet := mustTestnetAddress(t, 1)
+	contractorWallet := mustTestnetAddress(t, 2)
+	t.Setenv("STARLIGHT_DONATION_ADDRESS", mustTestnetAddress(t, 3))
+	apiKey := "psbt-commitment-key"
+
+	rawTxHex, txID := mustFundingTx(t, payerWallet, 20000)
+	mempool := http.NewServeMux()
+	mempool.HandleFunc("/address/"+payerWallet+"/utxo", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"txid": txID, "vout": 0, "value": 20000, "status": map[string]interface{}{"confirmed": true}},
+		})
+	})
+	mempool.HandleFunc("/tx/"+txID+"/raw", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(rawTxHex))
+	})
+	mempoolServer := httptest.NewServer(mempool)
+	defer mempoolServer.Close()
+	t.Setenv("MEMPOOL_API_BASE", mempoolServer.URL)
+
+	server := NewServer(store, &mockAPIKeyStore{
+		keys: map[string]auth.APIKey{apiKey: {Key: apiKey, Wallet: payerWallet}},
+	}, nil)
+	mux := http.NewServeMux()
+	server.RegisterRoutes(mux)
+
+	contractID := "contract-commitment-status"
+	taskID := "task-commitment-status"
+	if err := store.UpsertContractWithTasks(context.Background(), smart_contract.Contract{
+		ContractID:      contractID,
+		Title:           "Commitment status",
+		Status:          "open",
+		TotalBudgetSats: 1000,
+	}, []smart_contract.Task{
+		{TaskID: taskID, ContractID: contractID, Title: "Task", Status: "available", BudgetSats: 1000},
+	}); err != nil {
+		t.Fatalf("seed contract: %v", err)
+	}
+
+	getCommitment := func() smart_contract.TaskCommitment {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/smart_contract/tasks/"+taskID+"/commitment", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("commitment status = %d: %s", rec.Code, rec.Body.String())
+		}
+		var out smart_contract.TaskCommitment
+		if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+			t.Fatalf("decode commitment: %v", err)
+		}
+		return out
+	}
+
+	if before := getCommitment(); before.HasCommitment || before.ConfirmationStatus != smart_contract.CommitmentStatusNone {
+		t.Fatalf("commitment before PSBT = %+v, want none", before)
+	}
+
+	body := `{"contractor_wallet":"` + contractorWallet + `","pixel_hash":"` + strings.Repeat("c", 64) +
+		`","commitment_target":"donation","commitment_sats":1000,"task_id":"` + taskID + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/smart_contract/contracts/"+contractID+"/psbt", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", apiKey)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("psbt status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var psbt struct {
+		FundingTxID       string `json:"funding_txid"`
+		CommitmentSats    int64  `json:"commitment_sats"`
+		CommitmentVout    uint32 `json:"commitment_vout"`
+		CommitmentAddress string `json:"commitment_address"`
+		RedeemScript      string `json:"redeem_script"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &psbt); err != nil {
+		t.Fatalf("decode psbt: %v", err)
+	}
+
+	got := getCommitment()
+	if stored, err := store.GetTask(taskID); err != nil || stored.MerkleProof == nil || stored.MerkleProof.PayerWallet != payerWallet {
+		t.Fatalf("payer wallet not recorded on the commitment: %+v, %v", stored.MerkleProof, err)
+	}
+	if !got.HasCommitment {
+		t.Fatalf("commitment after PSBT = %+v, want has_commitment", got)
+	}
+	if got.TaskID != taskID || got.ContractID != contractID {
+		t.Fatalf("ids = %s/%s", got.TaskID, got.ContractID)
+	}
+	if got.CommitmentSats != psbt.CommitmentSats || got.CommitmentSats != 1000 {
+		t.Fatalf("commitment_sats = %d, psbt reported %d", got.CommitmentSats, psbt.CommitmentSats)
+	}
+	if got.CommitmentVout != psbt.CommitmentVout {
+		t.Fatalf("commitment_vout = %d, psbt reported %d", got.CommitmentVout, psbt.CommitmentVout)
+	}
+	if got.CommitmentAddress != psbt.CommitmentAddress {
+		t.Fatalf("commitment_address = %q, psbt reported %q", got.CommitmentAddress, psbt.CommitmentAddress)
+	}
+	if psbt.RedeemScript != "" && got.CommitmentRedeemScript != psbt.RedeemScript {
+		t.Fatalf("redeem script = %q, psbt reported %q", got.CommitmentRedeemScript, psbt.RedeemScript)
+	}
+	if got.FundingTxID != psbt.FundingTxID {
+		t.Fatalf("funding_tx_id = %q, psbt reported %q", got.FundingTxID, psbt.FundingTxID)
+	}
+	if got.ConfirmationStatus != smart_contract.CommitmentStatusProvisional || got.Confirmed {
+		t.Fatalf("confirmation = %q/%v, want provisional", got.ConfirmationStatus, got.Confirmed)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/smart_contract/tasks/missing-task/commitment", nil)
+	req.Header.Set("X-API-Key", apiKey)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing task status = %d, want 404", rec.Code)
+	}
+}
+
+func TestContractPSBTCommitmentSatsDustLimit(t *testing.T) {
+	store := scstore.NewMemoryStore(72 * 60 * 60)
+	payerWallet := mustTestnetAddress(t, 1)
+	contractorWallet := mustTestnetAddress(t, 2)
+	t.Setenv("STARLIGHT_DONATION_ADDRESS", mustTestnetAddress(t, 3))
+	apiKey := "psbt-dust-key"
+
+	rawTxHex, txID := mustFundingTx(t, payerWallet, 20000)
+	mempool := http.NewServeMux()
+	mempool.HandleFunc("/address/"+payerWallet+"/utxo", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"txid": txID, "vout": 0, "value": 20000, "status": map[string]interface{}{"confirmed": true}},
+		})
+	})
+	mempool.HandleFunc("/tx/"+txID+"/raw", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(rawTxHex))
+	})
+	mempoolServer := httptest.NewServer(mempool)
+	defer mempoolServer.Close()
+	t.Setenv("MEMPOOL_API_BASE", mempoolServer.URL)
+
+	server := NewServer(store, &mockAPIKeyStore{
+		keys: map[string]auth.APIKey{apiKey: {Key: apiKey, Wallet: payerWallet}},
+	}, nil)
+	contractID := "contract-commitment-dust"
+	if err := store.UpsertContractWithTasks(context.Background(), smart_contract.Contract{
+		ContractID:      contractID,
+		Title:           "Commitment dust",
+		Status:          "open",
+		TotalBudgetSats: 1000,
+	}, nil); err != nil {
+		t.Fatalf("seed contract: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		sats       string
+		wantStatus int
+		wantSats   int64
+	}{
+		{"zero uses default", "0", http.StatusOK, bitcoin.DefaultCommitmentSats},
+		{"below dust rejected", "100", http.StatusBadRequest, 0},
+		{"negative rejected", "-5", http.StatusBadRequest, 0},
+		{"valid value kept", "2000", http.StatusOK, 2000},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body := `{"contractor_wallet":"` + contractorWallet + `","pixel_hash":"` + strings.Repeat("d", 64) +
+				`","commitment_target":"donation","commitment_sats":` + tc.sats + `}`
+			req := httptest.NewRequest(http.MethodPost, "/api/smart_contract/contracts/"+contractID+"/psbt", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", apiKey)
+			rec := httptest.NewRecorder()
+			server.handleContracts(rec, req)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus != http.StatusOK {
+				if !strings.Contains(rec.Body.String(), "dust limit") {
+					t.Fatalf("error does not mention the dust limit: %s", rec.Body.String())
+				}
+				return
+			}
+			var payload struct {
+				CommitmentSats int64 `json:"commitment_sats"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if payload.CommitmentSats != tc.wantSats {
+				t.Fatalf("commitment_sats = %d, want %d", payload.CommitmentSats, tc.wantSats)
+			}
+		})
+	}
+}
+
+func TestResetTaskCommitment(t *testing.T) {
+	store := scstore.NewMemoryStore(72 * 60 * 60)
+	ctx := context.Background()
+	payer := mustTestnetAddress(t, 5)
+	owner := mustTestnetAddress(t, 6)
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{
+		"payer-key":    {Key: "payer-key", Wallet: payer},
+		"owner-key":    {Key: "owner-key", Wallet: owner},
+		"intruder-key": {Key: "intruder-key", Wallet: mustTestnetAddress(t, 7)},
+		"unbound-key":  {Key: "unbound-key"},
+	}}
+
+	abandonedTxID := strings.Repeat("f", 64)
+	broadcastTxID := strings.Repeat("d", 64)
+	mempool := http.NewServeMux()
+	mempool.HandleFunc("/tx/"+broadcastTxID+"/status", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"confirmed":false}`))
+	})
+	mempool.HandleFunc("/", http.NotFound)
+	mempoolServer := httptest.NewServer(mempool)
+	defer mempoolServer.Close()
+	t.Setenv("MEMPOOL_API_BASE", mempoolServer.URL)
+
+	mux := http.NewServeMux()
+	NewServer(store, keys, nil).RegisterRoutes(mux)
+
+	contractID := "contract-commitment-reset"
+	if err := store.UpsertContractWithTasks(ctx, smart_contract.Contract{ContractID: contractID, Title: "Reset", Status: "active"}, []smart_contract.Task{
+		{TaskID: "task-provisional", ContractID: contractID, Title: "Provisional", Status: "available"},
+		{TaskID: "task-owner", ContractID: contractID, Title: "Owner", Status: "available"},
+		{TaskID: "task-broadcast", ContractID: contractID, Title: "Broadcast", Status: "available"},
+		{TaskID: "task-confirmed", ContractID: contractID, Title: "Confirmed", Status: "available"},
+	}); err != nil {
+		t.Fatalf("seed contract: %v", err)
+	}
+	pixelHash := strings.Repeat("e", 64)
+	if err := store.CreateProposal(ctx, smart_contract.Proposal{
+		ID:               contractID,
+		Title:            "Reset",
+		VisiblePixelHash: pixelHash,
+		Status:           "approved",
+		Metadata:         map[string]interface{}{"creator_wallet": owner},
+	}); err != nil {
+		t.Fatalf("seed proposal: %v", err)
+	}
+	for taskID, seed := range map[string]struct{ txID, status string }{
+		"task-provisional": {abandonedTxID, smart_contract.CommitmentStatusProvisional},
+		"task-owner":       {abandonedTxID, smart_contract.CommitmentStatusProvisional},
+		"task-broadcast":   {broadcastTxID, smart_contract.CommitmentStatusProvisional},
+		"task-confirmed":   {abandonedTxID, smart_contract.CommitmentStatusConfirmed},
+	} {
+		if err := store.UpdateTaskProof(ctx, taskID, &smart_contract.MerkleProof{
+			TxID:                   seed.txID,
+			PayerWallet:            payer,
+			VisiblePixelHash:       pixelHash,
+			CommitmentAddress:      mustTestnetAddress(t, 4),
+			CommitmentRedeemScript: "a820" + pixelHash + "87",
+			CommitmentVout:         1,
+			CommitmentSats:         1000,
+			ConfirmationStatus:     seed.status,
+		}); err != nil {
+			t.Fatalf("seed proof: %v", err)
+		}
+	}
+
+	reset := func(taskID, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/smart_contract/tasks/"+taskID+"/commitment/reset", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("other wallets are refused", func(t *testing.T) {
+		for _, key := range []string{"intruder-key", "unbound-key"} {
+			if rec := reset("task-provisional", key); rec.Code != http.StatusForbidden {
+				t.Fatalf("%s: status = %d, want 403: %s", key, rec.Code, rec.Body.String())
+			}
+		}
+		task, err := store.GetTask("task-provisional")
+		if err != nil || task.MerkleProof == nil || task.MerkleProof.TxID != abandonedTxID {
+			t.Fatalf("refused reset modified the commitment: %+v, %v", task.MerkleProof, err)
+		}
+	})
+
+	t.Run("payer clears provisional", func(t *testing.T) {
+		rec := reset("task-provisional", "payer-key")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+		}
+		task, err := store.GetTask("task-provisional")
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof := task.MerkleProof
+		if proof == nil {
+			t.Fatal("proof removed entirely")
+		}
+		if proof.TxID != "" || proof.PayerWallet != "" || proof.CommitmentVout != 0 || proof.CommitmentSats != 0 || proof.CommitmentRedeemScript != "" || proof.ConfirmationStatus != "" {
+			t.Fatalf("commitment not cleared: %+v", proof)
+		}
+		if proof.VisiblePixelHash != pixelHash {
+			t.Fatalf("visible pixel hash = %q, want it kept", proof.VisiblePixelHash)
+		}
+		if got := smart_contract.TaskCommitmentStatus(task); got.HasCommitment || got.ConfirmationStatus != smart_contract.CommitmentStatusNone {
+			t.Fatalf("status after reset = %+v", got)
+		}
+	})
+
+	t.Run("proposal owner clears provisional", func(t *testing.T) {
+		if rec := reset("task-owner", "owner-key"); rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("broadcast is refused", func(t *testing.T) {
+		rec := reset("task-broadcast", "payer-key")
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want 409: %s", rec.Code, rec.Body.String())
+		}
+		task, err := store.GetTask("task-broadcast")
+		if err != nil || task.MerkleProof == nil || task.MerkleProof.TxID != broadcastTxID {
+			t.Fatalf("broadcast commitment was modified: %+v, %v", task.MerkleProof, err)
+		}
+	})
+
+	t.Run("confirmed is refused", func(t *testing.T) {
+		rec := reset("task-confirmed", "payer-key")
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want 409: %s", rec.Code, rec.Body.String())
+		}
+		task, err := store.GetTask("task-confirmed")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.MerkleProof == nil || task.MerkleProof.TxID == "" || task.MerkleProof.ConfirmationStatus != smart_contract.CommitmentStatusConfirmed {
+			t.Fatalf("confirmed commitment was modified: %+v", task.MerkleProof)
+		}
+	})
+
+	if rec := reset("missing-task", "payer-key"); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing task status = %d, want 404", rec.Code)
+	}
+}
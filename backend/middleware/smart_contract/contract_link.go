@@ -0,0 +1,58 @@
+package smart_contract
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"stargate-backend/bitcoin"
+)
+
+// ContractLinker links a contract detected in a block to an ingestion record.
+// *bitcoin.BlockMonitor implements it.
+type ContractLinker interface {
+	LinkContractToIngestion(contractID, ingestionID string) (*bitcoin.SmartContractData, error)
+}
+
+// SetContractLinker enables POST /api/smart_contract/contracts/{id}/link-ingestion.
+func (s *Server) SetContractLinker(linker ContractLinker) {
+	s.linker = linker
+}
+
+// handleLinkIngestion manually associates a detected contract with an
+// ingestion record after automatic reconciliation failed.
+func (s *Server) handleLinkIngestion(w http.ResponseWriter, r *http.Request, contractID string) {
+	if s.linker == nil {
+		Error(w, http.StatusServiceUnavailable, "contract linking not available")
+		return
+	}
+
+	var body struct {
+		IngestionID string `json:"ingestion_id"`
+	}
+	if err := decodeStrictJSON(r.Body, &body); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if strings.TrimSpace(body.IngestionID) == "" {
+		Error(w, http.StatusBadRequest, "ingestion_id is required")
+		return
+	}
+
+	contract, err := s.linker.LinkContractToIngestion(contractID, body.IngestionID)
+	switch {
+	case errors.Is(err, bitcoin.ErrContractNotFound), errors.Is(err, bitcoin.ErrIngestionNotFound):
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, bitcoin.ErrVisibleHashMismatch):
+		Error(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, bitcoin.ErrIngestionUnavailable):
+		Error(w, http.StatusServiceUnavailable, err.Error())
+		return
+	case err != nil:
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	JSON(w, http.StatusOK, contract)
+}
@@ -0,0 +1,56 @@
+package smart_contract
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stargate-backend/bitcoin"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+type fakeLinker struct {
+	hashes map[string]string // contract ID -> visible_pixel_hash
+}
+
+func (f fakeLinker) LinkContractToIngestion(contractID, ingestionID string) (*bitcoin.SmartContractData, error) {
+	hash, ok := f.hashes[contractID]
+	if !ok {
+		return nil, bitcoin.ErrContractNotFound
+	}
+	if hash != ingestionID {
+		return nil, fmt.Errorf("%w: %s", bitcoin.ErrVisibleHashMismatch, contractID)
+	}
+	return &bitcoin.SmartContractData{ContractID: contractID, ReconciliationStatus: bitcoin.ReconciliationFullyMatched}, nil
+}
+
+func TestHandleLinkIngestion(t *testing.T) {
+	server := NewServer(scstore.NewMemoryStore(72*60*60), nil, nil)
+	post := func(contractID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/smart_contract/contracts/"+contractID+"/link-ingestion", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		server.handleContracts(rec, req)
+		return rec
+	}
+
+	if rec := post("stego_a", `{"ingestion_id":"a"}`); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("no linker: expected 503, got %d", rec.Code)
+	}
+
+	server.SetContractLinker(fakeLinker{hashes: map[string]string{"stego_a": "a"}})
+	if rec := post("stego_a", `{"ingestion_id":"a"}`); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"reconciliation_status":"fully_matched"`) {
+		t.Fatalf("matching link: got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("stego_a", `{"ingestion_id":"b"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("mismatching link: expected 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("stego_missing", `{"ingestion_id":"a"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown contract: expected 404, got %d", rec.Code)
+	}
+	if rec := post("stego_a", `{}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("missing ingestion_id: expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,67 @@
+package smart_contract
+
+import (
+	"fmt"
+	"net/http"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// contractTask is a task together with the submissions made against it.
+type contractTask struct {
+	smart_contract.Task
+	Submissions []smart_contract.Submission `json:"submissions"`
+}
+
+// requireContract writes a 404 and returns false when contractID names
+// neither a stored contract nor any task, so contract-scoped lists can tell
+// "no such contract" apart from "contract has no tasks". Tasks whose contract
+// row was never written still count as a known contract.
+func (s *Server) requireContract(w http.ResponseWriter, contractID string) bool {
+	if _, err := s.store.GetContract(contractID); err == nil {
+		return true
+	}
+	tasks, err := s.store.ListTasks(smart_contract.TaskFilter{ContractID: contractID, Limit: 1})
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if len(tasks) == 0 {
+		Error(w, http.StatusNotFound, fmt.Sprintf("contract %s not found", contractID))
+		return false
+	}
+	return true
+}
+
+// handleContractTasks lists one contract's tasks with their submissions.
+// GET /api/smart_contract/contracts/{id}/tasks
+func (s *Server) handleContractTasks(w http.ResponseWriter, r *http.Request, contractID string) {
+	if !s.requireContract(w, contractID) {
+		return
+	}
+	tasks, err := s.store.ListTasks(smart_contract.TaskFilter{ContractID: contractID})
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	out := make([]contractTask, 0, len(tasks))
+	taskIDs := make([]string, 0, len(tasks))
+	index := make(map[string]int, len(tasks))
+	for _, t := range tasks {
+		index[t.TaskID] = len(out)
+		taskIDs = append(taskIDs, t.TaskID)
+		out = append(out, contractTask{Task: t, Submissions: []smart_contract.Submission{}})
+	}
+	subs, err := s.store.ListSubmissions(r.Context(), taskIDs)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, sub := range subs {
+		if i, ok := index[sub.TaskID]; ok {
+			out[i].Submissions = append(out[i].Submissions, sub)
+		}
+	}
+	JSON(w, http.StatusOK, out)
+}
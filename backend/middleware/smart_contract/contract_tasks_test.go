@@ -0,0 +1,144 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleContractTasks(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	ctx := context.Background()
+	for _, id := range []string{"contract-a", "contract-b"} {
+		contract := smart_contract.Contract{ContractID: id, Title: id, Status: "active"}
+		tasks := []smart_contract.Task{
+			{TaskID: id + "-task-1", ContractID: id, Title: "One", Status: "available"},
+			{TaskID: id + "-task-2", ContractID: id, Title: "Two", Status: "available"},
+		}
+		if err := store.UpsertContractWithTasks(ctx, contract, tasks); err != nil {
+			t.Fatalf("seed %s: %v", id, err)
+		}
+	}
+	claim, err := store.ClaimTask("contract-a-task-1", "bc1qcontracttasks", nil)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := store.SubmitWork(claim.ClaimID, map[string]interface{}{"notes": "done"}, nil); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	server := NewServer(store, nil, nil)
+	get := func(contractID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/smart_contract/contracts/"+contractID+"/tasks", nil)
+		rec := httptest.NewRecorder()
+		server.handleContracts(rec, req)
+		return rec
+	}
+
+	rec := get("contract-a")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var got []contractTask
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d tasks, want 2: %+v", len(got), got)
+	}
+	for _, task := range got {
+		if task.ContractID != "contract-a" {
+			t.Fatalf("task %s belongs to %s", task.TaskID, task.ContractID)
+		}
+		wantSubs := 0
+		if task.TaskID == "contract-a-task-1" {
+			wantSubs = 1
+		}
+		if len(task.Submissions) != wantSubs {
+			t.Fatalf("task %s has %d submissions, want %d", task.TaskID, len(task.Submissions), wantSubs)
+		}
+	}
+
+	if rec := get("contract-missing"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown contract: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestContractScopedEndpointsDistinguishMissingContract(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	empty := smart_contract.Contract{ContractID: "contract-empty", Title: "Empty", Status: "active"}
+	if err := store.UpsertContractWithTasks(context.Background(), empty, nil); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	const apiKey = "payer-key"
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{apiKey: {Key: apiKey, Wallet: "bc1qpayerwallet"}}}
+	mux := http.NewServeMux()
+	NewServer(store, keys, nil).RegisterRoutes(mux)
+
+	tests := []struct {
+		path        string
+		missing     int
+		emptyStatus int
+		emptyBody   string
+	}{
+		{"/api/smart_contract/tasks?contract_id=%s", http.StatusNotFound, http.StatusOK, `"total_matches":0`},
+		{"/api/smart_contract/submissions?contract_id=%s", http.StatusNotFound, http.StatusOK, `"total":0`},
+		{"/api/smart_contract/contracts/%s/tasks", http.StatusNotFound, http.StatusOK, `[]`},
+		{"/api/smart_contract/contracts/%s/payment-details", http.StatusNotFound, http.StatusNotFound, "no tasks found for contract"},
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	for _, tc := range tests {
+		if rec := get(fmt.Sprintf(tc.path, "contract-missing")); rec.Code != tc.missing {
+			t.Fatalf("%s unknown contract: status = %d, want %d: %s", tc.path, rec.Code, tc.missing, rec.Body.String())
+		}
+		rec := get(fmt.Sprintf(tc.path, empty.ContractID))
+		if rec.Code != tc.emptyStatus || !strings.Contains(rec.Body.String(), tc.emptyBody) {
+			t.Fatalf("%s empty contract: status = %d, want %d with %q: %s", tc.path, rec.Code, tc.emptyStatus, tc.emptyBody, rec.Body.String())
+		}
+	}
+}
+
+// contractlessStore hides every contract row, leaving only the tasks.
+type contractlessStore struct {
+	scstore.Store
+}
+
+func (contractlessStore) GetContract(id string) (smart_contract.Contract, error) {
+	return smart_contract.Contract{}, fmt.Errorf("contract %s not found", id)
+}
+
+func TestContractScopedEndpointsListTasksWithoutContractRow(t *testing.T) {
+	mem := scstore.NewMemoryStore(time.Hour)
+	contract := smart_contract.Contract{ContractID: "contract-orphan", Title: "Orphan", Status: "active"}
+	task := smart_contract.Task{TaskID: "orphan-task", ContractID: contract.ContractID, Title: "Orphan", Status: "available"}
+	if err := mem.UpsertContractWithTasks(context.Background(), contract, []smart_contract.Task{task}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	server := NewServer(contractlessStore{mem}, nil, nil)
+
+	rec := httptest.NewRecorder()
+	server.handleContractTasks(rec, httptest.NewRequest(http.MethodGet, "/api/smart_contract/contracts/contract-orphan/tasks", nil), contract.ContractID)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), task.TaskID) {
+		t.Fatalf("orphaned tasks: status = %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	server.handleContractTasks(rec, httptest.NewRequest(http.MethodGet, "/api/smart_contract/contracts/contract-gone/tasks", nil), "contract-gone")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown contract: status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,175 @@
+package smart_contract
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// Backpressure policies for event stream listeners whose buffer is full.
+const (
+	// EventPolicyDropNewest discards the event being broadcast (the default).
+	EventPolicyDropNewest = "drop_newest"
+	// EventPolicyDropOldest discards the oldest buffered event to make room.
+	EventPolicyDropOldest = "drop_oldest"
+	// EventPolicyDisconnect closes the stream so the client reconnects and
+	// replays recent events instead of silently missing some.
+	EventPolicyDisconnect = "disconnect"
+)
+
+const defaultEventBuffer = 10
+
+// defaultListenerStallTimeout is how long a listener's buffer must stay full
+// before the admin GC treats the stream as dead.
+const defaultListenerStallTimeout = 5 * time.Minute
+
+// eventStreamConfigFromEnv reads the listener buffer size and backpressure
+// policy from STARGATE_EVENT_STREAM_BUFFER and STARGATE_EVENT_STREAM_POLICY.
+// Invalid values are logged and replaced by the defaults.
+func eventStreamConfigFromEnv() (buffer int, policy string) {
+	buffer = defaultEventBuffer
+	if raw := strings.TrimSpace(os.Getenv("STARGATE_EVENT_STREAM_BUFFER")); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			buffer = n
+		} else {
+			log.Printf("invalid STARGATE_EVENT_STREAM_BUFFER %q, using %d", raw, defaultEventBuffer)
+		}
+	}
+	policy = EventPolicyDropNewest
+	switch raw := strings.ToLower(strings.TrimSpace(os.Getenv("STARGATE_EVENT_STREAM_POLICY"))); raw {
+	case "":
+	case EventPolicyDropNewest, EventPolicyDropOldest, EventPolicyDisconnect:
+		policy = raw
+	default:
+		log.Printf("invalid STARGATE_EVENT_STREAM_POLICY %q, using %s", raw, EventPolicyDropNewest)
+	}
+	return buffer, policy
+}
+
+// listenerStallTimeoutFromEnv reads STARGATE_EVENT_STREAM_STALL_TIMEOUT as a Go
+// duration. Invalid or non-positive values are logged and replaced by the default.
+func listenerStallTimeoutFromEnv() time.Duration {
+	return durationFromEnv("STARGATE_EVENT_STREAM_STALL_TIMEOUT", defaultListenerStallTimeout)
+}
+
+// addListener registers a new event stream listener with the configured buffer.
+// After Shutdown the returned channel is already closed, so late streams end
+// immediately instead of holding the connection open.
+func (s *Server) addListener() chan smart_contract.Event {
+	ch := make(chan smart_contract.Event, s.eventBuffer)
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	if s.streamsClosed {
+		close(ch)
+		return ch
+	}
+	s.listeners = append(s.listeners, ch)
+	return ch
+}
+
+// Shutdown closes every event stream listener so live SSE handlers return and
+// http.Server.Shutdown does not wait on them until its deadline. It is safe to
+// call more than once; ctx is accepted to match http.Server.Shutdown.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.streamsClosed = true
+	for _, ch := range s.listeners {
+		close(ch)
+	}
+	s.listeners = nil
+	s.listenerStalledSince = nil
+	return nil
+}
+
+// broadcastEvent pushes an event to connected listeners without blocking.
+// Listeners that are not keeping up are handled by the server's policy, and
+// the time their buffer was first found full is kept for the admin GC.
+func (s *Server) broadcastEvent(evt smart_contract.Event) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	now := time.Now()
+	kept := s.listeners[:0]
+	for _, ch := range s.listeners {
+		select {
+		case ch <- evt:
+			delete(s.listenerStalledSince, ch)
+			kept = append(kept, ch)
+			continue
+		default:
+		}
+		s.droppedEvents++
+		if _, ok := s.listenerStalledSince[ch]; !ok && s.eventPolicy != EventPolicyDisconnect {
+			if s.listenerStalledSince == nil {
+				s.listenerStalledSince = map[chan smart_contract.Event]time.Time{}
+			}
+			s.listenerStalledSince[ch] = now
+		}
+		switch s.eventPolicy {
+		case EventPolicyDropOldest:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- evt:
+			default:
+			}
+		case EventPolicyDisconnect:
+			close(ch)
+			delete(s.listenerStalledSince, ch)
+			s.disconnectedListeners++
+			continue
+		}
+		kept = append(kept, ch)
+	}
+	s.listeners = kept
+}
+
+var (
+	eventListenersDesc = prometheus.NewDesc(
+		"stargate_event_stream_listeners",
+		"Event stream listeners currently connected.", nil, nil)
+	eventDroppedDesc = prometheus.NewDesc(
+		"stargate_event_stream_dropped_events_total",
+		"Events a listener missed because its buffer was full.", []string{"policy"}, nil)
+	eventDisconnectedDesc = prometheus.NewDesc(
+		"stargate_event_stream_disconnected_listeners_total",
+		"Listeners closed by the disconnect policy for falling behind.", nil, nil)
+)
+
+// eventStreamCollector exposes event stream backpressure counters to Prometheus.
+type eventStreamCollector struct {
+	s *Server
+}
+
+func (c eventStreamCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- eventListenersDesc
+	ch <- eventDroppedDesc
+	ch <- eventDisconnectedDesc
+}
+
+func (c eventStreamCollector) Collect(ch chan<- prometheus.Metric) {
+	c.s.listenersMu.Lock()
+	listeners := len(c.s.listeners)
+	dropped := c.s.droppedEvents
+	disconnected := c.s.disconnectedListeners
+	c.s.listenersMu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(eventListenersDesc, prometheus.GaugeValue, float64(listeners))
+	ch <- prometheus.MustNewConstMetric(eventDroppedDesc, prometheus.CounterValue, float64(dropped), c.s.eventPolicy)
+	ch <- prometheus.MustNewConstMetric(eventDisconnectedDesc, prometheus.CounterValue, float64(disconnected))
+}
+
+// RegisterMetrics registers the event stream counters with reg, e.g.
+// prometheus.DefaultRegisterer to serve them from the shared /metrics endpoint.
+func (s *Server) RegisterMetrics(reg prometheus.Registerer) error {
+	return reg.Register(eventStreamCollector{s: s})
+}
@@ -0,0 +1,162 @@
+package smart_contract
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+// drainEvents reads what a listener has buffered and whether it is still open.
+func drainEvents(ch chan smart_contract.Event) (ids []string, open bool) {
+	for {
+		select {
+		case evt, ok := <-ch:
+			if !ok {
+				return ids, false
+			}
+			ids = append(ids, evt.EntityID)
+		default:
+			return ids, true
+		}
+	}
+}
+
+func TestEventStreamBackpressurePolicies(t *testing.T) {
+	tests := []struct {
+		policy        string
+		wantIDs       []string
+		wantOpen      bool
+		wantDropped   uint64
+		wantListeners int
+	}{
+		{EventPolicyDropNewest, []string{"e1", "e2"}, true, 3, 2},
+		{EventPolicyDropOldest, []string{"e4", "e5"}, true, 3, 2},
+		// Disconnected on the first overflow, so later events are not counted.
+		{EventPolicyDisconnect, []string{"e1", "e2"}, false, 1, 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.policy, func(t *testing.T) {
+			t.Setenv("STARGATE_EVENT_STREAM_BUFFER", "2")
+			t.Setenv("STARGATE_EVENT_STREAM_POLICY", tc.policy)
+			srv := NewServer(scstore.NewMemoryStore(time.Hour), nil, nil)
+			slow := srv.addListener()
+			fast := srv.addListener()
+
+			var fastIDs []string
+			for _, id := range []string{"e1", "e2", "e3", "e4", "e5"} {
+				srv.broadcastEvent(smart_contract.Event{Type: "test", EntityID: id})
+				got, _ := drainEvents(fast)
+				fastIDs = append(fastIDs, got...)
+			}
+
+			if len(fastIDs) != 5 {
+				t.Fatalf("fast consumer got %v, want every event", fastIDs)
+			}
+			ids, open := drainEvents(slow)
+			if strings.Join(ids, ",") != strings.Join(tc.wantIDs, ",") || open != tc.wantOpen {
+				t.Fatalf("slow consumer got %v (open=%v), want %v (open=%v)", ids, open, tc.wantIDs, tc.wantOpen)
+			}
+			if srv.droppedEvents != tc.wantDropped || len(srv.listeners) != tc.wantListeners {
+				t.Fatalf("dropped=%d listeners=%d, want %d and %d", srv.droppedEvents, len(srv.listeners), tc.wantDropped, tc.wantListeners)
+			}
+		})
+	}
+}
+
+func TestEventStreamConfigFromEnv(t *testing.T) {
+	t.Setenv("STARGATE_EVENT_STREAM_BUFFER", "")
+	t.Setenv("STARGATE_EVENT_STREAM_POLICY", "")
+	if buffer, policy := eventStreamConfigFromEnv(); buffer != defaultEventBuffer || policy != EventPolicyDropNewest {
+		t.Fatalf("defaults = %d, %q", buffer, policy)
+	}
+	t.Setenv("STARGATE_EVENT_STREAM_BUFFER", "-3")
+	t.Setenv("STARGATE_EVENT_STREAM_POLICY", "block")
+	if buffer, policy := eventStreamConfigFromEnv(); buffer != defaultEventBuffer || policy != EventPolicyDropNewest {
+		t.Fatalf("invalid values = %d, %q; want defaults", buffer, policy)
+	}
+	t.Setenv("STARGATE_EVENT_STREAM_BUFFER", "64")
+	t.Setenv("STARGATE_EVENT_STREAM_POLICY", "Disconnect")
+	if buffer, policy := eventStreamConfigFromEnv(); buffer != 64 || policy != EventPolicyDisconnect {
+		t.Fatalf("configured = %d, %q", buffer, policy)
+	}
+}
+
+func TestEventStreamMetrics(t *testing.T) {
+	t.Setenv("STARGATE_EVENT_STREAM_BUFFER", "1")
+	t.Setenv("STARGATE_EVENT_STREAM_POLICY", EventPolicyDisconnect)
+	srv := NewServer(scstore.NewMemoryStore(time.Hour), nil, nil)
+	reg := prometheus.NewRegistry()
+	if err := srv.RegisterMetrics(reg); err != nil {
+		t.Fatal(err)
+	}
+	srv.addListener()
+	srv.addListener()
+	srv.broadcastEvent(smart_contract.Event{EntityID: "e1"})
+	srv.broadcastEvent(smart_contract.Event{EntityID: "e2"})
+	srv.addListener()
+
+	w := httptest.NewRecorder()
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	for _, want := range []string{
+		"stargate_event_stream_listeners 1",
+		`stargate_event_stream_dropped_events_total{policy="disconnect"} 2`,
+		"stargate_event_stream_disconnected_listeners_total 2",
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Fatalf("scrape missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestShutdownClosesEventStreams(t *testing.T) {
+	srv := NewServer(scstore.NewMemoryStore(time.Hour), nil, nil)
+	listener := srv.addListener()
+
+	req := httptest.NewRequest("GET", "/api/smart_contract/events", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	done := make(chan struct{})
+	go func() {
+		srv.handleEvents(httptest.NewRecorder(), req)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		srv.listenersMu.Lock()
+		n := len(srv.listeners)
+		srv.listenersMu.Unlock()
+		if n == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stream handler never registered a listener")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if _, open := drainEvents(listener); open {
+		t.Fatalf("listener still open after shutdown")
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("stream handler did not return after shutdown")
+	}
+	if _, open := drainEvents(srv.addListener()); open {
+		t.Fatalf("listener added after shutdown should be closed")
+	}
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatalf("second shutdown: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package smart_contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+// exportFlushEvery is how many records are written between flushes.
+const exportFlushEvery = 100
+
+// handleExport streams contracts, tasks or submissions as NDJSON, one record
+// per line, straight from the store.
+// GET /api/smart_contract/export.ndjson?type=contracts|tasks|submissions&from=&to=
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	q := r.URL.Query()
+	kind := strings.TrimSpace(q.Get("type"))
+	if kind == "" {
+		kind = scstore.ExportContracts
+	}
+	if !scstore.ValidExportType(kind) {
+		Error(w, http.StatusBadRequest, "type must be contracts, tasks or submissions")
+		return
+	}
+	var filter scstore.ExportFilter
+	var err error
+	if filter.From, err = parseExportTime(q.Get("from")); err != nil {
+		Error(w, http.StatusBadRequest, "invalid from: "+err.Error())
+		return
+	}
+	if filter.To, err = parseExportTime(q.Get("to")); err != nil {
+		Error(w, http.StatusBadRequest, "invalid to: "+err.Error())
+		return
+	}
+	exporter, ok := s.store.(scstore.Exporter)
+	if !ok {
+		Error(w, http.StatusNotImplemented, "store does not support export")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.ndjson"`, kind))
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+	written := 0
+	err = exporter.Export(r.Context(), kind, filter, func(record any) error {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+		written++
+		if flusher != nil && written%exportFlushEvery == 0 {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if written == 0 {
+			Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		// Headers are already sent; the truncated stream is all we can report.
+		log.Printf("export %s aborted after %d records: %v", kind, written, err)
+	}
+}
+
+// parseExportTime accepts an RFC3339 timestamp or Unix seconds; empty means unbounded.
+func parseExportTime(raw string) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	if secs, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		t := time.Unix(secs, 0).UTC()
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("want RFC3339 or unix seconds")
+	}
+	return &t, nil
+}
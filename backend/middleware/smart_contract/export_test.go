@@ -0,0 +1,106 @@
+package smart_contract
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleExportStreamsNDJSON(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	ctx := context.Background()
+
+	created := scstore.SeedEpoch.Add(24 * time.Hour)
+	contract := smart_contract.Contract{ContractID: "export-contract", Title: "Export", Status: "active", CreatedAt: created}
+	tasks := []smart_contract.Task{
+		{TaskID: "export-task-1", ContractID: contract.ContractID, Title: "One", Status: "available"},
+		{TaskID: "export-task-2", ContractID: contract.ContractID, Title: "Two", Status: "available"},
+	}
+	if err := store.UpsertContractWithTasks(ctx, contract, tasks); err != nil {
+		t.Fatalf("seed contract: %v", err)
+	}
+	claim, err := store.ClaimTask("export-task-1", "bc1qexportwallet", nil)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := store.SubmitWork(claim.ClaimID, map[string]interface{}{"notes": "done"}, nil); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	allTasks, err := store.ListTasks(smart_contract.TaskFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const apiKey = "export-key"
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{apiKey: {Key: apiKey}}}
+	mux := http.NewServeMux()
+	NewServer(store, keys, nil).RegisterRoutes(mux)
+
+	from := url.QueryEscape(scstore.SeedEpoch.Add(time.Hour).Format(time.RFC3339))
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"type=contracts", len(scstore.Manifest().ContractIDs) + 1},
+		{"type=tasks", len(allTasks)},
+		{"type=submissions", 1},
+		{"type=contracts&from=" + from, 1},
+		{"type=tasks&from=" + from, 2},
+		{"type=contracts&to=" + from, len(scstore.Manifest().ContractIDs)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/smart_contract/export.ndjson?"+tc.query, nil)
+			req.Header.Set("X-API-Key", apiKey)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+				t.Fatalf("content type = %q", ct)
+			}
+			lines := 0
+			scanner := bufio.NewScanner(rec.Body)
+			for scanner.Scan() {
+				var obj map[string]any
+				if err := json.Unmarshal(scanner.Bytes(), &obj); err != nil {
+					t.Fatalf("line %d is not a JSON object: %v", lines+1, err)
+				}
+				lines++
+			}
+			if lines != tc.want {
+				t.Fatalf("got %d lines, want %d", lines, tc.want)
+			}
+		})
+	}
+
+	for query, want := range map[string]int{
+		"type=proposals":           http.StatusBadRequest,
+		"type=tasks&from=tomorrow": http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/smart_contract/export.ndjson?"+query, nil)
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("%s: status = %d, want %d", query, rec.Code, want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/smart_contract/export.ndjson", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("unauthenticated status = %d, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,67 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleContractsFundingStatusFilter(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	ctx := context.Background()
+	proofs := map[string]smart_contract.MerkleProof{
+		"rest-funded":   {TxID: "tx-rest-funded", ConfirmationStatus: smart_contract.CommitmentStatusConfirmed},
+		"rest-unfunded": {},
+		// A provisional proof's tx_id is from a PSBT nobody has broadcast yet.
+		"rest-provisional": {TxID: "tx-rest-psbt", ConfirmationStatus: smart_contract.CommitmentStatusProvisional},
+	}
+	for id, proof := range proofs {
+		c := smart_contract.Contract{ContractID: id, Title: id, Status: "active", TotalBudgetSats: 5000}
+		proof.FundedAmountSats = 5000
+		task := smart_contract.Task{TaskID: id + "-task", Title: id, Status: "available", MerkleProof: &proof}
+		if err := store.UpsertContractWithTasks(ctx, c, []smart_contract.Task{task}); err != nil {
+			t.Fatalf("upsert %s: %v", id, err)
+		}
+	}
+	mux := http.NewServeMux()
+	NewServer(store, nil, nil).RegisterRoutes(mux)
+
+	for status, want := range map[string][]string{"funded": {"rest-funded"}, "unfunded": {"rest-unfunded", "rest-provisional"}} {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/smart_contract/contracts?include_confirmed=true&funding_status="+status, nil))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d: %s", status, rr.Code, rr.Body.String())
+		}
+		var body struct {
+			Contracts []smart_contract.Contract `json:"contracts"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		seen := map[string]bool{}
+		for _, c := range body.Contracts {
+			seen[c.ContractID] = true
+		}
+		for id := range proofs {
+			wanted := false
+			for _, w := range want {
+				wanted = wanted || w == id
+			}
+			if seen[id] != wanted {
+				t.Fatalf("funding_status=%s listed %s = %v", status, id, seen[id])
+			}
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/smart_contract/contracts?funding_status=soon", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("invalid funding_status: status = %d, want 400", rr.Code)
+	}
+}
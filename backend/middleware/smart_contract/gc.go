@@ -0,0 +1,110 @@
+package smart_contract
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+// GCSweeper purges one kind of expired state and reports how many entries it
+// removed.
+type GCSweeper func(ctx context.Context, now time.Time) (int, error)
+
+// RegisterGCSweeper adds a sweeper run by POST /api/admin/gc under name.
+// Components outside this package, such as the MCP rate limiter, use it to
+// expose their own state to the purge.
+func (s *Server) RegisterGCSweeper(name string, sweep GCSweeper) {
+	s.gcMu.Lock()
+	defer s.gcMu.Unlock()
+	if s.gcSweepers == nil {
+		s.gcSweepers = map[string]GCSweeper{}
+	}
+	s.gcSweepers[name] = sweep
+}
+
+// RunGC runs every sweeper once and returns the number purged by each. Expired
+// claims are swept when the store supports it, and event stream listeners are
+// always swept. All sweepers run even if one fails; the first error is returned.
+func (s *Server) RunGC(ctx context.Context, now time.Time) (map[string]int, error) {
+	sweepers := map[string]GCSweeper{
+		"orphaned_listeners": func(_ context.Context, now time.Time) (int, error) {
+			return s.purgeStalledListeners(now), nil
+		},
+	}
+	if expirer, ok := s.store.(scstore.ClaimExpirer); ok {
+		sweepers["expired_claims"] = expirer.ExpireClaims
+	}
+	s.gcMu.Lock()
+	for name, sweep := range s.gcSweepers {
+		sweepers[name] = sweep
+	}
+	s.gcMu.Unlock()
+
+	names := make([]string, 0, len(sweepers))
+	for name := range sweepers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	purged := make(map[string]int, len(names))
+	var firstErr error
+	for _, name := range names {
+		n, err := sweepers[name](ctx, now)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", name, err)
+		}
+		purged[name] = n
+	}
+	return purged, firstErr
+}
+
+// purgeStalledListeners drops event stream listeners whose buffer has stayed
+// full for at least the stall timeout. A full buffer alone is not enough: under
+// drop_oldest a live but slow stream keeps its buffer full, and broadcastEvent
+// clears the mark as soon as a listener has room again.
+func (s *Server) purgeStalledListeners(now time.Time) int {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	kept := s.listeners[:0]
+	purged := 0
+	for _, ch := range s.listeners {
+		since, stalled := s.listenerStalledSince[ch]
+		if stalled && len(ch) < cap(ch) {
+			delete(s.listenerStalledSince, ch)
+			stalled = false
+		}
+		if stalled && now.Sub(since) >= s.listenerStallTimeout {
+			close(ch)
+			delete(s.listenerStalledSince, ch)
+			purged++
+			continue
+		}
+		kept = append(kept, ch)
+	}
+	s.listeners = kept
+	return purged
+}
+
+// handleAdminGC runs the sweepers immediately and reports what each purged.
+func (s *Server) handleAdminGC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	purged, err := s.RunGC(r.Context(), time.Now())
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	total := 0
+	for _, n := range purged {
+		total += n
+	}
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"purged": purged,
+		"total":  total,
+	})
+}
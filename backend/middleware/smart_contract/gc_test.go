@@ -0,0 +1,149 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleAdminGC(t *testing.T) {
+	// Claims expire a nanosecond after they are made.
+	store := scstore.NewMemoryStore(time.Nanosecond)
+	if _, err := store.ClaimTask(scstore.SeedTaskBollingerID, "wallet-1", nil); err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+
+	const apiKey = "admin-key"
+	t.Setenv("STARGATE_ADMIN_API_KEYS", apiKey)
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{
+		apiKey:       {Key: apiKey},
+		"member-key": {Key: "member-key"},
+	}}
+	srv := NewServer(store, keys, nil)
+	mux := http.NewServeMux()
+	srv.RegisterRoutes(mux)
+
+	// One listener stopped reading long ago; the other is live.
+	stalled := make(chan smart_contract.Event, 1)
+	stalled <- smart_contract.Event{Type: "stalled"}
+	live := make(chan smart_contract.Event, 1)
+	srv.listeners = append(srv.listeners, stalled, live)
+	srv.listenerStalledSince = map[chan smart_contract.Event]time.Time{
+		stalled: time.Now().Add(-2 * srv.listenerStallTimeout),
+	}
+
+	// Sweepers registered from outside the package are run and reported too.
+	pending := 3
+	srv.RegisterGCSweeper("registered", func(context.Context, time.Time) (int, error) {
+		n := pending
+		pending = 0
+		return n, nil
+	})
+
+	gc := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/gc", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := gc(""); rec.Code != http.StatusForbidden {
+		t.Fatalf("unauthenticated status = %d, want 403", rec.Code)
+	}
+	if rec := gc("member-key"); rec.Code != http.StatusForbidden {
+		t.Fatalf("non-admin key status = %d, want 403", rec.Code)
+	}
+
+	rec := gc(apiKey)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Purged map[string]int `json:"purged"`
+		Total  int            `json:"total"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := map[string]int{"expired_claims": 1, "orphaned_listeners": 1, "registered": 3}
+	for name, n := range want {
+		if resp.Purged[name] != n {
+			t.Fatalf("purged[%s] = %d, want %d (%s)", name, resp.Purged[name], n, rec.Body.String())
+		}
+	}
+	if resp.Total != 5 {
+		t.Fatalf("total = %d, want 5", resp.Total)
+	}
+	<-stalled // the buffered event
+	if _, open := <-stalled; open {
+		t.Fatal("stalled listener channel not closed")
+	}
+	if len(srv.listeners) != 1 || srv.listeners[0] != live {
+		t.Fatalf("listeners = %d, want only the live one", len(srv.listeners))
+	}
+	task, err := store.GetTask(scstore.SeedTaskBollingerID)
+	if err != nil || task.Status != "available" {
+		t.Fatalf("task after gc = %+v, %v; want available", task.Status, err)
+	}
+
+	rec = gc(apiKey)
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Total != 0 {
+		t.Fatalf("second gc = %s, want nothing purged", rec.Body.String())
+	}
+}
+
+func TestHandleAdminGCDisabledWithoutAdminKeys(t *testing.T) {
+	t.Setenv("STARGATE_ADMIN_API_KEYS", "")
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{"key": {Key: "key"}}}
+	mux := http.NewServeMux()
+	NewServer(scstore.NewMemoryStore(time.Hour), keys, nil).RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/gc", nil)
+	req.Header.Set("X-API-Key", "key")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want 403 with no admin keys configured", rec.Code)
+	}
+}
+
+func TestPurgeStalledListenersWaitsForStallTimeout(t *testing.T) {
+	t.Setenv("STARGATE_EVENT_STREAM_BUFFER", "1")
+	t.Setenv("STARGATE_EVENT_STREAM_POLICY", EventPolicyDropOldest)
+	t.Setenv("STARGATE_EVENT_STREAM_STALL_TIMEOUT", "1m")
+	srv := NewServer(scstore.NewMemoryStore(time.Hour), nil, nil)
+	dead := srv.addListener()
+	slow := srv.addListener()
+
+	// Both buffers fill up; drop_oldest keeps them full from here on.
+	srv.broadcastEvent(smart_contract.Event{Type: "one"})
+	srv.broadcastEvent(smart_contract.Event{Type: "two"})
+	if n := srv.purgeStalledListeners(time.Now()); n != 0 {
+		t.Fatalf("purged %d listeners that only just filled up", n)
+	}
+
+	// The slow stream catches up, so only the dead one stays stalled.
+	<-slow
+	if n := srv.purgeStalledListeners(time.Now().Add(2 * time.Minute)); n != 1 {
+		t.Fatalf("purged %d, want only the dead listener", n)
+	}
+	if _, open := <-dead; !open {
+		t.Fatal("dead listener closed before its buffered event was read")
+	}
+	if _, open := <-dead; open {
+		t.Fatal("dead listener not closed")
+	}
+	if len(srv.listeners) != 1 || srv.listeners[0] != slow {
+		t.Fatalf("listeners = %d, want only the slow one", len(srv.listeners))
+	}
+}
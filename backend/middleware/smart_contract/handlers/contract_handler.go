@@ -136,12 +136,8 @@ func (h *ContractHandler) handleGetContracts(w http.ResponseWriter, r *http.Requ
 	// Standardize on MCP-style response shape (Cat 4.4) for consistency across handlers.
 	hasMore := false
 	if filter.Limit > 0 && len(contracts) == filter.Limit {
-		checkFilter := filter
-		checkFilter.Offset = filter.Offset + filter.Limit
-		checkFilter.Limit = 1
-		more, merr := h.store.ListContracts(checkFilter)
-		if merr == nil && len(more) > 0 {
-			hasMore = true
+		if total, cerr := h.store.CountContracts(filter); cerr == nil {
+			hasMore = filter.Offset+len(contracts) < total
 		}
 	}
 
@@ -2,8 +2,12 @@ package smart_contract
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strings"
 
 	"stargate-backend/models"
 )
@@ -24,3 +28,21 @@ func JSON(w http.ResponseWriter, status int, payload interface{}) {
 func Error(w http.ResponseWriter, status int, msg string) {
 	JSON(w, status, models.NewErrorResponse(msg, status))
 }
+
+var errInvalidJSON = errors.New("invalid json")
+
+// decodeStrictJSON decodes a user-authored request body into dst, rejecting
+// fields dst does not declare so a typo such as "budget_sat" is reported
+// instead of silently ignored. Payloads that must stay forward compatible,
+// like node-to-node sync messages, should keep using a plain json.Decoder.
+func decodeStrictJSON(body io.Reader, dst interface{}) error {
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("unknown field %s in request body", field)
+		}
+		return errInvalidJSON
+	}
+	return nil
+}
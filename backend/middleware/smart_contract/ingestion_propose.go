@@ -0,0 +1,69 @@
+package smart_contract
+
+import (
+	"net/http"
+	"strings"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// ingestionProposeBody is the optional payload for POST
+// /api/ingestion/{id}/propose. Empty fields fall back to values derived from
+// the ingestion record, exactly as create_proposal with ingestion_id does.
+type ingestionProposeBody struct {
+	Title         string                `json:"title"`
+	DescriptionMD string                `json:"description_md"`
+	BudgetSats    int64                 `json:"budget_sats"`
+	ContractID    string                `json:"contract_id"`
+	Tasks         []smart_contract.Task `json:"tasks"`
+}
+
+// handleIngestionPropose turns a pending ingestion into a proposal in one call.
+// POST /api/ingestion/{id}/propose
+func (s *Server) handleIngestionPropose(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/ingestion/"), "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "propose" {
+		Error(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "application/json") {
+		Error(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+	if s.ingestionSvc == nil {
+		Error(w, http.StatusServiceUnavailable, "ingestion service unavailable")
+		return
+	}
+
+	var body ingestionProposeBody
+	if r.ContentLength != 0 {
+		if err := decodeStrictJSON(r.Body, &body); err != nil {
+			Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	ingestionID := parts[0]
+	proposal, status, err := s.createProposalFromIngestion(r, ProposalCreateBody{
+		IngestionID:   ingestionID,
+		ContractID:    body.ContractID,
+		Title:         body.Title,
+		DescriptionMD: body.DescriptionMD,
+		BudgetSats:    body.BudgetSats,
+		Tasks:         body.Tasks,
+	})
+	if err != nil {
+		Error(w, status, err.Error())
+		return
+	}
+	JSON(w, http.StatusCreated, map[string]interface{}{
+		"proposal_id":  proposal.ID,
+		"ingestion_id": ingestionID,
+		"status":       proposal.Status,
+		"message":      "proposal created from pending ingestion",
+	})
+}
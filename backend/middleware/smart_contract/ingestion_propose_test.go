@@ -0,0 +1,102 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	"stargate-backend/services"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestIngestionProposeCreatesProposal(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	ingest := newTestIngestionService(t)
+	ctx := context.Background()
+
+	hash := strings.Repeat("c", 64)
+	if err := ingest.Create(services.IngestionRecord{
+		ID:       "propose-ingest",
+		Filename: "propose.png",
+		Method:   "alpha",
+		Metadata: map[string]interface{}{
+			"embedded_message":   "* Build the widget",
+			"visible_pixel_hash": hash,
+			"contract_id":        hash,
+			"budget_sats":        int64(4000),
+		},
+		Status: "pending",
+	}); err != nil {
+		t.Fatalf("create ingestion: %v", err)
+	}
+	if err := store.UpsertContractWithTasks(ctx, smart_contract.Contract{
+		ContractID: "wish-" + hash,
+		Title:      "Widget wish",
+		Status:     "pending",
+	}, nil); err != nil {
+		t.Fatalf("seed wish: %v", err)
+	}
+
+	const apiKey = "propose-key"
+	mux := http.NewServeMux()
+	NewServer(store, &mockAPIKeyStore{keys: map[string]auth.APIKey{apiKey: {Key: apiKey}}}, ingest).RegisterRoutes(mux)
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("/api/ingestion/propose-ingest/propose", `{
+		"title": "Widget proposal",
+		"description_md": "Plan for the widget",
+		"tasks": [{"title": "Cut", "budget_sats": 1500}, {"title": "Polish", "budget_sats": 2500}]
+	}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		ProposalID  string `json:"proposal_id"`
+		IngestionID string `json:"ingestion_id"`
+		Status      string `json:"status"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.ProposalID == "" || resp.IngestionID != "propose-ingest" || resp.Status != "pending" {
+		t.Fatalf("response = %s", rec.Body.String())
+	}
+
+	proposal, err := store.GetProposal(ctx, resp.ProposalID)
+	if err != nil {
+		t.Fatalf("proposal not stored: %v", err)
+	}
+	if proposal.Title != "Widget proposal" || proposal.DescriptionMD != "Plan for the widget" {
+		t.Fatalf("proposal = %q / %q, want supplied title and description", proposal.Title, proposal.DescriptionMD)
+	}
+	if proposal.VisiblePixelHash != hash || len(proposal.Tasks) != 2 {
+		t.Fatalf("proposal hash=%q tasks=%d, want %q and 2", proposal.VisiblePixelHash, len(proposal.Tasks), hash)
+	}
+	if got, _ := proposal.Metadata["ingestion_id"].(string); got != "propose-ingest" {
+		t.Fatalf("proposal ingestion_id = %q", got)
+	}
+
+	if rec := post("/api/ingestion/missing-ingest/propose", `{}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing ingestion status = %d, want 404: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("/api/ingestion/propose-ingest/propose", `{"titel":"typo"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown field status = %d, want 400: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post("/api/ingestion/propose-ingest/other", `{}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown action status = %d, want 404", rec.Code)
+	}
+}
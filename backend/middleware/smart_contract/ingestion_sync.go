@@ -2,8 +2,6 @@ package smart_contract
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,9 +11,11 @@ import (
 	"strings"
 	"time"
 
+	"stargate-backend/coerce"
 	"stargate-backend/core/smart_contract"
 	"stargate-backend/storage/ipfs"
 	"stargate-backend/services"
+	"stargate-backend/stego"
 	scstore "stargate-backend/storage/smart_contract"
 )
 
@@ -139,12 +139,12 @@ func processRecord(ctx context.Context, rec services.IngestionRecord, ingest *se
 		return ingest.UpdateStatusWithNote(rec.ID, "ignored", "stego manifest metadata")
 	}
 	if strings.TrimSpace(rec.ImageBase64) == "" {
-		if strings.TrimSpace(metaString(meta["ipfs_image_cid"])) == "" {
+		if strings.TrimSpace(coerce.String(meta["ipfs_image_cid"])) == "" {
 			return ingest.UpdateStatusWithNote(rec.ID, "ignored", "missing image data")
 		}
 	}
 
-	visible := strings.TrimSpace(metaString(meta["visible_pixel_hash"]))
+	visible := strings.TrimSpace(coerce.String(meta["visible_pixel_hash"]))
 	if visible == "" {
 		visible = strings.TrimSpace(rec.ID)
 	}
@@ -251,13 +251,13 @@ func processRecord(ctx context.Context, rec services.IngestionRecord, ingest *se
 // Contracts are only loaded into the store after PSBT is built so remote nodes do
 // not pick up unfunded open contracts and duplicate work.
 func hasIngestionPSBT(meta map[string]interface{}) bool {
-	if strings.TrimSpace(metaString(meta["funding_txid"])) != "" {
+	if strings.TrimSpace(coerce.String(meta["funding_txid"])) != "" {
 		return true
 	}
 	switch v := meta["funding_txids"].(type) {
 	case []interface{}:
 		for _, item := range v {
-			if strings.TrimSpace(metaString(item)) != "" {
+			if strings.TrimSpace(coerce.String(item)) != "" {
 				return true
 			}
 		}
@@ -468,7 +468,7 @@ func buildProposalFromReplicatedTasks(ingestionID, tasksJSON string, meta map[st
 		return smart_contract.Proposal{}, fmt.Errorf("no tasks in replicated data")
 	}
 
-	visibleHash := strings.TrimSpace(metaString(meta["visible_pixel_hash"]))
+	visibleHash := strings.TrimSpace(coerce.String(meta["visible_pixel_hash"]))
 	contractIDBase := visibleHash
 	if contractIDBase == "" {
 		contractIDBase = strings.TrimSpace(ingestionID)
@@ -478,7 +478,7 @@ func buildProposalFromReplicatedTasks(ingestionID, tasksJSON string, meta map[st
 		contractID = fmt.Sprintf("wish-%s", contractID)
 	}
 
-	title := strings.TrimSpace(metaString(meta["proposal_title"]))
+	title := strings.TrimSpace(coerce.String(meta["proposal_title"]))
 	if title == "" {
 		title = strings.TrimSpace(wishText)
 	}
@@ -486,7 +486,7 @@ func buildProposalFromReplicatedTasks(ingestionID, tasksJSON string, meta map[st
 		title = fmt.Sprintf("Wish %s", ingestionID)
 	}
 
-	descMD := strings.TrimSpace(metaString(meta["proposal_description_md"]))
+	descMD := strings.TrimSpace(coerce.String(meta["proposal_description_md"]))
 	if descMD == "" {
 		descMD = strings.TrimSpace(wishText)
 	}
@@ -552,25 +552,14 @@ func budgetFromMeta(meta map[string]interface{}) int64 {
 	if meta == nil {
 		return scstore.DefaultBudgetSats()
 	}
-	if unit := strings.ToLower(strings.TrimSpace(metaString(meta["price_unit"]))); unit == "sats" {
-		if sats := satsFromMetaValue(meta["price"]); sats > 0 {
+	if unit := strings.ToLower(strings.TrimSpace(coerce.String(meta["price_unit"]))); unit == "sats" {
+		if sats, _ := coerce.Int64(meta["price"]); sats > 0 {
 			return sats
 		}
 	}
 	// budget_sats or funding_btc fields
-	if v, ok := meta["budget_sats"]; ok {
-		switch t := v.(type) {
-		case float64:
-			return int64(t)
-		case int64:
-			return t
-		case int:
-			return int64(t)
-		case json.Number:
-			if i, err := t.Int64(); err == nil {
-				return i
-			}
-		}
+	if sats, ok := coerce.Int64(meta["budget_sats"]); ok {
+		return sats
 	}
 	if sats := btcToSats(meta["funding_btc"]); sats > 0 {
 		return sats
@@ -581,55 +570,6 @@ func budgetFromMeta(meta map[string]interface{}) int64 {
 	return scstore.DefaultBudgetSats()
 }
 
-func metaString(v interface{}) string {
-	switch t := v.(type) {
-	case string:
-		return t
-	case fmt.Stringer:
-		return t.String()
-	case float64:
-		return strconv.FormatFloat(t, 'f', -1, 64)
-	case int64:
-		return strconv.FormatInt(t, 10)
-	case int:
-		return strconv.Itoa(t)
-	case json.Number:
-		return t.String()
-	default:
-		return ""
-	}
-}
-
-func satsFromMetaValue(v interface{}) int64 {
-	switch t := v.(type) {
-	case int64:
-		return t
-	case int:
-		return int64(t)
-	case float64:
-		return int64(t)
-	case json.Number:
-		if i, err := t.Int64(); err == nil {
-			return i
-		}
-	case string:
-		raw := strings.TrimSpace(t)
-		if raw == "" {
-			return 0
-		}
-		if strings.Contains(raw, ".") {
-			if f, err := strconv.ParseFloat(raw, 64); err == nil {
-				return int64(f)
-			}
-			return 0
-		}
-		if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
-			return i
-		}
-	}
-	return 0
-}
-
 func decodeProof(v map[string]interface{}) *smart_contract.MerkleProof {
 	if v == nil {
 		return nil
@@ -645,13 +585,11 @@ func decodeProof(v map[string]interface{}) *smart_contract.MerkleProof {
 	return &proof
 }
 
+// hashBase64 returns the visible_pixel_hash of a base64 image, computed over
+// the same canonical bytes block reconciliation hashes. Data that does not
+// decode as an image is rejected rather than hashed.
 func hashBase64(data string) (string, error) {
-	b, err := base64.StdEncoding.DecodeString(data)
-	if err != nil {
-		return "", err
-	}
-	sum := sha256.Sum256(b)
-	return fmt.Sprintf("%x", sum[:]), nil
+	return stego.VisiblePixelHashBase64(data)
 }
 
 func copyMeta(meta map[string]interface{}) map[string]interface{} {
@@ -688,15 +626,15 @@ func normalizeEmbedded(raw string, meta map[string]interface{}) (string, map[str
 		updated["price_unit"] = strings.ToLower(strings.TrimSpace(unit))
 	}
 	if budget, ok := obj["budget_sats"]; ok {
-		if sats := satsFromMetaValue(budget); sats > 0 {
+		if sats, _ := coerce.Int64(budget); sats > 0 {
 			updated["budget_sats"] = sats
 		}
 	}
 	if price, ok := obj["price"]; ok {
 		updated["price"] = price
-		unit := strings.ToLower(strings.TrimSpace(metaString(updated["price_unit"])))
+		unit := strings.ToLower(strings.TrimSpace(coerce.String(updated["price_unit"])))
 		if unit == "sats" {
-			if sats := satsFromMetaValue(price); sats > 0 {
+			if sats, _ := coerce.Int64(price); sats > 0 {
 				updated["budget_sats"] = sats
 			}
 		} else if sats := btcToSats(price); sats > 0 {
@@ -738,15 +676,8 @@ func looksLikeStegoManifestTextIngest(text string) bool {
 }
 
 func btcToSats(v interface{}) int64 {
-	switch t := v.(type) {
-	case float64:
-		return int64(t * 1e8)
-	case int64:
-		return t * 1e8
-	case string:
-		if f, err := strconv.ParseFloat(t, 64); err == nil {
-			return int64(f * 1e8)
-		}
+	if btc, ok := coerce.Float64(v); ok {
+		return int64(btc * 1e8)
 	}
 	return 0
 }
@@ -1,13 +1,18 @@
 package smart_contract
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/hex"
+	"image"
+	"image/png"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"stargate-backend/services"
+	"stargate-backend/stego"
 	scstore "stargate-backend/storage/smart_contract"
 )
 
@@ -121,4 +126,26 @@ func newTestIngestionService(t *testing.T) *services.IngestionService {
 		_ = os.Remove(dbPath)
 	})
 	return ingest
-}
\ No newline at end of file
+}
+
+func TestResolvePixelHashFromIngestionUsesCanonicalImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	identity := func(b []byte) []byte { return b }
+
+	rec := &services.IngestionRecord{
+		ImageBase64: base64.StdEncoding.EncodeToString(append([]byte("envelope"), buf.Bytes()...)),
+	}
+	got := hex.EncodeToString(resolvePixelHashFromIngestion(rec, identity))
+	if want := stego.VisiblePixelHash(buf.Bytes()); got != want {
+		t.Fatalf("pixel hash = %s, want hash of canonical png %s", got, want)
+	}
+
+	rec.ImageBase64 = base64.StdEncoding.EncodeToString([]byte("corrupt upload"))
+	if got := resolvePixelHashFromIngestion(rec, identity); got != nil {
+		t.Fatalf("corrupt upload hashed to %x", got)
+	}
+}
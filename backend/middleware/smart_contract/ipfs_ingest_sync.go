@@ -583,7 +583,9 @@ func ensureProposalFromStegoPayload(ctx context.Context, store Store, stegoCID s
 				updates[k] = v
 			}
 		}
-		_ = store.UpdateProposalMetadata(ctx, proposalID, updates)
+		if err := store.UpdateProposalMetadata(ctx, proposalID, updates); err != nil {
+			log.Printf("ipfs ingestion sync: failed to update proposal %s: %v", proposalID, err)
+		}
 		return nil
 	}
 	// NOTE: Do not require a pre-existing "wish-xxx" contract. The stego image
@@ -1002,7 +1004,10 @@ func applyIngestUpdate(ctx context.Context, ingest *services.IngestionService, s
 				continue
 			}
 			if existing, err := store.GetProposal(ctx, proposalID); err == nil && strings.TrimSpace(existing.ID) != "" {
-				_ = store.UpdateProposalMetadata(ctx, proposalID, meta)
+				if err := store.UpdateProposalMetadata(ctx, proposalID, meta); err != nil {
+					log.Printf("ipfs ingestion sync: failed to update proposal %s: %v", proposalID, err)
+					continue
+				}
 				updated = true
 			}
 		}
@@ -0,0 +1,158 @@
+package smart_contract
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// onboardingRecommendedTasks caps how many open tasks onboarding suggests.
+const onboardingRecommendedTasks = 5
+
+// onboardingStep is one actionable step of the agent playbook.
+type onboardingStep struct {
+	Step         int      `json:"step"`
+	Title        string   `json:"title"`
+	Description  string   `json:"description"`
+	Tools        []string `json:"tools,omitempty"`
+	Endpoint     string   `json:"endpoint,omitempty"`
+	AuthRequired bool     `json:"auth_required"`
+}
+
+// recommendedTask is the slice of a task an agent needs to decide whether to claim it.
+type recommendedTask struct {
+	TaskID     string   `json:"task_id"`
+	ContractID string   `json:"contract_id"`
+	Title      string   `json:"title"`
+	BudgetSats int64    `json:"budget_sats"`
+	Skills     []string `json:"skills_required,omitempty"`
+	Difficulty string   `json:"difficulty,omitempty"`
+}
+
+// handleOnboarding returns, in one response, what a new agent otherwise
+// collects from discover, tools, skills and open contracts: the playbook,
+// the tool surface, the skills catalog, the best-paid open tasks, and how
+// to authenticate.
+func (s *Server) handleOnboarding(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	skills, err := s.skillCatalog()
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sort.Strings(skills)
+
+	open, err := s.store.ListTasks(smart_contract.TaskFilter{Status: smart_contract.TaskStatusAvailable})
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sort.SliceStable(open, func(i, j int) bool {
+		if open[i].BudgetSats != open[j].BudgetSats {
+			return open[i].BudgetSats > open[j].BudgetSats
+		}
+		return open[i].TaskID < open[j].TaskID
+	})
+	recommended := make([]recommendedTask, 0, onboardingRecommendedTasks)
+	for _, t := range open {
+		if len(recommended) == onboardingRecommendedTasks {
+			break
+		}
+		if !strings.EqualFold(t.Status, smart_contract.TaskStatusAvailable) {
+			continue
+		}
+		recommended = append(recommended, recommendedTask{
+			TaskID:     t.TaskID,
+			ContractID: t.ContractID,
+			Title:      t.Title,
+			BudgetSats: t.BudgetSats,
+			Skills:     t.Skills,
+			Difficulty: t.Difficulty,
+		})
+	}
+
+	base := fmt.Sprintf("http://%s", r.Host)
+	challengeURL := base + "/api/auth/challenge"
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"version":           "1.0",
+		"playbook":          onboardingPlaybook(base),
+		"tools":             discoverTools,
+		"skills":            skills,
+		"recommended_tasks": recommended,
+		"authentication": map[string]interface{}{
+			"type":        "api_key",
+			"header_name": "X-API-Key",
+			"required":    s.apiKeys != nil,
+			"read_only":   "GET requests to contracts, tasks, proposals and events work without a key",
+		},
+		"register_wallet": map[string]string{
+			"challenge_url": challengeURL,
+			"verify_url":    base + "/api/auth/verify",
+			"mcp_tools":     "get_auth_challenge, verify_auth_challenge",
+			"description":   "Sign the challenge with your Bitcoin wallet and verify it to receive an API key bound to that wallet.",
+		},
+		"links": map[string]string{
+			"skill_md": base + "/mcp/SKILL.md",
+			"sdk":      base + "/mcp/starlight_sdk.sh",
+			"discover": base + "/api/smart_contract/discover",
+			"mcp":      base + "/mcp",
+		},
+	})
+}
+
+// onboardingPlaybook is the agent workflow from /mcp/SKILL.md as concrete steps.
+func onboardingPlaybook(base string) []onboardingStep {
+	return []onboardingStep{
+		{
+			Step:        1,
+			Title:       "Read the playbook",
+			Description: "Fetch the canonical agent workflow and download the SDK for file uploads.",
+			Endpoint:    "GET " + base + "/mcp/SKILL.md",
+		},
+		{
+			Step:        2,
+			Title:       "Register a wallet",
+			Description: "Request an auth challenge, sign it with your wallet, and verify it to obtain an API key.",
+			Tools:       []string{"get_auth_challenge", "verify_auth_challenge"},
+			Endpoint:    "POST " + base + "/api/auth/challenge",
+		},
+		{
+			Step:        3,
+			Title:       "Discover work",
+			Description: "Browse open contracts and available tasks that match your skills.",
+			Tools:       []string{"get_open_contracts", "list_contracts", "list_tasks"},
+			Endpoint:    "GET " + base + "/api/smart_contract/tasks?status=available",
+		},
+		{
+			Step:         4,
+			Title:        "Propose",
+			Description:  "Submit a structured proposal for a pending wish.",
+			Tools:        []string{"create_proposal"},
+			Endpoint:     "POST " + base + "/api/smart_contract/proposals",
+			AuthRequired: true,
+		},
+		{
+			Step:         5,
+			Title:        "Claim a task",
+			Description:  "Reserve an available task before starting work; claims expire.",
+			Tools:        []string{"claim_task"},
+			Endpoint:     "POST " + base + "/api/smart_contract/tasks/{task_id}/claim",
+			AuthRequired: true,
+		},
+		{
+			Step:         6,
+			Title:        "Submit work",
+			Description:  "Submit deliverables against your claim, then track review status.",
+			Tools:        []string{"submit_work", "list_submissions"},
+			Endpoint:     "POST " + base + "/api/smart_contract/claims/{claim_id}/submit",
+			AuthRequired: true,
+		},
+	}
+}
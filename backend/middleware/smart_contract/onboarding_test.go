@@ -0,0 +1,72 @@
+package smart_contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleOnboarding(t *testing.T) {
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{"onboarding-key": {Key: "onboarding-key"}}}
+	mux := http.NewServeMux()
+	NewServer(scstore.NewMemoryStore(time.Hour), keys, nil).RegisterRoutes(mux)
+
+	// New agents have no key yet, so onboarding must work without one.
+	req := httptest.NewRequest(http.MethodGet, "/api/smart_contract/onboarding", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		Playbook         []onboardingStep  `json:"playbook"`
+		Tools            []string          `json:"tools"`
+		Skills           []string          `json:"skills"`
+		RecommendedTasks []recommendedTask `json:"recommended_tasks"`
+		Authentication   map[string]any    `json:"authentication"`
+		RegisterWallet   map[string]string `json:"register_wallet"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Tools) != len(discoverTools) {
+		t.Fatalf("tools = %v, want %v", resp.Tools, discoverTools)
+	}
+	if !containsString(resp.Skills, "contract_bidding") {
+		t.Fatalf("skills missing defaults: %v", resp.Skills)
+	}
+	if len(resp.Playbook) != len(onboardingPlaybook("")) {
+		t.Fatalf("playbook has %d steps, want %d", len(resp.Playbook), len(onboardingPlaybook("")))
+	}
+	for i, step := range resp.Playbook {
+		if step.Step != i+1 || step.Title == "" {
+			t.Fatalf("playbook step %d malformed: %+v", i, step)
+		}
+	}
+	if len(resp.RecommendedTasks) == 0 || len(resp.RecommendedTasks) > onboardingRecommendedTasks {
+		t.Fatalf("recommended tasks = %d, want 1..%d", len(resp.RecommendedTasks), onboardingRecommendedTasks)
+	}
+	for i := 1; i < len(resp.RecommendedTasks); i++ {
+		if resp.RecommendedTasks[i].BudgetSats > resp.RecommendedTasks[i-1].BudgetSats {
+			t.Fatalf("recommended tasks not ordered by budget: %+v", resp.RecommendedTasks)
+		}
+	}
+	if resp.Authentication["header_name"] != "X-API-Key" || resp.RegisterWallet["challenge_url"] == "" {
+		t.Fatalf("missing auth guidance: %+v %+v", resp.Authentication, resp.RegisterWallet)
+	}
+}
+
+func containsString(list []string, want string) bool {
+	for _, v := range list {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
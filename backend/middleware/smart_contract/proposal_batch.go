@@ -0,0 +1,104 @@
+package smart_contract
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+// maxProposalBatch caps how many ingestion records one batch request may import.
+const maxProposalBatch = 100
+
+// proposalBatchResult reports the outcome for one ingestion record in a batch.
+type proposalBatchResult struct {
+	IngestionID string `json:"ingestion_id"`
+	Status      string `json:"status"` // created | failed
+	ProposalID  string `json:"proposal_id,omitempty"`
+	Code        int    `json:"code"`
+	Error       string `json:"error,omitempty"`
+}
+
+// handleProposalBatch creates one proposal per ingestion record. Items are
+// independent and best-effort: a failing record is reported in its result and
+// does not roll back the others.
+// POST /api/smart_contract/proposals/batch {"ingestion_ids":["...", "..."]}
+func (s *Server) handleProposalBatch(w http.ResponseWriter, r *http.Request) {
+	if ct := r.Header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "application/json") {
+		Error(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+	if s.ingestionSvc == nil {
+		Error(w, http.StatusServiceUnavailable, "ingestion service unavailable")
+		return
+	}
+	var body struct {
+		IngestionIDs []string `json:"ingestion_ids"`
+	}
+	if err := decodeStrictJSON(r.Body, &body); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(body.IngestionIDs) == 0 {
+		Error(w, http.StatusBadRequest, "ingestion_ids is required")
+		return
+	}
+	if len(body.IngestionIDs) > maxProposalBatch {
+		Error(w, http.StatusBadRequest, fmt.Sprintf("at most %d ingestion_ids per batch", maxProposalBatch))
+		return
+	}
+
+	results := make([]proposalBatchResult, 0, len(body.IngestionIDs))
+	seen := make(map[string]bool, len(body.IngestionIDs))
+	created := 0
+	for _, raw := range body.IngestionIDs {
+		id := strings.TrimSpace(raw)
+		var result proposalBatchResult
+		switch {
+		case id == "":
+			result = failedBatchItem(id, http.StatusBadRequest, "empty ingestion_id")
+		case seen[id]:
+			result = failedBatchItem(id, http.StatusConflict, "duplicate ingestion_id in batch")
+		default:
+			seen[id] = true
+			result = s.createBatchProposal(r, id)
+		}
+		if result.Status == "created" {
+			created++
+		}
+		results = append(results, result)
+	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"results": results,
+		"created": created,
+		"failed":  len(results) - created,
+	})
+}
+
+// createBatchProposal validates the record's visible pixel hash, then
+// creates its proposal the same way a single create_proposal call would.
+func (s *Server) createBatchProposal(r *http.Request, ingestionID string) proposalBatchResult {
+	rec, err := s.ingestionSvc.Get(ingestionID)
+	if err != nil {
+		return failedBatchItem(ingestionID, http.StatusNotFound, "ingestion not found")
+	}
+	visible, _ := rec.Metadata["visible_pixel_hash"].(string)
+	if visible = strings.TrimSpace(visible); visible == "" && rec.ImageBase64 != "" {
+		visible, _ = hashBase64(rec.ImageBase64)
+	}
+	if !scstore.IsValidHash(visible) {
+		return failedBatchItem(ingestionID, http.StatusBadRequest, "ingestion has no valid visible_pixel_hash (want 64 hex chars)")
+	}
+
+	proposal, status, err := s.createProposalFromIngestion(r, ProposalCreateBody{IngestionID: ingestionID})
+	if err != nil {
+		return failedBatchItem(ingestionID, status, err.Error())
+	}
+	return proposalBatchResult{IngestionID: ingestionID, Status: "created", ProposalID: proposal.ID, Code: status}
+}
+
+func failedBatchItem(ingestionID string, code int, msg string) proposalBatchResult {
+	return proposalBatchResult{IngestionID: ingestionID, Status: "failed", Code: code, Error: msg}
+}
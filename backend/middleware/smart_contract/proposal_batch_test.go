@@ -0,0 +1,110 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	"stargate-backend/services"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestProposalBatchFromIngestions(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	ingest := newTestIngestionService(t)
+	ctx := context.Background()
+
+	records := map[string]string{
+		"batch-ingest-1":   strings.Repeat("1", 64),
+		"batch-ingest-2":   strings.Repeat("2", 64),
+		"batch-ingest-bad": "not-a-pixel-hash",
+	}
+	for id, hash := range records {
+		if err := ingest.Create(services.IngestionRecord{
+			ID:       id,
+			Filename: id + ".png",
+			Method:   "alpha",
+			Metadata: map[string]interface{}{
+				"embedded_message":   "* Build " + id,
+				"visible_pixel_hash": hash,
+				"contract_id":        hash,
+				"budget_sats":        int64(1000),
+			},
+			Status: "pending",
+		}); err != nil {
+			t.Fatalf("create ingestion %s: %v", id, err)
+		}
+		if err := store.UpsertContractWithTasks(ctx, smart_contract.Contract{
+			ContractID: "wish-" + hash,
+			Title:      "Wish " + id,
+			Status:     "pending",
+		}, nil); err != nil {
+			t.Fatalf("seed wish %s: %v", id, err)
+		}
+	}
+
+	const apiKey = "batch-key"
+	mux := http.NewServeMux()
+	NewServer(store, &mockAPIKeyStore{keys: map[string]auth.APIKey{apiKey: {Key: apiKey}}}, ingest).RegisterRoutes(mux)
+
+	body := `{"ingestion_ids":["batch-ingest-1","batch-ingest-bad","batch-ingest-2","batch-ingest-1","missing-ingest"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/smart_contract/proposals/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", apiKey)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp struct {
+		Results []proposalBatchResult `json:"results"`
+		Created int                   `json:"created"`
+		Failed  int                   `json:"failed"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Created != 2 || resp.Failed != 3 || len(resp.Results) != 5 {
+		t.Fatalf("created=%d failed=%d results=%d, want 2/3/5", resp.Created, resp.Failed, len(resp.Results))
+	}
+
+	want := []struct {
+		status string
+		code   int
+	}{
+		{"created", http.StatusCreated},
+		{"failed", http.StatusBadRequest},
+		{"created", http.StatusCreated},
+		{"failed", http.StatusConflict},
+		{"failed", http.StatusNotFound},
+	}
+	for i, w := range want {
+		got := resp.Results[i]
+		if got.Status != w.status || got.Code != w.code {
+			t.Fatalf("result %d (%s) = %s/%d (%s), want %s/%d", i, got.IngestionID, got.Status, got.Code, got.Error, w.status, w.code)
+		}
+	}
+
+	for _, result := range resp.Results[:3] {
+		if result.Status != "created" {
+			continue
+		}
+		proposal, err := store.GetProposal(ctx, result.ProposalID)
+		if err != nil {
+			t.Fatalf("proposal %s not stored: %v", result.ProposalID, err)
+		}
+		if proposal.VisiblePixelHash != records[result.IngestionID] {
+			t.Fatalf("proposal %s hash = %q, want %q", proposal.ID, proposal.VisiblePixelHash, records[result.IngestionID])
+		}
+	}
+	if _, err := store.GetProposal(ctx, "proposal-batch-ingest-bad"); err == nil {
+		t.Fatal("proposal created for record with invalid hash")
+	}
+}
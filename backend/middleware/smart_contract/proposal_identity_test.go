@@ -0,0 +1,109 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	"stargate-backend/services"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+// TestProposalIdentityAcrossPaths pins contract_id == visible_pixel_hash for
+// every way a proposal is created or edited over HTTP.
+func TestProposalIdentityAcrossPaths(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	ingest := newTestIngestionService(t)
+	ctx := context.Background()
+	hashA, hashB := strings.Repeat("a", 64), strings.Repeat("b", 64)
+	for _, hash := range []string{hashA, hashB} {
+		if err := store.UpsertContractWithTasks(ctx, smart_contract.Contract{ContractID: "wish-" + hash, Title: "Wish", Status: "pending"}, nil); err != nil {
+			t.Fatalf("seed wish: %v", err)
+		}
+	}
+	for id, meta := range map[string]map[string]interface{}{
+		"ingest-hash-only": {"visible_pixel_hash": hashA},
+		"ingest-routed":    {"contract_id": "wish-" + hashA},
+		"ingest-mismatch":  {"visible_pixel_hash": hashA, "contract_id": hashB},
+	} {
+		meta["embedded_message"] = "# Identity wish"
+		if err := ingest.Create(services.IngestionRecord{ID: id, Filename: id + ".png", Method: "alpha", Metadata: meta, Status: "pending"}); err != nil {
+			t.Fatalf("create ingestion %s: %v", id, err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	NewServer(store, nil, ingest).RegisterRoutes(mux)
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	assertIdentity := func(t *testing.T, id, want string) {
+		t.Helper()
+		p, err := store.GetProposal(ctx, id)
+		if err != nil {
+			t.Fatalf("proposal %s: %v", id, err)
+		}
+		contractID, _ := p.Metadata["contract_id"].(string)
+		metaHash, _ := p.Metadata["visible_pixel_hash"].(string)
+		if p.VisiblePixelHash != want || metaHash != want || contractID != want {
+			t.Fatalf("proposal %s identity = field %q, metadata %q, contract_id %q; want all %q", id, p.VisiblePixelHash, metaHash, contractID, want)
+		}
+	}
+
+	created := []struct {
+		name, path, body string
+	}{
+		{"manual hash only", "/api/smart_contract/proposals", `{"id":"p-hash","title":"T","visible_pixel_hash":"` + hashA + `"}`},
+		{"manual contract only", "/api/smart_contract/proposals", `{"title":"T","contract_id":"` + hashA + `"}`},
+		{"manual wish prefix", "/api/smart_contract/proposals", `{"title":"T","visible_pixel_hash":"` + hashA + `","contract_id":"wish-` + hashA + `"}`},
+		{"from ingestion", "/api/smart_contract/proposals", `{"ingestion_id":"ingest-hash-only"}`},
+		{"propose route", "/api/ingestion/ingest-routed/propose", `{"title":"Routed"}`},
+	}
+	for _, tc := range created {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := send(http.MethodPost, tc.path, tc.body)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+			}
+			var resp struct {
+				ProposalID string `json:"proposal_id"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			assertIdentity(t, resp.ProposalID, hashA)
+		})
+	}
+
+	rejected := []struct {
+		name, method, path, body string
+	}{
+		{"manual mismatch", http.MethodPost, "/api/smart_contract/proposals", `{"title":"T","visible_pixel_hash":"` + hashA + `","contract_id":"` + hashB + `"}`},
+		{"manual metadata mismatch", http.MethodPost, "/api/smart_contract/proposals", `{"title":"T","visible_pixel_hash":"` + hashA + `","metadata":{"visible_pixel_hash":"` + hashB + `"}}`},
+		{"ingestion mismatch", http.MethodPost, "/api/smart_contract/proposals", `{"ingestion_id":"ingest-mismatch"}`},
+		{"propose override mismatch", http.MethodPost, "/api/ingestion/ingest-hash-only/propose", `{"contract_id":"` + hashB + `"}`},
+		{"patch contract only", http.MethodPatch, "/api/smart_contract/proposals/p-hash", `{"contract_id":"` + hashB + `"}`},
+	}
+	for _, tc := range rejected {
+		t.Run(tc.name, func(t *testing.T) {
+			if rec := send(tc.method, tc.path, tc.body); rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	// Moving a proposal to another wish carries contract_id with it.
+	if rec := send(http.MethodPatch, "/api/smart_contract/proposals/p-hash", `{"visible_pixel_hash":"`+hashB+`"}`); rec.Code != http.StatusOK {
+		t.Fatalf("patch hash status = %d: %s", rec.Code, rec.Body.String())
+	}
+	assertIdentity(t, "p-hash", hashB)
+}
@@ -0,0 +1,102 @@
+package smart_contract
+
+import (
+	"os"
+	"strings"
+
+	"stargate-backend/coerce"
+)
+
+// Title strategies control how BuildProposalFromIngestion derives a proposal
+// title from an ingestion's embedded message when the caller supplies none.
+// A record's "title_strategy" metadata wins over STARGATE_PROPOSAL_TITLE_STRATEGY;
+// unknown or empty values fall back to TitleStrategyHeading.
+const (
+	// TitleStrategyHeading uses the first markdown heading, else the first line.
+	TitleStrategyHeading = "markdown_heading"
+	// TitleStrategyFirstLine uses the first non-empty line.
+	TitleStrategyFirstLine = "first_line"
+	// TitleStrategyFirstWord uses the first word, the original behaviour.
+	TitleStrategyFirstWord = "first_word"
+)
+
+// maxProposalTitleRunes keeps derived titles readable in listings.
+const maxProposalTitleRunes = 120
+
+var titleStrategies = map[string]func(message string) string{
+	TitleStrategyHeading: func(message string) string {
+		for _, line := range strings.Split(message, "\n") {
+			line = strings.TrimSpace(line)
+			if text, ok := markdownHeading(line); ok {
+				return text
+			}
+		}
+		return firstLineTitle(message)
+	},
+	TitleStrategyFirstLine: firstLineTitle,
+	TitleStrategyFirstWord: func(message string) string {
+		if fields := strings.Fields(firstLineTitle(message)); len(fields) > 0 {
+			return fields[0]
+		}
+		return ""
+	},
+}
+
+// titleStrategyFor picks the strategy for an ingestion's metadata.
+func titleStrategyFor(meta map[string]interface{}) string {
+	for _, name := range []string{
+		strings.TrimSpace(coerce.String(meta["title_strategy"])),
+		strings.TrimSpace(os.Getenv("STARGATE_PROPOSAL_TITLE_STRATEGY")),
+	} {
+		name = strings.ToLower(name)
+		if _, ok := titleStrategies[name]; ok {
+			return name
+		}
+	}
+	return TitleStrategyHeading
+}
+
+// titleFromMessage derives a proposal title from an embedded message using
+// the named strategy. It returns "" when the message has no usable text.
+func titleFromMessage(message, strategy string) string {
+	message, _ = stripWishTimestamp(message, nil)
+	derive, ok := titleStrategies[strategy]
+	if !ok {
+		derive = titleStrategies[TitleStrategyHeading]
+	}
+	title := strings.TrimSpace(derive(message))
+	if runes := []rune(title); len(runes) > maxProposalTitleRunes {
+		title = strings.TrimSpace(string(runes[:maxProposalTitleRunes])) + "…"
+	}
+	return title
+}
+
+// firstLineTitle returns the first non-empty line with any heading, list or
+// quote marker removed.
+func firstLineTitle(message string) string {
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if text, ok := markdownHeading(line); ok {
+			line = text
+		}
+		line = strings.TrimSpace(strings.TrimLeft(line, "*->+ "))
+		if line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// markdownHeading reports the text of an ATX heading line such as "## Title".
+func markdownHeading(line string) (string, bool) {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 {
+		return "", false
+	}
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", false
+	}
+	text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(rest), "#"))
+	return text, text != ""
+}
@@ -0,0 +1,98 @@
+package smart_contract
+
+import (
+	"strings"
+	"testing"
+
+	"stargate-backend/services"
+)
+
+const titleTestMessage = `Intro paragraph before the heading
+with a second line.
+
+## Build a Bollinger band dashboard ##
+
+* Fetch price history
+* Render bands
+
+[stargate-ts:1769015334]`
+
+func TestTitleFromMessageStrategies(t *testing.T) {
+	tests := []struct {
+		strategy string
+		message  string
+		want     string
+	}{
+		{TitleStrategyHeading, titleTestMessage, "Build a Bollinger band dashboard"},
+		{TitleStrategyFirstLine, titleTestMessage, "Intro paragraph before the heading"},
+		{TitleStrategyFirstWord, titleTestMessage, "Intro"},
+		{"unknown", titleTestMessage, "Build a Bollinger band dashboard"},
+
+		// Heading falls back to the first line, stripped of list markers.
+		{TitleStrategyHeading, "\n* Fetch price history\n* Render bands", "Fetch price history"},
+		{TitleStrategyFirstLine, "# Title line\nbody", "Title line"},
+		{TitleStrategyFirstWord, "## Dashboard work\nbody", "Dashboard"},
+		{TitleStrategyHeading, "#hashtag is not a heading", "#hashtag is not a heading"},
+
+		{TitleStrategyHeading, "", ""},
+		{TitleStrategyFirstWord, "  \n\t\n", ""},
+	}
+	for _, tc := range tests {
+		if got := titleFromMessage(tc.message, tc.strategy); got != tc.want {
+			t.Errorf("titleFromMessage(%q, %s) = %q, want %q", tc.message, tc.strategy, got, tc.want)
+		}
+	}
+
+	long := "# " + strings.Repeat("word ", 60)
+	if got := titleFromMessage(long, TitleStrategyHeading); len([]rune(got)) > maxProposalTitleRunes+1 || !strings.HasSuffix(got, "…") {
+		t.Errorf("long title not truncated: %q", got)
+	}
+}
+
+func TestTitleStrategySelection(t *testing.T) {
+	t.Setenv("STARGATE_PROPOSAL_TITLE_STRATEGY", "")
+	if got := titleStrategyFor(nil); got != TitleStrategyHeading {
+		t.Fatalf("default strategy = %q, want %q", got, TitleStrategyHeading)
+	}
+
+	t.Setenv("STARGATE_PROPOSAL_TITLE_STRATEGY", "First_Line")
+	if got := titleStrategyFor(nil); got != TitleStrategyFirstLine {
+		t.Fatalf("env strategy = %q, want %q", got, TitleStrategyFirstLine)
+	}
+	if got := titleStrategyFor(map[string]interface{}{"title_strategy": "first_word"}); got != TitleStrategyFirstWord {
+		t.Fatalf("metadata strategy = %q, want %q", got, TitleStrategyFirstWord)
+	}
+	if got := titleStrategyFor(map[string]interface{}{"title_strategy": "bogus"}); got != TitleStrategyFirstLine {
+		t.Fatalf("unknown metadata strategy = %q, want env fallback %q", got, TitleStrategyFirstLine)
+	}
+
+	t.Setenv("STARGATE_PROPOSAL_TITLE_STRATEGY", "bogus")
+	if got := titleStrategyFor(nil); got != TitleStrategyHeading {
+		t.Fatalf("unknown env strategy = %q, want %q", got, TitleStrategyHeading)
+	}
+}
+
+func TestBuildProposalFromIngestionTitle(t *testing.T) {
+	t.Setenv("STARGATE_PROPOSAL_TITLE_STRATEGY", "")
+	rec := &services.IngestionRecord{
+		ID:       "title-ingest",
+		Metadata: map[string]interface{}{"embedded_message": titleTestMessage},
+	}
+	proposal, err := BuildProposalFromIngestion(ProposalCreateBody{}, rec)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if proposal.Title != "Build a Bollinger band dashboard" {
+		t.Fatalf("title = %q", proposal.Title)
+	}
+
+	rec.Metadata = map[string]interface{}{"embedded_message": titleTestMessage, "title_strategy": TitleStrategyFirstWord}
+	if proposal, _ = BuildProposalFromIngestion(ProposalCreateBody{}, rec); proposal.Title != "Intro" {
+		t.Fatalf("first_word title = %q", proposal.Title)
+	}
+
+	rec.Metadata = map[string]interface{}{"embedded_message": "   "}
+	if proposal, _ = BuildProposalFromIngestion(ProposalCreateBody{}, rec); proposal.Title != "Proposal title-ingest" {
+		t.Fatalf("blank message title = %q", proposal.Title)
+	}
+}
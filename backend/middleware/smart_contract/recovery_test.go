@@ -0,0 +1,32 @@
+package smart_contract
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegisteredRoutesRecoverPanics(t *testing.T) {
+	var logs bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&logs)
+	defer log.SetOutput(prev)
+
+	mux := http.NewServeMux()
+	recoveringMux{mux}.HandleFunc("/api/smart_contract/boom", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/smart_contract/boom", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", rec.Code)
+	}
+	if !strings.Contains(logs.String(), "Panic recovered") || !strings.Contains(logs.String(), "goroutine ") {
+		t.Fatalf("panic not logged with stack:\n%s", logs.String())
+	}
+}
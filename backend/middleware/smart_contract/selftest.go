@@ -0,0 +1,249 @@
+package smart_contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"stargate-backend/bitcoin"
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+// selfTestStep is one line of the self-test report.
+type selfTestStep struct {
+	Step       int    `json:"step"`
+	Name       string `json:"name"`
+	Passed     bool   `json:"passed"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// selfTestReport is the response of POST /api/smart_contract/selftest.
+type selfTestReport struct {
+	Passed     bool           `json:"passed"`
+	Store      string         `json:"store"`
+	Steps      []selfTestStep `json:"steps"`
+	StartedAt  time.Time      `json:"started_at"`
+	DurationMS int64          `json:"duration_ms"`
+}
+
+const (
+	selfTestBudgetSats  = 10000
+	selfTestFundingSats = 50000
+)
+
+// handleSelfTest runs the proposal-to-payout cycle against a throwaway memory
+// store and reports each step. The server's own store is never touched.
+func (s *Server) handleSelfTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	report := runSelfTest(r.Context())
+	JSON(w, http.StatusOK, report)
+}
+
+// runSelfTest creates, approves, claims, submits and reviews a proposal in a
+// fresh memory store, then builds a payout PSBT against a synthetic UTXO.
+// Steps after the first failure are reported as skipped.
+func runSelfTest(ctx context.Context) selfTestReport {
+	report := selfTestReport{Passed: true, Store: "memory", StartedAt: time.Now().UTC()}
+	store := scstore.NewMemoryStore(time.Hour)
+	params := &chaincfg.TestNet4Params
+	payer, _ := btcutil.NewAddressWitnessPubKeyHash(bytes.Repeat([]byte{0x51}, 20), params)
+	worker, _ := btcutil.NewAddressWitnessPubKeyHash(bytes.Repeat([]byte{0x52}, 20), params)
+
+	suffix := fmt.Sprintf("%d", report.StartedAt.UnixNano())
+	visibleHash := hex.EncodeToString(chainhash.HashB([]byte("selftest-" + suffix)))
+	proposalID := "selftest-proposal-" + suffix
+	var taskID, claimID, submissionID string
+
+	steps := []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{"create_proposal", func() (string, error) {
+			wish := smart_contract.Contract{ContractID: "wish-" + visibleHash, Title: "Self-test wish", Status: "pending"}
+			if err := store.UpsertContractWithTasks(ctx, wish, nil); err != nil {
+				return "", err
+			}
+			proposal := smart_contract.Proposal{
+				ID:               proposalID,
+				Title:            "Self-test proposal",
+				DescriptionMD:    "Self-test proposal exercising the full contract cycle.",
+				VisiblePixelHash: visibleHash,
+				BudgetSats:       selfTestBudgetSats,
+				Status:           "pending",
+				CreatedAt:        time.Now(),
+				Tasks: []smart_contract.Task{{
+					TaskID:     proposalID + "-task-1",
+					Title:      "Self-test task",
+					BudgetSats: selfTestBudgetSats,
+					Status:     "available",
+				}},
+				Metadata: map[string]interface{}{
+					"creator_wallet":     payer.EncodeAddress(),
+					"funding_address":    payer.EncodeAddress(),
+					"visible_pixel_hash": visibleHash,
+				},
+			}
+			if err := store.CreateProposal(ctx, proposal); err != nil {
+				return "", err
+			}
+			return "proposal " + proposalID, nil
+		}},
+		{"approve_proposal", func() (string, error) {
+			if err := store.ApproveProposal(ctx, proposalID); err != nil {
+				return "", err
+			}
+			p, err := store.GetProposal(ctx, proposalID)
+			if err != nil {
+				return "", err
+			}
+			contract, tasks := proposalContractTasks(p)
+			if err := store.UpsertContractWithTasks(ctx, contract, tasks); err != nil {
+				return "", err
+			}
+			taskID = tasks[0].TaskID
+			return fmt.Sprintf("contract %s published with %d task(s)", contract.ContractID, len(tasks)), nil
+		}},
+		{"claim_task", func() (string, error) {
+			claim, err := store.ClaimTask(taskID, worker.EncodeAddress(), nil)
+			if err != nil {
+				return "", err
+			}
+			claimID = claim.ClaimID
+			return "claim " + claimID, nil
+		}},
+		{"submit_work", func() (string, error) {
+			sub, err := store.SubmitWork(claimID, map[string]interface{}{"notes": "self-test deliverable"}, nil)
+			if err != nil {
+				return "", err
+			}
+			submissionID = sub.SubmissionID
+			return "submission " + submissionID, nil
+		}},
+		{"review_submission", func() (string, error) {
+			if err := store.UpdateSubmissionStatus(ctx, submissionID, "approved", "", ""); err != nil {
+				return "", err
+			}
+			task, err := store.GetTask(taskID)
+			if err != nil {
+				return "", err
+			}
+			if task.Status != "approved" {
+				return "", fmt.Errorf("task status %q after approval, want approved", task.Status)
+			}
+			return "task " + taskID + " approved", nil
+		}},
+		{"build_payout", func() (string, error) {
+			task, err := store.GetTask(taskID)
+			if err != nil {
+				return "", err
+			}
+			contractor, err := btcutil.DecodeAddress(task.ContractorWallet, params)
+			if err != nil {
+				return "", fmt.Errorf("contractor wallet: %w", err)
+			}
+			mempool, err := newSelfTestMempool(payer, selfTestFundingSats)
+			if err != nil {
+				return "", err
+			}
+			pixel, _ := hex.DecodeString(visibleHash)
+			res, err := bitcoin.BuildFundingPSBT(mempool, params, bitcoin.PSBTRequest{
+				PayerAddress:      payer,
+				TargetValueSats:   task.BudgetSats,
+				PixelHash:         pixel,
+				ContractorAddress: contractor,
+				FeeRateSatPerVB:   1,
+			})
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("dummy payout of %d sats to %s, fee %d sats", task.BudgetSats, task.ContractorWallet, res.FeeSats), nil
+		}},
+	}
+
+	for i, step := range steps {
+		result := selfTestStep{Step: i + 1, Name: step.name}
+		if !report.Passed {
+			result.Error = "skipped after earlier failure"
+			report.Steps = append(report.Steps, result)
+			continue
+		}
+		start := time.Now()
+		detail, err := step.run()
+		result.DurationMS = time.Since(start).Milliseconds()
+		if err != nil {
+			result.Error = err.Error()
+			report.Passed = false
+		} else {
+			result.Passed = true
+			result.Detail = detail
+		}
+		report.Steps = append(report.Steps, result)
+	}
+	report.DurationMS = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+// newSelfTestMempool returns a mempool client whose only UTXO is a confirmed
+// output of value sats to payer, served in-process without network access.
+func newSelfTestMempool(payer btcutil.Address, value int64) (*bitcoin.MempoolClient, error) {
+	script, err := txscript.PayToAddrScript(payer)
+	if err != nil {
+		return nil, err
+	}
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{}, 0xffffffff), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(value, script))
+	var raw bytes.Buffer
+	if err := tx.Serialize(&raw); err != nil {
+		return nil, err
+	}
+	txid := tx.TxHash().String()
+	utxos, _ := json.Marshal([]map[string]interface{}{{
+		"txid":   txid,
+		"vout":   0,
+		"value":  value,
+		"status": map[string]interface{}{"confirmed": true},
+	}})
+	routes := map[string][]byte{
+		"/address/" + payer.EncodeAddress() + "/utxo": utxos,
+		"/tx/" + txid + "/raw":                        []byte(hex.EncodeToString(raw.Bytes())),
+	}
+	client := &http.Client{Transport: selfTestTransport(routes)}
+	return bitcoin.NewMempoolClientWithHTTP("http://selftest.invalid", client), nil
+}
+
+// selfTestTransport answers mempool requests from a fixed path table.
+type selfTestTransport map[string][]byte
+
+func (t selfTestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, ok := t[strings.TrimSuffix(req.URL.Path, "/")]
+	status := http.StatusOK
+	if !ok {
+		status, body = http.StatusNotFound, []byte("not found")
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,55 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestSelfTestPassesOnCleanInstance(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	before, err := store.ListProposals(context.Background(), smart_contract.ProposalFilter{})
+	if err != nil {
+		t.Fatalf("list proposals: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	NewServer(store, nil, nil).RegisterRoutes(mux)
+	req := httptest.NewRequest(http.MethodPost, "/api/smart_contract/selftest", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var report selfTestReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	want := []string{"create_proposal", "approve_proposal", "claim_task", "submit_work", "review_submission", "build_payout"}
+	if len(report.Steps) != len(want) {
+		t.Fatalf("steps = %+v, want %v", report.Steps, want)
+	}
+	for i, step := range report.Steps {
+		if step.Name != want[i] || !step.Passed {
+			t.Errorf("step %d = %+v, want %s passed", i+1, step, want[i])
+		}
+	}
+	if !report.Passed {
+		t.Fatalf("report did not pass: %s", rec.Body.String())
+	}
+
+	after, err := store.ListProposals(context.Background(), smart_contract.ProposalFilter{})
+	if err != nil {
+		t.Fatalf("list proposals: %v", err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("self-test wrote to the server store: %d proposals, had %d", len(after), len(before))
+	}
+}
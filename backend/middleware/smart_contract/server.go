@@ -3,7 +3,6 @@ package smart_contract
 import (
 	"context"
 	"crypto/sha256"
-	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -21,7 +20,10 @@ import (
 	"github.com/btcsuite/btcd/chaincfg"
 	"golang.org/x/crypto/ripemd160"
 	"stargate-backend/bitcoin"
+	"stargate-backend/coerce"
 	"stargate-backend/core/smart_contract"
+	"stargate-backend/logging"
+	"stargate-backend/middleware"
 	"stargate-backend/storage/ipfs"
 	"stargate-backend/services"
 	auth "stargate-backend/storage/auth"
@@ -37,8 +39,25 @@ type Server struct {
 	eventsMu     sync.Mutex
 	listenersMu  sync.Mutex
 	listeners    []chan smart_contract.Event
+	// Event stream backpressure, see event_stream.go. The counters are
+	// guarded by listenersMu.
+	eventBuffer           int
+	eventPolicy           string
+	droppedEvents         uint64
+	disconnectedListeners uint64
+	streamsClosed         bool
+	// listenerStalledSince records when broadcastEvent first found a
+	// listener's buffer full; the entry is cleared once it has room again.
+	listenerStalledSince map[chan smart_contract.Event]time.Time
+	listenerStallTimeout time.Duration
+	adminKeys            map[string]bool
+	gcMu         sync.Mutex
+	gcSweepers   map[string]GCSweeper
 	mempool            *bitcoin.MempoolClient
 	escort             *smart_contract.EscortService
+	linker             ContractLinker
+	rateLimits         map[string]interface{}
+	claimETAHorizon    time.Duration
 }
 
 // SetEscortService sets the escort service for the server.
@@ -46,6 +65,12 @@ func (s *Server) SetEscortService(escort *smart_contract.EscortService) {
 	s.escort = escort
 }
 
+// SetRateLimitInfo sets the rate_limits block handleDiscover advertises. The
+// MCP server fills it in with the limits it enforces on tool calls.
+func (s *Server) SetRateLimitInfo(info map[string]interface{}) {
+	s.rateLimits = info
+}
+
 
 
 // proposalCreateBody captures POST payload for creating proposals.
@@ -140,7 +165,7 @@ func (s *Server) enforceCreatorApproval(r *http.Request, proposal smart_contract
 	}
 
 	if !hasWishCreatorInfo {
-		log.Printf("WARNING: allowing approval for proposal %s with NO wish creator info via REST", proposal.ID)
+		logging.Warnf("allowing approval for proposal %s with NO wish creator info via REST", proposal.ID)
 		return nil
 	}
 
@@ -155,12 +180,20 @@ func NewServer(store Store, apiKeys auth.APIKeyValidator, ingest *services.Inges
 		ingestionSvc: ingest,
 		mempool:      bitcoin.NewMempoolClient(),
 	}
+	srv.eventBuffer, srv.eventPolicy = eventStreamConfigFromEnv()
+	srv.listenerStallTimeout = listenerStallTimeoutFromEnv()
+	srv.adminKeys = adminAPIKeysFromEnv()
+	srv.claimETAHorizon = claimETAHorizonFromEnv()
 	RegisterEventSink(srv.recordEvent)
 	return srv
 }
 
-// RegisterRoutes attaches handlers to the mux.
-func (s *Server) RegisterRoutes(mux *http.ServeMux) {
+// RegisterRoutes attaches handlers to the mux, each wrapped in panic
+// recovery so the routes are protected even when served outside the main
+// middleware chain.
+func (s *Server) RegisterRoutes(root *http.ServeMux) {
+	mux := recoveringMux{root}
+
 	// Health and config endpoints
 	mux.HandleFunc("/healthz", s.handleHealth)
 	mux.HandleFunc("/api/smart_contract/config", s.authWrap(s.handleConfig))
@@ -179,11 +212,15 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	// Skill and discovery endpoints
 	mux.HandleFunc("/api/smart_contract/skills", s.authWrap(s.handleSkills))
 	mux.HandleFunc("/api/smart_contract/discover", s.authWrap(s.handleDiscover))
+	mux.HandleFunc("/api/smart_contract/onboarding", s.authWrapReadOnly(s.handleOnboarding))
 
 	// Proposal endpoints
 	mux.HandleFunc("/api/smart_contract/proposals", s.authWrapReadOnly(s.handleProposals))
 	mux.HandleFunc("/api/smart_contract/proposals/", s.authWrapReadOnly(s.handleProposals))
 
+	// Ingestion endpoints
+	mux.HandleFunc("/api/ingestion/", s.authWrap(s.handleIngestionPropose))
+
 	// Submission endpoints
 	mux.HandleFunc("/api/smart_contract/submissions", s.authWrap(s.handleSubmissions))
 	mux.HandleFunc("/api/smart_contract/submissions/", s.authWrap(s.handleSubmissions))
@@ -191,9 +228,34 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	// Event endpoints
 	mux.HandleFunc("/api/smart_contract/events", s.authWrapReadOnly(s.handleEvents))
 
+	// Status counts for dashboards
+	mux.HandleFunc("/api/smart_contract/stats", s.authWrapReadOnly(s.handleStats))
+
+	// Bulk export
+	mux.HandleFunc("/api/smart_contract/export.ndjson", s.authWrap(s.handleExport))
+
 	// Stego endpoints (still using original handlers for now)
 	mux.HandleFunc("/api/smart_contract/stego/reconcile", s.authWrap(s.handleStegoReconcile))
 	mux.HandleFunc("/api/smart_contract/stego/payload/", s.authWrap(s.handleStegoPayload))
+
+	// Admin endpoints
+	mux.HandleFunc("/api/smart_contract/admin/fixtures", s.adminWrap(s.handleAdminFixtures))
+	mux.HandleFunc("/api/smart_contract/admin/reset-and-seed", s.adminWrap(s.handleAdminResetAndSeed))
+	mux.HandleFunc("/api/smart_contract/admin/consistency", s.adminWrap(s.handleAdminConsistency))
+	mux.HandleFunc("/api/admin/repair", s.adminWrap(s.handleAdminRepair))
+	mux.HandleFunc("/api/admin/gc", s.adminWrap(s.handleAdminGC))
+
+	// Self-test: full happy-path cycle against a throwaway memory store
+	mux.HandleFunc("/api/smart_contract/selftest", s.authWrap(s.handleSelfTest))
+}
+
+// recoveringMux registers handlers on a ServeMux behind middleware.Recovery.
+type recoveringMux struct {
+	*http.ServeMux
+}
+
+func (m recoveringMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, middleware.Recovery(http.HandlerFunc(handler)))
 }
 
 func (s *Server) authWrap(next http.HandlerFunc) http.HandlerFunc {
@@ -282,10 +344,23 @@ func (s *Server) handleContracts(w http.ResponseWriter, r *http.Request) {
 		if path == "" || path == "/" {
 			status := r.URL.Query().Get("status")
 			skills := splitCSV(r.URL.Query().Get("skills"))
+			fundingStatus := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("funding_status")))
+			if fundingStatus != "" && !smart_contract.ValidFundingStatus(fundingStatus) {
+				Error(w, http.StatusBadRequest, "funding_status must be funded, partial or unfunded")
+				return
+			}
+			inactiveSince, byActivity, err := activityQuery(r)
+			if err != nil {
+				Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
 			filter := smart_contract.ContractFilter{
-				Status:  status,
-				Skills:  skills,
-				Creator: r.URL.Query().Get("creator"),
+				Status:              status,
+				Skills:              skills,
+				Creator:             r.URL.Query().Get("creator"),
+				FundingStatus:       fundingStatus,
+				InactiveSince:       inactiveSince,
+				OrderByLastActivity: byActivity,
 			}
 			contracts, err := s.store.ListContracts(filter)
 			if err != nil {
@@ -343,6 +418,11 @@ func (s *Server) handleContracts(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if len(parts) > 1 && parts[1] == "tasks" {
+			s.handleContractTasks(w, r, contractID)
+			return
+		}
+
 		contract, err := s.store.GetContract(contractID)
 		if err != nil {
 			Error(w, http.StatusNotFound, err.Error())
@@ -370,6 +450,11 @@ func (s *Server) handleContracts(w http.ResponseWriter, r *http.Request) {
 			s.handleContractRework(w, r, contractID)
 			return
 		}
+		if len(parts) > 1 && parts[1] == "link-ingestion" {
+			contractID := parts[0]
+			s.handleLinkIngestion(w, r, contractID)
+			return
+		}
 		Error(w, http.StatusNotFound, "unknown contract action")
 	case http.MethodPatch:
 		if len(parts) > 1 && parts[1] == "rework" && len(parts) > 2 && parts[2] != "" {
@@ -406,8 +491,8 @@ func (s *Server) handleContractRework(w http.ResponseWriter, r *http.Request, co
 	var body struct {
 		Notes string `json:"notes"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		Error(w, http.StatusBadRequest, "invalid request body")
+	if err := decodeStrictJSON(r.Body, &body); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -528,8 +613,8 @@ func (s *Server) handleContractPSBT(w http.ResponseWriter, r *http.Request, cont
 			AmountSats int64  `json:"amount_sats"`
 		} `json:"payouts"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		Error(w, http.StatusBadRequest, "invalid json")
+	if err := decodeStrictJSON(r.Body, &body); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -581,26 +666,18 @@ func (s *Server) handleContractPSBT(w http.ResponseWriter, r *http.Request, cont
 		target = scstore.DefaultBudgetSats()
 	}
 
-	// Handle commitment_sats separately from budget_sats
+	// Handle commitment_sats separately from budget_sats. Unset means the
+	// default for donations and no commitment output otherwise; explicit
+	// values must clear the dust limit or the output would not relay.
 	commitmentSats := body.CommitmentSats
-	log.Printf("DEBUG: Initial body.CommitmentSats=%d, body.CommitmentTarget=%s", body.CommitmentSats, body.CommitmentTarget)
-	if commitmentSats <= 0 {
-		// If user is skipping donation (commitment_target != 'donation'), set commitment budget to 0
-		// Otherwise fall back to contract budget for task payouts
-		if body.CommitmentTarget != "donation" {
-			commitmentSats = 0
-			log.Printf("DEBUG: Setting commitmentSats=0 for skipped donation")
-		} else {
-			commitmentSats = 1000
-			log.Printf("DEBUG: Setting commitmentSats=1000 for donation")
+	if commitmentSats == 0 {
+		if body.CommitmentTarget == "donation" {
+			commitmentSats = bitcoin.CommitmentSatsFromEnv()
 		}
+	} else if err := bitcoin.ValidateCommitmentSats(commitmentSats); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	// Only apply default for donation case if it was actually sent but empty
-	if commitmentSats <= 0 && body.CommitmentTarget == "donation" && body.CommitmentSats == 0 {
-		commitmentSats = scstore.DefaultBudgetSats()
-		log.Printf("DEBUG: Setting commitmentSats=%d (default)", commitmentSats)
-	}
-	log.Printf("DEBUG: Final commitmentSats=%d", commitmentSats)
 
 	fundingMode, fundingAddress := s.resolveFundingMode(r.Context(), contractID)
 	primaryPayer := payerAddr
@@ -819,7 +896,7 @@ func (s *Server) handleContractPSBT(w http.ResponseWriter, r *http.Request, cont
 		// final delivered product image rather than the original wish image.
 		commitmentSats = 0
 		commitmentLockAddr = primaryPayer
-		log.Printf("DEBUG: commitment_target=product, deferring commitment to delivery (commitmentSats=0)")
+		logging.Debugf("commitment_target=product, deferring commitment to delivery (commitmentSats=0)")
 	default:
 		Error(w, http.StatusBadRequest, "invalid commitment_target")
 		return
@@ -934,7 +1011,7 @@ func (s *Server) handleContractPSBT(w http.ResponseWriter, r *http.Request, cont
 			}
 			if len(raiseFundTasksByWallet[wallet]) > 0 {
 				for _, taskID := range raiseFundTasksByWallet[wallet] {
-					if err := s.updateTaskCommitmentProof(r.Context(), taskID, splitRes, pixelBytes, commitmentTarget); err != nil {
+					if err := s.updateTaskCommitmentProof(r.Context(), taskID, splitRes, pixelBytes, commitmentTarget, wallet); err != nil {
 						log.Printf("psbt: failed to update task proof for %s: %v", taskID, err)
 					}
 				}
@@ -1095,12 +1172,12 @@ func (s *Server) handleContractPSBT(w http.ResponseWriter, r *http.Request, cont
 		}()
 	}
 	if taskID := strings.TrimSpace(body.TaskID); taskID != "" {
-		if err := s.updateTaskCommitmentProof(r.Context(), taskID, res, pixelBytes, commitmentTarget); err != nil {
+		if err := s.updateTaskCommitmentProof(r.Context(), taskID, res, pixelBytes, commitmentTarget, payerRec.Wallet); err != nil {
 			log.Printf("psbt: failed to update task proof for %s: %v", taskID, err)
 		}
 	} else if isRaiseFund(fundingMode) && len(raiseFundTaskIDs) > 0 {
 		for _, taskID := range raiseFundTaskIDs {
-			if err := s.updateTaskCommitmentProof(r.Context(), taskID, res, pixelBytes, commitmentTarget); err != nil {
+			if err := s.updateTaskCommitmentProof(r.Context(), taskID, res, pixelBytes, commitmentTarget, payerRec.Wallet); err != nil {
 				log.Printf("psbt: failed to update task proof for %s: %v", taskID, err)
 			}
 		}
@@ -1222,7 +1299,7 @@ func (s *Server) publishPendingStegoIngest(ctx context.Context, proposalID, visi
 	if meta == nil {
 		return
 	}
-	stegoCID := strings.TrimSpace(toString(meta["stego_image_cid"]))
+	stegoCID := strings.TrimSpace(coerce.String(meta["stego_image_cid"]))
 	if stegoCID == "" {
 		return
 	}
@@ -1231,7 +1308,7 @@ func (s *Server) publishPendingStegoIngest(ctx context.Context, proposalID, visi
 		visible = strings.TrimSpace(p.VisiblePixelHash)
 	}
 	if visible == "" {
-		visible = strings.TrimSpace(toString(meta["visible_pixel_hash"]))
+		visible = strings.TrimSpace(coerce.String(meta["visible_pixel_hash"]))
 	}
 	if visible == "" {
 		return
@@ -1282,15 +1359,15 @@ func (s *Server) publishPendingStegoIngest(ctx context.Context, proposalID, visi
 		Filename:         "stego.png",
 		Method:           getStegoMethodFromFilename("stego.png"), // Use appropriate method based on image format
 		Message:          message,
-		Price:            strings.TrimSpace(toString(meta["price"])),
-		PriceUnit:        strings.TrimSpace(toString(meta["price_unit"])),
-		Address:          strings.TrimSpace(toString(meta["funding_address"])),
-		FundingMode:      strings.TrimSpace(toString(meta["funding_mode"])),
+		Price:            strings.TrimSpace(coerce.String(meta["price"])),
+		PriceUnit:        strings.TrimSpace(coerce.String(meta["price_unit"])),
+		Address:          strings.TrimSpace(coerce.String(meta["funding_address"])),
+		FundingMode:      strings.TrimSpace(coerce.String(meta["funding_mode"])),
 		Timestamp:        time.Now().Unix(),
 		ProposalTitle:    strings.TrimSpace(p.Title),
 		ProposalDesc:     strings.TrimSpace(p.DescriptionMD),
 		BudgetSats:       p.BudgetSats,
-		PayloadCID:       strings.TrimSpace(toString(meta["stego_payload_cid"])),
+		PayloadCID:       strings.TrimSpace(coerce.String(meta["stego_payload_cid"])),
 	}
 	// Include structured task data so peers can create proper proposals
 	// without depending on IPFS payload fetch
@@ -1336,7 +1413,7 @@ func (s *Server) resolveFundingMode(ctx context.Context, contractID string) (str
 			meta = rec.Metadata
 		}
 	}
-	mode := strings.ToLower(strings.TrimSpace(toString(meta["funding_mode"])))
+	mode := strings.ToLower(strings.TrimSpace(coerce.String(meta["funding_mode"])))
 	if mode == "" && proposal != nil {
 		if looksLikeRaiseFund(proposal.Title) || looksLikeRaiseFund(proposal.DescriptionMD) {
 			mode = "raise_fund"
@@ -1393,13 +1470,13 @@ func (s *Server) resolveProposalIDForContract(ctx context.Context, contractID st
 		}
 	}
 	if rec != nil && rec.Metadata != nil {
-		if id := strings.TrimSpace(toString(rec.Metadata["origin_proposal_id"])); id != "" {
+		if id := strings.TrimSpace(coerce.String(rec.Metadata["origin_proposal_id"])); id != "" {
 			return id
 		}
-		if id := strings.TrimSpace(toString(rec.Metadata["stego_manifest_proposal_id"])); id != "" {
+		if id := strings.TrimSpace(coerce.String(rec.Metadata["stego_manifest_proposal_id"])); id != "" {
 			return id
 		}
-		if id := strings.TrimSpace(toString(rec.Metadata["proposal_id"])); id != "" {
+		if id := strings.TrimSpace(coerce.String(rec.Metadata["proposal_id"])); id != "" {
 			return id
 		}
 	}
@@ -1419,12 +1496,12 @@ func (s *Server) ingestionFromProposalMeta(meta map[string]interface{}, visibleP
 	if s.ingestionSvc == nil {
 		return nil
 	}
-	ingestionID := strings.TrimSpace(toString(meta["ingestion_id"]))
+	ingestionID := strings.TrimSpace(coerce.String(meta["ingestion_id"]))
 	if ingestionID == "" {
 		ingestionID = strings.TrimSpace(visiblePixelHash)
 	}
 	if ingestionID == "" {
-		ingestionID = strings.TrimSpace(toString(meta["visible_pixel_hash"]))
+		ingestionID = strings.TrimSpace(coerce.String(meta["visible_pixel_hash"]))
 	}
 	if ingestionID == "" {
 		return nil
@@ -1456,29 +1533,15 @@ func fundingAddressFromMeta(meta map[string]interface{}) string {
 	if meta == nil {
 		return ""
 	}
-	if v := strings.TrimSpace(toString(meta["funding_address"])); v != "" {
+	if v := strings.TrimSpace(coerce.String(meta["funding_address"])); v != "" {
 		return v
 	}
-	if v := strings.TrimSpace(toString(meta["address"])); v != "" {
+	if v := strings.TrimSpace(coerce.String(meta["address"])); v != "" {
 		return v
 	}
 	return ""
 }
 
-func toString(value interface{}) string {
-	if value == nil {
-		return ""
-	}
-	switch v := value.(type) {
-	case string:
-		return v
-	case []byte:
-		return string(v)
-	default:
-		return fmt.Sprintf("%v", v)
-	}
-}
-
 func addressSlice(addrs []btcutil.Address) []string {
 	out := make([]string, 0, len(addrs))
 	for _, addr := range addrs {
@@ -1547,15 +1610,25 @@ func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		if path == "" {
+			inactiveSince, byActivity, err := activityQuery(r)
+			if err != nil {
+				Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
 			filter := smart_contract.TaskFilter{
-				Skills:        splitCSV(r.URL.Query().Get("skills")),
-				MaxDifficulty: r.URL.Query().Get("max_difficulty"),
-				Status:        r.URL.Query().Get("status"),
-				Limit:         intFromQuery(r, "limit", 50),
-				Offset:        intFromQuery(r, "offset", 0),
-				MinBudgetSats: int64FromQuery(r, "min_budget_sats", 0),
-				ContractID:    r.URL.Query().Get("contract_id"),
-				ClaimedBy:     r.URL.Query().Get("claimed_by"),
+				Skills:              splitCSV(r.URL.Query().Get("skills")),
+				MaxDifficulty:       r.URL.Query().Get("max_difficulty"),
+				Status:              r.URL.Query().Get("status"),
+				Limit:               intFromQuery(r, "limit", 50),
+				Offset:              intFromQuery(r, "offset", 0),
+				MinBudgetSats:       int64FromQuery(r, "min_budget_sats", 0),
+				ContractID:          r.URL.Query().Get("contract_id"),
+				ClaimedBy:           r.URL.Query().Get("claimed_by"),
+				InactiveSince:       inactiveSince,
+				OrderByLastActivity: byActivity,
+			}
+			if filter.ContractID != "" && !s.requireContract(w, filter.ContractID) {
+				return
 			}
 			tasks, err := s.store.ListTasks(filter)
 			if err != nil {
@@ -1590,6 +1663,16 @@ func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if len(parts) > 1 && parts[1] == "commitment" {
+			task, err := s.store.GetTask(taskID)
+			if err != nil {
+				Error(w, http.StatusNotFound, err.Error())
+				return
+			}
+			JSON(w, http.StatusOK, smart_contract.TaskCommitmentStatus(task))
+			return
+		}
+
 		if len(parts) > 1 && parts[1] == "status" {
 			status, err := s.store.TaskStatus(taskID)
 			if err != nil {
@@ -1616,6 +1699,12 @@ func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
 		switch parts[1] {
 		case "claim":
 			s.handleClaimTask(w, r, taskID)
+		case "commitment":
+			if len(parts) < 3 || parts[2] != "reset" {
+				Error(w, http.StatusNotFound, "expected /tasks/{task_id}/commitment/reset")
+				return
+			}
+			s.handleResetTaskCommitment(w, r, taskID)
 		default:
 			Error(w, http.StatusNotFound, "unknown task action")
 		}
@@ -1651,13 +1740,15 @@ func resolvePixelHashFromIngestion(rec *services.IngestionRecord, normalize func
 	if rec.ImageBase64 == "" {
 		return nil
 	}
-	imageBytes, err := base64.StdEncoding.DecodeString(rec.ImageBase64)
+	visible, err := hashBase64(rec.ImageBase64)
 	if err != nil {
 		return nil
 	}
-
-	sum := sha256.Sum256(imageBytes)
-	return normalize(sum[:])
+	sum, err := hex.DecodeString(visible)
+	if err != nil {
+		return nil
+	}
+	return normalize(sum)
 }
 
 func pixelSourceForBytes(pixel []byte) string {
@@ -1720,7 +1811,7 @@ func buildScriptHashes(scripts [][]byte) ([]string, []string) {
 	return shaHashes, hash160s
 }
 
-func (s *Server) updateTaskCommitmentProof(ctx context.Context, taskID string, res *bitcoin.PSBTResult, pixelBytes []byte, commitmentTarget string) error {
+func (s *Server) updateTaskCommitmentProof(ctx context.Context, taskID string, res *bitcoin.PSBTResult, pixelBytes []byte, commitmentTarget, payerWallet string) error {
 	task, err := s.store.GetTask(taskID)
 	if err != nil {
 		return err
@@ -1735,6 +1826,9 @@ func (s *Server) updateTaskCommitmentProof(ctx context.Context, taskID string, r
 	if res.FundingTxID != "" {
 		proof.TxID = res.FundingTxID
 	}
+	if wallet := strings.TrimSpace(payerWallet); wallet != "" {
+		proof.PayerWallet = wallet
+	}
 	if proof.ConfirmationStatus == "" {
 		proof.ConfirmationStatus = "provisional"
 	}
@@ -1769,6 +1863,92 @@ func (s *Server) updateTaskCommitmentProof(ctx context.Context, taskID string, r
 	return s.store.UpdateTaskProof(ctx, taskID, proof)
 }
 
+// handleResetTaskCommitment clears a task's funding commitment when the PSBT
+// that set it was never broadcast, so a new funding PSBT can be built. Only the
+// wallet that built the funding PSBT or the proposal owner may reset it, and a
+// commitment whose funding tx is confirmed or known to the mempool is kept.
+func (s *Server) handleResetTaskCommitment(w http.ResponseWriter, r *http.Request, taskID string) {
+	task, err := s.store.GetTask(taskID)
+	if err != nil {
+		Error(w, http.StatusNotFound, err.Error())
+		return
+	}
+	var requester string
+	if s.apiKeys != nil {
+		if rec, ok := s.apiKeys.Get(r.Header.Get("X-API-Key")); ok {
+			requester = strings.TrimSpace(rec.Wallet)
+		}
+	}
+	if requester == "" {
+		Error(w, http.StatusForbidden, "api key with wallet binding required")
+		return
+	}
+	proof := task.MerkleProof
+	payer := ""
+	if proof != nil {
+		payer = proof.PayerWallet
+	}
+	if !strings.EqualFold(payer, requester) && !strings.EqualFold(s.proposalOwnerWallet(r.Context(), task.ContractID), requester) {
+		Error(w, http.StatusForbidden, "only the payer or the proposal owner can reset this commitment")
+		return
+	}
+	if proof == nil {
+		JSON(w, http.StatusOK, smart_contract.TaskCommitmentStatus(task))
+		return
+	}
+	if proof.ConfirmationStatus == smart_contract.CommitmentStatusConfirmed {
+		Error(w, http.StatusConflict, "commitment is confirmed on-chain and cannot be reset")
+		return
+	}
+	if txID := strings.TrimSpace(proof.TxID); txID != "" {
+		if s.mempool == nil {
+			Error(w, http.StatusServiceUnavailable, "cannot check whether the funding tx was broadcast")
+			return
+		}
+		seen, err := s.mempool.TxSeen(txID)
+		if err != nil {
+			Error(w, http.StatusServiceUnavailable, fmt.Sprintf("cannot check whether the funding tx was broadcast: %v", err))
+			return
+		}
+		if seen {
+			Error(w, http.StatusConflict, "funding tx has been broadcast and cannot be reset")
+			return
+		}
+	}
+
+	previousTxID := proof.TxID
+	cleared := *proof
+	smart_contract.ClearCommitment(&cleared)
+	if err := s.store.UpdateTaskProof(r.Context(), taskID, &cleared); err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	task.MerkleProof = &cleared
+	JSON(w, http.StatusOK, smart_contract.TaskCommitmentStatus(task))
+
+	s.recordEvent(smart_contract.Event{
+		Type:      "task_commitment_reset",
+		EntityID:  taskID,
+		Actor:     requester,
+		Message:   fmt.Sprintf("commitment reset (abandoned funding tx %s)", previousTxID),
+		CreatedAt: time.Now(),
+	})
+}
+
+// proposalOwnerWallet returns the creator_wallet recorded on the proposal
+// behind contractID, or "" when there is none.
+func (s *Server) proposalOwnerWallet(ctx context.Context, contractID string) string {
+	proposalID := s.resolveProposalIDForContract(ctx, contractID, nil)
+	if proposalID == "" {
+		return ""
+	}
+	proposal, err := s.store.GetProposal(ctx, proposalID)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(coerce.String(proposal.Metadata["creator_wallet"]))
+}
+
 func (s *Server) handleCommitmentPSBT(w http.ResponseWriter, r *http.Request, contractID string) {
 	if r.Header.Get("Content-Type") != "" && !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
 		Error(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
@@ -1785,8 +1965,8 @@ func (s *Server) handleCommitmentPSBT(w http.ResponseWriter, r *http.Request, co
 		FeeRate            int64  `json:"fee_rate_sats_vb"`
 		Preimage           string `json:"preimage"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		Error(w, http.StatusBadRequest, "invalid json")
+	if err := decodeStrictJSON(r.Body, &body); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -1890,6 +2070,9 @@ func (s *Server) handlePaymentDetails(w http.ResponseWriter, r *http.Request, co
 	}
 
 	ctx := r.Context()
+	if !s.requireContract(w, contractID) {
+		return
+	}
 
 	// Get contract tasks to calculate payment details
 	tasks, err := s.store.ListTasks(smart_contract.TaskFilter{ContractID: contractID})
@@ -2006,16 +2189,7 @@ func contractIDFromMeta(meta map[string]interface{}, fallback string) string {
 }
 
 func networkParamsFromEnv() *chaincfg.Params {
-	switch bitcoin.GetCurrentNetwork() {
-	case "mainnet":
-		return &chaincfg.MainNetParams
-	case "signet":
-		return &chaincfg.SigNetParams
-	case "testnet":
-		return &chaincfg.TestNet3Params
-	default:
-		return &chaincfg.TestNet4Params
-	}
+	return bitcoin.NetworkParams(bitcoin.GetCurrentNetwork())
 }
 
 func (s *Server) handleClaimTask(w http.ResponseWriter, r *http.Request, taskID string) {
@@ -2026,10 +2200,16 @@ func (s *Server) handleClaimTask(w http.ResponseWriter, r *http.Request, taskID
 	var body struct {
 		EstimatedCompletion *time.Time `json:"estimated_completion,omitempty"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		Error(w, http.StatusBadRequest, "invalid json")
+	if err := decodeStrictJSON(r.Body, &body); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	eta, err := normalizeEstimatedCompletion(body.EstimatedCompletion, time.Now(), s.claimETAHorizon)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	body.EstimatedCompletion = eta
 
 	if task, err := s.store.GetTask(taskID); err == nil {
 		if strings.TrimSpace(task.ContractID) != "" {
@@ -2059,7 +2239,7 @@ func (s *Server) handleClaimTask(w http.ResponseWriter, r *http.Request, taskID
 		}
 	}
 	if walletAddress == "" {
-		Error(w, http.StatusBadRequest, "wallet address required - please bind wallet to API key using /api/auth/verify")
+		Error(w, http.StatusBadRequest, "wallet address required - bind a wallet to your API key with POST /api/auth/wallet (or /api/auth/verify)")
 		return
 	}
 
@@ -2083,7 +2263,7 @@ func (s *Server) handleClaimTask(w http.ResponseWriter, r *http.Request, taskID
 				return
 			}
 		}
-		if err == ErrTaskTaken || err == ErrTaskUnavailable || err.Error() == ErrTaskUnavailable.Error() {
+		if err == ErrTaskTaken || err == ErrTaskUnavailable || err == ErrTaskClosed || err.Error() == ErrTaskUnavailable.Error() {
 			Error(w, http.StatusConflict, err.Error())
 			return
 		}
@@ -2117,7 +2297,25 @@ func (s *Server) handleClaims(w http.ResponseWriter, r *http.Request) {
 	}
 	claimID := parts[0]
 
-	if len(parts) < 2 || parts[1] != "submit" {
+	if len(parts) == 1 {
+		if r.Method != http.MethodGet {
+			Error(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		details, err := scstore.LookupClaim(r.Context(), s.store, claimID, time.Now())
+		if err != nil {
+			if err == ErrClaimNotFound {
+				Error(w, http.StatusNotFound, err.Error())
+				return
+			}
+			Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		JSON(w, http.StatusOK, details)
+		return
+	}
+
+	if parts[1] != "submit" {
 		Error(w, http.StatusNotFound, "unknown claim action")
 		return
 	}
@@ -2132,12 +2330,33 @@ func (s *Server) handleClaims(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only the wallet that claimed the task may submit under its claim.
+	if s.apiKeys != nil {
+		claim, err := s.store.GetClaim(claimID)
+		if err != nil {
+			if err == ErrClaimNotFound {
+				Error(w, http.StatusNotFound, err.Error())
+				return
+			}
+			Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		callerWallet := ""
+		if rec, ok := s.apiKeys.Get(r.Header.Get("X-API-Key")); ok {
+			callerWallet = strings.TrimSpace(rec.Wallet)
+		}
+		if !scstore.ClaimHeldBy(claim, callerWallet) {
+			Error(w, http.StatusForbidden, "claim is held by a different wallet")
+			return
+		}
+	}
+
 	var body struct {
 		Deliverables    map[string]interface{} `json:"deliverables"`
 		CompletionProof map[string]interface{} `json:"completion_proof"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		Error(w, http.StatusBadRequest, "invalid json")
+	if err := decodeStrictJSON(r.Body, &body); err != nil {
+		Error(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -2173,11 +2392,25 @@ func (s *Server) handleSkills(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tasks, err := s.store.ListTasks(smart_contract.TaskFilter{})
+	skills, err := s.skillCatalog()
 	if err != nil {
 		Error(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+
+	JSON(w, http.StatusOK, map[string]interface{}{
+		"skills": skills,
+		"count":  len(skills),
+	})
+}
+
+// skillCatalog lists the lower-cased skills required by any task, plus the
+// built-in defaults, sorted so responses are stable across calls.
+func (s *Server) skillCatalog() ([]string, error) {
+	tasks, err := s.store.ListTasks(smart_contract.TaskFilter{})
+	if err != nil {
+		return nil, err
+	}
 	skillSet := make(map[string]struct{})
 	// Add default skills
 	skillSet["contract_bidding"] = struct{}{}
@@ -2196,11 +2429,20 @@ func (s *Server) handleSkills(w http.ResponseWriter, r *http.Request) {
 	for k := range skillSet {
 		skills = append(skills, k)
 	}
+	sort.Strings(skills)
+	return skills, nil
+}
 
-	JSON(w, http.StatusOK, map[string]interface{}{
-		"skills": skills,
-		"count":  len(skills),
-	})
+// discoverTools is the MCP tool surface advertised by discover and onboarding.
+var discoverTools = []string{
+	"list_contracts", "get_contract", "get_contract_funding", "get_open_contracts",
+	"get_contract_rework_requests", "create_contract_rework_request",
+	"list_tasks", "get_task", "get_claim", "claim_task", "submit_work", "get_task_proof", "get_task_status", "get_task_commitment",
+	"list_skills",
+	"list_proposals", "get_proposal", "create_proposal", "approve_proposal", "publish_proposal",
+	"list_submissions", "get_submission", "review_submission", "rework_submission",
+	"list_events",
+	"scan_image", "scan_transaction", "scan_block", "scan_block_range", "get_block_inscriptions", "extract_message", "get_scanner_info",
 }
 
 // handleDiscover advertises API endpoints and MCP tool surface for clients.
@@ -2225,28 +2467,20 @@ func (s *Server) handleDiscover(w http.ResponseWriter, r *http.Request) {
 			"/api/smart_contract/events",
 			"/api/open-contracts",
 		},
-		"tools": []string{
-			"list_contracts", "get_contract", "get_contract_funding", "get_open_contracts",
-			"get_contract_rework_requests", "create_contract_rework_request",
-			"list_tasks", "get_task", "claim_task", "submit_work", "get_task_proof", "get_task_status",
-			"list_skills",
-			"list_proposals", "get_proposal", "create_proposal", "approve_proposal", "publish_proposal",
-			"list_submissions", "get_submission", "review_submission", "rework_submission",
-			"list_events",
-			"scan_image", "scan_transaction", "scan_block", "extract_message", "get_scanner_info",
-		},
+		"tools": discoverTools,
 		"authentication": map[string]string{
 			"type":        "api_key",
 			"header_name": "X-API-Key",
 			"required":    fmt.Sprintf("%t", s.apiKeys != nil),
 		},
 		"rate_limits": map[string]interface{}{
-			"enabled":       false,
-			"notes":         "rate limiting planned; not enforced by default",
-			"recommended":   "10 rps claim, 5 rps submit (see roadmap)",
-			"burst_example": 100,
+			"enabled": false,
+			"notes":   "no MCP server attached; tool calls are not rate limited",
 		},
 	}
+	if s.rateLimits != nil {
+		resp["rate_limits"] = s.rateLimits
+	}
 	JSON(w, http.StatusOK, resp)
 }
 
@@ -2420,7 +2654,7 @@ func proposalMetaConfirmed(meta map[string]interface{}) bool {
 	if status, ok := meta["confirmation_status"].(string); ok && strings.EqualFold(strings.TrimSpace(status), "confirmed") {
 		return true
 	}
-	if height, ok := meta["confirmed_height"].(float64); ok && height > 0 {
+	if height, ok := coerce.Int64(meta["confirmed_height"]); ok && height > 0 {
 		return true
 	}
 	return false
@@ -2474,11 +2708,11 @@ func (s *Server) mergeSandboxMetadata(ctx context.Context, c *smart_contract.Con
 			meta = map[string]interface{}{}
 		}
 		changed := false
-		if h := strings.TrimSpace(sandboxHash); h != "" && strings.TrimSpace(toString(meta["sandbox_hash"])) == "" {
+		if h := strings.TrimSpace(sandboxHash); h != "" && strings.TrimSpace(coerce.String(meta["sandbox_hash"])) == "" {
 			meta["sandbox_hash"] = h
 			changed = true
 		}
-		if cid := strings.TrimSpace(sandboxCID); cid != "" && strings.TrimSpace(toString(meta["sandbox_tarball_cid"])) == "" {
+		if cid := strings.TrimSpace(sandboxCID); cid != "" && strings.TrimSpace(coerce.String(meta["sandbox_tarball_cid"])) == "" {
 			meta["sandbox_tarball_cid"] = cid
 			changed = true
 		}
@@ -2497,11 +2731,11 @@ func (s *Server) mergeSandboxMetadata(ctx context.Context, c *smart_contract.Con
 				pmeta = map[string]interface{}{}
 			}
 			changed := false
-			if h := strings.TrimSpace(sandboxHash); h != "" && strings.TrimSpace(toString(pmeta["sandbox_hash"])) == "" {
+			if h := strings.TrimSpace(sandboxHash); h != "" && strings.TrimSpace(coerce.String(pmeta["sandbox_hash"])) == "" {
 				pmeta["sandbox_hash"] = h
 				changed = true
 			}
-			if cid := strings.TrimSpace(sandboxCID); cid != "" && strings.TrimSpace(toString(pmeta["sandbox_tarball_cid"])) == "" {
+			if cid := strings.TrimSpace(sandboxCID); cid != "" && strings.TrimSpace(coerce.String(pmeta["sandbox_tarball_cid"])) == "" {
 				pmeta["sandbox_tarball_cid"] = cid
 				changed = true
 			}
@@ -2538,7 +2772,7 @@ func (s *Server) publishSyncEvent(evt smart_contract.Event) {
 			// For claim events, log error but still publish to maintain sync flow
 			// The receiving instance will retry getting task data during reconciliation
 			if evt.Type == "claim" {
-				log.Printf("WARNING: Failed to get task %s for claim sync: %v", evt.EntityID, err)
+				logging.Warnf("Failed to get task %s for claim sync: %v", evt.EntityID, err)
 			}
 		}
 	case "contract_confirmed":
@@ -2737,10 +2971,7 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 		}
 		flusher.Flush()
 
-		ch := make(chan smart_contract.Event, 10)
-		s.listenersMu.Lock()
-		s.listeners = append(s.listeners, ch)
-		s.listenersMu.Unlock()
+		ch := s.addListener()
 
 		notify := r.Context().Done()
 		for {
@@ -2748,7 +2979,11 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 			case <-notify:
 				s.removeListener(ch)
 				return
-			case evt := <-ch:
+			case evt, ok := <-ch:
+				if !ok {
+					// Closed by the admin GC, the disconnect policy or Shutdown.
+					return
+				}
 				if !eventMatches(evt, filterType, filterActor, filterEntity) {
 					continue
 				}
@@ -2783,19 +3018,6 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// broadcastEvent pushes an event to connected listeners without blocking.
-func (s *Server) broadcastEvent(evt smart_contract.Event) {
-	s.listenersMu.Lock()
-	defer s.listenersMu.Unlock()
-	for _, ch := range s.listeners {
-		select {
-		case ch <- evt:
-		default:
-			// drop if slow consumer
-		}
-	}
-}
-
 // tryPublishTasksForTaskID attempts to find a proposal that contains the given taskID and publish its tasks.
 func (s *Server) tryPublishTasksForTaskID(ctx context.Context, taskID string) error {
 	proposals, err := s.store.ListProposals(ctx, smart_contract.ProposalFilter{})
@@ -2818,6 +3040,7 @@ func (s *Server) removeListener(ch chan smart_contract.Event) {
 	for i, c := range s.listeners {
 		if c == ch {
 			close(c)
+			delete(s.listenerStalledSince, c)
 			s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
 			break
 		}
@@ -2852,7 +3075,35 @@ func (s *Server) PublishProposalTasks(ctx context.Context, proposalID string) er
 			return nil
 		}
 	}
-	// Build a contract from the proposal, then upsert tasks.
+	contract, tasks := proposalContractTasks(p)
+	if pg, ok := s.store.(interface {
+		UpsertContractWithTasks(context.Context, smart_contract.Contract, []smart_contract.Task) error
+	}); ok {
+		if err := pg.UpsertContractWithTasks(ctx, contract, tasks); err != nil {
+			return err
+		}
+		s.recordEvent(smart_contract.Event{
+			Type:      "contract_upsert",
+			EntityID:  contract.ContractID,
+			Actor:     "system",
+			Message:   fmt.Sprintf("contract upserted with %d tasks", len(tasks)),
+			CreatedAt: time.Now(),
+		})
+		s.recordEvent(smart_contract.Event{
+			Type:      "publish",
+			EntityID:  proposalID,
+			Actor:     "system",
+			Message:   "proposal tasks published",
+			CreatedAt: time.Now(),
+		})
+		return nil
+	}
+	return nil
+}
+
+// proposalContractTasks builds the active contract and claimable tasks that
+// publishing an approved proposal upserts. p.Tasks must be populated.
+func proposalContractTasks(p smart_contract.Proposal) (smart_contract.Contract, []smart_contract.Task) {
 	contractID := contractIDFromMeta(p.Metadata, p.ID)
 	contract := smart_contract.Contract{
 		ContractID:          contractID,
@@ -2862,13 +3113,16 @@ func (s *Server) PublishProposalTasks(ctx context.Context, proposalID string) er
 		AvailableTasksCount: len(p.Tasks),
 		Status:              "active",
 	}
+	if ttl, ok := p.Metadata[scstore.ClaimTTLMetadataKey]; ok {
+		contract.Metadata = map[string]interface{}{scstore.ClaimTTLMetadataKey: ttl}
+	}
 	// Preserve hashes/funding if present.
 	fundingAddr := scstore.FundingAddressFromMeta(p.Metadata)
 	tasks := make([]smart_contract.Task, 0, len(p.Tasks))
 	for i, t := range p.Tasks {
 		task := t
 		if strings.TrimSpace(task.TaskID) == "" {
-			task.TaskID = proposalID + "-task-" + strconv.Itoa(i+1)
+			task.TaskID = p.ID + "-task-" + strconv.Itoa(i+1)
 		}
 		if task.ContractID == "" || task.ContractID == p.ID {
 			task.ContractID = contractID
@@ -2886,29 +3140,7 @@ func (s *Server) PublishProposalTasks(ctx context.Context, proposalID string) er
 		}
 		tasks = append(tasks, task)
 	}
-	if pg, ok := s.store.(interface {
-		UpsertContractWithTasks(context.Context, smart_contract.Contract, []smart_contract.Task) error
-	}); ok {
-		if err := pg.UpsertContractWithTasks(ctx, contract, tasks); err != nil {
-			return err
-		}
-		s.recordEvent(smart_contract.Event{
-			Type:      "contract_upsert",
-			EntityID:  contract.ContractID,
-			Actor:     "system",
-			Message:   fmt.Sprintf("contract upserted with %d tasks", len(tasks)),
-			CreatedAt: time.Now(),
-		})
-		s.recordEvent(smart_contract.Event{
-			Type:      "publish",
-			EntityID:  proposalID,
-			Actor:     "system",
-			Message:   "proposal tasks published",
-			CreatedAt: time.Now(),
-		})
-		return nil
-	}
-	return nil
+	return contract, tasks
 }
 
 // handleProposals supports listing, getting, and approving proposals.
@@ -2919,6 +3151,10 @@ func (s *Server) handleProposals(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
 		// POST /mcp/v1/proposals/{id}/approve is handled separately.
+		if path == "batch" {
+			s.handleProposalBatch(w, r)
+			return
+		}
 		parts := strings.Split(path, "/")
 		if len(parts) == 2 && parts[1] == "approve" {
 			id := parts[0]
@@ -2945,14 +3181,14 @@ func (s *Server) handleProposals(w http.ResponseWriter, r *http.Request) {
 			if meta == nil {
 				meta = map[string]interface{}{}
 			}
-			fundingMode := strings.ToLower(strings.TrimSpace(toString(meta["funding_mode"])))
+			fundingMode := strings.ToLower(strings.TrimSpace(coerce.String(meta["funding_mode"])))
 			if fundingMode == "" && (looksLikeRaiseFund(proposal.Title) || looksLikeRaiseFund(proposal.DescriptionMD)) {
 				fundingMode = "raise_fund"
 				meta["funding_mode"] = fundingMode
 			}
 			if isRaiseFund(fundingMode) {
-				payoutAddr := strings.TrimSpace(toString(meta["payout_address"]))
-				fundingAddr := strings.TrimSpace(toString(meta["funding_address"]))
+				payoutAddr := strings.TrimSpace(coerce.String(meta["payout_address"]))
+				fundingAddr := strings.TrimSpace(coerce.String(meta["funding_address"]))
 				if payoutAddr == "" || fundingAddr == "" {
 					if s.apiKeys == nil {
 						Error(w, http.StatusBadRequest, "missing payout address; API key wallet binding required for fundraiser")
@@ -2984,7 +3220,7 @@ func (s *Server) handleProposals(w http.ResponseWriter, r *http.Request) {
 					}
 					visible := strings.TrimSpace(proposal.VisiblePixelHash)
 					if visible == "" {
-						visible = strings.TrimSpace(toString(proposal.Metadata["visible_pixel_hash"]))
+						visible = strings.TrimSpace(coerce.String(proposal.Metadata["visible_pixel_hash"]))
 					}
 					proposal.Tasks = scstore.BuildTasksFromMarkdown(proposal.ID, desc, visible, proposal.BudgetSats, scstore.FundingAddressFromMeta(proposal.Metadata))
 					if err := s.store.UpdateProposal(r.Context(), proposal); err != nil {
@@ -3003,7 +3239,7 @@ func (s *Server) handleProposals(w http.ResponseWriter, r *http.Request) {
 			}
 			visibleHash := strings.TrimSpace(proposal.VisiblePixelHash)
 			if visibleHash == "" {
-				visibleHash = strings.TrimSpace(toString(proposal.Metadata["visible_pixel_hash"]))
+				visibleHash = strings.TrimSpace(coerce.String(proposal.Metadata["visible_pixel_hash"]))
 			}
 			if visibleHash != "" {
 				s.archiveWishContract(r.Context(), visibleHash)
@@ -3059,47 +3295,17 @@ func (s *Server) handleProposals(w http.ResponseWriter, r *http.Request) {
 		log.Printf("CRITICAL: HandleCreateProposal called at %s from %s, User-Agent: %s", time.Now().Format(time.RFC3339), r.RemoteAddr, r.Header.Get("User-Agent"))
 
 		var body ProposalCreateBody
-		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-			Error(w, http.StatusBadRequest, "invalid json")
+		if err := decodeStrictJSON(r.Body, &body); err != nil {
+			Error(w, http.StatusBadRequest, err.Error())
 			return
 		}
 		// If an ingestion_id is provided, pull message/token/budget from that pending record.
 		if body.IngestionID != "" && s.ingestionSvc != nil {
-			rec, err := s.ingestionSvc.Get(body.IngestionID)
-			if err != nil {
-				Error(w, http.StatusNotFound, "ingestion not found")
-				return
-			}
-			proposal, err := BuildProposalFromIngestion(body, rec)
+			proposal, status, err := s.createProposalFromIngestion(r, body)
 			if err != nil {
-				Error(w, http.StatusBadRequest, err.Error())
-				return
-			}
-			if err := s.requireWishForProposalCreation(r.Context(), proposal); err != nil {
-				Error(w, http.StatusBadRequest, err.Error())
-				return
-			}
-			metaContractID, _ := proposal.Metadata["contract_id"].(string)
-			metaVisiblePixelHash, _ := proposal.Metadata["visible_pixel_hash"].(string)
-			if strings.TrimSpace(metaContractID) == "" || strings.TrimSpace(metaVisiblePixelHash) == "" {
-				Error(w, http.StatusBadRequest, "contract_id and visible_pixel_hash are required for proposal creation so the UI can display it; set both to the same 64-char hash if needed")
-				return
-			}
-			applyCreatorWallet(proposal.Metadata, r.Header.Get("X-API-Key"), s.apiKeys)
-			if err := s.store.CreateProposal(r.Context(), proposal); err != nil {
-				Error(w, http.StatusBadRequest, err.Error())
+				Error(w, status, err.Error())
 				return
 			}
-			// Note: stego/IPFS replication is deferred until PSBT build (see PSBT handlers).
-			// This prevents remote nodes from ingesting an actionable contract before
-			// the payer has committed funding via PSBT (wish hash + product hash + payouts).
-			s.recordEvent(smart_contract.Event{
-				Type:      "proposal_create",
-				EntityID:  proposal.ID,
-				Actor:     "creator",
-				Message:   "proposal created from ingestion",
-				CreatedAt: time.Now(),
-			})
 			JSON(w, http.StatusCreated, map[string]interface{}{
 				"proposal_id": proposal.ID,
 				"status":      proposal.Status,
@@ -3129,33 +3335,16 @@ func (s *Server) handleProposals(w http.ResponseWriter, r *http.Request) {
 		if body.ContractID != "" {
 			body.Metadata["contract_id"] = body.ContractID
 		}
-		if strings.TrimSpace(body.VisiblePixelHash) != "" {
-			body.Metadata["visible_pixel_hash"] = body.VisiblePixelHash
-		}
-		contractID := strings.TrimSpace(body.ContractID)
-		if contractID == "" {
-			if v, ok := body.Metadata["contract_id"].(string); ok {
-				contractID = strings.TrimSpace(v)
-			}
-		}
-		visiblePixelHash := strings.TrimSpace(body.VisiblePixelHash)
-		if visiblePixelHash == "" {
-			if v, ok := body.Metadata["visible_pixel_hash"].(string); ok {
-				visiblePixelHash = strings.TrimSpace(v)
-			}
+		identity := smart_contract.Proposal{VisiblePixelHash: body.VisiblePixelHash, Metadata: body.Metadata}
+		if err := scstore.ApplyProposalIdentity(&identity); err != nil {
+			Error(w, http.StatusBadRequest, err.Error())
+			return
 		}
+		visiblePixelHash, _ := body.Metadata["visible_pixel_hash"].(string)
 		if visiblePixelHash == "" {
 			Error(w, http.StatusBadRequest, "visible_pixel_hash is required for proposal creation")
 			return
 		}
-		if contractID == "" {
-			contractID = visiblePixelHash
-			body.Metadata["contract_id"] = contractID
-		}
-		if contractID != visiblePixelHash {
-			Error(w, http.StatusBadRequest, "contract_id must match visible_pixel_hash for wish proposals")
-			return
-		}
 		wishID := "wish-" + visiblePixelHash
 		if _, err := s.store.GetContract(wishID); err != nil {
 			Error(w, http.StatusNotFound, "wish not found for visible_pixel_hash")
@@ -3212,8 +3401,8 @@ func (s *Server) handleProposals(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		var body ProposalUpdateBody
-		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-			Error(w, http.StatusBadRequest, "invalid json")
+		if err := decodeStrictJSON(r.Body, &body); err != nil {
+			Error(w, http.StatusBadRequest, err.Error())
 			return
 		}
 		id := parts[0]
@@ -3265,23 +3454,23 @@ func (s *Server) handleProposals(w http.ResponseWriter, r *http.Request) {
 			updated.Metadata["contract_id"] = strings.TrimSpace(*body.ContractID)
 			changed = true
 		}
-		if strings.TrimSpace(updated.VisiblePixelHash) != "" {
-			if vph, ok := updated.Metadata["visible_pixel_hash"].(string); !ok || strings.TrimSpace(vph) == "" {
-				updated.Metadata["visible_pixel_hash"] = updated.VisiblePixelHash
+		if body.VisiblePixelHash != nil {
+			// A new hash moves the proposal to another wish, so the stored
+			// identity follows it unless the request names one explicitly.
+			updated.Metadata["visible_pixel_hash"] = updated.VisiblePixelHash
+			explicit := body.ContractID != nil
+			if body.Metadata != nil {
+				_, inMeta := (*body.Metadata)["contract_id"]
+				explicit = explicit || inMeta
 			}
-		}
-		if metaContract, ok := updated.Metadata["contract_id"].(string); ok {
-			metaContract = strings.TrimSpace(metaContract)
-			if metaContract != "" {
-				if metaHash, ok2 := updated.Metadata["visible_pixel_hash"].(string); ok2 {
-					metaHash = strings.TrimSpace(metaHash)
-					if metaHash != "" && metaHash != metaContract {
-						Error(w, http.StatusBadRequest, "visible_pixel_hash must match contract_id when both are set")
-						return
-					}
-				}
+			if !explicit {
+				delete(updated.Metadata, "contract_id")
 			}
 		}
+		if err := scstore.ApplyProposalIdentity(&updated); err != nil {
+			Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
 
 		if body.Tasks != nil {
 			updated.Tasks = *body.Tasks
@@ -3328,20 +3517,28 @@ func (s *Server) handleProposals(w http.ResponseWriter, r *http.Request) {
 			limit := intFromQuery(r, "limit", 20)
 			offset := intFromQuery(r, "offset", 0)
 
-			// First get total count by fetching all matching proposals without limit
+			// Total matching proposals. Hiding confirmed ones needs each
+			// description, so only the unfiltered total comes from a count.
 			countFilter := smart_contract.ProposalFilter{
 				Status:     r.URL.Query().Get("status"),
 				Skills:     splitCSV(r.URL.Query().Get("skills")),
 				MinBudget:  minBudget,
 				ContractID: r.URL.Query().Get("contract_id"),
 			}
-			allProposals, err := s.store.ListProposals(r.Context(), countFilter)
-			if err != nil {
-				Error(w, http.StatusInternalServerError, err.Error())
-				return
-			}
-			if !includeConfirmed(r) {
-				filtered := make([]smart_contract.Proposal, 0, len(allProposals))
+			var total int
+			var err error
+			if includeConfirmed(r) {
+				total, err = s.store.CountProposals(r.Context(), countFilter)
+				if err != nil {
+					Error(w, http.StatusInternalServerError, err.Error())
+					return
+				}
+			} else {
+				allProposals, err := s.store.ListProposals(r.Context(), countFilter)
+				if err != nil {
+					Error(w, http.StatusInternalServerError, err.Error())
+					return
+				}
 				for _, p := range allProposals {
 					if looksLikeStegoManifestText(p.DescriptionMD) {
 						continue
@@ -3349,11 +3546,9 @@ func (s *Server) handleProposals(w http.ResponseWriter, r *http.Request) {
 					if strings.EqualFold(strings.TrimSpace(p.Status), "rejected") {
 						continue
 					}
-					filtered = append(filtered, p)
+					total++
 				}
-				allProposals = filtered
 			}
-			total := len(allProposals)
 
 			// Apply pagination
 			filter := smart_contract.ProposalFilter{
@@ -3448,7 +3643,50 @@ func getStegoMethodFromFilename(filename string) string {
 	return defaultMethod
 }
 
-// buildProposalFromIngestion derives a proposal from a pending ingestion record.
+// createProposalFromIngestion builds a proposal from the pending ingestion
+// record named by body.IngestionID and stores it. On failure it also returns
+// the HTTP status to report.
+func (s *Server) createProposalFromIngestion(r *http.Request, body ProposalCreateBody) (smart_contract.Proposal, int, error) {
+	rec, err := s.ingestionSvc.Get(body.IngestionID)
+	if err != nil {
+		return smart_contract.Proposal{}, http.StatusNotFound, fmt.Errorf("ingestion not found")
+	}
+	proposal, err := BuildProposalFromIngestion(body, rec)
+	if err != nil {
+		return smart_contract.Proposal{}, http.StatusBadRequest, err
+	}
+	if err := scstore.ApplyProposalIdentity(&proposal); err != nil {
+		return smart_contract.Proposal{}, http.StatusBadRequest, err
+	}
+	proposal.VisiblePixelHash, _ = proposal.Metadata["visible_pixel_hash"].(string)
+	if proposal.VisiblePixelHash == "" {
+		return smart_contract.Proposal{}, http.StatusBadRequest, fmt.Errorf("visible_pixel_hash is required for proposal creation; the ingestion has no image or stored hash")
+	}
+	if err := s.requireWishForProposalCreation(r.Context(), proposal); err != nil {
+		return smart_contract.Proposal{}, http.StatusBadRequest, err
+	}
+	applyCreatorWallet(proposal.Metadata, r.Header.Get("X-API-Key"), s.apiKeys)
+	if err := s.store.CreateProposal(r.Context(), proposal); err != nil {
+		return smart_contract.Proposal{}, http.StatusBadRequest, err
+	}
+	// Note: stego/IPFS replication is deferred until PSBT build (see PSBT handlers).
+	// This prevents remote nodes from ingesting an actionable contract before
+	// the payer has committed funding via PSBT (wish hash + product hash + payouts).
+	s.recordEvent(smart_contract.Event{
+		Type:      "proposal_create",
+		EntityID:  proposal.ID,
+		Actor:     "creator",
+		Message:   "proposal created from ingestion",
+		CreatedAt: time.Now(),
+	})
+	return proposal, http.StatusCreated, nil
+}
+
+// BuildProposalFromIngestion derives a proposal from a pending ingestion
+// record without storing it. The record's metadata is copied and tagged with
+// its ingestion ID; fields left empty in body (ID, title, description,
+// budget, visible pixel hash, status, tasks) are filled from the record and
+// its embedded message.
 func BuildProposalFromIngestion(body ProposalCreateBody, rec *services.IngestionRecord) (smart_contract.Proposal, error) {
 	meta := copyMeta(rec.Metadata)
 	if meta == nil {
@@ -3471,12 +3709,9 @@ func BuildProposalFromIngestion(body ProposalCreateBody, rec *services.Ingestion
 	}
 	title := body.Title
 	if strings.TrimSpace(title) == "" {
-		if em, _ := meta["embedded_message"].(string); em != "" {
-			title = strings.Fields(em)[0]
-			if title == "" {
-				title = "Proposal " + rec.ID
-			}
-		} else {
+		em, _ := meta["embedded_message"].(string)
+		title = titleFromMessage(em, titleStrategyFor(meta))
+		if title == "" {
 			title = "Proposal " + rec.ID
 		}
 	}
@@ -3568,13 +3803,20 @@ func (s *Server) handleSubmissions(w http.ResponseWriter, r *http.Request) {
 			contractID := r.URL.Query().Get("contract_id")
 			taskIDs := splitCSV(r.URL.Query().Get("task_ids"))
 			status := r.URL.Query().Get("status")
+			expand, err := parseSubmissionExpand(r.URL.Query().Get("expand"))
+			if err != nil {
+				Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
 
 			var submissions []smart_contract.Submission
-			var err error
 
 			if len(taskIDs) > 0 {
 				submissions, err = s.store.ListSubmissions(r.Context(), taskIDs)
 			} else if contractID != "" {
+				if !s.requireContract(w, contractID) {
+					return
+				}
 				// Get tasks for contract, then submissions for those tasks
 				tasks, err := s.store.ListTasks(smart_contract.TaskFilter{ContractID: contractID})
 				if err != nil {
@@ -3616,15 +3858,46 @@ func (s *Server) handleSubmissions(w http.ResponseWriter, r *http.Request) {
 				submissions = filtered
 			}
 
-			// Convert to map for easier frontend consumption
-			submissionMap := make(map[string]smart_contract.Submission)
-			for _, sub := range submissions {
-				submissionMap[sub.SubmissionID] = sub
+			// Oldest first, so offset/limit pages are stable. limit 0 (the
+			// default) returns every submission from offset.
+			smart_contract.SortSubmissions(submissions)
+			limit := intFromQuery(r, "limit", 0)
+			offset := intFromQuery(r, "offset", 0)
+			if offset < 0 {
+				offset = 0
+			}
+			page, hasMore := smart_contract.PageSubmissions(submissions, limit, offset)
+
+			var body interface{} = page
+			if expand.any() {
+				expanded, err := s.expandSubmissions(r.Context(), page, expand)
+				if err != nil {
+					Error(w, http.StatusInternalServerError, err.Error())
+					return
+				}
+				body = expanded
+				if r.URL.Query().Get("format") == "map" {
+					submissionMap := make(map[string]expandedSubmission, len(expanded))
+					for _, sub := range expanded {
+						submissionMap[sub.SubmissionID] = sub
+					}
+					body = submissionMap
+				}
+			} else if r.URL.Query().Get("format") == "map" {
+				// Legacy shape keyed by submission ID, for older clients.
+				submissionMap := make(map[string]smart_contract.Submission, len(page))
+				for _, sub := range page {
+					submissionMap[sub.SubmissionID] = sub
+				}
+				body = submissionMap
 			}
 
 			JSON(w, http.StatusOK, map[string]interface{}{
-				"submissions": submissionMap,
+				"submissions": body,
 				"total":       len(submissions),
+				"limit":       limit,
+				"offset":      offset,
+				"has_more":    hasMore,
 			})
 			return
 		}
@@ -3662,8 +3935,8 @@ func (s *Server) handleSubmissions(w http.ResponseWriter, r *http.Request) {
 			submissionID := parts[0]
 
 			var body submissionReviewBody
-			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-				Error(w, http.StatusBadRequest, "invalid json")
+			if err := decodeStrictJSON(r.Body, &body); err != nil {
+				Error(w, http.StatusBadRequest, err.Error())
 				return
 			}
 
@@ -3766,8 +4039,8 @@ func (s *Server) handleSubmissions(w http.ResponseWriter, r *http.Request) {
 			submissionID := parts[0]
 
 			var body submissionReworkBody
-			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-				Error(w, http.StatusBadRequest, "invalid json")
+			if err := decodeStrictJSON(r.Body, &body); err != nil {
+				Error(w, http.StatusBadRequest, err.Error())
 				return
 			}
 
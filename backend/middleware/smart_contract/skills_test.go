@@ -0,0 +1,62 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestSkillsAreSorted(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	if err := store.UpsertContractWithTasks(context.Background(), smart_contract.Contract{
+		ContractID: "skills-contract",
+		Title:      "Skills",
+		Status:     "active",
+	}, []smart_contract.Task{
+		{TaskID: "skills-task-1", ContractID: "skills-contract", Title: "One", Status: "available", Skills: []string{"Zig", "rust", "  Go "}},
+		{TaskID: "skills-task-2", ContractID: "skills-contract", Title: "Two", Status: "available", Skills: []string{"python", "ADA", "rust"}},
+	}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	const apiKey = "skills-key"
+	mux := http.NewServeMux()
+	NewServer(store, &mockAPIKeyStore{keys: map[string]auth.APIKey{apiKey: {Key: apiKey}}}, nil).RegisterRoutes(mux)
+
+	// Map iteration order is random, so repeat to catch an unsorted result.
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api/smart_contract/skills", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Skills []string `json:"skills"`
+			Count  int      `json:"count"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if !sort.StringsAreSorted(resp.Skills) {
+			t.Fatalf("skills not sorted: %v", resp.Skills)
+		}
+		for _, want := range []string{"ada", "go", "python", "rust", "zig", "contract_bidding"} {
+			if !containsString(resp.Skills, want) {
+				t.Fatalf("skills missing %q: %v", want, resp.Skills)
+			}
+		}
+		if resp.Count != len(resp.Skills) {
+			t.Fatalf("count = %d, want %d", resp.Count, len(resp.Skills))
+		}
+	}
+}
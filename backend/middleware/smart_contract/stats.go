@@ -0,0 +1,28 @@
+package smart_contract
+
+import (
+	"net/http"
+
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+// handleStats returns contract, task, proposal and submission counts grouped
+// by status for dashboards.
+// GET /api/smart_contract/stats
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		Error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	counter, ok := s.store.(scstore.StatsCounter)
+	if !ok {
+		Error(w, http.StatusNotImplemented, "store does not support stats")
+		return
+	}
+	stats, err := counter.Stats(r.Context())
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	JSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,61 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleStatsGroupsByStatus(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	ctx := context.Background()
+	for _, p := range []smart_contract.Proposal{
+		{ID: "stats-p1", VisiblePixelHash: strings.Repeat("1", 64), Title: "One", Status: "pending"},
+		{ID: "stats-p2", VisiblePixelHash: strings.Repeat("2", 64), Title: "Two", Status: "pending"},
+		{ID: "stats-p3", VisiblePixelHash: strings.Repeat("3", 64), Title: "Three", Status: "rejected"},
+	} {
+		if err := store.CreateProposal(ctx, p); err != nil {
+			t.Fatalf("create proposal: %v", err)
+		}
+	}
+	want, err := store.Stats(ctx)
+	if err != nil {
+		t.Fatalf("store stats: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	NewServer(store, nil, nil).RegisterRoutes(mux)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/smart_contract/stats", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var got scstore.Stats
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("stats = %+v, want %+v", got, want)
+	}
+	if got.Proposals["pending"] != 2 || got.Proposals["rejected"] != 1 {
+		t.Fatalf("proposal counts = %v, want 2 pending and 1 rejected", got.Proposals)
+	}
+	if got.Contracts["active"] == 0 || got.Tasks["available"] == 0 {
+		t.Fatalf("seeded contracts and tasks missing: %+v", got)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/smart_contract/stats", nil))
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST status = %d, want 405", rr.Code)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"strings"
 	"time"
 
+	"stargate-backend/coerce"
 	"stargate-backend/core/smart_contract"
 	"stargate-backend/storage/ipfs"
 	"stargate-backend/services"
@@ -154,9 +155,9 @@ func (s *Server) PreparePublishArtifacts(ctx context.Context, proposalID string)
 	}
 
 	// If artifacts were already prepared (idempotent), return cached hashes.
-	if h := strings.TrimSpace(toString(meta["stego_contract_id"])); h != "" {
+	if h := strings.TrimSpace(coerce.String(meta["stego_contract_id"])); h != "" {
 		result := &PublishArtifacts{StegoImageHash: h}
-		if sh := strings.TrimSpace(toString(meta["sandbox_hash"])); sh != "" {
+		if sh := strings.TrimSpace(coerce.String(meta["sandbox_hash"])); sh != "" {
 			result.SandboxHash = sh
 		}
 		log.Printf("stego prepare: artifacts already prepared for proposal %s (stego=%s sandbox=%s)", proposalID, result.StegoImageHash, result.SandboxHash)
@@ -164,11 +165,11 @@ func (s *Server) PreparePublishArtifacts(ctx context.Context, proposalID string)
 	}
 
 	// Load the cover image from the ingestion record.
-	ingestionID := strings.TrimSpace(toString(meta["ingestion_id"]))
+	ingestionID := strings.TrimSpace(coerce.String(meta["ingestion_id"]))
 	if ingestionID == "" {
 		visible := strings.TrimSpace(p.VisiblePixelHash)
 		if visible == "" {
-			visible = strings.TrimSpace(toString(meta["visible_pixel_hash"]))
+			visible = strings.TrimSpace(coerce.String(meta["visible_pixel_hash"]))
 		}
 		ingestionID = visible
 		if ingestionID != "" {
@@ -197,7 +198,7 @@ func (s *Server) PreparePublishArtifacts(ctx context.Context, proposalID string)
 
 	visibleHash := strings.TrimSpace(p.VisiblePixelHash)
 	if visibleHash == "" {
-		visibleHash = strings.TrimSpace(toString(meta["visible_pixel_hash"]))
+		visibleHash = strings.TrimSpace(coerce.String(meta["visible_pixel_hash"]))
 	}
 	if visibleHash == "" {
 		return nil, fmt.Errorf("proposal %s missing visible_pixel_hash", proposalID)
@@ -324,18 +325,20 @@ func (s *Server) FinalizePublishArtifacts(ctx context.Context, proposalID string
 			}
 		}
 
-		_ = s.store.UpdateProposalMetadata(ctx, p.ID, meta)
+		if err := s.store.UpdateProposalMetadata(ctx, p.ID, meta); err != nil {
+			log.Printf("stego finalize: failed to update proposal metadata: %v", err)
+		}
 
 		// Pubsub announcement (optional, best-effort).
 		if cfg.AnnounceEnabled && strings.TrimSpace(cfg.AnnounceTopic) != "" {
 			announce := stegoAnnouncement{
 				Type:              "stego",
-				StegoCID:          strings.TrimSpace(toString(meta["stego_image_cid"])),
+				StegoCID:          strings.TrimSpace(coerce.String(meta["stego_image_cid"])),
 				ExpectedHash:      visibleHash,
 				ProposalID:        proposalID,
 				VisiblePixelHash:  visibleHash,
 				Issuer:            cfg.Issuer,
-				SandboxTarballCID: strings.TrimSpace(toString(meta["sandbox_tarball_cid"])),
+				SandboxTarballCID: strings.TrimSpace(coerce.String(meta["sandbox_tarball_cid"])),
 				Timestamp:         time.Now().Unix(),
 			}
 			if payload, err := json.Marshal(announce); err == nil {
@@ -347,13 +350,13 @@ func (s *Server) FinalizePublishArtifacts(ctx context.Context, proposalID string
 	}
 
 	// Update ingestion metadata.
-	ingestionID := strings.TrimSpace(toString(meta["ingestion_id"]))
+	ingestionID := strings.TrimSpace(coerce.String(meta["ingestion_id"]))
 	if s.ingestionSvc != nil && ingestionID != "" {
 		updates := map[string]interface{}{
 			"stego_payload_cid":          "inline",
-			"stego_image_cid":            toString(meta["stego_image_cid"]),
+			"stego_image_cid":            coerce.String(meta["stego_image_cid"]),
 			"stego_contract_id":          artifacts.StegoImageHash,
-			"stego_manifest_created_at":  toString(meta["stego_manifest_created_at"]),
+			"stego_manifest_created_at":  coerce.String(meta["stego_manifest_created_at"]),
 			"stego_manifest_proposal_id": proposalID,
 			"stego_manifest_issuer":      cfg.Issuer,
 			"visible_pixel_hash":         visibleHash,
@@ -422,20 +425,20 @@ func (s *Server) publishStegoForProposal(ctx context.Context, proposalID string,
 	if meta == nil {
 		meta = map[string]interface{}{}
 	}
-	commitmentLock := strings.TrimSpace(toString(meta["commitment_lock_address"]))
-	stegoCommitmentLock := strings.TrimSpace(toString(meta["stego_commitment_lock_address"]))
-	if strings.TrimSpace(toString(meta["stego_contract_id"])) != "" &&
-		strings.TrimSpace(toString(meta["stego_image_cid"])) != "" &&
+	commitmentLock := strings.TrimSpace(coerce.String(meta["commitment_lock_address"]))
+	stegoCommitmentLock := strings.TrimSpace(coerce.String(meta["stego_commitment_lock_address"]))
+	if strings.TrimSpace(coerce.String(meta["stego_contract_id"])) != "" &&
+		strings.TrimSpace(coerce.String(meta["stego_image_cid"])) != "" &&
 		(commitmentLock == "" || commitmentLock == stegoCommitmentLock) {
 		log.Printf("stego publish: already published for proposal %s (contract=%s cid=%s), skipping",
-			proposalID, toString(meta["stego_contract_id"]), toString(meta["stego_image_cid"]))
+			proposalID, coerce.String(meta["stego_contract_id"]), coerce.String(meta["stego_image_cid"]))
 		return nil
 	}
-	ingestionID := strings.TrimSpace(toString(meta["ingestion_id"]))
+	ingestionID := strings.TrimSpace(coerce.String(meta["ingestion_id"]))
 	if ingestionID == "" {
 		visible := strings.TrimSpace(p.VisiblePixelHash)
 		if visible == "" {
-			visible = strings.TrimSpace(toString(meta["visible_pixel_hash"]))
+			visible = strings.TrimSpace(coerce.String(meta["visible_pixel_hash"]))
 		}
 		ingestionID = visible
 		if ingestionID != "" {
@@ -460,7 +463,7 @@ func (s *Server) publishStegoForProposal(ctx context.Context, proposalID string,
 	addWishStegoMeta(meta, coverRec.Metadata)
 	visibleHash := strings.TrimSpace(p.VisiblePixelHash)
 	if visibleHash == "" {
-		visibleHash = strings.TrimSpace(toString(meta["visible_pixel_hash"]))
+		visibleHash = strings.TrimSpace(coerce.String(meta["visible_pixel_hash"]))
 	}
 	if visibleHash == "" {
 		return fmt.Errorf("proposal %s missing visible_pixel_hash", proposalID)
@@ -473,7 +476,7 @@ func (s *Server) publishStegoForProposal(ctx context.Context, proposalID string,
 		return fmt.Errorf("IPFS client is disabled - cannot publish stego")
 	}
 	manifestCreatedAt := time.Now().Unix()
-	if raw := strings.TrimSpace(toString(meta["stego_manifest_created_at"])); raw != "" {
+	if raw := strings.TrimSpace(coerce.String(meta["stego_manifest_created_at"])); raw != "" {
 		if v, err := strconv.ParseInt(raw, 10, 64); err == nil && v > 0 {
 			manifestCreatedAt = v
 		}
@@ -599,7 +602,7 @@ func (s *Server) publishStegoForProposal(ctx context.Context, proposalID string,
 			ProposalID:        proposalID,
 			VisiblePixelHash:  visibleHash,
 			Issuer:            cfg.Issuer,
-			SandboxTarballCID: strings.TrimSpace(toString(meta["sandbox_tarball_cid"])),
+			SandboxTarballCID: strings.TrimSpace(coerce.String(meta["sandbox_tarball_cid"])),
 			Timestamp:         time.Now().Unix(),
 		}
 		if payload, err := json.Marshal(announce); err != nil {
@@ -669,8 +672,8 @@ func mergeStegoLinkage(meta map[string]interface{}, ingestMeta map[string]interf
 	if meta == nil || ingestMeta == nil {
 		return
 	}
-	if strings.TrimSpace(toString(meta["funding_txid"])) == "" {
-		if v := strings.TrimSpace(toString(ingestMeta["funding_txid"])); v != "" {
+	if strings.TrimSpace(coerce.String(meta["funding_txid"])) == "" {
+		if v := strings.TrimSpace(coerce.String(ingestMeta["funding_txid"])); v != "" {
 			meta["funding_txid"] = v
 		} else if list := fundingTxIDsFromMeta(ingestMeta); len(list) > 0 {
 			meta["funding_txid"] = list[0]
@@ -698,9 +701,9 @@ func addWishStegoMeta(meta map[string]interface{}, ingestMeta map[string]interfa
 	if meta == nil || ingestMeta == nil {
 		return
 	}
-	wishText := strings.TrimSpace(toString(ingestMeta["embedded_message"]))
+	wishText := strings.TrimSpace(coerce.String(ingestMeta["embedded_message"]))
 	if wishText == "" {
-		wishText = strings.TrimSpace(toString(ingestMeta["message"]))
+		wishText = strings.TrimSpace(coerce.String(ingestMeta["message"]))
 	}
 	if wishText != "" {
 		meta["wish_text"] = wishText
@@ -769,7 +772,7 @@ func fundingTxIDsFromMeta(meta map[string]interface{}) []string {
 	if meta == nil {
 		return txids
 	}
-	if txid := strings.TrimSpace(toString(meta["funding_txid"])); txid != "" {
+	if txid := strings.TrimSpace(coerce.String(meta["funding_txid"])); txid != "" {
 		add(txid)
 	}
 	switch v := meta["funding_txids"].(type) {
@@ -28,6 +28,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/txscript"
 
+	"stargate-backend/coerce"
 	"stargate-backend/core/smart_contract"
 	"stargate-backend/services"
 	"stargate-backend/stego"
@@ -328,7 +329,7 @@ func (s *Server) ReconcileStegoWithAnnouncement(ctx context.Context, ann *stegoA
 			if pmeta == nil {
 				pmeta = map[string]interface{}{}
 			}
-			if strings.TrimSpace(toString(pmeta["sandbox_tarball_cid"])) == "" {
+			if strings.TrimSpace(coerce.String(pmeta["sandbox_tarball_cid"])) == "" {
 				pmeta["sandbox_tarball_cid"] = cid
 				_ = s.store.UpdateProposalMetadata(ctx, p.ID, pmeta)
 			}
@@ -869,14 +870,14 @@ func (s *Server) downloadSandboxArtifacts(ctx context.Context, contractID string
 func (s *Server) findSandboxHash(ctx context.Context, contractID, normalizedID string) string {
 	// 1. Direct proposal lookup by contractID.
 	if p, err := s.store.GetProposal(ctx, contractID); err == nil && p.Metadata != nil {
-		if h := strings.TrimSpace(toString(p.Metadata["sandbox_hash"])); h != "" {
+		if h := strings.TrimSpace(coerce.String(p.Metadata["sandbox_hash"])); h != "" {
 			return h
 		}
 	}
 	// 2. Proposal lookup by normalizedID (wish-<hash>).
 	if normalizedID != contractID {
 		if p, err := s.store.GetProposal(ctx, normalizedID); err == nil && p.Metadata != nil {
-			if h := strings.TrimSpace(toString(p.Metadata["sandbox_hash"])); h != "" {
+			if h := strings.TrimSpace(coerce.String(p.Metadata["sandbox_hash"])); h != "" {
 				return h
 			}
 		}
@@ -885,7 +886,7 @@ func (s *Server) findSandboxHash(ctx context.Context, contractID, normalizedID s
 	vph := strings.TrimPrefix(normalizedID, "wish-")
 	if vph != normalizedID && vph != contractID {
 		if p, err := s.store.GetProposal(ctx, vph); err == nil && p.Metadata != nil {
-			if h := strings.TrimSpace(toString(p.Metadata["sandbox_hash"])); h != "" {
+			if h := strings.TrimSpace(coerce.String(p.Metadata["sandbox_hash"])); h != "" {
 				return h
 			}
 		}
@@ -893,22 +894,22 @@ func (s *Server) findSandboxHash(ctx context.Context, contractID, normalizedID s
 	// 4. List proposals filtering by contract ID.
 	if proposals, err := s.store.ListProposals(ctx, smart_contract.ProposalFilter{ContractID: contractID}); err == nil {
 		for _, p := range proposals {
-			if h := strings.TrimSpace(toString(p.Metadata["sandbox_hash"])); h != "" {
+			if h := strings.TrimSpace(coerce.String(p.Metadata["sandbox_hash"])); h != "" {
 				return h
 			}
 		}
 	}
 	// 5. Check contract metadata directly.
 	if c, err := s.store.GetContract(contractID); err == nil && c.Metadata != nil {
-		if h := strings.TrimSpace(toString(c.Metadata["sandbox_hash"])); h != "" {
+		if h := strings.TrimSpace(coerce.String(c.Metadata["sandbox_hash"])); h != "" {
 			return h
 		}
 	}
 	// 6. Try with origin_proposal_id from contract metadata.
 	if c, err := s.store.GetContract(contractID); err == nil && c.Metadata != nil {
-		if opID := strings.TrimSpace(toString(c.Metadata["origin_proposal_id"])); opID != "" {
+		if opID := strings.TrimSpace(coerce.String(c.Metadata["origin_proposal_id"])); opID != "" {
 			if p, err := s.store.GetProposal(ctx, opID); err == nil && p.Metadata != nil {
-				if h := strings.TrimSpace(toString(p.Metadata["sandbox_hash"])); h != "" {
+				if h := strings.TrimSpace(coerce.String(p.Metadata["sandbox_hash"])); h != "" {
 					return h
 				}
 			}
@@ -922,13 +923,13 @@ func (s *Server) findSandboxHash(ctx context.Context, contractID, normalizedID s
 func (s *Server) findSandboxCID(ctx context.Context, contractID, normalizedID string) string {
 	for _, id := range []string{contractID, normalizedID, strings.TrimPrefix(normalizedID, "wish-")} {
 		if p, err := s.store.GetProposal(ctx, id); err == nil && p.Metadata != nil {
-			if cid := strings.TrimSpace(toString(p.Metadata["sandbox_tarball_cid"])); cid != "" {
+			if cid := strings.TrimSpace(coerce.String(p.Metadata["sandbox_tarball_cid"])); cid != "" {
 				return cid
 			}
 		}
 	}
 	if c, err := s.store.GetContract(contractID); err == nil && c.Metadata != nil {
-		if cid := strings.TrimSpace(toString(c.Metadata["sandbox_tarball_cid"])); cid != "" {
+		if cid := strings.TrimSpace(coerce.String(c.Metadata["sandbox_tarball_cid"])); cid != "" {
 			return cid
 		}
 	}
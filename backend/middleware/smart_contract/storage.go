@@ -23,4 +23,5 @@ var (
 	ErrClaimNotFound   = scstore.ErrClaimNotFound
 	ErrTaskTaken       = scstore.ErrTaskTaken
 	ErrTaskUnavailable = scstore.ErrTaskUnavailable
+	ErrTaskClosed      = scstore.ErrTaskClosed
 )
@@ -0,0 +1,62 @@
+package smart_contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	auth "stargate-backend/storage/auth"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestCreateEndpointsRejectUnknownFields(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	const apiKey = "strict-json-key"
+	keys := &mockAPIKeyStore{keys: map[string]auth.APIKey{apiKey: {Key: apiKey, Wallet: mustTestnetAddress(t, 5)}}}
+	mux := http.NewServeMux()
+	NewServer(store, keys, nil).RegisterRoutes(mux)
+
+	tests := []struct {
+		name  string
+		path  string
+		body  string
+		field string
+	}{
+		{"proposal budget typo", "/api/smart_contract/proposals", `{"title":"Typo","visible_pixel_hash":"` + strings.Repeat("a", 64) + `","budget_sat":5000}`, "budget_sat"},
+		{"nested task typo", "/api/smart_contract/proposals", `{"title":"Typo","tasks":[{"title":"t","budget_sat":1}]}`, "budget_sat"},
+		{"claim typo", "/api/smart_contract/tasks/" + scstore.SeedTaskBollingerID + "/claim", `{"estimated_completon":"2030-01-01T00:00:00Z"}`, "estimated_completon"},
+		{"psbt typo", "/api/smart_contract/contracts/" + scstore.SeedContractID + "/psbt", `{"comitment_sats":1000}`, "comitment_sats"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", apiKey)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), `unknown field \"`+tc.field+`\"`) {
+				t.Fatalf("error does not name %q: %s", tc.field, rec.Body.String())
+			}
+		})
+	}
+
+	// A correctly spelled payload still decodes.
+	req := httptest.NewRequest(http.MethodPost, "/api/smart_contract/tasks/"+scstore.SeedTaskBollingerID+"/claim", strings.NewReader(`{"estimated_completion":"`+time.Now().Add(24*time.Hour).UTC().Format(time.RFC3339)+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", apiKey)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("valid claim status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var claim map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &claim); err != nil || claim["claim_id"] == nil {
+		t.Fatalf("claim response = %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,73 @@
+package smart_contract
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+// submissionExpand is what ?expand= asked to hydrate on each submission.
+type submissionExpand struct {
+	Task     bool
+	Contract bool
+}
+
+func (e submissionExpand) any() bool { return e.Task || e.Contract }
+
+// parseSubmissionExpand reads a comma-separated ?expand= value. Only "task"
+// and "contract" are accepted.
+func parseSubmissionExpand(raw string) (submissionExpand, error) {
+	var e submissionExpand
+	for _, field := range splitCSV(raw) {
+		switch strings.ToLower(field) {
+		case "task":
+			e.Task = true
+		case "contract":
+			e.Contract = true
+		default:
+			return e, fmt.Errorf("unknown expand field %q (use task, contract)", field)
+		}
+	}
+	return e, nil
+}
+
+// expandedSubmission is a Submission with its task and contract summaries
+// inlined for reviewers.
+type expandedSubmission struct {
+	smart_contract.Submission
+	Task     *scstore.SubmissionTask     `json:"task,omitempty"`
+	Contract *scstore.SubmissionContract `json:"contract,omitempty"`
+}
+
+// expandSubmissions hydrates subs with the fields in e, resolving every
+// distinct task and contract in one store lookup.
+func (s *Server) expandSubmissions(ctx context.Context, subs []smart_contract.Submission, e submissionExpand) ([]expandedSubmission, error) {
+	taskIDs := make([]string, len(subs))
+	for i, sub := range subs {
+		taskIDs[i] = sub.TaskID
+	}
+	contexts, err := scstore.ResolveSubmissionContexts(ctx, s.store, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]expandedSubmission, len(subs))
+	for i, sub := range subs {
+		out[i] = expandedSubmission{Submission: sub}
+		sc, ok := contexts[sub.TaskID]
+		if !ok {
+			continue
+		}
+		if e.Task {
+			task := sc.Task
+			out[i].Task = &task
+		}
+		if e.Contract {
+			out[i].Contract = sc.Contract
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,80 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleSubmissionsExpand(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	ctx := context.Background()
+	contract := smart_contract.Contract{ContractID: "contract-expand", Title: "Expand", Status: "active"}
+	task := smart_contract.Task{TaskID: "expand-task", ContractID: contract.ContractID, Title: "Write docs", Skills: []string{"writing"}, Status: "available"}
+	if err := store.UpsertContractWithTasks(ctx, contract, []smart_contract.Task{task}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	claim, err := store.ClaimTask(task.TaskID, "bc1qexpand", nil)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	if _, err := store.SubmitWork(claim.ClaimID, map[string]interface{}{"notes": "done"}, nil); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	server := NewServer(store, nil, nil)
+	list := func(query string) (int, []map[string]json.RawMessage) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		server.handleSubmissions(rec, httptest.NewRequest(http.MethodGet, "/api/smart_contract/submissions?contract_id="+contract.ContractID+query, nil))
+		if rec.Code != http.StatusOK {
+			return rec.Code, nil
+		}
+		var body struct {
+			Submissions []map[string]json.RawMessage `json:"submissions"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(body.Submissions) != 1 {
+			t.Fatalf("expected 1 submission, got %d", len(body.Submissions))
+		}
+		return rec.Code, body.Submissions
+	}
+
+	_, plain := list("")
+	if _, ok := plain[0]["task"]; ok {
+		t.Fatalf("task present without expand: %s", plain[0]["task"])
+	}
+	if _, ok := plain[0]["contract"]; ok {
+		t.Fatalf("contract present without expand: %s", plain[0]["contract"])
+	}
+
+	_, expanded := list("&expand=task,contract")
+	var gotTask scstore.SubmissionTask
+	if err := json.Unmarshal(expanded[0]["task"], &gotTask); err != nil || gotTask.Title != "Write docs" || len(gotTask.Skills) != 1 || gotTask.Skills[0] != "writing" {
+		t.Fatalf("task = %s (%v)", expanded[0]["task"], err)
+	}
+	var gotContract scstore.SubmissionContract
+	if err := json.Unmarshal(expanded[0]["contract"], &gotContract); err != nil || gotContract.Title != "Expand" || gotContract.Status != "active" {
+		t.Fatalf("contract = %s (%v)", expanded[0]["contract"], err)
+	}
+	if string(expanded[0]["submission_id"]) == "" {
+		t.Fatalf("expanded submission lost its own fields: %v", expanded[0])
+	}
+
+	_, taskOnly := list("&expand=task")
+	if _, ok := taskOnly[0]["contract"]; ok {
+		t.Fatalf("contract present with expand=task")
+	}
+
+	if code, _ := list("&expand=claim"); code != http.StatusBadRequest {
+		t.Fatalf("unknown expand field: status %d, want 400", code)
+	}
+}
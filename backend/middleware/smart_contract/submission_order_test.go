@@ -0,0 +1,98 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+	scstore "stargate-backend/storage/smart_contract"
+)
+
+func TestHandleSubmissionsOrderedByCreatedAt(t *testing.T) {
+	store := scstore.NewMemoryStore(time.Hour)
+	ctx := context.Background()
+	contract := smart_contract.Contract{ContractID: "contract-order", Title: "Order", Status: "active"}
+	var tasks []smart_contract.Task
+	for i := 0; i < 5; i++ {
+		tasks = append(tasks, smart_contract.Task{TaskID: fmt.Sprintf("order-task-%d", i), ContractID: contract.ContractID, Title: "T", Status: "available"})
+	}
+	if err := store.UpsertContractWithTasks(ctx, contract, tasks); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	// Stored out of order; "sub-b" and "sub-c" share a timestamp.
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := map[string]time.Time{
+		"sub-d": base.Add(3 * time.Hour),
+		"sub-a": base,
+		"sub-e": base.Add(4 * time.Hour),
+		"sub-c": base.Add(time.Hour),
+		"sub-b": base.Add(time.Hour),
+	}
+	i := 0
+	for id, at := range created {
+		claim, err := store.ClaimTask(tasks[i].TaskID, "bc1qorder", nil)
+		if err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		if err := store.SyncSubmission(ctx, smart_contract.Submission{SubmissionID: id, ClaimID: claim.ClaimID, Status: "pending_review", CreatedAt: at}); err != nil {
+			t.Fatalf("sync: %v", err)
+		}
+		i++
+	}
+
+	server := NewServer(store, nil, nil)
+	get := func(query string) map[string]json.RawMessage {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		server.handleSubmissions(rec, httptest.NewRequest(http.MethodGet, "/api/smart_contract/submissions?contract_id="+contract.ContractID+query, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+		}
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return body
+	}
+	ids := func(body map[string]json.RawMessage) []string {
+		t.Helper()
+		var subs []smart_contract.Submission
+		if err := json.Unmarshal(body["submissions"], &subs); err != nil {
+			t.Fatalf("submissions is not an array: %s", body["submissions"])
+		}
+		out := make([]string, len(subs))
+		for i, s := range subs {
+			out[i] = s.SubmissionID
+		}
+		return out
+	}
+
+	want := []string{"sub-a", "sub-b", "sub-c", "sub-d", "sub-e"}
+	for n := 0; n < 3; n++ { // map iteration in the store must not leak into the order
+		if got := ids(get("")); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+
+	page := get("&limit=2&offset=2")
+	if got := ids(page); fmt.Sprint(got) != fmt.Sprint(want[2:4]) {
+		t.Fatalf("page = %v, want %v", got, want[2:4])
+	}
+	if string(page["total"]) != "5" || string(page["has_more"]) != "true" {
+		t.Fatalf("total = %s, has_more = %s", page["total"], page["has_more"])
+	}
+	if last := get("&limit=2&offset=4"); string(last["has_more"]) != "false" || len(ids(last)) != 1 {
+		t.Fatalf("last page = %s", last["submissions"])
+	}
+
+	var legacy map[string]smart_contract.Submission
+	if err := json.Unmarshal(get("&format=map")["submissions"], &legacy); err != nil || len(legacy) != 5 || legacy["sub-c"].SubmissionID != "sub-c" {
+		t.Fatalf("format=map = %v, %v", legacy, err)
+	}
+}
@@ -132,6 +132,7 @@ type SearchRequest struct {
 type PendingTransactionsResponse struct {
 	Transactions []InscriptionRequest `json:"transactions"`
 	Total        int                  `json:"total"`
+	NextOffset   *int                 `json:"next_offset,omitempty"` // set on paginated requests with more results
 }
 
 // SmartContractsResponse represents smart contracts response
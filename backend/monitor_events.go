@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"stargate-backend/bitcoin"
+)
+
+// monitorEventBuffer is how many events a slow SSE client may fall behind
+// before newer events are dropped for it.
+const monitorEventBuffer = 16
+
+// monitorEventHub fans block monitor progress out to SSE clients, following
+// the listener pattern of the smart_contract event stream: broadcasts never
+// block, so a stalled client cannot hold up block processing.
+type monitorEventHub struct {
+	mu        sync.Mutex
+	listeners []chan bitcoin.MonitorEvent
+	last      *bitcoin.MonitorEvent
+	closed    bool
+}
+
+func newMonitorEventHub() *monitorEventHub {
+	return &monitorEventHub{}
+}
+
+// addListener registers a client and returns the latest event for it to
+// start from. After Shutdown the channel is already closed.
+func (h *monitorEventHub) addListener() (chan bitcoin.MonitorEvent, *bitcoin.MonitorEvent) {
+	ch := make(chan bitcoin.MonitorEvent, monitorEventBuffer)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(ch)
+		return ch, nil
+	}
+	h.listeners = append(h.listeners, ch)
+	return ch, h.last
+}
+
+func (h *monitorEventHub) removeListener(ch chan bitcoin.MonitorEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, c := range h.listeners {
+		if c == ch {
+			close(c)
+			h.listeners = append(h.listeners[:i], h.listeners[i+1:]...)
+			break
+		}
+	}
+}
+
+// broadcast is registered with BlockMonitor.OnMonitorEvent. A listener whose
+// buffer is full misses the event.
+func (h *monitorEventHub) broadcast(evt bitcoin.MonitorEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = &evt
+	for _, ch := range h.listeners {
+		select {
+		case ch <- evt:
+		default:
+		}
+	}
+}
+
+func (h *monitorEventHub) listenerCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.listeners)
+}
+
+// Shutdown closes every listener so open streams return before the HTTP
+// server drains.
+func (h *monitorEventHub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, ch := range h.listeners {
+		close(ch)
+	}
+	h.listeners = nil
+	return nil
+}
+
+// handleEvents serves GET /api/monitor/events as a text/event-stream of
+// "monitor" events, starting with the most recent one.
+func (h *monitorEventHub) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ch, last := h.addListener()
+	write := func(evt bitcoin.MonitorEvent) {
+		b, _ := json.Marshal(evt)
+		w.Write([]byte("event: monitor\n"))
+		w.Write([]byte("data: " + string(b) + "\n\n"))
+		flusher.Flush()
+	}
+	if last != nil {
+		write(*last)
+	} else {
+		flusher.Flush()
+	}
+
+	notify := r.Context().Done()
+	for {
+		select {
+		case <-notify:
+			h.removeListener(ch)
+			return
+		case evt, ok := <-ch:
+			if !ok {
+				return
+			}
+			write(evt)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/bitcoin"
+)
+
+func TestMonitorEventsStreamsAndCleansUp(t *testing.T) {
+	hub := newMonitorEventHub()
+	hub.broadcast(bitcoin.MonitorEvent{Type: bitcoin.MonitorEventBlockStarted, Height: 99})
+	srv := httptest.NewServer(http.HandlerFunc(hub.handleEvents))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	events := make(chan bitcoin.MonitorEvent, 4)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				var evt bitcoin.MonitorEvent
+				if json.Unmarshal([]byte(data), &evt) == nil {
+					events <- evt
+				}
+			}
+		}
+	}()
+	next := func() bitcoin.MonitorEvent {
+		t.Helper()
+		select {
+		case evt := <-events:
+			return evt
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event received")
+		}
+		return bitcoin.MonitorEvent{}
+	}
+
+	// A new client first sees the latest event.
+	if evt := next(); evt.Type != bitcoin.MonitorEventBlockStarted || evt.Height != 99 {
+		t.Fatalf("initial event = %+v", evt)
+	}
+	hub.broadcast(bitcoin.MonitorEvent{Type: bitcoin.MonitorEventBlockCompleted, Height: 99, TxCount: 3, ImagesFound: 1, DurationMs: 12})
+	if evt := next(); evt.Type != bitcoin.MonitorEventBlockCompleted || evt.TxCount != 3 || evt.ImagesFound != 1 || evt.DurationMs != 12 {
+		t.Fatalf("completed event = %+v", evt)
+	}
+
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for hub.listenerCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("listener not removed after client disconnect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMonitorEventsSlowConsumerDoesNotBlock(t *testing.T) {
+	hub := newMonitorEventHub()
+	stalled, _ := hub.addListener() // never read
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < monitorEventBuffer*4; i++ {
+			hub.broadcast(bitcoin.MonitorEvent{Type: bitcoin.MonitorEventBlockStarted, Height: int64(i)})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("broadcast blocked on a stalled listener")
+	}
+	if n := len(stalled); n != monitorEventBuffer {
+		t.Fatalf("stalled listener buffered %d events, want %d", n, monitorEventBuffer)
+	}
+
+	if err := hub.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for range stalled {
+	}
+	late, _ := hub.addListener()
+	if _, ok := <-late; ok {
+		t.Fatal("listener added after Shutdown is open")
+	}
+}
@@ -14,6 +14,7 @@ export UPLOADS_DIR="${UPLOADS_DIR:-$ROOT_DIR/uploads}"
 export STARGATE_PROXY_BASE="${STARGATE_PROXY_BASE:-}"
 export STARGATE_API_KEY="${STARGATE_API_KEY:-demo-api-key}"
 export ALLOW_ORIGINS="${ALLOW_ORIGINS:-*}"
+export LOG_LEVEL="${LOG_LEVEL:-debug}"
 
 mkdir -p "${BLOCKS_DIR}" "${UPLOADS_DIR}"
 
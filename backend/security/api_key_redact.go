@@ -0,0 +1,20 @@
+package security
+
+import "strings"
+
+// apiKeyFingerprintChars is how many characters are kept from each end.
+const apiKeyFingerprintChars = 4
+
+// APIKeyFingerprint returns a loggable stand-in for an API key: its first and
+// last four characters. Keys too short for that to hide most of the secret
+// are fully masked. Use it wherever a key would otherwise reach a log.
+func APIKeyFingerprint(key string) string {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return ""
+	}
+	if len(key) <= 3*apiKeyFingerprintChars {
+		return "***"
+	}
+	return key[:apiKeyFingerprintChars] + "..." + key[len(key)-apiKeyFingerprintChars:]
+}
@@ -0,0 +1,18 @@
+package security
+
+import "testing"
+
+func TestAPIKeyFingerprint(t *testing.T) {
+	cases := map[string]string{
+		"":                         "",
+		"short":                    "***",
+		"exactly12chr":             "***",
+		"sk-live-0123456789abcdef": "sk-l...cdef",
+		"  padded-key-value-xyz  ": "padd...-xyz",
+	}
+	for key, want := range cases {
+		if got := APIKeyFingerprint(key); got != want {
+			t.Errorf("APIKeyFingerprint(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -85,3 +85,29 @@ func SafeFilePath(baseDir, filename string) string {
 	sanitized := SanitizeFilename(filename)
 	return filepath.Join(baseDir, sanitized)
 }
+
+// ContainedFilePath joins a single path element onto baseDir, rejecting names
+// that contain separators, traversal or control characters instead of rewriting them.
+func ContainedFilePath(baseDir, filename string) (string, error) {
+	if baseDir == "" {
+		return "", fmt.Errorf("invalid path parameters")
+	}
+	if filename == "" || filename == "." || filename == ".." {
+		return "", fmt.Errorf("invalid filename: %q", filename)
+	}
+	if strings.ContainsAny(filename, `/\`) {
+		return "", fmt.Errorf("path separator in filename: %q", filename)
+	}
+	for _, r := range filename {
+		if r < 32 || r == 127 {
+			return "", fmt.Errorf("control character in filename: %q", filename)
+		}
+	}
+
+	base := filepath.Clean(baseDir)
+	full := filepath.Join(base, filename)
+	if filepath.Dir(full) != base {
+		return "", fmt.Errorf("path traversal detected: %s", filename)
+	}
+	return full, nil
+}
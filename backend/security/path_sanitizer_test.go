@@ -157,3 +157,33 @@ func TestValidateExtensionWithAllowedLists(t *testing.T) {
 		t.Error("Expected SH to NOT be in allowed text extensions")
 	}
 }
+
+func TestContainedFilePath(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+		wantErr  bool
+	}{
+		{"image.png", "/blocks/images/image.png", false},
+		{"...test...", "/blocks/images/...test...", false},
+		{"", "", true},
+		{".", "", true},
+		{"..", "", true},
+		{"../escape.png", "", true},
+		{"sub/image.png", "", true},
+		{`..\..\escape.png`, "", true},
+		{"/etc/passwd", "", true},
+		{"bad\x00name.png", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ContainedFilePath("/blocks/images", tt.filename)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ContainedFilePath(%q) error = %v, wantErr %v", tt.filename, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ContainedFilePath(%q) = %q, want %q", tt.filename, got, tt.want)
+		}
+	}
+}
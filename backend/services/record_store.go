@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"stargate-backend/core"
+	"stargate-backend/models"
+)
+
+// InscriptionStore persists the pending inscription list. Load returns an
+// empty slice when nothing has been saved yet; Save replaces the whole list.
+type InscriptionStore interface {
+	LoadInscriptions() ([]models.InscriptionRequest, error)
+	SaveInscriptions(inscriptions []models.InscriptionRequest) error
+}
+
+// ContractStore persists the smart contract image list with the same
+// semantics as InscriptionStore.
+type ContractStore interface {
+	LoadContracts() ([]core.SmartContractImage, error)
+	SaveContracts(contracts []core.SmartContractImage) error
+}
+
+// FileInscriptionStore keeps inscriptions as a JSON array in one file.
+type FileInscriptionStore struct {
+	path string
+}
+
+// NewFileInscriptionStore returns a store backed by the JSON file at path.
+func NewFileInscriptionStore(path string) *FileInscriptionStore {
+	return &FileInscriptionStore{path: path}
+}
+
+// LoadInscriptions reads the file; a missing file is an empty list.
+func (f *FileInscriptionStore) LoadInscriptions() ([]models.InscriptionRequest, error) {
+	var inscriptions []models.InscriptionRequest
+	if err := readJSONFile(f.path, &inscriptions); err != nil {
+		if os.IsNotExist(err) {
+			return []models.InscriptionRequest{}, nil
+		}
+		return nil, fmt.Errorf("failed to load inscriptions: %w", err)
+	}
+	return inscriptions, nil
+}
+
+// SaveInscriptions replaces the file atomically.
+func (f *FileInscriptionStore) SaveInscriptions(inscriptions []models.InscriptionRequest) error {
+	if err := writeJSONFileAtomic(f.path, inscriptions); err != nil {
+		return fmt.Errorf("failed to save inscriptions: %w", err)
+	}
+	return nil
+}
+
+// FileContractStore keeps smart contract images as a JSON array in one file.
+type FileContractStore struct {
+	path string
+}
+
+// NewFileContractStore returns a store backed by the JSON file at path.
+func NewFileContractStore(path string) *FileContractStore {
+	return &FileContractStore{path: path}
+}
+
+// LoadContracts reads the file; a missing file is an empty list.
+func (f *FileContractStore) LoadContracts() ([]core.SmartContractImage, error) {
+	var contracts []core.SmartContractImage
+	if err := readJSONFile(f.path, &contracts); err != nil {
+		if os.IsNotExist(err) {
+			return []core.SmartContractImage{}, nil
+		}
+		return nil, fmt.Errorf("failed to load contracts: %w", err)
+	}
+	return contracts, nil
+}
+
+// SaveContracts replaces the file atomically, creating its directory.
+func (f *FileContractStore) SaveContracts(contracts []core.SmartContractImage) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("failed to create contracts directory: %w", err)
+	}
+	if err := writeJSONFileAtomic(f.path, contracts); err != nil {
+		return fmt.Errorf("failed to save contracts: %w", err)
+	}
+	return nil
+}
+
+func readJSONFile(path string, v any) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewDecoder(file).Decode(v)
+}
+
+// writeJSONFileAtomic encodes v to a temporary file next to path and renames
+// it into place, so readers and crashes never see a partly written file.
+func writeJSONFileAtomic(path string, v any) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	// CreateTemp uses 0600; keep the 0644 the files had when written directly.
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := json.NewEncoder(tmp).Encode(v); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+
+	"stargate-backend/core"
+	"stargate-backend/models"
+)
+
+// SQLiteRecordStore keeps inscriptions and smart contract images in SQLite.
+// Each record is stored as its JSON encoding, ordered by position, so a
+// load returns exactly what the file store would.
+type SQLiteRecordStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteRecordStore opens (creating if needed) the database at dbPath.
+func NewSQLiteRecordStore(dbPath string) (*SQLiteRecordStore, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_foreign_keys=on")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite3: %w", err)
+	}
+	// One writer keeps SQLite from returning SQLITE_BUSY on concurrent saves.
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteRecordStore{db: db}
+	if err := s.initSchema(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLiteRecordStore) initSchema(ctx context.Context) error {
+	schema := `
+CREATE TABLE IF NOT EXISTS inscriptions (
+  position INTEGER PRIMARY KEY,
+  id TEXT NOT NULL,
+  data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS smart_contracts (
+  position INTEGER PRIMARY KEY,
+  contract_id TEXT NOT NULL,
+  data TEXT NOT NULL
+);
+`
+	_, err := s.db.ExecContext(ctx, schema)
+	return err
+}
+
+// Close closes the underlying database.
+func (s *SQLiteRecordStore) Close() error {
+	return s.db.Close()
+}
+
+// LoadInscriptions returns all inscriptions in saved order.
+func (s *SQLiteRecordStore) LoadInscriptions() ([]models.InscriptionRequest, error) {
+	inscriptions := []models.InscriptionRequest{}
+	err := s.loadRows("SELECT data FROM inscriptions ORDER BY position", func(data []byte) error {
+		var ins models.InscriptionRequest
+		if err := json.Unmarshal(data, &ins); err != nil {
+			return err
+		}
+		inscriptions = append(inscriptions, ins)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inscriptions: %w", err)
+	}
+	return inscriptions, nil
+}
+
+// SaveInscriptions replaces all inscriptions in one transaction.
+func (s *SQLiteRecordStore) SaveInscriptions(inscriptions []models.InscriptionRequest) error {
+	rows := make([]recordRow, 0, len(inscriptions))
+	for _, ins := range inscriptions {
+		rows = append(rows, recordRow{id: ins.ID, value: ins})
+	}
+	if err := s.replaceRows("inscriptions", "id", rows); err != nil {
+		return fmt.Errorf("failed to save inscriptions: %w", err)
+	}
+	return nil
+}
+
+// LoadContracts returns all smart contract images in saved order.
+func (s *SQLiteRecordStore) LoadContracts() ([]core.SmartContractImage, error) {
+	contracts := []core.SmartContractImage{}
+	err := s.loadRows("SELECT data FROM smart_contracts ORDER BY position", func(data []byte) error {
+		var c core.SmartContractImage
+		if err := json.Unmarshal(data, &c); err != nil {
+			return err
+		}
+		contracts = append(contracts, c)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contracts: %w", err)
+	}
+	return contracts, nil
+}
+
+// SaveContracts replaces all smart contract images in one transaction.
+func (s *SQLiteRecordStore) SaveContracts(contracts []core.SmartContractImage) error {
+	rows := make([]recordRow, 0, len(contracts))
+	for _, c := range contracts {
+		rows = append(rows, recordRow{id: c.ContractID, value: c})
+	}
+	if err := s.replaceRows("smart_contracts", "contract_id", rows); err != nil {
+		return fmt.Errorf("failed to save contracts: %w", err)
+	}
+	return nil
+}
+
+type recordRow struct {
+	id    string
+	value any
+}
+
+func (s *SQLiteRecordStore) loadRows(query string, scan func([]byte) error) error {
+	rows, err := s.db.QueryContext(context.Background(), query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := scan(data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// replaceRows swaps the contents of table for rows. table and idColumn are
+// fixed identifiers from this file, never user input.
+func (s *SQLiteRecordStore) replaceRows(table, idColumn string, rows []recordRow) error {
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO "+table+" (position, "+idColumn+", data) VALUES (?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for i, row := range rows {
+		data, err := json.Marshal(row.value)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.ExecContext(ctx, i, row.id, string(data)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
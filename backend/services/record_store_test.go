@@ -0,0 +1,123 @@
+package services
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"stargate-backend/core"
+	"stargate-backend/models"
+)
+
+type recordStore interface {
+	InscriptionStore
+	ContractStore
+}
+
+type fileRecordStore struct {
+	*FileInscriptionStore
+	*FileContractStore
+}
+
+// recordStoreImpls builds each implementation on a fresh temp directory so
+// every test runs the same assertions through the interfaces.
+var recordStoreImpls = map[string]func(t *testing.T) recordStore{
+	"file": func(t *testing.T) recordStore {
+		dir := t.TempDir()
+		return fileRecordStore{
+			NewFileInscriptionStore(filepath.Join(dir, "inscriptions.json")),
+			NewFileContractStore(filepath.Join(dir, "contracts", "smart_contracts.json")),
+		}
+	},
+	"sqlite": func(t *testing.T) recordStore {
+		store, err := NewSQLiteRecordStore(filepath.Join(t.TempDir(), "records.db"))
+		if err != nil {
+			t.Fatalf("NewSQLiteRecordStore: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	},
+}
+
+func TestRecordStoresLoadEmpty(t *testing.T) {
+	for name, newStore := range recordStoreImpls {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			inscriptions, err := store.LoadInscriptions()
+			if err != nil || inscriptions == nil || len(inscriptions) != 0 {
+				t.Fatalf("LoadInscriptions = %v, %v; want empty non-nil slice", inscriptions, err)
+			}
+			contracts, err := store.LoadContracts()
+			if err != nil || contracts == nil || len(contracts) != 0 {
+				t.Fatalf("LoadContracts = %v, %v; want empty non-nil slice", contracts, err)
+			}
+		})
+	}
+}
+
+func TestRecordStoresRoundTripAndReplace(t *testing.T) {
+	inscriptions := []models.InscriptionRequest{
+		{ID: "b", Text: "second", Price: 0.5, Timestamp: 2, Status: "pending"},
+		{ID: "a", Text: "first", Timestamp: 1, Status: "confirmed"},
+	}
+	contracts := []core.SmartContractImage{
+		{ContractID: "c1", BlockHeight: 100, ContractType: "escrow", Metadata: map[string]interface{}{"k": "v"}},
+	}
+
+	for name, newStore := range recordStoreImpls {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			if err := store.SaveInscriptions(inscriptions); err != nil {
+				t.Fatalf("SaveInscriptions: %v", err)
+			}
+			if err := store.SaveContracts(contracts); err != nil {
+				t.Fatalf("SaveContracts: %v", err)
+			}
+
+			gotIns, err := store.LoadInscriptions()
+			if err != nil || !reflect.DeepEqual(gotIns, inscriptions) {
+				t.Fatalf("LoadInscriptions = %+v, %v; want %+v", gotIns, err, inscriptions)
+			}
+			gotContracts, err := store.LoadContracts()
+			if err != nil || !reflect.DeepEqual(gotContracts, contracts) {
+				t.Fatalf("LoadContracts = %+v, %v; want %+v", gotContracts, err, contracts)
+			}
+
+			// A save replaces the previous contents rather than appending.
+			if err := store.SaveInscriptions(inscriptions[1:]); err != nil {
+				t.Fatalf("SaveInscriptions: %v", err)
+			}
+			gotIns, err = store.LoadInscriptions()
+			if err != nil || !reflect.DeepEqual(gotIns, inscriptions[1:]) {
+				t.Fatalf("after replace LoadInscriptions = %+v, %v; want %+v", gotIns, err, inscriptions[1:])
+			}
+		})
+	}
+}
+
+func TestServicesPersistThroughStore(t *testing.T) {
+	for name, newStore := range recordStoreImpls {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			contracts := NewSmartContractServiceWithStore(store)
+			if _, err := contracts.CreateContract(models.CreateContractRequest{ContractID: "c1", BlockHeight: 7}); err != nil {
+				t.Fatalf("CreateContract: %v", err)
+			}
+
+			// A new service on the same store sees the saved contract.
+			got, err := NewSmartContractServiceWithStore(store).GetContractByID("c1")
+			if err != nil || got.BlockHeight != 7 {
+				t.Fatalf("GetContractByID = %+v, %v; want block height 7", got, err)
+			}
+
+			inscriptions := NewInscriptionServiceWithStore(store)
+			if err := store.SaveInscriptions([]models.InscriptionRequest{{ID: "x", Text: "hello"}}); err != nil {
+				t.Fatalf("SaveInscriptions: %v", err)
+			}
+			found, err := inscriptions.SearchInscriptions("hello")
+			if err != nil || len(found) != 1 {
+				t.Fatalf("SearchInscriptions = %v, %v; want 1 result", found, err)
+			}
+		})
+	}
+}
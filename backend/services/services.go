@@ -2,7 +2,9 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image/png"
 	"io"
@@ -22,15 +24,18 @@ import (
 
 // InscriptionService handles inscription-related business logic
 type InscriptionService struct {
-	inscriptionsFile string
-	mu               sync.RWMutex
+	store InscriptionStore
+	mu    sync.RWMutex
 }
 
-// NewInscriptionService creates a new inscription service
+// NewInscriptionService creates an inscription service backed by a JSON file.
 func NewInscriptionService(inscriptionsFile string) *InscriptionService {
-	return &InscriptionService{
-		inscriptionsFile: inscriptionsFile,
-	}
+	return NewInscriptionServiceWithStore(NewFileInscriptionStore(inscriptionsFile))
+}
+
+// NewInscriptionServiceWithStore creates an inscription service on store.
+func NewInscriptionServiceWithStore(store InscriptionStore) *InscriptionService {
+	return &InscriptionService{store: store}
 }
 
 // GetAllInscriptions retrieves all pending inscriptions
@@ -40,24 +45,9 @@ func (s *InscriptionService) GetAllInscriptions() ([]models.InscriptionRequest,
 	return s.loadInscriptions()
 }
 
-// loadInscriptions loads inscriptions from file without locking
+// loadInscriptions loads inscriptions from the store without locking
 func (s *InscriptionService) loadInscriptions() ([]models.InscriptionRequest, error) {
-	var inscriptions []models.InscriptionRequest
-
-	file, err := os.Open(s.inscriptionsFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []models.InscriptionRequest{}, nil
-		}
-		return nil, fmt.Errorf("failed to open inscriptions file: %w", err)
-	}
-	defer file.Close()
-
-	if err := json.NewDecoder(file).Decode(&inscriptions); err != nil {
-		return []models.InscriptionRequest{}, fmt.Errorf("failed to decode inscriptions: %w", err)
-	}
-
-	return inscriptions, nil
+	return s.store.LoadInscriptions()
 }
 
 // CreateInscription creates a new inscription
@@ -126,6 +116,57 @@ func (s *InscriptionService) CreateInscription(req models.InscribeRequest, file
 	return inscription, nil
 }
 
+// Errors returned by DeleteInscription.
+var (
+	ErrInscriptionNotFound   = errors.New("inscription not found")
+	ErrInscriptionNotPending = errors.New("only pending inscriptions can be deleted")
+	ErrInscriptionNotOwned   = errors.New("inscription belongs to a different wallet")
+)
+
+// DeleteInscription removes a pending inscription and its uploaded image,
+// returning the removed record. authorize, when set, decides under the same
+// lock whether the caller may delete the record it found.
+func (s *InscriptionService) DeleteInscription(id string, authorize func(models.InscriptionRequest) bool) (*models.InscriptionRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inscriptions, err := s.loadInscriptions()
+	if err != nil {
+		return nil, err
+	}
+	idx := -1
+	for i, insc := range inscriptions {
+		if insc.ID == id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, ErrInscriptionNotFound
+	}
+	removed := inscriptions[idx]
+	if authorize != nil && !authorize(removed) {
+		return nil, ErrInscriptionNotOwned
+	}
+	if removed.Status != "pending" {
+		return nil, fmt.Errorf("%w: status is %q", ErrInscriptionNotPending, removed.Status)
+	}
+
+	if err := s.saveInscriptions(append(inscriptions[:idx:idx], inscriptions[idx+1:]...)); err != nil {
+		return nil, err
+	}
+	if removed.ImageData != "" {
+		// Only ever remove files inside the uploads directory.
+		imagePath := filepath.Join(os.Getenv("UPLOADS_DIR"), filepath.Base(removed.ImageData))
+		if err := os.Remove(imagePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove image %s for inscription %s: %v", imagePath, id, err)
+		}
+	}
+
+	log.Printf("Deleted pending inscription: %s", id)
+	return &removed, nil
+}
+
 // SearchInscriptions searches inscriptions by query
 func (s *InscriptionService) SearchInscriptions(query string) ([]models.InscriptionRequest, error) {
 	inscriptions, err := s.GetAllInscriptions()
@@ -146,24 +187,47 @@ func (s *InscriptionService) SearchInscriptions(query string) ([]models.Inscript
 	return results, nil
 }
 
-// saveInscriptions saves inscriptions to file
+// saveInscriptions saves inscriptions to the store
 func (s *InscriptionService) saveInscriptions(inscriptions []models.InscriptionRequest) error {
-	file, err := os.Create(s.inscriptionsFile)
+	return s.store.SaveInscriptions(inscriptions)
+}
+
+// Flush waits for in-flight writes and rewrites the inscriptions, so the
+// store holds a complete copy when it returns. Called during shutdown.
+func (s *InscriptionService) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inscriptions, err := s.loadInscriptions()
 	if err != nil {
-		return fmt.Errorf("failed to create inscriptions file: %w", err)
+		return err
 	}
-	defer file.Close()
+	return s.saveInscriptions(inscriptions)
+}
 
-	if err := json.NewEncoder(file).Encode(inscriptions); err != nil {
-		return fmt.Errorf("failed to encode inscriptions: %w", err)
-	}
+// DefaultBlockAPIBase is the upstream used when BLOCK_API_BASE is unset.
+const DefaultBlockAPIBase = "https://mempool.space/api"
+
+// blockCacheTTL bounds how long a successful upstream response is reused.
+const blockCacheTTL = 5 * time.Second
+
+// blockAPIBase is the mempool-compatible API root recent blocks are read from,
+// e.g. https://mempool.space/signet/api for signet deployments.
+var blockAPIBase = blockAPIBaseFromEnv()
 
-	return nil
+func blockAPIBaseFromEnv() string {
+	if base := strings.TrimRight(strings.TrimSpace(os.Getenv("BLOCK_API_BASE")), "/"); base != "" {
+		return base
+	}
+	return DefaultBlockAPIBase
 }
 
 // BlockService handles block-related business logic
 type BlockService struct {
 	client *http.Client
+
+	mu        sync.Mutex
+	cached    []interface{}
+	fetchedAt time.Time
 }
 
 // NewBlockService creates a new block service
@@ -173,13 +237,33 @@ func NewBlockService() *BlockService {
 	}
 }
 
-// GetBlocks retrieves recent blocks from mempool.space
-func (s *BlockService) GetBlocks() ([]interface{}, error) {
-	resp, err := s.client.Get("https://mempool.space/api/v1/blocks")
+// GetBlocks retrieves recent blocks from the configured block API
+func (s *BlockService) GetBlocks(ctx context.Context) ([]interface{}, error) {
+	return s.fetchRecentBlocks(ctx)
+}
+
+// fetchRecentBlocks returns the upstream's recent blocks, reusing the last
+// successful response for blockCacheTTL. Concurrent callers wait on a single
+// request rather than each hitting the upstream.
+func (s *BlockService) fetchRecentBlocks(ctx context.Context) ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cached != nil && time.Since(s.fetchedAt) < blockCacheTTL {
+		return s.cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, blockAPIBase+"/v1/blocks", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build blocks request: %w", err)
+	}
+	resp, err := s.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch blocks: %w", err)
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch blocks: upstream returned %s", resp.Status)
+	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -191,12 +275,14 @@ func (s *BlockService) GetBlocks() ([]interface{}, error) {
 		return nil, fmt.Errorf("failed to unmarshal blocks: %w", err)
 	}
 
+	s.cached = blocks
+	s.fetchedAt = time.Now()
 	return blocks, nil
 }
 
 // SearchBlocks searches blocks by query
-func (s *BlockService) SearchBlocks(query string) ([]interface{}, error) {
-	blocks, err := s.GetBlocks()
+func (s *BlockService) SearchBlocks(ctx context.Context, query string) ([]interface{}, error) {
+	blocks, err := s.fetchRecentBlocks(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -221,35 +307,23 @@ func (s *BlockService) SearchBlocks(query string) ([]interface{}, error) {
 
 // SmartContractService handles smart contract-related business logic
 type SmartContractService struct {
-	contractsFile string
-	mu            sync.RWMutex
+	store ContractStore
+	mu    sync.RWMutex
 }
 
-// NewSmartContractService creates a new smart contract service
+// NewSmartContractService creates a smart contract service backed by a JSON file.
 func NewSmartContractService(contractsFile string) *SmartContractService {
-	return &SmartContractService{
-		contractsFile: contractsFile,
-	}
+	return NewSmartContractServiceWithStore(NewFileContractStore(contractsFile))
 }
 
-// loadContracts loads contracts from file without locking
-func (s *SmartContractService) loadContracts() ([]core.SmartContractImage, error) {
-	var contracts []core.SmartContractImage
-
-	file, err := os.Open(s.contractsFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []core.SmartContractImage{}, nil
-		}
-		return nil, fmt.Errorf("failed to open contracts file: %w", err)
-	}
-	defer file.Close()
-
-	if err := json.NewDecoder(file).Decode(&contracts); err != nil {
-		return []core.SmartContractImage{}, fmt.Errorf("failed to decode contracts: %w", err)
-	}
+// NewSmartContractServiceWithStore creates a smart contract service on store.
+func NewSmartContractServiceWithStore(store ContractStore) *SmartContractService {
+	return &SmartContractService{store: store}
+}
 
-	return contracts, nil
+// loadContracts loads contracts from the store without locking
+func (s *SmartContractService) loadContracts() ([]core.SmartContractImage, error) {
+	return s.store.LoadContracts()
 }
 
 // GetAllContracts retrieves all smart contracts
@@ -311,22 +385,21 @@ func (s *SmartContractService) GetContractByID(contractID string) (*core.SmartCo
 	return nil, fmt.Errorf("contract not found: %s", contractID)
 }
 
-// saveContracts saves contracts to file
+// saveContracts saves contracts to the store
 func (s *SmartContractService) saveContracts(contracts []core.SmartContractImage) error {
-	if err := os.MkdirAll(filepath.Dir(s.contractsFile), 0755); err != nil {
-		return fmt.Errorf("failed to create contracts directory: %w", err)
-	}
-	file, err := os.Create(s.contractsFile)
-	if err != nil {
-		return fmt.Errorf("failed to create contracts file: %w", err)
-	}
-	defer file.Close()
+	return s.store.SaveContracts(contracts)
+}
 
-	if err := json.NewEncoder(file).Encode(contracts); err != nil {
-		return fmt.Errorf("failed to encode contracts: %w", err)
+// Flush waits for in-flight writes and rewrites the contracts, so the store
+// holds a complete copy when it returns. Called during shutdown.
+func (s *SmartContractService) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	contracts, err := s.loadContracts()
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return s.saveContracts(contracts)
 }
 
 // QRCodeService handles QR code generation
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFetchRecentBlocksUsesConfiguredBaseAndCaches(t *testing.T) {
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/signet/api/v1/blocks" {
+			http.NotFound(w, r)
+			return
+		}
+		hits.Add(1)
+		w.Write([]byte(`[{"id":"00000000abc","height":2001},{"id":"00000000def","height":2000}]`))
+	}))
+	defer upstream.Close()
+
+	prev := blockAPIBase
+	blockAPIBase = upstream.URL + "/signet/api"
+	t.Cleanup(func() { blockAPIBase = prev })
+
+	s := NewBlockService()
+	ctx := context.Background()
+	blocks, err := s.GetBlocks(ctx)
+	if err != nil || len(blocks) != 2 {
+		t.Fatalf("GetBlocks = %v, %v; want 2 blocks", blocks, err)
+	}
+	found, err := s.SearchBlocks(ctx, "2001")
+	if err != nil || len(found) != 1 {
+		t.Fatalf("SearchBlocks = %v, %v; want 1 block", found, err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("upstream hit %d times, want 1 while cached", n)
+	}
+
+	s.fetchedAt = s.fetchedAt.Add(-blockCacheTTL)
+	if _, err := s.GetBlocks(ctx); err != nil {
+		t.Fatalf("GetBlocks after expiry: %v", err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("upstream hit %d times after expiry, want 2", n)
+	}
+}
+
+func TestBlockAPIBaseFromEnv(t *testing.T) {
+	t.Setenv("BLOCK_API_BASE", "")
+	if got := blockAPIBaseFromEnv(); got != DefaultBlockAPIBase {
+		t.Fatalf("unset BLOCK_API_BASE = %q, want %q", got, DefaultBlockAPIBase)
+	}
+	t.Setenv("BLOCK_API_BASE", " https://mempool.space/testnet4/api/ ")
+	if got := blockAPIBaseFromEnv(); got != "https://mempool.space/testnet4/api" {
+		t.Fatalf("BLOCK_API_BASE = %q", got)
+	}
+}
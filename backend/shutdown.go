@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout bounds a graceful shutdown unless
+// STARGATE_SHUTDOWN_TIMEOUT (a Go duration) overrides it.
+const defaultShutdownTimeout = 30 * time.Second
+
+func shutdownTimeoutFromEnv() time.Duration {
+	return durationFromEnv("STARGATE_SHUTDOWN_TIMEOUT", defaultShutdownTimeout)
+}
+
+// durationFromEnv reads name as a Go duration such as "30s". Unset values
+// return def; unparsable or non-positive values are logged and replaced by def.
+func durationFromEnv(name string, def time.Duration) time.Duration {
+	raw := strings.TrimSpace(os.Getenv(name))
+	if raw == "" {
+		return def
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Printf("Ignoring invalid %s=%q, using %v", name, raw, def)
+		return def
+	}
+	return d
+}
+
+// shutdownStep is one component torn down after SIGINT/SIGTERM.
+type shutdownStep struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// serveUntilSignal runs serve until it fails or the process receives SIGINT
+// or SIGTERM, then runs steps in order under a single timeout. A failing step
+// is logged and the rest still run, so state is saved even when an earlier
+// step overran.
+func serveUntilSignal(serve func() error, timeout time.Duration, steps []shutdownStep) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- serve() }()
+
+	select {
+	case err := <-errCh:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutdown signal received, stopping within %v", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, step := range steps {
+		if err := step.run(shutdownCtx); err != nil {
+			log.Printf("Shutdown: %s: %v", step.name, err)
+		}
+	}
+	log.Println("Shutdown complete")
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"stargate-backend/services"
+)
+
+// fakeMonitor records the shutdown the server gives the block monitor.
+type fakeMonitor struct{ stopped atomic.Bool }
+
+func (m *fakeMonitor) Shutdown(context.Context) error {
+	m.stopped.Store(true)
+	return nil
+}
+
+func TestServeUntilSignalStopsMonitorAndSavesState(t *testing.T) {
+	dir := t.TempDir()
+	inscriptionsFile := filepath.Join(dir, "inscriptions.json")
+	contractsFile := filepath.Join(dir, "contracts", "smart_contracts.json")
+	inscriptions := services.NewInscriptionService(inscriptionsFile)
+	contracts := services.NewSmartContractService(contractsFile)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})}
+	monitor := &fakeMonitor{}
+	var order []string
+	step := func(name string, run func(context.Context) error) shutdownStep {
+		return shutdownStep{name, func(ctx context.Context) error {
+			order = append(order, name)
+			return run(ctx)
+		}}
+	}
+	steps := []shutdownStep{
+		step("http", srv.Shutdown),
+		step("monitor", monitor.Shutdown),
+		step("inscriptions", func(context.Context) error { return inscriptions.Flush() }),
+		step("contracts", func(context.Context) error { return contracts.Flush() }),
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- serveUntilSignal(func() error { return srv.Serve(ln) }, 5*time.Second, steps)
+	}()
+
+	// The signal handler is installed before serving starts, so once the
+	// server answers a SIGTERM is caught rather than killing the test.
+	url := "http://" + ln.Addr().String()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server never came up: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serveUntilSignal: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("serveUntilSignal did not return after SIGTERM")
+	}
+
+	if !monitor.stopped.Load() {
+		t.Fatal("block monitor was not stopped")
+	}
+	if got := len(order); got != 4 || order[0] != "http" || order[1] != "monitor" {
+		t.Fatalf("shutdown order = %v", order)
+	}
+	for _, path := range []string{inscriptionsFile, contractsFile} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("%s not saved: %v", path, err)
+		}
+		var list []json.RawMessage
+		if err := json.Unmarshal(data, &list); err != nil {
+			t.Fatalf("%s is not a complete JSON list: %v", path, err)
+		}
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Fatal("server still accepting connections after shutdown")
+	}
+}
+
+func TestShutdownTimeoutFromEnv(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":      defaultShutdownTimeout,
+		"45s":   45 * time.Second,
+		"0":     defaultShutdownTimeout,
+		"later": defaultShutdownTimeout,
+	}
+	for raw, want := range cases {
+		t.Setenv("STARGATE_SHUTDOWN_TIMEOUT", raw)
+		if got := shutdownTimeoutFromEnv(); got != want {
+			t.Errorf("STARGATE_SHUTDOWN_TIMEOUT=%q: %v, want %v", raw, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"encoding/json"
@@ -8,6 +9,7 @@ import (
 	"io"
 	"io/fs"
 	"log"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -32,6 +34,7 @@ import (
 	auth "stargate-backend/storage/auth"
 	scstore "stargate-backend/storage/smart_contract"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -214,7 +217,17 @@ func findImagePath(height string, filename string) (string, bool) {
 		baseDir = storage.DefaultPath("blocks")
 	}
 
-	// Try explicit directory pattern first (height_*)
+	// Prefer the directory recorded in the block index
+	if h, err := strconv.ParseInt(height, 10, 64); err == nil {
+		if dir, err := bitcoin.FindBlockDirectory(baseDir, h); err == nil {
+			path := filepath.Join(dir, "images", filename)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, true
+			}
+		}
+	}
+
+	// Try explicit directory pattern (height_*)
 	pattern := filepath.Join(baseDir, fmt.Sprintf("%s_*", height), "images", filename)
 	matches, err := filepath.Glob(pattern)
 	if err == nil && len(matches) > 0 {
@@ -230,6 +243,40 @@ func findImagePath(height string, filename string) (string, bool) {
 	return "", false
 }
 
+// validBlockImageName rejects block image names that could leave the images
+// directory: empty, dot entries, or anything with a path separator.
+func validBlockImageName(name string) bool {
+	if name == "" || name == "." || name == ".." {
+		return false
+	}
+	return !strings.ContainsAny(name, `/\`)
+}
+
+// handleBlockImageFile serves a stored block image with an explicit
+// Content-Type from bitcoin.ServableImage rather than ServeFile's sniffing,
+// which misreads images with leftover opcode prefixes. download=1 serves it
+// as an attachment under its original filename.
+func handleBlockImageFile(w http.ResponseWriter, r *http.Request, path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		http.Error(w, "Failed to read image", http.StatusInternalServerError)
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		http.Error(w, "Failed to read image", http.StatusInternalServerError)
+		return
+	}
+	name := filepath.Base(path)
+	contentType, body := bitcoin.ServableImage(name, data)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if r.URL.Query().Get("download") == "1" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	}
+	http.ServeContent(w, r, name, info.ModTime(), bytes.NewReader(body))
+}
+
 // initializeMCPComponents is now a thin compatibility wrapper around the
 // unified storage factory (Phase 7 cleanup).
 //
@@ -391,11 +438,8 @@ func main() {
 		}
 	}()
 
-	// Start HTTP server (includes MCP endpoints)
-	go runHTTPServer(store, apiKeyIssuer, apiKeyValidator, ingestionSvc, challengeStore, ipfsClient)
-
-	// Wait indefinitely
-	select {} // Block forever
+	// Start HTTP server (includes MCP endpoints); returns after a graceful shutdown.
+	runHTTPServer(store, apiKeyIssuer, apiKeyValidator, ingestionSvc, challengeStore, ipfsClient)
 }
 
 func runHTTPServer(store scmiddleware.Store, apiKeyIssuer auth.APIKeyIssuer, apiKeyValidator auth.APIKeyValidator, ingestionSvc *services.IngestionService, challengeStore *auth.ChallengeStore, ipfsClient *ipfs.Client) {
@@ -439,6 +483,11 @@ func runHTTPServer(store scmiddleware.Store, apiKeyIssuer auth.APIKeyIssuer, api
 	scannerManager := starlight.GetScannerManager()
 	httpMCPServer := mcp.NewHTTPMCPServer(store, apiKeyValidator, apiKeyIssuer, ingestionSvc, scannerManager, container.SmartContractService, challengeStore)
 
+	// One limiter shared by every API-triggered block scan: the REST scan
+	// endpoints and MCP scan_block_range.
+	scanLimiter := bitcoin.NewScanLimiterFromEnv()
+	httpMCPServer.SetScanLimiter(scanLimiter)
+
 	// Set the smart contract handler with the store
 	container.SetSmartContractHandler(store)
 	// Also allow inscription handler to mirror into MCP store
@@ -454,6 +503,7 @@ func runHTTPServer(store scmiddleware.Store, apiKeyIssuer auth.APIKeyIssuer, api
 	// Start built-in agent orchestrator (opt-in via STARGATE_AGENT_ENABLED).
 	// This brings the former Python starlight.agents orchestration logic into stargate.
 	agentCfg := agents.LoadConfig()
+	var agentOrch *agents.Orchestrator
 	if agentCfg.Enabled {
 		// Propagate executor config from LoadConfig into env so NewAutoDetectExecutor picks it up
 		if agentCfg.ExecutorTool != "" {
@@ -463,10 +513,8 @@ func runHTTPServer(store scmiddleware.Store, apiKeyIssuer auth.APIKeyIssuer, api
 			os.Setenv("STARGATE_AGENT_EXECUTOR_MODEL", agentCfg.ExecutorModel)
 		}
 
-		agentOrch := agents.NewOrchestrator(agentCfg, store, nil)
+		agentOrch = agents.NewOrchestrator(agentCfg, store, nil)
 		agentOrch.Start(context.Background())
-		// Note: orchestrator runs until process exit for now (matches other background services).
-		// If a full shutdown path is added later, call agentOrch.Stop() on termination.
 		log.Printf("Built-in agents enabled (watcher=%v, worker=%v, tool=%s, model=%s)",
 			agentCfg.WatcherEnabled, agentCfg.WorkerEnabled, agentCfg.ExecutorTool, agentCfg.ExecutorModel)
 	} else {
@@ -480,17 +528,29 @@ func runHTTPServer(store scmiddleware.Store, apiKeyIssuer auth.APIKeyIssuer, api
 	httpMCPServer.RegisterRoutes(mux)
 
 	// Apply middleware to all routes
-	routes, mcpRestServer := setupRoutes(mux, container, store, apiKeyIssuer, apiKeyValidator, challengeStore, ingestionSvc, &mirror, escort)
+	routes, mcpRestServer, blockMonitor := setupRoutes(mux, container, store, apiKeyIssuer, apiKeyValidator, challengeStore, ingestionSvc, &mirror, escort, scanLimiter)
 
 	// Set smart_contract server reference on MCP server (must be done after mcpRestServer is created)
 	httpMCPServer.SetServer(mcpRestServer)
+	if blockMonitor != nil {
+		httpMCPServer.SetBlockMonitor(blockMonitor)
+	}
+
+	// Block monitor progress for operators (GET /api/monitor/events).
+	monitorEvents := newMonitorEventHub()
+	if blockMonitor != nil {
+		blockMonitor.OnMonitorEvent(monitorEvents.broadcast)
+	}
+	mux.HandleFunc("/api/monitor/events", monitorEvents.handleEvents)
 
 	handler := middleware.Recovery(
-		middleware.Logging(
-			middleware.SecurityHeaders(
-				middleware.CORS(
-					middleware.Timeout(30 * time.Second)(routes),
-				)),
+		middleware.RequestID(
+			middleware.Logging(
+				middleware.SecurityHeaders(
+					middleware.CORS(
+						middleware.Timeout(30 * time.Second)(routes),
+					)),
+			),
 		),
 	)
 
@@ -500,8 +560,17 @@ func runHTTPServer(store scmiddleware.Store, apiKeyIssuer auth.APIKeyIssuer, api
 		httpPort = "3001"
 	}
 
-	log.Printf("Server starting on :%s", httpPort)
-	log.Printf("Frontend available at: http://localhost:%s", httpPort)
+	tlsConfig, err := loadTLSFromEnv()
+	if err != nil {
+		log.Fatalf("TLS configuration: %v", err)
+	}
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+
+	log.Printf("Server starting on :%s (%s)", httpPort, scheme)
+	log.Printf("Frontend available at: %s://localhost:%s", scheme, httpPort)
 	log.Printf("Stargate API endpoints at: http://localhost:%s/api/", httpPort)
 	log.Printf("Bitcoin steganography API at: http://localhost:%s/bitcoin/v1/", httpPort)
 	log.Printf("Smart contract steganography at: http://localhost:%s/api/contract-stego/", httpPort)
@@ -510,10 +579,59 @@ func runHTTPServer(store scmiddleware.Store, apiKeyIssuer auth.APIKeyIssuer, api
 	log.Printf("MCP HTTP calls at: http://localhost:%s/mcp/call", httpPort)
 	log.Printf("Proxy to steganography API (port 8080) at: http://localhost:%s/stego/", httpPort)
 
-	log.Fatal(http.ListenAndServe(":"+httpPort, handler))
+	srv := &http.Server{Addr: ":" + httpPort, Handler: handler, TLSConfig: tlsConfig}
+
+	// With TLS on, TLS_REDIRECT_HTTP_PORT optionally serves plain HTTP that
+	// redirects to the HTTPS listener.
+	var redirectSrv *http.Server
+	if redirectPort := strings.TrimSpace(os.Getenv("TLS_REDIRECT_HTTP_PORT")); tlsConfig != nil && redirectPort != "" {
+		redirectSrv = &http.Server{Addr: ":" + redirectPort, Handler: httpsRedirectHandler(httpPort)}
+		go func() {
+			log.Printf("Redirecting HTTP on :%s to HTTPS on :%s", redirectPort, httpPort)
+			if err := redirectSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("HTTP redirect server: %v", err)
+			}
+		}()
+	}
+
+	// Stop taking requests before stopping the monitor, then save state once
+	// nothing else can write it.
+	steps := []shutdownStep{
+		// Close event streams first; otherwise Shutdown waits on them until the deadline.
+		{"event streams", mcpRestServer.Shutdown},
+		{"monitor event streams", monitorEvents.Shutdown},
+	}
+	if redirectSrv != nil {
+		steps = append(steps, shutdownStep{"HTTP redirect server", redirectSrv.Shutdown})
+	}
+	steps = append(steps,
+		shutdownStep{"HTTP server", srv.Shutdown},
+		shutdownStep{"MCP rate limiter", func(context.Context) error {
+			httpMCPServer.Close()
+			return nil
+		}},
+		shutdownStep{"agents", func(context.Context) error {
+			if agentOrch != nil {
+				agentOrch.Stop()
+			}
+			return nil
+		}},
+		shutdownStep{"block monitor", blockMonitor.Shutdown},
+		shutdownStep{"inscriptions", func(context.Context) error { return container.InscriptionService.Flush() }},
+		shutdownStep{"smart contracts", func(context.Context) error { return container.SmartContractService.Flush() }},
+		shutdownStep{"record store", func(context.Context) error { return container.Close() }},
+	)
+	serve := srv.ListenAndServe
+	if tlsConfig != nil {
+		// The certificate is already in srv.TLSConfig.
+		serve = func() error { return srv.ListenAndServeTLS("", "") }
+	}
+	if err := serveUntilSignal(serve, shutdownTimeoutFromEnv(), steps); err != nil {
+		log.Fatal(err)
+	}
 }
 
-func setupRoutes(mux *http.ServeMux, container *container.Container, store scmiddleware.Store, apiKeyIssuer auth.APIKeyIssuer, apiKeyValidator auth.APIKeyValidator, challengeStore *auth.ChallengeStore, ingestionSvc *services.IngestionService, mirror *mirrorState, escort *smart_contract.EscortService) (http.Handler, *scmiddleware.Server) {
+func setupRoutes(mux *http.ServeMux, container *container.Container, store scmiddleware.Store, apiKeyIssuer auth.APIKeyIssuer, apiKeyValidator auth.APIKeyValidator, challengeStore *auth.ChallengeStore, ingestionSvc *services.IngestionService, mirror *mirrorState, escort *smart_contract.EscortService, scanLimiter *bitcoin.ScanLimiter) (http.Handler, *scmiddleware.Server, *bitcoin.BlockMonitor) {
 	// Initialize MCP REST server for HTTP routes
 	mcpRestServer := scmiddleware.NewServer(store, apiKeyValidator, ingestionSvc)
 	if escort != nil {
@@ -555,6 +673,7 @@ func setupRoutes(mux *http.ServeMux, container *container.Container, store scmid
 	mux.HandleFunc("/api/auth/logout", keyHandler.HandleLogout)
 	mux.HandleFunc("/api/auth/challenge", keyHandler.HandleChallenge)
 	mux.HandleFunc("/api/auth/verify", keyHandler.HandleVerify)
+	mux.HandleFunc("/api/auth/wallet", keyHandler.HandleWallet)
 
 	// Helper function to wrap handlers with auth
 	wrapWithAuth := func(h http.HandlerFunc) http.Handler {
@@ -581,6 +700,7 @@ func setupRoutes(mux *http.ServeMux, container *container.Container, store scmid
 	mux.HandleFunc("/api/inscriptions", container.InscriptionHandler.HandleGetInscriptions)
 	mux.Handle("/api/inscriptions/", wrapWithAuth(container.InscriptionHandler.HandleDeleteInscription))
 	mux.Handle("/api/inscribe", wrapWithAuth(container.InscriptionHandler.HandleCreateInscription))
+	mux.Handle("/api/pending-transactions/", wrapWithAuth(container.InscriptionHandler.HandleDeletePendingTransaction))
 
 	// Block endpoints
 	mux.HandleFunc("/api/blocks", container.BlockHandler.HandleGetBlocks)
@@ -692,6 +812,13 @@ func setupRoutes(mux *http.ServeMux, container *container.Container, store scmid
 	// All Store implementations (Memory, SQLite, PG) now satisfy bitcoin.SweepTaskStore
 	// because the required methods are part of the core Store interface (Phase 5).
 	blockMonitor.SetSweepDependencies(store, bitcoin.NewMempoolClient())
+	mcpRestServer.SetContractLinker(blockMonitor)
+	if err := blockMonitor.RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+		log.Printf("block monitor metrics disabled: %v", err)
+	}
+	if err := mcpRestServer.RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+		log.Printf("event stream metrics disabled: %v", err)
+	}
 	// OP_RETURN-based matching: block monitor discovers contracts during normal
 	// block processing — no event-driven reconciliation needed.
 	if err := scmiddleware.StartIPFSIngestionSync(context.Background(), ingestionSvc, store, func(ctx context.Context, recent int) error {
@@ -757,14 +884,21 @@ func setupRoutes(mux *http.ServeMux, container *container.Container, store scmid
 	}
 
 	mux.HandleFunc("/api/data/block/", dataAPI.HandleGetBlockData)
+	mux.HandleFunc("/api/block/", dataAPI.HandleGetRawBlock)
 	mux.HandleFunc("/api/data/blocks", dataAPI.HandleGetRecentBlocks)
 	mux.HandleFunc("/api/data/block-summaries", dataAPI.HandleGetBlockSummaries)
+	mux.HandleFunc("/api/data/smart-contracts", dataAPI.HandleGetSmartContracts)
 	mux.HandleFunc("/api/data/block-inscriptions/", dataAPI.HandleGetBlockInscriptionsPaginated)
 	mux.HandleFunc("/api/data/stats", dataAPI.HandleGetSteganographyStats)
+	statsHandler := handlers.NewStatsHandler(container.InscriptionService, container.SmartContractService, blockMonitor)
+	mux.HandleFunc("/api/stats", statsHandler.HandleGetStats)
+	mux.HandleFunc("/api/messages/search", dataAPI.HandleSearchMessages)
 	mux.HandleFunc("/api/data/updates", dataAPI.HandleRealtimeUpdates)
-	mux.HandleFunc("/api/data/scan", dataAPI.HandleScanBlockOnDemand)
+	mux.HandleFunc("/api/data/scan", scanLimiter.Limit(dataAPI.HandleScanBlockOnDemand))
+	mux.Handle("/api/data/backfill", wrapWithAuth(dataAPI.HandleBackfill))
 	mux.HandleFunc("/api/data/block-images", dataAPI.HandleGetBlockImages)
 	mux.HandleFunc("/api/block-images", dataAPI.HandleGetBlockImages)
+	mux.HandleFunc("/api/stego/recent", dataAPI.HandleGetRecentStegoDetections)
 	mux.HandleFunc("/api/stego/callback", dataAPI.HandleStegoCallback)
 	mux.HandleFunc("/content/", dataAPI.HandleContent)
 
@@ -779,40 +913,33 @@ func setupRoutes(mux *http.ServeMux, container *container.Container, store scmid
 
 		height := pathParts[0]
 		filename := pathParts[1]
+		h, ok := dataAPI.RequireBlockHeight(w, height)
+		if !ok {
+			return
+		}
+		if len(pathParts) > 2 || !validBlockImageName(filename) {
+			http.Error(w, "Invalid filename", http.StatusBadRequest)
+			return
+		}
 
 		// Try to locate the image on disk (blocks/<height>_<hash>/images/<filename>)
 		if fsPath, ok := findImagePath(height, filename); ok {
 			log.Printf("Serving image from filesystem: %s", fsPath)
-			if filepath.Ext(fsPath) == "" {
-				if file, err := os.Open(fsPath); err == nil {
-					defer file.Close()
-					buf := make([]byte, 512)
-					n, _ := file.Read(buf)
-					if n > 0 {
-						w.Header().Set("Content-Type", http.DetectContentType(buf[:n]))
-					}
-				}
-			}
-			http.ServeFile(w, r, fsPath)
+			handleBlockImageFile(w, r, fsPath)
 			return
 		}
 
 		// Fallback: check UPLOADS_DIR (images received via IPFS or local creation)
 		if uDir := os.Getenv("UPLOADS_DIR"); uDir != "" {
 			uploadPath := filepath.Join(uDir, filename)
-			if !strings.HasPrefix(filepath.Clean(uploadPath), filepath.Clean(uDir)) {
-				http.Error(w, "Invalid filename", http.StatusBadRequest)
-				return
-			}
 			if info, err := os.Stat(uploadPath); err == nil && !info.IsDir() {
 				log.Printf("Serving image from uploads fallback: %s", uploadPath)
-				http.ServeFile(w, r, uploadPath)
+				handleBlockImageFile(w, r, uploadPath)
 				return
 			}
 		}
 
 		// Fallback to Postgres storage: delegate to data API to build an in-memory response
-		h, _ := strconv.ParseInt(height, 10, 64)
 		if cache, err := dataStorage.GetBlockData(h); err == nil {
 			if block, ok := cache.(*storage.BlockDataCache); ok {
 				for _, img := range block.Images {
@@ -839,14 +966,15 @@ func setupRoutes(mux *http.ServeMux, container *container.Container, store scmid
 	mux.HandleFunc("/bitcoin/v1/info", bitcoinAPI.HandleInfo)
 	mux.HandleFunc("/bitcoin/v1/scan/transaction", bitcoinAPI.HandleScanTransaction)
 	mux.HandleFunc("/bitcoin/v1/scan/image", bitcoinAPI.HandleScanImage)
-	mux.HandleFunc("/bitcoin/v1/scan/block", bitcoinAPI.HandleBlockScan)
+	mux.HandleFunc("/bitcoin/v1/scan/block", scanLimiter.Limit(bitcoinAPI.HandleBlockScan))
 	mux.HandleFunc("/bitcoin/v1/extract", bitcoinAPI.HandleExtract)
 	mux.HandleFunc("/bitcoin/v1/transaction/", bitcoinAPI.HandleGetTransaction)
+	mux.Handle("/bitcoin/v1/rescan/image/", wrapWithAuth(dataAPI.HandleRescanImage))
 
 	// MCP tools are available via HTTP endpoints at /mcp/
 
 	log.Printf("All routes registered, returning handler")
-	return mux, mcpRestServer
+	return mux, mcpRestServer, blockMonitor
 }
 
 type mirrorState struct {
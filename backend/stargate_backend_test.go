@@ -3,6 +3,8 @@ package main
 import (
 	"bytes"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -58,3 +60,38 @@ func TestInitializeMCPComponentsFallsBackToMemoryWhenSQLiteInitFails(t *testing.
 		t.Fatalf("expected actual memory store log, got %q", logOutput)
 	}
 }
+
+func TestHandleBlockImageFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deadbeef.svg")
+	svg := `<svg xmlns="http://www.w3.org/2000/svg"></svg>`
+	if err := os.WriteFile(path, append([]byte{0x01, 0x4c}, svg...), 0644); err != nil {
+		t.Fatalf("write image: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	handleBlockImageFile(rec, httptest.NewRequest(http.MethodGet, "/api/block-image/100/deadbeef.svg", nil), path)
+	if ct := rec.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Fatalf("Content-Type = %q, want image/svg+xml", ct)
+	}
+	if rec.Body.String() != svg {
+		t.Fatalf("body = %q, want prefix stripped", rec.Body.String())
+	}
+	if cd := rec.Header().Get("Content-Disposition"); cd != "" {
+		t.Fatalf("Content-Disposition = %q without download=1", cd)
+	}
+
+	rec = httptest.NewRecorder()
+	handleBlockImageFile(rec, httptest.NewRequest(http.MethodGet, "/api/block-image/100/deadbeef.svg?download=1", nil), path)
+	if cd := rec.Header().Get("Content-Disposition"); cd != `attachment; filename=deadbeef.svg` {
+		t.Fatalf("Content-Disposition = %q", cd)
+	}
+
+	for _, name := range []string{"", ".", "..", `..\..\etc\passwd`, "a/b.png"} {
+		if validBlockImageName(name) {
+			t.Errorf("validBlockImageName(%q) = true", name)
+		}
+	}
+	if !validBlockImageName("deadbeef.png") {
+		t.Error("validBlockImageName rejected a plain name")
+	}
+}
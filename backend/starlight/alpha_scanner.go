@@ -1,16 +1,9 @@
 package starlight
 
 import (
-	"bytes"
 	"fmt"
-	"image"
-	_ "image/gif"
-	_ "image/jpeg"
-	_ "image/png"
 	"log"
 
-	_ "golang.org/x/image/bmp"
-	_ "golang.org/x/image/webp"
 	"stargate-backend/core"
 	"stargate-backend/stego"
 )
@@ -40,9 +33,9 @@ func (s *AlphaScanner) ScanImage(imageData []byte, options core.ScanOptions) (*c
 		return nil, fmt.Errorf("AlphaScanner not initialized")
 	}
 
-	img, _, err := image.Decode(bytes.NewReader(imageData))
+	img, _, err := DecodeImage(imageData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %v", err)
+		return nil, err
 	}
 
 	payload, err := stego.ExtractAlpha(img)
@@ -102,9 +95,9 @@ func (s *AlphaScanner) ExtractMessage(imageData []byte, method string) (*core.Ex
 		}, nil
 	}
 
-	img, _, err := image.Decode(bytes.NewReader(imageData))
+	img, _, err := DecodeImage(imageData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode image: %v", err)
+		return nil, err
 	}
 
 	payload, err := stego.ExtractAlpha(img)
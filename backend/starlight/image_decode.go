@@ -0,0 +1,40 @@
+package starlight
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+)
+
+// ErrUnsupportedFormat is returned for image data that matches no registered
+// decoder. Data in a known format that fails to decode is reported as a
+// decode error instead.
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// RegisterImageDecoder makes an additional image format available to the
+// scanners. PNG, JPEG, GIF, BMP and WebP are registered by default; see
+// image.RegisterFormat for the meaning of magic.
+func RegisterImageDecoder(name, magic string, decode func(io.Reader) (image.Image, error), decodeConfig func(io.Reader) (image.Config, error)) {
+	image.RegisterFormat(name, magic, decode, decodeConfig)
+}
+
+// DecodeImage decodes imageData with the registered decoders and returns the
+// image with its format name.
+func DecodeImage(imageData []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(imageData))
+	if errors.Is(err, image.ErrFormat) {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to decode %s image: %w", format, err)
+	}
+	return img, format, nil
+}
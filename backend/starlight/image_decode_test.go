@@ -0,0 +1,69 @@
+package starlight
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"stargate-backend/core"
+)
+
+// 1x1 lossless and lossy WebP images.
+const (
+	webpLossless = "UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA=="
+	webpLossy    = "UklGRiIAAABXRUJQVlA4IBYAAAAwAQCdASoBAAEADsD+JaQAA3AAAAAA"
+)
+
+func gifImage(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{color.Black, color.White})
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestAlphaScannerScansWebPAndGIF(t *testing.T) {
+	scanner := NewAlphaScanner()
+	if err := scanner.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	images := map[string][]byte{"gif": gifImage(t)}
+	for name, encoded := range map[string]string{"webp lossless": webpLossless, "webp lossy": webpLossy} {
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		images[name] = data
+	}
+	for name, data := range images {
+		result, err := scanner.ScanImage(data, core.ScanOptions{})
+		if err != nil {
+			t.Fatalf("%s: ScanImage: %v", name, err)
+		}
+		if result.Prediction != "clean" {
+			t.Fatalf("%s: prediction = %q, want clean", name, result.Prediction)
+		}
+	}
+}
+
+func TestDecodeImageUnsupportedFormat(t *testing.T) {
+	_, _, err := DecodeImage([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"/>"))
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("svg: err = %v, want ErrUnsupportedFormat", err)
+	}
+
+	// A truncated GIF is a known format that fails to decode, not an unsupported one.
+	_, format, err := DecodeImage(gifImage(t)[:12])
+	if err == nil || errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("truncated gif: err = %v, want a decode error", err)
+	}
+	if format != "gif" {
+		t.Fatalf("truncated gif: format = %q, want gif", format)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"stargate-backend/core"
@@ -17,7 +19,8 @@ type ProxyScanner struct {
 	apiURL      string
 	apiKey      string
 	client      *http.Client
-	initialized bool
+	initMu      sync.Mutex // serializes health checks
+	initialized atomic.Bool
 	maxRetries  int
 	retryDelay  time.Duration
 }
@@ -32,17 +35,23 @@ func NewProxyScanner(apiURL string, apiKey string) *ProxyScanner {
 	}
 
 	return &ProxyScanner{
-		apiURL:      apiURL,
-		apiKey:      apiKey,
-		client:      &http.Client{Timeout: 120 * time.Second},
-		initialized: false,
-		maxRetries:  3,
-		retryDelay:  1 * time.Second,
+		apiURL:     apiURL,
+		apiKey:     apiKey,
+		client:     &http.Client{Timeout: 120 * time.Second},
+		maxRetries: 3,
+		retryDelay: 1 * time.Second,
 	}
 }
 
-// Initialize initializes the proxy scanner by testing connection
+// Initialize initializes the proxy scanner by testing connection. Every
+// failure is reported as ErrScannerUnavailable.
 func (p *ProxyScanner) Initialize() error {
+	p.initMu.Lock()
+	defer p.initMu.Unlock()
+	if p.initialized.Load() {
+		return nil
+	}
+
 	// Test health endpoint
 	req, err := http.NewRequest("GET", p.apiURL+"/health", nil)
 	if err != nil {
@@ -53,35 +62,57 @@ func (p *ProxyScanner) Initialize() error {
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to connect to Python API: %w", err)
+		return unavailable(fmt.Errorf("failed to connect to Python API: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("Python API returned status %d", resp.StatusCode)
+		return unavailable(fmt.Errorf("Python API returned status %d", resp.StatusCode))
 	}
 
 	// Parse health response
 	var health map[string]any
 	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
-		return fmt.Errorf("failed to parse health response: %w", err)
+		return unavailable(fmt.Errorf("failed to parse health response: %w", err))
 	}
 
 	// Check if scanner is available
 	if scanner, ok := health["scanner"].(map[string]any); ok {
 		if modelLoaded, ok := scanner["model_loaded"].(bool); ok && modelLoaded {
-			p.initialized = true
+			p.initialized.Store(true)
 			return nil
 		}
 	}
 
-	return fmt.Errorf("Python API scanner not ready")
+	return unavailable(fmt.Errorf("Python API scanner not ready"))
+}
+
+// ensureInitialized runs the health check until it first passes.
+func (p *ProxyScanner) ensureInitialized() error {
+	if p.initialized.Load() {
+		return nil
+	}
+	if err := p.Initialize(); err != nil {
+		return fmt.Errorf("steganography scanner not available at %s: %w", p.apiURL, err)
+	}
+	return nil
+}
+
+// responseError describes a non-200 response; 5xx responses are reported as
+// ErrScannerUnavailable, anything else as a problem with the request.
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	err := fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	if resp.StatusCode >= 500 {
+		return unavailable(err)
+	}
+	return err
 }
 
 // ScanImage scans an image by proxying to Python API
 func (p *ProxyScanner) ScanImage(imageData []byte, options core.ScanOptions) (*core.ScanResult, error) {
-	if !p.initialized {
-		return nil, fmt.Errorf("steganography scanner not available - ensure Python backend is running on port 8080")
+	if err := p.ensureInitialized(); err != nil {
+		return nil, err
 	}
 
 	// Create multipart form
@@ -126,13 +157,12 @@ func (p *ProxyScanner) ScanImage(imageData []byte, options core.ScanOptions) (*c
 	// Send request
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, unavailable(fmt.Errorf("failed to send request: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, responseError(resp)
 	}
 
 	// Parse response
@@ -159,6 +189,7 @@ func (p *ProxyScanner) ScanImage(imageData []byte, options core.ScanOptions) (*c
 		if message, ok := extractionResult["message"].(string); ok && message != "" {
 			scanResult.ExtractedMessage = message
 		}
+		scanResult.Probabilities = parseProbabilities(extractionResult["probabilities"])
 
 		// Set prediction based on message_found
 		if scanResult.IsStego {
@@ -196,6 +227,7 @@ func (p *ProxyScanner) ScanImage(imageData []byte, options core.ScanOptions) (*c
 		if err, ok := scanData["extraction_error"].(string); ok && err != "" {
 			scanResult.ExtractionError = err
 		}
+		scanResult.Probabilities = parseProbabilities(scanData["probabilities"])
 	}
 
 	return scanResult, nil
@@ -206,8 +238,8 @@ func (p *ProxyScanner) ScanImage(imageData []byte, options core.ScanOptions) (*c
 // entire blocks in one request and automatically updates the inscriptions.json file.
 // However, we implement block scanning in the Go backend for architectural consistency.
 func (p *ProxyScanner) ScanBlock(blockHeight int64, options core.ScanOptions) (*core.BlockScanResponse, error) {
-	if !p.initialized {
-		return nil, fmt.Errorf("steganography scanner not available - ensure Python backend is running on port 8080")
+	if err := p.ensureInitialized(); err != nil {
+		return nil, err
 	}
 
 	// Create request payload
@@ -237,13 +269,12 @@ func (p *ProxyScanner) ScanBlock(blockHeight int64, options core.ScanOptions) (*
 	// Send request
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, unavailable(fmt.Errorf("failed to send request: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, responseError(resp)
 	}
 
 	// Parse response
@@ -257,8 +288,8 @@ func (p *ProxyScanner) ScanBlock(blockHeight int64, options core.ScanOptions) (*
 
 // ExtractMessage extracts message by proxying to Python API
 func (p *ProxyScanner) ExtractMessage(imageData []byte, method string) (*core.ExtractionResult, error) {
-	if !p.initialized {
-		return nil, fmt.Errorf("steganography scanner not available - ensure Python backend is running on port 8080")
+	if err := p.ensureInitialized(); err != nil {
+		return nil, err
 	}
 
 	// Create multipart form
@@ -295,13 +326,12 @@ func (p *ProxyScanner) ExtractMessage(imageData []byte, method string) (*core.Ex
 	// Send request
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, unavailable(fmt.Errorf("failed to send request: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		return nil, responseError(resp)
 	}
 
 	// Parse response
@@ -399,7 +429,7 @@ func (p *ProxyScanner) GetScannerInfo() core.ScannerInfo {
 	}
 
 	return core.ScannerInfo{
-		ModelLoaded:  p.initialized,
+		ModelLoaded:  p.initialized.Load(),
 		ModelVersion: "proxy-v1.0",
 		ModelPath:    "proxy-to-python-api",
 		Device:       "proxy",
@@ -408,7 +438,7 @@ func (p *ProxyScanner) GetScannerInfo() core.ScannerInfo {
 
 // IsInitialized returns initialization status
 func (p *ProxyScanner) IsInitialized() bool {
-	return p.initialized
+	return p.initialized.Load()
 }
 
 // doRequestWithRetry executes an HTTP request with exponential backoff retry logic
@@ -445,3 +475,22 @@ func (p *ProxyScanner) doRequestWithRetry(req *http.Request) (*http.Response, er
 
 	return nil, fmt.Errorf("request failed after %d attempts: %w", p.maxRetries+1, lastErr)
 }
+
+// parseProbabilities converts a decoded class -> probability object, skipping
+// non-numeric entries. It returns nil when the scanner reported none.
+func parseProbabilities(value interface{}) map[string]float64 {
+	raw, ok := value.(map[string]interface{})
+	if !ok || len(raw) == 0 {
+		return nil
+	}
+	probs := make(map[string]float64, len(raw))
+	for class, v := range raw {
+		if p, ok := v.(float64); ok {
+			probs[class] = p
+		}
+	}
+	if len(probs) == 0 {
+		return nil
+	}
+	return probs
+}
@@ -0,0 +1,56 @@
+package starlight
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultScannerBreakerFailures = 3
+	defaultScannerBreakerCooldown = 30 * time.Second
+)
+
+// proxyScannerFromEnv returns the out-of-process scanner configured by
+// STARGATE_SCANNER_PROXY_URL (and STARGATE_SCANNER_PROXY_API_KEY), or nil
+// when scans should run in-process.
+func proxyScannerFromEnv() *ProxyScanner {
+	url := strings.TrimSpace(os.Getenv("STARGATE_SCANNER_PROXY_URL"))
+	if url == "" {
+		return nil
+	}
+	return NewProxyScanner(strings.TrimRight(url, "/"), strings.TrimSpace(os.Getenv("STARGATE_SCANNER_PROXY_API_KEY")))
+}
+
+// scannerBreakerFailuresFromEnv returns how many consecutive scanner failures
+// open the circuit breaker. Controlled by STARGATE_SCANNER_BREAKER_FAILURES.
+func scannerBreakerFailuresFromEnv() int {
+	raw := strings.TrimSpace(os.Getenv("STARGATE_SCANNER_BREAKER_FAILURES"))
+	if raw == "" {
+		return defaultScannerBreakerFailures
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		log.Printf("Ignoring invalid STARGATE_SCANNER_BREAKER_FAILURES=%q, using %d", raw, defaultScannerBreakerFailures)
+		return defaultScannerBreakerFailures
+	}
+	return n
+}
+
+// scannerBreakerCooldownFromEnv returns how long an open breaker short-circuits
+// scans before letting a trial call through. Controlled by
+// STARGATE_SCANNER_BREAKER_COOLDOWN (Go duration, e.g. 1m).
+func scannerBreakerCooldownFromEnv() time.Duration {
+	raw := strings.TrimSpace(os.Getenv("STARGATE_SCANNER_BREAKER_COOLDOWN"))
+	if raw == "" {
+		return defaultScannerBreakerCooldown
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Printf("Ignoring invalid STARGATE_SCANNER_BREAKER_COOLDOWN=%q, using %v", raw, defaultScannerBreakerCooldown)
+		return defaultScannerBreakerCooldown
+	}
+	return d
+}
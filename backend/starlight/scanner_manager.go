@@ -1,6 +1,7 @@
 package starlight
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -28,6 +29,33 @@ type CircuitBreaker struct {
 	mutex       sync.RWMutex
 }
 
+// ErrCircuitOpen is returned while the circuit breaker is open; callers
+// should record the image as not scanned rather than as a scan failure.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// ErrScannerUnavailable marks failures to reach the scanner backend: transport
+// errors, 5xx responses and failed health checks. Only these count toward the
+// circuit breaker; an image the scanner cannot decode says nothing about its
+// health.
+var ErrScannerUnavailable = errors.New("scanner unavailable")
+
+// unavailableError tags err as ErrScannerUnavailable without changing its message.
+type unavailableError struct{ err error }
+
+func (e unavailableError) Error() string   { return e.err.Error() }
+func (e unavailableError) Unwrap() []error { return []error{ErrScannerUnavailable, e.err} }
+
+func unavailable(err error) error { return unavailableError{err: err} }
+
+// CircuitBreakerStatus is a point-in-time view of a CircuitBreaker.
+type CircuitBreakerStatus struct {
+	State       string     `json:"state"`
+	Failures    int        `json:"failures"`
+	MaxFailures int        `json:"max_failures"`
+	Cooldown    string     `json:"cooldown"`
+	RetryAt     *time.Time `json:"retry_at,omitempty"`
+}
+
 var (
 	globalScannerManager *ScannerManager
 	once                 sync.Once
@@ -37,7 +65,7 @@ var (
 func GetScannerManager() *ScannerManager {
 	once.Do(func() {
 		globalScannerManager = &ScannerManager{
-			circuitBreaker: NewCircuitBreaker(3, 30*time.Second),
+			circuitBreaker: NewCircuitBreaker(scannerBreakerFailuresFromEnv(), scannerBreakerCooldownFromEnv()),
 			initialized:    false,
 		}
 	})
@@ -54,7 +82,8 @@ func NewCircuitBreaker(maxFailures int, timeout time.Duration) *CircuitBreaker {
 	}
 }
 
-// InitializeScanner initializes the scanner with native AlphaScanner
+// InitializeScanner initializes the scanner: the out-of-process proxy when
+// STARGATE_SCANNER_PROXY_URL is set, otherwise the native AlphaScanner.
 func (sm *ScannerManager) InitializeScanner() error {
 	sm.mutex.Lock()
 	defer sm.mutex.Unlock()
@@ -63,6 +92,24 @@ func (sm *ScannerManager) InitializeScanner() error {
 		return nil
 	}
 
+	if sm.scanner == nil {
+		if proxy := proxyScannerFromEnv(); proxy != nil {
+			sm.scanner = proxy
+			sm.scannerType = "proxy"
+		}
+	}
+	if proxy, ok := sm.scanner.(*ProxyScanner); ok {
+		// Keep the proxy even if it is not up yet, but report the manager as
+		// initialized only once its health check passes. Each scan retries the
+		// check, and the circuit breaker bounds the cost meanwhile.
+		if err := proxy.Initialize(); err != nil {
+			return fmt.Errorf("proxy scanner at %s not ready: %w", proxy.apiURL, err)
+		}
+		sm.initialized = true
+		log.Printf("Initialized out-of-process proxy scanner (%s)", proxy.apiURL)
+		return nil
+	}
+
 	// Initialize native AlphaScanner
 	alphaScanner := NewAlphaScanner()
 	initErr := alphaScanner.Initialize()
@@ -84,44 +131,62 @@ func (sm *ScannerManager) InitializeScanner() error {
 	return nil
 }
 
-// ScanImage scans an image with circuit breaker protection
-func (sm *ScannerManager) ScanImage(imageData []byte, options core.ScanOptions) (*core.ScanResult, error) {
-	if !sm.initialized {
-		if err := sm.InitializeScanner(); err != nil {
-			return nil, fmt.Errorf("scanner not initialized: %v", err)
-		}
+// ensureInitialized initializes the scanner on first use and returns the
+// scanner to call.
+func (sm *ScannerManager) ensureInitialized() (core.StarlightScannerInterface, error) {
+	sm.mutex.RLock()
+	scanner, initialized := sm.scanner, sm.initialized
+	sm.mutex.RUnlock()
+	if initialized {
+		return scanner, nil
+	}
+	if err := sm.InitializeScanner(); err != nil {
+		return nil, fmt.Errorf("scanner not initialized: %w", err)
+	}
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	return sm.scanner, nil
+}
+
+// recordResult feeds the outcome of a scanner call to the circuit breaker.
+// Errors other than ErrScannerUnavailable, such as an undecodable image,
+// leave it untouched.
+func (sm *ScannerManager) recordResult(err error) {
+	switch {
+	case err == nil:
+		sm.circuitBreaker.RecordSuccess()
+	case errors.Is(err, ErrScannerUnavailable):
+		sm.circuitBreaker.RecordFailure()
 	}
+}
 
+// ScanImage scans an image with circuit breaker protection
+func (sm *ScannerManager) ScanImage(imageData []byte, options core.ScanOptions) (*core.ScanResult, error) {
 	if !sm.circuitBreaker.CanExecute() {
 		return &core.ScanResult{
 			IsStego:          false,
 			StegoProbability: 0.0,
 			Confidence:       0.0,
-			Prediction:       "circuit_breaker_open",
-		}, fmt.Errorf("circuit breaker open")
+			Prediction:       "not_scanned",
+		}, ErrCircuitOpen
 	}
 
-	result, err := sm.scanner.ScanImage(imageData, options)
+	scanner, err := sm.ensureInitialized()
 	if err != nil {
-		sm.circuitBreaker.RecordFailure()
+		sm.recordResult(err)
 		return nil, err
 	}
 
-	sm.circuitBreaker.RecordSuccess()
+	result, err := scanner.ScanImage(imageData, options)
+	sm.recordResult(err)
+	if err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
 // ScanBlock scans an entire block using the underlying scanner
 func (sm *ScannerManager) ScanBlock(blockHeight int64, options core.ScanOptions) (*core.BlockScanResponse, error) {
-	sm.mutex.Lock()
-	defer sm.mutex.Unlock()
-
-	if !sm.initialized {
-		if err := sm.InitializeScanner(); err != nil {
-			return nil, fmt.Errorf("scanner not initialized: %v", err)
-		}
-	}
-
 	if !sm.circuitBreaker.CanExecute() {
 		return &core.BlockScanResponse{
 			BlockHeight:       blockHeight,
@@ -133,16 +198,22 @@ func (sm *ScannerManager) ScanBlock(blockHeight int64, options core.ScanOptions)
 			ProcessingTimeMs:  0,
 			Inscriptions:      []core.BlockScanInscription{},
 			RequestID:         "circuit_breaker_open",
-		}, fmt.Errorf("circuit breaker open")
+		}, ErrCircuitOpen
 	}
 
-	result, err := sm.scanner.ScanBlock(blockHeight, options)
+	scanner, err := sm.ensureInitialized()
 	if err != nil {
-		sm.circuitBreaker.RecordFailure()
+		sm.recordResult(err)
 		return nil, err
 	}
 
-	sm.circuitBreaker.RecordSuccess()
+	sm.mutex.Lock()
+	result, err := scanner.ScanBlock(blockHeight, options)
+	sm.mutex.Unlock()
+	sm.recordResult(err)
+	if err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
@@ -158,19 +229,10 @@ func (sm *ScannerManager) GetHealthStatus() map[string]interface{} {
 	sm.mutex.RLock()
 	defer sm.mutex.RUnlock()
 
-	circuitBreakerStatus := map[string]interface{}{
-		"state":    "unknown",
-		"failures": 0,
-	}
-	if sm.circuitBreaker != nil {
-		circuitBreakerStatus["state"] = sm.circuitBreaker.GetState()
-		circuitBreakerStatus["failures"] = sm.circuitBreaker.GetFailures()
-	}
-
 	status := map[string]interface{}{
 		"initialized":     sm.initialized,
 		"scanner_type":    sm.scannerType,
-		"circuit_breaker": circuitBreakerStatus,
+		"circuit_breaker": sm.CircuitBreakerStatus(),
 	}
 
 	if sm.scanner != nil {
@@ -199,6 +261,14 @@ func (sm *ScannerManager) GetScannerInfo() core.ScannerInfo {
 	}
 }
 
+// CircuitBreakerStatus reports the state of the breaker guarding scanner calls.
+func (sm *ScannerManager) CircuitBreakerStatus() CircuitBreakerStatus {
+	if sm.circuitBreaker == nil {
+		return CircuitBreakerStatus{State: "unknown"}
+	}
+	return sm.circuitBreaker.Status()
+}
+
 // IsInitialized returns initialization status
 func (sm *ScannerManager) IsInitialized() bool {
 	sm.mutex.RLock()
@@ -208,28 +278,26 @@ func (sm *ScannerManager) IsInitialized() bool {
 
 // ExtractMessage extracts hidden message using underlying scanner
 func (sm *ScannerManager) ExtractMessage(imageData []byte, method string) (*core.ExtractionResult, error) {
-	if !sm.initialized {
-		if err := sm.InitializeScanner(); err != nil {
-			return nil, fmt.Errorf("scanner not initialized: %v", err)
-		}
-	}
-
 	if !sm.circuitBreaker.CanExecute() {
 		return &core.ExtractionResult{
 			MessageFound: false,
 			ExtractionDetails: map[string]interface{}{
 				"error": "circuit breaker open",
 			},
-		}, fmt.Errorf("circuit breaker open")
+		}, ErrCircuitOpen
 	}
 
-	result, err := sm.scanner.ExtractMessage(imageData, method)
+	scanner, err := sm.ensureInitialized()
 	if err != nil {
-		sm.circuitBreaker.RecordFailure()
+		sm.recordResult(err)
 		return nil, err
 	}
 
-	sm.circuitBreaker.RecordSuccess()
+	result, err := scanner.ExtractMessage(imageData, method)
+	sm.recordResult(err)
+	if err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
@@ -291,3 +359,22 @@ func (cb *CircuitBreaker) GetFailures() int {
 	defer cb.mutex.RUnlock()
 	return cb.failures
 }
+
+// Status returns the breaker's state, thresholds and, while open, when the
+// next call will be let through.
+func (cb *CircuitBreaker) Status() CircuitBreakerStatus {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+
+	status := CircuitBreakerStatus{
+		State:       cb.state,
+		Failures:    cb.failures,
+		MaxFailures: cb.maxFailures,
+		Cooldown:    cb.timeout.String(),
+	}
+	if cb.state == "open" {
+		retryAt := cb.lastFailure.Add(cb.timeout)
+		status.RetryAt = &retryAt
+	}
+	return status
+}
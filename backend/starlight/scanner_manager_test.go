@@ -0,0 +1,184 @@
+package starlight
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stargate-backend/core"
+)
+
+// flakyScanner fails ScanImage while failing is set and counts the calls that reach it.
+type flakyScanner struct {
+	MockStarlightScanner
+	failing bool
+	calls   int
+}
+
+func (s *flakyScanner) ScanImage(imageData []byte, options core.ScanOptions) (*core.ScanResult, error) {
+	s.calls++
+	if s.failing {
+		return nil, unavailable(errors.New("scanner unreachable"))
+	}
+	return &core.ScanResult{IsStego: true, Confidence: 0.9}, nil
+}
+
+func newBreakerTestManager(scanner core.StarlightScannerInterface, cooldown time.Duration) *ScannerManager {
+	return &ScannerManager{
+		scanner:        scanner,
+		scannerType:    "test",
+		initialized:    true,
+		circuitBreaker: NewCircuitBreaker(3, cooldown),
+	}
+}
+
+func TestScannerManagerBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	scanner := &flakyScanner{failing: true}
+	sm := newBreakerTestManager(scanner, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if _, err := sm.ScanImage([]byte{1}, core.ScanOptions{}); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call %d: err = %v, want scanner failure", i, err)
+		}
+	}
+	status := sm.CircuitBreakerStatus()
+	if status.State != "open" || status.Failures != 3 || status.RetryAt == nil {
+		t.Fatalf("status after 3 failures = %+v, want open with retry_at", status)
+	}
+
+	result, err := sm.ScanImage([]byte{1}, core.ScanOptions{})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if result == nil || result.Prediction != "not_scanned" {
+		t.Fatalf("result = %+v, want not_scanned", result)
+	}
+	if scanner.calls != 3 {
+		t.Fatalf("scanner called %d times, want 3: open breaker must short-circuit", scanner.calls)
+	}
+}
+
+func TestScannerManagerBreakerClosesAfterCooldown(t *testing.T) {
+	scanner := &flakyScanner{failing: true}
+	sm := newBreakerTestManager(scanner, 20*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		sm.ScanImage([]byte{1}, core.ScanOptions{})
+	}
+	if _, err := sm.ScanImage([]byte{1}, core.ScanOptions{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+
+	// A failing trial call after the cooldown reopens the breaker.
+	time.Sleep(30 * time.Millisecond)
+	if _, err := sm.ScanImage([]byte{1}, core.ScanOptions{}); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("trial call err = %v, want scanner failure", err)
+	}
+	if state := sm.CircuitBreakerStatus().State; state != "open" {
+		t.Fatalf("state after failed trial = %q, want open", state)
+	}
+
+	// A successful trial call closes it again.
+	scanner.failing = false
+	time.Sleep(30 * time.Millisecond)
+	result, err := sm.ScanImage([]byte{1}, core.ScanOptions{})
+	if err != nil || !result.IsStego {
+		t.Fatalf("trial call = %+v, %v; want a scan result", result, err)
+	}
+	status := sm.CircuitBreakerStatus()
+	if status.State != "closed" || status.Failures != 0 || status.RetryAt != nil {
+		t.Fatalf("status after recovery = %+v, want closed", status)
+	}
+}
+
+// badImageScanner rejects every image the way a decoder would.
+type badImageScanner struct {
+	MockStarlightScanner
+	err error
+}
+
+func (s *badImageScanner) ScanImage([]byte, core.ScanOptions) (*core.ScanResult, error) {
+	return nil, s.err
+}
+
+func TestScannerManagerBreakerIgnoresBadImages(t *testing.T) {
+	for _, err := range []error{ErrUnsupportedFormat, fmt.Errorf("failed to decode png image: %w", errors.New("bad checksum"))} {
+		sm := newBreakerTestManager(&badImageScanner{err: err}, time.Hour)
+		for i := 0; i < 10; i++ {
+			if _, got := sm.ScanImage([]byte{1}, core.ScanOptions{}); !errors.Is(got, err) {
+				t.Fatalf("call %d: err = %v, want %v", i, got, err)
+			}
+		}
+		if status := sm.CircuitBreakerStatus(); status.State != "closed" || status.Failures != 0 {
+			t.Fatalf("%v tripped the breaker: %+v", err, status)
+		}
+	}
+}
+
+func TestScannerManagerWaitsForProxyHealth(t *testing.T) {
+	var ready atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			json.NewEncoder(w).Encode(map[string]any{"scanner": map[string]any{"model_loaded": ready.Load()}})
+		case "/scan/image":
+			json.NewEncoder(w).Encode(map[string]any{"is_stego": true, "prediction": "stego"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("STARGATE_SCANNER_PROXY_URL", srv.URL)
+	sm := &ScannerManager{circuitBreaker: NewCircuitBreaker(100, time.Hour)}
+
+	if err := sm.InitializeScanner(); !errors.Is(err, ErrScannerUnavailable) {
+		t.Fatalf("init err = %v, want ErrScannerUnavailable", err)
+	}
+	if sm.IsInitialized() || sm.GetScannerType() != "proxy" {
+		t.Fatalf("unready proxy: initialized = %v, type = %q", sm.IsInitialized(), sm.GetScannerType())
+	}
+	if _, err := sm.ScanImage([]byte{1}, core.ScanOptions{}); !errors.Is(err, ErrScannerUnavailable) {
+		t.Fatalf("scan before ready: err = %v", err)
+	}
+
+	ready.Store(true)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result, err := sm.ScanImage([]byte{1}, core.ScanOptions{}); err != nil || !result.IsStego {
+				t.Errorf("scan after ready = %+v, %v", result, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if !sm.IsInitialized() {
+		t.Fatal("manager not initialized after the proxy became healthy")
+	}
+}
+
+func TestScannerBreakerConfigFromEnv(t *testing.T) {
+	t.Setenv("STARGATE_SCANNER_BREAKER_FAILURES", "5")
+	t.Setenv("STARGATE_SCANNER_BREAKER_COOLDOWN", "2m")
+	if got := scannerBreakerFailuresFromEnv(); got != 5 {
+		t.Fatalf("failures = %d, want 5", got)
+	}
+	if got := scannerBreakerCooldownFromEnv(); got != 2*time.Minute {
+		t.Fatalf("cooldown = %v, want 2m", got)
+	}
+
+	t.Setenv("STARGATE_SCANNER_BREAKER_FAILURES", "0")
+	t.Setenv("STARGATE_SCANNER_BREAKER_COOLDOWN", "soon")
+	if got := scannerBreakerFailuresFromEnv(); got != defaultScannerBreakerFailures {
+		t.Fatalf("failures = %d, want default", got)
+	}
+	if got := scannerBreakerCooldownFromEnv(); got != defaultScannerBreakerCooldown {
+		t.Fatalf("cooldown = %v, want default", got)
+	}
+}
@@ -58,10 +58,7 @@ func Inscribe(cover []byte, message string, method string) (*InscribeResult, err
 	}
 	stegoBytes := buf.Bytes()
 
-	// Calculate SHA256 hash of the stego image
-	hasher := sha256.New()
-	hasher.Write(stegoBytes)
-	hash := hex.EncodeToString(hasher.Sum(nil))
+	hash := VisiblePixelHash(stegoBytes)
 
 	return &InscribeResult{
 		ID:          hash,
@@ -71,6 +68,14 @@ func Inscribe(cover []byte, message string, method string) (*InscribeResult, err
 	}, nil
 }
 
+// VisiblePixelHash returns the visible_pixel_hash of an inscribed image: the
+// hex SHA-256 of its encoded bytes. Inscription, ingestion and PSBT commitment
+// all key a wish by this value, so it must be computed the same way everywhere.
+func VisiblePixelHash(image []byte) string {
+	sum := sha256.Sum256(image)
+	return hex.EncodeToString(sum[:])
+}
+
 // EmbedAlpha embeds a payload into the alpha channel of an image using LSB.
 // It follows the AI42 alpha algorithm: prefix + payload + null terminator.
 // Bit order: LSB-first (bit 0 to 7 of each byte).
@@ -0,0 +1,81 @@
+package stego
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+)
+
+// ErrNotImage is returned by CanonicalImage for data with no recognisable image.
+var ErrNotImage = errors.New("not a recognised image")
+
+// TrimToImageSignature slices b from the first known image header (PNG, JPEG,
+// WEBP, GIF, AVIF). Inscription payloads often carry envelope bytes before the
+// image; b is returned unchanged when no header is found.
+func TrimToImageSignature(b []byte) []byte {
+	if len(b) < 8 {
+		return b
+	}
+	sigs := [][]byte{
+		{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}, // PNG
+		{0xFF, 0xD8, 0xFF},       // JPEG
+		[]byte("RIFF"),           // WEBP (we also check WEBP marker)
+		{0x47, 0x49, 0x46, 0x38}, // GIF
+		[]byte("ftypavif"),       // AVIF (ISO BMFF)
+	}
+	for _, sig := range sigs {
+		if idx := bytes.Index(b, sig); idx >= 0 {
+			// For RIFF/WEBP, ensure WEBP marker exists.
+			if sig[0] == 'R' && len(b) >= idx+12 {
+				if !(b[idx+8] == 'W' && b[idx+9] == 'E' && b[idx+10] == 'B' && b[idx+11] == 'P') {
+					continue
+				}
+			}
+			if string(sig) == "ftypavif" {
+				// Ensure we return from the start of the ftyp box.
+				if idx >= 4 {
+					return b[idx-4:]
+				}
+			}
+			return b[idx:]
+		}
+	}
+	return b
+}
+
+// CanonicalImage returns the bytes a visible_pixel_hash is computed over:
+// data trimmed to its image header, as block reconciliation does, and checked
+// to decode. AVIF has no registered decoder, so its header alone is accepted.
+func CanonicalImage(data []byte) ([]byte, error) {
+	trimmed := TrimToImageSignature(data)
+	if _, _, err := image.DecodeConfig(bytes.NewReader(trimmed)); err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			if isAVIF(trimmed) {
+				return trimmed, nil
+			}
+			return nil, ErrNotImage
+		}
+		return nil, fmt.Errorf("invalid image: %w", err)
+	}
+	return trimmed, nil
+}
+
+// VisiblePixelHashBase64 decodes a base64 image, canonicalises it and returns
+// its visible_pixel_hash.
+func VisiblePixelHashBase64(imageBase64 string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(imageBase64)
+	if err != nil {
+		return "", err
+	}
+	canonical, err := CanonicalImage(data)
+	if err != nil {
+		return "", err
+	}
+	return VisiblePixelHash(canonical), nil
+}
+
+func isAVIF(b []byte) bool {
+	return len(b) >= 12 && string(b[4:12]) == "ftypavif"
+}
@@ -0,0 +1,69 @@
+package stego
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodeTestPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i)
+	}
+	img.Set(0, 0, color.NRGBA{R: 1, G: 2, B: 3, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCanonicalImage(t *testing.T) {
+	pngBytes := encodeTestPNG(t)
+	prefixed := append([]byte("ord\x01\x09image/png\x00"), pngBytes...)
+	avif := append([]byte{0, 0, 0, 0x1c}, []byte("ftypavif\x00\x00\x00\x00")...)
+
+	got, err := CanonicalImage(prefixed)
+	if err != nil || !bytes.Equal(got, pngBytes) {
+		t.Fatalf("CanonicalImage(prefixed png) = %d bytes, %v; want the png", len(got), err)
+	}
+	if got, err := CanonicalImage(pngBytes); err != nil || !bytes.Equal(got, pngBytes) {
+		t.Fatalf("CanonicalImage(png) changed a clean image: %v", err)
+	}
+	if got, err := CanonicalImage(append([]byte("xx"), avif...)); err != nil || !bytes.Equal(got, avif) {
+		t.Fatalf("CanonicalImage(avif) = %x, %v", got, err)
+	}
+
+	// A PNG signature followed by a cut-off header must not be hashed.
+	if _, err := CanonicalImage(pngBytes[:20]); err == nil || errors.Is(err, ErrNotImage) {
+		t.Fatalf("truncated png err = %v, want decode error", err)
+	}
+	if _, err := CanonicalImage([]byte("just some text, no image here")); !errors.Is(err, ErrNotImage) {
+		t.Fatalf("text err = %v, want ErrNotImage", err)
+	}
+}
+
+func TestVisiblePixelHashBase64(t *testing.T) {
+	pngBytes := encodeTestPNG(t)
+	prefixed := append([]byte("envelope"), pngBytes...)
+
+	got, err := VisiblePixelHashBase64(base64.StdEncoding.EncodeToString(prefixed))
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if want := VisiblePixelHash(pngBytes); got != want {
+		t.Fatalf("hash = %s, want hash of canonical png %s", got, want)
+	}
+	if _, err := VisiblePixelHashBase64("***"); err == nil {
+		t.Fatal("invalid base64 accepted")
+	}
+	if _, err := VisiblePixelHashBase64(base64.StdEncoding.EncodeToString([]byte("not an image"))); err == nil {
+		t.Fatal("non-image accepted")
+	}
+}
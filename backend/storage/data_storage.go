@@ -20,6 +20,7 @@ type DataStorage struct {
 	mu           sync.RWMutex
 	cache        map[int64]*BlockDataCache
 	cacheTimeout time.Duration
+	messages     *messageIndex
 }
 
 // ExtendedDataStorage includes the core interface plus helper methods used by APIs.
@@ -59,6 +60,7 @@ func NewDataStorage(dataDir string) *DataStorage {
 		dataDir:      dataDir,
 		cache:        make(map[int64]*BlockDataCache),
 		cacheTimeout: 30 * time.Minute,
+		messages:     newMessageIndex(),
 	}
 
 	// Create data directory if it doesn't exist
@@ -98,6 +100,7 @@ func (ds *DataStorage) StoreBlockData(blockResponse *bitcoin.BlockInscriptionsRe
 
 	// Update cache
 	ds.cache[blockResponse.BlockHeight] = cacheEntry
+	ds.messages.replace(blockResponse.BlockHeight, extractBlockMessages(blockResponse.BlockHeight, blockResponse.SmartContracts, scanResults))
 
 	// Save to file
 	if err := ds.saveBlockDataToFile(cacheEntry); err != nil {
@@ -409,12 +412,43 @@ func (ds *DataStorage) loadCache() {
 
 		// Store in cache
 		ds.cache[blockInfo.BlockHeight] = cacheEntry
+		ds.messages.replace(blockInfo.BlockHeight, extractBlockMessages(blockInfo.BlockHeight, contracts, nil))
 		loadedCount++
 	}
 
 	log.Printf("Loaded %d blocks into cache from blocks/ directory", loadedCount)
 }
 
+// SearchMessages returns extracted messages containing query, newest block first.
+// The index covers every block stored or loaded since startup, independent of cache expiry.
+func (ds *DataStorage) SearchMessages(query string, limit int) ([]MessageMatch, error) {
+	return ds.messages.search(query, limit), nil
+}
+
+// GetRecentDetections returns the most recent detections held in the cache.
+func (ds *DataStorage) GetRecentDetections(limit int) ([]bitcoin.SmartContractData, error) {
+	limit = normalizeDetectionLimit(limit)
+
+	ds.mu.RLock()
+	defer ds.mu.RUnlock()
+	blocks := make([]*BlockDataCache, 0, len(ds.cache))
+	for _, cached := range ds.cache {
+		if len(cached.SmartContracts) > 0 {
+			blocks = append(blocks, cached)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].BlockHeight > blocks[j].BlockHeight })
+
+	var out []bitcoin.SmartContractData
+	for _, block := range blocks {
+		out = append(out, blockDetections(block.BlockHeight, block.SmartContracts)...)
+		if len(out) >= limit {
+			return out[:limit], nil
+		}
+	}
+	return out, nil
+}
+
 // cleanOldCache removes expired cache entries but keeps at least 10 recent blocks
 func (ds *DataStorage) cleanOldCache() {
 	now := time.Now()
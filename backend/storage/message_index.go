@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"stargate-backend/bitcoin"
+)
+
+// DefaultMessageSearchLimit caps SearchMessages results when no limit is given.
+const DefaultMessageSearchLimit = 50
+
+// MaxMessageSearchLimit is the most results SearchMessages returns; larger
+// limits are clamped to it.
+const MaxMessageSearchLimit = 500
+
+// MessageMatch is one extracted stego message found by SearchMessages.
+type MessageMatch struct {
+	BlockHeight int64  `json:"block_height"`
+	TxID        string `json:"tx_id"`
+	Message     string `json:"message"`
+	ContractID  string `json:"contract_id,omitempty"`
+}
+
+// MessageSearcher is implemented by storages that index extracted messages
+// across processed blocks. Matches are case-insensitive substring hits ordered
+// by block height, newest first.
+type MessageSearcher interface {
+	SearchMessages(query string, limit int) ([]MessageMatch, error)
+}
+
+// extractBlockMessages collects the non-empty extracted messages of a block from
+// its scan results and smart contracts, de-duplicated by (tx_id, message).
+func extractBlockMessages(height int64, contracts []bitcoin.SmartContractData, scanResults []map[string]interface{}) []MessageMatch {
+	var out []MessageMatch
+	seen := make(map[string]bool)
+	add := func(txID, message, contractID string) {
+		message = strings.TrimSpace(message)
+		if message == "" {
+			return
+		}
+		key := txID + "\x00" + message
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		out = append(out, MessageMatch{BlockHeight: height, TxID: txID, Message: message, ContractID: contractID})
+	}
+
+	for _, contract := range contracts {
+		add(metaString(contract.Metadata, "tx_id"), metaString(contract.Metadata, "extracted_message"), contract.ContractID)
+	}
+	for _, result := range scanResults {
+		add(metaString(result, "tx_id"), metaString(result, "extracted_message"), "")
+	}
+	return out
+}
+
+func metaString(meta map[string]interface{}, key string) string {
+	if meta == nil {
+		return ""
+	}
+	switch v := meta[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// normalizeMessageLimit applies the default to an unset limit and clamps
+// larger ones to MaxMessageSearchLimit.
+func normalizeMessageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultMessageSearchLimit
+	}
+	return min(limit, MaxMessageSearchLimit)
+}
+
+// messageIndex is the in-memory index used by the filesystem DataStorage.
+type messageIndex struct {
+	mu       sync.RWMutex
+	byHeight map[int64][]MessageMatch
+}
+
+func newMessageIndex() *messageIndex {
+	return &messageIndex{byHeight: make(map[int64][]MessageMatch)}
+}
+
+// replace swaps the indexed messages for height, so reprocessing a block does
+// not leave stale entries behind.
+func (idx *messageIndex) replace(height int64, messages []MessageMatch) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if len(messages) == 0 {
+		delete(idx.byHeight, height)
+		return
+	}
+	idx.byHeight[height] = messages
+}
+
+func (idx *messageIndex) search(query string, limit int) []MessageMatch {
+	needle := strings.ToLower(strings.TrimSpace(query))
+	if needle == "" {
+		return nil
+	}
+	limit = normalizeMessageLimit(limit)
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	heights := make([]int64, 0, len(idx.byHeight))
+	for h := range idx.byHeight {
+		heights = append(heights, h)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+
+	var out []MessageMatch
+	for _, h := range heights {
+		for _, m := range idx.byHeight[h] {
+			if strings.Contains(strings.ToLower(m.Message), needle) {
+				out = append(out, m)
+				if len(out) == limit {
+					return out
+				}
+			}
+		}
+	}
+	return out
+}
+
+// likePattern escapes LIKE wildcards in query and wraps it for substring matching.
+func likePattern(query string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(strings.TrimSpace(query)) + "%"
+}
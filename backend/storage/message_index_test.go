@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"stargate-backend/bitcoin"
+)
+
+func storeMessageBlock(t *testing.T, ds bitcoin.DataStorageInterface, height int64, txID, message string) {
+	t.Helper()
+	resp := &bitcoin.BlockInscriptionsResponse{
+		BlockHeight: height,
+		BlockHash:   "00000000000000000000000000000000000000000000000000000000000000aa",
+		Success:     true,
+		SmartContracts: []bitcoin.SmartContractData{{
+			ContractID:  "stego_" + txID,
+			BlockHeight: height,
+			Metadata:    map[string]any{"tx_id": txID, "extracted_message": message},
+		}},
+	}
+	scan := []map[string]interface{}{{"tx_id": txID, "is_stego": true, "extracted_message": message}}
+	if err := ds.StoreBlockData(resp, scan); err != nil {
+		t.Fatalf("store block %d: %v", height, err)
+	}
+}
+
+func TestSearchMessagesAcrossBlocks(t *testing.T) {
+	sqlite, err := NewSQLiteDataStorage(filepath.Join(t.TempDir(), "blocks.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Close()
+
+	backends := map[string]interface {
+		bitcoin.DataStorageInterface
+		MessageSearcher
+	}{
+		"filesystem": NewDataStorage(t.TempDir()),
+		"sqlite":     sqlite,
+	}
+
+	for name, ds := range backends {
+		t.Run(name, func(t *testing.T) {
+			storeMessageBlock(t, ds, 100, "tx100", "meet at the Old Oak tree")
+			storeMessageBlock(t, ds, 205, "tx205", "the old oak has fallen")
+			storeMessageBlock(t, ds, 150, "tx150", "unrelated 100% note")
+
+			matches, err := ds.SearchMessages("old oak", 10)
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			if len(matches) != 2 {
+				t.Fatalf("got %d matches, want 2: %+v", len(matches), matches)
+			}
+			if matches[0].BlockHeight != 205 || matches[0].TxID != "tx205" || matches[1].BlockHeight != 100 {
+				t.Fatalf("matches not ranked by recency: %+v", matches)
+			}
+			if matches[0].ContractID != "stego_tx205" {
+				t.Fatalf("contract id = %q", matches[0].ContractID)
+			}
+
+			// LIKE wildcards in the query are literal.
+			if got, _ := ds.SearchMessages("0%", 10); len(got) != 1 || got[0].TxID != "tx150" {
+				t.Fatalf("wildcard search = %+v", got)
+			}
+
+			// Reprocessing a block replaces its entries.
+			storeMessageBlock(t, ds, 205, "tx205", "nothing to see")
+			if got, _ := ds.SearchMessages("old oak", 10); len(got) != 1 || got[0].BlockHeight != 100 {
+				t.Fatalf("after reprocess = %+v", got)
+			}
+		})
+	}
+}
+
+func TestNormalizeMessageLimit(t *testing.T) {
+	for _, tc := range []struct{ in, want int }{
+		{0, DefaultMessageSearchLimit},
+		{-3, DefaultMessageSearchLimit},
+		{10, 10},
+		{MaxMessageSearchLimit, MaxMessageSearchLimit},
+		{MaxMessageSearchLimit + 1, MaxMessageSearchLimit},
+		{100000, MaxMessageSearchLimit},
+	} {
+		if got := normalizeMessageLimit(tc.in); got != tc.want {
+			t.Fatalf("normalizeMessageLimit(%d) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"regexp"
+	"strings"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -71,7 +72,16 @@ CREATE TABLE IF NOT EXISTS %s (
 CREATE INDEX IF NOT EXISTS %s_block_hash_idx ON %s (block_hash);
 CREATE INDEX IF NOT EXISTS %s_scanned_at_idx ON %s (scanned_at);
 CREATE INDEX IF NOT EXISTS %s_payload_idx ON %s USING GIN (payload jsonb_path_ops);
-`, ps.tableName, ps.tableName, ps.tableName, ps.tableName, ps.tableName, ps.tableName, ps.tableName)
+CREATE TABLE IF NOT EXISTS %s_messages (
+    block_height BIGINT NOT NULL,
+    tx_id        TEXT NOT NULL DEFAULT '',
+    message      TEXT NOT NULL,
+    contract_id  TEXT NOT NULL DEFAULT '',
+    position     INT NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS %s_messages_height_idx ON %s_messages (block_height DESC);
+`, ps.tableName, ps.tableName, ps.tableName, ps.tableName, ps.tableName, ps.tableName, ps.tableName,
+		ps.tableName, ps.tableName, ps.tableName)
 
 	if _, err := ps.db.ExecContext(ctx, schema); err != nil {
 		return fmt.Errorf("failed to ensure schema: %w", err)
@@ -126,9 +136,69 @@ ON CONFLICT (block_height) DO UPDATE SET
 		return nil
 	}
 
+	if err := ps.indexMessages(blockResponse.BlockHeight, extractBlockMessages(blockResponse.BlockHeight, blockResponse.SmartContracts, scanResults)); err != nil {
+		log.Printf("warning: failed to index messages for %d: %v", blockResponse.BlockHeight, err)
+	}
+
 	return nil
 }
 
+// indexMessages replaces the indexed messages for height.
+func (ps *PostgresStorage) indexMessages(height int64, messages []MessageMatch) error {
+	tx, err := ps.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s_messages WHERE block_height = $1`, ps.tableName), height); err != nil {
+		return err
+	}
+	insert := fmt.Sprintf(`INSERT INTO %s_messages (block_height, tx_id, message, contract_id, position) VALUES ($1, $2, $3, $4, $5)`, ps.tableName)
+	for i, m := range messages {
+		if _, err := tx.Exec(insert, m.BlockHeight, m.TxID, m.Message, m.ContractID, i); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchMessages returns extracted messages containing query, newest block first.
+func (ps *PostgresStorage) SearchMessages(query string, limit int) ([]MessageMatch, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	q := fmt.Sprintf(`SELECT block_height, tx_id, message, contract_id FROM %s_messages
+WHERE message ILIKE $1 ESCAPE '\' ORDER BY block_height DESC, position ASC LIMIT $2`, ps.tableName)
+	rows, err := ps.db.Query(q, likePattern(query), normalizeMessageLimit(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search messages: %w", err)
+	}
+	defer rows.Close()
+
+	var out []MessageMatch
+	for rows.Next() {
+		var m MessageMatch
+		if err := rows.Scan(&m.BlockHeight, &m.TxID, &m.Message, &m.ContractID); err != nil {
+			return nil, fmt.Errorf("failed to scan message row: %w", err)
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// GetRecentDetections returns the most recent detections across stored blocks.
+func (ps *PostgresStorage) GetRecentDetections(limit int) ([]bitcoin.SmartContractData, error) {
+	limit = normalizeDetectionLimit(limit)
+	q := fmt.Sprintf(`SELECT block_height, payload->'smart_contracts' FROM %s
+WHERE jsonb_array_length(CASE WHEN jsonb_typeof(payload->'smart_contracts') = 'array' THEN payload->'smart_contracts' ELSE '[]'::jsonb END) > 0
+ORDER BY block_height DESC`, ps.tableName)
+	rows, err := ps.db.Query(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query detections: %w", err)
+	}
+	return collectDetections(rows, limit)
+}
+
 // sanitizeInscriptions removes inline content to avoid JSON escape issues in Postgres.
 func sanitizeInscriptions(inscriptions []bitcoin.InscriptionData) []bitcoin.InscriptionData {
 	out := make([]bitcoin.InscriptionData, len(inscriptions))
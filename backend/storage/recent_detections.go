@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+
+	"stargate-backend/bitcoin"
+)
+
+// DefaultRecentDetectionsLimit caps GetRecentDetections results when no limit is given.
+const DefaultRecentDetectionsLimit = 20
+
+// maxRecentDetectionsLimit is the largest page GetRecentDetections returns.
+const maxRecentDetectionsLimit = 500
+
+// RecentDetectionLister is implemented by storages that can list the stego
+// detections (smart contracts) recorded across processed blocks, ordered by
+// block height and, within a block, first-seen time, newest first.
+type RecentDetectionLister interface {
+	GetRecentDetections(limit int) ([]bitcoin.SmartContractData, error)
+}
+
+// normalizeDetectionLimit applies the default and an upper bound to a limit.
+func normalizeDetectionLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultRecentDetectionsLimit
+	}
+	if limit > maxRecentDetectionsLimit {
+		return maxRecentDetectionsLimit
+	}
+	return limit
+}
+
+// blockDetections returns a block's contracts newest first, filling in the
+// block height for entries recorded without one.
+func blockDetections(height int64, contracts []bitcoin.SmartContractData) []bitcoin.SmartContractData {
+	out := make([]bitcoin.SmartContractData, len(contracts))
+	copy(out, contracts)
+	for i := range out {
+		if out[i].BlockHeight == 0 {
+			out[i].BlockHeight = height
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].FirstSeenAt > out[j].FirstSeenAt })
+	return out
+}
+
+// collectDetections reads (block_height, smart_contracts JSON) rows, newest
+// block first, until limit detections are collected.
+func collectDetections(rows *sql.Rows, limit int) ([]bitcoin.SmartContractData, error) {
+	defer rows.Close()
+
+	var out []bitcoin.SmartContractData
+	for rows.Next() {
+		var height int64
+		var raw []byte
+		if err := rows.Scan(&height, &raw); err != nil {
+			return nil, fmt.Errorf("failed to scan detection row: %w", err)
+		}
+		var contracts []bitcoin.SmartContractData
+		if err := json.Unmarshal(raw, &contracts); err != nil {
+			log.Printf("failed to unmarshal smart contracts for block %d: %v", height, err)
+			continue
+		}
+		out = append(out, blockDetections(height, contracts)...)
+		if len(out) >= limit {
+			return out[:limit], nil
+		}
+	}
+	return out, rows.Err()
+}
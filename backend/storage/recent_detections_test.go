@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"stargate-backend/bitcoin"
+)
+
+func TestGetRecentDetectionsOrdersByRecency(t *testing.T) {
+	sqlite, err := NewSQLiteDataStorage(filepath.Join(t.TempDir(), "blocks.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlite.Close()
+
+	backends := map[string]interface {
+		bitcoin.DataStorageInterface
+		RecentDetectionLister
+	}{
+		"filesystem": NewDataStorage(t.TempDir()),
+		"sqlite":     sqlite,
+	}
+
+	blocks := map[int64][]bitcoin.SmartContractData{
+		300: {
+			{ContractID: "early", Confidence: 0.8, FirstSeenAt: 1000},
+			{ContractID: "late", Confidence: 0.9, FirstSeenAt: 2000},
+		},
+		310: {{ContractID: "newest", BlockHeight: 310, Confidence: 0.7, FirstSeenAt: 1500}},
+		320: nil,
+	}
+	for name, ds := range backends {
+		t.Run(name, func(t *testing.T) {
+			for height, contracts := range blocks {
+				resp := &bitcoin.BlockInscriptionsResponse{BlockHeight: height, BlockHash: "abc", SmartContracts: contracts, Success: true}
+				if err := ds.StoreBlockData(resp, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := ds.GetRecentDetections(10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []string{"newest", "late", "early"}
+			if len(got) != len(want) {
+				t.Fatalf("got %d detections, want %d: %+v", len(got), len(want), got)
+			}
+			for i, id := range want {
+				if got[i].ContractID != id {
+					t.Fatalf("detection %d = %q, want %q (%+v)", i, got[i].ContractID, id, got)
+				}
+			}
+			if got[1].BlockHeight != 300 || got[1].Confidence != 0.9 {
+				t.Fatalf("detection lost its block height or confidence: %+v", got[1])
+			}
+
+			if got, _ := ds.GetRecentDetections(2); len(got) != 2 || got[1].ContractID != "late" {
+				t.Fatalf("limit 2 = %+v", got)
+			}
+		})
+	}
+}
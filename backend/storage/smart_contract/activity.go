@@ -0,0 +1,139 @@
+package smart_contract
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"stargate-backend/core/smart_contract"
+)
+
+// Last activity is bumped on claims, submissions, reviews and funding proof
+// updates. A task's activity also counts as activity on its contract, so a
+// contract nobody touches stays at its creation time.
+
+type pgExecer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+type sqliteExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// touchActivityPG records activity at `at` on taskID and its contract.
+func touchActivityPG(ctx context.Context, db pgExecer, taskID string, at time.Time) error {
+	if _, err := db.Exec(ctx, `UPDATE mcp_tasks SET last_activity_at=$2 WHERE task_id=$1`, taskID, at); err != nil {
+		return err
+	}
+	_, err := db.Exec(ctx, `
+UPDATE mcp_contracts SET last_activity_at=$2
+WHERE contract_id=(SELECT contract_id FROM mcp_tasks WHERE task_id=$1)`, taskID, at)
+	return err
+}
+
+// touchActivitySQLite is touchActivityPG for SQLite.
+func touchActivitySQLite(ctx context.Context, db sqliteExecer, taskID string, at time.Time) error {
+	ts := at.UTC().Format(time.RFC3339Nano)
+	if _, err := db.ExecContext(ctx, `UPDATE mcp_tasks SET last_activity_at=? WHERE task_id=?`, ts, taskID); err != nil {
+		return err
+	}
+	_, err := db.ExecContext(ctx, `
+UPDATE mcp_contracts SET last_activity_at=?
+WHERE contract_id=(SELECT contract_id FROM mcp_tasks WHERE task_id=?)`, ts, taskID)
+	return err
+}
+
+// touchActivity records activity on a task and its contract. Callers hold s.mu.
+func (s *MemoryStore) touchActivity(taskID string, at time.Time) {
+	t, ok := s.tasks[taskID]
+	if !ok {
+		return
+	}
+	t.LastActivityAt = &at
+	s.tasks[taskID] = t
+	if c, ok := s.contracts[t.ContractID]; ok {
+		c.LastActivityAt = &at
+		s.contracts[t.ContractID] = c
+	}
+}
+
+// contractLastActivity falls back to CreatedAt for contracts with no task activity.
+func contractLastActivity(c smart_contract.Contract) time.Time {
+	if c.LastActivityAt != nil {
+		return *c.LastActivityAt
+	}
+	return c.CreatedAt
+}
+
+// contractInactiveSince reports whether c has had no activity since cutoff.
+func contractInactiveSince(c smart_contract.Contract, cutoff time.Time) bool {
+	return contractLastActivity(c).Before(cutoff)
+}
+
+// taskInactiveSince reports whether t has had no activity since cutoff. Tasks
+// that were never touched count as inactive.
+func taskInactiveSince(t smart_contract.Task, cutoff time.Time) bool {
+	return t.LastActivityAt == nil || t.LastActivityAt.Before(cutoff)
+}
+
+// sortContractsByLastActivity orders contracts least recently active first.
+func sortContractsByLastActivity(out []smart_contract.Contract) {
+	sort.SliceStable(out, func(i, j int) bool {
+		ai, aj := contractLastActivity(out[i]), contractLastActivity(out[j])
+		if !ai.Equal(aj) {
+			return ai.Before(aj)
+		}
+		return out[i].ContractID < out[j].ContractID
+	})
+}
+
+// sortTasksByLastActivity orders tasks never active first, then least recently active.
+func sortTasksByLastActivity(out []smart_contract.Task) {
+	sort.SliceStable(out, func(i, j int) bool {
+		ai, aj := out[i].LastActivityAt, out[j].LastActivityAt
+		switch {
+		case ai == nil && aj == nil:
+			return out[i].TaskID < out[j].TaskID
+		case ai == nil:
+			return true
+		case aj == nil:
+			return false
+		case !ai.Equal(*aj):
+			return ai.Before(*aj)
+		}
+		return out[i].TaskID < out[j].TaskID
+	})
+}
+
+// pgContractInactiveCondition filters contracts aliased c on a cutoff bound at $arg.
+func pgContractInactiveCondition(arg int) string {
+	return fmt.Sprintf("COALESCE(c.last_activity_at, c.created_at) < $%d", arg)
+}
+
+// sqliteActivityArg binds an optional cutoff for the SQLite conditions below;
+// nil disables the task filter.
+func sqliteActivityArg(cutoff *time.Time) any {
+	if cutoff == nil {
+		return nil
+	}
+	return cutoff.UTC().Format(time.RFC3339Nano)
+}
+
+// SQLite timestamps are text in more than one layout; julianday normalises them.
+// The task conditions take the cutoff twice (or once for PG) and pass when it is NULL.
+const (
+	sqliteContractInactiveCondition = "julianday(COALESCE(c.last_activity_at, c.created_at)) < julianday(?)"
+	sqliteTaskInactiveCondition     = "(? IS NULL OR last_activity_at IS NULL OR julianday(last_activity_at) < julianday(?))"
+	pgContractActivityOrder         = "ORDER BY COALESCE(c.last_activity_at, c.created_at) ASC, c.contract_id ASC"
+	sqliteContractActivityOrder     = "ORDER BY julianday(COALESCE(c.last_activity_at, c.created_at)) ASC, c.contract_id ASC"
+	pgTaskActivityOrder             = "ORDER BY last_activity_at ASC NULLS FIRST, task_id ASC"
+	sqliteTaskActivityOrder         = "ORDER BY julianday(last_activity_at) ASC, task_id ASC"
+)
+
+// pgTaskInactiveCondition filters mcp_tasks rows on a nullable cutoff bound at $arg.
+func pgTaskInactiveCondition(arg int) string {
+	return fmt.Sprintf("($%[1]d::timestamptz IS NULL OR last_activity_at IS NULL OR last_activity_at < $%[1]d)", arg)
+}
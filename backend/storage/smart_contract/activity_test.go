@@ -0,0 +1,103 @@
+package smart_contract
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+func TestLastActivityAdvancesOnClaimAndFilters(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	created := time.Now().Add(-48 * time.Hour)
+	for name, store := range map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			for id, taskIDs := range map[string][]string{
+				"activity-stale": {"activity-stale-1", "activity-stale-2"},
+				"activity-busy":  {"activity-busy-1"},
+			} {
+				var tasks []smart_contract.Task
+				for _, taskID := range taskIDs {
+					tasks = append(tasks, smart_contract.Task{TaskID: taskID, Title: taskID, Status: "available"})
+				}
+				c := smart_contract.Contract{ContractID: id, Title: id, Status: "active", CreatedAt: created}
+				if err := store.UpsertContractWithTasks(ctx, c, tasks); err != nil {
+					t.Fatalf("upsert %s: %v", id, err)
+				}
+			}
+
+			before, err := store.GetTask("activity-busy-1")
+			if err != nil {
+				t.Fatalf("get task: %v", err)
+			}
+			if before.LastActivityAt != nil {
+				t.Fatalf("untouched task has LastActivityAt %v", before.LastActivityAt)
+			}
+			cutoff := time.Now().Add(-time.Second)
+			if _, err := store.ClaimTask("activity-busy-1", "tb1qactivity", nil); err != nil {
+				t.Fatalf("claim: %v", err)
+			}
+			task, err := store.GetTask("activity-busy-1")
+			if err != nil {
+				t.Fatalf("get task: %v", err)
+			}
+			if task.LastActivityAt == nil || task.LastActivityAt.Before(cutoff) {
+				t.Fatalf("task LastActivityAt = %v, want after %v", task.LastActivityAt, cutoff)
+			}
+			contract, err := store.GetContract("activity-busy")
+			if err != nil {
+				t.Fatalf("get contract: %v", err)
+			}
+			if contract.LastActivityAt == nil || contract.LastActivityAt.Before(cutoff) {
+				t.Fatalf("contract LastActivityAt = %v, want after %v", contract.LastActivityAt, cutoff)
+			}
+
+			for contractID, want := range map[string]int{"activity-stale": 2, "activity-busy": 0} {
+				filter := smart_contract.TaskFilter{ContractID: contractID, InactiveSince: &cutoff}
+				tasks, err := store.ListTasks(filter)
+				if err != nil {
+					t.Fatalf("list tasks: %v", err)
+				}
+				n, err := store.CountTasks(filter)
+				if err != nil {
+					t.Fatalf("count tasks: %v", err)
+				}
+				if len(tasks) != want || n != want {
+					t.Fatalf("%s inactive tasks: listed %d, counted %d, want %d", contractID, len(tasks), n, want)
+				}
+			}
+
+			contracts, err := store.ListContracts(smart_contract.ContractFilter{InactiveSince: &cutoff, OrderByLastActivity: true})
+			if err != nil {
+				t.Fatalf("list contracts: %v", err)
+			}
+			found := map[string]bool{}
+			for _, c := range contracts {
+				found[c.ContractID] = true
+			}
+			if !found["activity-stale"] || found["activity-busy"] {
+				t.Fatalf("inactive contracts = %v, want activity-stale without activity-busy", found)
+			}
+			n, err := store.CountContracts(smart_contract.ContractFilter{InactiveSince: &cutoff})
+			if err != nil || n != len(contracts) {
+				t.Fatalf("CountContracts = %d, %v; want %d", n, err, len(contracts))
+			}
+
+			ordered, err := store.ListTasks(smart_contract.TaskFilter{OrderByLastActivity: true})
+			if err != nil {
+				t.Fatalf("list tasks by activity: %v", err)
+			}
+			if len(ordered) == 0 || ordered[len(ordered)-1].TaskID != "activity-busy-1" {
+				t.Fatalf("most recently active task should sort last, got %+v", ordered)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package smart_contract
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// ClaimDetails is a claim as returned by claim lookups: the stored claim, the
+// wallet that holds it, and the submission made under it if there is one.
+type ClaimDetails struct {
+	smart_contract.Claim
+	Wallet     string                     `json:"wallet"`
+	Expired    bool                       `json:"expired"`
+	Submission *smart_contract.Submission `json:"submission,omitempty"`
+}
+
+// LookupClaim loads a claim by ID with its latest submission. Claims record
+// the claiming wallet as their AI identifier, so Wallet mirrors it. Expired
+// reports an active claim whose expiry has passed but has not been swept yet.
+func LookupClaim(ctx context.Context, store Store, claimID string, now time.Time) (ClaimDetails, error) {
+	claim, err := store.GetClaim(claimID)
+	if err != nil {
+		return ClaimDetails{}, err
+	}
+	details := ClaimDetails{
+		Claim:   claim,
+		Wallet:  claim.AiIdentifier,
+		Expired: claim.Status == "expired" || (claim.Status == "active" && !claim.ExpiresAt.IsZero() && now.After(claim.ExpiresAt)),
+	}
+	if claim.TaskID == "" {
+		return details, nil
+	}
+
+	subs, err := store.ListSubmissions(ctx, []string{claim.TaskID})
+	if err != nil {
+		return ClaimDetails{}, err
+	}
+	for i := range subs {
+		if subs[i].ClaimID != claimID {
+			continue
+		}
+		if details.Submission == nil || subs[i].CreatedAt.After(details.Submission.CreatedAt) {
+			sub := subs[i]
+			details.Submission = &sub
+		}
+	}
+	return details, nil
+}
+
+// ClaimHeldBy reports whether wallet is the one that claimed claim. Wallets
+// are compared case-insensitively, as everywhere else claims are matched.
+func ClaimHeldBy(claim smart_contract.Claim, wallet string) bool {
+	wallet = strings.TrimSpace(wallet)
+	return wallet != "" && strings.EqualFold(strings.TrimSpace(claim.AiIdentifier), wallet)
+}
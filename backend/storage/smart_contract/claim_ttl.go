@@ -0,0 +1,90 @@
+package smart_contract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClaimTTLMetadataKey is the contract metadata key that overrides the store's
+// global claim TTL for every task in the contract. The value is in hours.
+const ClaimTTLMetadataKey = "claim_ttl_hours"
+
+// MaxClaimTTL caps per-contract claim windows so a task cannot be locked up
+// indefinitely by a single claimant.
+const MaxClaimTTL = 30 * 24 * time.Hour
+
+// parseClaimTTL reads ClaimTTLMetadataKey from metadata. ok is false when
+// the key is absent.
+func parseClaimTTL(meta map[string]interface{}) (ttl time.Duration, ok bool, err error) {
+	raw, present := meta[ClaimTTLMetadataKey]
+	if !present || raw == nil {
+		return 0, false, nil
+	}
+	var hours float64
+	switch v := raw.(type) {
+	case float64:
+		hours = v
+	case int:
+		hours = float64(v)
+	case int64:
+		hours = float64(v)
+	case json.Number:
+		hours, err = v.Float64()
+	case string:
+		hours, err = strconv.ParseFloat(strings.TrimSpace(v), 64)
+	default:
+		err = fmt.Errorf("unsupported type %T", raw)
+	}
+	if err != nil {
+		return 0, true, fmt.Errorf("%w: %v", ErrInvalidClaimTTL, err)
+	}
+	ttl = time.Duration(hours * float64(time.Hour))
+	if ttl <= 0 || ttl > MaxClaimTTL {
+		return 0, true, fmt.Errorf("%w: %v hours must be between 0 and %v", ErrInvalidClaimTTL, hours, MaxClaimTTL.Hours())
+	}
+	return ttl, true, nil
+}
+
+// ValidateClaimTTL checks the claim TTL override in contract metadata, if any.
+func ValidateClaimTTL(meta map[string]interface{}) error {
+	_, _, err := parseClaimTTL(meta)
+	return err
+}
+
+// ContractClaimTTL returns the claim TTL for a contract with the given
+// metadata, falling back to def when no valid override is set.
+func ContractClaimTTL(meta map[string]interface{}, def time.Duration) time.Duration {
+	if ttl, ok, err := parseClaimTTL(meta); ok && err == nil {
+		return ttl
+	}
+	return def
+}
+
+// claimTTLPatch returns a JSON object holding only the claim TTL override from
+// meta, or "{}" when there is none. The SQL stores merge it into the stored
+// contract metadata on upsert, so an override set after the contract was
+// first written still takes effect without clobbering other keys.
+func claimTTLPatch(meta map[string]interface{}) string {
+	raw, ok := meta[ClaimTTLMetadataKey]
+	if !ok || raw == nil {
+		return "{}"
+	}
+	b, err := json.Marshal(map[string]interface{}{ClaimTTLMetadataKey: raw})
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// ClaimExpirer is implemented by stores that can release expired claims in
+// bulk. Claims are otherwise only treated as expired when their task is next
+// claimed or submitted against.
+type ClaimExpirer interface {
+	// ExpireClaims marks active claims that expired before now as "expired",
+	// returns their tasks to "available" and reports how many were expired.
+	ExpireClaims(ctx context.Context, now time.Time) (int, error)
+}
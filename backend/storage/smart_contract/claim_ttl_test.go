@@ -0,0 +1,149 @@
+package smart_contract
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+func TestClaimTaskUsesContractClaimTTL(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			contract := smart_contract.Contract{
+				ContractID: "ttl-contract",
+				Title:      "Long running",
+				Status:     "active",
+				Metadata:   map[string]interface{}{ClaimTTLMetadataKey: 48},
+			}
+			task := smart_contract.Task{TaskID: "ttl-task", Title: "Complex", Status: "available"}
+			if err := store.UpsertContractWithTasks(ctx, contract, []smart_contract.Task{task}); err != nil {
+				t.Fatalf("upsert: %v", err)
+			}
+
+			before := time.Now()
+			custom, err := store.ClaimTask(task.TaskID, "wallet-1", nil)
+			if err != nil {
+				t.Fatalf("claim custom task: %v", err)
+			}
+			global, err := store.ClaimTask(SeedTaskBollingerID, "wallet-1", nil)
+			if err != nil {
+				t.Fatalf("claim seeded task: %v", err)
+			}
+			if got := custom.ExpiresAt.Sub(before); got < 47*time.Hour || got > 49*time.Hour {
+				t.Fatalf("custom claim expires in %v, want ~48h", got)
+			}
+			if got := global.ExpiresAt.Sub(before); got < 30*time.Minute || got > 2*time.Hour {
+				t.Fatalf("default claim expires in %v, want ~1h", got)
+			}
+			if !custom.ExpiresAt.After(global.ExpiresAt) {
+				t.Fatalf("custom expiry %v not after default %v", custom.ExpiresAt, global.ExpiresAt)
+			}
+
+			for _, bad := range []interface{}{0, -2, "soon", MaxClaimTTL.Hours() + 1} {
+				contract.ContractID = "ttl-bad"
+				contract.Metadata = map[string]interface{}{ClaimTTLMetadataKey: bad}
+				if err := store.UpsertContractWithTasks(ctx, contract, nil); !errors.Is(err, ErrInvalidClaimTTL) {
+					t.Fatalf("claim_ttl_hours %v: err = %v, want ErrInvalidClaimTTL", bad, err)
+				}
+			}
+		})
+	}
+}
+
+func TestClaimTTLAppliedToExistingContract(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			contract := smart_contract.Contract{ContractID: "ttl-later", Title: "Later", Status: "active"}
+			task := smart_contract.Task{TaskID: "ttl-later-task", Title: "Later", Status: "available"}
+			if err := store.UpsertContractWithTasks(ctx, contract, []smart_contract.Task{task}); err != nil {
+				t.Fatalf("first upsert: %v", err)
+			}
+			contract.Metadata = map[string]interface{}{ClaimTTLMetadataKey: 24}
+			if err := store.UpsertContractWithTasks(ctx, contract, []smart_contract.Task{task}); err != nil {
+				t.Fatalf("second upsert: %v", err)
+			}
+
+			before := time.Now()
+			claim, err := store.ClaimTask(task.TaskID, "wallet-1", nil)
+			if err != nil {
+				t.Fatalf("claim: %v", err)
+			}
+			if got := claim.ExpiresAt.Sub(before); got < 23*time.Hour || got > 25*time.Hour {
+				t.Fatalf("claim expires in %v, want ~24h", got)
+			}
+		})
+	}
+}
+
+func TestValidateProposalInputRejectsBadClaimTTL(t *testing.T) {
+	proposal := smart_contract.Proposal{
+		ID:       "proposal-ttl",
+		Title:    "TTL",
+		Metadata: map[string]interface{}{ClaimTTLMetadataKey: "soon", "visible_pixel_hash": strings.Repeat("a", 64)},
+	}
+	if err := ValidateProposalInput(&proposal); !errors.Is(err, ErrInvalidClaimTTL) {
+		t.Fatalf("err = %v, want ErrInvalidClaimTTL", err)
+	}
+	proposal.Metadata[ClaimTTLMetadataKey] = 72
+	if err := ValidateProposalInput(&proposal); err != nil {
+		t.Fatalf("valid ttl rejected: %v", err)
+	}
+}
+
+func TestExpireClaimsReleasesTasks(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			claim, err := store.ClaimTask(SeedTaskBollingerID, "wallet-1", nil)
+			if err != nil {
+				t.Fatalf("claim: %v", err)
+			}
+			expirer := store.(ClaimExpirer)
+
+			if n, err := expirer.ExpireClaims(ctx, time.Now()); err != nil || n != 0 {
+				t.Fatalf("ExpireClaims before expiry = %d, %v; want 0", n, err)
+			}
+			if n, err := expirer.ExpireClaims(ctx, claim.ExpiresAt.Add(time.Minute)); err != nil || n != 1 {
+				t.Fatalf("ExpireClaims after expiry = %d, %v; want 1", n, err)
+			}
+			task, err := store.GetTask(SeedTaskBollingerID)
+			if err != nil {
+				t.Fatalf("get task: %v", err)
+			}
+			if task.Status != "available" || task.ClaimedBy != "" {
+				t.Fatalf("task after expiry = %s claimed by %q, want available", task.Status, task.ClaimedBy)
+			}
+			if n, _ := expirer.ExpireClaims(ctx, claim.ExpiresAt.Add(time.Minute)); n != 0 {
+				t.Fatalf("second sweep expired %d claims, want 0", n)
+			}
+			if _, err := store.ClaimTask(SeedTaskBollingerID, "wallet-2", nil); err != nil {
+				t.Fatalf("reclaim after expiry: %v", err)
+			}
+		})
+	}
+}
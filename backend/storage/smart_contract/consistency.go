@@ -0,0 +1,46 @@
+package smart_contract
+
+import "context"
+
+// OrphanTask is a task whose contract_id does not resolve to a contract.
+type OrphanTask struct {
+	TaskID     string `json:"task_id"`
+	ContractID string `json:"contract_id"`
+}
+
+// OrphanSubmission is a submission whose claim, or the claim's task, is missing.
+type OrphanSubmission struct {
+	SubmissionID string `json:"submission_id"`
+	ClaimID      string `json:"claim_id"`
+	TaskID       string `json:"task_id,omitempty"`
+	Status       string `json:"status"`
+}
+
+// ConsistencyReport lists records that reference rows which no longer exist.
+type ConsistencyReport struct {
+	OrphanTasks       []OrphanTask       `json:"orphan_tasks"`
+	OrphanSubmissions []OrphanSubmission `json:"orphan_submissions"`
+}
+
+// ConsistencyChecker is implemented by stores that can scan for dangling
+// references between contracts, tasks, claims and submissions.
+type ConsistencyChecker interface {
+	CheckConsistency(ctx context.Context) (ConsistencyReport, error)
+}
+
+// Both SQL backends share these queries; they use no dialect-specific syntax.
+const (
+	orphanTasksQuery = `
+SELECT t.task_id, COALESCE(t.contract_id, '')
+FROM mcp_tasks t
+LEFT JOIN mcp_contracts c ON c.contract_id = t.contract_id
+WHERE c.contract_id IS NULL
+ORDER BY t.task_id`
+	orphanSubmissionsQuery = `
+SELECT s.submission_id, COALESCE(s.claim_id, ''), COALESCE(c.task_id, ''), s.status
+FROM mcp_submissions s
+LEFT JOIN mcp_claims c ON c.claim_id = s.claim_id
+LEFT JOIN mcp_tasks t ON t.task_id = c.task_id
+WHERE c.claim_id IS NULL OR t.task_id IS NULL
+ORDER BY s.submission_id`
+)
@@ -0,0 +1,195 @@
+package smart_contract
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+func TestUpsertContractWithTasksRejectsUnknownContract(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, false)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			contract := smart_contract.Contract{ContractID: "ref-contract", Title: "Ref", Status: "active"}
+			tasks := []smart_contract.Task{
+				{TaskID: "ref-task-1", Title: "Defaulted", Status: "available"},
+				{TaskID: "ref-task-2", ContractID: "ghost-contract", Title: "Dangling", Status: "available"},
+			}
+			err := store.UpsertContractWithTasks(ctx, contract, tasks)
+			if !errors.Is(err, ErrUnknownContract) {
+				t.Fatalf("err = %v, want ErrUnknownContract", err)
+			}
+			if _, err := store.GetTask("ref-task-1"); err == nil {
+				t.Fatalf("rejected upsert still stored a task")
+			}
+
+			if err := store.UpsertContractWithTasks(ctx, contract, tasks[:1]); err != nil {
+				t.Fatalf("upsert: %v", err)
+			}
+			task, err := store.GetTask("ref-task-1")
+			if err != nil {
+				t.Fatalf("get task: %v", err)
+			}
+			if task.ContractID != contract.ContractID {
+				t.Fatalf("contract_id = %q, want %q", task.ContractID, contract.ContractID)
+			}
+		})
+	}
+}
+
+func TestCheckConsistencyFindsOrphans(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+	memoryStore := NewMemoryStore(time.Hour)
+
+	stores := map[string]struct {
+		store          Store
+		deleteContract func(id string)
+		deleteClaim    func(id string)
+	}{
+		"memory": {
+			store: memoryStore,
+			deleteContract: func(id string) {
+				memoryStore.mu.Lock()
+				delete(memoryStore.contracts, id)
+				memoryStore.mu.Unlock()
+			},
+			deleteClaim: func(id string) {
+				memoryStore.mu.Lock()
+				delete(memoryStore.claims, id)
+				memoryStore.mu.Unlock()
+			},
+		},
+		"sqlite": {
+			store: sqliteStore,
+			deleteContract: func(id string) {
+				if _, err := sqliteStore.db.Exec(`DELETE FROM mcp_contracts WHERE contract_id=?`, id); err != nil {
+					t.Fatalf("delete contract: %v", err)
+				}
+			},
+			deleteClaim: func(id string) {
+				if _, err := sqliteStore.db.Exec(`DELETE FROM mcp_claims WHERE claim_id=?`, id); err != nil {
+					t.Fatalf("delete claim: %v", err)
+				}
+			},
+		},
+	}
+	for name, tc := range stores {
+		t.Run(name, func(t *testing.T) {
+			checker := tc.store.(ConsistencyChecker)
+			report, err := checker.CheckConsistency(ctx)
+			if err != nil {
+				t.Fatalf("check seeded store: %v", err)
+			}
+			if len(report.OrphanTasks) != 0 || len(report.OrphanSubmissions) != 0 {
+				t.Fatalf("seeded store reported orphans: %+v", report)
+			}
+
+			task, err := tc.store.GetTask(SeedTaskBollingerID)
+			if err != nil {
+				t.Fatalf("get seeded task: %v", err)
+			}
+			claim, err := tc.store.ClaimTask(task.TaskID, "wallet-1", nil)
+			if err != nil {
+				t.Fatalf("claim: %v", err)
+			}
+			sub, err := tc.store.SubmitWork(claim.ClaimID, map[string]interface{}{"notes": "done"}, nil)
+			if err != nil {
+				t.Fatalf("submit: %v", err)
+			}
+			tc.deleteContract(task.ContractID)
+			tc.deleteClaim(claim.ClaimID)
+
+			report, err = checker.CheckConsistency(ctx)
+			if err != nil {
+				t.Fatalf("check: %v", err)
+			}
+			found := false
+			for _, o := range report.OrphanTasks {
+				if o.TaskID == task.TaskID && o.ContractID == task.ContractID {
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("orphan tasks %+v missing %s", report.OrphanTasks, task.TaskID)
+			}
+			if len(report.OrphanSubmissions) != 1 || report.OrphanSubmissions[0].SubmissionID != sub.SubmissionID {
+				t.Fatalf("orphan submissions = %+v, want %s", report.OrphanSubmissions, sub.SubmissionID)
+			}
+		})
+	}
+}
+
+func TestTasksNeverDangleAfterUpsertOrPublish(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, false)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+	memoryStore := NewMemoryStore(time.Hour)
+
+	stores := map[string]struct {
+		store          Store
+		deleteContract func(id string)
+	}{
+		"memory": {memoryStore, func(id string) {
+			memoryStore.mu.Lock()
+			delete(memoryStore.contracts, id)
+			memoryStore.mu.Unlock()
+		}},
+		"sqlite": {sqliteStore, func(id string) {
+			if _, err := sqliteStore.db.Exec(`DELETE FROM mcp_contracts WHERE contract_id=?`, id); err != nil {
+				t.Fatalf("delete contract: %v", err)
+			}
+		}},
+	}
+	for name, tc := range stores {
+		t.Run(name, func(t *testing.T) {
+			contractID := strings.Repeat("b", 64)
+			if err := tc.store.UpsertTask(ctx, smart_contract.Task{TaskID: "dangle-task", ContractID: contractID, Title: "Synced early", Status: "claimed"}); err != nil {
+				t.Fatalf("upsert task: %v", err)
+			}
+			placeholder, err := tc.store.GetContract(contractID)
+			if err != nil || placeholder.Status != "pending" {
+				t.Fatalf("placeholder after UpsertTask = %+v, %v", placeholder, err)
+			}
+
+			if err := tc.store.CreateProposal(ctx, smart_contract.Proposal{
+				ID:               contractID,
+				Title:            "Publish me",
+				VisiblePixelHash: contractID,
+				BudgetSats:       2500,
+				Status:           "approved",
+			}); err != nil {
+				t.Fatalf("create proposal: %v", err)
+			}
+			tc.deleteContract(contractID)
+			if err := tc.store.PublishProposal(ctx, contractID); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+			contract, err := tc.store.GetContract(contractID)
+			if err != nil {
+				t.Fatalf("publish left its tasks without a contract: %v", err)
+			}
+			if contract.Title != "Publish me" || contract.TotalBudgetSats != 2500 {
+				t.Fatalf("contract from proposal = %+v", contract)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package smart_contract
+
+import (
+	"context"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// The SQL stores answer counts with SELECT COUNT(*) when every filter field
+// maps to a column. Skills, and a proposal's contract_id (matched against
+// several metadata keys), are only applied in Go by the list methods, so
+// filters using them are counted by listing without pagination instead.
+
+func countContractsByListing(list func(smart_contract.ContractFilter) ([]smart_contract.Contract, error), filter smart_contract.ContractFilter) (int, error) {
+	filter.Limit, filter.Offset = 0, 0
+	contracts, err := list(filter)
+	return len(contracts), err
+}
+
+func countTasksByListing(list func(smart_contract.TaskFilter) ([]smart_contract.Task, error), filter smart_contract.TaskFilter) (int, error) {
+	filter.Limit, filter.Offset = 0, 0
+	tasks, err := list(filter)
+	return len(tasks), err
+}
+
+func countProposalsByListing(ctx context.Context, list func(context.Context, smart_contract.ProposalFilter) ([]smart_contract.Proposal, error), filter smart_contract.ProposalFilter) (int, error) {
+	filter.MaxResults, filter.Offset = 0, 0
+	proposals, err := list(ctx, filter)
+	return len(proposals), err
+}
@@ -0,0 +1,112 @@
+package smart_contract
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+func TestCountsMatchListLengths(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			contract := smart_contract.Contract{ContractID: "count-contract", Title: "Count", Status: "pending"}
+			tasks := []smart_contract.Task{
+				{TaskID: "count-task-1", Title: "Cheap", Status: "available", BudgetSats: 100, Skills: []string{"go"}},
+				{TaskID: "count-task-2", Title: "Pricey", Status: "available", BudgetSats: 9_000, Skills: []string{"rust"}},
+				{TaskID: "count-task-3", Title: "Claimed", Status: "available", BudgetSats: 500, Skills: []string{"go"}},
+			}
+			if err := store.UpsertContractWithTasks(ctx, contract, tasks); err != nil {
+				t.Fatalf("upsert: %v", err)
+			}
+			if _, err := store.ClaimTask("count-task-3", "wallet-count", nil); err != nil {
+				t.Fatalf("claim: %v", err)
+			}
+			for i, p := range []smart_contract.Proposal{
+				{ID: "count-proposal-1", Status: "pending", BudgetSats: 1_000},
+				{ID: "count-proposal-2", Status: "pending", BudgetSats: 50_000},
+				{ID: "count-proposal-3", Status: "rejected", BudgetSats: 50_000},
+			} {
+				p.Title = "Count"
+				p.VisiblePixelHash = strings.Repeat(string(rune('a'+i)), 64)
+				if err := store.CreateProposal(ctx, p); err != nil {
+					t.Fatalf("create proposal: %v", err)
+				}
+			}
+
+			for _, f := range []smart_contract.ContractFilter{
+				{},
+				{Status: "pending"},
+				{Status: "active", Limit: 1},
+				{Skills: []string{"python"}},
+				{Status: "no-such-status"},
+			} {
+				list, err := store.ListContracts(smart_contract.ContractFilter{Status: f.Status, Skills: f.Skills})
+				if err != nil {
+					t.Fatalf("list contracts %+v: %v", f, err)
+				}
+				n, err := store.CountContracts(f)
+				if err != nil || n != len(list) {
+					t.Fatalf("CountContracts(%+v) = %d, %v; want %d", f, n, err, len(list))
+				}
+			}
+
+			for _, f := range []smart_contract.TaskFilter{
+				{},
+				{ContractID: "count-contract"},
+				{ContractID: "count-contract", Status: "available"},
+				{ContractID: "count-contract", MinBudgetSats: 500},
+				{ContractID: "count-contract", Skills: []string{"go"}},
+				{ClaimedBy: "wallet-count", Limit: 1, Offset: 1},
+				{Status: "claimed"},
+			} {
+				unpaged := f
+				unpaged.Limit, unpaged.Offset = 0, 0
+				list, err := store.ListTasks(unpaged)
+				if err != nil {
+					t.Fatalf("list tasks %+v: %v", f, err)
+				}
+				n, err := store.CountTasks(f)
+				if err != nil || n != len(list) {
+					t.Fatalf("CountTasks(%+v) = %d, %v; want %d", f, n, err, len(list))
+				}
+			}
+			if n, _ := store.CountTasks(smart_contract.TaskFilter{ContractID: "count-contract", MinBudgetSats: 500}); n != 2 {
+				t.Fatalf("tasks over budget = %d, want 2", n)
+			}
+
+			for _, f := range []smart_contract.ProposalFilter{
+				{},
+				{Status: "pending"},
+				{Status: "pending", MinBudget: 10_000},
+				{ProposalID: "count-proposal-3"},
+				{ContractID: strings.Repeat("b", 64)},
+				{MaxResults: 1, Offset: 1},
+			} {
+				unpaged := f
+				unpaged.MaxResults, unpaged.Offset = 0, 0
+				list, err := store.ListProposals(ctx, unpaged)
+				if err != nil {
+					t.Fatalf("list proposals %+v: %v", f, err)
+				}
+				n, err := store.CountProposals(ctx, f)
+				if err != nil || n != len(list) {
+					t.Fatalf("CountProposals(%+v) = %d, %v; want %d", f, n, err, len(list))
+				}
+			}
+			if n, _ := store.CountProposals(ctx, smart_contract.ProposalFilter{Status: "pending"}); n != 2 {
+				t.Fatalf("pending proposals = %d, want 2", n)
+			}
+		})
+	}
+}
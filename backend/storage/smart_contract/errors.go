@@ -6,8 +6,14 @@ type Err string
 func (e Err) Error() string { return string(e) }
 
 var (
-	ErrTaskNotFound    = Err("task not found")
-	ErrClaimNotFound   = Err("claim not found")
-	ErrTaskTaken       = Err("task already claimed by another agent")
-	ErrTaskUnavailable = Err("task is not available for claiming")
+	ErrTaskNotFound     = Err("task not found")
+	ErrClaimNotFound    = Err("claim not found")
+	ErrTaskTaken        = Err("task already claimed by another agent")
+	ErrTaskUnavailable  = Err("task is not available for claiming")
+	ErrTaskClosed       = Err("task is closed: its work is approved or its contract has ended")
+	ErrUnknownContract  = Err("contract does not exist")
+	ErrInvalidClaimTTL  = Err("invalid claim_ttl_hours")
+	ErrIdentityMismatch = Err("contract_id must match visible_pixel_hash")
+	ErrFundingStatus    = Err("funding_status must be funded, partial or unfunded")
+	ErrTooManyTasks     = Err("too many tasks")
 )
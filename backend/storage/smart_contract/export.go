@@ -0,0 +1,49 @@
+package smart_contract
+
+import (
+	"context"
+	"time"
+)
+
+// Record types accepted by Exporter.Export.
+const (
+	ExportContracts   = "contracts"
+	ExportTasks       = "tasks"
+	ExportSubmissions = "submissions"
+)
+
+// ValidExportType reports whether kind names an exportable record type.
+func ValidExportType(kind string) bool {
+	switch kind {
+	case ExportContracts, ExportTasks, ExportSubmissions:
+		return true
+	}
+	return false
+}
+
+// ExportFilter bounds an export by record time. Contracts and submissions are
+// matched on created_at; tasks have no timestamp of their own and are matched
+// on their contract's created_at. Nil bounds are open.
+type ExportFilter struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// Includes reports whether t falls within the filter's bounds (inclusive).
+func (f ExportFilter) Includes(t time.Time) bool {
+	if f.From != nil && t.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && t.After(*f.To) {
+		return false
+	}
+	return true
+}
+
+// Exporter is implemented by stores that can stream every record of one type
+// for bulk export. Records are handed to fn one at a time, in a stable order,
+// so callers never hold the whole table; an error from fn stops the export
+// and is returned.
+type Exporter interface {
+	Export(ctx context.Context, kind string, filter ExportFilter, fn func(record any) error) error
+}
@@ -0,0 +1,59 @@
+package smart_contract
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportStreamsSeededRecords(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(time.Hour),
+		"sqlite": sqliteStore,
+	}
+	manifest := Manifest()
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			claim, err := store.ClaimTask(SeedTaskBollingerID, "wallet-1", nil)
+			if err != nil {
+				t.Fatalf("claim seeded task: %v", err)
+			}
+			if _, err := store.SubmitWork(claim.ClaimID, map[string]interface{}{"notes": "done"}, nil); err != nil {
+				t.Fatalf("submit: %v", err)
+			}
+
+			count := func(kind string, filter ExportFilter) int {
+				n := 0
+				err := store.(Exporter).Export(ctx, kind, filter, func(record any) error {
+					n++
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("export %s: %v", kind, err)
+				}
+				return n
+			}
+			if got := count(ExportContracts, ExportFilter{}); got != len(manifest.ContractIDs) {
+				t.Fatalf("contracts = %d, want %d", got, len(manifest.ContractIDs))
+			}
+			if got := count(ExportTasks, ExportFilter{}); got != len(manifest.TaskIDs) {
+				t.Fatalf("tasks = %d, want %d", got, len(manifest.TaskIDs))
+			}
+			if got := count(ExportSubmissions, ExportFilter{}); got != 1 {
+				t.Fatalf("submissions = %d, want 1", got)
+			}
+			later := SeedEpoch.Add(time.Hour)
+			if got := count(ExportTasks, ExportFilter{From: &later}); got != 0 {
+				t.Fatalf("tasks after seed epoch = %d, want 0", got)
+			}
+		})
+	}
+}
@@ -6,12 +6,50 @@ import (
 	"stargate-backend/core/smart_contract"
 )
 
-// SeedData returns in-memory demo contracts/tasks/proofs.
+// Fixed identifiers for the seeded demo fixtures. They never change between
+// runs so tests and demos can reference them directly.
+const (
+	SeedContractID       = "CONTRACT-550e8400"
+	SeedTaskBollingerID  = "TASK-7f3b9c2a"
+	SeedTaskRiskID       = "TASK-9d8e7f6a"
+	SeedTaskPendingTxsID = "TASK-get-pending-txs"
+	SeedProofBollingerTx = "a1b2c3d4e5f6"
+	SeedProofRiskTx      = "b2c3d4e5f6a1"
+)
+
+// SeedEpoch is the fixed reference time used for every fixture timestamp so
+// repeated seeding produces byte-identical records.
+var SeedEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// SeedManifest lists the records created by SeedData.
+type SeedManifest struct {
+	ContractIDs []string `json:"contract_ids"`
+	TaskIDs     []string `json:"task_ids"`
+	ProofTxIDs  []string `json:"proof_txids"`
+}
+
+// Manifest returns the identifiers SeedData creates, in seeding order.
+func Manifest() SeedManifest {
+	contracts, tasks := SeedData()
+	m := SeedManifest{}
+	for _, c := range contracts {
+		m.ContractIDs = append(m.ContractIDs, c.ContractID)
+	}
+	for _, t := range tasks {
+		m.TaskIDs = append(m.TaskIDs, t.TaskID)
+		if t.MerkleProof != nil {
+			m.ProofTxIDs = append(m.ProofTxIDs, t.MerkleProof.TxID)
+		}
+	}
+	return m
+}
+
+// SeedData returns deterministic demo contracts/tasks/proofs.
 func SeedData() ([]smart_contract.Contract, []smart_contract.Task) {
-	seen := time.Now().Add(-10 * time.Minute)
-	confirm := time.Now().Add(-5 * time.Minute)
+	seen := SeedEpoch.Add(-10 * time.Minute)
+	confirm := SeedEpoch.Add(-5 * time.Minute)
 	proof := &smart_contract.MerkleProof{
-		TxID:                  "a1b2c3d4e5f6",
+		TxID:                  SeedProofBollingerTx,
 		BlockHeight:           830001,
 		BlockHeaderMerkleRoot: "99887766554433221100",
 		ProofPath: []smart_contract.ProofNode{
@@ -26,7 +64,7 @@ func SeedData() ([]smart_contract.Contract, []smart_contract.Task) {
 
 	contracts := []smart_contract.Contract{
 		{
-			ContractID:          "CONTRACT-550e8400",
+			ContractID:          SeedContractID,
 			Title:               "Trading Bot Development",
 			TotalBudgetSats:     50_000_000,
 			GoalsCount:          5,
@@ -34,13 +72,14 @@ func SeedData() ([]smart_contract.Contract, []smart_contract.Task) {
 			Status:              "active",
 			Skills:              []string{"python", "api-integration", "finance"},
 			StegoImageURL:       "", // No stego image URL for seed data
+			CreatedAt:           SeedEpoch,
 		},
 	}
 
 	tasks := []smart_contract.Task{
 		{
-			TaskID:         "TASK-7f3b9c2a",
-			ContractID:     "CONTRACT-550e8400",
+			TaskID:         SeedTaskBollingerID,
+			ContractID:     SeedContractID,
 			GoalID:         "GOAL-001",
 			Title:          "Implement Bollinger Bands Strategy",
 			Description:    "Code BB indicator with entry/exit signals",
@@ -56,8 +95,8 @@ func SeedData() ([]smart_contract.Contract, []smart_contract.Task) {
 			MerkleProof: proof,
 		},
 		{
-			TaskID:         "TASK-9d8e7f6a",
-			ContractID:     "CONTRACT-550e8400",
+			TaskID:         SeedTaskRiskID,
+			ContractID:     SeedContractID,
 			GoalID:         "GOAL-001",
 			Title:          "Design execution risk controls",
 			Description:    "Add risk guardrails to the trading bot",
@@ -67,7 +106,7 @@ func SeedData() ([]smart_contract.Contract, []smart_contract.Task) {
 			Difficulty:     "beginner",
 			EstimatedHours: 5,
 			MerkleProof: &smart_contract.MerkleProof{
-				TxID:                  "b2c3d4e5f6a1",
+				TxID:                  SeedProofRiskTx,
 				BlockHeight:           830002,
 				BlockHeaderMerkleRoot: "aa9988776655",
 				ProofPath: []smart_contract.ProofNode{
@@ -79,8 +118,8 @@ func SeedData() ([]smart_contract.Contract, []smart_contract.Task) {
 			},
 		},
 		{
-			TaskID:         "TASK-get-pending-txs",
-			ContractID:     "CONTRACT-550e8400",
+			TaskID:         SeedTaskPendingTxsID,
+			ContractID:     SeedContractID,
 			GoalID:         "GOAL-002",
 			Title:          "Get Pending Transactions",
 			Description:    "Get a list of pending transactions (inscriptions/smart contracts).",
@@ -0,0 +1,75 @@
+package smart_contract
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSeedDataUsesFixedIdentifiers(t *testing.T) {
+	want := SeedManifest{
+		ContractIDs: []string{"CONTRACT-550e8400"},
+		TaskIDs:     []string{"TASK-7f3b9c2a", "TASK-9d8e7f6a", "TASK-get-pending-txs"},
+		ProofTxIDs:  []string{"a1b2c3d4e5f6", "b2c3d4e5f6a1"},
+	}
+	if got := Manifest(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("manifest = %+v, want %+v", got, want)
+	}
+
+	c1, t1 := SeedData()
+	c2, t2 := SeedData()
+	if !reflect.DeepEqual(c1, c2) || !reflect.DeepEqual(t1, t2) {
+		t.Fatal("SeedData is not deterministic across calls")
+	}
+	if !c1[0].CreatedAt.Equal(SeedEpoch) {
+		t.Fatalf("contract created_at = %v, want %v", c1[0].CreatedAt, SeedEpoch)
+	}
+}
+
+func TestResetAndSeedRestoresFixtures(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(time.Hour),
+		"sqlite": sqliteStore,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.ClaimTask(SeedTaskBollingerID, "wallet-1", nil); err != nil {
+				t.Fatalf("claim seeded task: %v", err)
+			}
+
+			manifest, err := store.(Reseeder).ResetAndSeed(ctx)
+			if err != nil {
+				t.Fatalf("reset and seed: %v", err)
+			}
+			if !reflect.DeepEqual(manifest, Manifest()) {
+				t.Fatalf("manifest = %+v, want %+v", manifest, Manifest())
+			}
+
+			contract, err := store.GetContract(SeedContractID)
+			if err != nil {
+				t.Fatalf("get seeded contract: %v", err)
+			}
+			if contract.ContractID != SeedContractID {
+				t.Fatalf("contract id = %q, want %q", contract.ContractID, SeedContractID)
+			}
+			for _, id := range manifest.TaskIDs {
+				task, err := store.GetTask(id)
+				if err != nil {
+					t.Fatalf("get seeded task %s: %v", id, err)
+				}
+				if task.Status != "available" {
+					t.Fatalf("task %s status = %q, want available", id, task.Status)
+				}
+			}
+		})
+	}
+}
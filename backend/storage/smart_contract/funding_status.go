@@ -0,0 +1,46 @@
+package smart_contract
+
+import (
+	"fmt"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// Funded amounts per contract, summed over confirmed task proofs that carry a
+// funding tx_id, mirroring smart_contract.FundingStatusFor. Joined as f onto
+// mcp_contracts c when a ContractFilter sets FundingStatus.
+const (
+	pgFundingJoin = `
+LEFT JOIN (
+	SELECT contract_id, SUM(COALESCE((merkle_proof->>'funded_amount_sats')::bigint, 0)) AS funded_sats
+	FROM mcp_tasks
+	WHERE btrim(COALESCE(merkle_proof->>'tx_id', '')) <> ''
+		AND merkle_proof->>'confirmation_status' = 'confirmed'
+	GROUP BY contract_id
+) f ON f.contract_id = c.contract_id
+`
+	sqliteFundingJoin = `
+LEFT JOIN (
+	SELECT contract_id, SUM(COALESCE(CAST(json_extract(merkle_proof, '$.funded_amount_sats') AS INTEGER), 0)) AS funded_sats
+	FROM mcp_tasks
+	WHERE json_valid(merkle_proof) AND TRIM(COALESCE(json_extract(merkle_proof, '$.tx_id'), '')) <> ''
+		AND json_extract(merkle_proof, '$.confirmation_status') = 'confirmed'
+	GROUP BY contract_id
+) f ON f.contract_id = c.contract_id
+`
+)
+
+// fundingStatusCondition returns the WHERE condition selecting contracts with
+// the given funding status over the funding join.
+func fundingStatusCondition(status string) (string, error) {
+	const funded, budget = "COALESCE(f.funded_sats, 0)", "COALESCE(c.total_budget_sats, 0)"
+	switch status {
+	case smart_contract.FundingStatusUnfunded:
+		return funded + " <= 0", nil
+	case smart_contract.FundingStatusFunded:
+		return fmt.Sprintf("%s > 0 AND %s >= %s", funded, funded, budget), nil
+	case smart_contract.FundingStatusPartial:
+		return fmt.Sprintf("%s > 0 AND %s < %s", funded, funded, budget), nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrFundingStatus, status)
+}
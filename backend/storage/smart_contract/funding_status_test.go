@@ -0,0 +1,92 @@
+package smart_contract
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+func TestListContractsByFundingStatus(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	fundingTask := func(id string, sats int64, txid, status string) smart_contract.Task {
+		return smart_contract.Task{TaskID: id, Title: id, Status: "available", MerkleProof: &smart_contract.MerkleProof{
+			TxID: txid, FundedAmountSats: sats, ConfirmationStatus: status,
+		}}
+	}
+	const confirmed, provisional = smart_contract.CommitmentStatusConfirmed, smart_contract.CommitmentStatusProvisional
+	contracts := map[string][]smart_contract.Task{
+		// Two proofs covering the budget between them.
+		"fund-full": {fundingTask("fund-full-1", 600, "tx-full-1", confirmed), fundingTask("fund-full-2", 400, "tx-full-2", confirmed)},
+		"fund-part": {fundingTask("fund-part-1", 400, "tx-part", confirmed), fundingTask("fund-part-2", 600, "tx-part-psbt", provisional)},
+		// A placeholder proof carries the intended amount but no transaction.
+		"fund-none": {fundingTask("fund-none-1", 1000, "", provisional)},
+		// A provisional proof's tx_id comes from an unsigned PSBT.
+		"fund-psbt": {fundingTask("fund-psbt-1", 1000, "tx-psbt", provisional)},
+	}
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			for id, tasks := range contracts {
+				c := smart_contract.Contract{ContractID: id, Title: id, Status: "active", TotalBudgetSats: 1000}
+				if err := store.UpsertContractWithTasks(ctx, c, tasks); err != nil {
+					t.Fatalf("upsert %s: %v", id, err)
+				}
+			}
+
+			for status, want := range map[string]string{
+				smart_contract.FundingStatusFunded:   "fund-full",
+				smart_contract.FundingStatusPartial:  "fund-part",
+				smart_contract.FundingStatusUnfunded: "fund-none,fund-psbt",
+			} {
+				filter := smart_contract.ContractFilter{FundingStatus: status}
+				list, err := store.ListContracts(filter)
+				if err != nil {
+					t.Fatalf("list %s: %v", status, err)
+				}
+				var got []string
+				for _, c := range list {
+					if strings.HasPrefix(c.ContractID, "fund-") {
+						got = append(got, c.ContractID)
+					}
+				}
+				sort.Strings(got)
+				if strings.Join(got, ",") != want {
+					t.Fatalf("%s contracts = %v, want [%s]", status, got, want)
+				}
+				n, err := store.CountContracts(filter)
+				if err != nil || n != len(list) {
+					t.Fatalf("count %s = %d, %v; want %d", status, n, err, len(list))
+				}
+			}
+
+			all, err := store.ListContracts(smart_contract.ContractFilter{})
+			if err != nil {
+				t.Fatalf("list all: %v", err)
+			}
+			total := 0
+			for _, status := range []string{smart_contract.FundingStatusFunded, smart_contract.FundingStatusPartial, smart_contract.FundingStatusUnfunded} {
+				n, _ := store.CountContracts(smart_contract.ContractFilter{FundingStatus: status})
+				total += n
+			}
+			if total != len(all) {
+				t.Fatalf("funding statuses cover %d contracts, want all %d", total, len(all))
+			}
+
+			if _, err := store.ListContracts(smart_contract.ContractFilter{FundingStatus: "maybe"}); !errors.Is(err, ErrFundingStatus) {
+				t.Fatalf("invalid funding_status err = %v, want ErrFundingStatus", err)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"stargate-backend/core/smart_contract"
+	"stargate-backend/logging"
 )
 
 // MemoryStore holds in-memory MCP data with proper concurrency control.
@@ -28,33 +29,188 @@ type MemoryStore struct {
 
 // NewMemoryStore seeds fixtures and returns a MemoryStore.
 func NewMemoryStore(claimTTL time.Duration) *MemoryStore {
+	store := &MemoryStore{claimTTL: claimTTL}
+	store.loadSeedData()
+
+	// Create missing tasks for contracts that should have them
+	store.createMissingTasks()
+
+	return store
+}
+
+// loadSeedData replaces every map with the deterministic fixtures.
+// Callers must hold s.mu or own the store exclusively.
+func (s *MemoryStore) loadSeedData() {
 	contracts, tasks := SeedData()
-	now := time.Now()
-	cMap := make(map[string]smart_contract.Contract, len(contracts))
+	s.contracts = make(map[string]smart_contract.Contract, len(contracts))
 	for _, c := range contracts {
-		if c.CreatedAt.IsZero() {
-			c.CreatedAt = now
-		}
-		cMap[c.ContractID] = c
+		s.contracts[c.ContractID] = c
 	}
-	tMap := make(map[string]smart_contract.Task, len(tasks))
+	s.tasks = make(map[string]smart_contract.Task, len(tasks))
 	for _, t := range tasks {
-		tMap[t.TaskID] = t
+		s.tasks[t.TaskID] = t
 	}
-	store := &MemoryStore{
-		contracts:    cMap,
-		tasks:        tMap,
-		claims:       make(map[string]smart_contract.Claim),
-		submissions:  make(map[string]smart_contract.Submission),
-		proposals:    make(map[string]smart_contract.Proposal),
-		escortStatus: make(map[string]smart_contract.EscortStatus),
-		claimTTL:     claimTTL,
+	s.claims = make(map[string]smart_contract.Claim)
+	s.submissions = make(map[string]smart_contract.Submission)
+	s.proposals = make(map[string]smart_contract.Proposal)
+	s.escortStatus = make(map[string]smart_contract.EscortStatus)
+}
+
+// ResetAndSeed drops all in-memory state and reloads the fixtures.
+func (s *MemoryStore) ResetAndSeed(ctx context.Context) (SeedManifest, error) {
+	s.mu.Lock()
+	s.loadSeedData()
+	s.mu.Unlock()
+	s.createMissingTasks()
+	return Manifest(), nil
+}
+
+// CheckConsistency reports tasks without a contract and submissions whose
+// claim or task is missing, ordered by ID.
+func (s *MemoryStore) CheckConsistency(ctx context.Context) (ConsistencyReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report := ConsistencyReport{OrphanTasks: []OrphanTask{}, OrphanSubmissions: []OrphanSubmission{}}
+	for _, t := range s.tasks {
+		if _, ok := s.contracts[t.ContractID]; !ok {
+			report.OrphanTasks = append(report.OrphanTasks, OrphanTask{TaskID: t.TaskID, ContractID: t.ContractID})
+		}
 	}
+	for _, sub := range s.submissions {
+		claim, ok := s.claims[sub.ClaimID]
+		if ok {
+			if _, ok = s.tasks[claim.TaskID]; ok {
+				continue
+			}
+		}
+		report.OrphanSubmissions = append(report.OrphanSubmissions, OrphanSubmission{SubmissionID: sub.SubmissionID, ClaimID: sub.ClaimID, TaskID: claim.TaskID, Status: sub.Status})
+	}
+	sort.Slice(report.OrphanTasks, func(i, j int) bool { return report.OrphanTasks[i].TaskID < report.OrphanTasks[j].TaskID })
+	sort.Slice(report.OrphanSubmissions, func(i, j int) bool {
+		return report.OrphanSubmissions[i].SubmissionID < report.OrphanSubmissions[j].SubmissionID
+	})
+	return report, nil
+}
 
-	// Create missing tasks for contracts that should have them
-	store.createMissingTasks()
+// Stats counts contracts, tasks, proposals and submissions by status.
+func (s *MemoryStore) Stats(ctx context.Context) (Stats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	return store
+	st := newStats()
+	for _, c := range s.contracts {
+		st.Contracts.add(c.Status)
+	}
+	for _, t := range s.tasks {
+		st.Tasks.add(t.Status)
+	}
+	for _, p := range s.proposals {
+		st.Proposals.add(p.Status)
+	}
+	for _, sub := range s.submissions {
+		st.Submissions.add(sub.Status)
+	}
+	return st, nil
+}
+
+// SubmissionContexts resolves tasks and their contracts under one read lock.
+func (s *MemoryStore) SubmissionContexts(ctx context.Context, taskIDs []string) (map[string]SubmissionContext, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(map[string]SubmissionContext, len(taskIDs))
+	for _, id := range taskIDs {
+		t, ok := s.tasks[id]
+		if !ok {
+			continue
+		}
+		sc := SubmissionContext{Task: SubmissionTask{TaskID: t.TaskID, Title: t.Title, Skills: t.Skills, ContractID: t.ContractID}}
+		if c, ok := s.contracts[t.ContractID]; ok {
+			sc.Contract = &SubmissionContract{ContractID: c.ContractID, Title: c.Title, Status: c.Status}
+		}
+		out[id] = sc
+	}
+	return out, nil
+}
+
+// ExpireClaims releases active claims that expired before now and frees
+// tasks still held by them.
+func (s *MemoryStore) ExpireClaims(ctx context.Context, now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := 0
+	for id, claim := range s.claims {
+		if claim.Status != "active" || !claim.ExpiresAt.Before(now) {
+			continue
+		}
+		claim.Status = "expired"
+		s.claims[id] = claim
+		expired++
+		task, ok := s.tasks[claim.TaskID]
+		if !ok || task.Status != "claimed" || (task.ActiveClaimID != "" && task.ActiveClaimID != id) {
+			continue
+		}
+		task.Status = "available"
+		task.ClaimedBy = ""
+		task.ClaimedAt = nil
+		task.ClaimExpires = nil
+		task.ActiveClaimID = ""
+		s.tasks[claim.TaskID] = task
+	}
+	return expired, nil
+}
+
+// Export streams every record of kind to fn, ordered by ID. The matching
+// records are copied under the read lock so fn runs without holding it.
+func (s *MemoryStore) Export(ctx context.Context, kind string, filter ExportFilter, fn func(record any) error) error {
+	var records []any
+	var ids []string
+	s.mu.RLock()
+	switch kind {
+	case ExportContracts:
+		for id, c := range s.contracts {
+			if filter.Includes(c.CreatedAt) {
+				ids, records = append(ids, id), append(records, c)
+			}
+		}
+	case ExportTasks:
+		for id, t := range s.tasks {
+			if filter.Includes(s.contracts[t.ContractID].CreatedAt) {
+				ids, records = append(ids, id), append(records, t)
+			}
+		}
+	case ExportSubmissions:
+		for id, sub := range s.submissions {
+			if !filter.Includes(sub.CreatedAt) {
+				continue
+			}
+			if claim, ok := s.claims[sub.ClaimID]; ok && sub.TaskID == "" {
+				sub.TaskID = claim.TaskID
+			}
+			ids, records = append(ids, id), append(records, sub)
+		}
+	default:
+		s.mu.RUnlock()
+		return fmt.Errorf("unknown export type %q", kind)
+	}
+	s.mu.RUnlock()
+
+	order := make([]int, len(ids))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return ids[order[i]] < ids[order[j]] })
+	for _, i := range order {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(records[i]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func containsSkill(all []string, skills []string) bool {
@@ -124,27 +280,40 @@ func matchesContractMeta(contractID string, proposals map[string]smart_contract.
 func (s *MemoryStore) ListContracts(filter smart_contract.ContractFilter) ([]smart_contract.Contract, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	fmt.Printf("DEBUG: ListContracts called on %p, contracts: %d\n", s, len(s.contracts))
+	logging.Debugf("ListContracts called on %p, contracts: %d", s, len(s.contracts))
 	for id := range s.contracts {
-		fmt.Printf("DEBUG: ListContracts - Contract ID: %s\n", id)
+		logging.Debugf("ListContracts - Contract ID: %s", id)
+	}
+	if filter.FundingStatus != "" && !smart_contract.ValidFundingStatus(filter.FundingStatus) {
+		return nil, fmt.Errorf("%w: %q", ErrFundingStatus, filter.FundingStatus)
 	}
 	availableCounts := make(map[string]int)
+	proofs := make(map[string][]smart_contract.MerkleProof)
 	for _, t := range s.tasks {
 		if strings.EqualFold(t.Status, "available") {
 			availableCounts[t.ContractID]++
 		}
+		if t.MerkleProof != nil {
+			proofs[t.ContractID] = append(proofs[t.ContractID], *t.MerkleProof)
+		}
 	}
 	out := make([]smart_contract.Contract, 0, len(s.contracts))
 	for _, c := range s.contracts {
 		if filter.Status != "" && !strings.EqualFold(filter.Status, c.Status) {
 			continue
 		}
+		if filter.FundingStatus != "" && smart_contract.FundingStatusFor(c.TotalBudgetSats, proofs[c.ContractID]) != filter.FundingStatus {
+			continue
+		}
 		if len(filter.Skills) > 0 && !containsSkill(c.Skills, filter.Skills) {
 			continue
 		}
 		if !matchesContractMeta(c.ContractID, s.proposals, filter) {
 			continue
 		}
+		if filter.InactiveSince != nil && !contractInactiveSince(c, *filter.InactiveSince) {
+			continue
+		}
 
 		// Cursor pagination by height
 		if filter.CursorHeight != nil && *filter.CursorHeight > 0 {
@@ -171,7 +340,9 @@ func (s *MemoryStore) ListContracts(filter smart_contract.ContractFilter) ([]sma
 	}
 
 	// Sort based on filter preference
-	if filter.OrderByConfirmedAt {
+	if filter.OrderByLastActivity {
+		sortContractsByLastActivity(out)
+	} else if filter.OrderByConfirmedAt {
 		sort.Slice(out, func(i, j int) bool {
 			if out[i].ConfirmedAt == nil {
 				return false
@@ -209,10 +380,15 @@ func (s *MemoryStore) ListContracts(filter smart_contract.ContractFilter) ([]sma
 	return out, nil
 }
 
+// CountContracts returns len(ListContracts) without pagination.
+func (s *MemoryStore) CountContracts(filter smart_contract.ContractFilter) (int, error) {
+	return countContractsByListing(s.ListContracts, filter)
+}
+
 // ListTasks returns tasks filtered by a TaskFilter.
 func (s *MemoryStore) ListTasks(filter smart_contract.TaskFilter) ([]smart_contract.Task, error) {
 	s.mu.RLock()
-	fmt.Printf("DEBUG: ListTasks called on %p, contracts: %d, tasks: %d\n", s, len(s.contracts), len(s.tasks))
+	logging.Debugf("ListTasks called on %p, contracts: %d, tasks: %d", s, len(s.contracts), len(s.tasks))
 	// Check if we need to create missing tasks
 	needTasks := false
 	for _, contract := range s.contracts {
@@ -257,6 +433,9 @@ func (s *MemoryStore) ListTasks(filter smart_contract.TaskFilter) ([]smart_contr
 		if filter.MinBudgetSats > 0 && t.BudgetSats < filter.MinBudgetSats {
 			continue
 		}
+		if filter.InactiveSince != nil && !taskInactiveSince(t, *filter.InactiveSince) {
+			continue
+		}
 
 		// Add time-based filtering for UpdatedSince
 		if filter.UpdatedSince != nil {
@@ -287,6 +466,9 @@ func (s *MemoryStore) ListTasks(filter smart_contract.TaskFilter) ([]smart_contr
 
 		out = append(out, t)
 	}
+	if filter.OrderByLastActivity {
+		sortTasksByLastActivity(out)
+	}
 
 	start := filter.Offset
 	if start < 0 {
@@ -299,6 +481,11 @@ func (s *MemoryStore) ListTasks(filter smart_contract.TaskFilter) ([]smart_contr
 	return out[start:end], nil
 }
 
+// CountTasks returns len(ListTasks) without pagination.
+func (s *MemoryStore) CountTasks(filter smart_contract.TaskFilter) (int, error) {
+	return countTasksByListing(s.ListTasks, filter)
+}
+
 // GetTask returns a task by ID.
 func (s *MemoryStore) GetTask(id string) (smart_contract.Task, error) {
 	s.mu.RLock()
@@ -346,6 +533,19 @@ func (s *MemoryStore) ClaimTask(taskID, walletAddress string, estimatedCompletio
 		return smart_contract.Claim{}, fmt.Errorf("wallet address required")
 	}
 
+	// Refuse claims that could never be paid.
+	if taskStatusClosed(task.Status) {
+		return smart_contract.Claim{}, ErrTaskClosed
+	}
+	if contract, ok := s.contracts[task.ContractID]; ok && contractStatusClosed(contract.Status) {
+		return smart_contract.Claim{}, ErrTaskClosed
+	}
+	for _, sub := range s.submissions {
+		if claim, ok := s.claims[sub.ClaimID]; ok && claim.TaskID == taskID && submissionStatusFinal(sub.Status) {
+			return smart_contract.Claim{}, ErrTaskClosed
+		}
+	}
+
 	// Existing claim by this user? (IDEMPOTENCY)
 	for _, c := range s.claims {
 		if c.TaskID == taskID {
@@ -372,7 +572,7 @@ func (s *MemoryStore) ClaimTask(taskID, walletAddress string, estimatedCompletio
 	}
 
 	claimID := fmt.Sprintf("CLAIM-%d", time.Now().UnixNano())
-	expires := time.Now().Add(s.claimTTL)
+	expires := time.Now().Add(ContractClaimTTL(s.contracts[task.ContractID].Metadata, s.claimTTL))
 	claim := smart_contract.Claim{
 		ClaimID:      claimID,
 		TaskID:       taskID,
@@ -394,6 +594,7 @@ func (s *MemoryStore) ClaimTask(taskID, walletAddress string, estimatedCompletio
 		task.MerkleProof.ContractorWallet = normalizedWallet
 	}
 	s.tasks[taskID] = task
+	s.touchActivity(taskID, claim.CreatedAt)
 
 	s.claims[claimID] = claim
 
@@ -460,6 +661,7 @@ SubmitWork:
 	task.Status = "submitted"
 	task.ActiveClaimID = claimID
 	s.tasks[claim.TaskID] = task
+	s.touchActivity(claim.TaskID, sub.CreatedAt)
 
 	claim.Status = "submitted"
 	s.claims[claimID] = claim
@@ -606,6 +808,9 @@ func (s *MemoryStore) UpdateTaskProof(ctx context.Context, taskID string, proof
 		t.MerkleProof = proof
 	}
 	s.tasks[taskID] = t
+	if proof != nil {
+		s.touchActivity(taskID, time.Now())
+	}
 	return nil
 }
 
@@ -665,6 +870,7 @@ func (s *MemoryStore) ConfirmContract(ctx context.Context, contractID string, bl
 	contract.ConfirmedBlockHeight = &blockHeight
 	confirmedAt := time.Now()
 	contract.ConfirmedAt = &confirmedAt
+	contract.LastActivityAt = &confirmedAt
 
 	// Set confirmed_txid in metadata
 	if contract.Metadata == nil {
@@ -696,32 +902,13 @@ func (s *MemoryStore) CreateProposal(ctx context.Context, p smart_contract.Propo
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if p.Metadata == nil {
-		p.Metadata = map[string]interface{}{}
-	}
-	if strings.TrimSpace(p.VisiblePixelHash) != "" {
-		if vph, ok := p.Metadata["visible_pixel_hash"].(string); !ok || strings.TrimSpace(vph) == "" {
-			p.Metadata["visible_pixel_hash"] = p.VisiblePixelHash
-		}
-	}
-	if metaContract, ok := p.Metadata["contract_id"].(string); ok {
-		metaContract = strings.TrimSpace(metaContract)
-		if metaContract != "" {
-			if metaHash, ok2 := p.Metadata["visible_pixel_hash"].(string); ok2 {
-				metaHash = strings.TrimSpace(metaHash)
-				if metaHash != "" && metaHash != metaContract {
-					normalizedContract := strings.TrimPrefix(metaContract, "wish-")
-					if metaHash != normalizedContract {
-						return fmt.Errorf("visible_pixel_hash must match contract_id when both are set (normalized: %s)", normalizedContract)
-					}
-				}
-			}
-		}
+	if err := ApplyProposalIdentity(&p); err != nil {
+		return err
 	}
 
 	// Comprehensive security validation
 	if err := ValidateProposalInput(&p); err != nil {
-		return fmt.Errorf("proposal validation failed: %v", err)
+		return fmt.Errorf("proposal validation failed: %w", err)
 	}
 
 	// Validate status field
@@ -822,19 +1009,41 @@ func (s *MemoryStore) createMissingTasks() {
 
 // UpsertContractWithTasks persists a contract and its tasks idempotently.
 func (s *MemoryStore) UpsertContractWithTasks(ctx context.Context, contract smart_contract.Contract, tasks []smart_contract.Task) error {
+	if err := ValidateClaimTTL(contract.Metadata); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Every task must belong to this contract or one that already exists.
+	for _, task := range tasks {
+		if task.ContractID == "" || task.ContractID == contract.ContractID {
+			continue
+		}
+		if _, ok := s.contracts[task.ContractID]; !ok {
+			return fmt.Errorf("%w: task %s references %s", ErrUnknownContract, task.TaskID, task.ContractID)
+		}
+	}
+
 	// Ensure CreatedAt is set
 	if contract.CreatedAt.IsZero() {
 		contract.CreatedAt = time.Now()
 	}
 
-	// Store the contract
+	// Store the contract, keeping activity recorded before the upsert
+	if existing, ok := s.contracts[contract.ContractID]; ok && contract.LastActivityAt == nil {
+		contract.LastActivityAt = existing.LastActivityAt
+	}
 	s.contracts[contract.ContractID] = contract
 
 	// Store all tasks
 	for _, task := range tasks {
+		if task.ContractID == "" {
+			task.ContractID = contract.ContractID
+		}
+		if existing, ok := s.tasks[task.TaskID]; ok && task.LastActivityAt == nil {
+			task.LastActivityAt = existing.LastActivityAt
+		}
 		s.tasks[task.TaskID] = task
 	}
 
@@ -895,6 +1104,11 @@ func (s *MemoryStore) ListProposals(ctx context.Context, filter smart_contract.P
 	return out, nil
 }
 
+// CountProposals returns len(ListProposals) without pagination.
+func (s *MemoryStore) CountProposals(ctx context.Context, filter smart_contract.ProposalFilter) (int, error) {
+	return countProposalsByListing(ctx, s.ListProposals, filter)
+}
+
 func (s *MemoryStore) GetProposal(ctx context.Context, id string) (smart_contract.Proposal, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -947,17 +1161,12 @@ func (s *MemoryStore) UpdateProposal(ctx context.Context, p smart_contract.Propo
 	if p.Status == "" {
 		p.Status = existing.Status
 	}
-	if p.Metadata == nil {
-		p.Metadata = map[string]interface{}{}
-	}
-	if strings.TrimSpace(p.VisiblePixelHash) != "" {
-		if vph, ok := p.Metadata["visible_pixel_hash"].(string); !ok || strings.TrimSpace(vph) == "" {
-			p.Metadata["visible_pixel_hash"] = p.VisiblePixelHash
-		}
+	if err := ApplyProposalIdentity(&p); err != nil {
+		return err
 	}
 
 	if err := ValidateProposalInput(&p); err != nil {
-		return fmt.Errorf("proposal validation failed: %v", err)
+		return fmt.Errorf("proposal validation failed: %w", err)
 	}
 
 	s.proposals[p.ID] = p
@@ -980,6 +1189,10 @@ func (s *MemoryStore) UpdateProposalMetadata(ctx context.Context, id string, upd
 	if meta == nil {
 		meta = map[string]interface{}{}
 	}
+	updates, err := checkMetadataIdentity(existing.VisiblePixelHash, meta, updates)
+	if err != nil {
+		return err
+	}
 	for k, v := range updates {
 		meta[k] = v
 	}
@@ -1008,7 +1221,7 @@ func (s *MemoryStore) ApproveProposal(ctx context.Context, id string) error {
 
 	// Validate proposal for approval without modifying status
 	if err := ValidateProposalForApproval(&p); err != nil {
-		return fmt.Errorf("proposal validation failed: %v", err)
+		return fmt.Errorf("proposal validation failed: %w", err)
 	}
 
 	// Check if proposal is already in final state
@@ -1099,6 +1312,30 @@ func (s *MemoryStore) PublishProposal(ctx context.Context, id string) error {
 		return fmt.Errorf("proposal %s must be approved before publish", id)
 	}
 	contractID := contractIDFromMeta(p.Metadata, id)
+	taskCount := 0
+	for _, t := range s.tasks {
+		if t.ContractID == contractID {
+			taskCount++
+		}
+	}
+	if err := ValidateContractTaskCount(contractID, taskCount); err != nil {
+		return err
+	}
+	if _, ok := s.contracts[contractID]; !ok {
+		// Published tasks must not dangle: create their contract from the proposal.
+		for _, t := range s.tasks {
+			if t.ContractID == contractID {
+				s.contracts[contractID] = smart_contract.Contract{
+					ContractID:      contractID,
+					Title:           p.Title,
+					TotalBudgetSats: p.BudgetSats,
+					Status:          "pending",
+					CreatedAt:       time.Now(),
+				}
+				break
+			}
+		}
+	}
 	for i, t := range s.tasks {
 		if t.ContractID == contractID {
 			switch strings.ToLower(t.Status) {
@@ -1182,8 +1419,18 @@ func (s *MemoryStore) UpsertTask(ctx context.Context, task smart_contract.Task)
 				return fmt.Errorf("task %s already claimed by %s, cannot overwrite with claim from %s", task.TaskID, existing.ClaimedBy, task.ClaimedBy)
 			}
 		}
+		if task.LastActivityAt == nil {
+			task.LastActivityAt = existing.LastActivityAt
+		}
 	}
 
+	// During cross-node sync tasks may arrive before their contracts; create a
+	// placeholder (as the SQL stores do) so the task never dangles.
+	if task.ContractID != "" {
+		if _, ok := s.contracts[task.ContractID]; !ok {
+			s.contracts[task.ContractID] = smart_contract.Contract{ContractID: task.ContractID, Status: "pending", CreatedAt: time.Now()}
+		}
+	}
 	s.tasks[task.TaskID] = task
 	return nil
 }
@@ -1231,6 +1478,9 @@ func (s *MemoryStore) UpdateSubmissionStatus(ctx context.Context, submissionID,
 		sub.RejectedAt = nil
 	}
 	s.submissions[submissionID] = sub
+	if claim, ok := s.claims[sub.ClaimID]; ok {
+		s.touchActivity(claim.TaskID, time.Now())
+	}
 
 	switch status {
 	case "accepted", "approved":
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"strings"
 	"time"
 
@@ -80,6 +81,7 @@ CREATE TABLE IF NOT EXISTS mcp_contracts (
   confirmed_block_height INTEGER,
   confirmed_at TIMESTAMP WITH TIME ZONE,
   created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  last_activity_at TIMESTAMP WITH TIME ZONE,
   metadata JSONB DEFAULT '{}'::jsonb
 );
 CREATE INDEX IF NOT EXISTS idx_mcp_contracts_confirmed_height ON mcp_contracts(confirmed_block_height DESC);
@@ -100,7 +102,8 @@ CREATE TABLE IF NOT EXISTS mcp_tasks (
   difficulty TEXT,
   estimated_hours INT,
   requirements JSONB,
-  merkle_proof JSONB
+  merkle_proof JSONB,
+  last_activity_at TIMESTAMPTZ
 );
 CREATE TABLE IF NOT EXISTS mcp_claims (
   claim_id TEXT PRIMARY KEY,
@@ -145,6 +148,8 @@ ALTER TABLE mcp_submissions ADD COLUMN IF NOT EXISTS task_id TEXT;
 ALTER TABLE mcp_submissions ADD COLUMN IF NOT EXISTS rejection_reason TEXT;
 ALTER TABLE mcp_submissions ADD COLUMN IF NOT EXISTS rejection_type TEXT;
 ALTER TABLE mcp_submissions ADD COLUMN IF NOT EXISTS rejected_at TIMESTAMPTZ;
+ALTER TABLE mcp_contracts ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMPTZ;
+ALTER TABLE mcp_tasks ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMPTZ;
 
 -- Add FOREIGN KEY constraints (ignoring errors if they already exist)
 DO $$ 
@@ -197,10 +202,10 @@ func (s *PGStore) seedFixtures(ctx context.Context) error {
 	contracts, tasks := SeedData()
 	for _, c := range contracts {
 		_, err := s.pool.Exec(ctx, `
-INSERT INTO mcp_contracts (contract_id, title, total_budget_sats, goals_count, available_tasks_count, status, skills, stego_image_url)
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+INSERT INTO mcp_contracts (contract_id, title, total_budget_sats, goals_count, available_tasks_count, status, skills, stego_image_url, created_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
 ON CONFLICT (contract_id) DO NOTHING
-`, c.ContractID, c.Title, c.TotalBudgetSats, c.GoalsCount, c.AvailableTasksCount, c.Status, c.Skills, c.StegoImageURL)
+`, c.ContractID, c.Title, c.TotalBudgetSats, c.GoalsCount, c.AvailableTasksCount, c.Status, c.Skills, c.StegoImageURL, c.CreatedAt)
 		if err != nil {
 			return err
 		}
@@ -230,6 +235,213 @@ ON CONFLICT (task_id) DO NOTHING
 	return nil
 }
 
+// ResetAndSeed truncates every MCP table and reloads the deterministic fixtures.
+func (s *PGStore) ResetAndSeed(ctx context.Context) (SeedManifest, error) {
+	_, err := s.pool.Exec(ctx, `TRUNCATE `+strings.Join([]string{TableEscortStatus, TableSubmissions, TableClaims, TableTasks, TableContracts, TableProposals}, ", ")+` CASCADE`)
+	if err != nil {
+		return SeedManifest{}, fmt.Errorf("reset mcp tables: %w", err)
+	}
+	if err := s.seedFixtures(ctx); err != nil {
+		return SeedManifest{}, err
+	}
+	return Manifest(), nil
+}
+
+// CheckConsistency reports tasks without a contract and submissions whose
+// claim or task is missing.
+func (s *PGStore) CheckConsistency(ctx context.Context) (ConsistencyReport, error) {
+	report := ConsistencyReport{OrphanTasks: []OrphanTask{}, OrphanSubmissions: []OrphanSubmission{}}
+	rows, err := s.pool.Query(ctx, orphanTasksQuery)
+	if err != nil {
+		return report, err
+	}
+	for rows.Next() {
+		var o OrphanTask
+		if err := rows.Scan(&o.TaskID, &o.ContractID); err != nil {
+			rows.Close()
+			return report, err
+		}
+		report.OrphanTasks = append(report.OrphanTasks, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	rows, err = s.pool.Query(ctx, orphanSubmissionsQuery)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o OrphanSubmission
+		if err := rows.Scan(&o.SubmissionID, &o.ClaimID, &o.TaskID, &o.Status); err != nil {
+			return report, err
+		}
+		report.OrphanSubmissions = append(report.OrphanSubmissions, o)
+	}
+	return report, rows.Err()
+}
+
+// Stats counts contracts, tasks, proposals and submissions by status with
+// one GROUP BY query per table.
+func (s *PGStore) Stats(ctx context.Context) (Stats, error) {
+	st := newStats()
+	for _, table := range statsTables {
+		rows, err := s.pool.Query(ctx, statsQuery(table))
+		if err != nil {
+			return st, err
+		}
+		counts := st.countsFor(table)
+		for rows.Next() {
+			var status string
+			var n int
+			if err := rows.Scan(&status, &n); err != nil {
+				rows.Close()
+				return st, err
+			}
+			counts[status] = n
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return st, err
+		}
+	}
+	return st, nil
+}
+
+// SubmissionContexts resolves tasks and their contracts with one join.
+func (s *PGStore) SubmissionContexts(ctx context.Context, taskIDs []string) (map[string]SubmissionContext, error) {
+	out := make(map[string]SubmissionContext, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+	rows, err := s.pool.Query(ctx, `
+SELECT t.task_id, COALESCE(t.title, ''), t.skills, COALESCE(t.contract_id, ''),
+       COALESCE(c.contract_id, ''), COALESCE(c.title, ''), COALESCE(c.status, '')
+FROM mcp_tasks t
+LEFT JOIN mcp_contracts c ON c.contract_id = t.contract_id
+WHERE t.task_id = ANY($1::text[])
+`, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sc SubmissionContext
+		var contract SubmissionContract
+		if err := rows.Scan(&sc.Task.TaskID, &sc.Task.Title, &sc.Task.Skills, &sc.Task.ContractID,
+			&contract.ContractID, &contract.Title, &contract.Status); err != nil {
+			return nil, err
+		}
+		if contract.ContractID != "" {
+			sc.Contract = &contract
+		}
+		out[sc.Task.TaskID] = sc
+	}
+	return out, rows.Err()
+}
+
+// ExpireClaims releases active claims that expired before now and frees
+// tasks still held by them.
+func (s *PGStore) ExpireClaims(ctx context.Context, now time.Time) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `UPDATE mcp_claims SET status='expired' WHERE status='active' AND expires_at < $1 RETURNING task_id`, now)
+	if err != nil {
+		return 0, err
+	}
+	var taskIDs []string
+	for rows.Next() {
+		var taskID string
+		if err := rows.Scan(&taskID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		taskIDs = append(taskIDs, taskID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(taskIDs) > 0 {
+		// A task re-claimed after its old claim lapsed keeps the newer claim.
+		if _, err := tx.Exec(ctx, `
+UPDATE mcp_tasks SET status='available', claimed_by=NULL, claimed_at=NULL, claim_expires_at=NULL
+WHERE task_id = ANY($1) AND status='claimed'
+  AND NOT EXISTS (SELECT 1 FROM mcp_claims c WHERE c.task_id = mcp_tasks.task_id AND c.status='active')`, taskIDs); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(taskIDs), nil
+}
+
+// Export streams every record of kind to fn as rows arrive, with the time
+// filter applied in SQL.
+func (s *PGStore) Export(ctx context.Context, kind string, filter ExportFilter, fn func(record any) error) error {
+	var query string
+	switch kind {
+	case ExportContracts:
+		query = `
+SELECT c.contract_id, COALESCE(c.title, ''), COALESCE(c.total_budget_sats, 0), COALESCE(c.goals_count, 0),
+	COALESCE((SELECT COUNT(*) FROM mcp_tasks t WHERE t.contract_id = c.contract_id AND t.status = 'available'), 0) AS available_tasks_count,
+	COALESCE(c.status, 'pending'), c.skills, COALESCE(c.stego_image_url, ''), c.metadata, c.confirmed_block_height, c.confirmed_at, c.created_at, c.last_activity_at
+FROM mcp_contracts c
+WHERE ($1::timestamptz IS NULL OR c.created_at >= $1)
+AND ($2::timestamptz IS NULL OR c.created_at <= $2)
+ORDER BY c.created_at, c.contract_id`
+	case ExportTasks:
+		query = `
+SELECT t.task_id, t.contract_id, t.goal_id, t.title, t.description, t.budget_sats, t.skills, t.status, t.claimed_by, t.claimed_at, t.claim_expires_at, t.difficulty, t.estimated_hours, t.requirements, t.merkle_proof, t.last_activity_at
+FROM mcp_tasks t
+LEFT JOIN mcp_contracts c ON c.contract_id = t.contract_id
+WHERE ($1::timestamptz IS NULL OR c.created_at >= $1)
+AND ($2::timestamptz IS NULL OR c.created_at <= $2)
+ORDER BY t.task_id`
+	case ExportSubmissions:
+		query = `
+SELECT s.submission_id, s.claim_id, c.task_id, s.status, s.deliverables, s.completion_proof, s.rejection_reason, s.rejection_type, s.rejected_at, s.created_at
+FROM mcp_submissions s
+JOIN mcp_claims c ON c.claim_id = s.claim_id
+WHERE ($1::timestamptz IS NULL OR s.created_at >= $1)
+AND ($2::timestamptz IS NULL OR s.created_at <= $2)
+ORDER BY s.created_at, s.submission_id`
+	default:
+		return fmt.Errorf("unknown export type %q", kind)
+	}
+
+	rows, err := s.pool.Query(ctx, query, filter.From, filter.To)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var record any
+		switch kind {
+		case ExportContracts:
+			record, err = scanContract(rows)
+		case ExportTasks:
+			record, err = scanTask(rows)
+		case ExportSubmissions:
+			record, err = scanSubmission(rows)
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 // Close shuts down the pool.
 func (s *PGStore) Close() {
 	if s.pool != nil {
@@ -244,9 +456,9 @@ func (s *PGStore) ListContracts(filter smart_contract.ContractFilter) ([]smart_c
 
 	// Build query dynamically based on filter
 	baseSelect := `
-SELECT c.contract_id, c.title, c.total_budget_sats, c.goals_count,
+SELECT c.contract_id, COALESCE(c.title, ''), COALESCE(c.total_budget_sats, 0), COALESCE(c.goals_count, 0),
 	COALESCE((SELECT COUNT(*) FROM mcp_tasks t WHERE t.contract_id = c.contract_id AND t.status = 'available'), 0) AS available_tasks_count,
-	c.status, c.skills, c.stego_image_url, c.metadata, c.confirmed_block_height, c.confirmed_at, c.created_at
+	COALESCE(c.status, 'pending'), c.skills, COALESCE(c.stego_image_url, ''), c.metadata, c.confirmed_block_height, c.confirmed_at, c.created_at, c.last_activity_at
 FROM mcp_contracts c
 `
 
@@ -262,6 +474,16 @@ FROM mcp_contracts c
 		argIndex++
 	}
 
+	// Funding status, aggregated from task proofs
+	if filter.FundingStatus != "" {
+		cond, err := fundingStatusCondition(filter.FundingStatus)
+		if err != nil {
+			return nil, err
+		}
+		baseSelect += pgFundingJoin
+		whereConditions = append(whereConditions, cond)
+	}
+
 	// Cursor-based pagination by block height (for efficient frontend pagination)
 	if filter.CursorHeight != nil && *filter.CursorHeight > 0 {
 		whereConditions = append(whereConditions, fmt.Sprintf("c.confirmed_block_height < $%d", argIndex))
@@ -280,6 +502,13 @@ FROM mcp_contracts c
 		argIndex++
 	}
 
+	// Staleness: no task activity (or creation) since the cutoff
+	if filter.InactiveSince != nil {
+		whereConditions = append(whereConditions, pgContractInactiveCondition(argIndex))
+		args = append(args, *filter.InactiveSince)
+		argIndex++
+	}
+
 	// Build WHERE clause
 	whereClause := ""
 	if len(whereConditions) > 0 {
@@ -291,6 +520,9 @@ FROM mcp_contracts c
 	if filter.OrderByConfirmedAt {
 		orderBy = "ORDER BY c.confirmed_at DESC NULLS FIRST, c.created_at DESC, c.contract_id DESC"
 	}
+	if filter.OrderByLastActivity {
+		orderBy = pgContractActivityOrder
+	}
 
 	// LIMIT
 	limitClause := ""
@@ -310,17 +542,10 @@ FROM mcp_contracts c
 
 	var allContracts []smart_contract.Contract
 	for rows.Next() {
-		var c smart_contract.Contract
-		var metadata []byte
-		if err := rows.Scan(&c.ContractID, &c.Title, &c.TotalBudgetSats, &c.GoalsCount, &c.AvailableTasksCount,
-			&c.Status, &c.Skills, &c.StegoImageURL, &metadata, &c.ConfirmedBlockHeight, &c.ConfirmedAt, &c.CreatedAt); err != nil {
+		c, err := scanContract(rows)
+		if err != nil {
 			return nil, err
 		}
-		if len(metadata) > 0 {
-			if err := json.Unmarshal(metadata, &c.Metadata); err == nil {
-				// Metadata loaded successfully
-			}
-		}
 		if len(filter.Skills) > 0 && !containsSkill(c.Skills, filter.Skills) {
 			continue
 		}
@@ -339,6 +564,49 @@ FROM mcp_contracts c
 	return allContracts, nil
 }
 
+// CountContracts counts contracts matching filter with SELECT COUNT(*),
+// using the same conditions as ListContracts.
+func (s *PGStore) CountContracts(filter smart_contract.ContractFilter) (int, error) {
+	if len(filter.Skills) > 0 {
+		return countContractsByListing(s.ListContracts, filter)
+	}
+	query := `SELECT COUNT(*) FROM mcp_contracts c`
+	fundingCond := ""
+	if filter.FundingStatus != "" {
+		cond, err := fundingStatusCondition(filter.FundingStatus)
+		if err != nil {
+			return 0, err
+		}
+		query += pgFundingJoin
+		fundingCond = " AND " + cond
+	}
+	query += " WHERE 1=1" + fundingCond
+	args := []interface{}{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND c.status = $%d", len(args))
+	}
+	if filter.CursorHeight != nil && *filter.CursorHeight > 0 {
+		args = append(args, *filter.CursorHeight)
+		query += fmt.Sprintf(" AND c.confirmed_block_height < $%d", len(args))
+	}
+	if filter.CursorDate != nil {
+		op := "<"
+		if strings.EqualFold(filter.CursorType, "after") {
+			op = ">"
+		}
+		args = append(args, *filter.CursorDate)
+		query += fmt.Sprintf(" AND c.confirmed_at %s $%d", op, len(args))
+	}
+	if filter.InactiveSince != nil {
+		args = append(args, *filter.InactiveSince)
+		query += " AND " + pgContractInactiveCondition(len(args))
+	}
+	var n int
+	err := s.pool.QueryRow(context.Background(), query, args...).Scan(&n)
+	return n, err
+}
+
 // hydrateProposalTasks updates proposal tasks with live task statuses from the DB.
 func (s *PGStore) hydrateProposalTasks(ctx context.Context, p *smart_contract.Proposal) {
 	if p == nil {
@@ -363,7 +631,7 @@ func (s *PGStore) hydrateProposalTasks(ctx context.Context, p *smart_contract.Pr
 	}
 
 	rows, err := s.pool.Query(ctx, `
-SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof
+SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof, last_activity_at
 FROM mcp_tasks WHERE contract_id = ANY($1)
 `, contractIDs)
 	if err != nil {
@@ -431,13 +699,17 @@ FROM mcp_tasks WHERE contract_id = ANY($1)
 // ListTasks returns tasks filtered by a TaskFilter.
 func (s *PGStore) ListTasks(filter smart_contract.TaskFilter) ([]smart_contract.Task, error) {
 	ctx := context.Background()
-	rows, err := s.pool.Query(ctx, `
-SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof
+	query := `
+SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof, last_activity_at
 FROM mcp_tasks
 WHERE ($1 = '' OR status = $1)
 AND ($2 = '' OR contract_id = $2)
 AND ($3 = '' OR claimed_by = $3)
-`, filter.Status, filter.ContractID, filter.ClaimedBy)
+AND ` + pgTaskInactiveCondition(4)
+	if filter.OrderByLastActivity {
+		query += "\n" + pgTaskActivityOrder
+	}
+	rows, err := s.pool.Query(ctx, query, filter.Status, filter.ContractID, filter.ClaimedBy, filter.InactiveSince)
 	if err != nil {
 		return nil, err
 	}
@@ -469,11 +741,29 @@ AND ($3 = '' OR claimed_by = $3)
 	return out, rows.Err()
 }
 
+// CountTasks counts tasks matching filter with SELECT COUNT(*), using the
+// same conditions as ListTasks.
+func (s *PGStore) CountTasks(filter smart_contract.TaskFilter) (int, error) {
+	if len(filter.Skills) > 0 {
+		return countTasksByListing(s.ListTasks, filter)
+	}
+	var n int
+	err := s.pool.QueryRow(context.Background(), `
+SELECT COUNT(*)
+FROM mcp_tasks
+WHERE ($1 = '' OR status = $1)
+AND ($2 = '' OR contract_id = $2)
+AND ($3 = '' OR claimed_by = $3)
+AND ($4 <= 0 OR budget_sats >= $4)
+AND `+pgTaskInactiveCondition(5), filter.Status, filter.ContractID, filter.ClaimedBy, filter.MinBudgetSats, filter.InactiveSince).Scan(&n)
+	return n, err
+}
+
 // GetTask returns a task by ID.
 func (s *PGStore) GetTask(id string) (smart_contract.Task, error) {
 	ctx := context.Background()
 	row := s.pool.QueryRow(ctx, `
-SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof
+SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof, last_activity_at
 FROM mcp_tasks WHERE task_id=$1
 `, id)
 	task, err := scanTask(row)
@@ -513,11 +803,11 @@ func (s *PGStore) GetContract(id string) (smart_contract.Contract, error) {
 	var c smart_contract.Contract
 	var metadata []byte
 	err := s.pool.QueryRow(ctx, `
-SELECT contract_id, title, total_budget_sats, goals_count,
+SELECT contract_id, COALESCE(title, ''), COALESCE(total_budget_sats, 0), COALESCE(goals_count, 0),
        COALESCE((SELECT COUNT(*) FROM mcp_tasks t WHERE t.contract_id = mcp_contracts.contract_id AND t.status = 'available'), 0) AS available_tasks_count,
-       status, skills, stego_image_url, confirmed_block_height, confirmed_at, metadata
+       COALESCE(status, 'pending'), skills, COALESCE(stego_image_url, ''), confirmed_block_height, confirmed_at, metadata, last_activity_at
 FROM mcp_contracts WHERE contract_id=$1
-`, id).Scan(&c.ContractID, &c.Title, &c.TotalBudgetSats, &c.GoalsCount, &c.AvailableTasksCount, &c.Status, &c.Skills, &c.StegoImageURL, &c.ConfirmedBlockHeight, &c.ConfirmedAt, &metadata)
+`, id).Scan(&c.ContractID, &c.Title, &c.TotalBudgetSats, &c.GoalsCount, &c.AvailableTasksCount, &c.Status, &c.Skills, &c.StegoImageURL, &c.ConfirmedBlockHeight, &c.ConfirmedAt, &metadata, &c.LastActivityAt)
 	if err != nil {
 		if strings.Contains(err.Error(), "no rows") {
 			return smart_contract.Contract{}, fmt.Errorf("contract %s not found", id)
@@ -766,7 +1056,7 @@ func (s *PGStore) ClaimTask(taskID, walletAddress string, estimatedCompletion *t
 	defer tx.Rollback(ctx)
 
 	task, err := scanTask(tx.QueryRow(ctx, `
-SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof
+SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof, last_activity_at
 FROM mcp_tasks WHERE task_id=$1 FOR UPDATE
 `, taskID))
 	if err != nil {
@@ -778,6 +1068,28 @@ FROM mcp_tasks WHERE task_id=$1 FOR UPDATE
 		return smart_contract.Claim{}, fmt.Errorf("wallet address required")
 	}
 
+	// Refuse claims that could never be paid.
+	if taskStatusClosed(task.Status) {
+		return smart_contract.Claim{}, ErrTaskClosed
+	}
+	var contractStatus *string
+	_ = tx.QueryRow(ctx, `SELECT status FROM mcp_contracts WHERE contract_id=$1`, task.ContractID).Scan(&contractStatus)
+	if contractStatus != nil && contractStatusClosed(*contractStatus) {
+		return smart_contract.Claim{}, ErrTaskClosed
+	}
+	var hasApproved bool
+	if err := tx.QueryRow(ctx, `
+SELECT EXISTS (
+  SELECT 1 FROM mcp_submissions s
+  JOIN mcp_claims c ON c.claim_id = s.claim_id
+  WHERE c.task_id=$1 AND LOWER(s.status) IN ('approved', 'accepted')
+)`, taskID).Scan(&hasApproved); err != nil {
+		return smart_contract.Claim{}, err
+	}
+	if hasApproved {
+		return smart_contract.Claim{}, ErrTaskClosed
+	}
+
 	// Check for existing active claim by this wallet
 	rows, err := tx.Query(ctx, `SELECT claim_id, task_id, ai_identifier, status, expires_at, created_at FROM mcp_claims WHERE task_id=$1`, taskID)
 	if err != nil {
@@ -843,8 +1155,18 @@ FROM mcp_tasks WHERE task_id=$1 FOR UPDATE
 		return err
 	}
 
+	// A contract may override the global claim window via its metadata.
+	ttl := s.claimTTL
+	var contractMeta []byte
+	if err := tx.QueryRow(ctx, `SELECT metadata FROM mcp_contracts WHERE contract_id=$1`, task.ContractID).Scan(&contractMeta); err == nil && len(contractMeta) > 0 {
+		var meta map[string]interface{}
+		if json.Unmarshal(contractMeta, &meta) == nil {
+			ttl = ContractClaimTTL(meta, s.claimTTL)
+		}
+	}
+
 	claimID := fmt.Sprintf("CLAIM-%d", time.Now().UnixNano())
-	expires := now.Add(s.claimTTL)
+	expires := now.Add(ttl)
 	claim := smart_contract.Claim{
 		ClaimID:      claimID,
 		TaskID:       taskID,
@@ -870,6 +1192,9 @@ UPDATE mcp_tasks SET status='claimed', claimed_by=$2, claimed_at=$3, claim_expir
 	if err := persistWallet(normalizedWallet); err != nil {
 		return smart_contract.Claim{}, err
 	}
+	if err := touchActivityPG(ctx, tx, taskID, claim.CreatedAt); err != nil {
+		return smart_contract.Claim{}, err
+	}
 
 	_ = estimatedCompletion // placeholder to persist ETA later
 	if err := tx.Commit(ctx); err != nil {
@@ -1003,6 +1328,9 @@ func (s *PGStore) SubmitWork(claimID string, deliverables map[string]interface{}
 		log.Printf("Failed to update task status: %v", err)
 		return smart_contract.Submission{}, err
 	}
+	if err := touchActivityPG(ctx, tx, claim.TaskID, sub.CreatedAt); err != nil {
+		return smart_contract.Submission{}, err
+	}
 
 	log.Printf("Committing transaction for submission %s", subID)
 	if err := tx.Commit(ctx); err != nil {
@@ -1076,30 +1404,10 @@ ORDER BY s.created_at DESC
 	defer rows.Close()
 	var out []smart_contract.Submission
 	for rows.Next() {
-		var sub smart_contract.Submission
-		var delivJSON, proofJSON []byte
-		var rejectionReason sql.NullString
-		var rejectionType sql.NullString
-		var rejectedAt sql.NullTime
-		if err := rows.Scan(&sub.SubmissionID, &sub.ClaimID, &sub.TaskID, &sub.Status, &delivJSON, &proofJSON, &rejectionReason, &rejectionType, &rejectedAt, &sub.CreatedAt); err != nil {
+		sub, err := scanSubmission(rows)
+		if err != nil {
 			return nil, err
 		}
-		if rejectionReason.Valid {
-			sub.RejectionReason = rejectionReason.String
-		}
-		if rejectionType.Valid {
-			sub.RejectionType = rejectionType.String
-		}
-		if rejectedAt.Valid {
-			t := rejectedAt.Time
-			sub.RejectedAt = &t
-		}
-		if len(delivJSON) > 0 {
-			_ = json.Unmarshal(delivJSON, &sub.Deliverables)
-		}
-		if len(proofJSON) > 0 {
-			_ = json.Unmarshal(proofJSON, &sub.CompletionProof)
-		}
 		out = append(out, sub)
 	}
 	return out, rows.Err()
@@ -1216,12 +1524,29 @@ func (s *PGStore) ContractFunding(contractID string) (smart_contract.Contract, [
 
 // UpsertContractWithTasks persists a contract and its tasks idempotently.
 func (s *PGStore) UpsertContractWithTasks(ctx context.Context, contract smart_contract.Contract, tasks []smart_contract.Task) error {
+	if err := ValidateClaimTTL(contract.Metadata); err != nil {
+		return err
+	}
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
+	// Every task must belong to this contract or one that already exists.
+	for _, t := range tasks {
+		if t.ContractID == "" || t.ContractID == contract.ContractID {
+			continue
+		}
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM mcp_contracts WHERE contract_id=$1)`, t.ContractID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: task %s references %s", ErrUnknownContract, t.TaskID, t.ContractID)
+		}
+	}
+
 	// Use Provided CreatedAt if set, otherwise NOW()
 	createdAt := "now()"
 	if !contract.CreatedAt.IsZero() {
@@ -1229,8 +1554,8 @@ func (s *PGStore) UpsertContractWithTasks(ctx context.Context, contract smart_co
 	}
 
 	query := fmt.Sprintf(`
-INSERT INTO mcp_contracts (contract_id, title, total_budget_sats, goals_count, available_tasks_count, status, skills, stego_image_url, created_at)
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8, %s)
+INSERT INTO mcp_contracts (contract_id, title, total_budget_sats, goals_count, available_tasks_count, status, skills, stego_image_url, metadata, created_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,COALESCE($9::jsonb, '{}'::jsonb), %s)
 ON CONFLICT (contract_id) DO UPDATE SET
   title = EXCLUDED.title,
   total_budget_sats = EXCLUDED.total_budget_sats,
@@ -1238,15 +1563,29 @@ ON CONFLICT (contract_id) DO UPDATE SET
   available_tasks_count = EXCLUDED.available_tasks_count,
   status = EXCLUDED.status,
   skills = EXCLUDED.skills,
-  stego_image_url = EXCLUDED.stego_image_url
+  stego_image_url = EXCLUDED.stego_image_url,
+  metadata = COALESCE(mcp_contracts.metadata, '{}'::jsonb) || $10::jsonb
  `, createdAt)
 
-	_, err = tx.Exec(ctx, query, contract.ContractID, contract.Title, contract.TotalBudgetSats, contract.GoalsCount, contract.AvailableTasksCount, contract.Status, contract.Skills, contract.StegoImageURL)
+	// Metadata is only written in full on insert, matching the SQLite store,
+	// so resyncs do not clobber rework requests or other stored keys; an
+	// existing contract only takes the claim TTL override.
+	var metaArg *string
+	if len(contract.Metadata) > 0 {
+		if metaJSON, err := json.Marshal(contract.Metadata); err == nil {
+			m := string(metaJSON)
+			metaArg = &m
+		}
+	}
+	_, err = tx.Exec(ctx, query, contract.ContractID, contract.Title, contract.TotalBudgetSats, contract.GoalsCount, contract.AvailableTasksCount, contract.Status, contract.Skills, contract.StegoImageURL, metaArg, claimTTLPatch(contract.Metadata))
 	if err != nil {
 		return err
 	}
 
 	for _, t := range tasks {
+		if t.ContractID == "" {
+			t.ContractID = contract.ContractID
+		}
 		// Encode JSONB parameters as *string so that nil → SQL NULL
 		// (letting COALESCE preserve existing data), while non-nil values
 		// produce a valid JSON string.  Previously string(nil) produced ""
@@ -1365,6 +1704,13 @@ ON CONFLICT (submission_id) DO UPDATE SET
 
 // UpsertTask persists a single task, useful for syncing individual task updates.
 func (s *PGStore) UpsertTask(ctx context.Context, t smart_contract.Task) error {
+	// During cross-node sync tasks may arrive before their contracts; create a
+	// placeholder so the task never dangles.
+	if t.ContractID != "" {
+		if _, err := s.pool.Exec(ctx, `INSERT INTO mcp_contracts (contract_id, title, total_budget_sats, goals_count, status, stego_image_url) VALUES ($1, '', 0, 0, 'pending', '') ON CONFLICT (contract_id) DO NOTHING`, t.ContractID); err != nil {
+			return err
+		}
+	}
 	// Prevent overwriting claimed tasks with different claim information
 	if strings.EqualFold(t.Status, "claimed") && t.ClaimedBy != "" {
 		var currentClaimedBy string
@@ -1397,15 +1743,16 @@ WHERE task_id=$1 AND claimed_by IS NOT NULL
 		}
 	}
 	_, err := s.pool.Exec(ctx, `
-INSERT INTO mcp_tasks (task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof)
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15)
+INSERT INTO mcp_tasks (task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof, last_activity_at)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16)
 ON CONFLICT (task_id) DO UPDATE SET
   status = EXCLUDED.status,
   claimed_by = COALESCE(EXCLUDED.claimed_by, mcp_tasks.claimed_by),
   claimed_at = COALESCE(EXCLUDED.claimed_at, mcp_tasks.claimed_at),
   claim_expires_at = COALESCE(EXCLUDED.claim_expires_at, mcp_tasks.claim_expires_at),
-  merkle_proof = COALESCE(EXCLUDED.merkle_proof, mcp_tasks.merkle_proof)
- `, t.TaskID, t.ContractID, t.GoalID, t.Title, t.Description, t.BudgetSats, t.Skills, t.Status, t.ClaimedBy, t.ClaimedAt, t.ClaimExpires, t.Difficulty, t.EstimatedHours, reqArg, proofArg)
+  merkle_proof = COALESCE(EXCLUDED.merkle_proof, mcp_tasks.merkle_proof),
+  last_activity_at = COALESCE(EXCLUDED.last_activity_at, mcp_tasks.last_activity_at)
+ `, t.TaskID, t.ContractID, t.GoalID, t.Title, t.Description, t.BudgetSats, t.Skills, t.Status, t.ClaimedBy, t.ClaimedAt, t.ClaimExpires, t.Difficulty, t.EstimatedHours, reqArg, proofArg, t.LastActivityAt)
 	return err
 }
 
@@ -1423,8 +1770,18 @@ func (s *PGStore) UpdateTaskProof(ctx context.Context, taskID string, proof *sma
 	if err != nil {
 		return err
 	}
-	_, err = s.pool.Exec(ctx, `UPDATE mcp_tasks SET merkle_proof=$2 WHERE task_id=$1`, taskID, string(b))
-	return err
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, `UPDATE mcp_tasks SET merkle_proof=$2 WHERE task_id=$1`, taskID, string(b)); err != nil {
+		return err
+	}
+	if err := touchActivityPG(ctx, tx, taskID, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 // UpdateContractStatus updates the status for a contract.
@@ -1478,7 +1835,7 @@ func (s *PGStore) ConfirmContract(ctx context.Context, contractID string, blockH
 	// Update status and confirmation tracking, including metadata with confirmed_txid
 	tag, err := s.pool.Exec(ctx, `
 UPDATE mcp_contracts 
-SET status='confirmed', confirmed_block_height=$2, confirmed_at=NOW(), 
+SET status='confirmed', confirmed_block_height=$2, confirmed_at=NOW(), last_activity_at=NOW(),
     stego_image_url=COALESCE($3, stego_image_url),
     metadata = jsonb_set(COALESCE(metadata, '{}'::jsonb), '{confirmed_txid}', to_jsonb($4::text))
 WHERE contract_id=$1`, contractID, blockHeight, stegoImageURL, txid)
@@ -1519,30 +1876,13 @@ WHERE status='approved' AND (
 
 // Proposal operations
 func (s *PGStore) CreateProposal(ctx context.Context, p smart_contract.Proposal) error {
-	if p.Metadata == nil {
-		p.Metadata = map[string]interface{}{}
-	}
-	if strings.TrimSpace(p.VisiblePixelHash) != "" {
-		if vph, ok := p.Metadata["visible_pixel_hash"].(string); !ok || strings.TrimSpace(vph) == "" {
-			p.Metadata["visible_pixel_hash"] = p.VisiblePixelHash
-		}
-	}
-	if metaContract, ok := p.Metadata["contract_id"].(string); ok {
-		metaContract = strings.TrimSpace(metaContract)
-		if metaContract != "" {
-			if metaHash, ok2 := p.Metadata["visible_pixel_hash"].(string); ok2 {
-				metaHash = strings.TrimSpace(metaHash)
-				normalizedContract := strings.TrimPrefix(metaContract, "wish-")
-				if metaHash != "" && metaHash != normalizedContract {
-					return fmt.Errorf("visible_pixel_hash must match contract_id when both are set (normalized: %s)", normalizedContract)
-				}
-			}
-		}
+	if err := ApplyProposalIdentity(&p); err != nil {
+		return err
 	}
 
 	// Comprehensive security validation - this sanitizes inputs in-place
 	if err := ValidateProposalInput(&p); err != nil {
-		return fmt.Errorf("proposal validation failed: %v", err)
+		return fmt.Errorf("proposal validation failed: %w", err)
 	}
 
 	// Validate status field
@@ -1695,6 +2035,30 @@ func (s *PGStore) ListProposals(ctx context.Context, filter smart_contract.Propo
 	return out, rows.Err()
 }
 
+// CountProposals counts proposals matching filter with SELECT COUNT(*),
+// using the same conditions as ListProposals.
+func (s *PGStore) CountProposals(ctx context.Context, filter smart_contract.ProposalFilter) (int, error) {
+	if len(filter.Skills) > 0 || filter.ContractID != "" {
+		return countProposalsByListing(ctx, s.ListProposals, filter)
+	}
+	query := `SELECT COUNT(*) FROM mcp_proposals WHERE 1=1`
+	var args []interface{}
+	if filter.ProposalID != "" {
+		args = append(args, filter.ProposalID)
+		query += fmt.Sprintf(" AND id=$%d", len(args))
+	} else if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(" AND status=$%d", len(args))
+	}
+	if filter.MinBudget > 0 {
+		args = append(args, filter.MinBudget)
+		query += fmt.Sprintf(" AND budget_sats >= $%d", len(args))
+	}
+	var n int
+	err := s.pool.QueryRow(ctx, query, args...).Scan(&n)
+	return n, err
+}
+
 func (s *PGStore) GetProposal(ctx context.Context, id string) (smart_contract.Proposal, error) {
 	var p smart_contract.Proposal
 	var meta []byte
@@ -1768,17 +2132,12 @@ FROM mcp_proposals WHERE id=$1 FOR UPDATE
 	if p.Status == "" {
 		p.Status = current.Status
 	}
-	if p.Metadata == nil {
-		p.Metadata = map[string]interface{}{}
-	}
-	if strings.TrimSpace(p.VisiblePixelHash) != "" {
-		if vph, ok := p.Metadata["visible_pixel_hash"].(string); !ok || strings.TrimSpace(vph) == "" {
-			p.Metadata["visible_pixel_hash"] = p.VisiblePixelHash
-		}
+	if err := ApplyProposalIdentity(&p); err != nil {
+		return err
 	}
 
 	if err := ValidateProposalInput(&p); err != nil {
-		return fmt.Errorf("proposal validation failed: %v", err)
+		return fmt.Errorf("proposal validation failed: %w", err)
 	}
 
 	metaMap := p.Metadata
@@ -1833,6 +2192,10 @@ FROM mcp_proposals WHERE id=$1 FOR UPDATE
 	if meta == nil {
 		meta = map[string]interface{}{}
 	}
+	updates, err = checkMetadataIdentity(visiblePixelHash, meta, updates)
+	if err != nil {
+		return err
+	}
 	for k, v := range updates {
 		meta[k] = v
 	}
@@ -1928,7 +2291,7 @@ WHERE id<>$1 AND status='pending' AND (
 
 	// Validate proposal for approval without modifying status
 	if err := ValidateProposalForApproval(&proposal); err != nil {
-		return fmt.Errorf("proposal validation failed: %v", err)
+		return fmt.Errorf("proposal validation failed: %w", err)
 	}
 	if len(proposal.Tasks) == 0 {
 		var taskCount int
@@ -1967,9 +2330,10 @@ func (s *PGStore) PublishProposal(ctx context.Context, id string) error {
 	}
 	defer tx.Rollback(ctx)
 
-	var status string
+	var status, title string
+	var budget int64
 	var metaJSON []byte
-	if err := tx.QueryRow(ctx, `SELECT status, metadata FROM mcp_proposals WHERE id=$1`, id).Scan(&status, &metaJSON); err != nil {
+	if err := tx.QueryRow(ctx, `SELECT status, title, COALESCE(budget_sats, 0), metadata FROM mcp_proposals WHERE id=$1`, id).Scan(&status, &title, &budget, &metaJSON); err != nil {
 		return err
 	}
 	if !strings.EqualFold(status, "approved") && !strings.EqualFold(status, "published") {
@@ -1980,6 +2344,20 @@ func (s *PGStore) PublishProposal(ctx context.Context, id string) error {
 	_ = json.Unmarshal(metaJSON, &meta)
 	contractID := contractIDFromMeta(meta, id)
 
+	var taskCount int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM mcp_tasks WHERE contract_id=$1`, contractID).Scan(&taskCount); err != nil {
+		return err
+	}
+	if err := ValidateContractTaskCount(contractID, taskCount); err != nil {
+		return err
+	}
+	if taskCount > 0 {
+		// Published tasks must not dangle: create their contract from the proposal.
+		if _, err := tx.Exec(ctx, `INSERT INTO mcp_contracts (contract_id, title, total_budget_sats, goals_count, status, stego_image_url) VALUES ($1,$2,$3,0,'pending','') ON CONFLICT (contract_id) DO NOTHING`, contractID, title, budget); err != nil {
+			return err
+		}
+	}
+
 	if _, err := tx.Exec(ctx, `UPDATE mcp_tasks SET status='published' WHERE contract_id=$1 AND status IN ('submitted','pending_review','claimed','approved')`, contractID); err != nil {
 		return err
 	}
@@ -1998,20 +2376,21 @@ func (s *PGStore) PublishProposal(ctx context.Context, id string) error {
 }
 
 // populateProposalTasks hydrates Tasks from metadata suggested_tasks or embedded_message.
+// Metadata is copied before it is filled in: the memory store hands out
+// proposals that share their map with the stored copy, and readers hydrate
+// them under a read lock.
 func populateProposalTasks(p *smart_contract.Proposal) {
 	if p == nil {
 		return
 	}
+	p.Metadata = maps.Clone(p.Metadata)
+	if p.Metadata == nil {
+		p.Metadata = map[string]interface{}{}
+	}
 	if p.BudgetSats == 0 {
 		p.BudgetSats = DefaultBudgetSats()
-		if p.Metadata == nil {
-			p.Metadata = map[string]interface{}{}
-		}
 		p.Metadata["budget_sats"] = p.BudgetSats
 	}
-	if p.Metadata == nil {
-		p.Metadata = map[string]interface{}{}
-	}
 	if _, ok := p.Metadata["funding_address"]; !ok {
 		p.Metadata["funding_address"] = FundingAddressFromMeta(p.Metadata)
 	}
@@ -2046,7 +2425,7 @@ func scanTask(scanner interface {
 	var estimatedHours sql.NullInt32
 	if err := scanner.Scan(
 		&t.TaskID, &t.ContractID, &t.GoalID, &t.Title, &t.Description, &t.BudgetSats, &t.Skills, &t.Status,
-		&claimedBy, &claimedAt, &claimExpires, &difficulty, &estimatedHours, &reqJSON, &proofJSON,
+		&claimedBy, &claimedAt, &claimExpires, &difficulty, &estimatedHours, &reqJSON, &proofJSON, &t.LastActivityAt,
 	); err != nil {
 		return smart_contract.Task{}, err
 	}
@@ -2082,6 +2461,51 @@ func scanTask(scanner interface {
 	return t, nil
 }
 
+func scanContract(scanner interface {
+	Scan(dest ...interface{}) error
+}) (smart_contract.Contract, error) {
+	var c smart_contract.Contract
+	var metadata []byte
+	if err := scanner.Scan(&c.ContractID, &c.Title, &c.TotalBudgetSats, &c.GoalsCount, &c.AvailableTasksCount,
+		&c.Status, &c.Skills, &c.StegoImageURL, &metadata, &c.ConfirmedBlockHeight, &c.ConfirmedAt, &c.CreatedAt, &c.LastActivityAt); err != nil {
+		return smart_contract.Contract{}, err
+	}
+	if len(metadata) > 0 {
+		_ = json.Unmarshal(metadata, &c.Metadata)
+	}
+	return c, nil
+}
+
+func scanSubmission(scanner interface {
+	Scan(dest ...interface{}) error
+}) (smart_contract.Submission, error) {
+	var sub smart_contract.Submission
+	var delivJSON, proofJSON []byte
+	var rejectionReason sql.NullString
+	var rejectionType sql.NullString
+	var rejectedAt sql.NullTime
+	if err := scanner.Scan(&sub.SubmissionID, &sub.ClaimID, &sub.TaskID, &sub.Status, &delivJSON, &proofJSON, &rejectionReason, &rejectionType, &rejectedAt, &sub.CreatedAt); err != nil {
+		return smart_contract.Submission{}, err
+	}
+	if rejectionReason.Valid {
+		sub.RejectionReason = rejectionReason.String
+	}
+	if rejectionType.Valid {
+		sub.RejectionType = rejectionType.String
+	}
+	if rejectedAt.Valid {
+		t := rejectedAt.Time
+		sub.RejectedAt = &t
+	}
+	if len(delivJSON) > 0 {
+		_ = json.Unmarshal(delivJSON, &sub.Deliverables)
+	}
+	if len(proofJSON) > 0 {
+		_ = json.Unmarshal(proofJSON, &sub.CompletionProof)
+	}
+	return sub, nil
+}
+
 // attachActiveClaims enriches tasks with active claim ids from the claims table.
 func (s *PGStore) attachActiveClaims(ctx context.Context, tasks []smart_contract.Task, taskIDs []string) []smart_contract.Task {
 	if len(tasks) == 0 || len(taskIDs) == 0 {
@@ -2186,6 +2610,12 @@ WHERE submission_id=$1
 `, submissionID, status, rejectionReason, rejectionType, rejectedAt); err != nil {
 		return err
 	}
+	var reviewedTaskID string
+	if err := tx.QueryRow(ctx, `SELECT task_id FROM mcp_claims WHERE claim_id=$1`, claimID).Scan(&reviewedTaskID); err == nil {
+		if err := touchActivityPG(ctx, tx, reviewedTaskID, time.Now()); err != nil {
+			return err
+		}
+	}
 
 	// On approval, update task and claim
 	if status == "accepted" || status == "approved" {
@@ -0,0 +1,86 @@
+package smart_contract
+
+import (
+	"fmt"
+	"strings"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// ApplyProposalIdentity enforces the one identity invariant for proposals:
+// contract_id IS the visible_pixel_hash. The hash may be given as the
+// VisiblePixelHash field, metadata visible_pixel_hash or metadata contract_id,
+// where a "wish-" prefixed contract_id names the same hash. Every value that is
+// set must agree, otherwise ErrIdentityMismatch is returned; on success both
+// metadata keys are rewritten to the bare hash. The VisiblePixelHash field is
+// left as given, since stores key per-wish limits on it.
+func ApplyProposalIdentity(p *smart_contract.Proposal) error {
+	if p.Metadata == nil {
+		p.Metadata = map[string]interface{}{}
+	}
+	metaHash, _ := p.Metadata["visible_pixel_hash"].(string)
+	metaContract, _ := p.Metadata["contract_id"].(string)
+	sources := []struct{ name, value string }{
+		{"visible_pixel_hash", strings.TrimSpace(p.VisiblePixelHash)},
+		{"metadata.visible_pixel_hash", strings.TrimSpace(metaHash)},
+		{"contract_id", strings.TrimPrefix(strings.TrimSpace(metaContract), "wish-")},
+	}
+	hash, from := "", ""
+	for _, src := range sources {
+		if src.value == "" {
+			continue
+		}
+		if hash == "" {
+			hash, from = src.value, src.name
+			continue
+		}
+		if src.value != hash {
+			return fmt.Errorf("%w: %s %q differs from %s %q", ErrIdentityMismatch, src.name, src.value, from, hash)
+		}
+	}
+	if hash == "" {
+		return nil
+	}
+	p.Metadata["visible_pixel_hash"] = hash
+	p.Metadata["contract_id"] = hash
+	return nil
+}
+
+// checkMetadataIdentity validates the identity keys of a metadata update
+// against the proposal's stored hash: the VisiblePixelHash field, else the
+// stored metadata visible_pixel_hash. Updates naming another hash return
+// ErrIdentityMismatch; otherwise a copy of updates is returned with the
+// identity keys rewritten to the bare hash.
+func checkMetadataIdentity(storedHash string, stored, updates map[string]interface{}) (map[string]interface{}, error) {
+	_, hasHash := updates["visible_pixel_hash"]
+	_, hasContract := updates["contract_id"]
+	if !hasHash && !hasContract {
+		return updates, nil
+	}
+	hash := strings.TrimSpace(storedHash)
+	if hash == "" {
+		metaHash, _ := stored["visible_pixel_hash"].(string)
+		hash = strings.TrimSpace(metaHash)
+	}
+	probe := smart_contract.Proposal{VisiblePixelHash: hash, Metadata: map[string]interface{}{}}
+	if hasHash {
+		probe.Metadata["visible_pixel_hash"] = updates["visible_pixel_hash"]
+	}
+	if hasContract {
+		probe.Metadata["contract_id"] = updates["contract_id"]
+	}
+	if err := ApplyProposalIdentity(&probe); err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{}, len(updates))
+	for k, v := range updates {
+		out[k] = v
+	}
+	if hasHash {
+		out["visible_pixel_hash"] = probe.Metadata["visible_pixel_hash"]
+	}
+	if hasContract {
+		out["contract_id"] = probe.Metadata["contract_id"]
+	}
+	return out, nil
+}
@@ -0,0 +1,109 @@
+package smart_contract
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+func TestApplyProposalIdentity(t *testing.T) {
+	hashA, hashB := strings.Repeat("a", 64), strings.Repeat("b", 64)
+	tests := []struct {
+		name    string
+		field   string
+		meta    map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{"field only", hashA, nil, hashA, false},
+		{"metadata hash only", "", map[string]interface{}{"visible_pixel_hash": hashA}, hashA, false},
+		{"contract only", "", map[string]interface{}{"contract_id": hashA}, hashA, false},
+		{"wish prefix", hashA, map[string]interface{}{"contract_id": "wish-" + hashA}, hashA, false},
+		{"none", "", map[string]interface{}{"title_strategy": "first_line"}, "", false},
+		{"contract mismatch", hashA, map[string]interface{}{"contract_id": hashB}, "", true},
+		{"metadata mismatch", hashA, map[string]interface{}{"visible_pixel_hash": hashB}, "", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := smart_contract.Proposal{VisiblePixelHash: tc.field, Metadata: tc.meta}
+			err := ApplyProposalIdentity(&p)
+			if tc.wantErr {
+				if !errors.Is(err, ErrIdentityMismatch) {
+					t.Fatalf("err = %v, want ErrIdentityMismatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			contractID, _ := p.Metadata["contract_id"].(string)
+			metaHash, _ := p.Metadata["visible_pixel_hash"].(string)
+			if contractID != tc.want || metaHash != tc.want {
+				t.Fatalf("contract_id %q, visible_pixel_hash %q; want both %q", contractID, metaHash, tc.want)
+			}
+		})
+	}
+}
+
+func TestStoresEnforceProposalIdentity(t *testing.T) {
+	ctx := context.Background()
+	hashA, hashB := strings.Repeat("a", 64), strings.Repeat("b", 64)
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, false)
+	if err != nil {
+		t.Fatalf("sqlite store: %v", err)
+	}
+	for name, store := range map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			err := store.CreateProposal(ctx, smart_contract.Proposal{
+				ID: "identity-bad", Title: "Bad", VisiblePixelHash: hashA, Status: "pending",
+				Metadata: map[string]interface{}{"contract_id": hashB},
+			})
+			if !errors.Is(err, ErrIdentityMismatch) {
+				t.Fatalf("mismatched create err = %v, want ErrIdentityMismatch", err)
+			}
+
+			if err := store.CreateProposal(ctx, smart_contract.Proposal{
+				ID: "identity-ok", Title: "Ok", VisiblePixelHash: hashA, Status: "pending",
+				Metadata: map[string]interface{}{"contract_id": "wish-" + hashA},
+			}); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+			p, err := store.GetProposal(ctx, "identity-ok")
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			if got, _ := p.Metadata["contract_id"].(string); got != hashA {
+				t.Fatalf("stored contract_id = %q, want bare hash %q", got, hashA)
+			}
+
+			p.Metadata["contract_id"] = hashB
+			if err := store.UpdateProposal(ctx, p); !errors.Is(err, ErrIdentityMismatch) {
+				t.Fatalf("mismatched update err = %v, want ErrIdentityMismatch", err)
+			}
+
+			for _, updates := range []map[string]interface{}{
+				{"visible_pixel_hash": hashB},
+				{"contract_id": hashB, "stego_image_cid": "cid"},
+			} {
+				if err := store.UpdateProposalMetadata(ctx, "identity-ok", updates); !errors.Is(err, ErrIdentityMismatch) {
+					t.Fatalf("mismatched metadata merge %v err = %v, want ErrIdentityMismatch", updates, err)
+				}
+			}
+			if err := store.UpdateProposalMetadata(ctx, "identity-ok", map[string]interface{}{"contract_id": "wish-" + hashA, "stego_image_cid": "cid"}); err != nil {
+				t.Fatalf("matching metadata merge: %v", err)
+			}
+			p, err = store.GetProposal(ctx, "identity-ok")
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			if p.Metadata["contract_id"] != hashA || p.Metadata["visible_pixel_hash"] != hashA || p.Metadata["stego_image_cid"] != "cid" {
+				t.Fatalf("merged metadata = %v", p.Metadata)
+			}
+		})
+	}
+}
@@ -106,29 +106,13 @@ FROM mcp_proposals WHERE id=$1
 
 // Create stores a new proposal with validation
 func (r *ProposalsRepository) Create(ctx context.Context, p smart_contract.Proposal) error {
-	if p.Metadata == nil {
-		p.Metadata = map[string]interface{}{}
-	}
-	if strings.TrimSpace(p.VisiblePixelHash) != "" {
-		if vph, ok := p.Metadata["visible_pixel_hash"].(string); !ok || strings.TrimSpace(vph) == "" {
-			p.Metadata["visible_pixel_hash"] = p.VisiblePixelHash
-		}
-	}
-	if metaContract, ok := p.Metadata["contract_id"].(string); ok {
-		metaContract = strings.TrimSpace(metaContract)
-		if metaContract != "" {
-			if metaHash, ok2 := p.Metadata["visible_pixel_hash"].(string); ok2 {
-				metaHash = strings.TrimSpace(metaHash)
-				if metaHash != "" && metaHash != metaContract {
-					return fmt.Errorf("visible_pixel_hash must match contract_id when both are set")
-				}
-			}
-		}
+	if err := ApplyProposalIdentity(&p); err != nil {
+		return err
 	}
 
 	// Comprehensive security validation
 	if err := ValidateProposalInput(&p); err != nil {
-		return fmt.Errorf("proposal validation failed: %v", err)
+		return fmt.Errorf("proposal validation failed: %w", err)
 	}
 
 	// Validate status field
@@ -0,0 +1,167 @@
+package smart_contract
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// Repair kinds reported in RepairAction.Kind.
+const (
+	RepairTaskWithoutContract   = "task_without_contract"
+	RepairSubmissionWithoutTask = "submission_without_task"
+	RepairProposalHashMismatch  = "proposal_hash_mismatch"
+)
+
+// RepairAction describes one inconsistency and what Repair did (or would do)
+// about it. Fixed is only true after a non-dry run applied the fix.
+type RepairAction struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix"`
+	Fixed  bool   `json:"fixed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// RepairReport is the result of a Repair pass.
+type RepairReport struct {
+	DryRun  bool           `json:"dry_run"`
+	Actions []RepairAction `json:"actions"`
+	Fixed   int            `json:"fixed"`
+}
+
+// RepairStore is a Store that can also report dangling references, which the
+// plain Store interface has no way to enumerate.
+type RepairStore interface {
+	Store
+	ConsistencyChecker
+}
+
+// Repair scans for data drift left by lazy publishing, reconciliation and
+// dual-store writes, and fixes what it finds unless dryRun is set:
+//
+//   - tasks whose contract is missing get a pending placeholder contract,
+//     titled from the proposal of the same ID when one exists;
+//   - submissions whose claim or task is missing are rejected so they leave
+//     the review queue (already rejected ones are not reported again);
+//   - proposals whose visible_pixel_hash metadata disagrees with the stored
+//     field have the metadata rewritten to match the field, unless the
+//     metadata contract_id vouches for the metadata value.
+//
+// Fixes go through the Store interface only. A failed fix is recorded on its
+// action and does not stop the pass.
+func Repair(ctx context.Context, store RepairStore, dryRun bool) (RepairReport, error) {
+	report := RepairReport{DryRun: dryRun, Actions: []RepairAction{}}
+	consistency, err := store.CheckConsistency(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	apply := func(action RepairAction, fix func() error) {
+		if !dryRun {
+			if err := fix(); err != nil {
+				action.Error = err.Error()
+			} else {
+				action.Fixed = true
+				report.Fixed++
+			}
+		}
+		report.Actions = append(report.Actions, action)
+	}
+
+	// Group orphan tasks by contract so each placeholder is created once.
+	orphansByContract := make(map[string][]string)
+	for _, o := range consistency.OrphanTasks {
+		orphansByContract[o.ContractID] = append(orphansByContract[o.ContractID], o.TaskID)
+	}
+	contractIDs := make([]string, 0, len(orphansByContract))
+	for id := range orphansByContract {
+		contractIDs = append(contractIDs, id)
+	}
+	sort.Strings(contractIDs)
+	for _, contractID := range contractIDs {
+		taskIDs := orphansByContract[contractID]
+		if strings.TrimSpace(contractID) == "" {
+			for _, taskID := range taskIDs {
+				report.Actions = append(report.Actions, RepairAction{
+					Kind:   RepairTaskWithoutContract,
+					ID:     taskID,
+					Detail: "task has no contract_id",
+					Fix:    "none; needs manual review",
+				})
+			}
+			continue
+		}
+		placeholder := smart_contract.Contract{ContractID: contractID, Status: "pending"}
+		if p, err := store.GetProposal(ctx, contractID); err == nil {
+			placeholder.Title = p.Title
+			placeholder.TotalBudgetSats = p.BudgetSats
+		}
+		apply(RepairAction{
+			Kind:   RepairTaskWithoutContract,
+			ID:     contractID,
+			Detail: fmt.Sprintf("contract %s is missing for tasks %s", contractID, strings.Join(taskIDs, ", ")),
+			Fix:    "create pending placeholder contract",
+		}, func() error {
+			return store.UpsertContractWithTasks(ctx, placeholder, nil)
+		})
+	}
+
+	for _, o := range consistency.OrphanSubmissions {
+		if strings.EqualFold(o.Status, "rejected") {
+			continue // already taken out of review by an earlier repair
+		}
+		detail := fmt.Sprintf("claim %s is missing", o.ClaimID)
+		if o.TaskID != "" {
+			detail = fmt.Sprintf("task %s of claim %s is missing", o.TaskID, o.ClaimID)
+		}
+		submissionID := o.SubmissionID
+		apply(RepairAction{
+			Kind:   RepairSubmissionWithoutTask,
+			ID:     submissionID,
+			Detail: detail,
+			Fix:    "reject submission",
+		}, func() error {
+			return store.UpdateSubmissionStatus(ctx, submissionID, "rejected", "repair: "+detail, "")
+		})
+	}
+
+	proposals, err := store.ListProposals(ctx, smart_contract.ProposalFilter{})
+	if err != nil {
+		return report, err
+	}
+	sort.Slice(proposals, func(i, j int) bool { return proposals[i].ID < proposals[j].ID })
+	for _, p := range proposals {
+		field := strings.TrimSpace(p.VisiblePixelHash)
+		meta, _ := p.Metadata["visible_pixel_hash"].(string)
+		meta = strings.TrimSpace(meta)
+		if field == "" || meta == "" || field == meta {
+			continue
+		}
+		id := p.ID
+		if contractID, _ := p.Metadata["contract_id"].(string); strings.TrimPrefix(strings.TrimSpace(contractID), "wish-") == meta {
+			// The metadata hash is backed by contract_id, so the stored field
+			// is the stale one; that needs a human to pick the right image.
+			report.Actions = append(report.Actions, RepairAction{
+				Kind:   RepairProposalHashMismatch,
+				ID:     id,
+				Detail: fmt.Sprintf("visible_pixel_hash is %s but metadata and contract_id have %s", field, meta),
+				Fix:    "none; needs manual review",
+			})
+			continue
+		}
+		apply(RepairAction{
+			Kind:   RepairProposalHashMismatch,
+			ID:     id,
+			Detail: fmt.Sprintf("visible_pixel_hash is %s but metadata has %s", field, meta),
+			Fix:    "set metadata visible_pixel_hash to " + field,
+		}, func() error {
+			return store.UpdateProposalMetadata(ctx, id, map[string]interface{}{"visible_pixel_hash": field})
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,161 @@
+package smart_contract
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+func TestRepairReportsAndFixesDrift(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+	memoryStore := NewMemoryStore(time.Hour)
+	fieldHash, metaHash := strings.Repeat("a", 64), strings.Repeat("b", 64)
+
+	stores := map[string]struct {
+		store RepairStore
+		drop  func(table, column, id string)
+		drift func(id, hash string)
+	}{
+		"memory": {memoryStore, func(table, column, id string) {
+			memoryStore.mu.Lock()
+			defer memoryStore.mu.Unlock()
+			switch table {
+			case TableContracts:
+				delete(memoryStore.contracts, id)
+			case TableTasks:
+				delete(memoryStore.tasks, id)
+			}
+		}, func(id, hash string) {
+			memoryStore.mu.Lock()
+			defer memoryStore.mu.Unlock()
+			memoryStore.proposals[id].Metadata["visible_pixel_hash"] = hash
+		}},
+		"sqlite": {sqliteStore, func(table, column, id string) {
+			if _, err := sqliteStore.db.Exec(`DELETE FROM `+table+` WHERE `+column+`=?`, id); err != nil {
+				t.Fatalf("delete from %s: %v", table, err)
+			}
+		}, func(id, hash string) {
+			if _, err := sqliteStore.db.Exec(`UPDATE mcp_proposals SET metadata=json_set(metadata, '$.visible_pixel_hash', ?) WHERE id=?`, hash, id); err != nil {
+				t.Fatalf("write mismatched metadata: %v", err)
+			}
+		}},
+	}
+	for name, tc := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := tc.store
+			// A task whose contract disappears, with a proposal of the same ID.
+			contract := smart_contract.Contract{ContractID: "drift-contract", Title: "Drift", Status: "active"}
+			if err := store.UpsertContractWithTasks(ctx, contract, []smart_contract.Task{{TaskID: "drift-task", Title: "Orphan", Status: "available"}}); err != nil {
+				t.Fatalf("upsert contract: %v", err)
+			}
+			if err := store.CreateProposal(ctx, smart_contract.Proposal{
+				ID:         "drift-contract",
+				Title:      "Drift proposal",
+				BudgetSats: 1200,
+				Status:     "pending",
+				Metadata:   map[string]interface{}{"image_scan_data": "scan"},
+			}); err != nil {
+				t.Fatalf("create proposal: %v", err)
+			}
+			tc.drop(TableContracts, "contract_id", contract.ContractID)
+
+			// A submission whose task disappears.
+			claim, err := store.ClaimTask(SeedTaskBollingerID, "wallet-1", nil)
+			if err != nil {
+				t.Fatalf("claim: %v", err)
+			}
+			sub, err := store.SubmitWork(claim.ClaimID, map[string]interface{}{"notes": "done"}, nil)
+			if err != nil {
+				t.Fatalf("submit: %v", err)
+			}
+			tc.drop(TableTasks, "task_id", SeedTaskBollingerID)
+
+			// A proposal whose metadata hash disagrees with the stored field.
+			// Creation and metadata updates reject that, so the drift is
+			// written straight into the row as legacy data would carry it.
+			if err := store.CreateProposal(ctx, smart_contract.Proposal{
+				ID:               "drift-proposal",
+				Title:            "Mismatch",
+				VisiblePixelHash: fieldHash,
+				Status:           "pending",
+			}); err != nil {
+				t.Fatalf("create proposal: %v", err)
+			}
+			tc.drift("drift-proposal", metaHash)
+
+			report, err := Repair(ctx, store, true)
+			if err != nil {
+				t.Fatalf("dry run: %v", err)
+			}
+			want := map[string]string{
+				RepairTaskWithoutContract:   "drift-contract",
+				RepairSubmissionWithoutTask: sub.SubmissionID,
+				RepairProposalHashMismatch:  "drift-proposal",
+			}
+			for kind, id := range want {
+				if !hasRepairAction(report, kind, id) {
+					t.Fatalf("dry run missing %s %s: %+v", kind, id, report.Actions)
+				}
+			}
+			if report.Fixed != 0 {
+				t.Fatalf("dry run fixed %d records", report.Fixed)
+			}
+			if _, err := store.GetContract("drift-contract"); err == nil {
+				t.Fatalf("dry run created the placeholder contract")
+			}
+
+			report, err = Repair(ctx, store, false)
+			if err != nil {
+				t.Fatalf("repair: %v", err)
+			}
+			for _, a := range report.Actions {
+				if !a.Fixed {
+					t.Fatalf("action not fixed: %+v", a)
+				}
+			}
+			if report.Fixed != len(want) {
+				t.Fatalf("fixed = %d, want %d: %+v", report.Fixed, len(want), report.Actions)
+			}
+			placeholder, err := store.GetContract("drift-contract")
+			if err != nil {
+				t.Fatalf("placeholder contract: %v", err)
+			}
+			if placeholder.Title != "Drift proposal" || placeholder.Status != "pending" {
+				t.Fatalf("placeholder = %+v", placeholder)
+			}
+			p, err := store.GetProposal(ctx, "drift-proposal")
+			if err != nil {
+				t.Fatalf("get proposal: %v", err)
+			}
+			if got := p.Metadata["visible_pixel_hash"]; got != fieldHash {
+				t.Fatalf("metadata visible_pixel_hash = %v, want %s", got, fieldHash)
+			}
+
+			report, err = Repair(ctx, store, true)
+			if err != nil {
+				t.Fatalf("second dry run: %v", err)
+			}
+			if len(report.Actions) != 0 {
+				t.Fatalf("drift remains after repair: %+v", report.Actions)
+			}
+		})
+	}
+}
+
+func hasRepairAction(report RepairReport, kind, id string) bool {
+	for _, a := range report.Actions {
+		if a.Kind == kind && a.ID == id {
+			return true
+		}
+	}
+	return false
+}
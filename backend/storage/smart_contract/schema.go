@@ -45,6 +45,7 @@ CREATE TABLE IF NOT EXISTS ` + TableContracts + ` (
   confirmed_block_height INTEGER,
   confirmed_at TIMESTAMPTZ,
   created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_activity_at TIMESTAMPTZ,
   metadata JSONB DEFAULT '{}'::jsonb
 );
 
@@ -64,7 +65,8 @@ CREATE TABLE IF NOT EXISTS ` + TableTasks + ` (
   difficulty TEXT,
   estimated_hours INT,
   requirements JSONB,
-  merkle_proof JSONB
+  merkle_proof JSONB,
+  last_activity_at TIMESTAMPTZ
 );
 
 -- Claims
@@ -116,6 +118,10 @@ CREATE INDEX IF NOT EXISTS idx_mcp_contracts_confirmed_height ON ` + TableContra
 CREATE INDEX IF NOT EXISTS idx_mcp_contracts_confirmed_at ON ` + TableContracts + `(confirmed_at DESC);
 CREATE INDEX IF NOT EXISTS idx_mcp_proposals_status ON ` + TableProposals + `(status);
 CREATE INDEX IF NOT EXISTS idx_mcp_tasks_contract_status ON ` + TableTasks + `(contract_id, status);
+
+-- Columns added after the initial schema
+ALTER TABLE ` + TableContracts + ` ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMPTZ;
+ALTER TABLE ` + TableTasks + ` ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMPTZ;
 `
 }
 
@@ -135,6 +141,7 @@ CREATE TABLE IF NOT EXISTS ` + TableContracts + ` (
   confirmed_block_height INTEGER,
   confirmed_at TEXT,
   created_at TEXT NOT NULL DEFAULT (datetime('now')),
+  last_activity_at TEXT,
   metadata TEXT DEFAULT '{}'
 );
 CREATE INDEX IF NOT EXISTS idx_mcp_contracts_confirmed_height ON ` + TableContracts + `(confirmed_block_height DESC);
@@ -155,6 +162,7 @@ CREATE TABLE IF NOT EXISTS ` + TableTasks + ` (
   estimated_hours INTEGER,
   requirements TEXT,
   merkle_proof TEXT,
+  last_activity_at TEXT,
   FOREIGN KEY (contract_id) REFERENCES ` + TableContracts + `(contract_id) ON DELETE CASCADE
 );
 
@@ -290,6 +290,12 @@ func ValidateProposalInput(proposal *smart_contract.Proposal) error {
 		return fmt.Errorf("metadata JSON depth validation failed: %v", err)
 	}
 
+	// The claim TTL override is copied to the contract when the proposal is
+	// published, so reject a bad value while the proposal can still be fixed.
+	if err := ValidateClaimTTL(proposal.Metadata); err != nil {
+		return err
+	}
+
 	// Sanitize metadata string values
 	if proposal.Metadata != nil {
 		for key, value := range proposal.Metadata {
@@ -338,6 +344,10 @@ func ValidateProposalInput(proposal *smart_contract.Proposal) error {
 		return fmt.Errorf("proposals must include image scan metadata (valid visible_pixel_hash or image_scan_data in metadata)")
 	}
 
+	if err := ValidateProposalTaskCount(len(proposal.Tasks)); err != nil {
+		return err
+	}
+
 	// Validate tasks if present
 	if len(proposal.Tasks) > 0 {
 		for i, task := range proposal.Tasks {
@@ -356,6 +366,22 @@ func ValidateProposalInput(proposal *smart_contract.Proposal) error {
 	return nil
 }
 
+// ValidateProposalTaskCount rejects proposals defining more than MaxTasksPerProposal tasks.
+func ValidateProposalTaskCount(n int) error {
+	if limit := MaxTasksPerProposal(); n > limit {
+		return fmt.Errorf("%w: proposal defines %d tasks, maximum is %d", ErrTooManyTasks, n, limit)
+	}
+	return nil
+}
+
+// ValidateContractTaskCount rejects publishing a contract holding more than MaxTasksPerContract tasks.
+func ValidateContractTaskCount(contractID string, n int) error {
+	if limit := MaxTasksPerContract(); n > limit {
+		return fmt.Errorf("%w: contract %s has %d tasks, maximum is %d", ErrTooManyTasks, contractID, n, limit)
+	}
+	return nil
+}
+
 // ValidateProposalForApproval validates a proposal for approval without requiring status change
 func ValidateProposalForApproval(proposal *smart_contract.Proposal) error {
 	// Use the same validation as ValidateProposalInput but without status dependency
@@ -62,8 +62,17 @@ func (s *SQLiteStore) initSchema(ctx context.Context) error {
 	// Use the single source of truth defined in schema.go.
 	// This eliminates the previous massive duplication with the PG schema.
 	schema := GetMCPSchema("sqlite")
-	_, err := s.db.ExecContext(ctx, schema)
-	return err
+	if _, err := s.db.ExecContext(ctx, schema); err != nil {
+		return err
+	}
+	// Databases created before last_activity_at existed need the column added;
+	// SQLite has no ADD COLUMN IF NOT EXISTS.
+	for _, table := range []string{TableContracts, TableTasks} {
+		if _, err := s.db.ExecContext(ctx, `ALTER TABLE `+table+` ADD COLUMN last_activity_at TEXT`); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *SQLiteStore) seedFixtures(ctx context.Context) error {
@@ -80,9 +89,9 @@ func (s *SQLiteStore) seedFixtures(ctx context.Context) error {
 		metadata, _ := json.Marshal(c.Metadata)
 		skills := strings.Join(c.Skills, ",")
 		_, err := s.db.ExecContext(ctx, `
-INSERT OR IGNORE INTO mcp_contracts (contract_id, title, total_budget_sats, goals_count, available_tasks_count, status, skills, stego_image_url, metadata)
-VALUES (?,?,?,?,?,?,?,?,?)
-`, c.ContractID, c.Title, c.TotalBudgetSats, c.GoalsCount, c.AvailableTasksCount, c.Status, skills, c.StegoImageURL, string(metadata))
+INSERT OR IGNORE INTO mcp_contracts (contract_id, title, total_budget_sats, goals_count, available_tasks_count, status, skills, stego_image_url, metadata, created_at)
+VALUES (?,?,?,?,?,?,?,?,?,?)
+`, c.ContractID, c.Title, c.TotalBudgetSats, c.GoalsCount, c.AvailableTasksCount, c.Status, skills, c.StegoImageURL, string(metadata), c.CreatedAt.UTC().Format(time.RFC3339))
 		if err != nil {
 			return err
 		}
@@ -106,6 +115,252 @@ VALUES (?,?,?,?,?,?,?,?,?,?,?,?)
 	return nil
 }
 
+// ResetAndSeed deletes every MCP row and reloads the deterministic fixtures.
+func (s *SQLiteStore) ResetAndSeed(ctx context.Context) (SeedManifest, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return SeedManifest{}, err
+	}
+	defer tx.Rollback()
+	for _, table := range []string{TableEscortStatus, TableSubmissions, TableClaims, TableTasks, TableContracts, TableProposals} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table); err != nil {
+			return SeedManifest{}, fmt.Errorf("reset %s: %w", table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return SeedManifest{}, err
+	}
+	if err := s.seedFixtures(ctx); err != nil {
+		return SeedManifest{}, err
+	}
+	return Manifest(), nil
+}
+
+// CheckConsistency reports tasks without a contract and submissions whose
+// claim or task is missing. The schema declares no foreign keys, so deletes
+// and direct writes can leave either behind.
+func (s *SQLiteStore) CheckConsistency(ctx context.Context) (ConsistencyReport, error) {
+	report := ConsistencyReport{OrphanTasks: []OrphanTask{}, OrphanSubmissions: []OrphanSubmission{}}
+	rows, err := s.db.QueryContext(ctx, orphanTasksQuery)
+	if err != nil {
+		return report, err
+	}
+	for rows.Next() {
+		var o OrphanTask
+		if err := rows.Scan(&o.TaskID, &o.ContractID); err != nil {
+			rows.Close()
+			return report, err
+		}
+		report.OrphanTasks = append(report.OrphanTasks, o)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, orphanSubmissionsQuery)
+	if err != nil {
+		return report, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o OrphanSubmission
+		if err := rows.Scan(&o.SubmissionID, &o.ClaimID, &o.TaskID, &o.Status); err != nil {
+			return report, err
+		}
+		report.OrphanSubmissions = append(report.OrphanSubmissions, o)
+	}
+	return report, rows.Err()
+}
+
+// Stats counts contracts, tasks, proposals and submissions by status with
+// one GROUP BY query per table.
+func (s *SQLiteStore) Stats(ctx context.Context) (Stats, error) {
+	st := newStats()
+	for _, table := range statsTables {
+		rows, err := s.db.QueryContext(ctx, statsQuery(table))
+		if err != nil {
+			return st, err
+		}
+		counts := st.countsFor(table)
+		for rows.Next() {
+			var status string
+			var n int
+			if err := rows.Scan(&status, &n); err != nil {
+				rows.Close()
+				return st, err
+			}
+			counts[status] = n
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return st, err
+		}
+	}
+	return st, nil
+}
+
+// SubmissionContexts resolves tasks and their contracts with one join.
+func (s *SQLiteStore) SubmissionContexts(ctx context.Context, taskIDs []string) (map[string]SubmissionContext, error) {
+	out := make(map[string]SubmissionContext, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+	placeholders := strings.Repeat("?,", len(taskIDs))
+	placeholders = placeholders[:len(placeholders)-1]
+	args := make([]interface{}, len(taskIDs))
+	for i, id := range taskIDs {
+		args[i] = id
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+SELECT t.task_id, COALESCE(t.title, ''), COALESCE(t.skills, ''), COALESCE(t.contract_id, ''),
+       COALESCE(c.contract_id, ''), COALESCE(c.title, ''), COALESCE(c.status, '')
+FROM mcp_tasks t
+LEFT JOIN mcp_contracts c ON c.contract_id = t.contract_id
+WHERE t.task_id IN (%s)
+`, placeholders), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var sc SubmissionContext
+		var skills string
+		var contract SubmissionContract
+		if err := rows.Scan(&sc.Task.TaskID, &sc.Task.Title, &skills, &sc.Task.ContractID,
+			&contract.ContractID, &contract.Title, &contract.Status); err != nil {
+			return nil, err
+		}
+		if skills != "" {
+			sc.Task.Skills = strings.Split(skills, ",")
+		}
+		if contract.ContractID != "" {
+			sc.Contract = &contract
+		}
+		out[sc.Task.TaskID] = sc
+	}
+	return out, rows.Err()
+}
+
+// ExpireClaims releases active claims that expired before now and frees
+// tasks still held by them. expires_at is text in more than one layout, so
+// expiry is decided after parsing rather than in SQL.
+func (s *SQLiteStore) ExpireClaims(ctx context.Context, now time.Time) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT claim_id, task_id, expires_at FROM mcp_claims WHERE status='active'`)
+	if err != nil {
+		return 0, err
+	}
+	var expired []smart_contract.Claim
+	for rows.Next() {
+		var c smart_contract.Claim
+		var expiresAt sql.NullString
+		if err := rows.Scan(&c.ClaimID, &c.TaskID, &expiresAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if t, err := parseSQLiteTime(expiresAt.String); err == nil && t != nil && t.Before(now) {
+			expired = append(expired, c)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, c := range expired {
+		if _, err := tx.ExecContext(ctx, `UPDATE mcp_claims SET status='expired' WHERE claim_id=?`, c.ClaimID); err != nil {
+			return 0, err
+		}
+	}
+	// A task re-claimed after its old claim lapsed keeps the newer claim.
+	for _, c := range expired {
+		if _, err := tx.ExecContext(ctx, `
+UPDATE mcp_tasks SET status='available', claimed_by=NULL, claimed_at=NULL, claim_expires_at=NULL
+WHERE task_id=? AND status='claimed'
+  AND NOT EXISTS (SELECT 1 FROM mcp_claims c WHERE c.task_id = mcp_tasks.task_id AND c.status='active')`, c.TaskID); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
+
+// Export streams every record of kind to fn row by row. Timestamps are stored
+// as text in more than one layout, so the time filter is applied after parsing.
+func (s *SQLiteStore) Export(ctx context.Context, kind string, filter ExportFilter, fn func(record any) error) error {
+	var query string
+	switch kind {
+	case ExportContracts:
+		query = sqliteContractSelect + ` ORDER BY c.created_at, c.contract_id`
+	case ExportTasks:
+		query = `
+SELECT t.task_id, t.contract_id, t.goal_id, t.title, t.description, t.budget_sats, t.skills, t.status, t.claimed_by, t.claimed_at, t.claim_expires_at, t.difficulty, t.estimated_hours, t.requirements, t.merkle_proof, t.last_activity_at, c.created_at
+FROM mcp_tasks t
+LEFT JOIN mcp_contracts c ON c.contract_id = t.contract_id
+ORDER BY t.task_id`
+	case ExportSubmissions:
+		query = `
+SELECT s.submission_id, s.claim_id, c.task_id, s.status, s.deliverables, s.completion_proof, s.rejection_reason, s.rejection_type, s.rejected_at, s.created_at
+FROM mcp_submissions s
+JOIN mcp_claims c ON c.claim_id = s.claim_id
+ORDER BY s.created_at, s.submission_id`
+	default:
+		return fmt.Errorf("unknown export type %q", kind)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var record any
+		var at time.Time
+		switch kind {
+		case ExportContracts:
+			c, err := scanContractSQLite(rows)
+			if err != nil {
+				return err
+			}
+			record, at = c, c.CreatedAt
+		case ExportTasks:
+			var contractCreated sql.NullString
+			t, err := scanTaskSQLite(rows, &contractCreated)
+			if err != nil {
+				return err
+			}
+			if contractCreated.Valid {
+				if ts, err := parseSQLiteTime(contractCreated.String); err == nil && ts != nil {
+					at = *ts
+				}
+			}
+			record = t
+		case ExportSubmissions:
+			sub, err := scanSubmissionSQLite(rows)
+			if err != nil {
+				return err
+			}
+			record, at = sub, sub.CreatedAt
+		}
+		if !filter.Includes(at) {
+			continue
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (s *SQLiteStore) Close() {
 	if s.db != nil {
 		s.db.Close()
@@ -124,12 +379,7 @@ func (s *SQLiteStore) containsSkill(all []string, skills []string) bool {
 }
 
 func (s *SQLiteStore) ListContracts(filter smart_contract.ContractFilter) ([]smart_contract.Contract, error) {
-	baseSelect := `
-SELECT c.contract_id, COALESCE(c.title, ''), COALESCE(c.total_budget_sats, 0), COALESCE(c.goals_count, 0),
-	(SELECT COUNT(*) FROM mcp_tasks t WHERE t.contract_id = c.contract_id AND t.status = 'available') AS available_tasks_count,
-	COALESCE(c.status, 'pending'), c.skills, COALESCE(c.stego_image_url, ''), c.metadata, c.confirmed_block_height, c.confirmed_at, c.created_at
-FROM mcp_contracts c
-`
+	baseSelect := sqliteContractSelect
 
 	whereConditions := []string{}
 	args := []interface{}{}
@@ -139,17 +389,34 @@ FROM mcp_contracts c
 		args = append(args, filter.Status)
 	}
 
+	if filter.FundingStatus != "" {
+		cond, err := fundingStatusCondition(filter.FundingStatus)
+		if err != nil {
+			return nil, err
+		}
+		baseSelect += sqliteFundingJoin
+		whereConditions = append(whereConditions, cond)
+	}
+
 	if filter.CursorHeight != nil && *filter.CursorHeight > 0 {
 		whereConditions = append(whereConditions, "c.confirmed_block_height < ?")
 		args = append(args, *filter.CursorHeight)
 	}
 
+	if filter.InactiveSince != nil {
+		whereConditions = append(whereConditions, sqliteContractInactiveCondition)
+		args = append(args, sqliteActivityArg(filter.InactiveSince))
+	}
+
 	whereClause := ""
 	if len(whereConditions) > 0 {
 		whereClause = "WHERE " + strings.Join(whereConditions, " AND ")
 	}
 
 	orderBy := "ORDER BY c.confirmed_block_height DESC NULLS LAST, c.created_at DESC, c.contract_id DESC"
+	if filter.OrderByLastActivity {
+		orderBy = sqliteContractActivityOrder
+	}
 	if filter.Limit > 0 {
 		orderBy += fmt.Sprintf(" LIMIT %d", filter.Limit)
 		if filter.Offset > 0 {
@@ -169,29 +436,10 @@ FROM mcp_contracts c
 
 	var allContracts []smart_contract.Contract
 	for rows.Next() {
-		var c smart_contract.Contract
-		var metadata, skillsStr []byte
-		var confirmedAtStr, createdAtStr sql.NullString
-		if err := rows.Scan(&c.ContractID, &c.Title, &c.TotalBudgetSats, &c.GoalsCount, &c.AvailableTasksCount,
-			&c.Status, &skillsStr, &c.StegoImageURL, &metadata, &c.ConfirmedBlockHeight, &confirmedAtStr, &createdAtStr); err != nil {
+		c, err := scanContractSQLite(rows)
+		if err != nil {
 			return nil, err
 		}
-		if confirmedAtStr.Valid {
-			if t, err := parseSQLiteTime(confirmedAtStr.String); err == nil {
-				c.ConfirmedAt = t
-			}
-		}
-		if createdAtStr.Valid {
-			if t, err := parseSQLiteTime(createdAtStr.String); err == nil && t != nil {
-				c.CreatedAt = *t
-			}
-		}
-		if len(metadata) > 0 {
-			_ = json.Unmarshal(metadata, &c.Metadata)
-		}
-		if len(skillsStr) > 0 {
-			c.Skills = strings.Split(string(skillsStr), ",")
-		}
 		if len(filter.Skills) > 0 && !s.containsSkill(c.Skills, filter.Skills) {
 			continue
 		}
@@ -203,15 +451,94 @@ FROM mcp_contracts c
 	return allContracts, nil
 }
 
+// CountContracts counts contracts matching filter with SELECT COUNT(*),
+// using the same conditions as ListContracts.
+func (s *SQLiteStore) CountContracts(filter smart_contract.ContractFilter) (int, error) {
+	if len(filter.Skills) > 0 {
+		return countContractsByListing(s.ListContracts, filter)
+	}
+	query := `SELECT COUNT(*) FROM mcp_contracts c`
+	fundingCond := ""
+	if filter.FundingStatus != "" {
+		cond, err := fundingStatusCondition(filter.FundingStatus)
+		if err != nil {
+			return 0, err
+		}
+		query += sqliteFundingJoin
+		fundingCond = " AND " + cond
+	}
+	query += " WHERE 1=1" + fundingCond
+	args := []interface{}{}
+	if filter.Status != "" {
+		query += " AND c.status = ?"
+		args = append(args, filter.Status)
+	}
+	if filter.CursorHeight != nil && *filter.CursorHeight > 0 {
+		query += " AND c.confirmed_block_height < ?"
+		args = append(args, *filter.CursorHeight)
+	}
+	if filter.InactiveSince != nil {
+		query += " AND " + sqliteContractInactiveCondition
+		args = append(args, sqliteActivityArg(filter.InactiveSince))
+	}
+	var n int
+	err := s.db.QueryRowContext(context.Background(), query, args...).Scan(&n)
+	return n, err
+}
+
+// sqliteContractSelect lists the columns scanContractSQLite expects.
+const sqliteContractSelect = `
+SELECT c.contract_id, COALESCE(c.title, ''), COALESCE(c.total_budget_sats, 0), COALESCE(c.goals_count, 0),
+	(SELECT COUNT(*) FROM mcp_tasks t WHERE t.contract_id = c.contract_id AND t.status = 'available') AS available_tasks_count,
+	COALESCE(c.status, 'pending'), c.skills, COALESCE(c.stego_image_url, ''), c.metadata, c.confirmed_block_height, c.confirmed_at, c.created_at, c.last_activity_at
+FROM mcp_contracts c
+`
+
+func scanContractSQLite(rows *sql.Rows) (smart_contract.Contract, error) {
+	var c smart_contract.Contract
+	var metadata, skillsStr []byte
+	var confirmedAtStr, createdAtStr, lastActivityStr sql.NullString
+	if err := rows.Scan(&c.ContractID, &c.Title, &c.TotalBudgetSats, &c.GoalsCount, &c.AvailableTasksCount,
+		&c.Status, &skillsStr, &c.StegoImageURL, &metadata, &c.ConfirmedBlockHeight, &confirmedAtStr, &createdAtStr, &lastActivityStr); err != nil {
+		return c, err
+	}
+	if lastActivityStr.Valid {
+		if t, err := parseSQLiteTime(lastActivityStr.String); err == nil {
+			c.LastActivityAt = t
+		}
+	}
+	if confirmedAtStr.Valid {
+		if t, err := parseSQLiteTime(confirmedAtStr.String); err == nil {
+			c.ConfirmedAt = t
+		}
+	}
+	if createdAtStr.Valid {
+		if t, err := parseSQLiteTime(createdAtStr.String); err == nil && t != nil {
+			c.CreatedAt = *t
+		}
+	}
+	if len(metadata) > 0 {
+		_ = json.Unmarshal(metadata, &c.Metadata)
+	}
+	if len(skillsStr) > 0 {
+		c.Skills = strings.Split(string(skillsStr), ",")
+	}
+	return c, nil
+}
+
 func (s *SQLiteStore) ListTasks(filter smart_contract.TaskFilter) ([]smart_contract.Task, error) {
 	query := `
-SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof
+SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof, last_activity_at
 FROM mcp_tasks
 WHERE (? = '' OR status = ?)
 AND (? = '' OR contract_id = ?)
 AND (? = '' OR claimed_by = ?)
-`
-	args := []interface{}{filter.Status, filter.Status, filter.ContractID, filter.ContractID, filter.ClaimedBy, filter.ClaimedBy}
+AND ` + sqliteTaskInactiveCondition
+	if filter.OrderByLastActivity {
+		query += "\n" + sqliteTaskActivityOrder
+	}
+	cutoff := sqliteActivityArg(filter.InactiveSince)
+	args := []interface{}{filter.Status, filter.Status, filter.ContractID, filter.ContractID, filter.ClaimedBy, filter.ClaimedBy, cutoff, cutoff}
 
 	rows, err := s.db.QueryContext(context.Background(), query, args...)
 	if err != nil {
@@ -242,13 +569,35 @@ AND (? = '' OR claimed_by = ?)
 	return out, rows.Err()
 }
 
-func scanTaskSQLite(rows *sql.Rows) (smart_contract.Task, error) {
+// CountTasks counts tasks matching filter with SELECT COUNT(*), using the
+// same conditions as ListTasks.
+func (s *SQLiteStore) CountTasks(filter smart_contract.TaskFilter) (int, error) {
+	if len(filter.Skills) > 0 {
+		return countTasksByListing(s.ListTasks, filter)
+	}
+	cutoff := sqliteActivityArg(filter.InactiveSince)
+	var n int
+	err := s.db.QueryRowContext(context.Background(), `
+SELECT COUNT(*)
+FROM mcp_tasks
+WHERE (? = '' OR status = ?)
+AND (? = '' OR contract_id = ?)
+AND (? = '' OR claimed_by = ?)
+AND (? <= 0 OR budget_sats >= ?)
+AND `+sqliteTaskInactiveCondition, filter.Status, filter.Status, filter.ContractID, filter.ContractID, filter.ClaimedBy, filter.ClaimedBy,
+		filter.MinBudgetSats, filter.MinBudgetSats, cutoff, cutoff).Scan(&n)
+	return n, err
+}
+
+// scanTaskSQLite scans the task columns, followed by any extra selected columns.
+func scanTaskSQLite(rows *sql.Rows, extra ...any) (smart_contract.Task, error) {
 	var t smart_contract.Task
 	var skillsStr, requirementsStr, merkleProofStr []byte
-	var claimedBy, claimedAtStr, claimExpiresAtStr sql.NullString
-	err := rows.Scan(&t.TaskID, &t.ContractID, &t.GoalID, &t.Title, &t.Description, &t.BudgetSats,
+	var claimedBy, claimedAtStr, claimExpiresAtStr, lastActivityStr sql.NullString
+	dest := []any{&t.TaskID, &t.ContractID, &t.GoalID, &t.Title, &t.Description, &t.BudgetSats,
 		&skillsStr, &t.Status, &claimedBy, &claimedAtStr, &claimExpiresAtStr, &t.Difficulty,
-		&t.EstimatedHours, &requirementsStr, &merkleProofStr)
+		&t.EstimatedHours, &requirementsStr, &merkleProofStr, &lastActivityStr}
+	err := rows.Scan(append(dest, extra...)...)
 	if err != nil {
 		return t, err
 	}
@@ -265,6 +614,11 @@ func scanTaskSQLite(rows *sql.Rows) (smart_contract.Task, error) {
 			t.ClaimExpires = tm
 		}
 	}
+	if lastActivityStr.Valid {
+		if tm, err := parseSQLiteTime(lastActivityStr.String); err == nil {
+			t.LastActivityAt = tm
+		}
+	}
 	if len(skillsStr) > 0 {
 		t.Skills = strings.Split(string(skillsStr), ",")
 	}
@@ -279,15 +633,15 @@ func scanTaskSQLite(rows *sql.Rows) (smart_contract.Task, error) {
 
 func (s *SQLiteStore) GetTask(id string) (smart_contract.Task, error) {
 	row := s.db.QueryRowContext(context.Background(), `
-SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof
+SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof, last_activity_at
 FROM mcp_tasks WHERE task_id=?
 `, id)
 	var t smart_contract.Task
 	var skillsStr, requirementsStr, merkleProofStr []byte
-	var claimedBy, claimedAtStr, claimExpiresAtStr sql.NullString
+	var claimedBy, claimedAtStr, claimExpiresAtStr, lastActivityStr sql.NullString
 	err := row.Scan(&t.TaskID, &t.ContractID, &t.GoalID, &t.Title, &t.Description, &t.BudgetSats,
 		&skillsStr, &t.Status, &claimedBy, &claimedAtStr, &claimExpiresAtStr, &t.Difficulty,
-		&t.EstimatedHours, &requirementsStr, &merkleProofStr)
+		&t.EstimatedHours, &requirementsStr, &merkleProofStr, &lastActivityStr)
 	if err != nil {
 		return t, ErrTaskNotFound
 	}
@@ -304,6 +658,11 @@ FROM mcp_tasks WHERE task_id=?
 			t.ClaimExpires = tm
 		}
 	}
+	if lastActivityStr.Valid {
+		if tm, err := parseSQLiteTime(lastActivityStr.String); err == nil {
+			t.LastActivityAt = tm
+		}
+	}
 	if len(skillsStr) > 0 {
 		t.Skills = strings.Split(string(skillsStr), ",")
 	}
@@ -319,14 +678,14 @@ FROM mcp_tasks WHERE task_id=?
 func (s *SQLiteStore) GetContract(id string) (smart_contract.Contract, error) {
 	var c smart_contract.Contract
 	var metadata, skillsStr []byte
-	var confirmedAtStr sql.NullString
+	var confirmedAtStr, lastActivityStr sql.NullString
 	err := s.db.QueryRowContext(context.Background(), `
 SELECT contract_id, COALESCE(title, ''), COALESCE(total_budget_sats, 0), COALESCE(goals_count, 0),
        (SELECT COUNT(*) FROM mcp_tasks t WHERE t.contract_id = mcp_contracts.contract_id AND t.status = 'available') AS available_tasks_count,
-       COALESCE(status, 'pending'), skills, COALESCE(stego_image_url, ''), confirmed_block_height, confirmed_at, metadata
+       COALESCE(status, 'pending'), skills, COALESCE(stego_image_url, ''), confirmed_block_height, confirmed_at, metadata, last_activity_at
 FROM mcp_contracts WHERE contract_id=?
 `, id).Scan(&c.ContractID, &c.Title, &c.TotalBudgetSats, &c.GoalsCount, &c.AvailableTasksCount,
-		&c.Status, &skillsStr, &c.StegoImageURL, &c.ConfirmedBlockHeight, &confirmedAtStr, &metadata)
+		&c.Status, &skillsStr, &c.StegoImageURL, &c.ConfirmedBlockHeight, &confirmedAtStr, &metadata, &lastActivityStr)
 	if err != nil {
 		return c, fmt.Errorf("contract %s not found", id)
 	}
@@ -335,6 +694,11 @@ FROM mcp_contracts WHERE contract_id=?
 			c.ConfirmedAt = t
 		}
 	}
+	if lastActivityStr.Valid {
+		if t, err := parseSQLiteTime(lastActivityStr.String); err == nil {
+			c.LastActivityAt = t
+		}
+	}
 	if len(metadata) > 0 {
 		_ = json.Unmarshal(metadata, &c.Metadata)
 		// Hydrate rework requests from metadata (mirrors PG GetContract)
@@ -415,6 +779,25 @@ func (s *SQLiteStore) ClaimTask(taskID, walletAddress string, estimatedCompletio
 	if err != nil {
 		return smart_contract.Claim{}, ErrTaskNotFound
 	}
+	// Refuse claims that could never be paid.
+	if taskStatusClosed(taskStatus) {
+		return smart_contract.Claim{}, ErrTaskClosed
+	}
+	var contractStatus sql.NullString
+	_ = tx.QueryRow(`SELECT c.status FROM mcp_tasks t JOIN mcp_contracts c ON c.contract_id = t.contract_id WHERE t.task_id=?`, taskID).Scan(&contractStatus)
+	if contractStatusClosed(contractStatus.String) {
+		return smart_contract.Claim{}, ErrTaskClosed
+	}
+	var approvedSubmissions int
+	if err := tx.QueryRow(`
+SELECT COUNT(*) FROM mcp_submissions s
+JOIN mcp_claims c ON c.claim_id = s.claim_id
+WHERE c.task_id=? AND LOWER(s.status) IN ('approved', 'accepted')`, taskID).Scan(&approvedSubmissions); err != nil {
+		return smart_contract.Claim{}, err
+	}
+	if approvedSubmissions > 0 {
+		return smart_contract.Claim{}, ErrTaskClosed
+	}
 	// Tasks that are "available" or "claimed" (by the same agent for re-claim) can be claimed.
 	// Other terminal states are blocked.
 	if taskStatus != "available" && taskStatus != "claimed" {
@@ -460,7 +843,16 @@ func (s *SQLiteStore) ClaimTask(taskID, walletAddress string, estimatedCompletio
 		return smart_contract.Claim{}, ErrTaskTaken
 	}
 
-	expires := now.Add(s.claimTTL)
+	// A contract may override the global claim window via its metadata.
+	ttl := s.claimTTL
+	var contractMeta []byte
+	if err := tx.QueryRow(`SELECT c.metadata FROM mcp_tasks t JOIN mcp_contracts c ON c.contract_id = t.contract_id WHERE t.task_id=?`, taskID).Scan(&contractMeta); err == nil && len(contractMeta) > 0 {
+		var meta map[string]interface{}
+		if json.Unmarshal(contractMeta, &meta) == nil {
+			ttl = ContractClaimTTL(meta, s.claimTTL)
+		}
+	}
+	expires := now.Add(ttl)
 
 	claimID := fmt.Sprintf("CLAIM-%d", time.Now().UnixNano())
 	claim := smart_contract.Claim{
@@ -486,6 +878,9 @@ UPDATE mcp_tasks SET status='claimed', claimed_by=?, claimed_at=?, claim_expires
 	if err != nil {
 		return smart_contract.Claim{}, err
 	}
+	if err := touchActivitySQLite(context.Background(), tx, taskID, claim.CreatedAt); err != nil {
+		return smart_contract.Claim{}, err
+	}
 
 	if err := tx.Commit(); err != nil {
 		return smart_contract.Claim{}, err
@@ -547,6 +942,7 @@ VALUES (?,?,?,?,?,?,?)
 
 	_, _ = s.db.Exec(`UPDATE mcp_claims SET status='submitted' WHERE claim_id=?`, claimID)
 	_, _ = s.db.Exec(`UPDATE mcp_tasks SET status='submitted' WHERE task_id=?`, claim.TaskID)
+	_ = touchActivitySQLite(context.Background(), s.db, claim.TaskID, sub.CreatedAt)
 
 	return sub, nil
 }
@@ -577,33 +973,41 @@ ORDER BY s.created_at DESC
 	defer rows.Close()
 	var out []smart_contract.Submission
 	for rows.Next() {
-		var sub smart_contract.Submission
-		var delivJSON, proofJSON, rejectionReason, rejectionType []byte
-		var rejectedAtStr, createdAtStr sql.NullString
-		if err := rows.Scan(&sub.SubmissionID, &sub.ClaimID, &sub.TaskID, &sub.Status, &delivJSON, &proofJSON, &rejectionReason, &rejectionType, &rejectedAtStr, &createdAtStr); err != nil {
+		sub, err := scanSubmissionSQLite(rows)
+		if err != nil {
 			return nil, err
 		}
-		if rejectedAtStr.Valid {
-			if t, err := parseSQLiteTime(rejectedAtStr.String); err == nil {
-				sub.RejectedAt = t
-			}
-		}
-		if createdAtStr.Valid {
-			if t, err := parseSQLiteTime(createdAtStr.String); err == nil && t != nil {
-				sub.CreatedAt = *t
-			}
-		}
-		if len(delivJSON) > 0 {
-			_ = json.Unmarshal(delivJSON, &sub.Deliverables)
-		}
-		if len(proofJSON) > 0 {
-			_ = json.Unmarshal(proofJSON, &sub.CompletionProof)
-		}
 		out = append(out, sub)
 	}
 	return out, rows.Err()
 }
 
+func scanSubmissionSQLite(rows *sql.Rows) (smart_contract.Submission, error) {
+	var sub smart_contract.Submission
+	var delivJSON, proofJSON, rejectionReason, rejectionType []byte
+	var rejectedAtStr, createdAtStr sql.NullString
+	if err := rows.Scan(&sub.SubmissionID, &sub.ClaimID, &sub.TaskID, &sub.Status, &delivJSON, &proofJSON, &rejectionReason, &rejectionType, &rejectedAtStr, &createdAtStr); err != nil {
+		return sub, err
+	}
+	if rejectedAtStr.Valid {
+		if t, err := parseSQLiteTime(rejectedAtStr.String); err == nil {
+			sub.RejectedAt = t
+		}
+	}
+	if createdAtStr.Valid {
+		if t, err := parseSQLiteTime(createdAtStr.String); err == nil && t != nil {
+			sub.CreatedAt = *t
+		}
+	}
+	if len(delivJSON) > 0 {
+		_ = json.Unmarshal(delivJSON, &sub.Deliverables)
+	}
+	if len(proofJSON) > 0 {
+		_ = json.Unmarshal(proofJSON, &sub.CompletionProof)
+	}
+	return sub, nil
+}
+
 func (s *SQLiteStore) GetSubmission(ctx context.Context, id string) (smart_contract.Submission, error) {
 	rows, err := s.db.QueryContext(ctx, `
 SELECT s.submission_id, s.claim_id, c.task_id, s.status, s.deliverables, s.completion_proof, s.rejection_reason, s.rejection_type, s.rejected_at, s.created_at
@@ -752,12 +1156,29 @@ func (s *SQLiteStore) ContractFunding(contractID string) (smart_contract.Contrac
 }
 
 func (s *SQLiteStore) UpsertContractWithTasks(ctx context.Context, contract smart_contract.Contract, tasks []smart_contract.Task) error {
+	if err := ValidateClaimTTL(contract.Metadata); err != nil {
+		return err
+	}
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	// Every task must belong to this contract or one that already exists.
+	for _, t := range tasks {
+		if t.ContractID == "" || t.ContractID == contract.ContractID {
+			continue
+		}
+		var exists int
+		if err := tx.QueryRowContext(ctx, `SELECT 1 FROM mcp_contracts WHERE contract_id=?`, t.ContractID).Scan(&exists); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w: task %s references %s", ErrUnknownContract, t.TaskID, t.ContractID)
+			}
+			return err
+		}
+	}
+
 	metadata, _ := json.Marshal(contract.Metadata)
 	skills := strings.Join(contract.Skills, ",")
 	createdAt := time.Now().Format(time.RFC3339)
@@ -775,13 +1196,17 @@ ON CONFLICT(contract_id) DO UPDATE SET
   available_tasks_count = excluded.available_tasks_count,
   status = excluded.status,
   skills = excluded.skills,
-  stego_image_url = excluded.stego_image_url
-`, contract.ContractID, contract.Title, contract.TotalBudgetSats, contract.GoalsCount, contract.AvailableTasksCount, contract.Status, skills, contract.StegoImageURL, createdAt, string(metadata))
+  stego_image_url = excluded.stego_image_url,
+  metadata = json_patch(COALESCE(mcp_contracts.metadata, '{}'), ?)
+`, contract.ContractID, contract.Title, contract.TotalBudgetSats, contract.GoalsCount, contract.AvailableTasksCount, contract.Status, skills, contract.StegoImageURL, createdAt, string(metadata), claimTTLPatch(contract.Metadata))
 	if err != nil {
 		return err
 	}
 
 	for _, t := range tasks {
+		if t.ContractID == "" {
+			t.ContractID = contract.ContractID
+		}
 		reqJSON, _ := json.Marshal(t.Requirements)
 		var proofStr *string
 		if t.MerkleProof != nil {
@@ -828,8 +1253,18 @@ func (s *SQLiteStore) UpdateTaskProof(ctx context.Context, taskID string, proof
 	if err != nil {
 		return err
 	}
-	_, err = s.db.ExecContext(ctx, `UPDATE mcp_tasks SET merkle_proof=? WHERE task_id=?`, string(b), taskID)
-	return err
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `UPDATE mcp_tasks SET merkle_proof=? WHERE task_id=?`, string(b), taskID); err != nil {
+		return err
+	}
+	if err := touchActivitySQLite(ctx, tx, taskID, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *SQLiteStore) UpdateContractStatus(ctx context.Context, contractID, status string) error {
@@ -863,7 +1298,7 @@ func (s *SQLiteStore) ConfirmContract(ctx context.Context, contractID string, bl
 
 	res, err := s.db.ExecContext(ctx, `
 UPDATE mcp_contracts 
-SET status='confirmed', confirmed_block_height=?, confirmed_at=datetime('now'), 
+SET status='confirmed', confirmed_block_height=?, confirmed_at=datetime('now'), last_activity_at=datetime('now'),
     stego_image_url=COALESCE(?, stego_image_url),
     metadata=?
 WHERE contract_id=?
@@ -984,8 +1419,8 @@ func (s *SQLiteStore) UpsertTask(ctx context.Context, t smart_contract.Task) err
 	}
 	taskSkills := strings.Join(t.Skills, ",")
 	_, err := s.db.ExecContext(ctx, `
-INSERT INTO mcp_tasks (task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof)
-VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+INSERT INTO mcp_tasks (task_id, contract_id, goal_id, title, description, budget_sats, skills, status, claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof, last_activity_at)
+VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
 ON CONFLICT(task_id) DO UPDATE SET
   contract_id = excluded.contract_id,
   goal_id = excluded.goal_id,
@@ -1000,8 +1435,9 @@ ON CONFLICT(task_id) DO UPDATE SET
   difficulty = excluded.difficulty,
   estimated_hours = excluded.estimated_hours,
   requirements = excluded.requirements,
-  merkle_proof = COALESCE(excluded.merkle_proof, mcp_tasks.merkle_proof)
-`, t.TaskID, t.ContractID, t.GoalID, t.Title, t.Description, t.BudgetSats, taskSkills, t.Status, t.ClaimedBy, t.ClaimedAt, t.ClaimExpires, t.Difficulty, t.EstimatedHours, string(reqJSON), string(proofJSON))
+  merkle_proof = COALESCE(excluded.merkle_proof, mcp_tasks.merkle_proof),
+  last_activity_at = COALESCE(excluded.last_activity_at, mcp_tasks.last_activity_at)
+`, t.TaskID, t.ContractID, t.GoalID, t.Title, t.Description, t.BudgetSats, taskSkills, t.Status, t.ClaimedBy, t.ClaimedAt, t.ClaimExpires, t.Difficulty, t.EstimatedHours, string(reqJSON), string(proofJSON), sqliteActivityArg(t.LastActivityAt))
 	return err
 }
 
@@ -1019,31 +1455,12 @@ ON CONFLICT(task_id) DO UPDATE SET
 }
 
 func (s *SQLiteStore) CreateProposal(ctx context.Context, p smart_contract.Proposal) error {
-	if p.Metadata == nil {
-		p.Metadata = map[string]interface{}{}
-	}
-	if strings.TrimSpace(p.VisiblePixelHash) != "" {
-		if vph, ok := p.Metadata["visible_pixel_hash"].(string); !ok || strings.TrimSpace(vph) == "" {
-			p.Metadata["visible_pixel_hash"] = p.VisiblePixelHash
-		}
-	}
-
-	// Validate contract_id vs visible_pixel_hash consistency (mirrors PG)
-	if metaContract, ok := p.Metadata["contract_id"].(string); ok {
-		metaContract = strings.TrimSpace(metaContract)
-		if metaContract != "" {
-			if metaHash, ok2 := p.Metadata["visible_pixel_hash"].(string); ok2 {
-				metaHash = strings.TrimSpace(metaHash)
-				normalizedContract := strings.TrimPrefix(metaContract, "wish-")
-				if metaHash != "" && metaHash != normalizedContract {
-					return fmt.Errorf("visible_pixel_hash must match contract_id when both are set (normalized: %s)", normalizedContract)
-				}
-			}
-		}
+	if err := ApplyProposalIdentity(&p); err != nil {
+		return err
 	}
 
 	if err := ValidateProposalInput(&p); err != nil {
-		return fmt.Errorf("proposal validation failed: %v", err)
+		return fmt.Errorf("proposal validation failed: %w", err)
 	}
 
 	// Validate status field (mirrors PG)
@@ -1212,6 +1629,31 @@ func (s *SQLiteStore) ListProposals(ctx context.Context, filter smart_contract.P
 	return out, rows.Err()
 }
 
+// CountProposals counts proposals matching filter with SELECT COUNT(*),
+// using the same conditions as ListProposals.
+func (s *SQLiteStore) CountProposals(ctx context.Context, filter smart_contract.ProposalFilter) (int, error) {
+	if len(filter.Skills) > 0 || filter.ContractID != "" {
+		return countProposalsByListing(ctx, s.ListProposals, filter)
+	}
+	query := `SELECT COUNT(*) FROM mcp_proposals WHERE 1=1`
+	args := []interface{}{}
+	if filter.ProposalID != "" {
+		query += " AND id = ?"
+		args = append(args, filter.ProposalID)
+	}
+	if filter.Status != "" {
+		query += " AND status = ?"
+		args = append(args, filter.Status)
+	}
+	if filter.MinBudget > 0 {
+		query += " AND budget_sats >= ?"
+		args = append(args, filter.MinBudget)
+	}
+	var n int
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&n)
+	return n, err
+}
+
 func (s *SQLiteStore) GetProposal(ctx context.Context, id string) (smart_contract.Proposal, error) {
 	var p smart_contract.Proposal
 	var metadata []byte
@@ -1257,6 +1699,15 @@ func (s *SQLiteStore) UpdateProposal(ctx context.Context, p smart_contract.Propo
 	if p.Status == "" {
 		p.Status = existing.Status
 	}
+	if p.VisiblePixelHash == "" {
+		p.VisiblePixelHash = existing.VisiblePixelHash
+	}
+	if err := ApplyProposalIdentity(&p); err != nil {
+		return err
+	}
+	if err := ValidateProposalTaskCount(len(p.Tasks)); err != nil {
+		return err
+	}
 
 	metadata, _ := json.Marshal(p.Metadata)
 	_, err = s.db.ExecContext(ctx, `
@@ -1274,6 +1725,10 @@ func (s *SQLiteStore) UpdateProposalMetadata(ctx context.Context, id string, upd
 	if meta == nil {
 		meta = map[string]interface{}{}
 	}
+	updates, err = checkMetadataIdentity(existing.VisiblePixelHash, meta, updates)
+	if err != nil {
+		return err
+	}
 	for k, v := range updates {
 		meta[k] = v
 	}
@@ -1328,7 +1783,7 @@ func (s *SQLiteStore) ApproveProposal(ctx context.Context, id string) error {
 	}
 	populateProposalTasks(&proposal)
 	if err := ValidateProposalForApproval(&proposal); err != nil {
-		return fmt.Errorf("proposal validation failed: %v", err)
+		return fmt.Errorf("proposal validation failed: %w", err)
 	}
 
 	contractID := contractIDFromMeta(meta, id)
@@ -1395,9 +1850,10 @@ func (s *SQLiteStore) PublishProposal(ctx context.Context, id string) error {
 	}
 	defer tx.Rollback()
 
-	var status string
+	var status, title string
+	var budget int64
 	var metaJSON []byte
-	if err := tx.QueryRowContext(ctx, `SELECT status, metadata FROM mcp_proposals WHERE id=?`, id).Scan(&status, &metaJSON); err != nil {
+	if err := tx.QueryRowContext(ctx, `SELECT status, COALESCE(title, ''), COALESCE(budget_sats, 0), metadata FROM mcp_proposals WHERE id=?`, id).Scan(&status, &title, &budget, &metaJSON); err != nil {
 		return err
 	}
 	if !strings.EqualFold(status, "approved") && !strings.EqualFold(status, "published") {
@@ -1410,6 +1866,20 @@ func (s *SQLiteStore) PublishProposal(ctx context.Context, id string) error {
 	}
 	contractID := contractIDFromMeta(meta, id)
 
+	var taskCount int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM mcp_tasks WHERE contract_id=?`, contractID).Scan(&taskCount); err != nil {
+		return err
+	}
+	if err := ValidateContractTaskCount(contractID, taskCount); err != nil {
+		return err
+	}
+	if taskCount > 0 {
+		// Published tasks must not dangle: create their contract from the proposal.
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO mcp_contracts (contract_id, title, total_budget_sats, status, created_at) VALUES (?, ?, ?, 'pending', datetime('now'))`, contractID, title, budget); err != nil {
+			return err
+		}
+	}
+
 	if _, err := tx.ExecContext(ctx, `UPDATE mcp_tasks SET status='published' WHERE contract_id=? AND status IN ('submitted','pending_review','claimed','approved')`, contractID); err != nil {
 		return err
 	}
@@ -1451,6 +1921,12 @@ UPDATE mcp_submissions SET status=?, rejection_reason=?, rejection_type=?, rejec
 `, status, rejectionReason, rejType, rejectedAt, submissionID); err != nil {
 		return err
 	}
+	var reviewedTaskID string
+	if err := tx.QueryRowContext(ctx, `SELECT task_id FROM mcp_claims WHERE claim_id=?`, claimID).Scan(&reviewedTaskID); err == nil {
+		if err := touchActivitySQLite(ctx, tx, reviewedTaskID, time.Now()); err != nil {
+			return err
+		}
+	}
 
 	// On approval, update task and claim
 	if status == "accepted" || status == "approved" {
@@ -1711,7 +2187,7 @@ func (s *SQLiteStore) hydrateProposalTasks(ctx context.Context, p *smart_contrac
 
 	rows, err := s.db.QueryContext(ctx, `
 SELECT task_id, contract_id, goal_id, title, description, budget_sats, skills, status,
-       claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof
+       claimed_by, claimed_at, claim_expires_at, difficulty, estimated_hours, requirements, merkle_proof, last_activity_at
 FROM mcp_tasks WHERE contract_id IN (`+strings.Join(placeholders, ",")+`)
 `, args...)
 	if err != nil {
@@ -0,0 +1,62 @@
+package smart_contract
+
+import "context"
+
+// StatusCounts maps a record status to how many records have it. Records
+// stored without a status are counted under StatusUnknown.
+type StatusCounts map[string]int
+
+// StatusUnknown is the StatusCounts key for records with an empty status.
+const StatusUnknown = "unknown"
+
+// Stats holds record counts grouped by status for dashboards.
+type Stats struct {
+	Contracts   StatusCounts `json:"contracts"`
+	Tasks       StatusCounts `json:"tasks"`
+	Proposals   StatusCounts `json:"proposals"`
+	Submissions StatusCounts `json:"submissions"`
+}
+
+// StatsCounter is implemented by stores that can count records by status
+// without loading them.
+type StatsCounter interface {
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// statsTables lists the tables counted by the SQL stores, in Stats field order.
+var statsTables = []string{TableContracts, TableTasks, TableProposals, TableSubmissions}
+
+// statsQuery groups table by status. Both SQL backends accept it as is.
+func statsQuery(table string) string {
+	return `SELECT COALESCE(NULLIF(status, ''), '` + StatusUnknown + `'), COUNT(*) FROM ` + table + ` GROUP BY 1`
+}
+
+func newStats() Stats {
+	return Stats{
+		Contracts:   StatusCounts{},
+		Tasks:       StatusCounts{},
+		Proposals:   StatusCounts{},
+		Submissions: StatusCounts{},
+	}
+}
+
+// countsFor returns the Stats field that table is counted into.
+func (st Stats) countsFor(table string) StatusCounts {
+	switch table {
+	case TableContracts:
+		return st.Contracts
+	case TableTasks:
+		return st.Tasks
+	case TableProposals:
+		return st.Proposals
+	default:
+		return st.Submissions
+	}
+}
+
+func (c StatusCounts) add(status string) {
+	if status == "" {
+		status = StatusUnknown
+	}
+	c[status]++
+}
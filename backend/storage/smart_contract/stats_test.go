@@ -0,0 +1,87 @@
+package smart_contract
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+func TestStatsCountsByStatus(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			counter := store.(StatsCounter)
+			before, err := counter.Stats(ctx)
+			if err != nil {
+				t.Fatalf("stats: %v", err)
+			}
+			if before.Contracts["active"] == 0 || before.Tasks["available"] == 0 {
+				t.Fatalf("seeded stats missing fixtures: %+v", before)
+			}
+
+			contract := smart_contract.Contract{ContractID: "stats-contract", Title: "Stats", Status: "active"}
+			tasks := []smart_contract.Task{
+				{TaskID: "stats-task-1", Title: "One", Status: "available"},
+				{TaskID: "stats-task-2", Title: "Two", Status: "available"},
+			}
+			if err := store.UpsertContractWithTasks(ctx, contract, tasks); err != nil {
+				t.Fatalf("upsert: %v", err)
+			}
+			claim, err := store.ClaimTask("stats-task-1", "wallet-1", nil)
+			if err != nil {
+				t.Fatalf("claim: %v", err)
+			}
+			sub, err := store.SubmitWork(claim.ClaimID, map[string]interface{}{"notes": "done"}, nil)
+			if err != nil {
+				t.Fatalf("submit: %v", err)
+			}
+			for i, status := range []string{"pending", "pending", "approved"} {
+				if err := store.CreateProposal(ctx, smart_contract.Proposal{
+					ID:               "stats-proposal-" + string(rune('a'+i)),
+					Title:            "Stats",
+					VisiblePixelHash: strings.Repeat(string(rune('a'+i)), 64),
+					Status:           status,
+				}); err != nil {
+					t.Fatalf("create proposal: %v", err)
+				}
+			}
+			task, err := store.GetTask("stats-task-1")
+			if err != nil {
+				t.Fatalf("get task: %v", err)
+			}
+
+			after, err := counter.Stats(ctx)
+			if err != nil {
+				t.Fatalf("stats: %v", err)
+			}
+			deltas := []struct {
+				kind          string
+				before, after StatusCounts
+				status        string
+				want          int
+			}{
+				{"contracts", before.Contracts, after.Contracts, "active", 1},
+				{"tasks", before.Tasks, after.Tasks, "available", 1},
+				{"tasks", before.Tasks, after.Tasks, task.Status, 1},
+				{"proposals", before.Proposals, after.Proposals, "pending", 2},
+				{"proposals", before.Proposals, after.Proposals, "approved", 1},
+				{"submissions", before.Submissions, after.Submissions, sub.Status, 1},
+			}
+			for _, d := range deltas {
+				if got := d.after[d.status] - d.before[d.status]; got != d.want {
+					t.Fatalf("%s[%s] grew by %d, want %d (before %v, after %v)", d.kind, d.status, got, d.want, d.before, d.after)
+				}
+			}
+		})
+	}
+}
@@ -15,6 +15,10 @@ import (
 type Store interface {
 	ListContracts(filter smart_contract.ContractFilter) ([]smart_contract.Contract, error)
 	ListTasks(filter smart_contract.TaskFilter) ([]smart_contract.Task, error)
+	// CountContracts and CountTasks return how many records ListContracts and
+	// ListTasks would return for filter, ignoring Limit and Offset.
+	CountContracts(filter smart_contract.ContractFilter) (int, error)
+	CountTasks(filter smart_contract.TaskFilter) (int, error)
 	GetTask(id string) (smart_contract.Task, error)
 	GetContract(id string) (smart_contract.Contract, error)
 	GetClaim(id string) (smart_contract.Claim, error)
@@ -36,6 +40,8 @@ type Store interface {
 	// Proposal operations
 	CreateProposal(ctx context.Context, p smart_contract.Proposal) error
 	ListProposals(ctx context.Context, filter smart_contract.ProposalFilter) ([]smart_contract.Proposal, error)
+	// CountProposals is ListProposals' length, ignoring MaxResults and Offset.
+	CountProposals(ctx context.Context, filter smart_contract.ProposalFilter) (int, error)
 	GetProposal(ctx context.Context, id string) (smart_contract.Proposal, error)
 	UpdateProposal(ctx context.Context, p smart_contract.Proposal) error
 	UpdateProposalMetadata(ctx context.Context, id string, updates map[string]interface{}) error
@@ -54,3 +60,10 @@ type Store interface {
 	// All implementations (Memory, SQLite, PG) provide it.
 	UpsertContractWithTasks(ctx context.Context, contract smart_contract.Contract, tasks []smart_contract.Task) error
 }
+
+// Reseeder is implemented by stores that can wipe their contents and reload
+// the deterministic fixtures from SeedData. It backs the reset-and-seed admin
+// action and is intended for test environments only.
+type Reseeder interface {
+	ResetAndSeed(ctx context.Context) (SeedManifest, error)
+}
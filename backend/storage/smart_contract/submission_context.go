@@ -0,0 +1,95 @@
+package smart_contract
+
+import (
+	"context"
+	"errors"
+)
+
+// SubmissionTask is the task summary attached to an expanded submission.
+type SubmissionTask struct {
+	TaskID     string   `json:"task_id"`
+	Title      string   `json:"title"`
+	Skills     []string `json:"skills_required"`
+	ContractID string   `json:"contract_id"`
+}
+
+// SubmissionContract is the contract summary attached to an expanded
+// submission.
+type SubmissionContract struct {
+	ContractID string `json:"contract_id"`
+	Title      string `json:"title"`
+	Status     string `json:"status"`
+}
+
+// SubmissionContext is the task a submission was made against and the
+// contract that task belongs to. Contract is nil when the task's contract
+// is missing.
+type SubmissionContext struct {
+	Task     SubmissionTask
+	Contract *SubmissionContract
+}
+
+// SubmissionContextResolver is implemented by stores that can resolve the
+// task and contract behind many submissions in one lookup.
+type SubmissionContextResolver interface {
+	// SubmissionContexts returns contexts keyed by task ID. Unknown task IDs
+	// are left out.
+	SubmissionContexts(ctx context.Context, taskIDs []string) (map[string]SubmissionContext, error)
+}
+
+// ResolveSubmissionContexts returns the context for each task ID, using the
+// store's SubmissionContextResolver when it has one. Otherwise it falls back
+// to one GetTask per distinct task and one GetContract per distinct contract.
+func ResolveSubmissionContexts(ctx context.Context, store Store, taskIDs []string) (map[string]SubmissionContext, error) {
+	taskIDs = distinctNonEmpty(taskIDs)
+	if len(taskIDs) == 0 {
+		return map[string]SubmissionContext{}, nil
+	}
+	if resolver, ok := store.(SubmissionContextResolver); ok {
+		return resolver.SubmissionContexts(ctx, taskIDs)
+	}
+
+	out := make(map[string]SubmissionContext, len(taskIDs))
+	contracts := make(map[string]*SubmissionContract)
+	for _, id := range taskIDs {
+		task, err := store.GetTask(id)
+		if err != nil {
+			if errors.Is(err, ErrTaskNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		sc := SubmissionContext{Task: SubmissionTask{
+			TaskID:     task.TaskID,
+			Title:      task.Title,
+			Skills:     task.Skills,
+			ContractID: task.ContractID,
+		}}
+		if task.ContractID != "" {
+			contract, seen := contracts[task.ContractID]
+			if !seen {
+				if c, err := store.GetContract(task.ContractID); err == nil {
+					contract = &SubmissionContract{ContractID: c.ContractID, Title: c.Title, Status: c.Status}
+				}
+				contracts[task.ContractID] = contract
+			}
+			sc.Contract = contract
+		}
+		out[id] = sc
+	}
+	return out, nil
+}
+
+// distinctNonEmpty drops empty and repeated IDs, keeping first-seen order.
+func distinctNonEmpty(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package smart_contract
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// storeWithoutResolver hides a store's SubmissionContextResolver so the
+// GetTask/GetContract fallback is exercised.
+type storeWithoutResolver struct{ Store }
+
+func TestResolveSubmissionContexts(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	stores := map[string]Store{
+		"memory":   NewMemoryStore(time.Hour),
+		"sqlite":   sqliteStore,
+		"fallback": storeWithoutResolver{NewMemoryStore(time.Hour)},
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			contract := smart_contract.Contract{ContractID: "ctx-contract", Title: "Context", Status: "active"}
+			tasks := []smart_contract.Task{
+				{TaskID: "ctx-task-1", ContractID: contract.ContractID, Title: "One", Skills: []string{"go", "sql"}, Status: "available"},
+				{TaskID: "ctx-task-2", ContractID: contract.ContractID, Title: "Two", Status: "available"},
+			}
+			if err := store.UpsertContractWithTasks(ctx, contract, tasks); err != nil {
+				t.Fatalf("upsert: %v", err)
+			}
+
+			got, err := ResolveSubmissionContexts(ctx, store, []string{"ctx-task-1", "ctx-task-2", "ctx-task-1", "", "missing-task"})
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			if len(got) != 2 {
+				t.Fatalf("expected 2 contexts, got %d: %+v", len(got), got)
+			}
+			one := got["ctx-task-1"]
+			if one.Task.Title != "One" || !reflect.DeepEqual(one.Task.Skills, []string{"go", "sql"}) || one.Task.ContractID != contract.ContractID {
+				t.Fatalf("task summary = %+v", one.Task)
+			}
+			if one.Contract == nil || one.Contract.Title != "Context" || one.Contract.Status != "active" {
+				t.Fatalf("contract summary = %+v", one.Contract)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package smart_contract
+
+import (
+	"strings"
+
+	"stargate-backend/core/smart_contract"
+)
+
+// taskStatusClosed reports whether a task status is terminal: the work has
+// been approved or paid out and a new claim could never be settled.
+func taskStatusClosed(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case smart_contract.TaskStatusApproved, smart_contract.TaskStatusPublished, smart_contract.TaskStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// contractStatusClosed reports whether a contract status means no further
+// work on its tasks will be paid.
+func contractStatusClosed(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case smart_contract.ContractStatusExpired, smart_contract.ContractStatusCompleted, smart_contract.ContractStatusClosed:
+		return true
+	}
+	return false
+}
+
+// submissionStatusFinal reports whether a submission has been accepted.
+func submissionStatusFinal(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case smart_contract.SubmissionStatusApproved, "accepted":
+		return true
+	}
+	return false
+}
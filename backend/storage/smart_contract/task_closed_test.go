@@ -0,0 +1,64 @@
+package smart_contract
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+func TestClaimTaskRefusesClosedTasks(t *testing.T) {
+	ctx := context.Background()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+
+	for name, store := range map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("approved task", func(t *testing.T) {
+				claim, err := store.ClaimTask(SeedTaskBollingerID, "wallet-1", nil)
+				if err != nil {
+					t.Fatalf("claim: %v", err)
+				}
+				sub, err := store.SubmitWork(claim.ClaimID, map[string]interface{}{"notes": "done"}, nil)
+				if err != nil {
+					t.Fatalf("submit: %v", err)
+				}
+				if err := store.UpdateSubmissionStatus(ctx, sub.SubmissionID, smart_contract.SubmissionStatusApproved, "", ""); err != nil {
+					t.Fatalf("approve: %v", err)
+				}
+				for _, wallet := range []string{"wallet-1", "wallet-2"} {
+					if _, err := store.ClaimTask(SeedTaskBollingerID, wallet, nil); !errors.Is(err, ErrTaskClosed) {
+						t.Fatalf("claim by %s: err = %v, want ErrTaskClosed", wallet, err)
+					}
+				}
+			})
+
+			t.Run("closed contract", func(t *testing.T) {
+				contract := smart_contract.Contract{ContractID: "closed-contract", Title: "Closed", Status: "active"}
+				task := smart_contract.Task{TaskID: "closed-task", Title: "Never paid", Status: "available"}
+				if err := store.UpsertContractWithTasks(ctx, contract, []smart_contract.Task{task}); err != nil {
+					t.Fatalf("upsert: %v", err)
+				}
+				if err := store.UpdateContractStatus(ctx, contract.ContractID, smart_contract.ContractStatusCompleted); err != nil {
+					t.Fatalf("close contract: %v", err)
+				}
+				if _, err := store.ClaimTask(task.TaskID, "wallet-1", nil); !errors.Is(err, ErrTaskClosed) {
+					t.Fatalf("claim: err = %v, want ErrTaskClosed", err)
+				}
+				got, err := store.GetTask(task.TaskID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Status != "available" || got.ActiveClaimID != "" {
+					t.Fatalf("refused claim changed task: %+v", got)
+				}
+			})
+		})
+	}
+}
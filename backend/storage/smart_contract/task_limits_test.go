@@ -0,0 +1,97 @@
+package smart_contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"stargate-backend/core/smart_contract"
+)
+
+func limitTasks(prefix string, n int) []smart_contract.Task {
+	tasks := make([]smart_contract.Task, n)
+	for i := range tasks {
+		tasks[i] = smart_contract.Task{
+			TaskID:     fmt.Sprintf("%s-%d", prefix, i),
+			Title:      fmt.Sprintf("Task %d", i),
+			BudgetSats: 1000,
+			Status:     "available",
+		}
+	}
+	return tasks
+}
+
+func taskLimitStores(t *testing.T) map[string]Store {
+	t.Helper()
+	sqliteStore, err := NewSQLiteStore(filepath.Join(t.TempDir(), "mcp.db"), time.Hour, true)
+	if err != nil {
+		t.Fatalf("create sqlite store: %v", err)
+	}
+	t.Cleanup(sqliteStore.Close)
+	return map[string]Store{"memory": NewMemoryStore(time.Hour), "sqlite": sqliteStore}
+}
+
+func limitProposal(id, hash string, tasks []smart_contract.Task) smart_contract.Proposal {
+	return smart_contract.Proposal{
+		ID:         id,
+		Title:      "Task limits",
+		BudgetSats: 5000,
+		Status:     "pending",
+		Tasks:      tasks,
+		Metadata:   map[string]interface{}{"visible_pixel_hash": hash, "contract_id": hash},
+	}
+}
+
+func TestMaxTasksPerProposal(t *testing.T) {
+	t.Setenv("STARGATE_MAX_TASKS_PER_PROPOSAL", "3")
+	ctx := context.Background()
+	for name, store := range taskLimitStores(t) {
+		t.Run(name, func(t *testing.T) {
+			atLimit := limitProposal("limit-at", strings.Repeat("a1", 32), limitTasks("limit-at", 3))
+			if err := store.CreateProposal(ctx, atLimit); err != nil {
+				t.Fatalf("create proposal at limit: %v", err)
+			}
+			over := limitProposal("limit-over", strings.Repeat("b2", 32), limitTasks("limit-over", 4))
+			if err := store.CreateProposal(ctx, over); !errors.Is(err, ErrTooManyTasks) {
+				t.Fatalf("create proposal over limit: err = %v, want ErrTooManyTasks", err)
+			}
+			atLimit.Tasks = limitTasks("limit-at", 4)
+			if err := store.UpdateProposal(ctx, atLimit); !errors.Is(err, ErrTooManyTasks) {
+				t.Fatalf("update proposal over limit: err = %v, want ErrTooManyTasks", err)
+			}
+		})
+	}
+}
+
+func TestMaxTasksPerContractAtPublish(t *testing.T) {
+	t.Setenv("STARGATE_MAX_TASKS_PER_CONTRACT", "4")
+	ctx := context.Background()
+	for name, store := range taskLimitStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for hash, n := range map[string]int{strings.Repeat("c3", 32): 4, strings.Repeat("d4", 32): 5} {
+				c := smart_contract.Contract{ContractID: hash, Title: "Task limits", Status: "active"}
+				if err := store.UpsertContractWithTasks(ctx, c, limitTasks(hash[:8], n)); err != nil {
+					t.Fatalf("upsert contract: %v", err)
+				}
+				id := "publish-" + hash[:8]
+				if err := store.CreateProposal(ctx, limitProposal(id, hash, nil)); err != nil {
+					t.Fatalf("create proposal: %v", err)
+				}
+				if err := store.ApproveProposal(ctx, id); err != nil {
+					t.Fatalf("approve proposal: %v", err)
+				}
+				err := store.PublishProposal(ctx, id)
+				if n <= 4 && err != nil {
+					t.Fatalf("publish contract with %d tasks: %v", n, err)
+				}
+				if n > 4 && !errors.Is(err, ErrTooManyTasks) {
+					t.Fatalf("publish contract with %d tasks: err = %v, want ErrTooManyTasks", n, err)
+				}
+			}
+		})
+	}
+}
@@ -17,6 +17,32 @@ func DefaultBudgetSats() int64 {
 	return 100_000
 }
 
+// Default task limits; override with STARGATE_MAX_TASKS_PER_PROPOSAL and
+// STARGATE_MAX_TASKS_PER_CONTRACT.
+const (
+	DefaultMaxTasksPerProposal = 100
+	DefaultMaxTasksPerContract = 500
+)
+
+// MaxTasksPerProposal returns how many tasks a single proposal may define.
+func MaxTasksPerProposal() int {
+	return positiveIntEnv("STARGATE_MAX_TASKS_PER_PROPOSAL", DefaultMaxTasksPerProposal)
+}
+
+// MaxTasksPerContract returns how many tasks a contract may hold when published.
+func MaxTasksPerContract() int {
+	return positiveIntEnv("STARGATE_MAX_TASKS_PER_CONTRACT", DefaultMaxTasksPerContract)
+}
+
+func positiveIntEnv(name string, def int) int {
+	if raw := strings.TrimSpace(os.Getenv(name)); raw != "" {
+		if v, err := strconv.Atoi(raw); err == nil && v > 0 {
+			return v
+		}
+	}
+	return def
+}
+
 // FundingAddressFromMeta extracts funding address from metadata.
 func FundingAddressFromMeta(meta map[string]interface{}) string {
 	if meta != nil {
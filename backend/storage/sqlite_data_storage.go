@@ -8,6 +8,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -71,7 +72,14 @@ CREATE TABLE IF NOT EXISTS %s (
 );
 CREATE INDEX IF NOT EXISTS idx_%s_hash ON %s(block_hash);
 CREATE INDEX IF NOT EXISTS idx_%s_scanned ON %s(scanned_at);
-`, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName)
+CREATE TABLE IF NOT EXISTS %s_messages (
+    block_height INTEGER NOT NULL,
+    tx_id        TEXT NOT NULL DEFAULT '',
+    message      TEXT NOT NULL,
+    contract_id  TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_%s_messages_height ON %s_messages(block_height);
+`, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName, s.tableName)
 
 	_, err := s.db.ExecContext(ctx, schema)
 	return err
@@ -111,9 +119,67 @@ func (s *SQLiteDataStorage) StoreBlockData(blockResponse *bitcoin.BlockInscripti
 	if err != nil {
 		log.Printf("SQLiteDataStorage StoreBlockData warning: %v", err)
 	}
+	if err := s.indexMessages(blockResponse.BlockHeight, extractBlockMessages(blockResponse.BlockHeight, blockResponse.SmartContracts, scanResults)); err != nil {
+		log.Printf("SQLiteDataStorage message index warning: %v", err)
+	}
 	return nil
 }
 
+// indexMessages replaces the indexed messages for height.
+func (s *SQLiteDataStorage) indexMessages(height int64, messages []MessageMatch) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s_messages WHERE block_height=?`, s.tableName), height); err != nil {
+		return err
+	}
+	ins := fmt.Sprintf(`INSERT INTO %s_messages (block_height, tx_id, message, contract_id) VALUES (?,?,?,?)`, s.tableName)
+	for _, m := range messages {
+		if _, err := tx.Exec(ins, m.BlockHeight, m.TxID, m.Message, m.ContractID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SearchMessages returns extracted messages containing query, newest block first.
+func (s *SQLiteDataStorage) SearchMessages(query string, limit int) ([]MessageMatch, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	q := fmt.Sprintf(`SELECT block_height, tx_id, message, contract_id FROM %s_messages
+		WHERE message LIKE ? ESCAPE '\' ORDER BY block_height DESC, rowid ASC LIMIT ?`, s.tableName)
+	rows, err := s.db.Query(q, likePattern(query), normalizeMessageLimit(limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []MessageMatch
+	for rows.Next() {
+		var m MessageMatch
+		if err := rows.Scan(&m.BlockHeight, &m.TxID, &m.Message, &m.ContractID); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// GetRecentDetections returns the most recent detections across stored blocks.
+func (s *SQLiteDataStorage) GetRecentDetections(limit int) ([]bitcoin.SmartContractData, error) {
+	limit = normalizeDetectionLimit(limit)
+	q := fmt.Sprintf(`SELECT block_height, json_extract(payload, '$.smart_contracts') FROM %s
+WHERE json_array_length(payload, '$.smart_contracts') > 0 ORDER BY block_height DESC`, s.tableName)
+	rows, err := s.db.Query(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query detections: %w", err)
+	}
+	return collectDetections(rows, limit)
+}
+
 func (s *SQLiteDataStorage) GetBlockData(height int64) (interface{}, error) {
 	var payload string
 	q := fmt.Sprintf(`SELECT payload FROM %s WHERE block_height=?`, s.tableName)
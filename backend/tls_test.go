@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate for 127.0.0.1 and returns
+// the cert and key paths.
+func writeTestCert(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "stargate test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestServesTLSWhenConfigured(t *testing.T) {
+	certFile, keyFile := writeTestCert(t)
+	t.Setenv("TLS_CERT_FILE", certFile)
+	t.Setenv("TLS_KEY_FILE", keyFile)
+	cfg, err := loadTLSFromEnv()
+	if err != nil || cfg == nil {
+		t.Fatalf("loadTLSFromEnv = %v, %v", cfg, err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	srv := &http.Server{
+		Handler:   http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "ok") }),
+		TLSConfig: cfg,
+	}
+	go srv.ServeTLS(ln, "", "")
+	defer srv.Close()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + ln.Addr().String() + "/")
+	if err != nil {
+		t.Fatalf("GET over TLS: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.TLS == nil || string(body) != "ok" {
+		t.Fatalf("TLS = %v, body = %q", resp.TLS, body)
+	}
+}
+
+func TestLoadTLSFromEnvValidation(t *testing.T) {
+	t.Setenv("TLS_CERT_FILE", "")
+	t.Setenv("TLS_KEY_FILE", "")
+	if cfg, err := loadTLSFromEnv(); cfg != nil || err != nil {
+		t.Fatalf("unset = %v, %v; want plain HTTP", cfg, err)
+	}
+
+	certFile, _ := writeTestCert(t)
+	t.Setenv("TLS_CERT_FILE", certFile)
+	if _, err := loadTLSFromEnv(); err == nil {
+		t.Fatal("cert without key accepted")
+	}
+
+	_, otherKey := writeTestCert(t)
+	t.Setenv("TLS_KEY_FILE", otherKey)
+	if _, err := loadTLSFromEnv(); err == nil {
+		t.Fatal("mismatched cert and key accepted")
+	}
+}
+
+func TestHTTPSRedirectHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "http://starlight.local:8080/api/inscribe?x=1", nil)
+	httpsRedirectHandler("8443").ServeHTTP(rec, req)
+	if rec.Code != http.StatusPermanentRedirect {
+		t.Fatalf("status = %d, want 308", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "https://starlight.local:8443/api/inscribe?x=1" {
+		t.Fatalf("Location = %q", loc)
+	}
+
+	rec = httptest.NewRecorder()
+	httpsRedirectHandler("443").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://starlight.local/", nil))
+	if loc := rec.Header().Get("Location"); loc != "https://starlight.local/" {
+		t.Fatalf("Location = %q", loc)
+	}
+}